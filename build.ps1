@@ -22,3 +22,4 @@ foreach($i in (ls third_party/*)){
 
 ./scripts/release-version.ps1 | Out-File -Encoding UTF8 server/release_version.go
 go build -v "${ETCD_PACKAGE}"
+go build -v "${ETCD_PACKAGE}/tools/etcd-dump"
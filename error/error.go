@@ -34,18 +34,34 @@ const (
 	EcodeKeyIsPreserved = 106
 	EcodeRootROnly      = 107
 	EcodeDirNotEmpty    = 108
+	EcodeInvalidKey     = 109
+	EcodeUnauthorized   = 110
 
 	EcodeValueRequired      = 200
 	EcodePrevValueRequired  = 201
 	EcodeTTLNaN             = 202
 	EcodeIndexNaN           = 203
 	EcodeValueOrTTLRequired = 204
+	EcodeInvalidCallback    = 205
+	EcodeTTLTooLarge        = 206
+	EcodeInvalidRequestID   = 207
+	EcodeRefreshValue       = 208
+	EcodeRefreshTTLRequired = 209
+	EcodeInvalidCoalesce    = 210
+	EcodeInvalidField       = 211
+	EcodeInvalidSerialize   = 212
 
 	EcodeRaftInternal = 300
 	EcodeLeaderElect  = 301
 
 	EcodeWatcherCleared    = 400
 	EcodeEventIndexCleared = 401
+	EcodeTooManyBlocking   = 402
+	EcodeIndexNotReached   = 403
+	EcodeWatcherTooSlow    = 404
+	EcodeWatchExpired      = 405
+	EcodeQuotaExceeded     = 406
+	EcodeRequestCancelled  = 407
 )
 
 func init() {
@@ -61,6 +77,8 @@ func init() {
 	errors[EcodeRootROnly] = "Root is read only"
 	errors[EcodeKeyIsPreserved] = "The prefix of given key is a keyword in etcd"
 	errors[EcodeDirNotEmpty] = "Directory not empty"
+	errors[EcodeInvalidKey] = "Invalid key"
+	errors[EcodeUnauthorized] = "The client is not allowed to write the key"
 
 	// Post form related errors
 	errors[EcodeValueRequired] = "Value is Required in POST form"
@@ -68,6 +86,14 @@ func init() {
 	errors[EcodeTTLNaN] = "The given TTL in POST form is not a number"
 	errors[EcodeIndexNaN] = "The given index in POST form is not a number"
 	errors[EcodeValueOrTTLRequired] = "Value or TTL is required in POST form"
+	errors[EcodeInvalidCallback] = "The given JSONP callback is not a valid function name"
+	errors[EcodeTTLTooLarge] = "The given TTL in POST form exceeds the maximum TTL"
+	errors[EcodeInvalidRequestID] = "The given requestId in POST form is not valid"
+	errors[EcodeRefreshValue] = "A value cannot be given when refreshing a TTL"
+	errors[EcodeRefreshTTLRequired] = "A TTL is required when refreshing"
+	errors[EcodeInvalidCoalesce] = "The given coalesce interval is not a positive duration"
+	errors[EcodeInvalidField] = "The given field is not a known response field"
+	errors[EcodeInvalidSerialize] = "The given serialize prefix does not contain the key"
 
 	// raft related errors
 	errors[EcodeRaftInternal] = "Raft Internal Error"
@@ -76,6 +102,12 @@ func init() {
 	// etcd related errors
 	errors[EcodeWatcherCleared] = "watcher is cleared due to etcd recovery"
 	errors[EcodeEventIndexCleared] = "The event in requested index is outdated and cleared"
+	errors[EcodeTooManyBlocking] = "Too many requests are waiting on the server"
+	errors[EcodeIndexNotReached] = "The member has not reached the requested index"
+	errors[EcodeWatcherTooSlow] = "The watcher fell too far behind and was removed, watch again from the last index received"
+	errors[EcodeWatchExpired] = "The watch reached the maximum watch duration, watch again from the same index"
+	errors[EcodeQuotaExceeded] = "The client exceeded its quota"
+	errors[EcodeRequestCancelled] = "The request was cancelled by an administrator"
 
 }
 
@@ -114,6 +146,8 @@ func (e Error) Write(w http.ResponseWriter) {
 	// 3xx is reft internal error
 	if e.ErrorCode/100 == 3 {
 		http.Error(w, e.toJsonString(), http.StatusInternalServerError)
+	} else if e.ErrorCode == EcodeWatchExpired {
+		http.Error(w, e.toJsonString(), http.StatusRequestTimeout)
 	} else {
 		http.Error(w, e.toJsonString(), http.StatusBadRequest)
 	}
@@ -19,9 +19,12 @@ package main
 import (
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/coreos/etcd/log"
+	"github.com/coreos/etcd/mod/dns"
 	"github.com/coreos/etcd/server"
 	"github.com/coreos/etcd/store"
 	"github.com/coreos/raft"
@@ -42,7 +45,10 @@ func main() {
 		os.Exit(0)
 	}
 
-	// Enable options.
+	// Enable options. A dev instance keeps stdout for its client URL.
+	if config.Dev {
+		log.SetOutput(os.Stderr)
+	}
 	if config.VeryVerbose {
 		log.Verbose = true
 		raft.SetLogLevel(raft.Debug)
@@ -57,6 +63,12 @@ func main() {
 		log.Fatal("The data dir was not set and could not be guessed from machine name")
 	}
 
+	// A process started by a handover waits for the previous one to let go
+	// of the data directory.
+	if err := server.WaitForHandover(); err != nil {
+		log.Fatal(err)
+	}
+
 	// Create data directory if it doesn't already exist.
 	if err := os.MkdirAll(config.DataDir, 0744); err != nil {
 		log.Fatalf("Unable to create path: %s", err)
@@ -80,11 +92,13 @@ func main() {
 
 	// Create etcd key-value store and registry.
 	store := store.New()
+	store.SetTombstoneRetention(time.Duration(config.TombstoneTTL)*time.Second, uint64(config.TombstoneIndexes))
 	registry := server.NewRegistry(store)
 
 	// Create peer server.
 	ps := server.NewPeerServer(info.Name, config.DataDir, info.RaftURL, info.RaftListenHost, &peerTLSConfig, &info.RaftTLS, registry, store, config.SnapshotCount)
 	ps.MaxClusterSize = config.MaxClusterSize
+	ps.MaxObservers = config.MaxObservers
 	ps.RetryTimes = config.MaxRetryAttempts
 	if config.HeartbeatTimeout > 0 {
 		ps.HeartbeatTimeout = time.Duration(config.HeartbeatTimeout) * time.Millisecond
@@ -92,18 +106,159 @@ func main() {
 	if config.ElectionTimeout > 0 {
 		ps.ElectionTimeout = time.Duration(config.ElectionTimeout) * time.Millisecond
 	}
+	ps.ElectionWindow = time.Duration(config.ElectionWindow) * time.Millisecond
+	ps.BatchWindow = time.Duration(config.BatchWindow) * time.Millisecond
+	if config.SlowDiskThreshold > 0 {
+		ps.SlowDiskThreshold = time.Duration(config.SlowDiskThreshold) * time.Millisecond
+	}
+	ps.SlowDiskAbdicate = config.SlowDiskAbdicate
+	if config.MaxClockSkew > 0 {
+		ps.MaxClockSkew = time.Duration(config.MaxClockSkew) * time.Millisecond
+	}
+	if ps.Tags, err = config.TagMap(); err != nil {
+		log.Fatal("Tags:", err)
+	}
+	ps.LeaderZone = config.LeaderZone
+	ps.Observer = config.Observer
+	ps.HashCheckInterval = time.Duration(config.HashCheckInterval) * time.Second
+	ps.NoFsync = config.Dev
+	ps.SocketOptions = config.SocketOptions()
+	if ps.SocketOptions.Filter, err = server.NewIPFilter(config.Peer.AllowCIDRs, config.Peer.DenyCIDRs); err != nil {
+		log.Fatal("Peer listener:", err)
+	}
+	snapConf := ps.SnapshotConfig()
+	snapConf.Bytes = uint64(config.SnapshotBytes)
+	snapConf.MaxLogBytes = uint64(config.MaxLogBytes)
+	ps.SetSnapshotConfig(snapConf)
 
 	// Create client server.
 	s := server.New(info.Name, info.EtcdURL, info.EtcdListenHost, &tlsConfig, &info.EtcdTLS, ps, registry, store)
 	if err := s.AllowOrigins(config.CorsOrigins); err != nil {
 		panic(err)
 	}
+	if err := s.TrustProxies(config.TrustedProxies); err != nil {
+		panic(err)
+	}
+	if err := s.AllowAdmin(config.AdminCIDRs); err != nil {
+		panic(err)
+	}
+	quotas := len(config.UserMaxBytes) > 0 || len(config.UserMaxRate) > 0 || len(config.UserMaxWatches) > 0
+	if (len(config.AdminNames) > 0 || len(config.WriteRules) > 0 || quotas) && config.CAFile == "" {
+		log.Fatal("Authorization requires client certificates: set -ca-file")
+	}
+	s.AllowAdminNames(config.AdminNames)
+	if err := s.AllowWriters(config.WriteRules); err != nil {
+		log.Fatal(err)
+	}
+	if err := s.SetUserQuotas(config.UserMaxWatches, config.UserMaxRate, config.UserMaxBytes); err != nil {
+		log.Fatal(err)
+	}
+	if len(config.EncryptPrefixes) > 0 {
+		if config.EncryptionKeyFile == "" {
+			log.Fatal("Encrypted prefixes require a key: set -encryption-key-file")
+		}
+		c, err := server.NewKeyFileCipher(config.EncryptionKeyFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := s.EncryptValues(config.EncryptPrefixes, c); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if err := s.FoldKeyCase(config.FoldCasePrefixes); err != nil {
+		log.Fatal(err)
+	}
+	s.MaxKeyDepth = config.MaxKeyDepth
+	s.MaxKeyNameLength = config.MaxKeyNameLength
+	s.DefaultTTL = config.DefaultTTL
+	s.MaxTTL = config.MaxTTL
+	s.TTLPrefixes = config.TTLPrefixes
+	s.AccessLog = config.AccessLog
+	s.DebugTTL = time.Duration(config.DebugTTL) * time.Second
+	s.SocketOptions = config.SocketOptions()
+	if s.SocketOptions.Filter, err = server.NewIPFilter(config.AllowCIDRs, config.DenyCIDRs); err != nil {
+		log.Fatal("Client listener:", err)
+	}
+	s.MaxBlockingRequests = config.MaxBlocking
+	s.MaxWatchDuration = time.Duration(config.MaxWatchDuration) * time.Second
+	if s.SlowRequestThreshold, err = config.SlowRequestThreshold(); err != nil {
+		log.Fatal(err)
+	}
 
 	ps.SetServer(s)
 
+	// Serve the services as DNS records.
+	var d *dns.Server
+	if config.DNS.Addr != "" {
+		d = dns.NewServer(s.URL(), config.DNS.Domain, config.DNS.Prefix)
+	}
+
+	// Hand leadership over before exiting on SIGTERM or an interrupt.
+	go func() {
+		c := make(chan os.Signal, 1)
+		signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+		sig := <-c
+		log.Infof("%v received, shutting down", sig)
+		ps.Resign(2 * ps.ElectionTimeout)
+		if config.Dev {
+			os.RemoveAll(config.DataDir)
+		}
+		os.Exit(0)
+	}()
+
+	// Hand the listeners over to a new process on SIGUSR2 for an upgrade.
+	// The listeners are closed on the way out, which must not end the
+	// process before the handover completes.
+	handedOver := make(chan bool)
+	go func() {
+		c := make(chan os.Signal, 1)
+		server.NotifyHandover(c)
+		for range c {
+			err := server.Handover(func() {
+				close(handedOver)
+				ps.Resign(2 * ps.ElectionTimeout)
+				s.Close()
+				if d != nil {
+					d.Close()
+				}
+				ps.Stop()
+			})
+			if err != nil {
+				log.Warnf("[handover] failed: %v", err)
+				continue
+			}
+			log.Infof("[handover] done, exiting")
+			os.Exit(0)
+		}
+	}()
+	serve := func(err error) {
+		select {
+		case <-handedOver:
+			select {}
+		default:
+			log.Fatal(err)
+		}
+	}
+
+	if d != nil {
+		go func() {
+			serve(d.ListenAndServe(config.DNS.Addr))
+		}()
+	}
+
+	// A dev instance prints its client URL on stdout once it serves.
+	if config.Dev {
+		go func() {
+			if err := s.StartDev(config.DevFixtures); err != nil {
+				log.Fatal(err)
+			}
+			fmt.Println(s.URL())
+		}()
+	}
+
 	// Run peer server in separate thread while the client server blocks.
 	go func() {
-		log.Fatal(ps.ListenAndServe(config.Snapshot, config.Peers))
+		serve(ps.ListenAndServe(config.Snapshot, config.Peers))
 	}()
-	log.Fatal(s.ListenAndServe())
+	serve(s.ListenAndServe())
 }
@@ -0,0 +1,90 @@
+// Package lifetime tells long-running handlers, such as watches, locks and
+// leaderships, when the request they serve is over.
+//
+// Not every http.ResponseWriter can report the client going away. Requests
+// answered through one that cannot end on a deadline instead of waiting
+// forever on a client that may be long gone.
+package lifetime
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// FallbackDeadline is the longest a request whose ResponseWriter cannot
+// report the client going away is served.
+var FallbackDeadline = 10 * time.Minute
+
+// CloseNotify returns the channel that fires when the client of w goes away,
+// or nil if w cannot tell. A ResponseWriter wrapper returns it from its own
+// CloseNotify so that it does not hide the lack of support.
+func CloseNotify(w http.ResponseWriter) <-chan bool {
+	if cn, ok := w.(http.CloseNotifier); ok {
+		return cn.CloseNotify()
+	}
+	return nil
+}
+
+// A Lifetime ends when the client of a request goes away, when its deadline
+// passes or when it is stopped, whichever comes first.
+type Lifetime struct {
+	done chan bool
+	stop chan bool
+	once sync.Once
+
+	mutex   sync.Mutex
+	expired bool
+}
+
+// Start starts the lifetime of the request answered through w, which ends
+// after d unless d is negative. If w cannot report the client going away,
+// the lifetime ends after FallbackDeadline at the latest.
+func Start(w http.ResponseWriter, d time.Duration) *Lifetime {
+	l := &Lifetime{done: make(chan bool), stop: make(chan bool)}
+
+	closeChan := CloseNotify(w)
+	if closeChan == nil && (d < 0 || d > FallbackDeadline) {
+		d = FallbackDeadline
+	}
+	var timer *time.Timer
+	var deadline <-chan time.Time
+	if d >= 0 {
+		timer = time.NewTimer(d)
+		deadline = timer.C
+	}
+
+	go func() {
+		select {
+		case <-closeChan:
+		case <-deadline:
+			l.mutex.Lock()
+			l.expired = true
+			l.mutex.Unlock()
+		case <-l.stop:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		close(l.done)
+	}()
+	return l
+}
+
+// Done returns a channel that is closed once the lifetime is over.
+func (l *Lifetime) Done() <-chan bool {
+	return l.done
+}
+
+// Expired tells whether the lifetime ended because its deadline passed.
+func (l *Lifetime) Expired() bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.expired
+}
+
+// Stop ends the lifetime. Handlers stop it once they answered so that
+// nothing is left waiting on the request.
+func (l *Lifetime) Stop() {
+	l.once.Do(func() { close(l.stop) })
+}
@@ -0,0 +1,67 @@
+package lifetime
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// notifyingWriter is a ResponseWriter whose client goes away on demand.
+type notifyingWriter struct {
+	http.ResponseWriter
+	closeChan chan bool
+}
+
+func (w *notifyingWriter) CloseNotify() <-chan bool {
+	return w.closeChan
+}
+
+// Ensures that a lifetime ends when the client goes away.
+func TestLifetimeClientGone(t *testing.T) {
+	w := &notifyingWriter{httptest.NewRecorder(), make(chan bool, 1)}
+	l := Start(w, -1)
+	defer l.Stop()
+
+	w.closeChan <- true
+	select {
+	case <-l.Done():
+	case <-time.After(time.Second):
+		t.Fatal("lifetime did not end when the client went away")
+	}
+	assert.False(t, l.Expired(), "")
+}
+
+// Ensures that a lifetime ends at its deadline and once stopped.
+func TestLifetimeDeadline(t *testing.T) {
+	w := &notifyingWriter{httptest.NewRecorder(), make(chan bool)}
+	l := Start(w, 10*time.Millisecond)
+	<-l.Done()
+	assert.True(t, l.Expired(), "")
+	l.Stop()
+
+	l = Start(w, -1)
+	l.Stop()
+	l.Stop()
+	<-l.Done()
+	assert.False(t, l.Expired(), "")
+}
+
+// Ensures that a ResponseWriter that cannot report the client going away
+// falls back to a deadline instead of waiting forever.
+func TestLifetimeFallbackDeadline(t *testing.T) {
+	defer func(d time.Duration) { FallbackDeadline = d }(FallbackDeadline)
+	FallbackDeadline = 10 * time.Millisecond
+
+	w := struct{ http.ResponseWriter }{httptest.NewRecorder()}
+	assert.Nil(t, CloseNotify(w), "")
+	l := Start(w, -1)
+	select {
+	case <-l.Done():
+	case <-time.After(time.Second):
+		t.Fatal("lifetime did not fall back to a deadline")
+	}
+	assert.True(t, l.Expired(), "")
+}
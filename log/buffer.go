@@ -0,0 +1,74 @@
+package log
+
+import (
+	"sync"
+	"time"
+)
+
+// The number of recent log entries kept in memory.
+const bufferSize = 1024
+
+// The log levels from the least to the most severe.
+var levels = map[string]int{
+	"debug":   0,
+	"info":    1,
+	"warning": 2,
+	"fatal":   3,
+}
+
+// Entry is a log message kept in memory so that it can be served to
+// operators without access to the machine.
+type Entry struct {
+	Index   uint64    `json:"index"`
+	Time    time.Time `json:"time"`
+	Level   string    `json:"level"`
+	Message string    `json:"message"`
+}
+
+// buffer holds the most recent entries, overwriting the oldest ones.
+var buffer struct {
+	sync.Mutex
+	entries [bufferSize]Entry
+	index   uint64
+}
+
+func record(level string, message string) {
+	buffer.Lock()
+	defer buffer.Unlock()
+
+	buffer.index++
+	buffer.entries[buffer.index%bufferSize] = Entry{
+		Index:   buffer.index,
+		Time:    time.Now(),
+		Level:   level,
+		Message: message,
+	}
+}
+
+// ValidLevel reports whether the level is the name of a log level.
+func ValidLevel(level string) bool {
+	_, ok := levels[level]
+	return ok
+}
+
+// Recent returns the buffered entries logged after the given index, oldest
+// first. Only entries at least as severe as the level are returned; an empty
+// level returns every entry.
+func Recent(since uint64, level string) []Entry {
+	buffer.Lock()
+	defer buffer.Unlock()
+
+	first := since + 1
+	if buffer.index >= bufferSize && first <= buffer.index-bufferSize {
+		first = buffer.index - bufferSize + 1
+	}
+
+	entries := make([]Entry, 0)
+	for i := first; i <= buffer.index; i++ {
+		e := buffer.entries[i%bufferSize]
+		if level == "" || levels[e.Level] >= levels[level] {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
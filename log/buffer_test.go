@@ -0,0 +1,33 @@
+package log
+
+import (
+	"fmt"
+	"testing"
+)
+
+// Ensures that only the most recent entries are kept.
+func TestRecentWraps(t *testing.T) {
+	start := Recent(0, "")
+	for i := 0; i < bufferSize+10; i++ {
+		record("info", fmt.Sprint(i))
+	}
+
+	entries := Recent(0, "")
+	if len(entries) != bufferSize {
+		t.Fatalf("expected %d entries, got %d", bufferSize, len(entries))
+	}
+	if entries[0].Message != "10" || entries[bufferSize-1].Message != fmt.Sprint(bufferSize+9) {
+		t.Fatalf("unexpected entries: first=%q last=%q", entries[0].Message, entries[bufferSize-1].Message)
+	}
+	if len(start) > 0 && entries[0].Index <= start[len(start)-1].Index {
+		t.Fatalf("old entries were returned")
+	}
+
+	last := entries[bufferSize-1].Index
+	if n := len(Recent(last-3, "")); n != 3 {
+		t.Fatalf("expected 3 entries since %d, got %d", last-3, n)
+	}
+	if n := len(Recent(0, "warning")); n != 0 {
+		t.Fatalf("expected no warnings, got %d", n)
+	}
+}
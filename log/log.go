@@ -1,44 +1,64 @@
 package log
 
 import (
+	"fmt"
 	golog "github.com/coreos/go-log/log"
+	"io"
 	"os"
+	"strings"
 )
 
 // The Verbose flag turns on verbose logging.
 var Verbose bool = false
 
-var logger *golog.Logger = golog.New("etcd", false,
-	golog.CombinedSink(os.Stdout, "[%s] %s %-9s | %s\n", []string{"prefix", "time", "priority", "message"}))
+var logger *golog.Logger = newLogger(os.Stdout)
+
+func newLogger(w io.Writer) *golog.Logger {
+	return golog.New("etcd", false,
+		golog.CombinedSink(w, "[%s] %s %-9s | %s\n", []string{"prefix", "time", "priority", "message"}))
+}
+
+// SetOutput sends the log messages to w instead of stdout. It must be
+// called before anything is logged.
+func SetOutput(w io.Writer) {
+	logger = newLogger(w)
+}
 
 func Infof(format string, v ...interface{}) {
 	logger.Infof(format, v...)
+	record("info", fmt.Sprintf(format, v...))
 }
 
 func Debugf(format string, v ...interface{}) {
 	if Verbose {
 		logger.Debugf(format, v...)
+		record("debug", fmt.Sprintf(format, v...))
 	}
 }
 
 func Debug(v ...interface{}) {
 	if Verbose {
 		logger.Debug(v...)
+		record("debug", fmt.Sprint(v...))
 	}
 }
 
 func Warnf(format string, v ...interface{}) {
 	logger.Warningf(format, v...)
+	record("warning", fmt.Sprintf(format, v...))
 }
 
 func Warn(v ...interface{}) {
 	logger.Warning(v...)
+	record("warning", fmt.Sprint(v...))
 }
 
 func Fatalf(format string, v ...interface{}) {
+	record("fatal", fmt.Sprintf(format, v...))
 	logger.Fatalf(format, v...)
 }
 
 func Fatal(v ...interface{}) {
+	record("fatal", strings.TrimSuffix(fmt.Sprintln(v...), "\n"))
 	logger.Fatalln(v...)
 }
@@ -0,0 +1,22 @@
+package v2
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// deleteHandler removes a blob along with its chunks.
+func (h *handler) deleteHandler(w http.ResponseWriter, req *http.Request) {
+	h.client.SyncCluster()
+
+	name := mux.Vars(req)["name"]
+	if _, err := h.client.Get(blobPath(name, manifestNode), false, false); err != nil {
+		http.Error(w, "delete blob error: "+err.Error(), http.StatusNotFound)
+		return
+	}
+	if _, err := h.client.Delete(blobPath(name), true); err != nil {
+		http.Error(w, "delete blob error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,81 @@
+package v2
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// getHandler reassembles a blob from its chunks. A blob that does not match
+// its checksums is never returned.
+func (h *handler) getHandler(w http.ResponseWriter, req *http.Request) {
+	h.client.SyncCluster()
+
+	name := mux.Vars(req)["name"]
+	m, data, err := h.read(name)
+	if err != nil {
+		if isNotFound(err) {
+			http.Error(w, "get blob error: "+err.Error(), http.StatusNotFound)
+		} else {
+			http.Error(w, "get blob error: "+err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", m.ContentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.Header().Set("ETag", `"`+m.SHA256+`"`)
+	w.Header().Set("X-Etcd-Blob-Index", strconv.FormatUint(m.Index, 10))
+	w.Write(data)
+}
+
+// manifestHandler retrieves the manifest of a blob.
+func (h *handler) manifestHandler(w http.ResponseWriter, req *http.Request) {
+	h.client.SyncCluster()
+
+	resp, err := h.client.Get(blobPath(mux.Vars(req)["name"], manifestNode), false, false)
+	if err != nil {
+		http.Error(w, "get blob manifest error: "+err.Error(), http.StatusNotFound)
+		return
+	}
+	m, err := parseManifest(resp.Node)
+	if err != nil {
+		http.Error(w, "get blob manifest error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(m)
+}
+
+// read reads the manifest of a blob and then its chunks. The chunks of a
+// blob are removed once another upload replaces it, so the manifest is
+// read again if they are gone.
+func (h *handler) read(name string) (*manifest, []byte, error) {
+	var err error
+	for i := 0; i < 3; i++ {
+		resp, e := h.client.Get(blobPath(name, manifestNode), false, false)
+		if e != nil {
+			return nil, nil, e
+		}
+		m, e := parseManifest(resp.Node)
+		if e != nil {
+			return nil, nil, e
+		}
+		if len(m.Chunks) == 0 {
+			return m, []byte{}, nil
+		}
+
+		resp, err = h.client.Get(blobPath(name, uploadsNode, m.Upload), false, true)
+		if isNotFound(err) {
+			continue
+		} else if err != nil {
+			return nil, nil, err
+		}
+		data, err := m.assemble(resp.Node)
+		return m, data, err
+	}
+	return nil, nil, err
+}
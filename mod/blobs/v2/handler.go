@@ -0,0 +1,53 @@
+package v2
+
+import (
+	"net/http"
+	"path"
+
+	"github.com/coreos/go-etcd/etcd"
+	"github.com/gorilla/mux"
+)
+
+const prefix = "/_etcd/mod/blobs"
+
+// The key of a blob holding its manifest, the directory holding the chunks
+// of its uploads, one directory per upload, and the directory marking the
+// uploads that are still being written.
+const (
+	manifestNode = "manifest"
+	uploadsNode  = "uploads"
+	pendingNode  = "pending"
+)
+
+// handler manages the blobs HTTP request.
+type handler struct {
+	*mux.Router
+	client *etcd.Client
+}
+
+// NewHandler creates an HTTP handler that can be registered on a router.
+// Its requests to the etcd server at addr are sent through tr.
+func NewHandler(addr string, tr http.RoundTripper) http.Handler {
+	h := &handler{
+		Router: mux.NewRouter(),
+		client: etcd.NewClient([]string{addr}),
+	}
+	h.client.SetTransport(tr)
+	h.StrictSlash(false)
+	h.HandleFunc("/blobs/{name:[a-zA-Z0-9_.-]+}", h.putHandler).Methods("PUT")
+	h.HandleFunc("/blobs/{name:[a-zA-Z0-9_.-]+}", h.getHandler).Methods("GET")
+	h.HandleFunc("/blobs/{name:[a-zA-Z0-9_.-]+}", h.deleteHandler).Methods("DELETE")
+	h.HandleFunc("/blobs/{name:[a-zA-Z0-9_.-]+}/manifest", h.manifestHandler).Methods("GET")
+	return h
+}
+
+// blobPath returns the path of a key of a blob.
+func blobPath(name string, nodes ...string) string {
+	return path.Join(append([]string{prefix, name}, nodes...)...)
+}
+
+// isNotFound tells whether err is a key that does not exist.
+func isNotFound(err error) bool {
+	e, ok := err.(etcd.EtcdError)
+	return ok && e.ErrorCode == 100
+}
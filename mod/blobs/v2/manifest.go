@@ -0,0 +1,115 @@
+package v2
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strconv"
+
+	"github.com/coreos/go-etcd/etcd"
+)
+
+// The size of the chunks of an upload that does not ask for one, and the
+// largest chunk size allowed. A chunk is stored base64 encoded as the value
+// of a single key, so it must stay well below the size of a request.
+const (
+	defaultChunkSize = 512 * 1024
+	maxChunkSize     = 4 * 1024 * 1024
+)
+
+// The largest blob that can be uploaded.
+const maxBlobSize = 64 * 1024 * 1024
+
+// How long, in seconds, an upload is marked as pending. Every chunk written
+// renews it, and the chunks of an upload that is neither pending nor
+// published are removed by the next upload of the same blob.
+const pendingTTL = 60
+
+// manifest describes a published blob: the upload holding its chunks and the
+// checksums of the whole blob and of every chunk. Publishing a blob is
+// writing its manifest, so readers see either the previous blob or the new
+// one in full.
+type manifest struct {
+	Upload      string   `json:"upload"`
+	Size        int      `json:"size"`
+	ChunkSize   int      `json:"chunkSize"`
+	SHA256      string   `json:"sha256"`
+	Chunks      []string `json:"chunks"`
+	ContentType string   `json:"contentType,omitempty"`
+
+	// The index the manifest was published at. It is not stored.
+	Index uint64 `json:"index,omitempty"`
+}
+
+// newManifest splits data into chunks of chunkSize bytes and describes them
+// for a new upload.
+func newManifest(data []byte, chunkSize int, contentType string) (*manifest, [][]byte, error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, nil, err
+	}
+
+	m := &manifest{
+		Upload:      hex.EncodeToString(id),
+		Size:        len(data),
+		ChunkSize:   chunkSize,
+		SHA256:      checksum(data),
+		ContentType: contentType,
+	}
+	var chunks [][]byte
+	for i := 0; i < len(data); i += chunkSize {
+		end := i + chunkSize
+		if end > len(data) {
+			end = len(data)
+		}
+		chunks = append(chunks, data[i:end])
+		m.Chunks = append(m.Chunks, checksum(data[i:end]))
+	}
+	return m, chunks, nil
+}
+
+// parseManifest reads the manifest stored in a node.
+func parseManifest(n *etcd.Node) (*manifest, error) {
+	var m manifest
+	if err := json.Unmarshal([]byte(n.Value), &m); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %v", err)
+	}
+	m.Index = n.ModifiedIndex
+	return &m, nil
+}
+
+// assemble puts the chunks of an upload back together, checking each chunk
+// and then the whole blob against the manifest.
+func (m *manifest) assemble(upload *etcd.Node) ([]byte, error) {
+	chunks := make(map[string]string, len(upload.Nodes))
+	for _, n := range upload.Nodes {
+		chunks[path.Base(n.Key)] = n.Value
+	}
+
+	data := make([]byte, 0, m.Size)
+	for i, sum := range m.Chunks {
+		value, ok := chunks[strconv.Itoa(i)]
+		if !ok {
+			return nil, fmt.Errorf("chunk %d is missing", i)
+		}
+		chunk, err := base64.StdEncoding.DecodeString(value)
+		if err != nil || checksum(chunk) != sum {
+			return nil, fmt.Errorf("chunk %d does not match its checksum", i)
+		}
+		data = append(data, chunk...)
+	}
+	if len(data) != m.Size || checksum(data) != m.SHA256 {
+		return nil, fmt.Errorf("blob does not match its checksum")
+	}
+	return data, nil
+}
+
+// checksum returns the hex encoded SHA-256 of data.
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
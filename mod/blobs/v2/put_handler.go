@@ -0,0 +1,167 @@
+package v2
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/coreos/go-etcd/etcd"
+	"github.com/gorilla/mux"
+)
+
+// putHandler uploads a blob from the request body and publishes it once all
+// of its chunks are stored, replacing the previous blob of the same name.
+// The body must not be sent as a form, since etcd would read it as one.
+// The "chunkSize" parameter sets the number of bytes stored per key and the
+// optional "sha256" parameter is checked against the body.
+func (h *handler) putHandler(w http.ResponseWriter, req *http.Request) {
+	h.client.SyncCluster()
+
+	contentType := req.Header.Get("Content-Type")
+	if strings.HasPrefix(contentType, "application/x-www-form-urlencoded") || strings.HasPrefix(contentType, "multipart/form-data") {
+		http.Error(w, "upload blob error: send the body as application/octet-stream", http.StatusUnsupportedMediaType)
+		return
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	query := req.URL.Query()
+	chunkSize := defaultChunkSize
+	if s := query.Get("chunkSize"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 || n > maxChunkSize {
+			http.Error(w, "invalid chunkSize: "+s, http.StatusBadRequest)
+			return
+		}
+		chunkSize = n
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(req.Body, maxBlobSize+1))
+	if err != nil {
+		http.Error(w, "upload blob error: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(data) > maxBlobSize {
+		http.Error(w, fmt.Sprintf("upload blob error: larger than %d bytes", maxBlobSize), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	m, chunks, err := newManifest(data, chunkSize, contentType)
+	if err != nil {
+		http.Error(w, "upload blob error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if sum := query.Get("sha256"); sum != "" && !strings.EqualFold(sum, m.SHA256) {
+		http.Error(w, "upload blob error: body does not match sha256 "+sum, http.StatusBadRequest)
+		return
+	}
+
+	name := mux.Vars(req)["name"]
+	if err := h.upload(name, m, chunks); err != nil {
+		h.client.Delete(blobPath(name, uploadsNode, m.Upload), true)
+		http.Error(w, "upload blob error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := h.publish(name, m); err != nil {
+		h.client.Delete(blobPath(name, uploadsNode, m.Upload), true)
+		http.Error(w, "publish blob error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.sweep(name)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(m)
+}
+
+// upload stores the chunks of an upload, marking it as pending until a bit
+// after the last chunk is written.
+func (h *handler) upload(name string, m *manifest, chunks [][]byte) error {
+	upload := blobPath(name, uploadsNode, m.Upload)
+	pending := blobPath(name, pendingNode, m.Upload)
+	for i, chunk := range chunks {
+		if _, err := h.client.Set(pending, m.Upload, pendingTTL); err != nil {
+			return err
+		}
+		if _, err := h.client.Set(path.Join(upload, strconv.Itoa(i)), base64.StdEncoding.EncodeToString(chunk), 0); err != nil {
+			return err
+		}
+	}
+	_, err := h.client.Set(pending, m.Upload, pendingTTL)
+	return err
+}
+
+// publish atomically replaces the manifest of a blob and then removes the
+// chunks of the upload it replaced. A blob published in the meantime by
+// another upload is replaced in turn.
+func (h *handler) publish(name string, m *manifest) error {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	key := blobPath(name, manifestNode)
+	for i := 0; i < 3; i++ {
+		var prev *manifest
+		resp, err := h.client.Get(key, false, false)
+		if isNotFound(err) {
+			resp, err = h.client.Create(key, string(b), 0)
+		} else if err != nil {
+			return err
+		} else if prev, err = parseManifest(resp.Node); err != nil {
+			return err
+		} else {
+			resp, err = h.client.CompareAndSwap(key, string(b), 0, "", resp.Node.ModifiedIndex)
+		}
+
+		if err != nil {
+			if e, ok := err.(etcd.EtcdError); ok && (e.ErrorCode == 101 || e.ErrorCode == 105) {
+				continue
+			}
+			return err
+		}
+		m.Index = resp.Node.ModifiedIndex
+		if prev != nil && prev.Upload != m.Upload {
+			h.client.Delete(blobPath(name, uploadsNode, prev.Upload), true)
+		}
+		return nil
+	}
+	return errors.New("too many concurrent uploads")
+}
+
+// sweep removes the chunks of the uploads of a blob that were abandoned
+// before being published. Uploads stay pending for a while after they are
+// published, so that one published during the sweep is not removed.
+func (h *handler) sweep(name string) {
+	resp, err := h.client.Get(blobPath(name, manifestNode), false, false)
+	if err != nil {
+		return
+	}
+	m, err := parseManifest(resp.Node)
+	if err != nil {
+		return
+	}
+	uploads, err := h.client.Get(blobPath(name, uploadsNode), false, false)
+	if err != nil {
+		return
+	}
+	pending := make(map[string]bool)
+	if resp, err := h.client.Get(blobPath(name, pendingNode), false, false); err == nil {
+		for _, n := range resp.Node.Nodes {
+			pending[path.Base(n.Key)] = true
+		}
+	}
+
+	for _, n := range uploads.Node.Nodes {
+		if id := path.Base(n.Key); id != m.Upload && !pending[id] {
+			h.client.Delete(n.Key, true)
+		}
+	}
+}
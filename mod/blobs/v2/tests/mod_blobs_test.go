@@ -0,0 +1,134 @@
+package blobs
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/coreos/etcd/server"
+	"github.com/coreos/etcd/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+// Ensure that a blob larger than a chunk is split, reassembled as it was
+// uploaded and replaced without leaving the previous chunks behind.
+func TestModBlobsUpload(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		data := testBlob(1000000)
+		resp, _ := testPutBlob(s, "bundle", data, "chunkSize=262144")
+		assert.Equal(t, resp.StatusCode, 200)
+		body := tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["size"], float64(1000000))
+		assert.Equal(t, len(body["chunks"].([]interface{})), 4)
+		assert.Equal(t, body["sha256"], testChecksum(data))
+
+		resp, _ = tests.Get(fmt.Sprintf("%s/mod/v2/blobs/bundle", s.URL()))
+		assert.Equal(t, resp.StatusCode, 200)
+		assert.Equal(t, resp.Header.Get("Content-Type"), "application/octet-stream")
+		assert.Equal(t, resp.Header.Get("ETag"), `"`+testChecksum(data)+`"`)
+		assert.True(t, bytes.Equal(tests.ReadBody(resp), data))
+
+		data = testBlob(1000)
+		resp, _ = testPutBlob(s, "bundle", data, "")
+		assert.Equal(t, resp.StatusCode, 200)
+		tests.ReadBody(resp)
+
+		resp, _ = tests.Get(fmt.Sprintf("%s/mod/v2/blobs/bundle", s.URL()))
+		assert.True(t, bytes.Equal(tests.ReadBody(resp), data))
+
+		resp, _ = tests.Get(fmt.Sprintf("%s/v2/keys/_etcd/mod/blobs/bundle/uploads", s.URL()))
+		body = tests.ReadBodyJSON(resp)
+		assert.Equal(t, len(body["node"].(map[string]interface{})["nodes"].([]interface{})), 1)
+
+		resp, _ = tests.Get(fmt.Sprintf("%s/mod/v2/blobs/bundle/manifest", s.URL()))
+		assert.Equal(t, resp.StatusCode, 200)
+		body = tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["size"], 1000)
+		assert.NotNil(t, body["index"])
+
+		resp, _ = tests.Delete(fmt.Sprintf("%s/mod/v2/blobs/bundle", s.URL()), "", nil)
+		assert.Equal(t, resp.StatusCode, 200)
+		tests.ReadBody(resp)
+		resp, _ = tests.Get(fmt.Sprintf("%s/mod/v2/blobs/bundle", s.URL()))
+		assert.Equal(t, resp.StatusCode, 404)
+		tests.ReadBody(resp)
+	})
+}
+
+// Ensure that a blob whose chunks do not match the manifest is not returned.
+func TestModBlobsIntegrity(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		resp, _ := testPutBlob(s, "bundle", testBlob(100), "chunkSize=10")
+		body := tests.ReadBodyJSON(resp)
+
+		chunk := fmt.Sprintf("%s/v2/keys/_etcd/mod/blobs/bundle/uploads/%s/3", s.URL(), body["upload"])
+		resp, _ = tests.PutForm(chunk, url.Values{"value": {"AAAAAAAAAAAAAA=="}})
+		tests.ReadBody(resp)
+
+		resp, _ = tests.Get(fmt.Sprintf("%s/mod/v2/blobs/bundle", s.URL()))
+		assert.Equal(t, resp.StatusCode, 500)
+		assert.Contains(t, string(tests.ReadBody(resp)), "chunk 3 does not match its checksum")
+	})
+}
+
+// Ensure that the chunks of an abandoned upload are removed by the next upload.
+func TestModBlobsSweep(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		abandoned := fmt.Sprintf("%s/v2/keys/_etcd/mod/blobs/bundle/uploads/0123456789abcdef/0", s.URL())
+		resp, _ := tests.PutForm(abandoned, url.Values{"value": {"AAAA"}})
+		tests.ReadBody(resp)
+
+		resp, _ = testPutBlob(s, "bundle", testBlob(100), "")
+		assert.Equal(t, resp.StatusCode, 200)
+		tests.ReadBody(resp)
+
+		resp, _ = tests.Get(abandoned)
+		body := tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["errorCode"], 100)
+	})
+}
+
+// Ensure that invalid uploads are refused.
+func TestModBlobsInvalidUpload(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		resp, _ := tests.Put(fmt.Sprintf("%s/mod/v2/blobs/bundle", s.URL()), "application/x-www-form-urlencoded", strings.NewReader("value=foo"))
+		assert.Equal(t, resp.StatusCode, 415)
+		tests.ReadBody(resp)
+
+		resp, _ = testPutBlob(s, "bundle", testBlob(10), "sha256=abcd")
+		assert.Equal(t, resp.StatusCode, 400)
+		tests.ReadBody(resp)
+
+		resp, _ = testPutBlob(s, "bundle", testBlob(10), "chunkSize=0")
+		assert.Equal(t, resp.StatusCode, 400)
+		tests.ReadBody(resp)
+
+		resp, _ = tests.Get(fmt.Sprintf("%s/mod/v2/blobs/bundle", s.URL()))
+		assert.Equal(t, resp.StatusCode, 404)
+		tests.ReadBody(resp)
+	})
+}
+
+func testPutBlob(s *server.Server, name string, data []byte, query string) (*http.Response, error) {
+	return tests.Put(fmt.Sprintf("%s/mod/v2/blobs/%s?%s", s.URL(), name, query), "application/octet-stream", bytes.NewReader(data))
+}
+
+func testBlob(size int) []byte {
+	data := make([]byte, size)
+	r := rand.New(rand.NewSource(int64(size)))
+	for i := range data {
+		data[i] = byte(r.Intn(256))
+	}
+	return data
+}
+
+func testChecksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,102 @@
+'use strict';
+
+angular.module('etcdLogs', ['ngRoute'])
+
+.config(['$routeProvider', function ($routeProvider) {
+  $routeProvider
+    .when('/', {
+      templateUrl: 'views/logs.html',
+      controller: 'LogsCtrl'
+    })
+    .otherwise({
+      templateUrl: 'views/logs.html',
+      controller: 'LogsCtrl'
+    });
+}])
+
+.controller('LogsCtrl', ['$scope', '$http', '$q', '$timeout', function ($scope, $http, $q, $timeout) {
+  //only keep this many rows of each list
+  var maxRows = 500;
+  var since = 0;
+  var waitIndex = 0;
+  var canceler = null;
+
+  $scope.levels = ['debug', 'info', 'warning', 'fatal'];
+  $scope.level = 'info';
+  $scope.prefix = '/';
+  $scope.entries = [];
+  $scope.events = [];
+
+  function keep(list, rows) {
+    Array.prototype.unshift.apply(list, rows.reverse());
+    list.splice(maxRows, list.length);
+  }
+
+  function readLogs() {
+    $http.get('/v2/admin/logs', {params: {since: since, level: $scope.level}})
+      .success(function(data) {
+        $scope.logError = '';
+        if (data.length > 0) {
+          since = data[data.length - 1].index;
+          keep($scope.entries, data);
+        }
+      })
+      .error(function(data, status) {
+        $scope.logError = status === 403 ? 'The logs are only shown to admin clients.' : data;
+      });
+  }
+
+  function watchKeys() {
+    var params = {wait: true, recursive: true};
+    if (waitIndex > 0) {
+      params.waitIndex = waitIndex;
+    }
+    canceler = $q.defer();
+    var path = '/v2/keys/' + $scope.prefix.replace(/^\/+/, '');
+    $http.get(path, {params: params, timeout: canceler.promise})
+      .success(function(data) {
+        if (data.node) {
+          waitIndex = data.node.modifiedIndex + 1;
+          keep($scope.events, [data]);
+        }
+        watchKeys();
+      })
+      .error(function(data, status) {
+        //status 0 means the watch was canceled
+        if (status !== 0) {
+          $timeout(watchKeys, 1000);
+        }
+      });
+  }
+
+  $scope.resetLogs = function() {
+    since = 0;
+    $scope.entries = [];
+    readLogs();
+  };
+
+  $scope.resetEvents = function() {
+    if (canceler) {
+      canceler.resolve();
+    }
+    waitIndex = 0;
+    $scope.events = [];
+    watchKeys();
+  };
+
+  readLogs();
+  watchKeys();
+
+  // Update the log live
+  var poller = setInterval(function() {
+    readLogs();
+    $scope.$apply();
+  }, 1000);
+
+  $scope.$on('$destroy', function() {
+    clearInterval(poller);
+    if (canceler) {
+      canceler.resolve();
+    }
+  });
+}]);
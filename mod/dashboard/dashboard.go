@@ -20,7 +20,7 @@ func memoryFileServer(w http.ResponseWriter, req *http.Request) {
 
 	// TODO: use the new mux to do this work
 	dir, file := path.Split(upath)
-	if file == "browser" || file == "stats" {
+	if file == "browser" || file == "stats" || file == "logs" {
 		file = file + ".html"
 	}
 	upath = path.Join(dir, file)
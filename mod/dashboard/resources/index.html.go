@@ -8,7 +8,7 @@ import (
 	"unsafe"
 )
 
-var _index_html = "\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\xb4\x59\x6d\x6f\xe3\xc6\x11\xfe\xde\x5f\xc1\x30\xe8\xa7\x90\xd4\xbe\xbf\xf8\x24\x17\x57\x5f\x82\x04\x48\x8a\xa0\x41\x8b\x16\x41\x50\xd0\x14\x2d\xb1\x47\x8b\xaa\x48\xdb\x77\x57\xf4\xbf\xf7\x99\x5d\x52\xb6\x65\x59\x76\x2e\xad\x00\x9d\x38\xe4\xee\xec\xbc\x3c\xf3\xcc\xd0\x37\xff\x62\xd9\x55\xc3\xc7\x6d\x9d\xac\x87\xeb\xf6\xfc\x77\xf3\x2f\xf2\xfc\xe7\xe6\x2a\x69\x87\xe4\xbb\xaf\x13\xfb\xcb\x79\x12\x3e\x73\x7a\x9a\x54\x6d\xd9\xf7\x8b\x74\xd3\xe5\xff\xec\xb1\x22\x6f\x6a\x1f\x7f\x5c\xfc\xb1\xe9\x79\x32\xff\xe2\xe7\x7a\xb3\x6c\xae\x7e\xc9\xf3\x7b\x6d\x0f\x55\xbd\x42\xdb\x09\x35\xee\x35\x6a\x9e\xdb\xbf\x1a\x46\x15\x74\xe3\xfc\xc8\xfe\xb0\x31\xcf\x1f\x6d\xa6\x73\xea\x72\x49\x17\xb8\xbc\xae\x87\x32\xa9\xd6\xe5\xae\xaf\x87\x45\x7a\x33\x5c\xe5\xb0\xf6\xc1\xa3\xf5\x30\x6c\xf3\xfa\x5f\x37\xcd\xed\x22\xfd\x5b\xfe\x97\xb7\xf9\x45\x77\xbd\x2d\x87\xe6\xb2\xad\xd3\xa4\xea\x36\x43\xbd\xc1\xbe\xef\xbe\x5e\xd4\xcb\x55\x3d\xed\x1c\x9a\xa1\xad\xcf\xeb\xa1\x5a\x26\xcb\xb2\x5f\x5f\x76\xe5\x6e\x39\x9f\xc5\xbb\x0f\x74\x6f\xca\xeb\x7a\x91\x2e\xeb\xbe\xda\x35\xdb\xa1\xe9\x36\x0f\x34\xa6\x4f\x17\xde\x36\xf5\xdd\xb6\xdb\x0d\x0f\x56\xdd\x35\xcb\x61\xbd\x58\xd6\xb7\x4d\x55\xe7\x41\x98\xf6\xb5\xcd\xe6\x7d\xb2\xde\xd5\x57\x8b\x94\x5c\x38\x9b\xcd\xae\xb0\xa9\x2f\x56\x5d\xb7\x6a\xeb\x72\xdb\xf4\x45\xd5\x5d\xcf\xaa\xbe\xff\xc3\x55\x79\xdd\xb4\x1f\x17\x3f\x75\x37\xbb\xaa\xfe\xea\xa7\x72\xd3\x7f\xf5\xe3\xae\x3b\x13\x8c\x65\x12\x5f\x15\xbf\xcd\x50\xb6\x4d\x95\x19\x48\x16\x5f\xcf\x58\x9a\xec\xea\x76\x91\xf6\xc3\xc7\xb6\xee\xd7\x75\x0d\xc3\x08\x78\x8b\x74\xa8\x3f\x0c\xa4\xf9\x37\xdb\x72\xd1\x2d\xeb\x60\x0b\xd9\xa0\xf1\x1d\x4f\x7f\xf5\xc9\x61\x45\xbc\xa6\xcf\x65\xb7\xfc\x98\xfc\x7b\x2f\xd2\x67\x5b\x2e\x97\xcd\x66\x75\x96\x48\xb6\xfd\xf0\xe6\xd1\xa3\xeb\x72\xb7\x6a\x36\x67\xc9\xa3\x07\xff\xd9\x5f\xad\xf9\x81\x2a\x72\x2a\x8f\x0e\x9c\x25\x69\x74\x21\xa1\x70\x26\x70\x21\xcd\x92\xf4\xdb\xba\xbd\xad\x87\xa6\x2a\x93\x3f\xd5\x37\x35\xee\xec\x6f\x64\xc9\xdb\x5d\x53\xb6\x59\xd2\x63\x79\xde\xd7\xbb\xe6\xea\xcd\x53\xdd\x77\x75\xb3\x5a\x0f\x67\x09\xa2\xf1\xac\xa5\xe1\x2b\xc6\x8b\x37\xc7\x5d\x7d\xc6\xa1\xe6\x6a\x07\x9c\x1d\x38\x75\xd9\xed\x96\xf5\xee\x2c\xd9\x74\x9b\xfa\xe1\xa6\xfd\x65\x79\xb0\xa1\xea\xda\x0e\xeb\xbf\xe4\xb5\xa9\x2b\xfe\xd8\x02\x4a\x4f\xbe\xac\xab\x6e\x57\x12\xdc\x4f\x69\x3d\x5b\x77\xb7\xf5\xee\x40\xf7\x93\xfd\x37\x1b\x18\x07\x78\x1d\x57\x72\xd4\xa1\x18\xab\xfc\xb2\x1b\x86\xee\xfa\x30\xed\x4f\x37\x7f\xf5\x2a\x2d\xcf\x29\xf9\xf2\xaa\xeb\x86\x27\x6e\x84\x42\x3d\x4b\x38\x63\xbf\x3f\x92\xe5\xff\x13\x82\x46\x8b\x87\x6e\x7b\x16\xf0\x71\xd4\xde\x60\x33\xc2\x5b\x77\x7d\xde\x76\xab\xee\xc0\xf0\x7b\x3d\x64\x3d\x30\x56\xde\x0c\x5d\xc2\xc2\xcf\x9b\x27\x2b\xd7\x23\x5c\x9f\x56\xd6\x83\x20\xb8\x27\x0f\x0f\xcc\x79\x62\x52\x7f\xbb\x3a\x62\x56\x08\x5f\xd3\xb6\x40\x9e\xf7\xfe\xe9\x71\xd1\xf4\x0f\xf9\x7d\xec\x8f\x19\x45\x9f\x65\xd3\x6f\xdb\x12\x09\x68\x36\x84\xac\xfc\xb2\xed\xaa\xf7\xc7\x97\x02\xa1\x14\xfb\x36\x07\x35\xae\x10\x93\xeb\x66\xb9\x6c\xeb\xa7\x6b\x0f\x23\xbc\xed\xee\xea\x5d\xbd\xcc\x2f\x0f\xd9\x28\x78\x41\x20\xe8\x9b\x4f\x35\xac\x14\xc7\x8c\x9c\x2a\x4c\x4a\xf9\x6c\x58\x9f\x62\xeb\x57\xb8\xf6\x5a\xb7\x82\x8e\x29\xcb\xdc\x1f\x3b\x31\x54\xec\xa8\xa6\x42\xcb\xaa\x77\x87\xc9\xa6\x7f\xe7\xb3\x91\xa8\xe7\xb3\xd8\x9a\xe7\xc4\xd4\x23\x87\xaf\x79\xec\xa4\xef\xee\x3b\x29\x6e\xc5\x67\x63\x6d\xf6\xbb\x8a\xba\x41\x89\x9e\x42\x23\x40\x9a\x04\x75\x63\x7b\x1c\x83\xb1\x87\xa3\x0a\xa9\x4f\xcf\xe7\xb3\xb8\xfb\x88\xaa\xcb\x5d\x77\x87\x12\xfa\x7c\x65\xcb\xe6\x36\x69\x96\x8b\x34\x96\x7f\x7a\xdf\x81\xf6\x4f\xee\x21\x90\x9e\xff\x18\xaf\x13\xc0\x61\x5e\x3e\x68\x94\x3d\x3a\xe5\xaa\x19\xd6\x37\x97\xb1\x3f\x86\x22\x98\x51\x30\xd2\x10\x92\xf9\xac\xc4\xc1\xd0\x78\x44\xff\x83\x8a\x79\x70\x7c\x58\x52\x3e\xee\xc5\x71\x25\x9d\x70\xb0\x30\x2c\xa6\x6a\x03\x1e\x7a\xd0\xed\x22\xe5\x05\x4f\x83\xf6\xef\xcb\x8f\xf5\xee\x1f\x10\x3e\x5c\xb7\x9b\x7e\xaf\xea\xee\xee\xae\xb8\x93\x45\xb7\x5b\xcd\x30\x3d\xb0\x19\x36\x8f\x4b\xce\x3e\xd0\x10\x70\x6c\x21\x47\xb9\xce\xc2\x53\x2c\x5d\xa4\x08\x67\x9a\x7c\x8c\xbf\x47\x6b\x2e\xd9\xee\x6a\xe4\xe6\xb6\x7e\xdb\x6f\xeb\x6a\xf8\x33\x75\x82\x45\xfa\xe1\x87\x66\xf3\x77\x7c\xd3\x84\xa6\xa4\x3f\x76\xa4\x09\xc4\x64\xbd\x00\xfd\x98\x34\xa9\x37\x25\x26\xb6\xfc\xb2\xac\xde\xaf\x76\x1d\xda\x06\x06\xc4\xfa\x2e\x79\xb4\x06\x86\x9e\xf5\xdb\xb2\x42\xae\xa7\x33\x8e\x45\x64\xf5\xf4\xde\xa9\xfb\xe1\x19\x26\xc6\x75\xa0\xa7\x45\xfa\xa5\x96\x6f\xe5\xbb\xb7\x69\x02\x13\x7e\xe0\xd2\x14\xdc\xb8\x4c\xe9\x42\x0b\x7b\x61\x4d\xe1\xfc\x24\x65\xc2\x15\xc6\xf9\xcc\xcb\xc2\x4a\x3f\x49\x5c\xcb\x0a\xb3\x90\x2f\x84\xd1\x99\x72\x85\x60\xb8\xc7\x6c\xa1\xac\x1a\x7f\xf7\xf2\xb3\xe6\xd0\xa7\x22\x15\x5a\x64\x2c\xae\x36\x3a\x7f\xa8\x0c\xe2\xa8\xe4\x42\x28\x59\x18\x29\x27\x33\xb8\xd7\x85\x12\x93\x89\x8f\xed\xff\x94\xce\x5e\x19\x83\x6f\xb8\x61\xe6\xdd\xe3\x18\x68\x5d\x48\xe7\xab\x9c\xdb\x42\x38\x99\xb1\x5c\xf2\xc2\x2b\x9e\x09\x5b\x18\xa5\x73\x89\xdb\x52\x67\xc6\x14\xcc\x60\x15\xc3\x1e\x58\x83\x7b\x06\xd7\xa0\xc2\x4c\x14\xca\x59\x5c\x2b\xeb\x32\xd8\xaa\xcc\x69\xff\xb1\xd2\x0a\x99\x41\x9d\xb2\x39\xf4\x70\x99\x71\x01\x45\xf0\xbc\x50\xda\x65\xdc\x15\x8e\xb9\x70\x10\x47\x2e\x0a\x6f\x04\x5d\x5b\x9d\xe9\xc2\x6b\x33\x5e\xbb\xc2\x3b\x87\x84\xc8\x82\x09\x9f\xb1\x82\x61\xa7\x19\xaf\xa7\xe7\xfa\xb4\x21\xac\x10\x0a\x7e\xc1\x6e\x64\x03\x31\xd6\x64\x87\x35\x48\xe7\xde\x0e\xce\x2b\x57\x70\x46\xb7\x98\x82\xd7\x06\xcf\xc9\x79\x6b\x10\x1e\x0d\x3b\xc9\x40\xe6\x7d\x25\x0b\x8f\x6b\x1c\xad\x4c\x66\x0b\x17\x34\x0a\xc4\x86\xf3\xf1\x01\x84\xd3\xd6\x28\x18\x8f\xe0\x67\x0e\x0e\x32\xe7\x68\xa7\xf3\x3a\x0f\x3b\x2b\x4f\xc7\x87\x70\xe3\x81\x2d\x2c\x73\x08\x16\xd3\xc1\x06\xee\xf3\x68\x03\xc3\x86\x10\x2a\x4e\xf6\x5a\x1b\xf6\x02\xbf\x85\xd1\x61\xaf\x71\xa7\x2d\x90\x48\x9e\x56\xb9\x42\x0a\x33\x1d\x92\x9e\x73\x8d\x1f\x31\x49\x00\x84\x12\xee\x42\xd0\x42\x6b\x32\xef\xa3\xa1\x1e\x06\x5b\x3e\xc2\x28\x7b\x8c\xaa\x5f\x81\xcc\xf0\x19\x91\x89\x7a\xd4\x40\x20\x87\x7f\x3a\x82\xce\x5b\x4f\xf8\x10\x8e\x9c\x15\xe4\xb2\xf5\x26\x97\x08\xb8\x83\xc5\xc8\x53\xee\x02\x3a\x38\x2b\xb4\xb6\xb9\x10\x85\xf0\x36\x40\xda\x44\x08\x0b\x35\x4a\x2f\xa0\x53\x15\x0e\x41\x63\xb9\x27\xd5\xc8\xa5\x62\x50\x13\xb3\x08\x44\x78\x46\x85\x82\x70\x9b\x4c\x15\xda\xe1\x3c\x94\x8a\xcd\xfc\x98\x06\xe5\x01\x22\xd4\xa9\x0f\xf8\x55\x08\x1d\x56\x69\x8e\x6b\x03\xd6\xf0\x85\xf4\xd3\x35\x57\x10\xfc\x49\x5b\xfa\x08\x21\xe8\x56\x84\xea\xfd\x2e\x53\x91\x52\x95\xc5\x03\xa0\xd4\xd9\xf0\x58\xd1\x63\xc5\x44\x14\x2a\x5d\x30\xb0\x0a\x0b\xfb\x45\xc0\x82\x18\xf7\x07\x13\x5e\x60\x29\xc4\x91\x71\x32\x96\x3c\x02\x03\x6a\x09\xf7\x1c\x0a\x4b\x00\x1f\x56\xe7\x70\x12\x41\xa7\x22\x36\x78\xa0\x19\xc7\x39\x06\x31\xd6\x85\xe1\x7a\xbc\x0e\x35\x78\xc1\x9d\x42\x0d\xa3\x14\x14\x14\x90\x0d\x0e\xc8\xa6\xfa\x02\x8c\x24\xe1\xe7\x71\xb2\x3f\x3d\xd3\x7d\xc6\xcf\x33\x70\x9a\xcf\x7e\x6b\x77\x10\x92\x7f\x23\x58\xc4\x9f\x54\x40\x81\x87\x49\x8c\x88\x45\x56\xe0\x05\xed\x34\x62\x09\x58\x39\x6e\xc0\x19\x1c\x85\x28\xa8\x17\xc0\x1f\x50\x90\x11\x2d\x21\x8f\x51\x0e\x78\x21\xc5\x4b\x14\x88\xda\x40\x40\x75\xc1\x51\xb0\xd8\x00\xc2\x42\xb0\x00\x9e\x9c\x53\xa5\xf3\x28\x00\x68\x66\x04\xa3\x20\x70\x53\x08\x51\x5a\x93\x40\xa0\xf6\x1c\x14\x28\x64\xa1\x54\x20\x0c\x62\x66\xdc\x36\xcc\x51\xd7\x42\xac\xa3\x70\xda\x18\x00\x5d\x38\x6a\x47\x3a\x10\x0e\xd5\x36\xb2\x0c\x02\xe1\x96\x7c\x12\xc2\xb5\xc1\x35\x3b\x9e\x40\x95\x06\xb6\x46\x8e\xb5\x77\xc1\x60\x22\x25\xe4\x18\x7b\x03\x11\x45\xa1\xca\x05\xc2\xc4\xc9\x7a\x05\xda\x94\x3c\x27\x42\x75\x93\xa0\x89\xb8\x4f\xd7\xe2\x05\x2a\xb8\x90\x38\x56\xe0\x78\x69\x32\x09\x05\x4a\xab\x29\x2b\xd9\x41\x96\x5e\x4f\x35\x0f\x53\xad\x10\x63\x6f\x22\xfa\x2c\xe3\x15\xfc\x91\xa1\x3b\x83\xc6\x9c\x08\x15\x63\xbd\x9c\x24\x05\x76\xf1\x96\x62\x0e\x9c\xab\xc0\x2f\x0a\xd4\xa7\xa8\x14\x7d\x7e\xbf\x08\xd2\x0b\x08\xc0\x39\xdc\x5b\x6a\xb6\x06\xc5\x98\x87\x63\xc4\x28\x44\x05\x17\xd2\x81\xb4\x51\x58\x9c\x64\xaf\x32\x89\x70\x84\xf6\x14\x4d\xcd\x0e\x4c\xff\x94\xdc\x3b\xe3\x89\x96\x5e\x60\x3a\x02\x2a\x35\x1c\x44\x55\xe8\x31\xd3\xa3\x10\xbd\xab\x18\xf1\xbf\x46\x23\x81\x56\x91\x87\x7e\xa7\xf2\xfd\x0a\x08\x3d\x49\xcc\x53\x4a\xb0\xdb\x4e\x52\x7c\x78\x3a\xb7\xd2\x03\x0d\x84\x29\x17\x90\x86\xa0\xe1\x4c\xb9\x37\x3d\x3b\x70\xe5\x33\x93\x8b\x59\xcb\x5a\x8a\x90\x45\x01\xfb\x35\x01\x53\xc9\x16\x43\x87\xa1\xb2\x35\xce\xad\x41\x85\xc2\x83\x2b\x35\x0b\xbd\x83\x58\x1e\x0f\x40\xd3\xd1\x75\x2a\x04\x67\x27\xe9\x85\xfe\x59\x78\x49\x85\x84\x89\x42\x6b\x62\x67\x4c\x01\x61\x86\x40\x37\xd6\x56\xb5\xb1\x5b\x51\x9d\x59\xa9\x50\x1e\x85\xa1\x16\x6d\x5d\x68\xb9\x3c\xcc\x42\xe8\x5c\x08\x21\x77\xf1\xba\xca\x89\x68\xa8\x82\x60\x93\xc6\x85\xa2\x89\x25\x66\xc0\xc4\x7a\x95\xb7\x0a\x34\xc0\x4e\xcf\x16\xeb\x98\x18\xf1\xd7\x31\x0e\x9f\x17\x4c\x70\x34\x98\xe2\xbe\x52\x50\x95\x3e\x10\xc7\x38\x2e\xc2\x1e\x8c\xae\x93\x14\x90\x6c\xc3\x80\x86\x61\x8a\xc6\x0f\x4c\x25\xb8\x07\x04\x91\xcf\x36\x0b\x14\xb7\xce\x15\xf8\xe3\x85\x5a\xa1\xe8\x51\x1d\x32\x26\x09\x68\x12\x83\x36\x65\x05\x5c\x17\xe8\x98\x8f\x12\x9a\x91\xe1\x01\xd0\xe8\xfd\x96\xda\x15\x23\xab\x2c\x99\x43\x6d\x09\x1c\x4d\xe1\xd4\xaf\x63\x68\x30\x0f\x3a\x1f\x08\xc6\x3a\xa2\x61\x83\x79\x07\xb4\x07\x4d\x60\x35\x6a\xbb\x41\x40\x12\x31\x93\x60\x3c\x44\x19\x23\xc2\x2e\x1f\x69\x30\x5c\x8f\x55\xac\x19\xb5\x35\xaa\x62\x0a\x5f\xa6\x85\x0c\x83\xde\x54\xc4\x07\x51\xfd\x74\xd2\xac\xe4\x07\xcc\x36\x64\x3c\x82\x0d\x9e\xb2\x86\x4a\x94\xbc\xb5\xd4\x4b\x9c\x27\x56\x31\x11\xad\x56\x8a\x51\xaa\x68\x94\x11\x9a\x50\x84\x92\xf2\xd4\xbd\xac\x71\x04\x09\x0a\x6b\x58\xf2\xed\xa8\xf6\xf3\xde\x23\x0c\xf8\xd7\x60\xec\xe0\x1a\x90\x66\x44\x1b\xe8\x03\x16\x93\x38\x4d\xaa\x4e\x13\xd5\x83\xaa\x33\x45\x23\x9b\x1f\xa5\x0a\x63\x84\x33\x94\x2d\xdc\x36\xf4\xb2\xc1\xc2\xe0\x30\x4a\x71\xd1\x0b\xe3\x7b\x86\x63\x1c\x39\x8d\x63\x50\xa9\x1a\xcd\x51\xe6\x93\x02\x12\x2e\x0c\x9c\xa4\xe9\x48\x30\x6a\x3a\x36\xdb\x5b\xea\x68\x46\x33\xd9\x81\xe5\x60\x50\x63\x09\x97\xd3\x8d\x17\x0c\xa0\x4e\x6c\xc0\xfb\xd4\x96\xf5\xbe\xb3\x49\x0c\x5e\x7e\x14\x02\x40\x98\x0b\x00\xc1\x28\xad\x02\xc1\x32\x2f\x27\x69\x5c\xc5\xa8\x5d\x63\x9e\x8d\x0d\x1e\xa1\x42\x99\x02\x13\xd3\xa2\x20\x9d\xa6\x53\xcc\x73\x60\x73\x01\x4f\x59\x68\xe7\x93\x1f\x8e\xf0\x29\xb3\xc7\x6e\xbd\x3e\xcd\x78\x65\x56\xef\xfc\x98\x66\xcc\xfb\x96\x5a\xbd\x33\x84\xa7\xca\xd2\xa0\x1d\xcb\x98\xbc\x52\x5c\x4c\x45\x2a\xd1\x25\x85\x1f\xa5\x2a\x82\x31\x63\xe3\x0b\x53\x98\x6d\xfc\x24\x20\xed\xd6\xbc\x18\x67\x60\xdc\x33\x15\x76\x86\x29\x9e\xeb\xd0\x87\xf0\x1a\x99\xc7\x41\xa5\x8d\x90\x37\x28\x5b\xe6\x44\x78\x17\x30\xe8\xc7\x20\x64\x43\x47\x78\xa9\x88\xdc\x25\xc0\x38\x4a\xe1\xf8\x17\x06\x23\x16\xca\x59\xd1\x00\x12\x8e\x17\x04\x1a\x41\xde\x31\xbc\x50\x45\x29\x8e\x86\x2e\x8e\x86\x06\x53\xad\x0e\x73\x3a\x8d\x68\x14\x6f\xbc\x11\x48\xdd\xe6\xc4\xdb\x2a\x92\x09\xcd\x7e\xde\x89\x9c\x9a\x84\xa0\x03\xa8\x4e\x11\x84\x9c\xe6\x6a\x16\xfa\xf0\x0b\xe1\xc8\x63\x83\xa2\xa9\x30\x4e\xa3\xf4\x72\xe6\x27\x81\xca\xda\x68\x20\x8a\xde\x59\x68\x3a\x0d\x6e\xd3\x9b\x2b\x21\x8a\xd8\x10\x93\x14\xaa\x1e\x93\x79\x1b\x62\x46\xaf\xcd\xfe\x05\x78\xd1\xac\xa0\x9c\xc9\x68\x60\x1d\x67\xc3\xf0\x6a\x8b\x21\x61\x92\xa8\xb3\x48\xdf\xe7\x61\x1a\x0e\x7f\x3b\x70\x78\xef\xc1\x88\x2f\xd8\x24\x55\xf9\xd8\x22\x18\x91\x28\x46\x5e\x8a\x01\xd6\x20\xc8\x14\x04\xea\xb0\xe6\xfb\x03\x94\xfd\x0f\xdf\x06\x9e\xb9\xd9\xdf\x1e\xdc\xa6\x3f\xee\xdd\xff\x59\xef\xfe\xaf\x7c\xe3\xe5\x7c\x16\xff\x44\x3a\x9f\xc5\xff\xe7\xfd\x6f\x00\x00\x00\xff\xff\x81\x9d\x08\x43\xf8\x1d\x00\x00"
+var _index_html = "\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\x03\xbd\x59\x6b\x6f\xeb\xc6\x11\xfd\xde\x5f\xc1\x30\xe8\xa7\x90\xd4\xbe\x1f\xbe\x96\x8b\x5b\xdf\x04\x09\x90\x14\x41\x83\x16\x2d\x82\xa0\xa0\x29\x5a\x62\x43\x8b\xaa\x48\xdb\xd7\xb7\xe8\x7f\xef\x99\x5d\x52\xb6\x65\x59\x72\x92\xa2\x06\x04\xed\x70\x77\x67\x67\x66\xcf\x9c\x19\xca\xe7\x9f\x2d\xba\x6a\x78\xd8\xd4\xc9\x6a\xb8\x69\x2f\x7e\x77\xfe\x59\x9e\xff\xd8\x5c\x27\xed\x90\x7c\xf3\x65\x62\x7f\xba\x48\xc2\xdf\x39\xcd\x26\x55\x5b\xf6\xfd\x3c\x5d\x77\xf9\x3f\x7b\xac\xc8\x9b\xda\xc7\x2f\x17\xbf\x6c\x7a\x91\x9c\x7f\xf6\x63\xbd\x5e\x34\xd7\x3f\xe5\xf9\xa3\xb6\xa7\xaa\xde\xa0\xed\x88\x1a\xf7\x16\x35\xaf\xed\x5f\x0e\xa3\x0a\x7a\x70\x71\x60\x7f\xd8\x98\xe7\xcf\x36\xd3\x39\x75\xb9\xa0\x01\x86\x37\xf5\x50\x26\xd5\xaa\xdc\xf6\xf5\x30\x4f\x6f\x87\xeb\x1c\xd6\x3e\x99\x5a\x0d\xc3\x26\xaf\xff\x75\xdb\xdc\xcd\xd3\xbf\xe5\x7f\x79\x9f\x5f\x76\x37\x9b\x72\x68\xae\xda\x3a\x4d\xaa\x6e\x3d\xd4\x6b\xec\xfb\xe6\xcb\x79\xbd\x58\xd6\xd3\xce\xa1\x19\xda\xfa\xa2\x1e\xaa\x45\xb2\x28\xfb\xd5\x55\x57\x6e\x17\xe7\xb3\xf8\xf4\x89\xee\x75\x79\x53\xcf\xd3\x45\xdd\x57\xdb\x66\x33\x34\xdd\xfa\x89\xc6\xf4\xe5\xc2\xbb\xa6\xbe\xdf\x74\xdb\xe1\xc9\xaa\xfb\x66\x31\xac\xe6\x8b\xfa\xae\xa9\xea\x3c\x08\xd3\xbe\xb6\x59\xff\x9c\xac\xb6\xf5\xf5\x3c\x25\x17\xce\x66\xb3\x6b\x6c\xea\x8b\x65\xd7\x2d\xdb\xba\xdc\x34\x7d\x51\x75\x37\xb3\xaa\xef\xff\x70\x5d\xde\x34\xed\xc3\xfc\x87\xee\x76\x5b\xd5\x5f\xfc\x50\xae\xfb\x2f\xbe\xdf\x76\x67\x82\xb1\x4c\xe2\xa3\xe2\xa7\x19\xca\xb6\xa9\x32\x03\xc9\xe2\xe3\x19\x4b\x93\x6d\xdd\xce\xd3\x7e\x78\x68\xeb\x7e\x55\xd7\x30\x8c\x80\x37\x4f\x87\xfa\xe3\x40\x9a\x7f\xb3\x2d\x97\xdd\xa2\x0e\xb6\x90\x0d\x1a\x9f\xf1\xf4\x37\x9f\x1c\x56\xc4\x31\xfd\x5d\x75\x8b\x87\xe4\xdf\x3b\x91\xfe\x36\xe5\x62\xd1\xac\x97\x67\x89\x64\x9b\x8f\xef\x9e\x4d\xdd\x94\xdb\x65\xb3\x3e\x4b\x9e\x4d\xfc\x67\x37\x5a\xf1\x3d\x55\xe4\x54\x1e\x1d\x38\x4b\xd2\xe8\x42\x42\xe1\x4c\xe0\x42\x9a\x25\xe9\xd7\x75\x7b\x57\x0f\x4d\x55\x26\x7f\xaa\x6f\x6b\x3c\xd9\x3d\xc8\x92\xf7\xdb\xa6\x6c\xb3\xa4\xc7\xf2\xbc\xaf\xb7\xcd\xf5\xbb\x97\xba\xef\xeb\x66\xb9\x1a\xce\x12\x44\xe3\x55\x4b\xc3\x47\x8c\x83\x77\x87\x5d\x7d\xc5\xa1\xe6\x7a\x0b\x9c\xed\x39\x75\xd5\x6d\x17\xf5\xf6\x2c\x59\x77\xeb\xfa\xe9\xa6\xdd\xb0\xdc\xdb\x50\x75\x6d\x87\xf5\x9f\xf3\xda\xd4\x15\x7f\x6e\x01\x5d\x4f\xbe\xa8\xab\x6e\x5b\x12\xdc\x8f\x69\x3d\x5b\x75\x77\xf5\x76\x4f\xf7\x8b\xfd\xb7\x6b\x18\x07\x78\x1d\x56\x72\xd0\xa1\x18\xab\xfc\xaa\x1b\x86\xee\x66\xff\xda\x5f\x6e\xfe\xe2\x4d\x5a\x5e\x53\xf2\xf9\x75\xd7\x0d\x2f\xdc\x08\x89\x7a\x96\x70\xc6\x7e\xff\xee\xff\x86\xa0\xd1\xe2\xa1\xdb\x9c\x05\x7c\x1c\xb4\x37\xd8\x8c\xf0\xd6\x5d\x9f\xb7\xdd\xb2\xdb\x33\xfc\x29\xd6\x38\x61\xac\xbc\x1d\xba\x84\x85\xaf\x77\x2f\x56\xae\x46\xb8\xbe\xcc\xac\x27\x41\x70\x2f\x26\xf7\xcc\x79\x61\x52\x7f\xb7\x3c\x60\x56\x08\x5f\xd3\xb6\x40\x9e\xf7\xfe\xdd\xc1\xf9\x9b\xf2\x63\xfe\x18\xfb\x43\x46\xd1\xdf\xa2\xe9\x37\x6d\x89\x0b\x68\xd6\x84\xac\xfc\xaa\xed\xaa\x9f\x0f\x2f\x05\x42\x29\xf6\x6d\x0e\x6a\x5c\x22\x26\x37\xcd\x62\xd1\xd6\x2f\xd7\xee\x47\x78\xd3\xdd\xd7\xdb\x7a\x91\x5f\x3d\x1c\xf0\x24\x80\xa0\x6f\x3e\xd5\xb0\x52\x1c\x32\x72\xca\x30\x29\xe5\xab\x61\x7d\x89\xad\x5f\xe0\xda\x5b\xdd\x0a\x3a\xa6\x5b\xe6\xfe\xd0\x89\x21\x63\x47\x35\x15\x4a\x56\xbd\xdd\xbf\xec\xc0\xd3\xb3\x91\xa8\xcf\x67\xb1\x34\x9f\x13\x53\x8f\x1c\xbe\xe2\xb1\x92\x7e\x78\xac\xa4\x78\x14\xe7\xc6\xdc\xec\xb7\x15\x55\x83\x12\x35\x85\x5a\x80\x34\x09\xea\xc6\xf2\x38\x06\x63\x07\x47\x15\xae\x3e\xbd\x38\x9f\xc5\xdd\x07\x54\x5d\x6d\xbb\x7b\xa4\xd0\xff\x46\x19\x40\xfb\x1b\xcc\x5a\x34\x77\x49\xb3\x98\xa7\x91\x48\xd2\xc7\x5a\xb6\x9b\x79\x04\x53\x7a\xf1\x7d\x1c\x27\x00\xd6\x79\xf9\xa4\xe4\xf6\xa8\xb9\xcb\x66\x58\xdd\x5e\xc5\x4a\x1b\xd2\x69\x46\x61\x4d\x43\x70\xcf\x67\x25\x0e\x86\xc6\x03\xfa\x9f\xe4\xde\x93\xe3\xc3\x92\xf2\x79\x55\x8f\x2b\xe9\x84\xbd\x85\xb1\x16\x23\x6f\x81\xac\x1e\xc4\x3d\x4f\x79\xc1\xd3\xa0\xfd\xdb\xf2\xa1\xde\xfe\x03\xc2\xc7\x9b\x76\xdd\xef\x54\xdd\xdf\xdf\x17\xf7\xb2\xe8\xb6\xcb\x19\xfa\x10\x36\xc3\xe6\x71\xc9\xd9\x47\x6a\x27\x0e\x2d\xe4\x48\xfc\x59\x98\xc5\xd2\x79\x8a\x70\xa6\xc9\x43\xfc\x3e\x98\xbd\xc9\x66\x5b\xe3\x96\xef\xea\xf7\xfd\xa6\xae\x86\x3f\x53\x4d\x99\xa7\x1f\xbf\x6b\xd6\x7f\xc7\x27\x4d\xa8\xdf\xfa\x63\x47\x9a\x40\x71\xd6\x0b\x10\x99\x49\x93\x7a\x5d\xa2\xf7\xcb\xaf\xca\xea\xe7\xe5\xb6\x43\x01\x42\xab\x59\xdf\x27\xcf\xd6\xc0\xd0\xb3\x7e\x53\x56\xb8\xeb\xe9\x8c\x43\x11\x59\x5e\x1c\xb4\xeb\xb5\xe7\x61\x0e\xbd\xe7\x2a\x10\xdd\x3c\xfd\x5c\xcb\xf7\xf2\xc3\xfb\x34\x81\x09\xdf\x71\x69\x0a\x6e\x5c\xa6\x74\xa1\x85\xbd\xb4\xa6\x70\x7e\x92\x32\xe1\x0a\xe3\x7c\xe6\x65\x61\xa5\x9f\x24\xae\x65\x85\xae\xca\x17\xc2\xe8\x4c\xb9\x42\x30\x3c\x63\xb6\x50\x56\x8d\xdf\x3b\xf9\x55\x73\x02\x1d\x91\x0a\x2d\x32\x16\x57\x1b\x9d\x3f\x55\x06\x71\x54\x72\x29\x94\x2c\x8c\x94\x93\x19\xdc\xeb\x42\x89\xc9\xc4\xe7\xf6\x7f\x4a\x67\x6f\x8c\xc1\x57\xdc\x30\xf3\xe1\x79\x0c\xb4\x2e\xa4\xf3\x55\xce\x6d\x21\x9c\xcc\x58\x2e\x79\xe1\x15\xcf\x84\x2d\x8c\xd2\xb9\xc4\x63\xa9\x33\x63\x0a\x66\xb0\x8a\x61\x0f\xac\xc1\x33\x83\x31\x48\x35\x13\x85\x72\x16\x63\x65\x5d\x06\x5b\x95\x39\xee\x3f\x56\x5a\x21\x33\xa8\x53\x36\x87\x1e\x2e\x33\x2e\xa0\x08\x9e\x17\x4a\xbb\x8c\xbb\xc2\x31\x17\x0e\xe2\xb8\x8b\xc2\x1b\x41\x63\xab\x33\x5d\x78\x6d\xc6\xb1\x2b\xbc\x73\xb8\x10\x59\x30\xe1\x33\x56\x30\xec\x34\xe3\x78\x9a\xd7\xc7\x0d\x61\x85\x50\xf0\x0b\x76\xe3\x36\x10\x63\x4d\x76\x58\x83\xeb\xdc\xd9\xc1\x79\xe5\x0a\xce\xe8\x11\x53\xf0\xda\x60\x9e\x9c\xb7\x06\xe1\xd1\xb0\x93\x0c\x64\xde\x57\xb2\xf0\x18\xe3\x68\x65\x32\x5b\xb8\xa0\x51\x20\x36\x9c\x8f\x13\x10\x8e\x5b\xa3\x60\x3c\x82\x9f\x39\x38\xc8\x9c\xa3\x9d\xce\xeb\x3c\xec\xac\x3c\x1d\x1f\xc2\x8d\x09\x5b\x58\xe6\x10\x2c\xa6\x83\x0d\xdc\xe7\xd1\x06\x86\x0d\x21\x54\x9c\xec\xb5\x36\xec\x05\x7e\x0b\xa3\xc3\x5e\xe3\x8e\x5b\x20\x71\x79\x5a\xe5\x0a\x57\x98\xe9\x70\xe9\x39\xd7\xf8\x12\x93\x04\x40\x28\xe1\x2e\x05\x2d\xb4\x26\xf3\x3e\x1a\xea\x61\xb0\xe5\x23\x8c\xb2\xe7\xa8\xfa\x05\xc8\x0c\x7f\x23\x32\x91\x8f\x1a\x08\xe4\xf0\x4f\x47\xd0\x79\xeb\x09\x1f\xc2\x91\xb3\x82\x5c\xb6\xde\xe4\x12\x01\x77\xb0\x18\xf7\x94\xbb\x80\x0e\xce\x0a\xad\x6d\x2e\x44\x21\xbc\x0d\x90\x36\x11\xc2\x42\x8d\xd2\x09\x74\xaa\xc2\x21\x68\x2c\xf7\xa4\x1a\x77\xa9\x18\xd4\xc4\x5b\x04\x22\x3c\xa3\x44\x41\xb8\x4d\xa6\x0a\xed\x70\x1e\x52\xc5\x66\x7e\xbc\x06\xe5\x01\x22\xe4\xa9\x0f\xf8\x55\x08\x1d\x56\x69\x8e\xb1\x01\x6b\xf8\x42\xfa\x69\xcc\x15\x04\x7f\xd4\x96\x3e\x42\x08\xba\x15\xa1\x7a\xb7\xcb\x54\xa4\x54\x65\xf1\x00\x28\x75\x36\x4c\x2b\x9a\x56\x4c\x44\xa1\xd2\x05\x03\xab\xb0\xb0\x5f\x04\x2c\x88\x71\x7f\x30\xe1\x04\x4b\x21\x8e\x8c\x93\xb1\xe4\x11\x18\x50\x4b\xb8\xe7\x90\x58\x02\xf8\xb0\x3a\x87\x93\x08\x3a\x25\xb1\xc1\x84\x66\x1c\xe7\x18\xc4\x58\x17\x86\xeb\x71\x1c\x72\xf0\x92\x3b\x85\x1c\x46\x2a\x28\x28\x20\x1b\x1c\x90\x4d\xf9\x05\x18\x49\xc2\xcf\xf3\xcb\xfe\x94\x1e\x35\xec\x15\x38\x9d\xcf\x7e\x6b\x75\x10\x92\x7f\x25\x58\xc4\x9f\x54\x40\x81\x87\x49\x8c\x88\x45\x56\xe0\x05\xed\x34\x62\x09\x58\x39\x6e\xc0\x19\x1c\x89\x28\xa8\x16\xc0\x1f\x50\x90\x11\x2d\x21\x8f\xd1\x1d\xf0\x42\x8a\x53\x14\x88\xdc\x40\x40\x75\xc1\x91\xb0\xd8\x00\xc2\x42\xb0\x00\x9e\x9c\x53\xa6\xf3\x28\x00\x68\x66\x04\xa3\x20\x70\x53\x08\x91\x5a\x93\x40\xa0\xf6\x1c\x14\x28\x64\xa1\x54\x20\x0c\x62\x66\x3c\x36\xcc\x51\xd5\x42\xac\xa3\x70\xdc\x18\x00\x5d\x38\x2a\x47\x3a\x10\x0e\xe5\x36\x6e\x19\x04\xc2\x2d\xf9\x24\x84\x6b\x83\x6b\x76\x3c\x81\x32\x0d\x6c\x8d\x3b\xd6\xde\x05\x83\x89\x94\x70\xc7\xd8\x1b\x88\x28\x0a\x55\x2e\x10\x26\x4e\xd6\x2b\xd0\xa6\xe4\x39\x11\xaa\x9b\x04\x4d\xc4\x7d\x3c\x17\x2f\x91\xc1\x85\xc4\xb1\x02\xc7\x4b\x93\x49\x28\x50\x5a\x4d\xb7\x92\xed\xdd\xd2\xdb\xa9\xe6\xe9\x55\x2b\xc4\xd8\x9b\x88\x3e\xcb\x78\x05\x7f\x64\xa8\xce\xa0\x31\x27\x42\xc6\x58\x2f\x27\x49\x81\x5d\xbc\xa5\x98\x03\xe7\x2a\xf0\x8b\x02\xf5\x29\x4a\x45\x9f\x3f\x2e\x82\x74\x02\x01\x38\x87\x7b\x4b\xc5\xd6\x20\x19\xf3\x70\x8c\x18\x85\xa8\xe0\x52\x3a\x90\x36\x12\x8b\x93\xec\x55\x26\x11\x8e\x50\x9e\xa2\xa9\xd9\x9e\xe9\x9f\x92\x47\x67\x3c\xd1\xd2\x09\xa6\x23\xa0\x52\xc1\x41\x54\x85\x1e\x6f\x7a\x14\xa2\x77\x15\x23\xfe\xd7\x28\x24\xd0\x2a\xf2\x50\xef\x54\xbe\x5b\x01\xa1\x27\x89\x79\xba\x12\xec\xb6\x93\x14\x27\x8f\xdf\xad\xf4\x40\x03\x61\xca\x05\xa4\x21\x68\x38\x53\xee\x4c\xcf\xf6\x5c\xf9\x95\x97\x8b\x5e\xcb\x5a\x8a\x90\x45\x02\xfb\x15\x01\x53\xc9\x16\x4d\x87\xa1\xb4\x35\xce\xad\x40\x85\xc2\x83\x2b\x35\x0b\xb5\x83\x58\x1e\x13\xa0\xe9\xe8\x3a\x25\x82\xb3\x93\x74\xa2\x7e\x16\x5e\x52\x22\xa1\xa3\xd0\x9a\xd8\x19\x5d\x40\xe8\x21\x50\x8d\xb5\x55\x6d\xac\x56\x94\x67\x56\x2a\xa4\x47\x61\xa8\x44\x5b\x17\x4a\x2e\x0f\xbd\x10\x2a\x17\x42\xc8\x5d\x1c\x57\x39\x11\x0d\x65\x10\x6c\xd2\x18\x28\xea\x58\xe2\x0d\x98\x98\xaf\xf2\x4e\x81\x06\xd8\xf1\xde\x62\x15\x2f\x46\xfc\x75\x8c\xc3\xaf\x0b\x26\x38\x1a\x4c\xf1\x98\x29\xc8\x4a\x1f\x88\x63\x6c\x17\x61\x0f\x5a\xd7\x49\x0a\x48\xb6\xa1\x41\x43\x33\x45\xed\x07\xba\x12\x3c\x03\x82\xc8\x67\x9b\x05\x8a\x5b\xe5\x0a\xfc\x71\x22\x57\x28\x7a\x94\x87\x8c\x49\x02\x9a\x44\xa3\x4d\xb7\x02\xae\x0b\x74\xcc\x47\x09\xc5\xc8\xf0\x00\x68\xd4\x7e\x4b\xe5\x8a\x91\x55\x96\xcc\xa1\xb2\x04\x8e\xa6\x70\xea\xb7\x31\x34\x98\x07\x95\x0f\x04\x63\x1d\xd1\xb0\x41\xbf\x03\xda\x83\x26\xb0\x1a\x95\xdd\x20\xe0\x12\xd1\x93\xa0\x3d\x44\x1a\x23\xc2\x2e\x1f\x69\x30\x8c\xc7\x2c\xd6\x8c\xca\x1a\x65\x31\x85\x2f\xd3\x42\x86\x46\x6f\x4a\xe2\xbd\xa8\x7e\x3a\x6a\x56\xf2\x1d\x7a\x1b\x32\x1e\xc1\x06\x4f\x59\x43\x29\x4a\xde\x5a\xaa\x25\xce\x13\xab\x98\x88\x56\x2b\xc5\x28\x55\xd4\xca\x08\x4d\x28\x42\x4a\x79\xaa\x5e\xd6\x38\x82\x04\x85\x35\x2c\xf9\x7a\x54\xfb\xeb\xde\x23\x0c\xf8\xd7\xa0\xed\xe0\x1a\x90\x66\x44\x1b\xa8\x03\x16\x9d\x38\x75\xaa\x4e\x13\xd5\x83\xaa\x33\x45\x2d\x9b\x1f\xa5\x0a\x6d\x84\x33\x74\x5b\x78\x6c\xe8\x65\x83\x85\xc6\x61\x94\xe2\xa2\x13\xed\x7b\x86\x63\x1c\x39\x8d\x63\x90\xa9\x1a\xc5\x51\xe6\x93\x02\x12\x2e\x0d\x9c\xa4\xee\x48\x30\x2a\x3a\x36\xdb\x59\xea\xa8\x47\x33\xd9\x9e\xe5\x60\x50\x63\x09\x97\xd3\x83\x13\x06\x50\x25\x36\xe0\x7d\x2a\xcb\x7a\x57\xd9\x24\x1a\x2f\x3f\x0a\x01\x20\xcc\x05\x80\xa0\x95\x56\x81\x60\x99\x97\x93\x34\xae\x62\x54\xae\xd1\xcf\xc6\x02\x8f\x50\x21\x4d\x81\x89\x69\x51\x90\x8e\xd3\x29\xfa\x39\xb0\xb9\x80\xa7\x2c\x94\xf3\xc9\x0f\x47\xf8\x94\xd9\x73\xb7\xde\x7e\xcd\x78\x65\x56\x1f\xfc\x78\xcd\xe8\xf7\x2d\x95\x7a\x67\x08\x4f\x95\xa5\x46\x3b\xa6\x31\x79\xa5\xb8\x98\x92\x54\xa2\x4a\x0a\x3f\x4a\x55\x04\x63\xc6\xc6\x17\xa6\xd0\xdb\xf8\x49\xc0\xb5\x5b\x73\x32\xce\xc0\xb8\x67\x2a\xec\x0c\x5d\x3c\xd7\xa1\x0e\xe1\x35\x32\x8f\x8d\x4a\x1b\x21\x6f\x90\xb6\xcc\x89\xf0\x2e\x60\x50\x8f\x41\xc8\x86\x8e\xf0\x52\x11\xb9\x4b\x80\x71\x94\xc2\xf1\xee\xe4\xb9\x2a\x74\xf7\xe3\xf1\x82\x40\x23\xc8\x3b\x86\x17\xaa\x28\xc5\xd6\xd0\xc5\xd6\xd0\xa0\xab\xd5\xa1\x4f\xa7\x16\x8d\xe2\x8d\x37\x02\xa9\xdb\x9c\x78\x5b\x45\x32\xa1\xde\xcf\x3b\x91\x53\x91\x10\x74\x00\xe5\x29\x82\x90\x53\x5f\xcd\x42\x1d\xb6\xa7\x5a\x87\x50\xa0\xa8\x2b\x8c\xdd\x28\xbd\x9c\xf9\x49\xa0\xb4\x36\x1a\x88\xa2\x77\x16\xea\x4e\x83\xdb\xf4\xe6\x4a\x88\x22\x36\x44\x27\x85\xac\x47\x67\xde\x86\x98\xd1\x6b\xb3\x3f\x01\x2f\xea\x15\x94\x33\x19\x35\xac\x63\x6f\x18\x5e\x6d\xd1\x24\x4c\x12\x55\x16\xe9\xfb\x3c\x74\xc3\xe1\xb7\x03\x87\xf7\x1e\xb4\xf8\x82\x4d\x52\x95\x8f\x25\x82\x11\x89\xa2\xe5\xa5\x18\x60\x0d\x82\x4c\x41\xa0\x0a\x6b\xbe\xdd\x43\xd9\xff\xf0\x6d\xe0\x95\x87\xfd\xdd\xde\x63\xfa\x71\xef\xf1\x67\xbd\xc7\x5f\xf9\xc6\xe1\xf9\x2c\xfe\xd8\x7a\x3e\x8b\xff\x31\xfe\x2f\x38\x9a\xdf\x3f\x42\x1e\x00\x00"
 
 // index_html returns raw, uncompressed file data.
 func index_html() []byte {
@@ -0,0 +1,39 @@
+package resources
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"reflect"
+	"unsafe"
+)
+
+var _logs_html = "\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\x03\x8d\x54\x4d\x4f\xdc\x30\x10\xbd\xf3\x2b\x86\xdc\x9d\x1c\xaa\x0a\xa8\x9c\x48\xa8\xe2\x80\xd4\x43\x2f\x95\x5a\x21\x84\xbc\xf6\x6c\x62\x9a\x78\x5c\x7b\xb2\xdb\xfd\xf7\xb5\x93\x0d\x04\xd8\x22\xf6\xb0\x99\x78\xfc\xde\x7c\xbc\xb7\x2b\xcf\x0d\x69\x3e\x78\x84\x8e\x87\xbe\x39\x93\xe7\x42\xdc\xd9\x2d\xf4\x0c\xb7\x37\x70\x71\xdf\xc0\xf4\x91\x39\x0b\xba\x57\x31\xd6\x85\x23\xf1\x18\xd3\x0d\x61\xf1\x6a\x7e\x5c\xce\x8f\x8b\xa2\x01\x79\x7e\x87\xce\xd8\xed\xbd\x10\xcf\x6c\x6b\xaa\x0f\xb0\xbd\x43\x73\xf9\x11\x9a\xff\xe1\x5b\x3e\x52\xe4\x83\xe6\x04\x7e\x02\x0a\xf1\x02\x9c\xeb\xa0\x32\x39\x48\xe1\x80\xac\x40\x77\x2a\x44\xe4\xba\x18\x79\x2b\x52\xb7\xab\x54\xc7\xec\x05\xfe\x19\xed\xae\x2e\x7e\x8a\x1f\xd7\xe2\x2b\x0d\x5e\xb1\xdd\xf4\x58\x80\x26\xc7\xe8\x12\xee\xf6\xa6\x46\xd3\xe2\x82\x64\xcb\x3d\x36\xc8\xda\xc0\x37\x6a\xa3\xac\xe6\x83\x15\xad\x53\x03\xd6\x85\xc1\xa8\x83\xf5\x6c\xc9\xad\xc8\x8a\xb7\x17\x77\x16\xf7\x9e\x02\xaf\x6e\xed\xad\xe1\xae\x36\xb8\xb3\x1a\xc5\xf4\xb2\xe0\xd2\xc0\xf0\xbd\x57\x1a\x61\xab\x52\x96\x5c\x99\xbe\x40\x39\x03\xca\xfb\x1e\x05\xd3\xa8\x3b\x31\x25\xbc\x6b\xc1\x3a\xe0\x0e\x21\x10\x31\x18\x1b\x50\x33\x85\x03\xe4\x4d\x9d\x3d\x09\xd3\x5b\xf7\x1b\x02\xf6\x75\x11\xf9\xd0\x63\xec\x10\x53\x2f\x5d\xc0\xed\x72\x52\x0d\xca\xba\x52\xc7\xb4\xf2\x33\x59\x2d\x0b\x96\x1b\x32\x07\x70\xad\x48\x95\xeb\x22\x2f\x24\xef\x63\xd5\xe8\x0b\x6b\x1e\xeb\x49\xbf\x68\xb8\x09\xb4\x8f\xd8\x25\xf0\xa1\x68\x7e\xd1\x08\x2a\x20\x8c\xd1\xa6\xae\x95\x03\x19\x39\x90\x6b\x1b\x1a\xd9\x28\x46\x23\xab\xe3\x01\xcc\xb8\x50\xa6\x35\xa0\x8a\x08\x52\x1d\x7b\xcd\x6a\x7e\xa9\xaa\x15\x6f\xa9\x69\xa8\x8a\x66\xf4\x6d\x50\x06\xe1\x40\x63\x58\xe0\xb2\x52\x0d\x30\x81\x1d\x7c\xa0\xdd\x31\x87\x7f\x3d\x06\x8b\x4e\x63\x29\x2b\xbf\x0c\xb2\xb2\xd7\x89\xd1\xae\x9e\x47\x9b\xf5\x86\x18\x74\x9a\x8e\xf6\x18\x1e\x52\x7d\x4f\x2e\x49\x1a\x2b\x8c\x9f\x45\xec\xec\xf0\x14\x94\xd9\xc1\x69\xac\x09\xf4\x31\x8e\xc7\x48\xee\x53\xd5\xdb\xcd\x1c\x95\x43\x52\xe5\x2d\xcb\xe9\x8e\xe1\xda\x98\x79\xca\x68\x19\x81\xc2\x64\x18\xab\x55\x36\xe8\xe2\x3c\xe8\x30\x89\x30\xff\x90\x12\xcc\xd8\x1d\x58\x93\xb5\x35\x0f\x7d\xd6\x36\xab\x9d\xed\x3a\xcb\xbd\x48\x5d\xa5\x7b\xcd\xb3\x9f\xd6\x23\xcc\x71\x4c\xe2\x29\x8e\x62\x20\x33\x26\x3b\xbd\xea\xf9\x7d\x64\xae\x2b\x8e\x2f\xaf\x80\xb2\xca\x0e\x9c\x2c\x99\xff\x0e\xff\x01\xc9\xc0\x19\xb1\x1e\x05\x00\x00"
+
+// logs_html returns raw, uncompressed file data.
+func logs_html() []byte {
+	var empty [0]byte
+	sx := (*reflect.StringHeader)(unsafe.Pointer(&_logs_html))
+	b := empty[:]
+	bx := (*reflect.SliceHeader)(unsafe.Pointer(&b))
+	bx.Data = sx.Data
+	bx.Len = len(_logs_html)
+	bx.Cap = bx.Len
+
+	gz, err := gzip.NewReader(bytes.NewBuffer(b))
+
+	if err != nil {
+		panic("Decompression failed: " + err.Error())
+	}
+
+	var buf bytes.Buffer
+	io.Copy(&buf, gz)
+	gz.Close()
+
+	return buf.Bytes()
+}
+
+
+func init() {
+	go_bindata["/logs.html"] = logs_html
+}
@@ -0,0 +1,39 @@
+package resources
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"reflect"
+	"unsafe"
+)
+
+var _scripts_logs_scripts_js = "\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\x03\xad\x56\x4d\x8f\xdb\x36\x10\xbd\xfb\x57\x4c\x01\x03\x94\xb0\xae\xe4\x6d\xda\x8b\x85\x6d\x11\x04\x39\x14\xed\xa1\x08\x9a\xd3\x66\x0b\xb0\xd2\xc8\x22\x42\x91\x5a\x92\x92\x63\x2c\xfc\xdf\x3b\x24\x25\x59\xda\xdd\x14\x68\xd0\x83\x2d\x61\x38\x9f\x6f\xde\x0c\xc5\x7a\x8b\x60\x9d\x11\xa5\x63\xc5\x66\xc3\xd5\xb1\x97\xdc\x64\xad\xae\x7a\x89\x09\x43\x57\x56\xbf\xeb\xa3\x65\x3b\xb8\x67\xea\xf8\x41\xf7\x0e\xd9\x43\xba\xd9\x64\xa5\x56\xb5\x38\x26\xf7\x6c\x6b\xbc\xf0\x0f\xa3\x07\x51\xa1\x21\xc5\xba\x57\xa5\x13\x5a\x41\xb2\x3e\x4a\xe1\x69\x03\xb0\x96\x91\x00\x20\x3b\x35\xa8\x12\x96\x93\xed\x53\x10\x00\x38\x6c\x3b\xc9\x1d\x7e\x34\xf2\x00\x6c\x10\x78\xb2\xb9\xa4\x3c\xb2\xc6\xb5\x92\xed\x46\x2d\xca\xc1\x19\x2d\x25\x1a\x52\xf2\x69\xbe\x73\x46\xb2\x70\x78\x49\xa3\x6b\xed\x1a\x34\x27\x61\x31\xf9\x1f\x5d\x17\x9b\xcb\x84\xc1\xa8\x94\x5c\x75\x3c\x52\x5b\x5b\xea\x0e\xe9\x95\x6d\x1b\xe7\xba\xf0\xf2\x18\xfe\x9d\x68\x91\x00\x58\xc3\x14\xb4\x77\x10\x74\xe9\xf1\x48\xbf\x51\x2f\x62\x96\xe7\x5a\xc9\x33\x7c\x46\xec\xc0\x35\xc2\x42\xcb\xd5\x19\x8c\x3e\x59\xd0\x35\x20\x2f\x1b\x90\xc2\x3a\xd2\x1c\xb8\xa1\xc3\x2f\x1f\xfc\xd1\x1d\xfc\xb4\xdf\x17\xa3\xd0\x0a\x55\x22\x89\x66\xc1\x89\x0b\xf7\xab\xaa\xf0\xcb\x52\x58\x72\xd2\xa2\x72\x48\xa6\x7a\x29\x89\x10\xd4\xb0\x90\x5d\x26\x71\x40\xe9\x9d\xde\xb3\x0a\xff\xee\x8f\xbe\x1a\xa1\x6a\xed\x9f\x27\x6e\x94\x50\x41\x54\x73\xc7\x25\x7b\x28\x9e\x19\x92\x5d\xd4\x5e\x1c\x74\x06\x6b\xe1\xc3\x53\xe7\x17\x62\x24\x4c\x05\x86\x48\x4b\x37\xe4\x45\xb9\x49\x4a\xe2\x19\x3e\x8f\x4a\xe2\xcb\xdf\x05\x44\xd2\x91\x43\x6f\x8d\xe1\x67\x8a\xa1\x9d\x76\x67\xb2\xef\x95\x6d\x44\xed\x32\xde\x75\xf2\xbc\xd0\xcf\x0c\x79\x36\xc4\x8f\x34\x2d\x82\xa1\x3f\xca\x6c\x27\x45\x89\xc9\x08\xe5\x2e\x0a\x25\xaa\xa3\x6b\x82\xda\x65\x95\x82\x41\x1e\xa6\x24\x99\x82\x87\x4e\x66\x47\x74\xc4\xea\xe1\x87\x9c\x57\xad\x50\x81\x64\x9e\xe2\x1d\x37\xbc\xb5\x07\x78\x0a\x3d\x39\xc4\xd6\x50\x08\x8f\xd3\x61\x85\xda\x65\x64\x31\xf1\xd8\xf6\x65\x89\xd6\x26\x53\xcc\xa4\x22\xa0\xd3\x79\x5e\xae\x68\xeb\xe3\x7b\x63\xb4\xef\x20\x63\xc5\x7c\x2a\x6a\x08\x16\x63\x0d\xf0\x33\xec\x97\xc6\x30\xf3\xc3\x2b\xdd\x2f\x35\xbf\x87\xdb\x87\x4c\x78\xa2\x14\x0b\xf5\x00\xfa\xba\x61\xbb\x60\x9b\x5e\xb5\x2e\xe3\xdb\xb5\x08\xf4\x99\xad\x4b\xd8\xd1\xf2\xe1\xae\xb7\xff\x5e\x4a\xd4\x81\xbb\xbb\x3b\xf8\x71\xff\x06\x7e\x01\xf6\x67\x83\xe0\x01\x05\x6e\x10\xc2\x74\xd8\x46\x9f\x14\x38\x0d\x01\x6d\x28\xa5\xf0\x84\xc9\x18\x1c\x42\x62\xc5\x9c\xcd\xcb\xfe\x9d\xb8\x2b\x9b\xdf\xf0\x7c\x6d\xa0\x1f\x86\xd8\x27\x8a\xfe\xe4\x67\xe5\x00\xce\xf4\xd4\x26\x83\x65\x6f\xac\x18\x30\x0a\x2e\xd1\xaf\xc7\xf7\x3a\x51\x2b\x74\xa3\x9b\x6c\x39\x6f\xf3\x7b\x34\x8e\x40\x2d\x66\x6f\xfb\x98\x55\x58\xd3\x56\x19\xd1\x8c\xd9\x50\x33\xfc\xac\x10\x9f\x3e\x53\xaa\x39\x83\x9b\xf5\x24\x11\x93\x69\xb1\x11\x6b\xf3\xbf\x3e\xe5\x37\x39\x0d\x23\x1b\xed\xaf\x74\xf4\x5e\x16\x14\x8c\xcf\x1d\x8c\xdb\xe6\x30\x27\xe1\x07\xa7\xa5\xad\xf9\x1f\x08\x38\x53\x4c\xe9\x0a\xd7\xe4\x5a\xd6\x3e\xab\xf8\x3b\x46\xd4\x02\xab\x78\x72\x03\xb7\x5f\x25\x58\x18\x7d\xda\xab\xde\xf6\xe1\x15\x86\xc1\xb2\x83\xc5\xb7\xd2\x2e\xcf\x47\x9a\xed\xa1\x45\xae\x2c\xad\x5a\x8c\x8e\xe9\xdf\x4e\xd0\x54\xab\x82\x47\x8b\xef\x88\x98\xcf\x06\x6a\xda\xe0\xc9\x9c\xda\x0e\x6e\xf7\xfb\xfd\xab\x03\x32\x53\x72\xac\xd8\xa0\x45\xe7\x37\x0a\x01\x36\xe7\x3d\xf9\x5f\xad\xf2\xaf\xae\x4d\x58\x6c\xa5\xe0\xbe\x78\xee\xff\xfd\xb4\x51\x5f\x44\xf0\x95\x4d\x4c\xb8\x56\x35\x73\x83\xac\xb5\x1c\x70\x82\x3a\x96\xf1\xe2\x3e\x79\x7d\x73\xbf\xd2\xac\x98\xd9\x3a\xdb\x95\x4a\xb8\x01\xe1\x63\x47\x8d\xc3\xd0\x14\x1a\x7c\xda\xc8\x03\x8e\xb7\x56\x17\xae\x60\xbf\x26\x90\x52\x70\x68\x06\x2e\x93\x17\x45\xad\x03\xcc\xd9\x6d\xe3\x85\x10\x33\x99\x5b\x74\x85\x6a\x4b\x2e\xd8\xb6\x42\xfa\x46\xd2\xe7\xc5\xb5\x3d\xfb\x2d\x25\x72\x33\x87\x8d\xb9\xa4\xc5\x37\xe2\x38\x7e\x5b\x14\x9b\x7f\x00\x2e\x21\x8a\xfd\x98\x09\x00\x00"
+
+// scripts_logs_scripts_js returns raw, uncompressed file data.
+func scripts_logs_scripts_js() []byte {
+	var empty [0]byte
+	sx := (*reflect.StringHeader)(unsafe.Pointer(&_scripts_logs_scripts_js))
+	b := empty[:]
+	bx := (*reflect.SliceHeader)(unsafe.Pointer(&b))
+	bx.Data = sx.Data
+	bx.Len = len(_scripts_logs_scripts_js)
+	bx.Cap = bx.Len
+
+	gz, err := gzip.NewReader(bytes.NewBuffer(b))
+
+	if err != nil {
+		panic("Decompression failed: " + err.Error())
+	}
+
+	var buf bytes.Buffer
+	io.Copy(&buf, gz)
+	gz.Close()
+
+	return buf.Bytes()
+}
+
+
+func init() {
+	go_bindata["/scripts/logs-scripts.js"] = scripts_logs_scripts_js
+}
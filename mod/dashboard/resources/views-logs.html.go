@@ -0,0 +1,39 @@
+package resources
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"reflect"
+	"unsafe"
+)
+
+var _views_logs_html = "\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\x03\xb5\x54\xcb\x6e\xdb\x30\x10\xbc\xf7\x2b\x08\x5e\xd2\x1e\x6c\x15\x3d\xa5\x86\x24\x20\x08\x02\xa4\x68\x7a\xeb\x0f\xd0\xe2\x5a\x22\x4a\x91\x02\xc9\xb8\x16\x5c\xff\x7b\x97\x6b\xc6\xd5\xc3\x09\x74\x68\x74\x11\x77\x35\xb3\xcb\x1d\x0d\x99\x4b\xb5\x67\x95\x16\xde\x17\x1c\x42\x25\x57\x95\x35\x41\x28\x03\x8e\x51\xa8\x6d\xed\x79\xf9\x81\xe1\x93\x4f\xa1\x0d\x08\x89\x38\x6f\xb5\x92\x09\x43\xb8\x9d\x75\xed\x08\x18\x8b\xac\x76\x4a\x07\x70\x9c\x99\x7a\xe5\x9f\xb7\xad\x0a\x05\x77\xe0\x21\x3c\xec\xc1\x04\xff\xf1\xd3\xa0\x02\x55\xf1\xa0\xa1\x0a\x11\xde\x5a\x09\xba\xe0\x1a\xf6\xa0\x89\x5f\x35\xc2\xd4\x90\xf8\x4f\x58\x1c\xd9\x31\x6f\xbb\xa0\xac\xc1\xae\x9a\xe1\x1e\x98\x66\xca\x30\x62\xe1\x08\x79\x76\x2e\x38\xe9\xa2\x4c\xf7\x1c\x58\xe8\x3b\x2c\x17\xe0\x10\xf8\xa0\x61\xe7\x60\xa7\x0e\x9c\x75\x5a\x54\xd0\x58\x8d\xc3\x16\x3c\xe3\x2c\x88\xad\x32\x12\x0e\x05\xbf\xbd\xfd\xca\x59\x36\x18\x3d\x8b\xb3\x27\xb9\x32\xd4\xeb\x15\xe5\xb6\x56\xf6\x43\xc5\xa6\xdf\xb5\xf2\x61\xaa\x47\xf3\xa5\xc4\x51\xf3\x0c\xdf\xe3\x0f\x53\x32\x38\x67\x93\xce\x8d\xfd\x8d\x62\xd8\xfa\x81\x52\xe5\xf1\xf8\xb2\x3e\x9d\x06\xdb\xbb\x54\xc2\xc1\x34\xb0\x0a\xb4\xee\x84\x94\xca\xd4\x05\xff\xcc\x29\xf6\x9d\xa8\x52\x3c\xe5\x44\x17\x8c\x73\xe7\xbc\x1c\x6d\x2a\xa8\x16\x92\x61\x78\xf9\x13\x83\x3c\x0b\x0b\x68\xf4\xf7\x2e\xbc\xa7\x18\x2d\x23\xb6\xe0\xbd\xa8\xff\xb5\xfc\x71\x8e\xe7\x64\xcc\xcc\x27\xc8\x43\xfc\x43\xd7\xba\xb8\xa8\xab\x83\x0e\x04\xfa\x17\x8d\xeb\xfa\x68\xb2\xb8\x50\xe0\xf9\xd4\xf5\xab\xe3\x91\x30\x6b\x9a\xe3\x74\xe2\xf3\x92\x69\xf3\xe5\x0b\x32\x0a\xc5\xfe\x30\x29\x02\x6c\x6e\x1e\x1f\x37\x6d\xbb\xf1\xfe\x26\xfe\xaf\x6b\x63\x4f\xd9\xa9\xcf\x32\x70\xd2\xe8\x35\x38\x66\xdd\x4c\xab\xb9\x2e\x98\x8c\xb6\x19\x1e\x82\x91\xb3\x96\x9a\xfb\x3b\xf4\xec\x9e\x0e\xb6\xbf\x62\xf2\x77\xb4\x26\x9d\xe5\x8b\x51\xbe\xc5\x68\x99\xc7\x44\x15\xaf\x9b\x0b\xf3\x8e\xc2\x65\x54\x23\x06\xc7\x01\x27\xff\xcf\xbe\x8c\x57\x2a\xf9\x92\xee\xd6\xb7\x5d\x17\x21\x6b\x83\x37\xde\x1a\xaf\x3d\xb5\x53\x20\x49\x82\x05\x26\x22\xe6\x59\x84\xa5\x68\xea\xf3\x0b\xfa\x77\xf4\x5c\x5a\xa6\xd7\x5f\xdb\x3f\xc5\xe9\xe0\x06\x00\x00"
+
+// views_logs_html returns raw, uncompressed file data.
+func views_logs_html() []byte {
+	var empty [0]byte
+	sx := (*reflect.StringHeader)(unsafe.Pointer(&_views_logs_html))
+	b := empty[:]
+	bx := (*reflect.SliceHeader)(unsafe.Pointer(&b))
+	bx.Data = sx.Data
+	bx.Len = len(_views_logs_html)
+	bx.Cap = bx.Len
+
+	gz, err := gzip.NewReader(bytes.NewBuffer(b))
+
+	if err != nil {
+		panic("Decompression failed: " + err.Error())
+	}
+
+	var buf bytes.Buffer
+	io.Copy(&buf, gz)
+	gz.Close()
+
+	return buf.Bytes()
+}
+
+
+func init() {
+	go_bindata["/views/logs.html"] = views_logs_html
+}
@@ -0,0 +1,220 @@
+package dns
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"strings"
+)
+
+// The record types and class served.
+const (
+	typeA     = 1
+	typeSRV   = 33
+	typeANY   = 255
+	classINET = 1
+)
+
+// The response codes.
+const (
+	rcodeSuccess        = 0
+	rcodeFormatError    = 1
+	rcodeServerFailure  = 2
+	rcodeNameError      = 3
+	rcodeNotImplemented = 4
+	rcodeRefused        = 5
+)
+
+// The header flags.
+const (
+	flagResponse      = 1 << 15
+	flagAuthoritative = 1 << 10
+	flagTruncated     = 1 << 9
+	flagRecursion     = 1 << 8
+)
+
+const headerLen = 12
+
+var errMalformed = errors.New("malformed message")
+
+// question is the single question of a query.
+type question struct {
+	name   string
+	qtype  uint16
+	qclass uint16
+}
+
+// srv is the data of an SRV record.
+type srv struct {
+	priority uint16
+	weight   uint16
+	port     uint16
+	target   string
+}
+
+// record is an A or SRV resource record.
+type record struct {
+	name  string
+	rtype uint16
+	ttl   uint32
+	ip    net.IP
+	srv   *srv
+}
+
+// query is a parsed DNS query.
+type query struct {
+	id       uint16
+	flags    uint16
+	question question
+}
+
+// parseQuery reads a standard query with a single question. The query is
+// returned along with the error when the header could be read, so that the
+// error can be answered.
+func parseQuery(b []byte) (*query, error) {
+	if len(b) < headerLen {
+		return nil, errMalformed
+	}
+	q := &query{
+		id:    binary.BigEndian.Uint16(b[0:]),
+		flags: binary.BigEndian.Uint16(b[2:]),
+	}
+	if q.flags&flagResponse != 0 || binary.BigEndian.Uint16(b[4:]) != 1 {
+		return q, errMalformed
+	}
+
+	name, off, err := readName(b, headerLen)
+	if err != nil || off+4 > len(b) {
+		return q, errMalformed
+	}
+	q.question = question{
+		name:   name,
+		qtype:  binary.BigEndian.Uint16(b[off:]),
+		qclass: binary.BigEndian.Uint16(b[off+2:]),
+	}
+	return q, nil
+}
+
+// opcode returns the kind of query; only standard queries are answered.
+func (q *query) opcode() int {
+	return int(q.flags>>11) & 0xf
+}
+
+// readName reads a domain name at off and returns it in lower case with a
+// trailing dot, followed by the offset after it.
+func readName(b []byte, off int) (string, int, error) {
+	var labels []string
+	end := -1
+	for jumps := 0; ; {
+		if off >= len(b) {
+			return "", 0, errMalformed
+		}
+		n := int(b[off])
+		switch {
+		case n == 0:
+			if end < 0 {
+				end = off + 1
+			}
+			return strings.ToLower(strings.Join(labels, ".")) + ".", end, nil
+		case n&0xc0 == 0xc0:
+			// A pointer to a name earlier in the message.
+			if off+1 >= len(b) || jumps > 10 {
+				return "", 0, errMalformed
+			}
+			if end < 0 {
+				end = off + 2
+			}
+			off = int(binary.BigEndian.Uint16(b[off:]) & 0x3fff)
+			jumps++
+		case n&0xc0 != 0 || off+1+n > len(b):
+			return "", 0, errMalformed
+		default:
+			labels = append(labels, string(b[off+1:off+1+n]))
+			off += 1 + n
+		}
+	}
+}
+
+// appendName writes a domain name without compression.
+func appendName(b []byte, name string) []byte {
+	for _, label := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+		if label == "" {
+			continue
+		}
+		if len(label) > 63 {
+			label = label[:63]
+		}
+		b = append(b, byte(len(label)))
+		b = append(b, label...)
+	}
+	return append(b, 0)
+}
+
+func appendUint16(b []byte, v uint16) []byte {
+	return append(b, byte(v>>8), byte(v))
+}
+
+func appendUint32(b []byte, v uint32) []byte {
+	return append(b, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
+
+// appendRecord writes a resource record.
+func appendRecord(b []byte, r record) []byte {
+	b = appendName(b, r.name)
+	b = appendUint16(b, r.rtype)
+	b = appendUint16(b, classINET)
+	b = appendUint32(b, r.ttl)
+
+	var data []byte
+	switch r.rtype {
+	case typeA:
+		data = r.ip.To4()
+	case typeSRV:
+		data = appendUint16(data, r.srv.priority)
+		data = appendUint16(data, r.srv.weight)
+		data = appendUint16(data, r.srv.port)
+		data = appendName(data, r.srv.target)
+	}
+	b = appendUint16(b, uint16(len(data)))
+	return append(b, data...)
+}
+
+// packResponse writes the answer to a query in at most max bytes. Records
+// that do not fit are left out and the response is marked truncated, unless
+// only additional records were left out.
+func packResponse(q *query, rcode int, answers, extras []record, max int) []byte {
+	flags := uint16(flagResponse|flagAuthoritative) | q.flags&flagRecursion | uint16(rcode)
+
+	b := make([]byte, headerLen, 512)
+	binary.BigEndian.PutUint16(b[0:], q.id)
+	if q.question.name != "" {
+		binary.BigEndian.PutUint16(b[4:], 1)
+		b = appendName(b, q.question.name)
+		b = appendUint16(b, q.question.qtype)
+		b = appendUint16(b, q.question.qclass)
+	}
+
+	var an, ar uint16
+	for _, r := range answers {
+		next := appendRecord(b, r)
+		if len(next) > max {
+			flags |= flagTruncated
+			break
+		}
+		b, an = next, an+1
+	}
+	if flags&flagTruncated == 0 {
+		for _, r := range extras {
+			next := appendRecord(b, r)
+			if len(next) > max {
+				break
+			}
+			b, ar = next, ar+1
+		}
+	}
+
+	binary.BigEndian.PutUint16(b[2:], flags)
+	binary.BigEndian.PutUint16(b[6:], an)
+	binary.BigEndian.PutUint16(b[10:], ar)
+	return b
+}
@@ -0,0 +1,44 @@
+package dns
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Ensure that names are read in lower case, following compression pointers.
+func TestReadName(t *testing.T) {
+	b := []byte{3, 'W', 'e', 'b', 4, 'e', 't', 'c', 'd', 0, 2, 'i', '1', 0xc0, 0}
+	name, off, err := readName(b, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, name, "web.etcd.")
+	assert.Equal(t, off, 10)
+
+	name, off, err = readName(b, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, name, "i1.web.etcd.")
+	assert.Equal(t, off, 15)
+
+	// A pointer to itself.
+	_, _, err = readName([]byte{0xc0, 0}, 0)
+	assert.Error(t, err)
+}
+
+// Ensure that answers that do not fit are left out and the response marked
+// truncated.
+func TestPackResponseTruncated(t *testing.T) {
+	q := &query{id: 7, question: question{name: "web.etcd.", qtype: typeA, qclass: classINET}}
+	var answers []record
+	for i := 0; i < 40; i++ {
+		answers = append(answers, record{name: "web.etcd.", rtype: typeA, ttl: 30, ip: []byte{10, 0, 0, byte(i)}})
+	}
+
+	b := packResponse(q, rcodeSuccess, answers, nil, 512)
+	assert.True(t, len(b) <= 512)
+	assert.Equal(t, b[2]&0x02, byte(0x02), "truncated")
+	assert.Equal(t, int(b[6])<<8|int(b[7]), 20)
+
+	b = packResponse(q, rcodeSuccess, answers, nil, 65535)
+	assert.Equal(t, b[2]&0x02, byte(0))
+	assert.Equal(t, int(b[6])<<8|int(b[7]), 40)
+}
@@ -0,0 +1,124 @@
+package dns
+
+import (
+	"net"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/coreos/go-etcd/etcd"
+)
+
+// The TTL, in seconds, of records derived from keys without one.
+const defaultTTL = 30
+
+// The priority and weight given to every SRV record.
+const (
+	srvPriority = 10
+	srvWeight   = 10
+)
+
+// keyPath maps a name in the domain to the key it is derived from: the
+// labels are read from right to left under the prefix, so that
+// "web.example.com" with the domain "example.com" is the key
+// <prefix>/web. The "_tcp" and "_udp" labels of SRV names are ignored and
+// service labels lose their underscore, so "_web._tcp.example.com" is the
+// same key.
+func (s *Server) keyPath(name string) (string, bool) {
+	if name != s.domain && !strings.HasSuffix(name, "."+s.domain) {
+		return "", false
+	}
+	rest := strings.TrimSuffix(strings.TrimSuffix(name, s.domain), ".")
+
+	var parts []string
+	if rest != "" {
+		labels := strings.Split(rest, ".")
+		for i := len(labels) - 1; i >= 0; i-- {
+			if labels[i] == "_tcp" || labels[i] == "_udp" {
+				continue
+			}
+			parts = append(parts, strings.TrimPrefix(labels[i], "_"))
+		}
+	}
+	return path.Join(append([]string{s.prefix}, parts...)...), true
+}
+
+// instance is a service instance read from a key.
+type instance struct {
+	name string
+	host string
+	port int
+	ttl  uint32
+}
+
+// instances returns the instances a name stands for: the key itself, or
+// the keys right under it when it is a directory. The value of a key is the
+// address of the instance, as "host:port" or only "host".
+func (s *Server) instances(name string, n *etcd.Node) []instance {
+	nodes := []etcd.Node{*n}
+	if n.Dir {
+		nodes = n.Nodes
+	}
+
+	var is []instance
+	for _, node := range nodes {
+		if node.Dir {
+			continue
+		}
+		i := instance{name: name, host: node.Value, ttl: defaultTTL}
+		if n.Dir {
+			i.name = path.Base(node.Key) + "." + name
+		}
+		if host, port, err := net.SplitHostPort(node.Value); err == nil {
+			i.host = host
+			i.port, _ = strconv.Atoi(port)
+		}
+		if node.TTL > 0 {
+			i.ttl = uint32(node.TTL)
+		}
+		is = append(is, i)
+	}
+	return is
+}
+
+// lookup answers a question from the keys under the prefix.
+func (s *Server) lookup(q question) (answers []record, extras []record, rcode int) {
+	if q.qclass != classINET && q.qclass != typeANY {
+		return nil, nil, rcodeNotImplemented
+	}
+	key, ok := s.keyPath(q.name)
+	if !ok {
+		return nil, nil, rcodeRefused
+	}
+
+	resp, err := s.client.Get(key, false, true)
+	if e, ok := err.(etcd.EtcdError); ok && e.ErrorCode == 100 {
+		return nil, nil, rcodeNameError
+	} else if err != nil {
+		return nil, nil, rcodeServerFailure
+	}
+
+	for _, i := range s.instances(q.name, resp.Node) {
+		ip := net.ParseIP(i.host).To4()
+		if ip != nil && (q.qtype == typeA || q.qtype == typeANY) {
+			answers = append(answers, record{name: q.name, rtype: typeA, ttl: i.ttl, ip: ip})
+		}
+		if i.port > 0 && (q.qtype == typeSRV || q.qtype == typeANY) {
+			// Instances with an IP address get a name of their own.
+			target := i.host
+			if ip != nil {
+				target = i.name
+				extras = append(extras, record{name: i.name, rtype: typeA, ttl: i.ttl, ip: ip})
+			} else if net.ParseIP(i.host) != nil {
+				continue
+			}
+			answers = append(answers, record{
+				name:  q.name,
+				rtype: typeSRV,
+				ttl:   i.ttl,
+				srv:   &srv{priority: srvPriority, weight: srvWeight, port: uint16(i.port), target: target},
+			})
+		}
+	}
+	return answers, extras, rcodeSuccess
+}
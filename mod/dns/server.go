@@ -0,0 +1,143 @@
+// dns serves the service instances kept under a key prefix as A and SRV
+// records, for clients that can only discover services through DNS.
+package dns
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/coreos/etcd/log"
+	"github.com/coreos/go-etcd/etcd"
+)
+
+// The largest response sent over UDP.
+const maxUDPSize = 512
+
+// How long a TCP connection may stay idle between queries.
+const tcpIdleTimeout = 10 * time.Second
+
+// Server answers DNS queries over UDP and TCP.
+type Server struct {
+	client *etcd.Client
+	domain string
+	prefix string
+
+	udp net.PacketConn
+	tcp net.Listener
+}
+
+// NewServer creates a DNS server for the names under domain, derived from
+// the keys under prefix of the etcd server at addr.
+func NewServer(addr string, domain string, prefix string) *Server {
+	return &Server{
+		client: etcd.NewClient([]string{addr}),
+		domain: strings.ToLower(strings.TrimSuffix(domain, ".")) + ".",
+		prefix: path.Join("/", prefix),
+	}
+}
+
+// ListenAndServe answers queries on addr over both UDP and TCP until the
+// server is closed.
+func (s *Server) ListenAndServe(addr string) error {
+	udp, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		return err
+	}
+	tcp, err := net.Listen("tcp", addr)
+	if err != nil {
+		udp.Close()
+		return err
+	}
+	s.udp, s.tcp = udp, tcp
+	log.Infof("dns server [domain %s, prefix %s, listen on %s]", s.domain, s.prefix, addr)
+
+	errs := make(chan error, 2)
+	go func() { errs <- s.serveUDP(udp) }()
+	go func() { errs <- s.serveTCP(tcp) }()
+	return <-errs
+}
+
+// Close stops answering queries.
+func (s *Server) Close() {
+	if s.udp != nil {
+		s.udp.Close()
+	}
+	if s.tcp != nil {
+		s.tcp.Close()
+	}
+}
+
+func (s *Server) serveUDP(c net.PacketConn) error {
+	buf := make([]byte, 65535)
+	for {
+		n, addr, err := c.ReadFrom(buf)
+		if err != nil {
+			return err
+		}
+		b := make([]byte, n)
+		copy(b, buf[:n])
+		go func() {
+			if resp := s.answer(b, maxUDPSize); resp != nil {
+				c.WriteTo(resp, addr)
+			}
+		}()
+	}
+}
+
+func (s *Server) serveTCP(l net.Listener) error {
+	for {
+		c, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go s.serveConn(c)
+	}
+}
+
+// serveConn answers the queries of a TCP connection, each prefixed with its
+// length.
+func (s *Server) serveConn(c net.Conn) {
+	defer c.Close()
+	for {
+		c.SetDeadline(time.Now().Add(tcpIdleTimeout))
+		var l [2]byte
+		if _, err := io.ReadFull(c, l[:]); err != nil {
+			return
+		}
+		b := make([]byte, binary.BigEndian.Uint16(l[:]))
+		if _, err := io.ReadFull(c, b); err != nil {
+			return
+		}
+		resp := s.answer(b, 65535)
+		if resp == nil {
+			return
+		}
+		binary.BigEndian.PutUint16(l[:], uint16(len(resp)))
+		if _, err := c.Write(append(l[:], resp...)); err != nil {
+			return
+		}
+	}
+}
+
+// answer returns the response to a query, or nil when the query cannot be
+// answered at all.
+func (s *Server) answer(b []byte, max int) []byte {
+	q, err := parseQuery(b)
+	if q == nil {
+		return nil
+	}
+	if err != nil {
+		return packResponse(q, rcodeFormatError, nil, nil, max)
+	}
+	if q.opcode() != 0 {
+		return packResponse(q, rcodeNotImplemented, nil, nil, max)
+	}
+
+	answers, extras, rcode := s.lookup(q.question)
+	log.Debugf("[dns] %s type=%d rcode=%d answers=%d", q.question.name, q.question.qtype, rcode, len(answers))
+	return packResponse(q, rcode, answers, extras, max)
+}
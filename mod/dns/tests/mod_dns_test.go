@@ -0,0 +1,78 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/coreos/etcd/mod/dns"
+	"github.com/coreos/etcd/server"
+	"github.com/coreos/etcd/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+// Ensure that the instances of a service are served as A and SRV records.
+func TestModDNSLookup(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		testSetKey(t, s, "/services/web/i1", "10.0.0.1:8080")
+		testSetKey(t, s, "/services/web/i2", "10.0.0.2:8081")
+		testSetKey(t, s, "/services/db/primary", "db1.example.com:5432")
+
+		d := dns.NewServer(s.URL(), "etcd", "/services")
+		go d.ListenAndServe("127.0.0.1:4453")
+		defer d.Close()
+		time.Sleep(50 * time.Millisecond)
+		r := testResolver("127.0.0.1:4453")
+
+		addrs, err := r.LookupHost(context.Background(), "web.etcd")
+		assert.NoError(t, err)
+		sort.Strings(addrs)
+		assert.Equal(t, addrs, []string{"10.0.0.1", "10.0.0.2"})
+
+		addrs, err = r.LookupHost(context.Background(), "i2.web.etcd")
+		assert.NoError(t, err)
+		assert.Equal(t, addrs, []string{"10.0.0.2"})
+
+		_, srvs, err := r.LookupSRV(context.Background(), "web", "tcp", "etcd")
+		assert.NoError(t, err)
+		if assert.Equal(t, len(srvs), 2) {
+			sort.Slice(srvs, func(i, j int) bool { return srvs[i].Port < srvs[j].Port })
+			assert.Equal(t, srvs[0].Target, "i1._web._tcp.etcd.")
+			assert.Equal(t, srvs[0].Port, uint16(8080))
+			assert.Equal(t, srvs[1].Target, "i2._web._tcp.etcd.")
+		}
+
+		_, srvs, err = r.LookupSRV(context.Background(), "db", "tcp", "etcd")
+		assert.NoError(t, err)
+		if assert.Equal(t, len(srvs), 1) {
+			assert.Equal(t, srvs[0].Target, "db1.example.com.")
+			assert.Equal(t, srvs[0].Port, uint16(5432))
+		}
+
+		_, err = r.LookupHost(context.Background(), "cache.etcd")
+		if assert.Error(t, err) {
+			assert.True(t, err.(*net.DNSError).IsNotFound)
+		}
+	})
+}
+
+func testSetKey(t *testing.T, s *server.Server, key string, value string) {
+	resp, err := tests.PutForm(fmt.Sprintf("%s/v2/keys%s", s.URL(), key), url.Values{"value": {value}})
+	assert.NoError(t, err)
+	tests.ReadBody(resp)
+}
+
+// testResolver returns a resolver that only asks the server at addr.
+func testResolver(addr string) *net.Resolver {
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, addr)
+		},
+	}
+}
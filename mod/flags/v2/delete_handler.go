@@ -0,0 +1,18 @@
+package v2
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// deleteHandler removes a flag and its value.
+func (h *handler) deleteHandler(w http.ResponseWriter, req *http.Request) {
+	h.client.SyncCluster()
+
+	name := mux.Vars(req)["name"]
+	if _, err := h.client.Delete(flagPath(name), false); err != nil {
+		http.Error(w, "delete flag error: "+err.Error(), http.StatusNotFound)
+		return
+	}
+}
@@ -0,0 +1,150 @@
+package v2
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strconv"
+
+	"github.com/coreos/go-etcd/etcd"
+)
+
+// The types a flag can have.
+const (
+	boolType   = "bool"
+	intType    = "int"
+	stringType = "string"
+	enumType   = "enum"
+)
+
+// definition is the stored form of a flag. Values are kept as the strings
+// the clients wrote and checked against the type and constraints.
+type definition struct {
+	Type    string   `json:"type"`
+	Default string   `json:"default"`
+	Min     *int64   `json:"min,omitempty"`
+	Max     *int64   `json:"max,omitempty"`
+	Values  []string `json:"values,omitempty"`
+
+	// The value set by a client. The default applies when it is nil.
+	Value *string `json:"value,omitempty"`
+}
+
+// flag is a flag as it is returned to clients, with typed values.
+type flag struct {
+	Name    string      `json:"name"`
+	Type    string      `json:"type"`
+	Value   interface{} `json:"value"`
+	Default interface{} `json:"default"`
+	IsSet   bool        `json:"isSet"`
+	Min     *int64      `json:"min,omitempty"`
+	Max     *int64      `json:"max,omitempty"`
+	Values  []string    `json:"values,omitempty"`
+	Index   uint64      `json:"index"`
+}
+
+// flagPath returns the key that holds a given flag.
+func flagPath(name string) string {
+	return path.Join(prefix, name)
+}
+
+// validate checks the type and the constraints of a definition and that
+// its default satisfies them.
+func (d *definition) validate() error {
+	switch d.Type {
+	case boolType, stringType:
+		if d.Min != nil || d.Max != nil || len(d.Values) > 0 {
+			return fmt.Errorf("%s flags take no min, max or values", d.Type)
+		}
+	case intType:
+		if len(d.Values) > 0 {
+			return fmt.Errorf("int flags take no values")
+		}
+		if d.Min != nil && d.Max != nil && *d.Min > *d.Max {
+			return fmt.Errorf("min %d is above max %d", *d.Min, *d.Max)
+		}
+	case enumType:
+		if d.Min != nil || d.Max != nil {
+			return fmt.Errorf("enum flags take no min or max")
+		}
+		if len(d.Values) == 0 {
+			return fmt.Errorf("enum flags need values")
+		}
+	default:
+		return fmt.Errorf("unknown type %q", d.Type)
+	}
+	if _, err := d.parse(d.Default); err != nil {
+		return fmt.Errorf("invalid default: %v", err)
+	}
+	return nil
+}
+
+// parse converts a raw value to the type of the flag and checks it against
+// the constraints.
+func (d *definition) parse(s string) (interface{}, error) {
+	switch d.Type {
+	case boolType:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a bool", s)
+		}
+		return b, nil
+
+	case intType:
+		i, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an int", s)
+		}
+		if d.Min != nil && i < *d.Min {
+			return nil, fmt.Errorf("%d is below the min of %d", i, *d.Min)
+		}
+		if d.Max != nil && i > *d.Max {
+			return nil, fmt.Errorf("%d is above the max of %d", i, *d.Max)
+		}
+		return i, nil
+
+	case enumType:
+		for _, v := range d.Values {
+			if v == s {
+				return s, nil
+			}
+		}
+		return nil, fmt.Errorf("%q is not one of %v", s, d.Values)
+	}
+	return s, nil
+}
+
+// flag returns the typed form of the definition.
+func (d *definition) flag(name string, index uint64) *flag {
+	f := &flag{
+		Name:   name,
+		Type:   d.Type,
+		Min:    d.Min,
+		Max:    d.Max,
+		Values: d.Values,
+		Index:  index,
+	}
+	f.Default, _ = d.parse(d.Default)
+	f.Value = f.Default
+	if d.Value != nil {
+		if v, err := d.parse(*d.Value); err == nil {
+			f.Value, f.IsSet = v, true
+		}
+	}
+	return f
+}
+
+// encode returns the stored form of the definition.
+func (d *definition) encode() string {
+	b, _ := json.Marshal(d)
+	return string(b)
+}
+
+// parseDefinition decodes a stored flag.
+func parseDefinition(node *etcd.Node) (*definition, error) {
+	d := &definition{}
+	if err := json.Unmarshal([]byte(node.Value), d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
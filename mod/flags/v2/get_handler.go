@@ -0,0 +1,125 @@
+package v2
+
+import (
+	"encoding/json"
+	"net/http"
+	"path"
+	"strconv"
+
+	"github.com/coreos/etcd/lifetime"
+	"github.com/coreos/go-etcd/etcd"
+	"github.com/gorilla/mux"
+)
+
+// event is a change to a flag as it is delivered to watchers. The flag is
+// absent when it was deleted.
+type event struct {
+	Action string `json:"action"`
+	Name   string `json:"name"`
+	Flag   *flag  `json:"flag,omitempty"`
+}
+
+// getHandler retrieves a flag and its typed value.
+// With "wait=true" the request waits for the next change to the flag, or the
+// first one since "waitIndex", and returns it as an event.
+func (h *handler) getHandler(w http.ResponseWriter, req *http.Request) {
+	h.client.SyncCluster()
+
+	name := mux.Vars(req)["name"]
+	if req.FormValue("wait") == "true" {
+		h.waitHandler(w, req, flagPath(name), false)
+		return
+	}
+
+	resp, err := h.client.Get(flagPath(name), false, false)
+	if err != nil {
+		http.Error(w, "get flag error: "+err.Error(), http.StatusNotFound)
+		return
+	}
+	d, err := parseDefinition(resp.Node)
+	if err != nil {
+		http.Error(w, "get flag error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(d.flag(name, resp.Node.ModifiedIndex))
+}
+
+// listHandler retrieves every flag and its typed value, sorted by name.
+// With "wait=true" the request waits for a change to any flag instead.
+func (h *handler) listHandler(w http.ResponseWriter, req *http.Request) {
+	h.client.SyncCluster()
+
+	if req.FormValue("wait") == "true" {
+		h.waitHandler(w, req, prefix, true)
+		return
+	}
+
+	flags := make([]*flag, 0)
+	resp, err := h.client.Get(prefix, true, false)
+	if err != nil {
+		if e, ok := err.(etcd.EtcdError); !ok || e.ErrorCode != 100 {
+			http.Error(w, "get flags error: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+	} else {
+		for _, node := range resp.Node.Nodes {
+			if d, err := parseDefinition(&node); err == nil {
+				flags = append(flags, d.flag(path.Base(node.Key), node.ModifiedIndex))
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(flags)
+}
+
+// waitHandler waits for a change to a flag, or to any flag when recursive is
+// set, and writes it as a typed event. The watch stops when the client
+// disconnects.
+func (h *handler) waitHandler(w http.ResponseWriter, req *http.Request, key string, recursive bool) {
+	var waitIndex uint64
+	if s := req.FormValue("waitIndex"); s != "" {
+		var err error
+		if waitIndex, err = strconv.ParseUint(s, 10, 64); err != nil {
+			http.Error(w, "invalid waitIndex: "+s, http.StatusBadRequest)
+			return
+		}
+	}
+
+	l := lifetime.Start(w, -1)
+	defer l.Stop()
+	stopChan := make(chan bool)
+	go func() {
+		<-l.Done()
+		close(stopChan)
+	}()
+
+	for {
+		resp, err := h.client.Watch(key, waitIndex, recursive, nil, stopChan)
+		if err == etcd.ErrWatchStoppedByUser {
+			return
+		} else if err != nil {
+			http.Error(w, "watch flag error: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		// Nested keys are not flags and are skipped.
+		if recursive && path.Dir(resp.Node.Key) != prefix {
+			waitIndex = resp.Node.ModifiedIndex + 1
+			continue
+		}
+
+		e := &event{Action: resp.Action, Name: path.Base(resp.Node.Key)}
+		if resp.Action != "delete" && resp.Action != "expire" {
+			if d, err := parseDefinition(resp.Node); err == nil {
+				e.Flag = d.flag(e.Name, resp.Node.ModifiedIndex)
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(e)
+		return
+	}
+}
@@ -0,0 +1,34 @@
+package v2
+
+import (
+	"net/http"
+
+	"github.com/coreos/go-etcd/etcd"
+	"github.com/gorilla/mux"
+)
+
+const prefix = "/_etcd/mod/flags"
+
+// handler manages the flags HTTP request.
+type handler struct {
+	*mux.Router
+	client *etcd.Client
+}
+
+// NewHandler creates an HTTP handler that can be registered on a router.
+// Its requests to the etcd server at addr are sent through tr.
+func NewHandler(addr string, tr http.RoundTripper) http.Handler {
+	h := &handler{
+		Router: mux.NewRouter(),
+		client: etcd.NewClient([]string{addr}),
+	}
+	h.client.SetTransport(tr)
+	h.StrictSlash(false)
+	h.HandleFunc("/flags", h.listHandler).Methods("GET")
+	h.HandleFunc("/flags/{name:[a-zA-Z0-9_.-]+}", h.getHandler).Methods("GET")
+	h.HandleFunc("/flags/{name:[a-zA-Z0-9_.-]+}", h.defineHandler).Methods("PUT")
+	h.HandleFunc("/flags/{name:[a-zA-Z0-9_.-]+}", h.deleteHandler).Methods("DELETE")
+	h.HandleFunc("/flags/{name:[a-zA-Z0-9_.-]+}/value", h.setValueHandler).Methods("PUT")
+	h.HandleFunc("/flags/{name:[a-zA-Z0-9_.-]+}/value", h.resetValueHandler).Methods("DELETE")
+	return h
+}
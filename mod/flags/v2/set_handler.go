@@ -0,0 +1,161 @@
+package v2
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/coreos/go-etcd/etcd"
+	"github.com/gorilla/mux"
+)
+
+// defineHandler creates or redefines a flag.
+// The "type" parameter is one of bool, int, string or enum and "default" is
+// the value used until one is set. Int flags take optional "min" and "max"
+// bounds and enum flags a comma-separated list of "values". A value set
+// before the flag was redefined is kept if it is still valid.
+func (h *handler) defineHandler(w http.ResponseWriter, req *http.Request) {
+	h.client.SyncCluster()
+
+	name := mux.Vars(req)["name"]
+	d := &definition{
+		Type:    req.FormValue("type"),
+		Default: req.FormValue("default"),
+	}
+	for _, bound := range []struct {
+		param string
+		value **int64
+	}{{"min", &d.Min}, {"max", &d.Max}} {
+		if s := req.FormValue(bound.param); s != "" {
+			i, err := strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				http.Error(w, "invalid "+bound.param+": "+s, http.StatusBadRequest)
+				return
+			}
+			*bound.value = &i
+		}
+	}
+	if s := req.FormValue("values"); s != "" {
+		for _, v := range strings.Split(s, ",") {
+			d.Values = append(d.Values, strings.TrimSpace(v))
+		}
+	}
+	if err := d.validate(); err != nil {
+		http.Error(w, "invalid flag: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.update(name, true, func(prev *definition) (*definition, error) {
+		d.Value = nil
+		if prev != nil && prev.Value != nil {
+			if _, err := d.parse(*prev.Value); err == nil {
+				d.Value = prev.Value
+			}
+		}
+		return d, nil
+	})
+	if err != nil {
+		http.Error(w, "set flag error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// setValueHandler sets the value of a flag from the "value" parameter. Values
+// that do not match the type or constraints of the flag are rejected.
+func (h *handler) setValueHandler(w http.ResponseWriter, req *http.Request) {
+	h.client.SyncCluster()
+
+	name := mux.Vars(req)["name"]
+	value := req.FormValue("value")
+	resp, err := h.update(name, false, func(prev *definition) (*definition, error) {
+		if _, err := prev.parse(value); err != nil {
+			return nil, err
+		}
+		prev.Value = &value
+		return prev, nil
+	})
+	h.writeUpdate(w, "set flag value error: ", resp, err)
+}
+
+// resetValueHandler clears the value of a flag so that its default applies.
+func (h *handler) resetValueHandler(w http.ResponseWriter, req *http.Request) {
+	h.client.SyncCluster()
+
+	name := mux.Vars(req)["name"]
+	resp, err := h.update(name, false, func(prev *definition) (*definition, error) {
+		prev.Value = nil
+		return prev, nil
+	})
+	h.writeUpdate(w, "reset flag value error: ", resp, err)
+}
+
+// errFlagNotFound is returned when updating a flag that is not defined.
+type errFlagNotFound string
+
+func (e errFlagNotFound) Error() string {
+	return "flag not found: " + string(e)
+}
+
+// errInvalidValue wraps the reason a value was rejected.
+type errInvalidValue struct {
+	error
+}
+
+// update changes a flag with fn, retrying when it was changed concurrently.
+// fn gets the current definition, which is nil only when create is set and
+// the flag does not exist yet.
+func (h *handler) update(name string, create bool, fn func(*definition) (*definition, error)) (*flag, error) {
+	key := flagPath(name)
+	for {
+		var prev *definition
+		var prevIndex uint64
+		if resp, err := h.client.Get(key, false, false); err == nil {
+			if prev, err = parseDefinition(resp.Node); err != nil {
+				return nil, err
+			}
+			prevIndex = resp.Node.ModifiedIndex
+		} else if e, ok := err.(etcd.EtcdError); !ok || e.ErrorCode != 100 {
+			return nil, err
+		} else if !create {
+			return nil, errFlagNotFound(name)
+		}
+
+		d, err := fn(prev)
+		if err != nil {
+			return nil, errInvalidValue{err}
+		}
+
+		var resp *etcd.Response
+		if prev == nil {
+			resp, err = h.client.Create(key, d.encode(), 0)
+		} else {
+			resp, err = h.client.CompareAndSwap(key, d.encode(), 0, "", prevIndex)
+		}
+		if e, ok := err.(etcd.EtcdError); ok && (e.ErrorCode == 101 || e.ErrorCode == 105) {
+			// Changed or created since it was read.
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		return d.flag(name, resp.Node.ModifiedIndex), nil
+	}
+}
+
+// writeUpdate writes the flag changed by update or the error it returned.
+func (h *handler) writeUpdate(w http.ResponseWriter, msg string, f *flag, err error) {
+	switch err.(type) {
+	case nil:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(f)
+	case errFlagNotFound:
+		http.Error(w, msg+err.Error(), http.StatusNotFound)
+	case errInvalidValue:
+		http.Error(w, msg+err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, msg+err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,127 @@
+package flags
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/coreos/etcd/server"
+	"github.com/coreos/etcd/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+// Ensure that flags return typed values and fall back to their default.
+func TestModFlagsTypedValues(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		resp, _ := testDefineFlag(s, "workers", url.Values{"type": {"int"}, "default": {"8"}, "min": {"1"}, "max": {"64"}})
+		assert.Equal(t, resp.StatusCode, 200)
+		body := tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["type"], "int")
+		assert.Equal(t, body["value"], 8)
+		assert.Equal(t, body["isSet"], false)
+
+		resp, _ = testSetFlag(s, "workers", "16")
+		assert.Equal(t, resp.StatusCode, 200)
+		tests.ReadBody(resp)
+
+		resp, _ = tests.Get(fmt.Sprintf("%s/mod/v2/flags/workers", s.URL()))
+		body = tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["value"], 16)
+		assert.Equal(t, body["default"], 8)
+		assert.Equal(t, body["isSet"], true)
+
+		resp, _ = testDefineFlag(s, "debug", url.Values{"type": {"bool"}, "default": {"false"}})
+		tests.ReadBody(resp)
+		resp, _ = testSetFlag(s, "debug", "true")
+		body = tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["value"], true)
+
+		// Resetting goes back to the default.
+		resp, _ = tests.DeleteForm(fmt.Sprintf("%s/mod/v2/flags/workers/value", s.URL()), nil)
+		body = tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["value"], 8)
+		assert.Equal(t, body["isSet"], false)
+
+		resp, _ = tests.Get(fmt.Sprintf("%s/mod/v2/flags", s.URL()))
+		assert.Equal(t, resp.StatusCode, 200)
+		tests.ReadBody(resp)
+
+		resp, _ = tests.DeleteForm(fmt.Sprintf("%s/mod/v2/flags/workers", s.URL()), nil)
+		assert.Equal(t, resp.StatusCode, 200)
+		tests.ReadBody(resp)
+		resp, _ = tests.Get(fmt.Sprintf("%s/mod/v2/flags/workers", s.URL()))
+		assert.Equal(t, resp.StatusCode, 404)
+		tests.ReadBody(resp)
+	})
+}
+
+// Ensure that invalid definitions and values are rejected.
+func TestModFlagsInvalid(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		resp, _ := testDefineFlag(s, "workers", url.Values{"type": {"int"}, "default": {"100"}, "max": {"64"}})
+		assert.Equal(t, resp.StatusCode, 400)
+		assert.Equal(t, string(tests.ReadBody(resp)), "invalid flag: invalid default: 100 is above the max of 64\n")
+
+		resp, _ = testDefineFlag(s, "workers", url.Values{"type": {"float"}, "default": {"1"}})
+		assert.Equal(t, resp.StatusCode, 400)
+		tests.ReadBody(resp)
+
+		resp, _ = testDefineFlag(s, "level", url.Values{"type": {"enum"}, "default": {"info"}, "values": {"debug,info"}})
+		assert.Equal(t, resp.StatusCode, 200)
+		tests.ReadBody(resp)
+
+		resp, _ = testSetFlag(s, "level", "trace")
+		assert.Equal(t, resp.StatusCode, 400)
+		tests.ReadBody(resp)
+
+		resp, _ = testSetFlag(s, "missing", "1")
+		assert.Equal(t, resp.StatusCode, 404)
+		tests.ReadBody(resp)
+
+		// Redefining drops a value that is no longer valid.
+		resp, _ = testSetFlag(s, "level", "debug")
+		tests.ReadBody(resp)
+		resp, _ = testDefineFlag(s, "level", url.Values{"type": {"enum"}, "default": {"info"}, "values": {"info,warning"}})
+		body := tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["value"], "info")
+		assert.Equal(t, body["isSet"], false)
+	})
+}
+
+// Ensure that watching a flag returns a typed change event.
+func TestModFlagsWatch(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		resp, _ := testDefineFlag(s, "workers", url.Values{"type": {"int"}, "default": {"8"}})
+		tests.ReadBody(resp)
+
+		c := make(chan map[string]interface{})
+		go func() {
+			resp, _ := tests.Get(fmt.Sprintf("%s/mod/v2/flags/workers?wait=true", s.URL()))
+			c <- tests.ReadBodyJSON(resp)
+		}()
+		time.Sleep(200 * time.Millisecond)
+
+		resp, _ = testSetFlag(s, "workers", "4")
+		tests.ReadBody(resp)
+
+		select {
+		case body := <-c:
+			assert.Equal(t, body["action"], "compareAndSwap")
+			assert.Equal(t, body["name"], "workers")
+			flag := body["flag"].(map[string]interface{})
+			assert.Equal(t, flag["value"], 4)
+		case <-time.After(2 * time.Second):
+			t.Fatal("watch did not return")
+		}
+	})
+}
+
+func testDefineFlag(s *server.Server, name string, v url.Values) (*http.Response, error) {
+	return tests.PutForm(fmt.Sprintf("%s/mod/v2/flags/%s", s.URL(), name), v)
+}
+
+func testSetFlag(s *server.Server, name string, value string) (*http.Response, error) {
+	return tests.PutForm(fmt.Sprintf("%s/mod/v2/flags/%s/value", s.URL(), name), url.Values{"value": {value}})
+}
@@ -0,0 +1,24 @@
+package v2
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/gorilla/mux"
+)
+
+// deleteHandler removes a candidate (or the current leader) for the given key.
+// The "name" parameter specifies the candidate to remove.
+func (h *handler) deleteHandler(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	name := req.FormValue("name")
+	if len(name) == 0 {
+		http.Error(w, "delete leader error: name required", http.StatusInternalServerError)
+		return
+	}
+
+	if _, err := h.lockRequest(w, "DELETE", vars["key"], url.Values{"value": {name}}); err != nil {
+		http.Error(w, "delete leader error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
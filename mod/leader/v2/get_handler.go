@@ -0,0 +1,66 @@
+package v2
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/gorilla/mux"
+)
+
+// getHandler retrieves the name of the current leader.
+// The "wait" parameter blocks until the leader changes. If "name" is also
+// given then it blocks until that candidate is the leader, which lets a
+// standby learn of its promotion without polling.
+// The "stream" parameter writes the current leader and then each new leader,
+// one name per line, until the client disconnects.
+func (h *handler) getHandler(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	name := req.FormValue("name")
+
+	leader, err := h.lockRequest(w, "GET", vars["key"], url.Values{"field": {"value"}})
+	if err != nil {
+		http.Error(w, "get leader error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	switch {
+	case req.FormValue("stream") == "true":
+		flusher, _ := w.(http.Flusher)
+		for {
+			if _, err := w.Write([]byte(leader + "\n")); err != nil {
+				return
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+			if leader, err = h.waitForChange(w, vars["key"], leader); err != nil {
+				return
+			}
+		}
+
+	case req.FormValue("wait") == "true":
+		if len(name) > 0 && leader == name {
+			break
+		}
+		for {
+			if leader, err = h.waitForChange(w, vars["key"], leader); err != nil {
+				http.Error(w, "get leader error: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if len(name) == 0 || leader == name {
+				break
+			}
+		}
+	}
+
+	w.Write([]byte(leader))
+}
+
+// waitForChange blocks until the leader for a key is no longer prev and returns the new leader.
+func (h *handler) waitForChange(w http.ResponseWriter, key string, prev string) (string, error) {
+	params := url.Values{"field": {"value"}, "wait": {"true"}}
+	if len(prev) > 0 {
+		params.Set("prevValue", prev)
+	}
+	return h.lockRequest(w, "GET", key, params)
+}
@@ -0,0 +1,86 @@
+package v2
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/coreos/etcd/lifetime"
+	"github.com/gorilla/mux"
+)
+
+// handler manages the leader HTTP request.
+// Leadership is implemented on top of the lock module: the leader is the
+// current holder of the lock and the candidates are its waiters.
+type handler struct {
+	*mux.Router
+	lock http.Handler
+}
+
+// NewHandler creates an HTTP handler that can be registered on a router.
+// The lock module requests are served by lock in-process.
+func NewHandler(lock http.Handler) http.Handler {
+	h := &handler{
+		Router: mux.NewRouter(),
+		lock:   lock,
+	}
+	h.StrictSlash(false)
+	h.HandleFunc("/{key:.*}/proclaim", h.proclaimHandler).Methods("PUT")
+	h.HandleFunc("/{key:.*}", h.getHandler).Methods("GET")
+	h.HandleFunc("/{key:.*}", h.setHandler).Methods("PUT")
+	h.HandleFunc("/{key:.*}", h.deleteHandler).Methods("DELETE")
+	return h
+}
+
+// lockRequest sends a request to the lock module and returns the response body.
+// The request is cancelled if the client disconnects from w.
+func (h *handler) lockRequest(w http.ResponseWriter, method string, key string, params url.Values) (string, error) {
+	l := lifetime.Start(w, -1)
+	defer l.Stop()
+	return h.cancelableLockRequest(l.Done(), method, key, params)
+}
+
+// cancelableLockRequest sends a request to the lock module and returns the
+// response body. The request is cancelled once closeChan is readable.
+func (h *handler) cancelableLockRequest(closeChan <-chan bool, method string, key string, params url.Values) (string, error) {
+	r, err := http.NewRequest(method, "/"+key+"?"+params.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+
+	lw := &lockResponseWriter{header: make(http.Header), code: http.StatusOK, closeChan: closeChan}
+	h.lock.ServeHTTP(lw, r)
+	if lw.code != http.StatusOK {
+		return "", errors.New(lw.body.String())
+	}
+	return lw.body.String(), nil
+}
+
+// lockResponseWriter collects the answer of the lock module. It reports the
+// request as gone once closeChan is readable, the way a disconnecting client
+// would be.
+type lockResponseWriter struct {
+	header    http.Header
+	code      int
+	body      bytes.Buffer
+	closeChan <-chan bool
+}
+
+func (w *lockResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *lockResponseWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+func (w *lockResponseWriter) WriteHeader(code int) {
+	w.code = code
+}
+
+// CloseNotify returns nil for requests that cannot be cancelled, which
+// lifetime treats as a client that cannot report going away.
+func (w *lockResponseWriter) CloseNotify() <-chan bool {
+	return w.closeChan
+}
@@ -0,0 +1,62 @@
+package v2
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// queueEntry is a lock node as listed by the lock module.
+type queueEntry struct {
+	Index int    `json:"index"`
+	Value string `json:"value"`
+	TTL   int64  `json:"ttl"`
+}
+
+// proclaimHandler replaces the value the current leader is known by, for
+// example to advertise a new endpoint, without stepping down.
+// The "name" parameter specifies the current leader and "value" the value it
+// is known by from then on. The leadership keeps its lock index, so observers
+// see a new value rather than a new leader. The "ttl" parameter optionally
+// renews the TTL at the same time.
+func (h *handler) proclaimHandler(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	name := req.FormValue("name")
+	value := req.FormValue("value")
+	if len(name) == 0 || len(value) == 0 {
+		http.Error(w, "proclaim leader error: name and value required", http.StatusInternalServerError)
+		return
+	}
+
+	body, err := h.lockRequest(w, "GET", vars["key"], url.Values{"field": {"queue"}})
+	if err != nil {
+		http.Error(w, "proclaim leader error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var queue []queueEntry
+	if err := json.Unmarshal([]byte(body), &queue); err != nil {
+		http.Error(w, "proclaim leader error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if len(queue) == 0 || queue[0].Value != name {
+		http.Error(w, "proclaim leader error: not the leader: "+name, http.StatusInternalServerError)
+		return
+	}
+
+	// Keep the remaining TTL unless a new one is given.
+	ttl := req.FormValue("ttl")
+	if len(ttl) == 0 {
+		ttl = strconv.FormatInt(queue[0].TTL, 10)
+	}
+
+	// The lock node is updated in place, so it keeps its index; if it
+	// expired in the meantime the update fails and nothing is proclaimed.
+	params := url.Values{"index": {strconv.Itoa(queue[0].Index)}, "value": {value}, "ttl": {ttl}}
+	if _, err := h.lockRequest(w, "PUT", vars["key"], params); err != nil {
+		http.Error(w, "proclaim leader error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
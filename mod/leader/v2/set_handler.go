@@ -0,0 +1,95 @@
+package v2
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/coreos/etcd/lifetime"
+	"github.com/gorilla/mux"
+)
+
+// setHandler attempts to become the leader for the given key.
+// The "name" parameter specifies the name of the candidate.
+// The "ttl" parameter specifies how long the leadership will persist for.
+// The request blocks until the candidate is the leader, or for at most
+// "wait" seconds. Sending the same request again as the leader renews the TTL.
+// The "deleteOnDisconnect" parameter keeps the connection open once the
+// candidate is the leader, renewing the TTL while it lasts, and steps down as
+// soon as the client disconnects instead of waiting for the TTL to expire.
+// The response ends if the leadership is lost.
+func (h *handler) setHandler(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	name := req.FormValue("name")
+	if len(name) == 0 {
+		http.Error(w, "set leader error: name required", http.StatusInternalServerError)
+		return
+	}
+
+	// Wait in the lock queue until we hold the lock.
+	params := url.Values{"value": {name}, "ttl": {req.FormValue("ttl")}}
+	for _, p := range []string{"wait", "timeout"} {
+		if v := req.FormValue(p); len(v) > 0 {
+			params.Set(p, v)
+		}
+	}
+	if _, err := h.lockRequest(w, "POST", vars["key"], params); err != nil {
+		http.Error(w, "set leader error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Refresh the TTL in case we were already the leader.
+	if _, err := h.lockRequest(w, "PUT", vars["key"], url.Values{"value": {name}, "ttl": {req.FormValue("ttl")}}); err != nil {
+		http.Error(w, "set leader error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if req.FormValue("deleteOnDisconnect") == "true" {
+		// Hold on to the lock index rather than the name, which changes
+		// when the leader proclaims a new value.
+		index, err := h.lockRequest(w, "GET", vars["key"], url.Values{"field": {"index"}})
+		if err != nil {
+			http.Error(w, "set leader error: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		h.holdLeadership(w, vars["key"], index, req.FormValue("ttl"))
+	}
+}
+
+// holdLeadership renews the leadership held by the given lock index until the
+// client disconnects and then removes it.
+func (h *handler) holdLeadership(w http.ResponseWriter, key string, index string, ttl string) {
+	interval := time.Second
+	if n, err := strconv.Atoi(ttl); err == nil && n > 1 {
+		interval = time.Duration(n) * time.Second / 2
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+
+	// The lifetime is closed once the client disconnects, so every renewal
+	// can wait on it.
+	l := lifetime.Start(w, -1)
+	defer l.Stop()
+	doneChan := l.Done()
+
+	for {
+		select {
+		case <-doneChan:
+			h.cancelableLockRequest(nil, "DELETE", key, url.Values{"index": {index}})
+			return
+		case <-time.After(interval):
+			if _, err := h.cancelableLockRequest(doneChan, "PUT", key, url.Values{"index": {index}, "ttl": {ttl}}); err != nil {
+				// The leadership was lost: nothing is left to remove.
+				select {
+				case <-doneChan:
+				default:
+					return
+				}
+			}
+		}
+	}
+}
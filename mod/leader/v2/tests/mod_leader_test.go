@@ -0,0 +1,199 @@
+package leader
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/coreos/etcd/server"
+	"github.com/coreos/etcd/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+// Ensure that a leader can be set and read.
+func TestModLeaderSet(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		// Set leader.
+		body, err := testSetLeader(s, "foo", "xxx", 10)
+		assert.NoError(t, err)
+		assert.Equal(t, body, "")
+
+		// Check that the leader is set.
+		body, err = testGetLeader(s, "foo", "")
+		assert.NoError(t, err)
+		assert.Equal(t, body, "xxx")
+
+		// Delete leader.
+		body, err = testDeleteLeader(s, "foo", "xxx")
+		assert.NoError(t, err)
+		assert.Equal(t, body, "")
+
+		// Check that the leader is removed.
+		body, err = testGetLeader(s, "foo", "")
+		assert.NoError(t, err)
+		assert.Equal(t, body, "")
+	})
+}
+
+// Ensure that the leader TTL can be renewed by setting it again.
+func TestModLeaderRenew(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		testSetLeader(s, "foo", "xxx", 2)
+		time.Sleep(1 * time.Second)
+
+		body, err := testSetLeader(s, "foo", "xxx", 3)
+		assert.NoError(t, err)
+		assert.Equal(t, body, "")
+		time.Sleep(2 * time.Second)
+
+		body, _ = testGetLeader(s, "foo", "")
+		assert.Equal(t, body, "xxx")
+	})
+}
+
+// Ensure that a standby is notified as soon as it becomes the leader.
+func TestModLeaderStandbyNotification(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		testSetLeader(s, "foo", "xxx", 10)
+
+		// Queue up a second candidate.
+		go testSetLeader(s, "foo", "yyy", 10)
+		time.Sleep(500 * time.Millisecond)
+
+		// Wait for promotion in the background.
+		c := make(chan string)
+		go func() {
+			body, _ := testGetLeader(s, "foo", "?wait=true&name=yyy")
+			c <- body
+		}()
+
+		// The standby should still be waiting.
+		select {
+		case <-c:
+			t.Fatal("standby notified before promotion")
+		case <-time.After(500 * time.Millisecond):
+		}
+
+		// Step down and check that the standby is told it is now the leader.
+		testDeleteLeader(s, "foo", "xxx")
+		select {
+		case body := <-c:
+			assert.Equal(t, body, "yyy")
+		case <-time.After(5 * time.Second):
+			t.Fatal("standby not notified")
+		}
+	})
+}
+
+// Ensure that a stream of leader changes can be followed.
+func TestModLeaderStream(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		testSetLeader(s, "foo", "xxx", 10)
+
+		resp, err := tests.Get(fmt.Sprintf("%s/mod/v2/leader/foo?stream=true", s.URL()))
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		var buf = make([]byte, 4)
+		n, _ := resp.Body.Read(buf)
+		assert.Equal(t, string(buf[:n]), "xxx\n")
+
+		go testSetLeader(s, "foo", "yyy", 10)
+		time.Sleep(500 * time.Millisecond)
+		testDeleteLeader(s, "foo", "xxx")
+
+		n, _ = resp.Body.Read(buf)
+		assert.Equal(t, string(buf[:n]), "yyy\n")
+	})
+}
+
+// Ensure that a leader holding the connection steps down as soon as it disconnects.
+func TestModLeaderDeleteOnDisconnect(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		resp, err := tests.PutForm(fmt.Sprintf("%s/mod/v2/leader/foo?name=xxx&ttl=2&deleteOnDisconnect=true", s.URL()), nil)
+		assert.NoError(t, err)
+		assert.Equal(t, resp.StatusCode, 200)
+
+		// The leadership outlives the TTL while the connection is open.
+		time.Sleep(3 * time.Second)
+		body, _ := testGetLeader(s, "foo", "")
+		assert.Equal(t, body, "xxx")
+
+		resp.Body.Close()
+		time.Sleep(500 * time.Millisecond)
+		body, _ = testGetLeader(s, "foo", "")
+		assert.Equal(t, body, "")
+	})
+}
+
+// Ensure that the leader can proclaim a new value without losing its lock index.
+func TestModLeaderProclaim(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		testSetLeader(s, "foo", "xxx", 10)
+		index, _ := testGetLockIndex(s, "foo")
+
+		// Only the leader can proclaim.
+		resp, err := tests.PutForm(fmt.Sprintf("%s/mod/v2/leader/foo/proclaim?name=yyy&value=zzz", s.URL()), nil)
+		assert.NoError(t, err)
+		assert.Equal(t, resp.StatusCode, 500)
+		resp.Body.Close()
+
+		resp, err = tests.PutForm(fmt.Sprintf("%s/mod/v2/leader/foo/proclaim?name=xxx&value=xxx2", s.URL()), nil)
+		assert.NoError(t, err)
+		assert.Equal(t, resp.StatusCode, 200)
+		resp.Body.Close()
+
+		body, _ := testGetLeader(s, "foo", "")
+		assert.Equal(t, body, "xxx2")
+		body, _ = testGetLockIndex(s, "foo")
+		assert.Equal(t, body, index)
+	})
+}
+
+// Ensure that a leader holding the connection keeps its leadership after a proclamation.
+func TestModLeaderProclaimDeleteOnDisconnect(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		resp, err := tests.PutForm(fmt.Sprintf("%s/mod/v2/leader/foo?name=xxx&ttl=2&deleteOnDisconnect=true", s.URL()), nil)
+		assert.NoError(t, err)
+		assert.Equal(t, resp.StatusCode, 200)
+
+		r, err := tests.PutForm(fmt.Sprintf("%s/mod/v2/leader/foo/proclaim?name=xxx&value=xxx2", s.URL()), nil)
+		assert.NoError(t, err)
+		assert.Equal(t, r.StatusCode, 200)
+		r.Body.Close()
+
+		// The renewals carry on under the new value.
+		time.Sleep(3 * time.Second)
+		body, _ := testGetLeader(s, "foo", "")
+		assert.Equal(t, body, "xxx2")
+
+		resp.Body.Close()
+		time.Sleep(500 * time.Millisecond)
+		body, _ = testGetLeader(s, "foo", "")
+		assert.Equal(t, body, "")
+	})
+}
+
+func testSetLeader(s *server.Server, key string, name string, ttl int) (string, error) {
+	resp, err := tests.PutForm(fmt.Sprintf("%s/mod/v2/leader/%s?name=%s&ttl=%d", s.URL(), key, name, ttl), nil)
+	ret := tests.ReadBody(resp)
+	return string(ret), err
+}
+
+func testGetLeader(s *server.Server, key string, query string) (string, error) {
+	resp, err := tests.Get(fmt.Sprintf("%s/mod/v2/leader/%s%s", s.URL(), key, query))
+	ret := tests.ReadBody(resp)
+	return string(ret), err
+}
+
+func testDeleteLeader(s *server.Server, key string, name string) (string, error) {
+	resp, err := tests.DeleteForm(fmt.Sprintf("%s/mod/v2/leader/%s?name=%s", s.URL(), key, name), nil)
+	ret := tests.ReadBody(resp)
+	return string(ret), err
+}
+
+func testGetLockIndex(s *server.Server, key string) (string, error) {
+	resp, err := tests.Get(fmt.Sprintf("%s/mod/v2/lock/%s?field=index", s.URL(), key))
+	ret := tests.ReadBody(resp)
+	return string(ret), err
+}
@@ -0,0 +1,45 @@
+package v2
+
+import (
+	"net/http"
+	"path"
+
+	"github.com/gorilla/mux"
+)
+
+// attachHandler sets a key and attaches it to a lease so that the key is
+// deleted when the lease expires or is revoked.
+// The "value" parameter specifies the value to set on the key.
+func (h *handler) attachHandler(w http.ResponseWriter, req *http.Request) {
+	h.client.SyncCluster()
+
+	vars := mux.Vars(req)
+	id := vars["id"]
+	key := path.Join("/", vars["key"])
+	value := req.FormValue("value")
+
+	// Only attach to leases that are still alive.
+	if _, err := h.client.Get(leasePath(id), false, false); err != nil {
+		http.Error(w, "attach lease error: "+err.Error(), http.StatusNotFound)
+		return
+	}
+
+	// Record the attachment before writing the key so an expiration in
+	// between never leaves an orphaned key behind.
+	if _, err := h.client.AddChild(attachmentsPath(id), key, 0); err != nil {
+		http.Error(w, "attach lease error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if _, err := h.client.Set(key, value, 0); err != nil {
+		http.Error(w, "attach lease error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// The lease may have expired while attaching; clean up after the reaper.
+	if _, err := h.client.Get(leasePath(id), false, false); err != nil {
+		h.revoke(id)
+		http.Error(w, "attach lease error: "+err.Error(), http.StatusNotFound)
+		return
+	}
+}
@@ -0,0 +1,28 @@
+package v2
+
+import (
+	"net/http"
+	"path"
+	"strconv"
+)
+
+// createHandler creates a new lease and returns its id.
+// The "ttl" parameter specifies how long the lease will persist without a keep alive.
+func (h *handler) createHandler(w http.ResponseWriter, req *http.Request) {
+	h.client.SyncCluster()
+
+	ttl, err := strconv.Atoi(req.FormValue("ttl"))
+	if err != nil || ttl <= 0 {
+		http.Error(w, "invalid ttl: "+req.FormValue("ttl"), http.StatusInternalServerError)
+		return
+	}
+
+	// Store the TTL as the value so that keep alives can reuse it.
+	resp, err := h.client.AddChild(path.Join(prefix, "leases"), strconv.Itoa(ttl), uint64(ttl))
+	if err != nil {
+		http.Error(w, "create lease error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write([]byte(path.Base(resp.Node.Key)))
+}
@@ -0,0 +1,37 @@
+package v2
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// leaseResponse is the JSON representation of a lease.
+type leaseResponse struct {
+	ID   string   `json:"id"`
+	TTL  int64    `json:"ttl"`
+	Keys []string `json:"keys"`
+}
+
+// getHandler retrieves the remaining TTL of a lease and the keys attached to it.
+func (h *handler) getHandler(w http.ResponseWriter, req *http.Request) {
+	h.client.SyncCluster()
+
+	id := mux.Vars(req)["id"]
+	resp, err := h.client.Get(leasePath(id), false, false)
+	if err != nil {
+		http.Error(w, "get lease error: "+err.Error(), http.StatusNotFound)
+		return
+	}
+
+	l := &leaseResponse{ID: id, TTL: resp.Node.TTL, Keys: make([]string, 0)}
+	if resp, err = h.client.Get(attachmentsPath(id), true, false); err == nil {
+		for _, node := range resp.Node.Nodes {
+			l.Keys = append(l.Keys, node.Value)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(l)
+}
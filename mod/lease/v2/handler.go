@@ -0,0 +1,36 @@
+package v2
+
+import (
+	"net/http"
+
+	"github.com/coreos/go-etcd/etcd"
+	"github.com/gorilla/mux"
+)
+
+const prefix = "/_etcd/mod/lease"
+
+// handler manages the lease HTTP request.
+type handler struct {
+	*mux.Router
+	client *etcd.Client
+}
+
+// NewHandler creates an HTTP handler that can be registered on a router.
+// Its requests to the etcd server at addr are sent through tr.
+func NewHandler(addr string, tr http.RoundTripper) http.Handler {
+	h := &handler{
+		Router: mux.NewRouter(),
+		client: etcd.NewClient([]string{addr}),
+	}
+	h.client.SetTransport(tr)
+	h.StrictSlash(false)
+	h.HandleFunc("/lease", h.createHandler).Methods("POST")
+	h.HandleFunc("/lease/{id:[0-9]+}", h.getHandler).Methods("GET")
+	h.HandleFunc("/lease/{id:[0-9]+}", h.keepAliveHandler).Methods("PUT")
+	h.HandleFunc("/lease/{id:[0-9]+}", h.revokeHandler).Methods("DELETE")
+	h.HandleFunc("/lease/{id:[0-9]+}/keys/{key:.*}", h.attachHandler).Methods("PUT")
+
+	go h.reap()
+
+	return h
+}
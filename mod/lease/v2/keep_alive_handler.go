@@ -0,0 +1,37 @@
+package v2
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// keepAliveHandler refreshes the TTL of a lease and therefore of every key attached to it.
+// The "ttl" parameter optionally replaces the TTL the lease was created with.
+func (h *handler) keepAliveHandler(w http.ResponseWriter, req *http.Request) {
+	h.client.SyncCluster()
+
+	id := mux.Vars(req)["id"]
+	resp, err := h.client.Get(leasePath(id), false, false)
+	if err != nil {
+		http.Error(w, "keep alive lease error: "+err.Error(), http.StatusNotFound)
+		return
+	}
+
+	// Default to the TTL stored with the lease.
+	value := resp.Node.Value
+	if len(req.FormValue("ttl")) > 0 {
+		value = req.FormValue("ttl")
+	}
+	ttl, err := strconv.Atoi(value)
+	if err != nil || ttl <= 0 {
+		http.Error(w, "invalid ttl: "+value, http.StatusInternalServerError)
+		return
+	}
+
+	if _, err = h.client.Update(leasePath(id), value, uint64(ttl)); err != nil {
+		http.Error(w, "keep alive lease error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,15 @@
+package v2
+
+import (
+	"path"
+)
+
+// leasePath returns the key that holds the TTL for a given lease.
+func leasePath(id string) string {
+	return path.Join(prefix, "leases", id)
+}
+
+// attachmentsPath returns the directory that lists the keys attached to a given lease.
+func attachmentsPath(id string) string {
+	return path.Join(prefix, "keys", id)
+}
@@ -0,0 +1,84 @@
+package v2
+
+import (
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/coreos/etcd/log"
+)
+
+// The amount of time to wait before retrying a failed watch on the leases.
+const reapRetryInterval = time.Second
+
+// reap watches the leases and revokes the keys attached to each lease once
+// it expires. Every member runs a reaper; attachments are claimed by deleting
+// their record so that each key is only deleted once.
+func (h *handler) reap() {
+	var waitIndex uint64
+	for {
+		// Catch up on anything that expired while we were not watching.
+		if waitIndex == 0 {
+			waitIndex = h.sweep()
+			if waitIndex == 0 {
+				time.Sleep(reapRetryInterval)
+				continue
+			}
+		}
+
+		resp, err := h.client.Watch(path.Join(prefix, "leases"), waitIndex, true, nil, nil)
+		if err != nil {
+			waitIndex = 0
+			time.Sleep(reapRetryInterval)
+			continue
+		}
+		waitIndex = resp.Node.ModifiedIndex + 1
+
+		if resp.Action == "expire" || resp.Action == "delete" {
+			h.revoke(path.Base(resp.Node.Key))
+		}
+	}
+}
+
+// sweep revokes the attachments of every lease that no longer exists and
+// returns the index to start watching from. Returns zero on failure.
+func (h *handler) sweep() uint64 {
+	h.client.SyncCluster()
+
+	// Read the current index before sweeping so nothing is missed afterwards.
+	// Errors carry the index too so the prefix does not need to exist.
+	raw, err := h.client.RawGet(prefix, false, false)
+	if err != nil {
+		return 0
+	}
+	index, _ := strconv.ParseUint(raw.Header.Get("X-Etcd-Index"), 10, 64)
+
+	if resp, err := h.client.Get(path.Join(prefix, "keys"), false, false); err == nil {
+		for _, node := range resp.Node.Nodes {
+			id := path.Base(node.Key)
+			if _, err := h.client.Get(leasePath(id), false, false); err != nil {
+				h.revoke(id)
+			}
+		}
+	}
+	return index + 1
+}
+
+// revoke deletes all keys attached to a lease.
+func (h *handler) revoke(id string) {
+	resp, err := h.client.Get(attachmentsPath(id), true, false)
+	if err != nil {
+		return
+	}
+
+	for _, node := range resp.Node.Nodes {
+		// Only the member that removes the record deletes the key.
+		if _, err := h.client.Delete(node.Key, false); err != nil {
+			continue
+		}
+		if _, err := h.client.Delete(node.Value, false); err != nil {
+			log.Debugf("lease %s: cannot delete attached key %s: %v", id, node.Value, err)
+		}
+	}
+	h.client.Delete(attachmentsPath(id), true)
+}
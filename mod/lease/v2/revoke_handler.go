@@ -0,0 +1,19 @@
+package v2
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// revokeHandler deletes a lease along with every key attached to it.
+func (h *handler) revokeHandler(w http.ResponseWriter, req *http.Request) {
+	h.client.SyncCluster()
+
+	id := mux.Vars(req)["id"]
+	if _, err := h.client.Delete(leasePath(id), false); err != nil {
+		http.Error(w, "revoke lease error: "+err.Error(), http.StatusNotFound)
+		return
+	}
+	h.revoke(id)
+}
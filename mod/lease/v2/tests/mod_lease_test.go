@@ -0,0 +1,111 @@
+package lease
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/coreos/etcd/server"
+	"github.com/coreos/etcd/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+// Ensure that a lease can be created and that attached keys are readable.
+func TestModLeaseCreateAndAttach(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		// Create lease.
+		id, err := testCreateLease(s, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, id, "2")
+
+		// Attach two keys.
+		body, err := testAttachKey(s, id, "foo", "XXX")
+		assert.NoError(t, err)
+		assert.Equal(t, body, "")
+		body, err = testAttachKey(s, id, "bar/baz", "YYY")
+		assert.NoError(t, err)
+		assert.Equal(t, body, "")
+
+		// Check that the keys exist.
+		assert.Equal(t, testGetKeyValue(s, "foo"), "XXX")
+		assert.Equal(t, testGetKeyValue(s, "bar/baz"), "YYY")
+
+		// Check that the lease lists both keys.
+		resp, err := tests.Get(fmt.Sprintf("%s/mod/v2/lease/%s", s.URL(), id))
+		assert.NoError(t, err)
+		lease := tests.ReadBodyJSON(resp)
+		assert.Equal(t, lease["id"], id)
+		assert.Equal(t, len(lease["keys"].([]interface{})), 2)
+	})
+}
+
+// Ensure that the keys attached to a lease are deleted when it expires.
+func TestModLeaseExpire(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		id, err := testCreateLease(s, 2)
+		assert.NoError(t, err)
+		testAttachKey(s, id, "foo", "XXX")
+		testAttachKey(s, id, "bar", "YYY")
+
+		// Keep the lease alive past its original TTL.
+		time.Sleep(1 * time.Second)
+		body, err := testKeepAlive(s, id)
+		assert.NoError(t, err)
+		assert.Equal(t, body, "")
+		time.Sleep(1500 * time.Millisecond)
+		assert.Equal(t, testGetKeyValue(s, "foo"), "XXX")
+
+		// Let the lease expire.
+		time.Sleep(3 * time.Second)
+		assert.Equal(t, testGetKeyValue(s, "foo"), "")
+		assert.Equal(t, testGetKeyValue(s, "bar"), "")
+	})
+}
+
+// Ensure that revoking a lease deletes its keys immediately.
+func TestModLeaseRevoke(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		id, _ := testCreateLease(s, 60)
+		testAttachKey(s, id, "foo", "XXX")
+
+		resp, err := tests.DeleteForm(fmt.Sprintf("%s/mod/v2/lease/%s", s.URL(), id), nil)
+		assert.NoError(t, err)
+		assert.Equal(t, resp.StatusCode, 200)
+		tests.ReadBody(resp)
+		assert.Equal(t, testGetKeyValue(s, "foo"), "")
+
+		// Attaching to a revoked lease fails.
+		resp, _ = tests.PutForm(fmt.Sprintf("%s/mod/v2/lease/%s/keys/foo", s.URL(), id), url.Values{"value": {"XXX"}})
+		assert.Equal(t, resp.StatusCode, 404)
+		tests.ReadBody(resp)
+	})
+}
+
+func testCreateLease(s *server.Server, ttl int) (string, error) {
+	resp, err := tests.PostForm(fmt.Sprintf("%s/mod/v2/lease?ttl=%d", s.URL(), ttl), nil)
+	ret := tests.ReadBody(resp)
+	return string(ret), err
+}
+
+func testAttachKey(s *server.Server, id string, key string, value string) (string, error) {
+	resp, err := tests.PutForm(fmt.Sprintf("%s/mod/v2/lease/%s/keys/%s", s.URL(), id, key), url.Values{"value": {value}})
+	ret := tests.ReadBody(resp)
+	return string(ret), err
+}
+
+func testKeepAlive(s *server.Server, id string) (string, error) {
+	resp, err := tests.PutForm(fmt.Sprintf("%s/mod/v2/lease/%s", s.URL(), id), nil)
+	ret := tests.ReadBody(resp)
+	return string(ret), err
+}
+
+func testGetKeyValue(s *server.Server, key string) string {
+	resp, _ := tests.Get(fmt.Sprintf("%s/v2/keys/%s", s.URL(), key))
+	body := tests.ReadBodyJSON(resp)
+	if node, ok := body["node"].(map[string]interface{}); ok {
+		value, _ := node["value"].(string)
+		return value
+	}
+	return ""
+}
@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/coreos/etcd/lifetime"
 	"github.com/coreos/go-etcd/etcd"
 	"github.com/gorilla/mux"
 )
@@ -15,49 +16,58 @@ import (
 // acquireHandler attempts to acquire a lock on the given key.
 // The "key" parameter specifies the resource to lock.
 // The "value" parameter specifies a value to associate with the lock.
-// The "ttl" parameter specifies how long the lock will persist for.
-// The "timeout" parameter specifies how long the request should wait for the lock.
+// The "ttl" parameter specifies how long the lock will be held for once acquired. It defaults to the lock's configured TTL.
+// The "wait" parameter specifies how many seconds the request may wait in line for the lock. Zero only takes a free
+// lock and no value waits forever. "timeout" is the old name of "wait".
 func (h *handler) acquireHandler(w http.ResponseWriter, req *http.Request) {
-	h.client.SyncCluster()
-
-	// Setup connection watcher.
-	closeNotifier, _ := w.(http.CloseNotifier)
-	closeChan := closeNotifier.CloseNotify()
-	stopChan := make(chan bool)
+	h.backend.Sync()
 
 	// Parse the lock "key".
 	vars := mux.Vars(req)
 	keypath := path.Join(prefix, vars["key"])
 	value := req.FormValue("value")
 
-	// Parse "timeout" parameter.
-	var timeout int
-	var err error
-	if req.FormValue("timeout") == "" {
-		timeout = -1
-	} else if timeout, err = strconv.Atoi(req.FormValue("timeout")); err != nil {
-		http.Error(w, "invalid timeout: " + req.FormValue("timeout"), http.StatusInternalServerError)
+	// Parse "wait" parameter.
+	wait, err := parseWait(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	timeout = timeout + 1
 
-	// Parse TTL.
-	ttl, err := strconv.Atoi(req.FormValue("ttl"))
+	// Read the lock configuration.
+	conf, err := h.getConfig(keypath)
 	if err != nil {
+		http.Error(w, "read lock config error: " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Parse TTL. Fall back to the lock's default TTL if there is one.
+	var ttl int
+	if req.FormValue("ttl") == "" && conf.TTL > 0 {
+		ttl = conf.TTL
+	} else if ttl, err = strconv.Atoi(req.FormValue("ttl")); err != nil || ttl <= 0 {
 		http.Error(w, "invalid ttl: " + req.FormValue("ttl"), http.StatusInternalServerError)
 		return
 	}
 
+	// Stop waiting when the connection closes or the wait is over.
+	l := lifetime.Start(w, time.Duration(wait) * time.Second)
+	stopChan := make(chan bool)
+
 	// If node exists then just watch it. Otherwise create the node and watch it.
 	index := h.findExistingNode(keypath, value)
 	if index > 0 {
-		err = h.watch(keypath, index, nil)
-	} else {
-		index, err = h.createNode(keypath, value, ttl, closeChan, stopChan)
+		err = h.watch(keypath, index, l.Done())
+	} else if err = h.checkWaiters(keypath, conf); err == nil {
+		index, err = h.createNode(keypath, value, ttl, conf, l.Done(), stopChan)
+	}
+	if err != nil && l.Expired() {
+		err = fmt.Errorf("acquire lock error: not acquired within %ds", wait)
 	}
 
 	// Stop all goroutines.
 	close(stopChan)
+	l.Stop()
 
 	// Write response.
 	if err != nil {
@@ -67,15 +77,49 @@ func (h *handler) acquireHandler(w http.ResponseWriter, req *http.Request) {
 	}
 }
 
+// parseWait reads the number of seconds a request may wait for the lock, or
+// -1 to wait forever.
+func parseWait(req *http.Request) (int, error) {
+	name := "wait"
+	if req.FormValue(name) == "" {
+		name = "timeout"
+	}
+	if req.FormValue(name) == "" {
+		return -1, nil
+	}
+	wait, err := strconv.Atoi(req.FormValue(name))
+	if err != nil || wait < 0 {
+		return 0, fmt.Errorf("invalid %s: %s", name, req.FormValue(name))
+	}
+	return wait, nil
+}
+
+// checkWaiters returns an error if the lock already has the maximum number of waiters.
+func (h *handler) checkWaiters(keypath string, conf *lockConfig) error {
+	if conf.MaxWaiters <= 0 {
+		return nil
+	}
+	resp, err := h.backend.Get(keypath, true, true)
+	if err != nil {
+		return nil
+	}
+
+	// The first node holds the lock and the rest are waiting.
+	if len(resp.Node.Nodes) - 1 >= conf.MaxWaiters {
+		return fmt.Errorf("acquire lock error: too many waiters: %d", conf.MaxWaiters)
+	}
+	return nil
+}
+
 // createNode creates a new lock node and watches it until it is acquired or acquisition fails.
-func (h *handler) createNode(keypath string, value string, ttl int, closeChan <- chan bool, stopChan chan bool) (int, error) {
+func (h *handler) createNode(keypath string, value string, ttl int, conf *lockConfig, closeChan <- chan bool, stopChan chan bool) (int, error) {
 	// Default the value to "-" if it is blank.
 	if len(value) == 0 {
 		value = "-"
 	}
 
 	// Create an incrementing id for the lock.
-	resp, err := h.client.AddChild(keypath, value, uint64(ttl))
+	resp, err := h.backend.AddChild(keypath, value, uint64(ttl))
 	if err != nil {
 		return 0, errors.New("acquire lock index error: " + err.Error())
 	}
@@ -98,10 +142,18 @@ func (h *handler) createNode(keypath string, value string, ttl int, closeChan <-
 	}
 
 	// Update TTL one last time if acquired. Otherwise delete.
+	// A lock with a maximum hold time records when the holder must let go
+	// and never keeps the lock past it.
 	if err == nil {
-		h.client.Update(indexpath, value, uint64(ttl))
+		if conf.MaxHold > 0 {
+			h.backend.Set(path.Join(keypath, holdsNode, strconv.Itoa(index)), value, uint64(conf.MaxHold))
+			if ttl > conf.MaxHold {
+				ttl = conf.MaxHold
+			}
+		}
+		h.backend.Update(indexpath, value, uint64(ttl))
 	} else {
-		h.client.Delete(indexpath, false)
+		h.backend.Delete(indexpath, false)
 	}
 
 	return index, err
@@ -110,7 +162,7 @@ func (h *handler) createNode(keypath string, value string, ttl int, closeChan <-
 // findExistingNode search for a node on the lock with the given value.
 func (h *handler) findExistingNode(keypath string, value string) int {
 	if len(value) > 0 {
-		resp, err := h.client.Get(keypath, true, true)
+		resp, err := h.backend.Get(keypath, true, true)
 		if err == nil {
 			nodes := lockNodes{resp.Node.Nodes}
 			if node := nodes.FindByValue(value); node != nil {
@@ -127,7 +179,7 @@ func (h *handler) ttlKeepAlive(k string, value string, ttl int, stopChan chan bo
 	for {
 		select {
 		case <-time.After(time.Duration(ttl / 2) * time.Second):
-			h.client.Update(k, value, uint64(ttl))
+			h.backend.Update(k, value, uint64(ttl))
 		case <-stopChan:
 			return
 		}
@@ -139,18 +191,19 @@ func (h *handler) ttlKeepAlive(k string, value string, ttl int, stopChan chan bo
 func (h *handler) watch(keypath string, index int, closeChan <- chan bool) error {
 	// Wrap close chan so we can pass it to Client.Watch().
 	stopWatchChan := make(chan bool)
+	doneChan := make(chan bool)
 	go func() {
 		select {
 		case <- closeChan:
-			stopWatchChan <- true
-		case <- stopWatchChan:
+			close(stopWatchChan)
+		case <- doneChan:
 		}
 	}()
-	defer close(stopWatchChan)
+	defer close(doneChan)
 
 	for {
 		// Read all nodes for the lock.
-		resp, err := h.client.Get(keypath, true, true)
+		resp, err := h.backend.Get(keypath, true, true)
 		if err != nil {
 			return fmt.Errorf("lock watch lookup error: %s", err.Error())
 		}
@@ -164,7 +217,7 @@ func (h *handler) watch(keypath string, index int, closeChan <- chan bool) error
 		}
 
 		// Watch previous index until it's gone.
-		_, err = h.client.Watch(path.Join(keypath, strconv.Itoa(prevIndex)), waitIndex, false, nil, stopWatchChan)
+		_, err = h.backend.Watch(path.Join(keypath, strconv.Itoa(prevIndex)), waitIndex, false, stopWatchChan)
 		if err == etcd.ErrWatchStoppedByUser {
 			return fmt.Errorf("lock watch closed")
 		} else if err != nil {
@@ -0,0 +1,319 @@
+package v2
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	etcdErr "github.com/coreos/etcd/error"
+	"github.com/coreos/etcd/store"
+	"github.com/coreos/go-etcd/etcd"
+	"github.com/coreos/raft"
+)
+
+// Backend is the part of the key space the lock module works on. Missing
+// keys and failed writes are reported as etcd.EtcdError, whatever the
+// backend, so that the handlers do not depend on where the keys live.
+type Backend interface {
+	Get(key string, sort, recursive bool) (*etcd.Response, error)
+	// GetWithIndex also returns the etcd index the node was read at, which
+	// is returned along with the error when the node does not exist.
+	GetWithIndex(key string, sort, recursive bool) (*etcd.Response, uint64, error)
+	Set(key string, value string, ttl uint64) (*etcd.Response, error)
+	Update(key string, value string, ttl uint64) (*etcd.Response, error)
+	AddChild(key string, value string, ttl uint64) (*etcd.Response, error)
+	Delete(key string, recursive bool) (*etcd.Response, error)
+	// Watch waits for the first change after waitIndex. It returns
+	// etcd.ErrWatchStoppedByUser once stop is closed.
+	Watch(key string, waitIndex uint64, recursive bool, stop chan bool) (*etcd.Response, error)
+
+	// Sync refreshes what the backend knows about the cluster.
+	Sync()
+	// IsLeader returns whether the member serving the module is the leader.
+	IsLeader() bool
+}
+
+// clientBackend reaches the keys through an etcd client.
+type clientBackend struct {
+	*etcd.Client
+	transport *http.Transport
+	addr      string
+}
+
+// NewClientBackend creates a backend sending every request to the etcd
+// server at addr.
+func NewClientBackend(addr string) Backend {
+	return &clientBackend{
+		Client:    etcd.NewClient([]string{addr}),
+		transport: &http.Transport{},
+		addr:      addr,
+	}
+}
+
+func (b *clientBackend) GetWithIndex(key string, sort, recursive bool) (*etcd.Response, uint64, error) {
+	raw, err := b.RawGet(key, sort, recursive)
+	if err != nil {
+		return nil, 0, err
+	}
+	index, _ := strconv.ParseUint(raw.Header.Get("X-Etcd-Index"), 10, 64)
+
+	if raw.StatusCode != http.StatusOK {
+		e := etcd.EtcdError{}
+		json.Unmarshal(raw.Body, &e)
+		return nil, index, e
+	}
+	resp := &etcd.Response{}
+	if err := json.Unmarshal(raw.Body, resp); err != nil {
+		return nil, 0, err
+	}
+	return resp, index, nil
+}
+
+func (b *clientBackend) Watch(key string, waitIndex uint64, recursive bool, stop chan bool) (*etcd.Response, error) {
+	return b.Client.Watch(key, waitIndex, recursive, nil, stop)
+}
+
+func (b *clientBackend) Sync() {
+	b.SyncCluster()
+}
+
+// IsLeader asks for the leader stats, which are only served by the leader;
+// every other member redirects to it.
+func (b *clientBackend) IsLeader() bool {
+	req, err := http.NewRequest("GET", b.addr+"/v2/stats/leader", nil)
+	if err != nil {
+		return false
+	}
+	resp, err := b.transport.RoundTrip(req)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return resp.StatusCode == http.StatusOK
+}
+
+// Server is the part of the etcd server a store backend needs.
+type Server interface {
+	State() string
+	Store() store.Store
+	RevealEvent(*http.Request, *store.Event) *store.Event
+	// ServeHTTP serves a client API request without a network round trip.
+	ServeHTTP(http.ResponseWriter, *http.Request)
+}
+
+// storeBackend works on the member serving the module while it is the
+// leader, saving the round trip through the network. Reads and watches go
+// to its store, and writes are handed to its client API in-process so that
+// they pass the same key, write rule, quota, TTL and encryption checks as
+// any other write. The other members go through a client to the leader.
+type storeBackend struct {
+	server Server
+	client *clientBackend
+}
+
+// NewStoreBackend creates a backend using the store of s, or a client to
+// addr, the client URL of s, when s is not the leader.
+func NewStoreBackend(s Server, addr string) Backend {
+	return &storeBackend{
+		server: s,
+		client: NewClientBackend(addr).(*clientBackend),
+	}
+}
+
+func (b *storeBackend) IsLeader() bool {
+	return b.server.State() == raft.Leader
+}
+
+func (b *storeBackend) Sync() {
+	if !b.IsLeader() {
+		b.client.Sync()
+	}
+}
+
+func (b *storeBackend) Get(key string, sort, recursive bool) (*etcd.Response, error) {
+	if !b.IsLeader() {
+		return b.client.Get(key, sort, recursive)
+	}
+	e, err := b.server.Store().Get(key, recursive, sort)
+	if err != nil {
+		return nil, clientError(err)
+	}
+	return b.toResponse(e)
+}
+
+func (b *storeBackend) GetWithIndex(key string, sort, recursive bool) (*etcd.Response, uint64, error) {
+	if !b.IsLeader() {
+		return b.client.GetWithIndex(key, sort, recursive)
+	}
+	e, index, err := b.server.Store().GetWithIndex(key, recursive, sort)
+	if err != nil {
+		return nil, index, clientError(err)
+	}
+	resp, err := b.toResponse(e)
+	return resp, index, err
+}
+
+func (b *storeBackend) Set(key string, value string, ttl uint64) (*etcd.Response, error) {
+	if !b.IsLeader() {
+		return b.client.Set(key, value, ttl)
+	}
+	return b.write("PUT", key, writeValues(value, ttl), func() (*etcd.Response, error) {
+		return b.client.Set(key, value, ttl)
+	})
+}
+
+func (b *storeBackend) Update(key string, value string, ttl uint64) (*etcd.Response, error) {
+	if !b.IsLeader() {
+		return b.client.Update(key, value, ttl)
+	}
+	v := writeValues(value, ttl)
+	v.Set("prevExist", "true")
+	return b.write("PUT", key, v, func() (*etcd.Response, error) {
+		return b.client.Update(key, value, ttl)
+	})
+}
+
+func (b *storeBackend) AddChild(key string, value string, ttl uint64) (*etcd.Response, error) {
+	if !b.IsLeader() {
+		return b.client.AddChild(key, value, ttl)
+	}
+	return b.write("POST", key, writeValues(value, ttl), func() (*etcd.Response, error) {
+		return b.client.AddChild(key, value, ttl)
+	})
+}
+
+func (b *storeBackend) Delete(key string, recursive bool) (*etcd.Response, error) {
+	if !b.IsLeader() {
+		return b.client.Delete(key, recursive)
+	}
+	v := url.Values{}
+	if recursive {
+		v.Set("recursive", "true")
+	}
+	return b.write("DELETE", key, v, func() (*etcd.Response, error) {
+		return b.client.Delete(key, recursive)
+	})
+}
+
+func (b *storeBackend) Watch(key string, waitIndex uint64, recursive bool, stop chan bool) (*etcd.Response, error) {
+	if !b.IsLeader() {
+		return b.client.Watch(key, waitIndex, recursive, stop)
+	}
+	// A stream watcher can be removed when the wait is given up. Only its
+	// first event is read; it is removed or evicted after that.
+	w, err := b.server.Store().WatchStream(key, recursive, waitIndex, 1)
+	if err != nil {
+		return nil, clientError(err)
+	}
+	defer w.Remove()
+
+	select {
+	case e, ok := <-w.EventChan():
+		if !ok {
+			return nil, etcd.EtcdError{ErrorCode: etcdErr.EcodeEventIndexCleared, Message: "The event in requested index is outdated and cleared"}
+		}
+		return b.toResponse(e)
+	case <-stop:
+		return nil, etcd.ErrWatchStoppedByUser
+	}
+}
+
+// write sends a write to the client API of the server in-process and
+// returns the change it made. A write that is redirected because the member
+// just lost the leadership is retried through the client.
+func (b *storeBackend) write(method string, key string, v url.Values, retry func() (*etcd.Response, error)) (*etcd.Response, error) {
+	req, err := http.NewRequest(method, "/v2/keys"+key, strings.NewReader(v.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.RemoteAddr = loopbackAddr
+
+	w := &responseRecorder{header: make(http.Header), code: http.StatusOK}
+	b.server.ServeHTTP(w, req)
+	switch w.code {
+	case http.StatusOK, http.StatusCreated:
+		resp := &etcd.Response{}
+		if err := json.Unmarshal(w.body.Bytes(), resp); err != nil {
+			return nil, err
+		}
+		return resp, nil
+	case http.StatusTemporaryRedirect:
+		return retry()
+	}
+
+	e := etcd.EtcdError{}
+	if err := json.Unmarshal(w.body.Bytes(), &e); err != nil || e.ErrorCode == 0 {
+		return nil, errors.New(strings.TrimSpace(w.body.String()))
+	}
+	return nil, e
+}
+
+// The client address of the requests the store backend serves in-process,
+// the one they had when they were sent through a client to the member.
+const loopbackAddr = "127.0.0.1:0"
+
+// writeValues builds the form of a write the way a client does.
+func writeValues(value string, ttl uint64) url.Values {
+	v := url.Values{}
+	if value != "" {
+		v.Set("value", value)
+	}
+	if ttl > 0 {
+		v.Set("ttl", strconv.FormatUint(ttl, 10))
+	}
+	return v
+}
+
+// responseRecorder collects the answer of the client API to a request
+// served in-process.
+type responseRecorder struct {
+	header http.Header
+	code   int
+	body   bytes.Buffer
+}
+
+func (w *responseRecorder) Header() http.Header {
+	return w.header
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+func (w *responseRecorder) WriteHeader(code int) {
+	w.code = code
+}
+
+// toResponse converts a store event to the response a client would get,
+// with the values under encrypted prefixes revealed the same way.
+func (b *storeBackend) toResponse(e *store.Event) (*etcd.Response, error) {
+	req, _ := http.NewRequest("GET", "/v2/keys"+e.Node.Key, nil)
+	req.RemoteAddr = loopbackAddr
+	return toResponse(b.server.RevealEvent(req, e))
+}
+
+// toResponse converts a store event to the response a client would get.
+func toResponse(e *store.Event) (*etcd.Response, error) {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	resp := &etcd.Response{}
+	if err := json.Unmarshal(b, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// clientError converts a store error to the error a client would get.
+func clientError(err error) error {
+	if e, ok := err.(*etcdErr.Error); ok {
+		return etcd.EtcdError{ErrorCode: e.ErrorCode, Message: e.Message, Cause: e.Cause}
+	}
+	return err
+}
@@ -0,0 +1,89 @@
+package v2
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	etcdErr "github.com/coreos/etcd/error"
+	"github.com/coreos/etcd/store"
+	"github.com/coreos/go-etcd/etcd"
+	"github.com/coreos/raft"
+	"github.com/stretchr/testify/assert"
+)
+
+// Ensure that store events and errors reach the handlers the way a client returns them.
+func TestStoreBackendConversion(t *testing.T) {
+	expiration := time.Date(2013, 12, 2, 9, 0, 0, 0, time.UTC)
+	e := &store.Event{
+		Action: store.Get,
+		Node: &store.NodeExtern{
+			Key:           prefix + "/foo",
+			Dir:           true,
+			ModifiedIndex: 3,
+			Nodes: store.NodeExterns{
+				{Key: prefix + "/foo/2", Value: "bar", TTL: 10, Expiration: &expiration, ModifiedIndex: 2, CreatedIndex: 2},
+			},
+		},
+	}
+	resp, err := toResponse(e)
+	assert.NoError(t, err)
+	assert.Equal(t, resp.Action, "get")
+	assert.Equal(t, resp.Node.ModifiedIndex, uint64(3))
+	if assert.Equal(t, len(resp.Node.Nodes), 1) {
+		n := resp.Node.Nodes[0]
+		assert.Equal(t, n.Key, prefix+"/foo/2")
+		assert.Equal(t, n.Value, "bar")
+		assert.Equal(t, n.TTL, int64(10))
+		assert.True(t, n.Expiration.Equal(expiration))
+	}
+
+	err = clientError(etcdErr.NewError(etcdErr.EcodeKeyNotFound, prefix+"/foo", 3))
+	if e, ok := err.(etcd.EtcdError); assert.True(t, ok) {
+		assert.Equal(t, e.ErrorCode, 100)
+		assert.Equal(t, e.Cause, prefix+"/foo")
+	}
+}
+
+// testServer is a leader whose client API rejects every write.
+type testServer struct {
+	store store.Store
+}
+
+func (s *testServer) State() string      { return raft.Leader }
+func (s *testServer) Store() store.Store { return s.store }
+
+func (s *testServer) RevealEvent(req *http.Request, e *store.Event) *store.Event {
+	return e
+}
+
+func (s *testServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	etcdErr.NewError(etcdErr.EcodeUnauthorized, req.URL.Path, 0).Write(w)
+}
+
+// Ensure that writes on the leader go through its client API and watches
+// given up are removed from the store.
+func TestStoreBackendLeader(t *testing.T) {
+	s := &testServer{store: store.New()}
+	b := NewStoreBackend(s, "http://127.0.0.1:4001")
+
+	_, err := b.Set(prefix+"/foo", "bar", 0)
+	if e, ok := err.(etcd.EtcdError); assert.True(t, ok) {
+		assert.Equal(t, e.ErrorCode, etcdErr.EcodeUnauthorized)
+	}
+
+	stop := make(chan bool)
+	c := make(chan error)
+	go func() {
+		_, err := b.Watch(prefix+"/foo", 0, false, stop)
+		c <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	close(stop)
+	assert.Equal(t, <-c, etcd.ErrWatchStoppedByUser)
+
+	var stats store.Stats
+	assert.NoError(t, json.Unmarshal(s.store.JsonStats(), &stats))
+	assert.Equal(t, stats.Watchers, uint64(0))
+}
@@ -0,0 +1,59 @@
+package v2
+
+import (
+	"encoding/json"
+	"net/http"
+	"path"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// getConfigHandler retrieves the configuration of a lock as JSON.
+func (h *handler) getConfigHandler(w http.ResponseWriter, req *http.Request) {
+	h.backend.Sync()
+
+	vars := mux.Vars(req)
+	keypath := path.Join(prefix, vars["key"])
+
+	conf, err := h.getConfig(keypath)
+	if err != nil {
+		http.Error(w, "read lock config error: " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	b, _ := json.Marshal(conf)
+	w.Write(b)
+}
+
+// setConfigHandler updates the configuration of a lock.
+// The "ttl" parameter specifies the default TTL for requests without one.
+// The "maxHold" parameter specifies the seconds a holder can keep the lock.
+// The "maxWaiters" parameter specifies how many requests can wait for the lock.
+// Parameters that are not given are left unchanged and "0" disables a setting.
+func (h *handler) setConfigHandler(w http.ResponseWriter, req *http.Request) {
+	h.backend.Sync()
+
+	vars := mux.Vars(req)
+	keypath := path.Join(prefix, vars["key"])
+
+	// Validate all parameters before changing anything.
+	for _, name := range configFields {
+		if v := req.FormValue(name); v != "" {
+			if n, err := strconv.Atoi(v); err != nil || n < 0 {
+				http.Error(w, "invalid " + name + ": " + v, http.StatusInternalServerError)
+				return
+			}
+		}
+	}
+
+	for _, name := range configFields {
+		if v := req.FormValue(name); v != "" {
+			if _, err := h.backend.Set(path.Join(keypath, configNode, name), v, 0); err != nil {
+				http.Error(w, "set lock config error: " + err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+	}
+}
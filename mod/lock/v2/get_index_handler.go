@@ -1,16 +1,23 @@
 package v2
 
 import (
+	"encoding/json"
 	"net/http"
 	"path"
 
+	"github.com/coreos/etcd/lifetime"
+	"github.com/coreos/go-etcd/etcd"
 	"github.com/gorilla/mux"
 )
 
 // getIndexHandler retrieves the current lock index.
 // The "field" parameter specifies to read either the lock "index" or lock "value".
+// A "queue" field lists the holder followed by the waiters as JSON, with the
+// time each one last sent a heartbeat.
+// The "wait" parameter blocks until the lock holder changes. If "prevIndex" or
+// "prevValue" is given then it blocks until the holder no longer matches it.
 func (h *handler) getIndexHandler(w http.ResponseWriter, req *http.Request) {
-	h.client.SyncCluster()
+	h.backend.Sync()
 
 	vars := mux.Vars(req)
 	keypath := path.Join(prefix, vars["key"])
@@ -18,26 +25,97 @@ func (h *handler) getIndexHandler(w http.ResponseWriter, req *http.Request) {
 	if len(field) == 0 {
 		field = "value"
 	}
+	if field != "index" && field != "value" && field != "queue" {
+		http.Error(w, "read lock error: invalid field: " + field, http.StatusInternalServerError)
+		return
+	}
 
 	// Read all indices.
-	resp, err := h.client.Get(keypath, true, true)
+	nodes, index, err := h.getLockNodes(keypath)
 	if err != nil {
 		http.Error(w, "read lock error: " + err.Error(), http.StatusInternalServerError)
 		return
 	}
-	nodes := lockNodes{resp.Node.Nodes}
+
+	if req.FormValue("wait") == "true" {
+		// Default to waiting on the current holder, even if there is none.
+		prevIndex, prevValue := req.FormValue("prevIndex"), req.FormValue("prevValue")
+		unchanged := func() bool {
+			if len(prevValue) > 0 {
+				return holderValue(nodes.First()) == prevValue
+			}
+			return holderIndex(nodes.First()) == prevIndex
+		}
+		if len(prevIndex) == 0 && len(prevValue) == 0 {
+			prevIndex = holderIndex(nodes.First())
+		}
+
+		// Setup connection watcher.
+		l := lifetime.Start(w, -1)
+		defer l.Stop()
+		stopChan := make(chan bool)
+		go func() {
+			<-l.Done()
+			close(stopChan)
+		}()
+
+		// Wait until the holder no longer matches.
+		for unchanged() {
+			if _, err = h.backend.Watch(keypath, index+1, true, stopChan); err == etcd.ErrWatchStoppedByUser {
+				return
+			} else if err != nil {
+				http.Error(w, "read lock watch error: " + err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if nodes, index, err = h.getLockNodes(keypath); err != nil {
+				http.Error(w, "read lock error: " + err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+	}
 
 	// Write out the requested field.
+	if field == "queue" {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(nodes.Queue(h.getHeartbeats(keypath)))
+		return
+	}
 	if node := nodes.First(); node != nil {
 		switch field {
 		case "index":
-			w.Write([]byte(path.Base(node.Key)))
+			w.Write([]byte(holderIndex(node)))
 
 		case "value":
 			w.Write([]byte(node.Value))
+		}
+	}
+}
 
-		default:
-			http.Error(w, "read lock error: invalid field: " + field, http.StatusInternalServerError)
+// getLockNodes reads all nodes for a lock along with the current etcd index.
+// A lock that does not exist yet has no nodes.
+func (h *handler) getLockNodes(keypath string) (lockNodes, uint64, error) {
+	resp, index, err := h.backend.GetWithIndex(keypath, true, true)
+	if err != nil {
+		if e, ok := err.(etcd.EtcdError); ok && e.ErrorCode == 100 {
+			return lockNodes{}, index, nil
 		}
+		return lockNodes{}, 0, err
+	}
+	return lockNodes{resp.Node.Nodes}, index, nil
+}
+
+// holderIndex returns the index of the given lock node or blank if there is no node.
+func holderIndex(node *etcd.Node) string {
+	if node == nil {
+		return ""
+	}
+	return path.Base(node.Key)
+}
+
+// holderValue returns the value of the given lock node or blank if there is no node.
+func holderValue(node *etcd.Node) string {
+	if node == nil {
+		return ""
 	}
+	return node.Value
 }
@@ -4,7 +4,6 @@ import (
 	"net/http"
 
 	"github.com/gorilla/mux"
-	"github.com/coreos/go-etcd/etcd"
 )
 
 const prefix = "/_etcd/mod/lock"
@@ -12,19 +11,27 @@ const prefix = "/_etcd/mod/lock"
 // handler manages the lock HTTP request.
 type handler struct {
 	*mux.Router
-	client *etcd.Client
+	backend Backend
+	health  *lockHealth
 }
 
 // NewHandler creates an HTTP handler that can be registered on a router.
-func NewHandler(addr string) (http.Handler) {
+// The locks are kept in the given backend.
+func NewHandler(b Backend) (http.Handler) {
 	h := &handler{
-		Router: mux.NewRouter(),
-		client: etcd.NewClient([]string{addr}),
+		Router:  mux.NewRouter(),
+		backend: b,
+		health:  &lockHealth{seen: make(map[string]*lockObservation)},
 	}
 	h.StrictSlash(false)
+	h.HandleFunc("/_health", h.healthHandler).Methods("GET")
+	h.HandleFunc("/{key:.*}/_config", h.getConfigHandler).Methods("GET")
+	h.HandleFunc("/{key:.*}/_config", h.setConfigHandler).Methods("PUT")
+	h.HandleFunc("/{key:.*}/heartbeat", h.heartbeatHandler).Methods("POST")
 	h.HandleFunc("/{key:.*}", h.getIndexHandler).Methods("GET")
 	h.HandleFunc("/{key:.*}", h.acquireHandler).Methods("POST")
 	h.HandleFunc("/{key:.*}", h.renewLockHandler).Methods("PUT")
 	h.HandleFunc("/{key:.*}", h.releaseLockHandler).Methods("DELETE")
+	go h.sweep()
 	return h
 }
@@ -0,0 +1,152 @@
+package v2
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-etcd/etcd"
+)
+
+// The default time a lock must stay in the same state before it is reported.
+const defaultStuckAfter = 5 * time.Minute
+
+// lockHealth remembers how each lock looked on previous health checks so
+// that locks which stopped moving can be detected.
+type lockHealth struct {
+	sync.Mutex
+	seen map[string]*lockObservation
+}
+
+// lockObservation is the state of a lock's holder and when it last changed.
+type lockObservation struct {
+	holder        string
+	modifiedIndex uint64
+
+	// When the holder got the lock and when it was last renewed.
+	since     time.Time
+	refreshed time.Time
+}
+
+// lockAnomaly describes a lock that looks stuck.
+type lockAnomaly struct {
+	Key     string `json:"key"`
+	Index   string `json:"index"`
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// healthHandler reports locks whose holders look dead:
+//
+//   expiredHolder  the holder's TTL has passed but it still holds the lock.
+//   notRefreshed   the holder has not renewed its TTL for "stuckAfter".
+//   stuckQueue     requests are waiting but the holder has not changed for "stuckAfter".
+//
+// The last two compare against earlier checks, so the endpoint should be
+// polled regularly by monitoring.
+func (h *handler) healthHandler(w http.ResponseWriter, req *http.Request) {
+	stuckAfter := defaultStuckAfter
+	if v := req.FormValue("stuckAfter"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			http.Error(w, "invalid stuckAfter: " + v, http.StatusInternalServerError)
+			return
+		}
+		stuckAfter = d
+	}
+
+	resp, err := h.backend.Get(prefix, false, true)
+	if err != nil {
+		if e, ok := err.(etcd.EtcdError); !ok || e.ErrorCode != 100 {
+			http.Error(w, "get locks error: " + err.Error(), http.StatusInternalServerError)
+			return
+		}
+		resp = &etcd.Response{Node: &etcd.Node{}}
+	}
+
+	locks := make(map[string]etcd.Nodes)
+	collectLocks(resp.Node.Nodes, locks)
+	anomalies := h.health.check(locks, stuckAfter, time.Now())
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"locks":     len(locks),
+		"anomalies": anomalies,
+	})
+}
+
+// collectLocks walks the lock tree and gathers the queue of every lock,
+// keyed by lock name. A lock is a directory holding numbered nodes.
+func collectLocks(nodes etcd.Nodes, locks map[string]etcd.Nodes) {
+	for _, node := range nodes {
+		if !node.Dir {
+			continue
+		}
+		var queue etcd.Nodes
+		for _, child := range node.Nodes {
+			if child.Dir {
+				collectLocks(etcd.Nodes{child}, locks)
+			} else if _, err := strconv.Atoi(path.Base(child.Key)); err == nil {
+				queue = append(queue, child)
+			}
+		}
+		if len(queue) > 0 {
+			locks[strings.TrimPrefix(node.Key, prefix)] = queue
+		}
+	}
+}
+
+// check records the current holders and returns the anomalies found.
+func (lh *lockHealth) check(locks map[string]etcd.Nodes, stuckAfter time.Duration, now time.Time) []*lockAnomaly {
+	lh.Lock()
+	defer lh.Unlock()
+
+	anomalies := make([]*lockAnomaly, 0)
+	seen := make(map[string]*lockObservation)
+	keys := make([]string, 0, len(locks))
+	for key := range locks {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		queue := locks[key]
+		head := lockNodes{queue}.First()
+		index := path.Base(head.Key)
+
+		// Keep the previous observation while the holder is unchanged.
+		o := lh.seen[key]
+		if o == nil || o.holder != index {
+			o = &lockObservation{holder: index, modifiedIndex: head.ModifiedIndex, since: now, refreshed: now}
+		} else if o.modifiedIndex != head.ModifiedIndex {
+			o.modifiedIndex = head.ModifiedIndex
+			o.refreshed = now
+		}
+		seen[key] = o
+
+		if head.Expiration != nil && now.After(*head.Expiration) {
+			anomalies = append(anomalies, &lockAnomaly{key, index, "expiredHolder",
+				fmt.Sprintf("holder expired at %v but still holds the lock", head.Expiration.Format(time.RFC3339))})
+		}
+
+		if d := now.Sub(o.refreshed); d >= stuckAfter {
+			anomalies = append(anomalies, &lockAnomaly{key, index, "notRefreshed",
+				fmt.Sprintf("holder has not been refreshed for %v", d)})
+		}
+
+		if d := now.Sub(o.since); len(queue) > 1 && d >= stuckAfter {
+			anomalies = append(anomalies, &lockAnomaly{key, index, "stuckQueue",
+				fmt.Sprintf("%d requests waiting behind a holder of %v", len(queue)-1, d)})
+		}
+	}
+
+	// Forget locks that went away.
+	lh.seen = seen
+	return anomalies
+}
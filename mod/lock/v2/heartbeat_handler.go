@@ -0,0 +1,75 @@
+package v2
+
+import (
+	"net/http"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// heartbeatHandler renews the lock held at the given index and records when
+// the holder was last seen, so that a holder that is alive but slow can be
+// told apart from a dead one whose lock has not expired yet.
+// Returns a 200 OK if successful. Returns non-200 on error.
+func (h *handler) heartbeatHandler(w http.ResponseWriter, req *http.Request) {
+	h.backend.Sync()
+
+	vars := mux.Vars(req)
+	keypath := path.Join(prefix, vars["key"])
+
+	index := req.FormValue("index")
+	if len(index) == 0 {
+		http.Error(w, "heartbeat error: index required", http.StatusInternalServerError)
+		return
+	}
+
+	conf, err := h.getConfig(keypath)
+	if err != nil {
+		http.Error(w, "read lock config error: " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Parse the TTL parameter. Fall back to the lock's default TTL if there is one.
+	var ttl int
+	if req.FormValue("ttl") == "" && conf.TTL > 0 {
+		ttl = conf.TTL
+	} else if ttl, err = strconv.Atoi(req.FormValue("ttl")); err != nil {
+		http.Error(w, "invalid ttl: " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Keep the value of the lock node.
+	resp, err := h.backend.Get(path.Join(keypath, index), false, false)
+	if err != nil {
+		http.Error(w, "heartbeat error: " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.renewLock(keypath, conf, index, resp.Node.Value, ttl); err != nil {
+		http.Error(w, "heartbeat error: " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// The record goes away with the lock node if the holder stops.
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	h.backend.Set(path.Join(keypath, heartbeatsNode, index), now, uint64(ttl))
+}
+
+// getHeartbeats reads when each holder of a lock last sent a heartbeat, by
+// lock index.
+func (h *handler) getHeartbeats(keypath string) map[int]time.Time {
+	seen := make(map[int]time.Time)
+	resp, err := h.backend.Get(path.Join(keypath, heartbeatsNode), false, false)
+	if err != nil {
+		return seen
+	}
+	for _, node := range resp.Node.Nodes {
+		idx, _ := strconv.Atoi(path.Base(node.Key))
+		if t, err := time.Parse(time.RFC3339Nano, node.Value); err == nil {
+			seen[idx] = t
+		}
+	}
+	return seen
+}
@@ -0,0 +1,61 @@
+package v2
+
+import (
+	"path"
+	"strconv"
+
+	"github.com/coreos/go-etcd/etcd"
+)
+
+const (
+	// The hidden child of a lock that holds its configuration.
+	configNode = "_config"
+
+	// The hidden child of a lock that records when each holder must let go.
+	holdsNode = "_holds"
+
+	// The hidden child of a lock that records when each holder last sent a
+	// heartbeat.
+	heartbeatsNode = "_heartbeats"
+)
+
+// lockConfig holds the optional settings of a single lock.
+type lockConfig struct {
+	// The TTL used when a request does not specify one.
+	TTL int `json:"ttl"`
+
+	// The number of seconds a holder can keep the lock before it is expired.
+	MaxHold int `json:"maxHold"`
+
+	// The number of requests that can wait for the lock at once.
+	MaxWaiters int `json:"maxWaiters"`
+}
+
+// configFields lists the configuration nodes that can be set on a lock.
+var configFields = []string{"ttl", "maxHold", "maxWaiters"}
+
+// getConfig reads the configuration of a lock. A lock without configuration
+// returns the zero configuration.
+func (h *handler) getConfig(keypath string) (*lockConfig, error) {
+	c := &lockConfig{}
+	resp, err := h.backend.Get(path.Join(keypath, configNode), false, true)
+	if err != nil {
+		if e, ok := err.(etcd.EtcdError); ok && e.ErrorCode == 100 {
+			return c, nil
+		}
+		return nil, err
+	}
+
+	for _, node := range resp.Node.Nodes {
+		v, _ := strconv.Atoi(node.Value)
+		switch path.Base(node.Key) {
+		case "ttl":
+			c.TTL = v
+		case "maxHold":
+			c.MaxHold = v
+		case "maxWaiters":
+			c.MaxWaiters = v
+		}
+	}
+	return c, nil
+}
@@ -4,6 +4,7 @@ import (
 	"path"
 	"sort"
 	"strconv"
+	"time"
 
 	"github.com/coreos/go-etcd/etcd"
 )
@@ -55,3 +56,28 @@ func (s lockNodes) PrevIndex(index int) int {
 	}
 	return 0
 }
+
+// lockQueueEntry describes a lock node in a queue listing.
+type lockQueueEntry struct {
+	Index    int        `json:"index"`
+	Value    string     `json:"value"`
+	TTL      int64      `json:"ttl"`
+	LastSeen *time.Time `json:"lastSeen,omitempty"`
+}
+
+// Retrieves the holder followed by the waiters in the order they acquire the lock.
+// The heartbeats give the last time each node was seen, by index.
+func (s lockNodes) Queue(heartbeats map[int]time.Time) []lockQueueEntry {
+	sort.Sort(s)
+
+	queue := make([]lockQueueEntry, 0, len(s.Nodes))
+	for _, node := range s.Nodes {
+		idx, _ := strconv.Atoi(path.Base(node.Key))
+		entry := lockQueueEntry{Index: idx, Value: node.Value, TTL: node.TTL}
+		if t, ok := heartbeats[idx]; ok {
+			entry.LastSeen = &t
+		}
+		queue = append(queue, entry)
+	}
+	return queue
+}
@@ -0,0 +1,181 @@
+package v2
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coreos/etcd/lifetime"
+	"github.com/gorilla/mux"
+)
+
+// multiLock is one of the locks held by a multilock request.
+type multiLock struct {
+	Key   string `json:"key"`
+	Index int    `json:"index"`
+}
+
+// NewMultiLockHandler creates an HTTP handler acquiring several locks at once
+// that can be registered on a router. The locks are kept in the given backend.
+func NewMultiLockHandler(b Backend) http.Handler {
+	h := &handler{
+		Router:  mux.NewRouter(),
+		backend: b,
+	}
+	h.StrictSlash(false)
+	h.HandleFunc("/multilock", h.multiLockHandler).Methods("PUT")
+	h.HandleFunc("/multilock", h.multiReleaseHandler).Methods("DELETE")
+	return h
+}
+
+// ParseLockKeys splits a comma-separated list of lock keys and returns them
+// cleaned, without duplicates and in the order they are acquired.
+func ParseLockKeys(s string) []string {
+	seen := make(map[string]bool)
+	keys := make([]string, 0)
+	for _, k := range strings.Split(s, ",") {
+		k = strings.TrimSpace(k)
+		if k == "" {
+			continue
+		}
+		k = path.Clean("/" + k)[1:]
+		if k != "" && !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// multiLockHandler acquires all the locks given in the "keys" parameter or
+// none of them. The locks are taken in sorted order, whatever the order they
+// are given in, so that requests for overlapping sets of locks cannot
+// deadlock each other. When one of the locks cannot be acquired the ones
+// already taken are released.
+// The "value", "ttl" and "wait" parameters are the ones of a single lock;
+// "wait" covers the whole set.
+func (h *handler) multiLockHandler(w http.ResponseWriter, req *http.Request) {
+	h.backend.Sync()
+
+	keys := ParseLockKeys(req.FormValue("keys"))
+	if len(keys) == 0 {
+		http.Error(w, "acquire multilock error: keys required", http.StatusInternalServerError)
+		return
+	}
+	value := req.FormValue("value")
+
+	wait, err := parseWait(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Check the TTL against every lock before taking any of them.
+	confs := make([]*lockConfig, len(keys))
+	ttls := make([]int, len(keys))
+	for i, key := range keys {
+		if confs[i], err = h.getConfig(path.Join(prefix, key)); err != nil {
+			http.Error(w, "read lock config error: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if req.FormValue("ttl") == "" && confs[i].TTL > 0 {
+			ttls[i] = confs[i].TTL
+		} else if ttls[i], err = strconv.Atoi(req.FormValue("ttl")); err != nil || ttls[i] <= 0 {
+			http.Error(w, "invalid ttl: "+req.FormValue("ttl"), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	// Stop waiting when the connection closes or the wait is over. The locks
+	// taken first are kept alive until they are all acquired.
+	l := lifetime.Start(w, time.Duration(wait)*time.Second)
+	stopChan := make(chan bool)
+
+	locks := make([]multiLock, 0, len(keys))
+	for i, key := range keys {
+		keypath := path.Join(prefix, key)
+		if err = h.checkWaiters(keypath, confs[i]); err != nil {
+			break
+		}
+		var index int
+		if index, err = h.createNode(keypath, value, ttls[i], confs[i], l.Done(), stopChan); err != nil {
+			break
+		}
+		locks = append(locks, multiLock{Key: key, Index: index})
+	}
+	if err != nil {
+		if l.Expired() {
+			err = fmt.Errorf("acquire multilock error: not acquired within %ds", wait)
+		}
+		for _, l := range locks {
+			h.release(path.Join(prefix, l.Key), strconv.Itoa(l.Index))
+		}
+	}
+
+	// Stop all goroutines.
+	close(stopChan)
+	l.Stop()
+
+	// Write response.
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(locks)
+}
+
+// multiReleaseHandler releases the locks given in the "keys" parameter. They
+// are found by "value", or by "index", a comma-separated list of the indexes
+// in the order the locks were returned.
+func (h *handler) multiReleaseHandler(w http.ResponseWriter, req *http.Request) {
+	h.backend.Sync()
+
+	keys := ParseLockKeys(req.FormValue("keys"))
+	value := req.FormValue("value")
+	var indexes []string
+	if s := req.FormValue("index"); s != "" {
+		indexes = strings.Split(s, ",")
+	}
+	if len(keys) == 0 {
+		http.Error(w, "release multilock error: keys required", http.StatusInternalServerError)
+		return
+	} else if len(indexes) == 0 && len(value) == 0 {
+		http.Error(w, "release multilock error: index or value required", http.StatusInternalServerError)
+		return
+	} else if len(indexes) != 0 && len(value) != 0 {
+		http.Error(w, "release multilock error: index and value cannot both be specified", http.StatusInternalServerError)
+		return
+	} else if len(indexes) != 0 && len(indexes) != len(keys) {
+		http.Error(w, "release multilock error: one index is required per key", http.StatusInternalServerError)
+		return
+	}
+
+	// Release every lock, even when some of them are already gone.
+	var errs []string
+	for i, key := range keys {
+		keypath := path.Join(prefix, key)
+		var index string
+		if len(indexes) != 0 {
+			index = strings.TrimSpace(indexes[i])
+		} else if resp, err := h.backend.Get(keypath, true, true); err == nil {
+			if node := (lockNodes{resp.Node.Nodes}).FindByValue(value); node != nil {
+				index = path.Base(node.Key)
+			}
+		}
+		if index == "" {
+			errs = append(errs, key+": cannot find: "+value)
+		} else if err := h.release(keypath, index); err != nil {
+			errs = append(errs, key+": "+err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		http.Error(w, "release multilock error: "+strings.Join(errs, ", "), http.StatusInternalServerError)
+	}
+}
@@ -9,7 +9,7 @@ import (
 
 // releaseLockHandler deletes the lock.
 func (h *handler) releaseLockHandler(w http.ResponseWriter, req *http.Request) {
-	h.client.SyncCluster()
+	h.backend.Sync()
 
 	vars := mux.Vars(req)
 	keypath := path.Join(prefix, vars["key"])
@@ -27,7 +27,7 @@ func (h *handler) releaseLockHandler(w http.ResponseWriter, req *http.Request) {
 
 	// Look up index by value if index is missing.
 	if len(index) == 0 {
-		resp, err := h.client.Get(keypath, true, true)
+		resp, err := h.backend.Get(keypath, true, true)
 		if err != nil {
 			http.Error(w, "release lock index error: " + err.Error(), http.StatusInternalServerError)
 			return
@@ -42,10 +42,21 @@ func (h *handler) releaseLockHandler(w http.ResponseWriter, req *http.Request) {
 	}
 
 	// Delete the lock.
-	_, err := h.client.Delete(path.Join(keypath, index), false)
-	if err != nil {
+	if err := h.release(keypath, index); err != nil {
 		http.Error(w, "release lock error: " + err.Error(), http.StatusInternalServerError)
 		return
 	}
 }
 
+// release deletes a lock index and its hold and heartbeat records.
+func (h *handler) release(keypath string, index string) error {
+	if _, err := h.backend.Delete(path.Join(keypath, index), false); err != nil {
+		return err
+	}
+
+	// Clean up the hold and heartbeat records if there are any.
+	h.backend.Delete(path.Join(keypath, holdsNode, index), false)
+	h.backend.Delete(path.Join(keypath, heartbeatsNode, index), false)
+	return nil
+}
+
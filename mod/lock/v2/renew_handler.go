@@ -1,6 +1,7 @@
 package v2
 
 import (
+	"errors"
 	"path"
 	"net/http"
 	"strconv"
@@ -8,18 +9,29 @@ import (
 	"github.com/gorilla/mux"
 )
 
+var errMaxHold = errors.New("maximum hold time exceeded")
+
 // renewLockHandler attempts to update the TTL on an existing lock.
 // Returns a 200 OK if successful. Returns non-200 on error.
 func (h *handler) renewLockHandler(w http.ResponseWriter, req *http.Request) {
-	h.client.SyncCluster()
+	h.backend.Sync()
 
 	// Read the lock path.
 	vars := mux.Vars(req)
 	keypath := path.Join(prefix, vars["key"])
 
-	// Parse new TTL parameter.
-	ttl, err := strconv.Atoi(req.FormValue("ttl"))
+	// Read the lock configuration.
+	conf, err := h.getConfig(keypath)
 	if err != nil {
+		http.Error(w, "read lock config error: " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Parse new TTL parameter. Fall back to the lock's default TTL if there is one.
+	var ttl int
+	if req.FormValue("ttl") == "" && conf.TTL > 0 {
+		ttl = conf.TTL
+	} else if ttl, err = strconv.Atoi(req.FormValue("ttl")); err != nil {
 		http.Error(w, "invalid ttl: " + err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -35,7 +47,7 @@ func (h *handler) renewLockHandler(w http.ResponseWriter, req *http.Request) {
 
 	if len(index) == 0 {
 		// If index is not specified then look it up by value.
-		resp, err := h.client.Get(keypath, true, true)
+		resp, err := h.backend.Get(keypath, true, true)
 		if err != nil {
 			http.Error(w, "renew lock index error: " + err.Error(), http.StatusInternalServerError)
 			return
@@ -50,7 +62,7 @@ func (h *handler) renewLockHandler(w http.ResponseWriter, req *http.Request) {
 
 	} else if len(value) == 0 {
 		// If value is not specified then default it to the previous value.
-		resp, err := h.client.Get(path.Join(keypath, index), true, false)
+		resp, err := h.backend.Get(path.Join(keypath, index), true, false)
 		if err != nil {
 			http.Error(w, "renew lock value error: " + err.Error(), http.StatusInternalServerError)
 			return
@@ -58,10 +70,26 @@ func (h *handler) renewLockHandler(w http.ResponseWriter, req *http.Request) {
 		value = resp.Node.Value
 	}
 
-	// Renew the lock, if it exists.
-	_, err = h.client.Update(path.Join(keypath, index), value, uint64(ttl))
-	if err != nil {
+	if err := h.renewLock(keypath, conf, index, value, ttl); err != nil {
 		http.Error(w, "renew lock error: " + err.Error(), http.StatusInternalServerError)
 		return
 	}
 }
+
+// renewLock updates the TTL of a lock node, never past the maximum hold time.
+func (h *handler) renewLock(keypath string, conf *lockConfig, index string, value string, ttl int) error {
+	if conf.MaxHold > 0 {
+		resp, err := h.backend.Get(path.Join(keypath, holdsNode, index), false, false)
+		if err != nil || resp.Node.TTL <= 0 {
+			h.backend.Delete(path.Join(keypath, index), false)
+			return errMaxHold
+		}
+		if int64(ttl) > resp.Node.TTL {
+			ttl = int(resp.Node.TTL)
+		}
+	}
+
+	// Renew the lock, if it exists.
+	_, err := h.backend.Update(path.Join(keypath, index), value, uint64(ttl))
+	return err
+}
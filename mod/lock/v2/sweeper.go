@@ -0,0 +1,76 @@
+package v2
+
+import (
+	"time"
+
+	"github.com/coreos/etcd/log"
+	"github.com/coreos/go-etcd/etcd"
+)
+
+const (
+	// How often the leader looks for orphaned lock nodes.
+	sweepInterval = 30 * time.Second
+
+	// How long past its TTL a lock node must be before it is swept. The
+	// store normally removes it long before this.
+	sweepGrace = 5 * time.Second
+)
+
+// lockSweeper removes lock nodes that outlived their TTL. Such nodes are left
+// behind when the store misses their expiration, for example across a
+// restart, and nobody deletes them because the request that created them is
+// gone. They block every request queued behind them.
+type lockSweeper struct {
+	// The modified index of each expired node found on the previous sweep.
+	expired map[string]uint64
+}
+
+// sweep runs while this member is the leader so that only one member deletes
+// orphaned nodes at a time.
+func (h *handler) sweep() {
+	s := &lockSweeper{expired: make(map[string]uint64)}
+	for {
+		time.Sleep(sweepInterval)
+		if !h.backend.IsLeader() {
+			s.expired = make(map[string]uint64)
+			continue
+		}
+
+		resp, err := h.backend.Get(prefix, false, true)
+		if err != nil {
+			continue
+		}
+		locks := make(map[string]etcd.Nodes)
+		collectLocks(resp.Node.Nodes, locks)
+
+		for _, n := range s.orphans(locks, time.Now()) {
+			if _, err := h.backend.Delete(n.Key, false); err != nil {
+				continue
+			}
+			log.Infof("[lock] removed orphaned node %s (expired at %v)", n.Key, n.Expiration.Format(time.RFC3339))
+		}
+	}
+}
+
+// orphans returns the nodes that were already expired on the previous sweep
+// and have not been modified since. Waiting two sweeps keeps a node that is
+// being renewed right now from being deleted.
+func (s *lockSweeper) orphans(locks map[string]etcd.Nodes, now time.Time) etcd.Nodes {
+	var orphans etcd.Nodes
+	expired := make(map[string]uint64)
+	for _, queue := range locks {
+		for _, n := range queue {
+			if n.Expiration == nil || now.Sub(*n.Expiration) < sweepGrace {
+				continue
+			}
+			if index, ok := s.expired[n.Key]; ok && index == n.ModifiedIndex {
+				orphans = append(orphans, n)
+			}
+			expired[n.Key] = n.ModifiedIndex
+		}
+	}
+
+	// Forget nodes that went away or were renewed.
+	s.expired = expired
+	return orphans
+}
@@ -0,0 +1,47 @@
+package v2
+
+import (
+	"testing"
+	"time"
+
+	"github.com/coreos/go-etcd/etcd"
+	"github.com/stretchr/testify/assert"
+)
+
+// Ensure that only nodes left expired and unmodified across two sweeps are orphans.
+func TestLockSweeperOrphans(t *testing.T) {
+	now := time.Date(2013, 12, 2, 9, 0, 0, 0, time.UTC)
+	expired := now.Add(-time.Minute)
+	recent := now.Add(-time.Second)
+	live := now.Add(time.Minute)
+	locks := map[string]etcd.Nodes{
+		"/foo": {
+			{Key: prefix + "/foo/2", ModifiedIndex: 2, Expiration: &expired},
+			{Key: prefix + "/foo/3", ModifiedIndex: 3, Expiration: &live},
+			{Key: prefix + "/foo/4", ModifiedIndex: 4, Expiration: &recent},
+		},
+		"/bar": {
+			{Key: prefix + "/bar/5", ModifiedIndex: 5, Expiration: &expired},
+		},
+	}
+
+	// The first sweep only remembers the expired nodes.
+	s := &lockSweeper{expired: make(map[string]uint64)}
+	assert.Equal(t, len(s.orphans(locks, now)), 0)
+
+	// The second one returns those that did not change in between.
+	locks["/bar"][0].ModifiedIndex = 6
+	orphans := s.orphans(locks, now.Add(sweepInterval))
+	if assert.Equal(t, len(orphans), 1) {
+		assert.Equal(t, orphans[0].Key, prefix+"/foo/2")
+	}
+
+	// A renewed node is forgotten.
+	locks["/foo"][0].ModifiedIndex = 7
+	locks["/foo"][0].Expiration = &live
+	orphans = s.orphans(locks, now.Add(2*sweepInterval))
+	if assert.Equal(t, len(orphans), 2) {
+		assert.NotEqual(t, orphans[0].Key, prefix+"/foo/2")
+		assert.NotEqual(t, orphans[1].Key, prefix+"/foo/2")
+	}
+}
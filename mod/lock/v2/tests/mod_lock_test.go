@@ -1,6 +1,7 @@
 package lock
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
 	"time"
@@ -193,6 +194,237 @@ func TestModLockAcquireAndReleaseByValue(t *testing.T) {
 
 
 
+// Ensure that a read can wait until the lock holder changes.
+func TestModLockWaitForHolderChange(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		testAcquireLock(s, "foo", "XXX", 10)
+
+		c := make(chan string)
+		go func() {
+			resp, _ := tests.Get(fmt.Sprintf("%s/mod/v2/lock/foo?wait=true&prevValue=XXX", s.URL()))
+			c <- string(tests.ReadBody(resp))
+		}()
+		time.Sleep(500 * time.Millisecond)
+
+		// Queue up a second lock and release the first.
+		go testAcquireLock(s, "foo", "YYY", 10)
+		time.Sleep(500 * time.Millisecond)
+		testReleaseLock(s, "foo", "", "XXX")
+
+		select {
+		case body := <-c:
+			assert.Equal(t, body, "YYY")
+		case <-time.After(5 * time.Second):
+			t.Fatal("wait did not return")
+		}
+	})
+}
+
+// Ensure that a lock uses its configured TTL when none is given.
+func TestModLockConfigDefaultTTL(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		resp, err := tests.PutForm(fmt.Sprintf("%s/mod/v2/lock/foo/_config?ttl=2", s.URL()), nil)
+		assert.NoError(t, err)
+		assert.Equal(t, resp.StatusCode, 200)
+		tests.ReadBody(resp)
+
+		// Check the configuration.
+		resp, _ = tests.Get(fmt.Sprintf("%s/mod/v2/lock/foo/_config", s.URL()))
+		conf := tests.ReadBodyJSON(resp)
+		assert.Equal(t, conf["ttl"], float64(2))
+		assert.Equal(t, conf["maxHold"], float64(0))
+
+		// Acquire without a TTL.
+		resp, err = tests.PostForm(fmt.Sprintf("%s/mod/v2/lock/foo", s.URL()), nil)
+		assert.NoError(t, err)
+		assert.Equal(t, string(tests.ReadBody(resp)), "3")
+
+		// Check that the lock expires with the default TTL.
+		time.Sleep(3 * time.Second)
+		body, _ := testGetLockIndex(s, "foo")
+		assert.Equal(t, body, "")
+	})
+}
+
+// Ensure that a lock rejects requests beyond its maximum number of waiters.
+func TestModLockConfigMaxWaiters(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		resp, _ := tests.PutForm(fmt.Sprintf("%s/mod/v2/lock/foo/_config?maxWaiters=1", s.URL()), nil)
+		tests.ReadBody(resp)
+
+		testAcquireLock(s, "foo", "XXX", 10)
+		go testAcquireLock(s, "foo", "YYY", 10)
+		time.Sleep(500 * time.Millisecond)
+
+		resp, err := tests.PostForm(fmt.Sprintf("%s/mod/v2/lock/foo?value=ZZZ&ttl=10", s.URL()), nil)
+		assert.NoError(t, err)
+		assert.Equal(t, resp.StatusCode, 500)
+		assert.Equal(t, string(tests.ReadBody(resp)), "acquire lock error: too many waiters: 1\n")
+	})
+}
+
+// Ensure that a lock holder is expired after the maximum hold time even if it renews.
+func TestModLockConfigMaxHold(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		resp, _ := tests.PutForm(fmt.Sprintf("%s/mod/v2/lock/foo/_config?maxHold=2", s.URL()), nil)
+		tests.ReadBody(resp)
+
+		body, err := testAcquireLock(s, "foo", "XXX", 10)
+		assert.NoError(t, err)
+		assert.Equal(t, body, "3")
+
+		// Renewing cannot extend the hold.
+		time.Sleep(1 * time.Second)
+		testRenewLock(s, "foo", "", "XXX", 10)
+		time.Sleep(2 * time.Second)
+		body, _ = testGetLockValue(s, "foo")
+		assert.Equal(t, body, "")
+
+		resp, _ = tests.PutForm(fmt.Sprintf("%s/mod/v2/lock/foo?value=XXX&ttl=10", s.URL()), nil)
+		assert.Equal(t, resp.StatusCode, 500)
+		tests.ReadBody(resp)
+	})
+}
+
+// Ensure that a request only waits for the lock as long as asked to.
+func TestModLockWait(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		body, _ := testAcquireLock(s, "foo", "XXX", 10)
+		assert.Equal(t, body, "2")
+
+		// A lock that is held is not taken without waiting.
+		resp, _ := tests.PostForm(fmt.Sprintf("%s/mod/v2/lock/foo?value=YYY&ttl=10&wait=0", s.URL()), nil)
+		assert.Equal(t, resp.StatusCode, 500)
+		tests.ReadBody(resp)
+
+		// Waiting gives up after the deadline and leaves the queue.
+		start := time.Now()
+		resp, _ = tests.PostForm(fmt.Sprintf("%s/mod/v2/lock/foo?value=YYY&ttl=10&wait=1", s.URL()), nil)
+		assert.Equal(t, resp.StatusCode, 500)
+		assert.Contains(t, string(tests.ReadBody(resp)), "not acquired within 1s")
+		assert.True(t, time.Since(start) >= time.Second)
+		resp, _ = tests.Get(fmt.Sprintf("%s/mod/v2/lock/foo?field=queue", s.URL()))
+		var queue []map[string]interface{}
+		json.Unmarshal(tests.ReadBody(resp), &queue)
+		assert.Equal(t, len(queue), 1)
+
+		// A free lock is taken right away.
+		resp, _ = tests.PostForm(fmt.Sprintf("%s/mod/v2/lock/bar?value=YYY&ttl=10&wait=0", s.URL()), nil)
+		assert.Equal(t, resp.StatusCode, 200)
+		tests.ReadBody(resp)
+
+		// Invalid waits are rejected.
+		resp, _ = tests.PostForm(fmt.Sprintf("%s/mod/v2/lock/baz?ttl=10&wait=-1", s.URL()), nil)
+		assert.Equal(t, resp.StatusCode, 500)
+		assert.Equal(t, string(tests.ReadBody(resp)), "invalid wait: -1\n")
+	})
+}
+
+// Ensure that the holder and waiters of a lock can be listed in order.
+func TestModLockQueue(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		testAcquireLock(s, "foo", "XXX", 10)
+		go testAcquireLock(s, "foo", "YYY", 10)
+		time.Sleep(500 * time.Millisecond)
+
+		resp, err := tests.Get(fmt.Sprintf("%s/mod/v2/lock/foo?field=queue", s.URL()))
+		assert.NoError(t, err)
+		var queue []map[string]interface{}
+		assert.NoError(t, json.Unmarshal(tests.ReadBody(resp), &queue))
+		assert.Equal(t, len(queue), 2)
+		assert.Equal(t, queue[0]["value"], "XXX")
+		assert.Equal(t, queue[0]["index"], 2)
+		assert.Equal(t, queue[1]["value"], "YYY")
+		assert.True(t, queue[1]["ttl"].(float64) > 0)
+
+		// A lock nobody holds has an empty queue.
+		resp, _ = tests.Get(fmt.Sprintf("%s/mod/v2/lock/bar?field=queue", s.URL()))
+		assert.Equal(t, string(tests.ReadBody(resp)), "[]\n")
+	})
+}
+
+// Ensure that a heartbeat renews the lock and shows when the holder was last seen.
+func TestModLockHeartbeat(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		index, _ := testAcquireLock(s, "foo", "XXX", 2)
+		go testAcquireLock(s, "foo", "YYY", 10)
+		time.Sleep(500 * time.Millisecond)
+
+		before := time.Now()
+		body, err := testHeartbeatLock(s, "foo", index, 5)
+		assert.NoError(t, err)
+		assert.Equal(t, body, "")
+
+		resp, _ := tests.Get(fmt.Sprintf("%s/mod/v2/lock/foo?field=queue", s.URL()))
+		var queue []map[string]interface{}
+		assert.NoError(t, json.Unmarshal(tests.ReadBody(resp), &queue))
+		assert.Equal(t, len(queue), 2)
+		assert.True(t, queue[0]["ttl"].(float64) > 2)
+		seen, err := time.Parse(time.RFC3339Nano, queue[0]["lastSeen"].(string))
+		assert.NoError(t, err)
+		assert.False(t, seen.Before(before.Add(-time.Second)))
+		assert.Nil(t, queue[1]["lastSeen"])
+
+		// The value of the lock is kept.
+		value, _ := testGetLockValue(s, "foo")
+		assert.Equal(t, value, "XXX")
+
+		// Unknown holders cannot send heartbeats.
+		body, _ = testHeartbeatLock(s, "foo", "1000", 5)
+		assert.Contains(t, body, "heartbeat error")
+	})
+}
+
+// Ensure that a set of locks is acquired in sorted order and released together.
+func TestModMultiLockAcquireAndRelease(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		resp, _ := tests.PutForm(fmt.Sprintf("%s/mod/v2/multilock?keys=foo,bar&value=XXX&ttl=10", s.URL()), nil)
+		assert.Equal(t, resp.StatusCode, 200)
+		var locks []map[string]interface{}
+		json.Unmarshal(tests.ReadBody(resp), &locks)
+		if assert.Equal(t, len(locks), 2) {
+			assert.Equal(t, locks[0]["key"], "bar")
+			assert.Equal(t, locks[1]["key"], "foo")
+		}
+
+		body, _ := testGetLockValue(s, "foo")
+		assert.Equal(t, body, "XXX")
+		body, _ = testGetLockValue(s, "bar")
+		assert.Equal(t, body, "XXX")
+
+		resp, _ = tests.DeleteForm(fmt.Sprintf("%s/mod/v2/multilock?keys=foo,bar&value=XXX", s.URL()), nil)
+		assert.Equal(t, resp.StatusCode, 200)
+		tests.ReadBody(resp)
+
+		body, _ = testGetLockIndex(s, "foo")
+		assert.Equal(t, body, "")
+		body, _ = testGetLockIndex(s, "bar")
+		assert.Equal(t, body, "")
+	})
+}
+
+// Ensure that the locks taken by a multilock that fails are released.
+func TestModMultiLockReleasesPartialAcquisition(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		body, _ := testAcquireLock(s, "foo", "XXX", 10)
+		assert.Equal(t, body, "2")
+
+		// "bar" is taken first, then "foo" is never free.
+		resp, _ := tests.PutForm(fmt.Sprintf("%s/mod/v2/multilock?keys=foo,bar&value=YYY&ttl=10&wait=1", s.URL()), nil)
+		assert.Equal(t, resp.StatusCode, 500)
+		assert.Contains(t, string(tests.ReadBody(resp)), "not acquired within 1s")
+
+		body, _ = testGetLockIndex(s, "bar")
+		assert.Equal(t, body, "")
+		body, _ = testGetLockValue(s, "foo")
+		assert.Equal(t, body, "XXX")
+
+		resp, _ = tests.PutForm(fmt.Sprintf("%s/mod/v2/multilock?keys=,&ttl=10", s.URL()), nil)
+		assert.Equal(t, resp.StatusCode, 500)
+		assert.Equal(t, string(tests.ReadBody(resp)), "acquire multilock error: keys required\n")
+	})
+}
+
 func testAcquireLock(s *server.Server, key string, value string, ttl int) (string, error) {
 	resp, err := tests.PostForm(fmt.Sprintf("%s/mod/v2/lock/%s?value=%s&ttl=%d", s.URL(), key, value, ttl), nil)
 	ret := tests.ReadBody(resp)
@@ -222,3 +454,55 @@ func testRenewLock(s *server.Server, key string, index string, value string, ttl
 	ret := tests.ReadBody(resp)
 	return string(ret), err
 }
+
+func testHeartbeatLock(s *server.Server, key string, index string, ttl int) (string, error) {
+	resp, err := tests.PostForm(fmt.Sprintf("%s/mod/v2/lock/%s/heartbeat?index=%s&ttl=%d", s.URL(), key, index, ttl), nil)
+	ret := tests.ReadBody(resp)
+	return string(ret), err
+}
+
+// Ensure that a holder that stops renewing while others wait is reported.
+func TestModLockHealth(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		// No locks, no anomalies.
+		health := testGetLockHealth(s, "1s")
+		assert.Equal(t, health["locks"], 0)
+		assert.Equal(t, len(health["anomalies"].([]interface{})), 0)
+
+		body, err := testAcquireLock(s, "foo", "first", 60)
+		assert.NoError(t, err)
+		assert.Equal(t, body, "2")
+		go testAcquireLock(s, "foo", "second", 60)
+		time.Sleep(100 * time.Millisecond)
+
+		health = testGetLockHealth(s, "1s")
+		assert.Equal(t, health["locks"], 1)
+		assert.Equal(t, len(health["anomalies"].([]interface{})), 0)
+
+		// The holder never renews, and the waiter stays queued.
+		time.Sleep(1200 * time.Millisecond)
+		health = testGetLockHealth(s, "1s")
+		anomalies := health["anomalies"].([]interface{})
+		assert.Equal(t, len(anomalies), 2)
+		if len(anomalies) == 2 {
+			assert.Equal(t, anomalies[0].(map[string]interface{})["key"], "/foo")
+			assert.Equal(t, anomalies[0].(map[string]interface{})["index"], "2")
+			assert.Equal(t, anomalies[0].(map[string]interface{})["type"], "notRefreshed")
+			assert.Equal(t, anomalies[1].(map[string]interface{})["type"], "stuckQueue")
+		}
+
+		// Renewing clears the refresh anomaly but not the queue one.
+		testRenewLock(s, "foo", "2", "", 60)
+		health = testGetLockHealth(s, "1s")
+		anomalies = health["anomalies"].([]interface{})
+		assert.Equal(t, len(anomalies), 1)
+		if len(anomalies) == 1 {
+			assert.Equal(t, anomalies[0].(map[string]interface{})["type"], "stuckQueue")
+		}
+	})
+}
+
+func testGetLockHealth(s *server.Server, stuckAfter string) map[string]interface{} {
+	resp, _ := tests.Get(fmt.Sprintf("%s/mod/v2/lock/_health?stuckAfter=%s", s.URL(), stuckAfter))
+	return tests.ReadBodyJSON(resp)
+}
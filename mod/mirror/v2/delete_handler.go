@@ -0,0 +1,20 @@
+package v2
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// deleteHandler removes a mirror and its checkpoint. Keys already copied to
+// the destination are left in place.
+func (h *handler) deleteHandler(w http.ResponseWriter, req *http.Request) {
+	h.client.SyncCluster()
+
+	name := mux.Vars(req)["name"]
+	if _, err := h.client.Delete(mirrorPath(name), false); err != nil {
+		http.Error(w, "delete mirror error: "+err.Error(), http.StatusNotFound)
+		return
+	}
+	h.client.Delete(checkpointPath(name), false)
+}
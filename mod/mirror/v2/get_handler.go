@@ -0,0 +1,80 @@
+package v2
+
+import (
+	"encoding/json"
+	"net/http"
+	"path"
+
+	"github.com/coreos/go-etcd/etcd"
+	"github.com/gorilla/mux"
+)
+
+// getHandler retrieves a mirror and its progress.
+func (h *handler) getHandler(w http.ResponseWriter, req *http.Request) {
+	h.client.SyncCluster()
+
+	name := mux.Vars(req)["name"]
+	resp, err := h.client.Get(mirrorPath(name), false, false)
+	if err != nil {
+		http.Error(w, "get mirror error: "+err.Error(), http.StatusNotFound)
+		return
+	}
+	m, err := parseMirror(name, resp.Node)
+	if err != nil {
+		http.Error(w, "get mirror error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	m.checkpoint = h.checkpoint(m)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(m)
+}
+
+// listHandler retrieves every mirror and its progress, sorted by name.
+func (h *handler) listHandler(w http.ResponseWriter, req *http.Request) {
+	h.client.SyncCluster()
+
+	mirrors, err := h.mirrors()
+	if err != nil {
+		http.Error(w, "get mirrors error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	for _, m := range mirrors {
+		m.checkpoint = h.checkpoint(m)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(mirrors)
+}
+
+// mirrors returns all valid mirrors sorted by name.
+func (h *handler) mirrors() ([]*mirror, error) {
+	mirrors := make([]*mirror, 0)
+	resp, err := h.client.Get(path.Join(prefix, "mirrors"), true, false)
+	if err != nil {
+		if e, ok := err.(etcd.EtcdError); ok && e.ErrorCode == 100 {
+			return mirrors, nil
+		}
+		return nil, err
+	}
+
+	for _, node := range resp.Node.Nodes {
+		if m, err := parseMirror(path.Base(node.Key), &node); err == nil {
+			mirrors = append(mirrors, m)
+		}
+	}
+	return mirrors, nil
+}
+
+// checkpoint returns the recorded progress of a mirror. A mirror that has
+// not been checkpointed since it was set starts at index zero.
+func (h *handler) checkpoint(m *mirror) *checkpoint {
+	c := &checkpoint{}
+	if resp, err := h.client.Get(checkpointPath(m.Name), false, false); err == nil {
+		json.Unmarshal([]byte(resp.Node.Value), c)
+	}
+	if c.Mirror != m.index {
+		c = &checkpoint{Mirror: m.index}
+	}
+	return c
+}
@@ -0,0 +1,45 @@
+package v2
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/coreos/go-etcd/etcd"
+	"github.com/gorilla/mux"
+)
+
+const prefix = "/_etcd/mod/mirror"
+
+// handler manages the mirror HTTP request.
+type handler struct {
+	*mux.Router
+	client    *etcd.Client
+	transport *http.Transport
+	addr      string
+
+	// The mirrors replicated by this member while it is the leader.
+	mutex   sync.Mutex
+	running map[string]*replicator
+}
+
+// NewHandler creates an HTTP handler that can be registered on a router.
+// Its requests to the etcd server at addr are sent through tr.
+func NewHandler(addr string, tr http.RoundTripper) http.Handler {
+	h := &handler{
+		Router:    mux.NewRouter(),
+		client:    etcd.NewClient([]string{addr}),
+		transport: &http.Transport{},
+		addr:      addr,
+		running:   make(map[string]*replicator),
+	}
+	h.client.SetTransport(tr)
+	h.StrictSlash(false)
+	h.HandleFunc("/mirror", h.listHandler).Methods("GET")
+	h.HandleFunc("/mirror/{name:[a-zA-Z0-9_.-]+}", h.getHandler).Methods("GET")
+	h.HandleFunc("/mirror/{name:[a-zA-Z0-9_.-]+}", h.setHandler).Methods("PUT")
+	h.HandleFunc("/mirror/{name:[a-zA-Z0-9_.-]+}", h.deleteHandler).Methods("DELETE")
+
+	go h.run()
+
+	return h
+}
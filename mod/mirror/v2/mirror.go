@@ -0,0 +1,93 @@
+package v2
+
+import (
+	"encoding/json"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/coreos/go-etcd/etcd"
+)
+
+// mirror copies the keys under a prefix to a remote cluster.
+type mirror struct {
+	Name        string `json:"name"`
+	Prefix      string `json:"prefix"`
+	URL         string `json:"url"`
+	Destination string `json:"destination"`
+	CertFile    string `json:"certFile,omitempty"`
+	KeyFile     string `json:"keyFile,omitempty"`
+
+	// The index at which the definition was last set.
+	index uint64
+
+	// The progress of the replication, read from the checkpoint.
+	*checkpoint
+}
+
+// checkpoint records how far a mirror got. The leader writes it
+// periodically so that a new leader resumes where the old one stopped.
+// It belongs to the definition set at the mirror index; setting the mirror
+// again starts over.
+type checkpoint struct {
+	Mirror  uint64     `json:"mirrorIndex"`
+	Index   uint64     `json:"index"`
+	Updated *time.Time `json:"updated,omitempty"`
+	Skipped uint64     `json:"skipped,omitempty"`
+	Error   string     `json:"error,omitempty"`
+}
+
+// mirrorPath returns the key that holds the definition of a given mirror.
+func mirrorPath(name string) string {
+	return path.Join(prefix, "mirrors", name)
+}
+
+// checkpointPath returns the key that holds the progress of a given mirror.
+func checkpointPath(name string) string {
+	return path.Join(prefix, "checkpoints", name)
+}
+
+// parseMirror decodes a stored mirror definition.
+func parseMirror(name string, node *etcd.Node) (*mirror, error) {
+	m := &mirror{}
+	if err := json.Unmarshal([]byte(node.Value), m); err != nil {
+		return nil, err
+	}
+	m.Name = name
+	m.index = node.ModifiedIndex
+	return m, nil
+}
+
+// encode returns the stored form of the definition.
+func (m *mirror) encode() string {
+	b, _ := json.Marshal(&mirror{
+		Prefix:      m.Prefix,
+		URL:         m.URL,
+		Destination: m.Destination,
+		CertFile:    m.CertFile,
+		KeyFile:     m.KeyFile,
+	})
+	return string(b)
+}
+
+// remoteKey returns the key in the remote cluster that mirrors a local key.
+func (m *mirror) remoteKey(key string) string {
+	return path.Join(m.Destination, strings.TrimPrefix(key, m.Prefix))
+}
+
+// includes reports whether changes to a key are mirrored. Internal keys are
+// never copied, even when mirroring the root.
+func (m *mirror) includes(key string) bool {
+	return key != "/_etcd" && !strings.HasPrefix(key, "/_etcd/")
+}
+
+// remote creates a client for the destination cluster.
+func (m *mirror) remote() (*etcd.Client, error) {
+	c := etcd.NewClient([]string{m.URL})
+	if m.CertFile != "" || m.KeyFile != "" {
+		if err := c.SetCertAndKey(m.CertFile, m.KeyFile); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
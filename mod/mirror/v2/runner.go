@@ -0,0 +1,377 @@
+package v2
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/coreos/etcd/log"
+	"github.com/coreos/go-etcd/etcd"
+)
+
+const (
+	// How often the leader looks for mirrors that were added, changed or
+	// removed.
+	checkInterval = time.Second
+
+	// How often the progress of a mirror is recorded.
+	checkpointInterval = time.Second
+
+	// The amount of time to wait before retrying after the remote cluster
+	// could not be reached. It doubles up to maxRetryInterval.
+	retryInterval    = 100 * time.Millisecond
+	maxRetryInterval = 30 * time.Second
+)
+
+// replicator copies the changes of a single mirror while this member is
+// the leader.
+type replicator struct {
+	*mirror
+	stop chan bool
+
+	// Guards the checkpoint, which is updated as changes are copied and
+	// recorded periodically.
+	mutex sync.Mutex
+	dirty bool
+}
+
+// run starts a replicator for every mirror while this member is the leader
+// and stops them when it is not. Only one member replicates a mirror at a
+// time; a new leader resumes from the last recorded checkpoint.
+func (h *handler) run() {
+	for {
+		time.Sleep(checkInterval)
+		if !h.isLeader() {
+			h.reconcile(nil)
+			continue
+		}
+
+		mirrors, err := h.mirrors()
+		if err != nil {
+			continue
+		}
+		h.reconcile(mirrors)
+	}
+}
+
+// reconcile stops the replicators of mirrors that were removed or changed
+// and starts the missing ones.
+func (h *handler) reconcile(mirrors []*mirror) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	wanted := make(map[string]*mirror)
+	for _, m := range mirrors {
+		wanted[m.Name] = m
+	}
+	for name, r := range h.running {
+		if m, ok := wanted[name]; !ok || m.index != r.index {
+			close(r.stop)
+			delete(h.running, name)
+		}
+	}
+	for name, m := range wanted {
+		if _, ok := h.running[name]; !ok {
+			m.checkpoint = h.checkpoint(m)
+			r := &replicator{mirror: m, stop: make(chan bool)}
+			h.running[name] = r
+			go h.replicate(r)
+			go h.record(r)
+		}
+	}
+}
+
+// isLeader returns whether this member is the raft leader. Leader stats
+// are only served by the leader; every other member redirects to it.
+func (h *handler) isLeader() bool {
+	req, err := http.NewRequest("GET", h.addr+"/v2/stats/leader", nil)
+	if err != nil {
+		return false
+	}
+	resp, err := h.transport.RoundTrip(req)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return resp.StatusCode == http.StatusOK
+}
+
+// replicate watches the prefix of a mirror and copies every change to the
+// remote cluster in order. The whole prefix is copied first when the mirror
+// is new or when the watch fell too far behind.
+func (h *handler) replicate(r *replicator) {
+	backoff := retryInterval
+	retry := func(err error) bool {
+		r.setError(err)
+		select {
+		case <-r.stop:
+			return false
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > maxRetryInterval {
+			backoff = maxRetryInterval
+		}
+		return true
+	}
+
+	remote, err := r.remote()
+	for err != nil {
+		if !retry(err) {
+			return
+		}
+		remote, err = r.remote()
+	}
+
+	for {
+		if r.position() == 0 {
+			index, err := h.resync(r, remote)
+			if err != nil {
+				if !retry(err) {
+					return
+				}
+				continue
+			}
+			r.advance(index, true)
+		}
+
+		resp, err := h.client.Watch(r.Prefix, r.position()+1, true, nil, r.stop)
+		if err == etcd.ErrWatchStoppedByUser {
+			return
+		} else if e, ok := err.(etcd.EtcdError); ok && e.ErrorCode == 401 {
+			// The changes since the checkpoint are gone; copy everything.
+			log.Infof("mirror %s: fell behind, copying %s again", r.Name, r.Prefix)
+			r.advance(0, true)
+			continue
+		} else if err != nil {
+			if !retry(err) {
+				return
+			}
+			continue
+		}
+
+		if !r.includes(resp.Node.Key) {
+			r.advance(resp.Node.ModifiedIndex, false)
+			continue
+		}
+		for {
+			err = r.apply(remote, resp)
+			if _, ok := err.(etcd.EtcdError); err == nil || ok {
+				break
+			}
+			if !retry(err) {
+				return
+			}
+		}
+		if err != nil {
+			// The remote cluster refused the change; it will not accept
+			// it on a retry either.
+			log.Warnf("mirror %s: cannot copy %s %s: %v", r.Name, resp.Action, resp.Node.Key, err)
+			r.skip(err)
+		} else {
+			r.setError(nil)
+		}
+		backoff = retryInterval
+		r.advance(resp.Node.ModifiedIndex, true)
+	}
+}
+
+// apply copies a single change to the remote cluster.
+func (r *replicator) apply(remote *etcd.Client, resp *etcd.Response) error {
+	key := r.remoteKey(resp.Node.Key)
+	switch resp.Action {
+	case "delete", "expire":
+		_, err := remote.Delete(key, true)
+		if e, ok := err.(etcd.EtcdError); ok && e.ErrorCode == 100 {
+			return nil
+		}
+		return err
+	default:
+		return r.copyNode(remote, resp.Node)
+	}
+}
+
+// copyNode writes a node to the remote cluster with its remaining TTL.
+func (r *replicator) copyNode(remote *etcd.Client, node *etcd.Node) error {
+	key := r.remoteKey(node.Key)
+	ttl := uint64(node.TTL)
+	if !node.Dir {
+		_, err := remote.Set(key, node.Value, ttl)
+		return err
+	}
+
+	_, err := remote.SetDir(key, ttl)
+	if e, ok := err.(etcd.EtcdError); ok && e.ErrorCode == 102 {
+		// The directory exists already; only its TTL changes.
+		_, err = remote.UpdateDir(key, ttl)
+	}
+	return err
+}
+
+// resync copies every key under the prefix of a mirror and deletes the
+// remote keys that no longer exist locally. It returns the index at which
+// the copy was read.
+func (h *handler) resync(r *replicator, remote *etcd.Client) (uint64, error) {
+	raw, err := h.client.RawGet(r.Prefix, true, true)
+	if err != nil {
+		return 0, err
+	}
+	index, _ := strconv.ParseUint(raw.Header.Get("X-Etcd-Index"), 10, 64)
+
+	local := &etcd.Response{}
+	if err := decode(raw, local); err != nil {
+		if e, ok := err.(etcd.EtcdError); !ok || e.ErrorCode != 100 {
+			return 0, err
+		}
+		local.Node = &etcd.Node{Key: r.Prefix, Dir: true}
+	}
+
+	copied := make(map[string]bool)
+	var walk func(node *etcd.Node) error
+	walk = func(node *etcd.Node) error {
+		if !r.includes(node.Key) {
+			return nil
+		}
+		copied[r.remoteKey(node.Key)] = true
+		if !node.Dir || len(node.Nodes) == 0 {
+			if err := r.copyNode(remote, node); err != nil {
+				if _, ok := err.(etcd.EtcdError); !ok {
+					return err
+				}
+				r.skip(err)
+			}
+		}
+		for i := range node.Nodes {
+			if err := walk(&node.Nodes[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(local.Node); err != nil {
+		return 0, err
+	}
+
+	// Delete what was removed while the mirror was not replicating.
+	resp, err := remote.Get(r.Destination, false, true)
+	if err != nil {
+		if e, ok := err.(etcd.EtcdError); ok && e.ErrorCode == 100 {
+			return index, nil
+		}
+		return 0, err
+	}
+	var prune func(node *etcd.Node) error
+	prune = func(node *etcd.Node) error {
+		if !copied[node.Key] {
+			_, err := remote.Delete(node.Key, true)
+			if _, ok := err.(etcd.EtcdError); err != nil && !ok {
+				return err
+			}
+			return nil
+		}
+		for i := range node.Nodes {
+			if err := prune(&node.Nodes[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := prune(resp.Node); err != nil {
+		return 0, err
+	}
+	return index, nil
+}
+
+// decode parses a raw response, returning the error it carries if any.
+func decode(raw *etcd.RawResponse, resp *etcd.Response) error {
+	if raw.StatusCode == http.StatusBadRequest {
+		var e etcd.EtcdError
+		json.Unmarshal(raw.Body, &e)
+		return e
+	}
+	return json.Unmarshal(raw.Body, resp)
+}
+
+// record writes the checkpoint of a replicator periodically until it stops.
+func (h *handler) record(r *replicator) {
+	for {
+		select {
+		case <-r.stop:
+			h.saveCheckpoint(r)
+			return
+		case <-time.After(checkpointInterval):
+			h.saveCheckpoint(r)
+		}
+	}
+}
+
+// saveCheckpoint writes the checkpoint of a replicator if it changed. The
+// checkpoint is only swapped while it still belongs to the same definition,
+// so a mirror that was removed or set again is left alone.
+func (h *handler) saveCheckpoint(r *replicator) {
+	r.mutex.Lock()
+	if !r.dirty {
+		r.mutex.Unlock()
+		return
+	}
+	r.dirty = false
+	now := time.Now().UTC()
+	r.Updated = &now
+	b, _ := json.Marshal(r.checkpoint)
+	r.mutex.Unlock()
+
+	resp, err := h.client.Get(checkpointPath(r.Name), false, false)
+	if err != nil {
+		return
+	}
+	var old checkpoint
+	json.Unmarshal([]byte(resp.Node.Value), &old)
+	if old.Mirror != r.index {
+		return
+	}
+	if _, err := h.client.CompareAndSwap(checkpointPath(r.Name), string(b), 0, "", resp.Node.ModifiedIndex); err != nil {
+		log.Debugf("mirror %s: cannot record checkpoint: %v", r.Name, err)
+		r.mutex.Lock()
+		r.dirty = true
+		r.mutex.Unlock()
+	}
+}
+
+func (r *replicator) position() uint64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.Index
+}
+
+// advance moves the checkpoint to a local index. Changes outside of the
+// mirror do not need to be recorded on their own.
+func (r *replicator) advance(index uint64, record bool) {
+	r.mutex.Lock()
+	r.Index = index
+	r.dirty = r.dirty || record
+	r.mutex.Unlock()
+}
+
+// skip counts a change the remote cluster refused.
+func (r *replicator) skip(err error) {
+	r.mutex.Lock()
+	r.Skipped++
+	r.Error = err.Error()
+	r.dirty = true
+	r.mutex.Unlock()
+}
+
+// setError records the last error, or clears it.
+func (r *replicator) setError(err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	msg := ""
+	if err != nil {
+		msg = err.Error()
+	}
+	if r.Error != msg {
+		r.Error = msg
+		r.dirty = true
+	}
+}
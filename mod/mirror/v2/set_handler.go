@@ -0,0 +1,63 @@
+package v2
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// setHandler creates or replaces a mirror.
+// The "prefix" parameter is the local directory to copy, "url" is a client
+// URL of the destination cluster and "destination" the directory written
+// there, which defaults to the prefix. "certFile" and "keyFile" are the
+// client certificate presented to the destination; the files must exist on
+// every member since any of them may become the leader.
+func (h *handler) setHandler(w http.ResponseWriter, req *http.Request) {
+	h.client.SyncCluster()
+
+	m := &mirror{
+		Name:        mux.Vars(req)["name"],
+		Prefix:      path.Clean("/" + req.FormValue("prefix")),
+		URL:         req.FormValue("url"),
+		Destination: req.FormValue("destination"),
+		CertFile:    req.FormValue("certFile"),
+		KeyFile:     req.FormValue("keyFile"),
+	}
+	if m.Prefix == prefix || strings.HasPrefix(m.Prefix, prefix+"/") {
+		http.Error(w, "invalid prefix: "+m.Prefix, http.StatusInternalServerError)
+		return
+	}
+	if m.Destination == "" {
+		m.Destination = m.Prefix
+	}
+	m.Destination = path.Clean("/" + m.Destination)
+
+	u, err := url.Parse(m.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		http.Error(w, "invalid url: "+m.URL, http.StatusInternalServerError)
+		return
+	}
+	if _, err := m.remote(); err != nil {
+		http.Error(w, "invalid certificate: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp, err := h.client.Set(mirrorPath(m.Name), m.encode(), 0)
+	if err != nil {
+		http.Error(w, "set mirror error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Start over: the whole prefix is copied again.
+	m.index = resp.Node.ModifiedIndex
+	m.checkpoint = &checkpoint{Mirror: m.index}
+	b, _ := json.Marshal(m.checkpoint)
+	h.client.Set(checkpointPath(m.Name), string(b), 0)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(m)
+}
@@ -0,0 +1,95 @@
+package mirror
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/coreos/etcd/server"
+	"github.com/coreos/etcd/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+// Ensure that a prefix is copied and then kept in sync with the destination.
+func TestModMirrorReplicate(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		testSetKey(s, "foo/a", "1")
+		testSetKey(s, "bar/stale", "X")
+
+		// Mirror /foo to /bar on the same cluster.
+		resp, err := testSetMirror(s, "local", url.Values{"prefix": {"/foo"}, "url": {s.URL()}, "destination": {"/bar"}})
+		assert.NoError(t, err)
+		assert.Equal(t, resp.StatusCode, 200)
+		m := tests.ReadBodyJSON(resp)
+		assert.Equal(t, m["prefix"], "/foo")
+		assert.Equal(t, m["destination"], "/bar")
+
+		// The existing keys are copied and the others removed.
+		time.Sleep(2500 * time.Millisecond)
+		assert.Equal(t, testGetKeyValue(s, "bar/a"), "1")
+		assert.Equal(t, testGetKeyValue(s, "bar/stale"), "")
+
+		// Further changes are streamed.
+		testSetKey(s, "foo/b/c", "2")
+		tests.DeleteForm(fmt.Sprintf("%s/v2/keys/foo/a", s.URL()), nil)
+		time.Sleep(1500 * time.Millisecond)
+		assert.Equal(t, testGetKeyValue(s, "bar/b/c"), "2")
+		assert.Equal(t, testGetKeyValue(s, "bar/a"), "")
+
+		// The progress is checkpointed.
+		resp, _ = tests.Get(fmt.Sprintf("%s/mod/v2/mirror/local", s.URL()))
+		m = tests.ReadBodyJSON(resp)
+		assert.True(t, m["index"].(float64) > 0)
+		assert.Nil(t, m["error"])
+
+		resp, _ = tests.DeleteForm(fmt.Sprintf("%s/mod/v2/mirror/local", s.URL()), nil)
+		assert.Equal(t, resp.StatusCode, 200)
+		tests.ReadBody(resp)
+		resp, _ = tests.Get(fmt.Sprintf("%s/mod/v2/mirror/local", s.URL()))
+		assert.Equal(t, resp.StatusCode, 404)
+		tests.ReadBody(resp)
+
+		// Changes are no longer copied.
+		time.Sleep(1500 * time.Millisecond)
+		testSetKey(s, "foo/d", "3")
+		time.Sleep(500 * time.Millisecond)
+		assert.Equal(t, testGetKeyValue(s, "bar/d"), "")
+	})
+}
+
+// Ensure that invalid mirrors are rejected.
+func TestModMirrorInvalid(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		resp, _ := testSetMirror(s, "bad", url.Values{"prefix": {"/foo"}, "url": {"localhost:4001"}})
+		assert.Equal(t, resp.StatusCode, 500)
+		assert.Equal(t, string(tests.ReadBody(resp)), "invalid url: localhost:4001\n")
+
+		resp, _ = testSetMirror(s, "bad", url.Values{"prefix": {"/_etcd/mod/mirror"}, "url": {s.URL()}})
+		assert.Equal(t, resp.StatusCode, 500)
+		tests.ReadBody(resp)
+
+		resp, _ = tests.Get(fmt.Sprintf("%s/mod/v2/mirror", s.URL()))
+		assert.Equal(t, string(tests.ReadBody(resp)), "[]\n")
+	})
+}
+
+func testSetMirror(s *server.Server, name string, v url.Values) (*http.Response, error) {
+	return tests.PutForm(fmt.Sprintf("%s/mod/v2/mirror/%s", s.URL(), name), v)
+}
+
+func testSetKey(s *server.Server, key string, value string) {
+	resp, _ := tests.PutForm(fmt.Sprintf("%s/v2/keys/%s", s.URL(), key), url.Values{"value": {value}})
+	tests.ReadBody(resp)
+}
+
+func testGetKeyValue(s *server.Server, key string) string {
+	resp, _ := tests.Get(fmt.Sprintf("%s/v2/keys/%s", s.URL(), key))
+	body := tests.ReadBodyJSON(resp)
+	if node, ok := body["node"].(map[string]interface{}); ok {
+		value, _ := node["value"].(string)
+		return value
+	}
+	return ""
+}
@@ -5,24 +5,49 @@ import (
 	"net/http"
 	"path"
 
+	blobs2 "github.com/coreos/etcd/mod/blobs/v2"
 	"github.com/coreos/etcd/mod/dashboard"
+	flags2 "github.com/coreos/etcd/mod/flags/v2"
+	leader2 "github.com/coreos/etcd/mod/leader/v2"
+	lease2 "github.com/coreos/etcd/mod/lease/v2"
 	lock2 "github.com/coreos/etcd/mod/lock/v2"
+	mirror2 "github.com/coreos/etcd/mod/mirror/v2"
+	scheduler2 "github.com/coreos/etcd/mod/scheduler/v2"
+	tasks2 "github.com/coreos/etcd/mod/tasks/v2"
 	"github.com/gorilla/mux"
 )
 
 var ServeMux *http.Handler
 
+// The modules served under /mod.
+var Modules = []string{"dashboard", "lock", "multilock", "leader", "lease", "scheduler", "mirror", "flags", "tasks", "blobs"}
+
 func addSlash(w http.ResponseWriter, req *http.Request) {
 	http.Redirect(w, req, path.Join("mod", req.URL.Path) + "/", 302)
 	return
 }
 
-func HttpHandler(addr string) http.Handler {
+// HttpHandler creates the handler of the modules served by s at addr. The
+// lock, multilock and leader modules work on the store of s directly while it
+// leads the cluster; the other modules go through its client API, sending
+// their requests through tr.
+func HttpHandler(addr string, s lock2.Server, tr http.RoundTripper) http.Handler {
+	locks := lock2.NewStoreBackend(s, addr)
+	lockHandler := lock2.NewHandler(locks)
+
 	r := mux.NewRouter()
 	r.HandleFunc("/dashboard", addSlash)
 	r.PathPrefix("/dashboard/").Handler(http.StripPrefix("/dashboard/", dashboard.HttpHandler()))
 
 	// TODO: Use correct addr.
-	r.PathPrefix("/v2/lock").Handler(http.StripPrefix("/v2/lock", lock2.NewHandler(addr)))
+	r.PathPrefix("/v2/lock").Handler(http.StripPrefix("/v2/lock", lockHandler))
+	r.PathPrefix("/v2/multilock").Handler(http.StripPrefix("/v2", lock2.NewMultiLockHandler(locks)))
+	r.PathPrefix("/v2/leader").Handler(http.StripPrefix("/v2/leader", leader2.NewHandler(lockHandler)))
+	r.PathPrefix("/v2/lease").Handler(http.StripPrefix("/v2", lease2.NewHandler(addr, tr)))
+	r.PathPrefix("/v2/scheduler").Handler(http.StripPrefix("/v2", scheduler2.NewHandler(addr, tr)))
+	r.PathPrefix("/v2/mirror").Handler(http.StripPrefix("/v2", mirror2.NewHandler(addr, tr)))
+	r.PathPrefix("/v2/flags").Handler(http.StripPrefix("/v2", flags2.NewHandler(addr, tr)))
+	r.PathPrefix("/v2/tasks").Handler(http.StripPrefix("/v2", tasks2.NewHandler(addr, tr)))
+	r.PathPrefix("/v2/blobs").Handler(http.StripPrefix("/v2", blobs2.NewHandler(addr, tr)))
 	return r
 }
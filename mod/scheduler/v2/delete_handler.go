@@ -0,0 +1,19 @@
+package v2
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// deleteHandler removes a job and its run history.
+func (h *handler) deleteHandler(w http.ResponseWriter, req *http.Request) {
+	h.client.SyncCluster()
+
+	name := mux.Vars(req)["name"]
+	if _, err := h.client.Delete(jobPath(name), false); err != nil {
+		http.Error(w, "delete job error: "+err.Error(), http.StatusNotFound)
+		return
+	}
+	h.client.Delete(historyPath(name), true)
+}
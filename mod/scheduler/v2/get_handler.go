@@ -0,0 +1,69 @@
+package v2
+
+import (
+	"encoding/json"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/coreos/go-etcd/etcd"
+	"github.com/gorilla/mux"
+)
+
+// getHandler retrieves a job and the next time it will run.
+func (h *handler) getHandler(w http.ResponseWriter, req *http.Request) {
+	h.client.SyncCluster()
+
+	name := mux.Vars(req)["name"]
+	resp, err := h.client.Get(jobPath(name), false, false)
+	if err != nil {
+		http.Error(w, "get job error: "+err.Error(), http.StatusNotFound)
+		return
+	}
+	j, err := parseJob(name, resp.Node.Value)
+	if err != nil {
+		http.Error(w, "get job error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	j.Next = j.schedule.next(time.Now())
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(j)
+}
+
+// listHandler retrieves every job sorted by name.
+func (h *handler) listHandler(w http.ResponseWriter, req *http.Request) {
+	h.client.SyncCluster()
+
+	jobs, err := h.jobs()
+	if err != nil {
+		http.Error(w, "get jobs error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	now := time.Now()
+	for _, j := range jobs {
+		j.Next = j.schedule.next(now)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(jobs)
+}
+
+// jobs returns all valid jobs sorted by name.
+func (h *handler) jobs() ([]*job, error) {
+	jobs := make([]*job, 0)
+	resp, err := h.client.Get(path.Join(prefix, "jobs"), true, false)
+	if err != nil {
+		if e, ok := err.(etcd.EtcdError); ok && e.ErrorCode == 100 {
+			return jobs, nil
+		}
+		return nil, err
+	}
+
+	for _, node := range resp.Node.Nodes {
+		if j, err := parseJob(path.Base(node.Key), node.Value); err == nil {
+			jobs = append(jobs, j)
+		}
+	}
+	return jobs, nil
+}
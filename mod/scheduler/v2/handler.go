@@ -0,0 +1,40 @@
+package v2
+
+import (
+	"net/http"
+
+	"github.com/coreos/go-etcd/etcd"
+	"github.com/gorilla/mux"
+)
+
+const prefix = "/_etcd/mod/scheduler"
+
+// handler manages the scheduler HTTP request.
+type handler struct {
+	*mux.Router
+	client    *etcd.Client
+	transport *http.Transport
+	addr      string
+}
+
+// NewHandler creates an HTTP handler that can be registered on a router.
+// Its requests to the etcd server at addr are sent through tr.
+func NewHandler(addr string, tr http.RoundTripper) http.Handler {
+	h := &handler{
+		Router:    mux.NewRouter(),
+		client:    etcd.NewClient([]string{addr}),
+		transport: &http.Transport{},
+		addr:      addr,
+	}
+	h.client.SetTransport(tr)
+	h.StrictSlash(false)
+	h.HandleFunc("/scheduler", h.listHandler).Methods("GET")
+	h.HandleFunc("/scheduler/{name:[a-zA-Z0-9_.-]+}", h.getHandler).Methods("GET")
+	h.HandleFunc("/scheduler/{name:[a-zA-Z0-9_.-]+}", h.setHandler).Methods("PUT")
+	h.HandleFunc("/scheduler/{name:[a-zA-Z0-9_.-]+}", h.deleteHandler).Methods("DELETE")
+	h.HandleFunc("/scheduler/{name:[a-zA-Z0-9_.-]+}/history", h.historyHandler).Methods("GET")
+
+	go h.run()
+
+	return h
+}
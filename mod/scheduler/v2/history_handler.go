@@ -0,0 +1,32 @@
+package v2
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// historyHandler retrieves the recorded runs of a job, oldest first.
+func (h *handler) historyHandler(w http.ResponseWriter, req *http.Request) {
+	h.client.SyncCluster()
+
+	name := mux.Vars(req)["name"]
+	if _, err := h.client.Get(jobPath(name), false, false); err != nil {
+		http.Error(w, "get job error: "+err.Error(), http.StatusNotFound)
+		return
+	}
+
+	runs := make([]*run, 0)
+	if resp, err := h.client.Get(historyPath(name), true, false); err == nil {
+		for _, node := range resp.Node.Nodes {
+			r := &run{}
+			if err := json.Unmarshal([]byte(node.Value), r); err == nil {
+				runs = append(runs, r)
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(runs)
+}
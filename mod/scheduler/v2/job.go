@@ -0,0 +1,59 @@
+package v2
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"time"
+)
+
+// job is a webhook that is triggered on a schedule.
+type job struct {
+	Name     string    `json:"name"`
+	Schedule string    `json:"schedule"`
+	URL      string    `json:"url"`
+	Next     time.Time `json:"next,omitempty"`
+
+	schedule *schedule
+}
+
+// run is the record of a single triggering of a job.
+type run struct {
+	Scheduled time.Time  `json:"scheduled"`
+	Started   time.Time  `json:"started"`
+	Finished  *time.Time `json:"finished,omitempty"`
+	Status    int        `json:"status,omitempty"`
+	Error     string     `json:"error,omitempty"`
+}
+
+// jobPath returns the key that holds the definition of a given job.
+func jobPath(name string) string {
+	return path.Join(prefix, "jobs", name)
+}
+
+// historyPath returns the directory that holds the runs of a given job.
+func historyPath(name string) string {
+	return path.Join(prefix, "history", name)
+}
+
+// runPath returns the key that records the run of a job scheduled at t.
+// Runs are keyed by their zero padded unix time so they sort chronologically.
+func runPath(name string, t time.Time) string {
+	return path.Join(historyPath(name), fmt.Sprintf("%020d", t.Unix()))
+}
+
+// parseJob decodes a stored job definition.
+func parseJob(name string, value string) (*job, error) {
+	j := &job{}
+	if err := json.Unmarshal([]byte(value), j); err != nil {
+		return nil, err
+	}
+	j.Name = name
+
+	s, err := parseSchedule(j.Schedule)
+	if err != nil {
+		return nil, err
+	}
+	j.schedule = s
+	return j, nil
+}
@@ -0,0 +1,116 @@
+package v2
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/coreos/etcd/log"
+)
+
+const (
+	// How often the leader checks whether any job is due.
+	tickInterval = 500 * time.Millisecond
+
+	// How far back a newly elected leader looks for runs that were not
+	// triggered during the election.
+	leaderCatchUp = 10 * time.Second
+
+	// The amount of time to wait for a webhook to respond.
+	webhookTimeout = 30 * time.Second
+
+	// The number of runs kept in the history of each job.
+	maxHistory = 100
+)
+
+// run triggers the jobs that are due while this member is the leader.
+// Every run is claimed by creating its history record so that a job only
+// runs once per scheduled time, even across a change of leader.
+func (h *handler) run() {
+	webhook := &http.Client{Timeout: webhookTimeout}
+
+	var last time.Time
+	for {
+		time.Sleep(tickInterval)
+		if !h.isLeader() {
+			last = time.Time{}
+			continue
+		}
+
+		now := time.Now().UTC().Truncate(time.Second)
+		if now.Sub(last) > leaderCatchUp {
+			last = now.Add(-leaderCatchUp)
+		}
+
+		jobs, err := h.jobs()
+		if err != nil {
+			continue
+		}
+		for t := last.Add(time.Second); !t.After(now); t = t.Add(time.Second) {
+			for _, j := range jobs {
+				if j.schedule.matches(t) {
+					go h.trigger(webhook, j, t)
+				}
+			}
+		}
+		last = now
+	}
+}
+
+// isLeader returns whether this member is the raft leader. Leader stats
+// are only served by the leader; every other member redirects to it.
+func (h *handler) isLeader() bool {
+	req, err := http.NewRequest("GET", h.addr+"/v2/stats/leader", nil)
+	if err != nil {
+		return false
+	}
+	resp, err := h.transport.RoundTrip(req)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return resp.StatusCode == http.StatusOK
+}
+
+// trigger claims the run of a job scheduled at t, calls its webhook and
+// records the result.
+func (h *handler) trigger(webhook *http.Client, j *job, t time.Time) {
+	r := &run{Scheduled: t, Started: time.Now().UTC()}
+	b, _ := json.Marshal(r)
+
+	// Only the member that creates the record runs the job.
+	if _, err := h.client.Create(runPath(j.Name, t), string(b), 0); err != nil {
+		return
+	}
+
+	body, _ := json.Marshal(map[string]interface{}{"job": j.Name, "scheduled": t})
+	resp, err := webhook.Post(j.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		r.Error = err.Error()
+	} else {
+		r.Status = resp.StatusCode
+		resp.Body.Close()
+	}
+	finished := time.Now().UTC()
+	r.Finished = &finished
+
+	// Update rather than set so a job deleted mid-run leaves no history behind.
+	b, _ = json.Marshal(r)
+	if _, err := h.client.Update(runPath(j.Name, t), string(b), 0); err != nil {
+		log.Debugf("scheduler %s: cannot record run at %v: %v", j.Name, t, err)
+		return
+	}
+	h.prune(j.Name)
+}
+
+// prune deletes the oldest runs of a job beyond the history limit.
+func (h *handler) prune(name string) {
+	resp, err := h.client.Get(historyPath(name), true, false)
+	if err != nil {
+		return
+	}
+	for i := 0; i < len(resp.Node.Nodes)-maxHistory; i++ {
+		h.client.Delete(resp.Node.Nodes[i].Key, false)
+	}
+}
@@ -0,0 +1,164 @@
+package v2
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// schedule is a parsed cron expression.
+// Each field is a bitmask of the values it matches.
+type schedule struct {
+	second, minute, hour, dom, month, dow uint64
+
+	// Cron matches a day on either field when both are restricted.
+	domStar, dowStar bool
+}
+
+// The bounds of each cron field.
+type bounds struct {
+	min, max uint
+}
+
+var (
+	seconds = bounds{0, 59}
+	minutes = bounds{0, 59}
+	hours   = bounds{0, 23}
+	doms    = bounds{1, 31}
+	months  = bounds{1, 12}
+	dows    = bounds{0, 7}
+)
+
+// The farthest a schedule is searched ahead for its next run.
+const maxScheduleSearch = 5 * 366 * 24 * time.Hour
+
+// parseSchedule parses a cron expression. Five fields are minute, hour,
+// day of month, month and day of week; a sixth leading field adds seconds.
+// Fields accept "*", numbers, ranges ("1-5"), lists ("1,3") and steps ("*/15").
+// Times are matched in UTC.
+func parseSchedule(spec string) (*schedule, error) {
+	fields := strings.Fields(spec)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return nil, fmt.Errorf("expected 5 or 6 fields, found %d: %q", len(fields), spec)
+	}
+
+	s := &schedule{
+		domStar: fields[3] == "*",
+		dowStar: fields[5] == "*",
+	}
+	var err error
+	for i, b := range []bounds{seconds, minutes, hours, doms, months, dows} {
+		dst := []*uint64{&s.second, &s.minute, &s.hour, &s.dom, &s.month, &s.dow}[i]
+		if *dst, err = parseField(fields[i], b); err != nil {
+			return nil, err
+		}
+	}
+
+	// Sunday is both 0 and 7.
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	return s, nil
+}
+
+// parseField parses a comma separated cron field into a bitmask.
+func parseField(field string, b bounds) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		lo, hi, step := b.min, b.max, uint(1)
+
+		if i := strings.Index(part, "/"); i != -1 {
+			n, err := strconv.ParseUint(part[i+1:], 10, 8)
+			if err != nil || n == 0 {
+				return 0, fmt.Errorf("invalid step in %q", part)
+			}
+			step = uint(n)
+			part = part[:i]
+		}
+
+		if part != "*" {
+			r := strings.SplitN(part, "-", 2)
+			n, err := parseValue(r[0], b)
+			if err != nil {
+				return 0, err
+			}
+			lo, hi = n, n
+			if len(r) == 2 {
+				if hi, err = parseValue(r[1], b); err != nil {
+					return 0, err
+				}
+			} else if step > 1 {
+				// "5/10" means every 10 starting at 5.
+				hi = b.max
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("invalid range %q", part)
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+// parseValue parses a single number within the bounds of a field.
+func parseValue(s string, b bounds) (uint, error) {
+	n, err := strconv.ParseUint(s, 10, 8)
+	if err != nil || uint(n) < b.min || uint(n) > b.max {
+		return 0, fmt.Errorf("invalid value %q, must be between %d and %d", s, b.min, b.max)
+	}
+	return uint(n), nil
+}
+
+// matches returns whether the schedule fires at the second containing t.
+func (s *schedule) matches(t time.Time) bool {
+	t = t.UTC()
+	if s.second&(1<<uint(t.Second())) == 0 ||
+		s.minute&(1<<uint(t.Minute())) == 0 ||
+		s.hour&(1<<uint(t.Hour())) == 0 ||
+		s.month&(1<<uint(t.Month())) == 0 {
+		return false
+	}
+	return s.matchesDay(t)
+}
+
+// next returns the first time after t that the schedule fires.
+// Returns the zero time if it never fires, such as on February 30th.
+func (s *schedule) next(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Second).Add(time.Second)
+	end := t.Add(maxScheduleSearch)
+	for t.Before(end) {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !s.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = t.Truncate(time.Hour).Add(time.Hour)
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Truncate(time.Minute).Add(time.Minute)
+		case s.second&(1<<uint(t.Second())) == 0:
+			t = t.Add(time.Second)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// matchesDay returns whether the day of t matches the day fields.
+func (s *schedule) matchesDay(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
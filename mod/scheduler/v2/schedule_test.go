@@ -0,0 +1,58 @@
+package v2
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Ensure that cron expressions match the expected times.
+func TestScheduleMatches(t *testing.T) {
+	s, err := parseSchedule("*/15 9-17 * * 1-5")
+	assert.NoError(t, err)
+
+	// Monday 2013-12-02.
+	assert.True(t, s.matches(time.Date(2013, 12, 2, 9, 0, 0, 0, time.UTC)))
+	assert.True(t, s.matches(time.Date(2013, 12, 2, 17, 45, 0, 0, time.UTC)))
+	assert.False(t, s.matches(time.Date(2013, 12, 2, 9, 0, 1, 0, time.UTC)))
+	assert.False(t, s.matches(time.Date(2013, 12, 2, 9, 5, 0, 0, time.UTC)))
+	assert.False(t, s.matches(time.Date(2013, 12, 2, 18, 0, 0, 0, time.UTC)))
+	assert.False(t, s.matches(time.Date(2013, 12, 1, 9, 0, 0, 0, time.UTC)))
+}
+
+// Ensure that a restricted day of month or day of week both match.
+func TestScheduleMatchesEitherDay(t *testing.T) {
+	s, err := parseSchedule("0 0 1 * 0")
+	assert.NoError(t, err)
+	assert.True(t, s.matches(time.Date(2013, 12, 1, 0, 0, 0, 0, time.UTC)))
+	assert.True(t, s.matches(time.Date(2013, 12, 8, 0, 0, 0, 0, time.UTC)))
+	assert.True(t, s.matches(time.Date(2014, 1, 1, 0, 0, 0, 0, time.UTC)))
+	assert.False(t, s.matches(time.Date(2014, 1, 2, 0, 0, 0, 0, time.UTC)))
+
+	// Sunday may also be written as 7.
+	s, _ = parseSchedule("0 0 * * 7")
+	assert.True(t, s.matches(time.Date(2013, 12, 8, 0, 0, 0, 0, time.UTC)))
+}
+
+// Ensure that the next run is found.
+func TestScheduleNext(t *testing.T) {
+	s, _ := parseSchedule("30 2 29 2 *")
+	next := s.next(time.Date(2013, 12, 2, 9, 0, 0, 0, time.UTC))
+	assert.Equal(t, next, time.Date(2016, 2, 29, 2, 30, 0, 0, time.UTC))
+
+	s, _ = parseSchedule("*/10 * * * * *")
+	next = s.next(time.Date(2013, 12, 2, 9, 0, 5, 0, time.UTC))
+	assert.Equal(t, next, time.Date(2013, 12, 2, 9, 0, 10, 0, time.UTC))
+
+	s, _ = parseSchedule("0 0 30 2 *")
+	assert.True(t, s.next(time.Now()).IsZero())
+}
+
+// Ensure that invalid expressions are rejected.
+func TestParseScheduleInvalid(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "5-1 * * * *", "*/0 * * * *", "a * * * *"} {
+		_, err := parseSchedule(spec)
+		assert.Error(t, err, spec)
+	}
+}
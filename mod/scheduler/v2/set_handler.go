@@ -0,0 +1,40 @@
+package v2
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// setHandler creates or replaces a job.
+// The "schedule" parameter is a cron expression and "url" is the webhook
+// that receives a POST each time the schedule fires.
+func (h *handler) setHandler(w http.ResponseWriter, req *http.Request) {
+	h.client.SyncCluster()
+
+	name := mux.Vars(req)["name"]
+	s, err := parseSchedule(req.FormValue("schedule"))
+	if err != nil {
+		http.Error(w, "invalid schedule: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	u, err := url.Parse(req.FormValue("url"))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		http.Error(w, "invalid url: "+req.FormValue("url"), http.StatusInternalServerError)
+		return
+	}
+
+	j := &job{Name: name, Schedule: req.FormValue("schedule"), URL: u.String(), schedule: s}
+	b, _ := json.Marshal(&job{Schedule: j.Schedule, URL: j.URL})
+	if _, err := h.client.Set(jobPath(name), string(b), 0); err != nil {
+		http.Error(w, "set job error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	j.Next = s.next(time.Now())
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(j)
+}
@@ -0,0 +1,116 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/coreos/etcd/server"
+	"github.com/coreos/etcd/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+// Ensure that a job is triggered once per scheduled time and its runs are recorded.
+func TestModSchedulerTrigger(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		var mu sync.Mutex
+		seen := make(map[string]int)
+		hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			var body struct {
+				Job       string `json:"job"`
+				Scheduled string `json:"scheduled"`
+			}
+			json.NewDecoder(req.Body).Decode(&body)
+			mu.Lock()
+			seen[body.Scheduled]++
+			mu.Unlock()
+		}))
+		defer hook.Close()
+
+		resp, err := testSetJob(s, "tick", "* * * * * *", hook.URL)
+		assert.NoError(t, err)
+		assert.Equal(t, resp.StatusCode, 200)
+		j := tests.ReadBodyJSON(resp)
+		assert.Equal(t, j["name"], "tick")
+		assert.Equal(t, j["url"], hook.URL)
+
+		time.Sleep(3 * time.Second)
+
+		mu.Lock()
+		assert.True(t, len(seen) >= 2, fmt.Sprintf("runs: %v", seen))
+		for scheduled, n := range seen {
+			assert.Equal(t, n, 1, scheduled)
+		}
+		mu.Unlock()
+
+		resp, _ = tests.Get(fmt.Sprintf("%s/mod/v2/scheduler/tick/history", s.URL()))
+		var runs []map[string]interface{}
+		json.Unmarshal(tests.ReadBody(resp), &runs)
+		assert.True(t, len(runs) >= 2)
+		assert.Equal(t, runs[0]["status"], 200)
+
+		// Deleting the job stops it and removes its history.
+		resp, _ = tests.DeleteForm(fmt.Sprintf("%s/mod/v2/scheduler/tick", s.URL()), nil)
+		assert.Equal(t, resp.StatusCode, 200)
+		tests.ReadBody(resp)
+		resp, _ = tests.Get(fmt.Sprintf("%s/mod/v2/scheduler/tick/history", s.URL()))
+		assert.Equal(t, resp.StatusCode, 404)
+		tests.ReadBody(resp)
+	})
+}
+
+// Ensure that jobs can be retrieved and listed.
+func TestModSchedulerGet(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		resp, _ := testSetJob(s, "nightly", "30 2 * * *", "http://127.0.0.1:1/backup")
+		tests.ReadBody(resp)
+		resp, _ = testSetJob(s, "hourly", "0 * * * *", "http://127.0.0.1:1/report")
+		tests.ReadBody(resp)
+
+		resp, _ = tests.Get(fmt.Sprintf("%s/mod/v2/scheduler/nightly", s.URL()))
+		j := tests.ReadBodyJSON(resp)
+		assert.Equal(t, j["schedule"], "30 2 * * *")
+		next, err := time.Parse(time.RFC3339, j["next"].(string))
+		assert.NoError(t, err)
+		assert.Equal(t, next.Hour(), 2)
+		assert.Equal(t, next.Minute(), 30)
+
+		resp, _ = tests.Get(fmt.Sprintf("%s/mod/v2/scheduler", s.URL()))
+		var jobs []map[string]interface{}
+		json.Unmarshal(tests.ReadBody(resp), &jobs)
+		assert.Equal(t, len(jobs), 2)
+		assert.Equal(t, jobs[0]["name"], "hourly")
+		assert.Equal(t, jobs[1]["name"], "nightly")
+
+		resp, _ = tests.Get(fmt.Sprintf("%s/mod/v2/scheduler/missing", s.URL()))
+		assert.Equal(t, resp.StatusCode, 404)
+		tests.ReadBody(resp)
+	})
+}
+
+// Ensure that invalid schedules and webhooks are rejected.
+func TestModSchedulerInvalid(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		resp, _ := testSetJob(s, "bad", "61 * * * *", "http://127.0.0.1:1/")
+		assert.Equal(t, resp.StatusCode, 500)
+		tests.ReadBody(resp)
+
+		resp, _ = testSetJob(s, "bad", "* * *", "http://127.0.0.1:1/")
+		assert.Equal(t, resp.StatusCode, 500)
+		tests.ReadBody(resp)
+
+		resp, _ = testSetJob(s, "bad", "* * * * *", "ftp://127.0.0.1/")
+		assert.Equal(t, resp.StatusCode, 500)
+		tests.ReadBody(resp)
+	})
+}
+
+func testSetJob(s *server.Server, name, schedule, hook string) (*http.Response, error) {
+	v := url.Values{"schedule": {schedule}, "url": {hook}}
+	return tests.PutForm(fmt.Sprintf("%s/mod/v2/scheduler/%s", s.URL(), name), v)
+}
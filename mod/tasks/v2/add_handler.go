@@ -0,0 +1,91 @@
+package v2
+
+import (
+	"encoding/json"
+	"net/http"
+	"path"
+
+	"github.com/coreos/go-etcd/etcd"
+	"github.com/gorilla/mux"
+)
+
+// addHandler adds a task to the end of a queue.
+// The "value" parameter describes the work to do.
+func (h *handler) addHandler(w http.ResponseWriter, req *http.Request) {
+	h.client.SyncCluster()
+
+	queue := mux.Vars(req)["queue"]
+	resp, err := h.client.AddChild(queuePath(queue, tasksNode), req.FormValue("value"), 0)
+	if err != nil {
+		http.Error(w, "add task error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(newTask(resp.Node, nil))
+}
+
+// listHandler retrieves the tasks of a queue in the order they were added,
+// along with the workers holding a claim on them.
+func (h *handler) listHandler(w http.ResponseWriter, req *http.Request) {
+	h.client.SyncCluster()
+
+	queue := mux.Vars(req)["queue"]
+	tasks, claims, err := h.tasks(queue)
+	if err != nil {
+		http.Error(w, "list tasks error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	list := make([]*task, 0, len(tasks))
+	for i := range tasks {
+		n := &tasks[i]
+		list = append(list, newTask(n, claims[path.Base(n.Key)]))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// getHandler retrieves a task and the worker holding a claim on it.
+func (h *handler) getHandler(w http.ResponseWriter, req *http.Request) {
+	h.client.SyncCluster()
+
+	vars := mux.Vars(req)
+	queue, id := vars["queue"], vars["id"]
+	resp, err := h.client.Get(path.Join(queuePath(queue, tasksNode), id), false, false)
+	if err != nil {
+		http.Error(w, "get task error: "+err.Error(), http.StatusNotFound)
+		return
+	}
+	var claim *etcd.Node
+	if resp, err := h.client.Get(path.Join(queuePath(queue, claimsNode), id), false, false); err == nil {
+		claim = resp.Node
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(newTask(resp.Node, claim))
+}
+
+// tasks retrieves the tasks of a queue, oldest first, and its claims by task
+// id. A queue that was never used has neither.
+func (h *handler) tasks(queue string) (etcd.Nodes, map[string]*etcd.Node, error) {
+	var tasks etcd.Nodes
+	resp, err := h.client.Get(queuePath(queue, tasksNode), true, false)
+	if err == nil {
+		tasks = resp.Node.Nodes
+	} else if e, ok := err.(etcd.EtcdError); !ok || e.ErrorCode != 100 {
+		return nil, nil, err
+	}
+
+	var claims map[string]*etcd.Node
+	resp, err = h.client.Get(queuePath(queue, claimsNode), false, false)
+	if err == nil {
+		claims = children(resp.Node)
+	} else if e, ok := err.(etcd.EtcdError); !ok || e.ErrorCode != 100 {
+		return nil, nil, err
+	} else {
+		claims = children(nil)
+	}
+	return tasks, claims, nil
+}
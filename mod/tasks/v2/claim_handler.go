@@ -0,0 +1,105 @@
+package v2
+
+import (
+	"encoding/json"
+	"net/http"
+	"path"
+	"strconv"
+
+	"github.com/coreos/go-etcd/etcd"
+	"github.com/gorilla/mux"
+)
+
+// claimHandler assigns the oldest unclaimed task of a queue to a worker.
+// The "worker" parameter names the caller and "ttl" is the lease in seconds
+// after which the task can be claimed again unless it was renewed or
+// finished. It fails with 404 when every task is claimed.
+func (h *handler) claimHandler(w http.ResponseWriter, req *http.Request) {
+	h.client.SyncCluster()
+
+	queue := mux.Vars(req)["queue"]
+	worker := req.FormValue("worker")
+	ttl, err := leaseTTL(req)
+	if worker == "" {
+		http.Error(w, "claim task error: worker required", http.StatusBadRequest)
+		return
+	} else if err != nil {
+		http.Error(w, "claim task error: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tasks, claims, err := h.tasks(queue)
+	if err != nil {
+		http.Error(w, "claim task error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Creating the claim fails if another worker got there first, in which
+	// case the next task is tried.
+	for i := range tasks {
+		n := &tasks[i]
+		id := path.Base(n.Key)
+		if claims[id] != nil {
+			continue
+		}
+		resp, err := h.client.Create(path.Join(queuePath(queue, claimsNode), id), worker, ttl)
+		if e, ok := err.(etcd.EtcdError); ok && e.ErrorCode == 105 {
+			continue
+		} else if err != nil {
+			http.Error(w, "claim task error: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(newTask(n, resp.Node))
+		return
+	}
+
+	http.Error(w, "claim task error: no unclaimed task", http.StatusNotFound)
+}
+
+// renewHandler extends the lease of a worker on a task by "ttl" seconds.
+func (h *handler) renewHandler(w http.ResponseWriter, req *http.Request) {
+	h.client.SyncCluster()
+
+	vars := mux.Vars(req)
+	queue, id := vars["queue"], vars["id"]
+	worker := req.FormValue("worker")
+	ttl, err := leaseTTL(req)
+	if worker == "" {
+		http.Error(w, "renew task error: worker required", http.StatusBadRequest)
+		return
+	} else if err != nil {
+		http.Error(w, "renew task error: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.client.Get(path.Join(queuePath(queue, tasksNode), id), false, false)
+	if err != nil {
+		http.Error(w, "renew task error: "+errTaskNotFound(id).Error(), http.StatusNotFound)
+		return
+	}
+
+	// The swap only succeeds while the claim still belongs to the worker.
+	claim, err := h.client.CompareAndSwap(path.Join(queuePath(queue, claimsNode), id), worker, ttl, worker, 0)
+	if err != nil {
+		http.Error(w, "renew task error: "+errNotClaimed(worker).Error(), http.StatusConflict)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(newTask(resp.Node, claim.Node))
+}
+
+// leaseTTL reads the lease of a claim from the "ttl" parameter.
+func leaseTTL(req *http.Request) (uint64, error) {
+	s := req.FormValue("ttl")
+	if s == "" {
+		return defaultLeaseTTL, nil
+	}
+	ttl, err := strconv.ParseUint(s, 10, 64)
+	if err != nil || ttl == 0 {
+		return 0, errInvalidTTL(s)
+	}
+	return ttl, nil
+}
@@ -0,0 +1,98 @@
+package v2
+
+import (
+	"encoding/json"
+	"net/http"
+	"path"
+
+	"github.com/gorilla/mux"
+)
+
+// completeHandler records that a worker finished a task and removes it from
+// the queue. The optional "result" parameter is kept with the outcome.
+func (h *handler) completeHandler(w http.ResponseWriter, req *http.Request) {
+	h.client.SyncCluster()
+
+	vars := mux.Vars(req)
+	r := &result{
+		ID:     vars["id"],
+		Worker: req.FormValue("worker"),
+		Status: statusCompleted,
+		Result: req.FormValue("result"),
+	}
+	h.writeFinish(w, "complete task error: ", r, h.finish(vars["queue"], r, false))
+}
+
+// failHandler records that a worker gave up on a task. The task is removed
+// from the queue unless "retry=true" is given, in which case the claim is
+// released and the task can be claimed again right away.
+func (h *handler) failHandler(w http.ResponseWriter, req *http.Request) {
+	h.client.SyncCluster()
+
+	vars := mux.Vars(req)
+	r := &result{
+		ID:     vars["id"],
+		Worker: req.FormValue("worker"),
+		Status: statusFailed,
+		Result: req.FormValue("result"),
+	}
+	retry := req.FormValue("retry") == "true"
+	h.writeFinish(w, "fail task error: ", r, h.finish(vars["queue"], r, retry))
+}
+
+// resultHandler retrieves the last outcome recorded for a task.
+func (h *handler) resultHandler(w http.ResponseWriter, req *http.Request) {
+	h.client.SyncCluster()
+
+	vars := mux.Vars(req)
+	resp, err := h.client.Get(path.Join(queuePath(vars["queue"], resultsNode), vars["id"]), false, false)
+	if err != nil {
+		http.Error(w, "get task result error: "+err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(resp.Node.Value))
+}
+
+// finish checks that the worker holds the claim on the task, records the
+// outcome and then removes the task, or only the claim when it is retried.
+func (h *handler) finish(queue string, r *result, retry bool) error {
+	taskKey := path.Join(queuePath(queue, tasksNode), r.ID)
+	claimKey := path.Join(queuePath(queue, claimsNode), r.ID)
+
+	if _, err := h.client.Get(taskKey, false, false); err != nil {
+		return errTaskNotFound(r.ID)
+	}
+	claim, err := h.client.Get(claimKey, false, false)
+	if err != nil || r.Worker == "" || claim.Node.Value != r.Worker {
+		return errNotClaimed(r.Worker)
+	}
+
+	b, _ := json.Marshal(r)
+	if _, err := h.client.Set(path.Join(queuePath(queue, resultsNode), r.ID), string(b), resultTTL); err != nil {
+		return err
+	}
+	if !retry {
+		if _, err := h.client.Delete(taskKey, false); err != nil {
+			return err
+		}
+	}
+	h.client.Delete(claimKey, false)
+	return nil
+}
+
+// writeFinish writes the recorded outcome or the error of finishing a task.
+func (h *handler) writeFinish(w http.ResponseWriter, msg string, r *result, err error) {
+	switch err.(type) {
+	case nil:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(r)
+	case errTaskNotFound:
+		http.Error(w, msg+err.Error(), http.StatusNotFound)
+	case errNotClaimed:
+		http.Error(w, msg+err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, msg+err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,50 @@
+package v2
+
+import (
+	"net/http"
+	"path"
+
+	"github.com/coreos/go-etcd/etcd"
+	"github.com/gorilla/mux"
+)
+
+const prefix = "/_etcd/mod/tasks"
+
+// The directories of a queue holding its tasks, the claims of the workers
+// on them and the outcome of the finished ones.
+const (
+	tasksNode   = "tasks"
+	claimsNode  = "claims"
+	resultsNode = "results"
+)
+
+// handler manages the tasks HTTP request.
+type handler struct {
+	*mux.Router
+	client *etcd.Client
+}
+
+// NewHandler creates an HTTP handler that can be registered on a router.
+// Its requests to the etcd server at addr are sent through tr.
+func NewHandler(addr string, tr http.RoundTripper) http.Handler {
+	h := &handler{
+		Router: mux.NewRouter(),
+		client: etcd.NewClient([]string{addr}),
+	}
+	h.client.SetTransport(tr)
+	h.StrictSlash(false)
+	h.HandleFunc("/tasks/{queue:[a-zA-Z0-9_.-]+}", h.addHandler).Methods("POST")
+	h.HandleFunc("/tasks/{queue:[a-zA-Z0-9_.-]+}", h.listHandler).Methods("GET")
+	h.HandleFunc("/tasks/{queue:[a-zA-Z0-9_.-]+}/claim", h.claimHandler).Methods("POST")
+	h.HandleFunc("/tasks/{queue:[a-zA-Z0-9_.-]+}/{id:[0-9]+}", h.getHandler).Methods("GET")
+	h.HandleFunc("/tasks/{queue:[a-zA-Z0-9_.-]+}/{id:[0-9]+}/lease", h.renewHandler).Methods("PUT")
+	h.HandleFunc("/tasks/{queue:[a-zA-Z0-9_.-]+}/{id:[0-9]+}/complete", h.completeHandler).Methods("POST")
+	h.HandleFunc("/tasks/{queue:[a-zA-Z0-9_.-]+}/{id:[0-9]+}/fail", h.failHandler).Methods("POST")
+	h.HandleFunc("/tasks/{queue:[a-zA-Z0-9_.-]+}/{id:[0-9]+}/result", h.resultHandler).Methods("GET")
+	return h
+}
+
+// queuePath returns the path of a directory of a queue.
+func queuePath(queue, node string) string {
+	return path.Join(prefix, queue, node)
+}
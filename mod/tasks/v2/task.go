@@ -0,0 +1,79 @@
+package v2
+
+import (
+	"path"
+	"time"
+
+	"github.com/coreos/go-etcd/etcd"
+)
+
+// task is a unit of work as it is returned to producers and workers. The
+// worker and expiration are only set while the task is claimed.
+type task struct {
+	ID         string     `json:"id"`
+	Value      string     `json:"value"`
+	Worker     string     `json:"worker,omitempty"`
+	Expiration *time.Time `json:"expiration,omitempty"`
+}
+
+// result is the outcome a worker recorded for a task.
+type result struct {
+	ID     string `json:"id"`
+	Worker string `json:"worker"`
+	Status string `json:"status"`
+	Result string `json:"result,omitempty"`
+}
+
+// The statuses of a result.
+const (
+	statusCompleted = "completed"
+	statusFailed    = "failed"
+)
+
+// How long the outcome of a task is kept.
+const resultTTL = 24 * 60 * 60
+
+// The lease given to a claim that does not ask for one, in seconds.
+const defaultLeaseTTL = 60
+
+// newTask builds a task from its node and the node of its claim, if any.
+func newTask(n *etcd.Node, claim *etcd.Node) *task {
+	t := &task{ID: path.Base(n.Key), Value: n.Value}
+	if claim != nil {
+		t.Worker = claim.Value
+		t.Expiration = claim.Expiration
+	}
+	return t
+}
+
+// children indexes the children of a directory node by name.
+func children(n *etcd.Node) map[string]*etcd.Node {
+	m := make(map[string]*etcd.Node)
+	if n != nil {
+		for i := range n.Nodes {
+			m[path.Base(n.Nodes[i].Key)] = &n.Nodes[i]
+		}
+	}
+	return m
+}
+
+// errTaskNotFound is returned when a task does not exist.
+type errTaskNotFound string
+
+func (e errTaskNotFound) Error() string {
+	return "task not found: " + string(e)
+}
+
+// errInvalidTTL is returned for a lease that is not a positive number.
+type errInvalidTTL string
+
+func (e errInvalidTTL) Error() string {
+	return "invalid ttl: " + string(e)
+}
+
+// errNotClaimed is returned when a worker acts on a task it does not hold.
+type errNotClaimed string
+
+func (e errNotClaimed) Error() string {
+	return "task not claimed by " + string(e)
+}
@@ -0,0 +1,147 @@
+package tasks
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/coreos/etcd/server"
+	"github.com/coreos/etcd/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+// Ensure that tasks are claimed oldest first and only by one worker at a time.
+func TestModTasksClaim(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		id1 := testAddTask(t, s, "photo-1.jpg")
+		id2 := testAddTask(t, s, "photo-2.jpg")
+
+		resp, _ := testClaimTask(s, "worker-1", "30")
+		assert.Equal(t, resp.StatusCode, 200)
+		body := tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["id"], id1)
+		assert.Equal(t, body["value"], "photo-1.jpg")
+		assert.Equal(t, body["worker"], "worker-1")
+		assert.NotNil(t, body["expiration"])
+
+		resp, _ = testClaimTask(s, "worker-2", "30")
+		assert.Equal(t, resp.StatusCode, 200)
+		body = tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["id"], id2)
+		assert.Equal(t, body["worker"], "worker-2")
+
+		// Every task is claimed.
+		resp, _ = testClaimTask(s, "worker-3", "30")
+		assert.Equal(t, resp.StatusCode, 404)
+		tests.ReadBody(resp)
+
+		resp, _ = tests.Get(fmt.Sprintf("%s/mod/v2/tasks/thumbnails/%s", s.URL(), id1))
+		assert.Equal(t, resp.StatusCode, 200)
+		body = tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["worker"], "worker-1")
+
+		// Only the worker holding the claim may renew it.
+		resp, _ = tests.PutForm(fmt.Sprintf("%s/mod/v2/tasks/thumbnails/%s/lease", s.URL(), id1), url.Values{"worker": {"worker-2"}, "ttl": {"30"}})
+		assert.Equal(t, resp.StatusCode, 409)
+		tests.ReadBody(resp)
+		resp, _ = tests.PutForm(fmt.Sprintf("%s/mod/v2/tasks/thumbnails/%s/lease", s.URL(), id1), url.Values{"worker": {"worker-1"}, "ttl": {"30"}})
+		assert.Equal(t, resp.StatusCode, 200)
+		tests.ReadBody(resp)
+
+		resp, _ = testClaimTask(s, "", "30")
+		assert.Equal(t, resp.StatusCode, 400)
+		tests.ReadBody(resp)
+		resp, _ = testClaimTask(s, "worker-3", "0")
+		assert.Equal(t, resp.StatusCode, 400)
+		tests.ReadBody(resp)
+	})
+}
+
+// Ensure that a task can be claimed again once its lease expires.
+func TestModTasksLeaseExpiration(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		id := testAddTask(t, s, "photo-1.jpg")
+
+		resp, _ := testClaimTask(s, "worker-1", "1")
+		assert.Equal(t, resp.StatusCode, 200)
+		tests.ReadBody(resp)
+
+		time.Sleep(2 * time.Second)
+
+		resp, _ = testClaimTask(s, "worker-2", "30")
+		assert.Equal(t, resp.StatusCode, 200)
+		body := tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["id"], id)
+		assert.Equal(t, body["worker"], "worker-2")
+
+		// The first worker lost its claim.
+		resp, _ = testFinishTask(s, id, "complete", url.Values{"worker": {"worker-1"}})
+		assert.Equal(t, resp.StatusCode, 409)
+		tests.ReadBody(resp)
+	})
+}
+
+// Ensure that finished tasks leave the queue and keep their outcome.
+func TestModTasksFinish(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		id1 := testAddTask(t, s, "photo-1.jpg")
+		id2 := testAddTask(t, s, "photo-2.jpg")
+
+		resp, _ := testClaimTask(s, "worker-1", "30")
+		tests.ReadBody(resp)
+		resp, _ = testFinishTask(s, id1, "complete", url.Values{"worker": {"worker-1"}, "result": {"ok"}})
+		assert.Equal(t, resp.StatusCode, 200)
+		body := tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["status"], "completed")
+
+		resp, _ = tests.Get(fmt.Sprintf("%s/mod/v2/tasks/thumbnails/%s", s.URL(), id1))
+		assert.Equal(t, resp.StatusCode, 404)
+		tests.ReadBody(resp)
+		resp, _ = tests.Get(fmt.Sprintf("%s/mod/v2/tasks/thumbnails/%s/result", s.URL(), id1))
+		assert.Equal(t, resp.StatusCode, 200)
+		body = tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["status"], "completed")
+		assert.Equal(t, body["worker"], "worker-1")
+		assert.Equal(t, body["result"], "ok")
+
+		// A retried failure releases the task to the next worker.
+		resp, _ = testClaimTask(s, "worker-1", "30")
+		tests.ReadBody(resp)
+		resp, _ = testFinishTask(s, id2, "fail", url.Values{"worker": {"worker-1"}, "retry": {"true"}})
+		assert.Equal(t, resp.StatusCode, 200)
+		tests.ReadBody(resp)
+		resp, _ = testClaimTask(s, "worker-2", "30")
+		assert.Equal(t, resp.StatusCode, 200)
+		body = tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["id"], id2)
+
+		resp, _ = testFinishTask(s, id2, "fail", url.Values{"worker": {"worker-2"}})
+		assert.Equal(t, resp.StatusCode, 200)
+		tests.ReadBody(resp)
+		resp, _ = testClaimTask(s, "worker-3", "30")
+		assert.Equal(t, resp.StatusCode, 404)
+		tests.ReadBody(resp)
+
+		resp, _ = testFinishTask(s, id2, "complete", url.Values{"worker": {"worker-2"}})
+		assert.Equal(t, resp.StatusCode, 404)
+		tests.ReadBody(resp)
+	})
+}
+
+func testAddTask(t *testing.T, s *server.Server, value string) string {
+	resp, _ := tests.PostForm(fmt.Sprintf("%s/mod/v2/tasks/thumbnails", s.URL()), url.Values{"value": {value}})
+	assert.Equal(t, resp.StatusCode, 200)
+	body := tests.ReadBodyJSON(resp)
+	id, _ := body["id"].(string)
+	return id
+}
+
+func testClaimTask(s *server.Server, worker string, ttl string) (*http.Response, error) {
+	return tests.PostForm(fmt.Sprintf("%s/mod/v2/tasks/thumbnails/claim", s.URL()), url.Values{"worker": {worker}, "ttl": {ttl}})
+}
+
+func testFinishTask(s *server.Server, id string, action string, v url.Values) (*http.Response, error) {
+	return tests.PostForm(fmt.Sprintf("%s/mod/v2/tasks/thumbnails/%s/%s", s.URL(), id, action), v)
+}
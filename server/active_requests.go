@@ -0,0 +1,277 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	etcdErr "github.com/coreos/etcd/error"
+	"github.com/coreos/etcd/lifetime"
+	"github.com/gorilla/mux"
+)
+
+// activeRequests keeps track of the blocking requests currently being
+// served so that an administrator can list them and cancel stuck ones.
+type activeRequests struct {
+	sync.Mutex
+	nextID uint64
+	active map[uint64]*activeRequest
+}
+
+// activeRequest describes a client waiting on the server.
+type activeRequest struct {
+	ID         uint64 `json:"id"`
+	Kind       string `json:"kind"`
+	Key        string `json:"key"`
+	RemoteAddr string `json:"remoteAddr"`
+	Age        string `json:"age"`
+
+	startTime time.Time
+	writer    *cancelWriter
+}
+
+// The response of the active requests endpoint.
+type activeRequestsResponse struct {
+	Total    int              `json:"total"`
+	Requests []*activeRequest `json:"requests"`
+}
+
+func newActiveRequests() *activeRequests {
+	return &activeRequests{active: make(map[uint64]*activeRequest)}
+}
+
+// add records a request and returns a function that removes it.
+func (ar *activeRequests) add(r *activeRequest) func() {
+	ar.Lock()
+	defer ar.Unlock()
+	ar.nextID++
+	r.ID = ar.nextID
+	ar.active[r.ID] = r
+	return func() {
+		ar.Lock()
+		delete(ar.active, r.ID)
+		ar.Unlock()
+	}
+}
+
+// cancel ends the request with the given id. It returns false if there is no
+// such request.
+func (ar *activeRequests) cancel(id uint64) (*activeRequest, bool) {
+	ar.Lock()
+	r, ok := ar.active[id]
+	ar.Unlock()
+	if !ok {
+		return nil, false
+	}
+	r.writer.cancel()
+	return r, true
+}
+
+// snapshot returns the requests being served, oldest first.
+func (ar *activeRequests) snapshot(now time.Time) *activeRequestsResponse {
+	ar.Lock()
+	defer ar.Unlock()
+
+	requests := make([]*activeRequest, 0, len(ar.active))
+	for _, r := range ar.active {
+		c := *r
+		c.Age = now.Sub(r.startTime).String()
+		requests = append(requests, &c)
+	}
+	sort.Sort(requestsByAge(requests))
+	return &activeRequestsResponse{Total: len(requests), Requests: requests}
+}
+
+type requestsByAge []*activeRequest
+
+func (r requestsByAge) Len() int           { return len(r) }
+func (r requestsByAge) Less(i, j int) bool { return r[i].startTime.Before(r[j].startTime) }
+func (r requestsByAge) Swap(i, j int)      { r[i], r[j] = r[j], r[i] }
+
+// cancelWriter lets an administrator end a request by reporting the client
+// as gone to the handler waiting on it.
+type cancelWriter struct {
+	http.ResponseWriter
+	lifetime *lifetime.Lifetime
+
+	mutex     sync.Mutex
+	written   bool
+	cancelled bool
+}
+
+func newCancelWriter(w http.ResponseWriter) *cancelWriter {
+	return &cancelWriter{ResponseWriter: w, lifetime: lifetime.Start(w, -1)}
+}
+
+// CloseNotify fires when the client goes away or the request is cancelled.
+func (cw *cancelWriter) CloseNotify() <-chan bool {
+	return cw.lifetime.Done()
+}
+
+func (cw *cancelWriter) WriteHeader(code int) {
+	cw.wrote()
+	cw.ResponseWriter.WriteHeader(code)
+}
+
+func (cw *cancelWriter) Write(b []byte) (int, error) {
+	cw.wrote()
+	return cw.ResponseWriter.Write(b)
+}
+
+// Flush lets streams flush their events through the writer.
+func (cw *cancelWriter) Flush() {
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack lets websocket watches take over the connection.
+func (cw *cancelWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := cw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("connection cannot be hijacked")
+	}
+	cw.wrote()
+	return h.Hijack()
+}
+
+func (cw *cancelWriter) wrote() {
+	cw.mutex.Lock()
+	cw.written = true
+	cw.mutex.Unlock()
+}
+
+func (cw *cancelWriter) cancel() {
+	cw.mutex.Lock()
+	cw.cancelled = true
+	cw.mutex.Unlock()
+	cw.lifetime.Stop()
+}
+
+// unanswered tells whether the request was cancelled before the handler
+// answered.
+func (cw *cancelWriter) unanswered() bool {
+	cw.mutex.Lock()
+	defer cw.mutex.Unlock()
+	return cw.cancelled && !cw.written
+}
+
+func (cw *cancelWriter) stop() {
+	cw.lifetime.Stop()
+}
+
+// requestKind names the kind of a blocking request.
+func requestKind(req *http.Request) string {
+	p := req.URL.Path
+	switch {
+	case strings.HasPrefix(p, "/mod/v2/lock/"):
+		return "lock"
+	case strings.HasPrefix(p, "/mod/v2/leader/"):
+		return "leader"
+	case isWatchRequest(req):
+		return "watch"
+	}
+	return "ephemeral"
+}
+
+// requestKey returns the key a blocking request waits on, or the prefixes
+// of a stream.
+func requestKey(req *http.Request) string {
+	p := req.URL.Path
+	switch {
+	case p == "/v2/stream":
+		req.ParseForm()
+		return strings.Join(req.Form["prefix"], ",")
+	case strings.HasPrefix(p, "/mod/v2/lock/"):
+		return strings.TrimPrefix(p, "/mod/v2/lock")
+	case strings.HasPrefix(p, "/mod/v2/leader/"):
+		return strings.TrimPrefix(p, "/mod/v2/leader")
+	}
+	return "/" + mux.Vars(req)["key"]
+}
+
+// trackRequest starts tracking a blocking request and returns the writer its
+// handler must answer through, along with a function that stops tracking it.
+// Other requests, and the watches the modules keep on their own keys, are
+// not tracked.
+func (s *Server) trackRequest(w http.ResponseWriter, req *http.Request) (*cancelWriter, func()) {
+	if !isBlockingRequest(req) || strings.HasPrefix(mux.Vars(req)["key"], "_etcd/") {
+		return nil, func() {}
+	}
+	cw := newCancelWriter(w)
+	done := s.requests.add(&activeRequest{
+		Kind:       requestKind(req),
+		Key:        requestKey(req),
+		RemoteAddr: req.RemoteAddr,
+		startTime:  time.Now(),
+		writer:     cw,
+	})
+	return cw, func() {
+		done()
+		cw.stop()
+	}
+}
+
+// Lets administrators cancel blocking requests. A cancelled request that its
+// handler did not answer gets an error so the client knows to retry.
+func (s *Server) cancelableRequest(f func(http.ResponseWriter, *http.Request) error) func(http.ResponseWriter, *http.Request) error {
+	return func(w http.ResponseWriter, req *http.Request) error {
+		cw, done := s.trackRequest(w, req)
+		defer done()
+		if cw == nil {
+			return f(w, req)
+		}
+		if err := f(cw, req); err != nil || !cw.unanswered() {
+			return err
+		}
+		return etcdErr.NewError(etcdErr.EcodeRequestCancelled, req.URL.Path, s.store.Index())
+	}
+}
+
+// cancelableRequest for the modules, which are plain HTTP handlers.
+func (s *Server) cancelableModRequest(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		cw, done := s.trackRequest(w, req)
+		defer done()
+		if cw == nil {
+			h.ServeHTTP(w, req)
+			return
+		}
+		h.ServeHTTP(cw, req)
+		if cw.unanswered() {
+			w.Header().Set("Content-Type", "application/json")
+			etcdErr.NewError(etcdErr.EcodeRequestCancelled, req.URL.Path, s.store.Index()).Write(w)
+		}
+	})
+}
+
+// Lists the blocking requests this node is serving, oldest first.
+func (s *Server) GetActiveRequestsHandler(w http.ResponseWriter, req *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(s.requests.snapshot(time.Now()))
+}
+
+// Cancels a blocking request, as if its client went away.
+func (s *Server) DeleteActiveRequestHandler(w http.ResponseWriter, req *http.Request) error {
+	id, err := strconv.ParseUint(mux.Vars(req)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid request id", http.StatusBadRequest)
+		return nil
+	}
+	r, ok := s.requests.cancel(id)
+	if !ok {
+		http.Error(w, "No such request", http.StatusNotFound)
+		return nil
+	}
+	c := *r
+	c.Age = time.Now().Sub(r.startTime).String()
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(&c)
+}
@@ -0,0 +1,56 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// The networks allowed to use the admin endpoints when none are configured.
+var defaultAdminCIDRs = []string{"127.0.0.0/8", "::1"}
+
+// AllowAdmin sets the list of CIDRs whose clients may use the admin
+// endpoints. An empty list only allows clients on the loopback interface.
+func (s *Server) AllowAdmin(cidrs []string) error {
+	if len(cidrs) == 0 {
+		cidrs = defaultAdminCIDRs
+	}
+	nets, err := parseCIDRs(cidrs)
+	if err != nil {
+		return fmt.Errorf("Invalid admin CIDR: %s", err)
+	}
+	s.adminNets = nets
+	return nil
+}
+
+// AdminAllowed determines whether a request comes from an admin client.
+// The remote address has already been resolved through trusted proxies.
+func (s *Server) AdminAllowed(req *http.Request) bool {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, ipnet := range s.adminNets {
+		if ipnet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Adds a server handler that is only served to admin clients.
+func (s *Server) handleAdminFunc(path string, f func(http.ResponseWriter, *http.Request) error) *mux.Route {
+	return s.handleFunc(path, func(w http.ResponseWriter, req *http.Request) error {
+		if !s.AdminAllowed(req) || !s.HasAdminRole(req) {
+			http.Error(w, "Admin access required", http.StatusForbidden)
+			return nil
+		}
+		return f(w, req)
+	})
+}
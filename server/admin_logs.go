@@ -0,0 +1,33 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	etcdErr "github.com/coreos/etcd/error"
+	"github.com/coreos/etcd/log"
+)
+
+// Retrieves the recent log entries of this member. The since parameter
+// skips the entries already seen and level only keeps the entries at least
+// that severe.
+func (s *Server) GetLogsHandler(w http.ResponseWriter, req *http.Request) error {
+	var since uint64
+	if v := req.FormValue("since"); v != "" {
+		var err error
+		if since, err = strconv.ParseUint(v, 10, 64); err != nil {
+			return etcdErr.NewError(etcdErr.EcodeIndexNaN, "Since", s.Store().Index())
+		}
+	}
+
+	level := req.FormValue("level")
+	if level != "" && !log.ValidLevel(level) {
+		http.Error(w, "Invalid level", http.StatusBadRequest)
+		return nil
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(log.Recent(since, level))
+	return nil
+}
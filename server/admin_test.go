@@ -0,0 +1,27 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Ensures that only loopback clients are admins by default.
+func TestAdminAllowedDefault(t *testing.T) {
+	s := &Server{}
+	assert.Nil(t, s.AllowAdmin(nil), "")
+	assert.True(t, s.AdminAllowed(newProxyRequest("127.0.0.1:1234", "", "")), "")
+	assert.True(t, s.AdminAllowed(newProxyRequest("[::1]:1234", "", "")), "")
+	assert.False(t, s.AdminAllowed(newProxyRequest("10.0.0.1:1234", "", "")), "")
+}
+
+// Ensures that admin clients can be configured by CIDR.
+func TestAdminAllowedCIDRs(t *testing.T) {
+	s := &Server{}
+	assert.Nil(t, s.AllowAdmin([]string{"10.0.0.0/8", "192.168.1.1"}), "")
+	assert.True(t, s.AdminAllowed(newProxyRequest("10.1.2.3:1234", "", "")), "")
+	assert.True(t, s.AdminAllowed(newProxyRequest("192.168.1.1:1234", "", "")), "")
+	assert.False(t, s.AdminAllowed(newProxyRequest("192.168.1.2:1234", "", "")), "")
+	assert.False(t, s.AdminAllowed(newProxyRequest("127.0.0.1:1234", "", "")), "")
+	assert.Error(t, s.AllowAdmin([]string{"10.0.0.0/99"}))
+}
@@ -0,0 +1,112 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+
+	etcdErr "github.com/coreos/etcd/error"
+	"github.com/gorilla/mux"
+)
+
+// blockingStats counts the requests that hold a connection open waiting for
+// something to happen, such as watches and lock acquisitions.
+type blockingStats struct {
+	sync.Mutex
+	Active   int    `json:"active"`
+	Max      int    `json:"max"`
+	Rejected uint64 `json:"rejected"`
+}
+
+// enter admits a blocking request unless max are already waiting. Zero means
+// no limit. The returned function must be called once the request is done.
+func (b *blockingStats) enter(max int) (func(), bool) {
+	b.Lock()
+	defer b.Unlock()
+	b.Max = max
+	if max > 0 && b.Active >= max {
+		b.Rejected++
+		return nil, false
+	}
+	b.Active++
+	return func() {
+		b.Lock()
+		b.Active--
+		b.Unlock()
+	}, true
+}
+
+// JSON returns the current counts.
+func (b *blockingStats) JSON() []byte {
+	b.Lock()
+	defer b.Unlock()
+	j, _ := json.Marshal(b)
+	return j
+}
+
+// isBlockingRequest checks whether a request may wait indefinitely: watches,
+// ephemeral writes, and the lock and leader module calls that wait in a queue.
+func isBlockingRequest(req *http.Request) bool {
+	if isWatchRequest(req) || req.FormValue("ephemeral") == "true" {
+		return true
+	}
+	p := req.URL.Path
+	switch {
+	case strings.HasPrefix(p, "/mod/v2/lock/"):
+		return req.Method == "POST"
+	case strings.HasPrefix(p, "/mod/v2/leader/"):
+		return req.Method == "PUT" || req.FormValue("stream") == "true"
+	}
+	return false
+}
+
+// admitBlocking counts a blocking request against MaxBlockingRequests and
+// returns the function that releases it, or an error once the limit is
+// reached. Requests that do not block are always admitted, and so are the
+// watches the modules keep on their own keys under /_etcd.
+func (s *Server) admitBlocking(req *http.Request) (func(), error) {
+	if !isBlockingRequest(req) || strings.HasPrefix(mux.Vars(req)["key"], "_etcd/") {
+		return func() {}, nil
+	}
+	done, ok := s.blocking.enter(s.MaxBlockingRequests)
+	if !ok {
+		return nil, etcdErr.NewError(etcdErr.EcodeTooManyBlocking, req.URL.Path, s.store.Index())
+	}
+	return done, nil
+}
+
+// Rejects blocking requests beyond MaxBlockingRequests so that a flood of
+// watches cannot exhaust the goroutines and file descriptors of the server.
+func (s *Server) limitBlocking(f func(http.ResponseWriter, *http.Request) error) func(http.ResponseWriter, *http.Request) error {
+	return func(w http.ResponseWriter, req *http.Request) error {
+		done, err := s.admitBlocking(req)
+		if err != nil {
+			return err
+		}
+		defer done()
+		return f(w, req)
+	}
+}
+
+// limitBlocking for the modules, which are plain HTTP handlers.
+func (s *Server) limitModBlocking(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		done, err := s.admitBlocking(req)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			err.(*etcdErr.Error).Write(w)
+			return
+		}
+		defer done()
+		h.ServeHTTP(w, req)
+	})
+}
+
+// Retrieves the number of blocking requests this node is serving and how
+// many were rejected.
+func (s *Server) GetBlockingStatsHandler(w http.ResponseWriter, req *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(s.blocking.JSON())
+	return nil
+}
@@ -0,0 +1,191 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	etcdErr "github.com/coreos/etcd/error"
+	lock2 "github.com/coreos/etcd/mod/lock/v2"
+	"github.com/gorilla/context"
+	"github.com/gorilla/mux"
+)
+
+// A writeRule lets the client named by its certificate write the keys under
+// a prefix.
+type writeRule struct {
+	prefix string
+	name   string
+}
+
+// The store prefixes written by the modules, by the route they are served on.
+// The leader module is built on top of the lock module.
+var modWritePrefixes = []struct {
+	route  string
+	prefix string
+}{
+	{"/v2/lock/", "/_etcd/mod/lock/"},
+	{"/v2/leader/", "/_etcd/mod/lock/"},
+	{"/v2/scheduler/", "/_etcd/mod/scheduler/jobs/"},
+	{"/v2/mirror/", "/_etcd/mod/mirror/mirrors/"},
+	{"/v2/flags/", "/_etcd/mod/flags/"},
+	{"/v2/tasks/", "/_etcd/mod/tasks/"},
+	{"/v2/blobs/", "/_etcd/mod/blobs/"},
+}
+
+// AllowAdminNames sets the common names of the client certificates holding
+// the admin role. An empty list leaves the admin endpoints to AllowAdmin.
+func (s *Server) AllowAdminNames(names []string) {
+	s.adminNames = make(map[string]bool)
+	for _, name := range names {
+		s.adminNames[name] = true
+	}
+}
+
+// AllowWriters sets the rules for writing keys as a list of prefix=name
+// pairs, where name is the common name of a client certificate. An empty
+// list lets every client write every key.
+func (s *Server) AllowWriters(rules []string) error {
+	var writeRules []writeRule
+	for _, r := range rules {
+		kv := strings.SplitN(r, "=", 2)
+		if len(kv) != 2 || !strings.HasPrefix(kv[0], "/") || kv[1] == "" {
+			return fmt.Errorf("Invalid write rule: %s", r)
+		}
+		writeRules = append(writeRules, writeRule{prefix: kv[0], name: kv[1]})
+	}
+	s.writeRules = writeRules
+	return nil
+}
+
+// clientName returns the common name of the verified client certificate of
+// a request, or an empty string without one.
+func clientName(req *http.Request) string {
+	if req.TLS == nil || len(req.TLS.VerifiedChains) == 0 || len(req.TLS.VerifiedChains[0]) == 0 {
+		return ""
+	}
+	return req.TLS.VerifiedChains[0][0].Subject.CommonName
+}
+
+// HasAdminRole determines whether a request comes from a client holding the
+// admin role. Every client does when no admin names are set.
+func (s *Server) HasAdminRole(req *http.Request) bool {
+	if len(s.adminNames) == 0 {
+		return true
+	}
+	return s.adminNames[clientName(req)]
+}
+
+// The context key marking the requests the modules serve in-process.
+type internalRequestKey struct{}
+
+// isInternalRequest determines whether a request was sent by a module
+// in-process. The module request it serves was already checked against the
+// write rules, see checkModWrite.
+func isInternalRequest(req *http.Request) bool {
+	internal, _ := context.Get(req, internalRequestKey{}).(bool)
+	return internal
+}
+
+// WriteAllowed determines whether a request may write a key.
+func (s *Server) WriteAllowed(req *http.Request, key string) bool {
+	if len(s.writeRules) == 0 || isInternalRequest(req) {
+		return true
+	}
+	name := clientName(req)
+	if name == "" {
+		return false
+	}
+	for _, r := range s.writeRules {
+		if r.name == name && hasKeyPrefix(key, r.prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Restricts a handler to clients holding the admin role.
+func (s *Server) requireAdminRole(f func(http.ResponseWriter, *http.Request) error) func(http.ResponseWriter, *http.Request) error {
+	return func(w http.ResponseWriter, req *http.Request) error {
+		if !s.HasAdminRole(req) {
+			http.Error(w, "Admin access required", http.StatusForbidden)
+			return nil
+		}
+		return f(w, req)
+	}
+}
+
+// Rejects key writes that no write rule allows.
+func (s *Server) checkWrite(f func(http.ResponseWriter, *http.Request) error) func(http.ResponseWriter, *http.Request) error {
+	return func(w http.ResponseWriter, req *http.Request) error {
+		if req.Method == "GET" || strings.HasPrefix(req.URL.Path, "/v1/watch/") {
+			return f(w, req)
+		}
+		key := "/" + mux.Vars(req)["key"]
+		if !s.WriteAllowed(req, key) {
+			return etcdErr.NewError(etcdErr.EcodeUnauthorized, key, s.store.Index())
+		}
+
+		// Moving a value deletes the key it comes from.
+		if moveFrom := moveFromKey(req); moveFrom != "" {
+			moveFrom = path.Clean(path.Join("/", moveFrom))
+			if !s.WriteAllowed(req, moveFrom) {
+				return etcdErr.NewError(etcdErr.EcodeUnauthorized, moveFrom, s.store.Index())
+			}
+		}
+		return f(w, req)
+	}
+}
+
+// modWriteKey returns the key written by a module request, given its path
+// below /mod, or an empty string for requests outside of the modules.
+func modWriteKey(p string) string {
+	for _, m := range modWritePrefixes {
+		if strings.HasPrefix(p, m.route) {
+			key := strings.TrimSuffix(strings.TrimPrefix(p, m.route), "/_config")
+			return m.prefix + key
+		}
+	}
+
+	// Attaching to a lease writes the key itself.
+	if strings.HasPrefix(p, "/v2/lease") {
+		if i := strings.Index(p, "/keys/"); i != -1 {
+			return p[i+len("/keys"):]
+		}
+		return "/_etcd/mod/lease"
+	}
+	return ""
+}
+
+// modWriteKeys returns the keys written by a module request. A multilock
+// request writes every lock it is given.
+func modWriteKeys(req *http.Request) []string {
+	if req.URL.Path == "/v2/multilock" {
+		keys := lock2.ParseLockKeys(req.FormValue("keys"))
+		for i, key := range keys {
+			keys[i] = "/_etcd/mod/lock/" + key
+		}
+		return keys
+	}
+	if key := modWriteKey(req.URL.Path); key != "" {
+		return []string{key}
+	}
+	return nil
+}
+
+// Rejects module requests that change state without write access to the
+// keys the module writes.
+func (s *Server) checkModWrite(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != "GET" {
+			for _, key := range modWriteKeys(req) {
+				if !s.WriteAllowed(req, key) {
+					http.Error(w, "Write access required", http.StatusForbidden)
+					return
+				}
+			}
+		}
+		h.ServeHTTP(w, req)
+	})
+}
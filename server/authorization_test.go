@@ -0,0 +1,65 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newAuthRequest(name string) *http.Request {
+	req, _ := http.NewRequest("PUT", "/v2/keys/foo", nil)
+	if name != "" {
+		cert := &x509.Certificate{Subject: pkix.Name{CommonName: name}}
+		req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+	}
+	return req
+}
+
+// Ensures that every client holds the admin role until admin names are set.
+func TestHasAdminRole(t *testing.T) {
+	s := &Server{}
+	assert.True(t, s.HasAdminRole(newAuthRequest("")), "")
+
+	s.AllowAdminNames([]string{"ops"})
+	assert.True(t, s.HasAdminRole(newAuthRequest("ops")), "")
+	assert.False(t, s.HasAdminRole(newAuthRequest("web")), "")
+	assert.False(t, s.HasAdminRole(newAuthRequest("")), "")
+}
+
+// Ensures that write rules only let the named client write under the prefix.
+func TestWriteAllowed(t *testing.T) {
+	s := &Server{}
+	assert.True(t, s.WriteAllowed(newAuthRequest(""), "/foo"), "")
+
+	assert.Nil(t, s.AllowWriters([]string{"/services=web", "/_etcd/mod/lock/web=web"}), "")
+	assert.True(t, s.WriteAllowed(newAuthRequest("web"), "/services/a"), "")
+	assert.True(t, s.WriteAllowed(newAuthRequest("web"), "/services"), "")
+	assert.False(t, s.WriteAllowed(newAuthRequest("web"), "/servicesx"), "")
+	assert.False(t, s.WriteAllowed(newAuthRequest("db"), "/services/a"), "")
+	assert.False(t, s.WriteAllowed(newAuthRequest(""), "/services/a"), "")
+
+	assert.Error(t, s.AllowWriters([]string{"services=web"}))
+	assert.Error(t, s.AllowWriters([]string{"/services"}))
+}
+
+// Ensures that module requests map to the keys the modules write.
+func TestModWriteKey(t *testing.T) {
+	assert.Equal(t, modWriteKey("/v2/lock/web"), "/_etcd/mod/lock/web", "")
+	assert.Equal(t, modWriteKey("/v2/lock/web/_config"), "/_etcd/mod/lock/web", "")
+	assert.Equal(t, modWriteKey("/v2/leader/web"), "/_etcd/mod/lock/web", "")
+	assert.Equal(t, modWriteKey("/v2/lease/12/keys/services/a"), "/services/a", "")
+	assert.Equal(t, modWriteKey("/v2/lease"), "/_etcd/mod/lease", "")
+	assert.Equal(t, modWriteKey("/v2/scheduler/backup"), "/_etcd/mod/scheduler/jobs/backup", "")
+	assert.Equal(t, modWriteKey("/v2/mirror/config"), "/_etcd/mod/mirror/mirrors/config", "")
+	assert.Equal(t, modWriteKey("/dashboard/"), "", "")
+}
+
+// Ensures that a multilock request maps to every lock it takes.
+func TestModWriteKeysMultiLock(t *testing.T) {
+	req, _ := http.NewRequest("PUT", "/v2/multilock?keys=web,db,../web", nil)
+	assert.Equal(t, modWriteKeys(req), []string{"/_etcd/mod/lock/db", "/_etcd/mod/lock/web"}, "")
+}
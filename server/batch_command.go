@@ -0,0 +1,67 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/coreos/raft"
+)
+
+func init() {
+	raft.RegisterCommand(&BatchCommand{})
+}
+
+// The BatchCommand applies a group of commands from a single log entry.
+type BatchCommand struct {
+	Commands []*batchedCommand `json:"commands"`
+}
+
+// batchedCommand is a command encoded inside a batch.
+type batchedCommand struct {
+	Name string          `json:"name"`
+	Data json.RawMessage `json:"data"`
+}
+
+// batchResult is the outcome of applying a single command of a batch.
+type batchResult struct {
+	value interface{}
+	err   error
+}
+
+// Creates a new batch from a list of commands.
+func NewBatchCommand(commands []raft.Command) (*BatchCommand, error) {
+	c := &BatchCommand{}
+	for _, command := range commands {
+		var b bytes.Buffer
+		if encoder, ok := command.(raft.CommandEncoder); ok {
+			if err := encoder.Encode(&b); err != nil {
+				return nil, err
+			}
+		} else if err := json.NewEncoder(&b).Encode(command); err != nil {
+			return nil, err
+		}
+		c.Commands = append(c.Commands, &batchedCommand{Name: command.CommandName(), Data: b.Bytes()})
+	}
+	return c, nil
+}
+
+// The name of the batch command in the log
+func (c *BatchCommand) CommandName() string {
+	return "etcd:batch"
+}
+
+// Apply each command in order. A failing command does not stop the rest of
+// the batch; its error is returned in its own result.
+func (c *BatchCommand) Apply(server raft.Server) (interface{}, error) {
+	results := make([]*batchResult, len(c.Commands))
+	for i, bc := range c.Commands {
+		command, err := raft.NewCommand(bc.Name, bc.Data)
+		if err != nil {
+			results[i] = &batchResult{err: err}
+			continue
+		}
+		value, err := command.Apply(server)
+		results[i] = &batchResult{value: value, err: err}
+	}
+	return results, nil
+}
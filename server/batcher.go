@@ -0,0 +1,158 @@
+package server
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	etcdErr "github.com/coreos/etcd/error"
+	"github.com/coreos/etcd/store"
+	"github.com/coreos/raft"
+)
+
+// The maximum number of commands that are grouped into one log entry.
+const maxBatchSize = 1000
+
+// batcher groups commands that arrive within a short window on the leader so
+// that they are committed as a single log entry.
+type batcher struct {
+	raftServer raft.Server
+	window     time.Duration
+	c          chan *batchRequest
+	// Batches wait here to be flushed one at a time, in order.
+	flushes chan []*batchRequest
+	stats   batchStats
+}
+
+type batchRequest struct {
+	command raft.Command
+	c       chan *batchResult
+}
+
+// batchStats tracks the size of the batches sent to raft.
+type batchStats struct {
+	mutex    sync.Mutex
+	Window   int64   `json:"window"`
+	Batches  uint64  `json:"batches"`
+	Commands uint64  `json:"commands"`
+	LastSize int     `json:"lastSize"`
+	MaxSize  int     `json:"maxSize"`
+	AvgSize  float64 `json:"avgSize"`
+}
+
+func newBatcher(raftServer raft.Server, window time.Duration) *batcher {
+	b := &batcher{
+		raftServer: raftServer,
+		window:     window,
+		c:          make(chan *batchRequest, maxBatchSize),
+		flushes:    make(chan []*batchRequest, 1),
+	}
+	b.stats.Window = int64(window / time.Millisecond)
+	go b.loop()
+	go b.flusher()
+	return b
+}
+
+// Do queues a command for the next batch and waits for its result.
+func (b *batcher) Do(command raft.Command) (interface{}, error) {
+	req := &batchRequest{command: command, c: make(chan *batchResult, 1)}
+	b.c <- req
+	r := <-req.c
+	return r.value, r.err
+}
+
+// loop collects requests for one window at a time and hands them to the
+// flusher. The next batch is collected while the previous one commits.
+func (b *batcher) loop() {
+	for {
+		batch := []*batchRequest{<-b.c}
+		timeout := time.After(b.window)
+
+	collect:
+		for len(batch) < maxBatchSize {
+			select {
+			case req := <-b.c:
+				batch = append(batch, req)
+			case <-timeout:
+				break collect
+			}
+		}
+
+		b.flushes <- batch
+	}
+}
+
+// flusher sends the batches to raft in the order they were collected, so
+// that writes are applied in the order they arrived.
+func (b *batcher) flusher() {
+	for batch := range b.flushes {
+		b.flush(batch)
+	}
+}
+
+// flush commits a batch and hands each command its own result.
+func (b *batcher) flush(batch []*batchRequest) {
+	b.stats.record(len(batch))
+
+	// A batch of one is sent as is.
+	if len(batch) == 1 {
+		value, err := b.raftServer.Do(batch[0].command)
+		batch[0].c <- &batchResult{value: value, err: err}
+		return
+	}
+
+	commands := make([]raft.Command, len(batch))
+	for i, req := range batch {
+		commands[i] = req.command
+	}
+
+	c, err := NewBatchCommand(commands)
+	if err == nil {
+		var value interface{}
+		if value, err = b.raftServer.Do(c); err == nil {
+			results, ok := value.([]*batchResult)
+			if ok && len(results) == len(batch) {
+				for i, req := range batch {
+					req.c <- results[i]
+				}
+				return
+			}
+			err = etcdErr.NewError(etcdErr.EcodeRaftInternal, "Unexpected batch result", b.index())
+		}
+	}
+
+	for _, req := range batch {
+		req.c <- &batchResult{err: err}
+	}
+}
+
+// index returns the current index of the store.
+func (b *batcher) index() uint64 {
+	if s, ok := b.raftServer.StateMachine().(store.Store); ok {
+		return s.Index()
+	}
+	return 0
+}
+
+// record adds a batch of the given size to the stats.
+func (s *batchStats) record(size int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.Batches++
+	s.Commands += uint64(size)
+	s.LastSize = size
+	if size > s.MaxSize {
+		s.MaxSize = size
+	}
+	s.AvgSize = float64(s.Commands) / float64(s.Batches)
+}
+
+// JSON returns the stats encoded as JSON.
+func (s *batchStats) JSON() []byte {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	b, _ := json.Marshal(s)
+	return b
+}
@@ -0,0 +1,46 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// The header in which a peer answering an append entries request reports
+// its wall clock time, in nanoseconds since the epoch.
+const peerTimeHeader = "X-Etcd-Time"
+
+// The default clock difference above which a follower is reported as skewed.
+const defaultMaxClockSkew = time.Second
+
+// setPeerTime reports the local time in a response to the leader.
+func setPeerTime(w http.ResponseWriter) {
+	w.Header().Set(peerTimeHeader, strconv.FormatInt(time.Now().UnixNano(), 10))
+}
+
+// clockSkew estimates how far ahead of the local clock the clock of the peer
+// that answered is. The peer is assumed to have read its clock halfway
+// through the round trip, so the error is at most half the latency.
+func clockSkew(resp *http.Response, start, end time.Time) (time.Duration, bool) {
+	v := resp.Header.Get(peerTimeHeader)
+	if v == "" {
+		return 0, false
+	}
+	nsec, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	middle := start.Add(end.Sub(start) / 2)
+	return time.Unix(0, nsec).Sub(middle), true
+}
+
+// UpdateClockSkew records the latest clock skew estimate of the follower.
+// It returns true when the follower crosses the threshold either way.
+func (ps *raftFollowerStats) UpdateClockSkew(d time.Duration, threshold time.Duration) bool {
+	ps.ClockSkew.Current = float64(d) / 1000000.0
+
+	skewed := d > threshold || d < -threshold
+	changed := skewed != ps.ClockSkew.Skewed
+	ps.ClockSkew.Skewed = skewed
+	return changed
+}
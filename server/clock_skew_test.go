@@ -0,0 +1,45 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Ensures that the skew is measured from the middle of the round trip.
+func TestClockSkew(t *testing.T) {
+	start := time.Now()
+	end := start.Add(100 * time.Millisecond)
+	peer := start.Add(50*time.Millisecond + 3*time.Second)
+
+	resp := &http.Response{Header: http.Header{}}
+	_, ok := clockSkew(resp, start, end)
+	assert.False(t, ok)
+
+	resp.Header.Set(peerTimeHeader, strconv.FormatInt(peer.UnixNano(), 10))
+	skew, ok := clockSkew(resp, start, end)
+	assert.True(t, ok)
+	assert.Equal(t, skew, 3*time.Second)
+
+	w := httptest.NewRecorder()
+	setPeerTime(w)
+	skew, ok = clockSkew(&http.Response{Header: w.Header()}, time.Now(), time.Now())
+	assert.True(t, ok)
+	assert.True(t, skew < time.Second && skew > -time.Second)
+}
+
+// Ensures that crossing the threshold either way is reported once.
+func TestFollowerClockSkew(t *testing.T) {
+	var ps raftFollowerStats
+	assert.False(t, ps.UpdateClockSkew(100*time.Millisecond, time.Second))
+	assert.True(t, ps.UpdateClockSkew(-2*time.Second, time.Second))
+	assert.True(t, ps.ClockSkew.Skewed)
+	assert.Equal(t, ps.ClockSkew.Current, -2000.0)
+	assert.False(t, ps.UpdateClockSkew(3*time.Second, time.Second))
+	assert.True(t, ps.UpdateClockSkew(0, time.Second))
+	assert.False(t, ps.ClockSkew.Skewed)
+}
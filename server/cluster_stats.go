@@ -0,0 +1,128 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/coreos/etcd/log"
+)
+
+// memberStatus is what a member reports about itself to the cluster stats.
+type memberStatus struct {
+	Name        string `json:"name"`
+	Reachable   bool   `json:"reachable"`
+	Error       string `json:"error,omitempty"`
+	State       string `json:"state,omitempty"`
+	Leader      string `json:"leader,omitempty"`
+	Term        uint64 `json:"term,omitempty"`
+	CommitIndex uint64 `json:"commitIndex,omitempty"`
+	StoreIndex  uint64 `json:"storeIndex,omitempty"`
+	DataSize    int64  `json:"dataSize,omitempty"`
+	Version     string `json:"version,omitempty"`
+}
+
+// clusterStats is the merged view of every member of the cluster.
+type clusterStats struct {
+	Leader  string          `json:"leader"`
+	Members []*memberStatus `json:"members"`
+}
+
+type membersByName []*memberStatus
+
+func (m membersByName) Len() int           { return len(m) }
+func (m membersByName) Less(i, j int) bool { return m[i].Name < m[j].Name }
+func (m membersByName) Swap(i, j int)      { m[i], m[j] = m[j], m[i] }
+
+// Status returns what this member reports about itself.
+func (s *PeerServer) Status() *memberStatus {
+	return &memberStatus{
+		Name:        s.name,
+		Reachable:   true,
+		State:       s.raftServer.State(),
+		Leader:      s.raftServer.Leader(),
+		Term:        s.raftServer.Term(),
+		CommitIndex: s.raftServer.CommitIndex(),
+		StoreIndex:  s.store.Index(),
+		DataSize:    dirSize(s.raftServer.Path()),
+		Version:     ReleaseVersion,
+	}
+}
+
+// peerStatus fetches the status of another member.
+func (s *PeerServer) peerStatus(name string) (*memberStatus, error) {
+	peerURL, ok := s.registry.PeerURL(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown peer %s", name)
+	}
+
+	t := s.raftServer.Transporter().(*transporter)
+	resp, req, err := t.Get(fmt.Sprintf("%s/status", peerURL))
+	if err != nil {
+		return nil, err
+	}
+	t.CancelWhenTimeout(req)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status: %s", resp.Status)
+	}
+	var status memberStatus
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// ClusterStats asks every member for its status at once. Members that
+// cannot be reached are listed with the error.
+func (s *PeerServer) ClusterStats() []byte {
+	stats := &clusterStats{Leader: s.raftServer.Leader(), Members: s.memberStatuses()}
+	b, _ := json.Marshal(stats)
+	return b
+}
+
+// memberStatuses fetches the status of every member at once, sorted by
+// name. Members that cannot be reached come with the error.
+func (s *PeerServer) memberStatuses() []*memberStatus {
+	names := s.registry.Names()
+	statuses := make([]*memberStatus, len(names))
+
+	var wg sync.WaitGroup
+	for i, name := range names {
+		if name == s.name {
+			statuses[i] = s.Status()
+			continue
+		}
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			status, err := s.peerStatus(name)
+			if err != nil {
+				log.Debugf("[stats] cannot reach %s: %v", name, err)
+				status = &memberStatus{Name: name, Error: err.Error()}
+			}
+			statuses[i] = status
+		}(i, name)
+	}
+	wg.Wait()
+
+	sort.Sort(membersByName(statuses))
+	return statuses
+}
+
+// dirSize returns the total size of the files under a directory.
+func dirSize(dir string) int64 {
+	var size int64
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
@@ -12,6 +12,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/coreos/etcd/log"
@@ -46,35 +47,83 @@ var newFlagNameLookup = map[string]string{
 type Config struct {
 	SystemPath string
 
-	Addr             string `toml:"addr" env:"ETCD_ADDR"`
-	BindAddr         string `toml:"bind_addr" env:"ETCD_BIND_ADDR"`
-	CAFile           string `toml:"ca_file" env:"ETCD_CA_FILE"`
-	CertFile         string `toml:"cert_file" env:"ETCD_CERT_FILE"`
-	CPUProfileFile   string
-	CorsOrigins      []string `toml:"cors" env:"ETCD_CORS"`
-	DataDir          string   `toml:"data_dir" env:"ETCD_DATA_DIR"`
-	Force            bool
-	KeyFile          string   `toml:"key_file" env:"ETCD_KEY_FILE"`
-	Peers            []string `toml:"peers" env:"ETCD_PEERS"`
-	PeersFile        string   `toml:"peers_file" env:"ETCD_PEERS_FILE"`
-	MaxClusterSize   int      `toml:"max_cluster_size" env:"ETCD_MAX_CLUSTER_SIZE"`
-	MaxResultBuffer  int      `toml:"max_result_buffer" env:"ETCD_MAX_RESULT_BUFFER"`
-	MaxRetryAttempts int      `toml:"max_retry_attempts" env:"ETCD_MAX_RETRY_ATTEMPTS"`
-	Name             string   `toml:"name" env:"ETCD_NAME"`
-	Snapshot         bool     `toml:"snapshot" env:"ETCD_SNAPSHOT"`
-	SnapshotCount    int      `toml:"snapshot_count" env:"ETCD_SNAPSHOTCOUNT"`
-	ShowHelp         bool
-	ShowVersion      bool
-	Verbose          bool `toml:"verbose" env:"ETCD_VERBOSE"`
-	VeryVerbose      bool `toml:"very_verbose" env:"ETCD_VERY_VERBOSE"`
-	HeartbeatTimeout int  `toml:"peer_heartbeat_timeout" env:"ETCD_PEER_HEARTBEAT_TIMEOUT"`
-	ElectionTimeout  int  `toml:"peer_election_timeout" env:"ETCD_PEER_ELECTION_TIMEOUT"`
-	Peer             struct {
-		Addr     string `toml:"addr" env:"ETCD_PEER_ADDR"`
-		BindAddr string `toml:"bind_addr" env:"ETCD_PEER_BIND_ADDR"`
-		CAFile   string `toml:"ca_file" env:"ETCD_PEER_CA_FILE"`
-		CertFile string `toml:"cert_file" env:"ETCD_PEER_CERT_FILE"`
-		KeyFile  string `toml:"key_file" env:"ETCD_PEER_KEY_FILE"`
+	AccessLog         bool   `toml:"access_log" env:"ETCD_ACCESS_LOG"`
+	Addr              string `toml:"addr" env:"ETCD_ADDR"`
+	BatchWindow       int    `toml:"batch_window" env:"ETCD_BATCH_WINDOW"`
+	BindAddr          string `toml:"bind_addr" env:"ETCD_BIND_ADDR"`
+	CAFile            string `toml:"ca_file" env:"ETCD_CA_FILE"`
+	CertFile          string `toml:"cert_file" env:"ETCD_CERT_FILE"`
+	CPUProfileFile    string
+	AdminCIDRs        []string `toml:"admin_cidrs" env:"ETCD_ADMIN_CIDRS"`
+	AdminNames        []string `toml:"admin_names" env:"ETCD_ADMIN_NAMES"`
+	AllowCIDRs        []string `toml:"allow_cidrs" env:"ETCD_ALLOW_CIDRS"`
+	CorsOrigins       []string `toml:"cors" env:"ETCD_CORS"`
+	DataDir           string   `toml:"data_dir" env:"ETCD_DATA_DIR"`
+	DebugTTL          int      `toml:"debug_ttl" env:"ETCD_DEBUG_TTL"`
+	DefaultTTL        int      `toml:"default_ttl" env:"ETCD_DEFAULT_TTL"`
+	DenyCIDRs         []string `toml:"deny_cidrs" env:"ETCD_DENY_CIDRS"`
+	Dev               bool     `toml:"dev" env:"ETCD_DEV"`
+	DevFixtures       string   `toml:"dev_fixtures" env:"ETCD_DEV_FIXTURES"`
+	EncryptPrefixes   []string `toml:"encrypt_prefixes" env:"ETCD_ENCRYPT_PREFIXES"`
+	EncryptionKeyFile string   `toml:"encryption_key_file" env:"ETCD_ENCRYPTION_KEY_FILE"`
+	FoldCasePrefixes  []string `toml:"fold_case_prefixes" env:"ETCD_FOLD_CASE_PREFIXES"`
+	Force             bool
+	HashCheckInterval int      `toml:"hash_check_interval" env:"ETCD_HASH_CHECK_INTERVAL"`
+	KeyFile           string   `toml:"key_file" env:"ETCD_KEY_FILE"`
+	LeaderZone        string   `toml:"leader_zone" env:"ETCD_LEADER_ZONE"`
+	LogSlowRequests   string   `toml:"log_slow_requests" env:"ETCD_LOG_SLOW_REQUESTS"`
+	Peers             []string `toml:"peers" env:"ETCD_PEERS"`
+	PeersFile         string   `toml:"peers_file" env:"ETCD_PEERS_FILE"`
+	MaxBlocking       int      `toml:"max_blocking_requests" env:"ETCD_MAX_BLOCKING_REQUESTS"`
+	MaxClockSkew      int      `toml:"max_clock_skew" env:"ETCD_MAX_CLOCK_SKEW"`
+	MaxClusterSize    int      `toml:"max_cluster_size" env:"ETCD_MAX_CLUSTER_SIZE"`
+	MaxKeyDepth       int      `toml:"max_key_depth" env:"ETCD_MAX_KEY_DEPTH"`
+	MaxKeyNameLength  int      `toml:"max_key_name_length" env:"ETCD_MAX_KEY_NAME_LENGTH"`
+	MaxLogBytes       int      `toml:"max_log_bytes" env:"ETCD_MAX_LOG_BYTES"`
+	MaxObservers      int      `toml:"max_observers" env:"ETCD_MAX_OBSERVERS"`
+	MaxResultBuffer   int      `toml:"max_result_buffer" env:"ETCD_MAX_RESULT_BUFFER"`
+	MaxRetryAttempts  int      `toml:"max_retry_attempts" env:"ETCD_MAX_RETRY_ATTEMPTS"`
+	MaxTTL            int      `toml:"max_ttl" env:"ETCD_MAX_TTL"`
+	MaxWatchDuration  int      `toml:"max_watch_duration" env:"ETCD_MAX_WATCH_DURATION"`
+	Name              string   `toml:"name" env:"ETCD_NAME"`
+	Observer          bool     `toml:"observer" env:"ETCD_OBSERVER"`
+	SlowDiskAbdicate  bool     `toml:"slow_disk_abdicate" env:"ETCD_SLOW_DISK_ABDICATE"`
+	SlowDiskThreshold int      `toml:"slow_disk_threshold" env:"ETCD_SLOW_DISK_THRESHOLD"`
+	Snapshot          bool     `toml:"snapshot" env:"ETCD_SNAPSHOT"`
+	SnapshotBytes     int      `toml:"snapshot_bytes" env:"ETCD_SNAPSHOT_BYTES"`
+	SnapshotCount     int      `toml:"snapshot_count" env:"ETCD_SNAPSHOTCOUNT"`
+	Tags              []string `toml:"tags" env:"ETCD_TAGS"`
+	ReusePort         bool     `toml:"reuse_port" env:"ETCD_REUSE_PORT"`
+	TCPKeepAlive      int      `toml:"tcp_keepalive" env:"ETCD_TCP_KEEPALIVE"`
+	TCPNoDelay        bool     `toml:"tcp_nodelay" env:"ETCD_TCP_NODELAY"`
+	TombstoneIndexes  int      `toml:"tombstone_indexes" env:"ETCD_TOMBSTONE_INDEXES"`
+	TombstoneTTL      int      `toml:"tombstone_ttl" env:"ETCD_TOMBSTONE_TTL"`
+	TrustedProxies    []string `toml:"trusted_proxies" env:"ETCD_TRUSTED_PROXIES"`
+	TTLPrefixes       []string `toml:"ttl_prefixes" env:"ETCD_TTL_PREFIXES"`
+	UserMaxBytes      []string `toml:"user_max_bytes" env:"ETCD_USER_MAX_BYTES"`
+	UserMaxRate       []string `toml:"user_max_rate" env:"ETCD_USER_MAX_RATE"`
+	UserMaxWatches    []string `toml:"user_max_watches" env:"ETCD_USER_MAX_WATCHES"`
+	WriteRules        []string `toml:"write_rules" env:"ETCD_WRITE_RULES"`
+	ShowHelp          bool
+	ShowVersion       bool
+	Verbose           bool `toml:"verbose" env:"ETCD_VERBOSE"`
+	VeryVerbose       bool `toml:"very_verbose" env:"ETCD_VERY_VERBOSE"`
+	HeartbeatTimeout  int  `toml:"peer_heartbeat_timeout" env:"ETCD_PEER_HEARTBEAT_TIMEOUT"`
+	ElectionTimeout   int  `toml:"peer_election_timeout" env:"ETCD_PEER_ELECTION_TIMEOUT"`
+	ElectionWindow    int  `toml:"peer_election_window" env:"ETCD_PEER_ELECTION_WINDOW"`
+	Peer              struct {
+		Addr       string   `toml:"addr" env:"ETCD_PEER_ADDR"`
+		AllowCIDRs []string `toml:"allow_cidrs" env:"ETCD_PEER_ALLOW_CIDRS"`
+		BindAddr   string   `toml:"bind_addr" env:"ETCD_PEER_BIND_ADDR"`
+		CAFile     string   `toml:"ca_file" env:"ETCD_PEER_CA_FILE"`
+		CertFile   string   `toml:"cert_file" env:"ETCD_PEER_CERT_FILE"`
+		DenyCIDRs  []string `toml:"deny_cidrs" env:"ETCD_PEER_DENY_CIDRS"`
+		KeyFile    string   `toml:"key_file" env:"ETCD_PEER_KEY_FILE"`
+	}
+	DNS struct {
+		Addr   string `toml:"addr" env:"ETCD_DNS_ADDR"`
+		Domain string `toml:"domain" env:"ETCD_DNS_DOMAIN"`
+		Prefix string `toml:"prefix" env:"ETCD_DNS_PREFIX"`
 	}
 }
 
@@ -84,10 +133,16 @@ func NewConfig() *Config {
 	c.SystemPath = DefaultSystemConfigPath
 	c.Addr = "127.0.0.1:4001"
 	c.MaxClusterSize = 9
+	c.MaxObservers = 9
+	c.MaxKeyDepth = defaultMaxKeyDepth
+	c.MaxKeyNameLength = defaultMaxKeyNameLength
 	c.MaxResultBuffer = 1024
 	c.MaxRetryAttempts = 3
 	c.Peer.Addr = "127.0.0.1:7001"
+	c.DNS.Domain = "etcd."
+	c.DNS.Prefix = "/services"
 	c.SnapshotCount = 10000
+	c.TCPNoDelay = true
 	c.ElectionTimeout = 0
 	c.HeartbeatTimeout = 0
 	return c
@@ -164,6 +219,9 @@ func (c *Config) LoadEnv() error {
 	if err := c.loadEnv(&c.Peer); err != nil {
 		return err
 	}
+	if err := c.loadEnv(&c.DNS); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -200,7 +258,7 @@ func (c *Config) loadEnv(target interface{}) error {
 
 // Loads configuration from command line flags.
 func (c *Config) LoadFlags(arguments []string) error {
-	var peers, cors, path string
+	var peers, cors, proxies, adminCIDRs, adminNames, allowCIDRs, denyCIDRs, peerAllowCIDRs, peerDenyCIDRs, writeRules, userMaxBytes, userMaxRate, userMaxWatches, tags, ttlPrefixes, encryptPrefixes, foldCasePrefixes, path string
 
 	f := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
 	f.SetOutput(ioutil.Discard)
@@ -231,18 +289,65 @@ func (c *Config) LoadFlags(arguments []string) error {
 	f.StringVar(&c.Peer.CAFile, "peer-ca-file", c.Peer.CAFile, "")
 	f.StringVar(&c.Peer.CertFile, "peer-cert-file", c.Peer.CertFile, "")
 	f.StringVar(&c.Peer.KeyFile, "peer-key-file", c.Peer.KeyFile, "")
+	f.StringVar(&peerAllowCIDRs, "peer-allow-cidrs", "", "")
+	f.StringVar(&peerDenyCIDRs, "peer-deny-cidrs", "", "")
+
+	f.StringVar(&c.DNS.Addr, "dns-addr", c.DNS.Addr, "")
+	f.StringVar(&c.DNS.Domain, "dns-domain", c.DNS.Domain, "")
+	f.StringVar(&c.DNS.Prefix, "dns-prefix", c.DNS.Prefix, "")
 
 	f.StringVar(&c.DataDir, "data-dir", c.DataDir, "")
+	f.BoolVar(&c.Dev, "dev", c.Dev, "")
+	f.StringVar(&c.DevFixtures, "dev-fixtures", c.DevFixtures, "")
 	f.IntVar(&c.MaxResultBuffer, "max-result-buffer", c.MaxResultBuffer, "")
 	f.IntVar(&c.MaxRetryAttempts, "max-retry-attempts", c.MaxRetryAttempts, "")
 	f.IntVar(&c.MaxClusterSize, "max-cluster-size", c.MaxClusterSize, "")
+	f.IntVar(&c.MaxObservers, "max-observers", c.MaxObservers, "")
+	f.IntVar(&c.MaxBlocking, "max-blocking-requests", c.MaxBlocking, "")
+	f.IntVar(&c.MaxKeyDepth, "max-key-depth", c.MaxKeyDepth, "")
+	f.IntVar(&c.MaxKeyNameLength, "max-key-name-length", c.MaxKeyNameLength, "")
+	f.IntVar(&c.DefaultTTL, "default-ttl", c.DefaultTTL, "")
+	f.IntVar(&c.MaxTTL, "max-ttl", c.MaxTTL, "")
+	f.IntVar(&c.MaxWatchDuration, "max-watch-duration", c.MaxWatchDuration, "")
+	f.StringVar(&ttlPrefixes, "ttl-prefixes", "", "")
 	f.IntVar(&c.HeartbeatTimeout, "peer-heartbeat-timeout", c.HeartbeatTimeout, "")
 	f.IntVar(&c.ElectionTimeout, "peer-election-timeout", c.ElectionTimeout, "")
+	f.IntVar(&c.ElectionWindow, "peer-election-window", c.ElectionWindow, "")
+	f.IntVar(&c.BatchWindow, "batch-window", c.BatchWindow, "")
+	f.IntVar(&c.SlowDiskThreshold, "slow-disk-threshold", c.SlowDiskThreshold, "")
+	f.IntVar(&c.MaxClockSkew, "max-clock-skew", c.MaxClockSkew, "")
+	f.BoolVar(&c.SlowDiskAbdicate, "slow-disk-abdicate", c.SlowDiskAbdicate, "")
+	f.IntVar(&c.HashCheckInterval, "hash-check-interval", c.HashCheckInterval, "")
+	f.BoolVar(&c.ReusePort, "reuse-port", c.ReusePort, "")
+	f.IntVar(&c.TCPKeepAlive, "tcp-keepalive", c.TCPKeepAlive, "")
+	f.BoolVar(&c.TCPNoDelay, "tcp-nodelay", c.TCPNoDelay, "")
+	f.IntVar(&c.TombstoneIndexes, "tombstone-indexes", c.TombstoneIndexes, "")
+	f.IntVar(&c.TombstoneTTL, "tombstone-ttl", c.TombstoneTTL, "")
 
 	f.StringVar(&cors, "cors", "", "")
+	f.StringVar(&proxies, "trusted-proxies", "", "")
+	f.StringVar(&adminCIDRs, "admin-cidrs", "", "")
+	f.StringVar(&adminNames, "admin-names", "", "")
+	f.StringVar(&allowCIDRs, "allow-cidrs", "", "")
+	f.StringVar(&denyCIDRs, "deny-cidrs", "", "")
+	f.StringVar(&writeRules, "write-rules", "", "")
+	f.StringVar(&userMaxBytes, "user-max-bytes", "", "")
+	f.StringVar(&userMaxRate, "user-max-rate", "", "")
+	f.StringVar(&userMaxWatches, "user-max-watches", "", "")
+	f.StringVar(&encryptPrefixes, "encrypt-prefixes", "", "")
+	f.StringVar(&c.EncryptionKeyFile, "encryption-key-file", c.EncryptionKeyFile, "")
+	f.StringVar(&foldCasePrefixes, "fold-case-prefixes", "", "")
+	f.StringVar(&tags, "tags", "", "")
+	f.StringVar(&c.LeaderZone, "leader-zone", c.LeaderZone, "")
+	f.BoolVar(&c.Observer, "observer", c.Observer, "")
+	f.BoolVar(&c.AccessLog, "access-log", c.AccessLog, "")
+	f.StringVar(&c.LogSlowRequests, "log-slow-requests", c.LogSlowRequests, "")
+	f.IntVar(&c.DebugTTL, "debug-ttl", c.DebugTTL, "")
 
 	f.BoolVar(&c.Snapshot, "snapshot", c.Snapshot, "")
 	f.IntVar(&c.SnapshotCount, "snapshot-count", c.SnapshotCount, "")
+	f.IntVar(&c.SnapshotBytes, "snapshot-bytes", c.SnapshotBytes, "")
+	f.IntVar(&c.MaxLogBytes, "max-log-bytes", c.MaxLogBytes, "")
 	f.StringVar(&c.CPUProfileFile, "cpuprofile", "", "")
 
 	// BEGIN IGNORED FLAGS
@@ -288,6 +393,51 @@ func (c *Config) LoadFlags(arguments []string) error {
 	if cors != "" {
 		c.CorsOrigins = trimsplit(cors, ",")
 	}
+	if proxies != "" {
+		c.TrustedProxies = trimsplit(proxies, ",")
+	}
+	if adminCIDRs != "" {
+		c.AdminCIDRs = trimsplit(adminCIDRs, ",")
+	}
+	if adminNames != "" {
+		c.AdminNames = trimsplit(adminNames, ",")
+	}
+	if allowCIDRs != "" {
+		c.AllowCIDRs = trimsplit(allowCIDRs, ",")
+	}
+	if denyCIDRs != "" {
+		c.DenyCIDRs = trimsplit(denyCIDRs, ",")
+	}
+	if peerAllowCIDRs != "" {
+		c.Peer.AllowCIDRs = trimsplit(peerAllowCIDRs, ",")
+	}
+	if peerDenyCIDRs != "" {
+		c.Peer.DenyCIDRs = trimsplit(peerDenyCIDRs, ",")
+	}
+	if writeRules != "" {
+		c.WriteRules = trimsplit(writeRules, ",")
+	}
+	if userMaxBytes != "" {
+		c.UserMaxBytes = trimsplit(userMaxBytes, ",")
+	}
+	if userMaxRate != "" {
+		c.UserMaxRate = trimsplit(userMaxRate, ",")
+	}
+	if userMaxWatches != "" {
+		c.UserMaxWatches = trimsplit(userMaxWatches, ",")
+	}
+	if tags != "" {
+		c.Tags = trimsplit(tags, ",")
+	}
+	if ttlPrefixes != "" {
+		c.TTLPrefixes = trimsplit(ttlPrefixes, ",")
+	}
+	if encryptPrefixes != "" {
+		c.EncryptPrefixes = trimsplit(encryptPrefixes, ",")
+	}
+	if foldCasePrefixes != "" {
+		c.FoldCasePrefixes = trimsplit(foldCasePrefixes, ",")
+	}
 
 	return nil
 }
@@ -410,6 +560,12 @@ func (c *Config) Sanitize() error {
 		return fmt.Errorf("Peer Listen Host: %s", err)
 	}
 
+	if c.Dev {
+		if err := c.sanitizeDev(); err != nil {
+			return err
+		}
+	}
+
 	// Only guess the machine name if there is no data dir specified
 	// because the info file should have our name
 	if c.Name == "" && c.DataDir == "" {
@@ -423,6 +579,43 @@ func (c *Config) Sanitize() error {
 	return nil
 }
 
+// TagMap parses the member tags given as key=value pairs.
+func (c *Config) TagMap() (map[string]string, error) {
+	if len(c.Tags) == 0 {
+		return nil, nil
+	}
+	m := make(map[string]string)
+	for _, t := range c.Tags {
+		kv := strings.SplitN(t, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("Invalid tag: %s", t)
+		}
+		m[kv[0]] = kv[1]
+	}
+	return m, nil
+}
+
+// SlowRequestThreshold parses the latency above which requests are logged.
+func (c *Config) SlowRequestThreshold() (time.Duration, error) {
+	if c.LogSlowRequests == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(c.LogSlowRequests)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid slow request threshold: %s", c.LogSlowRequests)
+	}
+	return d, nil
+}
+
+// SocketOptions retrieves the socket options applied to both listeners.
+func (c *Config) SocketOptions() SocketOptions {
+	return SocketOptions{
+		ReusePort: c.ReusePort,
+		KeepAlive: time.Duration(c.TCPKeepAlive) * time.Second,
+		Delay:     !c.TCPNoDelay,
+	}
+}
+
 // TLSInfo retrieves a TLSInfo object for the client server.
 func (c *Config) TLSInfo() TLSInfo {
 	return TLSInfo{
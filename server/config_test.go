@@ -4,6 +4,7 @@ import (
 	"io/ioutil"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/stretchr/testify/assert"
@@ -163,6 +164,21 @@ func TestConfigAddrFlag(t *testing.T) {
 	assert.Equal(t, c.Addr, "127.0.0.1:4002", "")
 }
 
+// Ensures that the Batch Window can be parsed from the environment.
+func TestConfigBatchWindowEnv(t *testing.T) {
+	withEnv("ETCD_BATCH_WINDOW", "5", func(c *Config) {
+		assert.Nil(t, c.LoadEnv(), "")
+		assert.Equal(t, c.BatchWindow, 5, "")
+	})
+}
+
+// Ensures that a the Batch Window flag can be parsed.
+func TestConfigBatchWindowFlag(t *testing.T) {
+	c := NewConfig()
+	assert.Nil(t, c.LoadFlags([]string{"-batch-window", "5"}), "")
+	assert.Equal(t, c.BatchWindow, 5, "")
+}
+
 // Ensures that a the CA file can be parsed from the environment.
 func TestConfigCAFileEnv(t *testing.T) {
 	withEnv("ETCD_CA_FILE", "/tmp/file.ca", func(c *Config) {
@@ -261,6 +277,547 @@ func TestConfigPeersFlag(t *testing.T) {
 	assert.Equal(t, c.Peers, []string{"coreos.com:4001", "coreos.com:4002"}, "")
 }
 
+// Ensures that the Trusted Proxies can be parsed from the environment.
+func TestConfigTrustedProxiesEnv(t *testing.T) {
+	withEnv("ETCD_TRUSTED_PROXIES", "10.0.0.0/8,192.168.1.1", func(c *Config) {
+		assert.Nil(t, c.LoadEnv(), "")
+		assert.Equal(t, c.TrustedProxies, []string{"10.0.0.0/8", "192.168.1.1"}, "")
+	})
+}
+
+// Ensures that a the Trusted Proxies flag can be parsed.
+func TestConfigTrustedProxiesFlag(t *testing.T) {
+	c := NewConfig()
+	assert.Nil(t, c.LoadFlags([]string{"-trusted-proxies", "10.0.0.0/8,192.168.1.1"}), "")
+	assert.Equal(t, c.TrustedProxies, []string{"10.0.0.0/8", "192.168.1.1"}, "")
+}
+
+// Ensures that the Admin CIDRs can be parsed from the environment.
+func TestConfigAdminCIDRsEnv(t *testing.T) {
+	withEnv("ETCD_ADMIN_CIDRS", "10.0.0.0/8,192.168.1.1", func(c *Config) {
+		assert.Nil(t, c.LoadEnv(), "")
+		assert.Equal(t, c.AdminCIDRs, []string{"10.0.0.0/8", "192.168.1.1"}, "")
+	})
+}
+
+// Ensures that a the Admin CIDRs flag can be parsed.
+func TestConfigAdminCIDRsFlag(t *testing.T) {
+	c := NewConfig()
+	assert.Nil(t, c.LoadFlags([]string{"-admin-cidrs", "10.0.0.0/8,192.168.1.1"}), "")
+	assert.Equal(t, c.AdminCIDRs, []string{"10.0.0.0/8", "192.168.1.1"}, "")
+}
+
+// Ensures that the Allow CIDRs can be parsed from the environment.
+func TestConfigAllowCIDRsEnv(t *testing.T) {
+	withEnv("ETCD_ALLOW_CIDRS", "10.0.0.0/8,192.168.1.1", func(c *Config) {
+		assert.Nil(t, c.LoadEnv(), "")
+		assert.Equal(t, c.AllowCIDRs, []string{"10.0.0.0/8", "192.168.1.1"}, "")
+	})
+}
+
+// Ensures that a the Allow CIDRs flag can be parsed.
+func TestConfigAllowCIDRsFlag(t *testing.T) {
+	c := NewConfig()
+	assert.Nil(t, c.LoadFlags([]string{"-allow-cidrs", "10.0.0.0/8,192.168.1.1"}), "")
+	assert.Equal(t, c.AllowCIDRs, []string{"10.0.0.0/8", "192.168.1.1"}, "")
+}
+
+// Ensures that the Peer Deny CIDRs can be parsed from the environment.
+func TestConfigPeerDenyCIDRsEnv(t *testing.T) {
+	withEnv("ETCD_PEER_DENY_CIDRS", "10.0.0.0/8,192.168.1.1", func(c *Config) {
+		assert.Nil(t, c.LoadEnv(), "")
+		assert.Equal(t, c.Peer.DenyCIDRs, []string{"10.0.0.0/8", "192.168.1.1"}, "")
+	})
+}
+
+// Ensures that a the Peer Deny CIDRs flag can be parsed.
+func TestConfigPeerDenyCIDRsFlag(t *testing.T) {
+	c := NewConfig()
+	assert.Nil(t, c.LoadFlags([]string{"-peer-deny-cidrs", "10.0.0.0/8,192.168.1.1"}), "")
+	assert.Equal(t, c.Peer.DenyCIDRs, []string{"10.0.0.0/8", "192.168.1.1"}, "")
+}
+
+// Ensures that the Admin Names can be parsed from the environment.
+func TestConfigAdminNamesEnv(t *testing.T) {
+	withEnv("ETCD_ADMIN_NAMES", "ops,deploy", func(c *Config) {
+		assert.Nil(t, c.LoadEnv(), "")
+		assert.Equal(t, c.AdminNames, []string{"ops", "deploy"}, "")
+	})
+}
+
+// Ensures that a the Admin Names flag can be parsed.
+func TestConfigAdminNamesFlag(t *testing.T) {
+	c := NewConfig()
+	assert.Nil(t, c.LoadFlags([]string{"-admin-names", "ops,deploy"}), "")
+	assert.Equal(t, c.AdminNames, []string{"ops", "deploy"}, "")
+}
+
+// Ensures that the Write Rules can be parsed from the environment.
+func TestConfigWriteRulesEnv(t *testing.T) {
+	withEnv("ETCD_WRITE_RULES", "/services=web,/jobs=cron", func(c *Config) {
+		assert.Nil(t, c.LoadEnv(), "")
+		assert.Equal(t, c.WriteRules, []string{"/services=web", "/jobs=cron"}, "")
+	})
+}
+
+// Ensures that a the Write Rules flag can be parsed.
+func TestConfigWriteRulesFlag(t *testing.T) {
+	c := NewConfig()
+	assert.Nil(t, c.LoadFlags([]string{"-write-rules", "/services=web,/jobs=cron"}), "")
+	assert.Equal(t, c.WriteRules, []string{"/services=web", "/jobs=cron"}, "")
+}
+
+// Ensures that the user quotas can be parsed from the environment.
+func TestConfigUserQuotasEnv(t *testing.T) {
+	withEnv("ETCD_USER_MAX_RATE", "web=100,cron=10", func(c *Config) {
+		assert.Nil(t, c.LoadEnv(), "")
+		assert.Equal(t, c.UserMaxRate, []string{"web=100", "cron=10"}, "")
+	})
+}
+
+// Ensures that a the user quota flags can be parsed.
+func TestConfigUserQuotasFlag(t *testing.T) {
+	c := NewConfig()
+	assert.Nil(t, c.LoadFlags([]string{"-user-max-watches", "web=50", "-user-max-rate", "web=100", "-user-max-bytes", "web=1024"}), "")
+	assert.Equal(t, c.UserMaxWatches, []string{"web=50"}, "")
+	assert.Equal(t, c.UserMaxRate, []string{"web=100"}, "")
+	assert.Equal(t, c.UserMaxBytes, []string{"web=1024"}, "")
+}
+
+// Ensures that the Tags can be parsed from the environment.
+func TestConfigTagsEnv(t *testing.T) {
+	withEnv("ETCD_TAGS", "zone=us-east-1a,rack=r12", func(c *Config) {
+		assert.Nil(t, c.LoadEnv(), "")
+		assert.Equal(t, c.Tags, []string{"zone=us-east-1a", "rack=r12"}, "")
+	})
+}
+
+// Ensures that a the Tags flag can be parsed.
+func TestConfigTagsFlag(t *testing.T) {
+	c := NewConfig()
+	assert.Nil(t, c.LoadFlags([]string{"-tags", "zone=us-east-1a,rack=r12"}), "")
+	assert.Equal(t, c.Tags, []string{"zone=us-east-1a", "rack=r12"}, "")
+	m, err := c.TagMap()
+	assert.Nil(t, err, "")
+	assert.Equal(t, m, map[string]string{"zone": "us-east-1a", "rack": "r12"}, "")
+}
+
+// Ensures that malformed tags are rejected.
+func TestConfigTagsInvalid(t *testing.T) {
+	c := NewConfig()
+	c.Tags = []string{"zone"}
+	_, err := c.TagMap()
+	assert.NotNil(t, err, "")
+}
+
+// Ensures that the Leader Zone can be parsed from the environment.
+func TestConfigLeaderZoneEnv(t *testing.T) {
+	withEnv("ETCD_LEADER_ZONE", "us-east-1a", func(c *Config) {
+		assert.Nil(t, c.LoadEnv(), "")
+		assert.Equal(t, c.LeaderZone, "us-east-1a", "")
+	})
+}
+
+// Ensures that a the Leader Zone flag can be parsed.
+func TestConfigLeaderZoneFlag(t *testing.T) {
+	c := NewConfig()
+	assert.Nil(t, c.LoadFlags([]string{"-leader-zone", "us-east-1a"}), "")
+	assert.Equal(t, c.LeaderZone, "us-east-1a", "")
+}
+
+// Ensures that the Max Key Depth can be parsed from the environment.
+func TestConfigMaxKeyDepthEnv(t *testing.T) {
+	withEnv("ETCD_MAX_KEY_DEPTH", "8", func(c *Config) {
+		assert.Nil(t, c.LoadEnv(), "")
+		assert.Equal(t, c.MaxKeyDepth, 8, "")
+	})
+}
+
+// Ensures that a the Max Key Depth flag can be parsed.
+func TestConfigMaxKeyDepthFlag(t *testing.T) {
+	c := NewConfig()
+	assert.Nil(t, c.LoadFlags([]string{"-max-key-depth", "8"}), "")
+	assert.Equal(t, c.MaxKeyDepth, 8, "")
+}
+
+// Ensures that the Max Key Name Length can be parsed from the environment.
+func TestConfigMaxKeyNameLengthEnv(t *testing.T) {
+	withEnv("ETCD_MAX_KEY_NAME_LENGTH", "32", func(c *Config) {
+		assert.Nil(t, c.LoadEnv(), "")
+		assert.Equal(t, c.MaxKeyNameLength, 32, "")
+	})
+}
+
+// Ensures that a the Max Key Name Length flag can be parsed.
+func TestConfigMaxKeyNameLengthFlag(t *testing.T) {
+	c := NewConfig()
+	assert.Nil(t, c.LoadFlags([]string{"-max-key-name-length", "32"}), "")
+	assert.Equal(t, c.MaxKeyNameLength, 32, "")
+}
+
+// Ensures that the Default TTL can be parsed from the environment.
+func TestConfigDefaultTTLEnv(t *testing.T) {
+	withEnv("ETCD_DEFAULT_TTL", "60", func(c *Config) {
+		assert.Nil(t, c.LoadEnv(), "")
+		assert.Equal(t, c.DefaultTTL, 60, "")
+	})
+}
+
+// Ensures that a the Default TTL flag can be parsed.
+func TestConfigDefaultTTLFlag(t *testing.T) {
+	c := NewConfig()
+	assert.Nil(t, c.LoadFlags([]string{"-default-ttl", "60"}), "")
+	assert.Equal(t, c.DefaultTTL, 60, "")
+}
+
+// Ensures that the DNS options can be parsed from the environment.
+func TestConfigDNSEnv(t *testing.T) {
+	withEnv("ETCD_DNS_ADDR", "127.0.0.1:5353", func(_ *Config) {
+		withEnv("ETCD_DNS_DOMAIN", "example.com", func(c *Config) {
+			assert.Nil(t, c.LoadEnv(), "")
+			assert.Equal(t, c.DNS.Addr, "127.0.0.1:5353", "")
+			assert.Equal(t, c.DNS.Domain, "example.com", "")
+			assert.Equal(t, c.DNS.Prefix, "/services", "")
+		})
+	})
+}
+
+// Ensures that a the DNS flags can be parsed.
+func TestConfigDNSFlags(t *testing.T) {
+	c := NewConfig()
+	assert.Nil(t, c.LoadFlags([]string{"-dns-addr", "127.0.0.1:5353", "-dns-prefix", "/svc"}), "")
+	assert.Equal(t, c.DNS.Addr, "127.0.0.1:5353", "")
+	assert.Equal(t, c.DNS.Domain, "etcd.", "")
+	assert.Equal(t, c.DNS.Prefix, "/svc", "")
+}
+
+// Ensures that the debug TTL can be parsed from the environment.
+func TestConfigDebugTTLEnv(t *testing.T) {
+	withEnv("ETCD_DEBUG_TTL", "60", func(c *Config) {
+		assert.Nil(t, c.LoadEnv(), "")
+		assert.Equal(t, c.DebugTTL, 60, "")
+	})
+}
+
+// Ensures that a the debug TTL flag can be parsed.
+func TestConfigDebugTTLFlag(t *testing.T) {
+	c := NewConfig()
+	assert.Nil(t, c.LoadFlags([]string{"-debug-ttl", "60"}), "")
+	assert.Equal(t, c.DebugTTL, 60, "")
+}
+
+// Ensures that the Dev mode and fixtures can be parsed from the environment.
+func TestConfigDevEnv(t *testing.T) {
+	withEnv("ETCD_DEV", "true", func(c *Config) {
+		os.Setenv("ETCD_DEV_FIXTURES", "/tmp/fixtures.json")
+		defer os.Setenv("ETCD_DEV_FIXTURES", "")
+		assert.Nil(t, c.LoadEnv(), "")
+		assert.True(t, c.Dev, "")
+		assert.Equal(t, c.DevFixtures, "/tmp/fixtures.json", "")
+	})
+}
+
+// Ensures that a the Dev mode and fixtures flags can be parsed.
+func TestConfigDevFlag(t *testing.T) {
+	c := NewConfig()
+	assert.Nil(t, c.LoadFlags([]string{"-dev", "-dev-fixtures", "/tmp/fixtures.json"}), "")
+	assert.True(t, c.Dev, "")
+	assert.Equal(t, c.DevFixtures, "/tmp/fixtures.json", "")
+}
+
+// Ensures that a dev instance gets a fresh temporary data dir and no peers.
+func TestConfigSanitizeDev(t *testing.T) {
+	c := NewConfig()
+	c.DataDir = "/tmp/machine0"
+	c.Peers = []string{"127.0.0.1:7002"}
+	assert.Nil(t, c.sanitizeDev(), "")
+	defer os.RemoveAll(c.DataDir)
+	assert.Equal(t, c.Name, "dev", "")
+	assert.NotEqual(t, c.DataDir, "/tmp/machine0", "")
+	assert.Nil(t, c.Peers, "")
+	_, err := os.Stat(c.DataDir)
+	assert.Nil(t, err, "")
+}
+
+// Ensures that the Max TTL can be parsed from the environment.
+func TestConfigMaxTTLEnv(t *testing.T) {
+	withEnv("ETCD_MAX_TTL", "3600", func(c *Config) {
+		assert.Nil(t, c.LoadEnv(), "")
+		assert.Equal(t, c.MaxTTL, 3600, "")
+	})
+}
+
+// Ensures that a the Max TTL flag can be parsed.
+func TestConfigMaxTTLFlag(t *testing.T) {
+	c := NewConfig()
+	assert.Nil(t, c.LoadFlags([]string{"-max-ttl", "3600"}), "")
+	assert.Equal(t, c.MaxTTL, 3600, "")
+}
+
+// Ensures that the Max Watch Duration can be parsed from the environment.
+func TestConfigMaxWatchDurationEnv(t *testing.T) {
+	withEnv("ETCD_MAX_WATCH_DURATION", "240", func(c *Config) {
+		assert.Nil(t, c.LoadEnv(), "")
+		assert.Equal(t, c.MaxWatchDuration, 240, "")
+	})
+}
+
+// Ensures that a the Max Watch Duration flag can be parsed.
+func TestConfigMaxWatchDurationFlag(t *testing.T) {
+	c := NewConfig()
+	assert.Nil(t, c.LoadFlags([]string{"-max-watch-duration", "240"}), "")
+	assert.Equal(t, c.MaxWatchDuration, 240, "")
+}
+
+// Ensures that the TTL Prefixes can be parsed from the environment.
+func TestConfigTTLPrefixesEnv(t *testing.T) {
+	withEnv("ETCD_TTL_PREFIXES", "/ephemeral,/sessions", func(c *Config) {
+		assert.Nil(t, c.LoadEnv(), "")
+		assert.Equal(t, c.TTLPrefixes, []string{"/ephemeral", "/sessions"}, "")
+	})
+}
+
+// Ensures that a the TTL Prefixes flag can be parsed.
+func TestConfigTTLPrefixesFlag(t *testing.T) {
+	c := NewConfig()
+	assert.Nil(t, c.LoadFlags([]string{"-ttl-prefixes", "/ephemeral, /sessions"}), "")
+	assert.Equal(t, c.TTLPrefixes, []string{"/ephemeral", "/sessions"}, "")
+}
+
+// Ensures that the Encrypt Prefixes can be parsed from the environment.
+func TestConfigEncryptPrefixesEnv(t *testing.T) {
+	withEnv("ETCD_ENCRYPT_PREFIXES", "/secrets,/db/passwords", func(c *Config) {
+		assert.Nil(t, c.LoadEnv(), "")
+		assert.Equal(t, c.EncryptPrefixes, []string{"/secrets", "/db/passwords"}, "")
+	})
+}
+
+// Ensures that a the Encrypt Prefixes flag can be parsed.
+func TestConfigEncryptPrefixesFlag(t *testing.T) {
+	c := NewConfig()
+	assert.Nil(t, c.LoadFlags([]string{"-encrypt-prefixes", "/secrets, /db/passwords"}), "")
+	assert.Equal(t, c.EncryptPrefixes, []string{"/secrets", "/db/passwords"}, "")
+}
+
+// Ensures that the Fold Case Prefixes can be parsed from the environment.
+func TestConfigFoldCasePrefixesEnv(t *testing.T) {
+	withEnv("ETCD_FOLD_CASE_PREFIXES", "/hosts,/dns", func(c *Config) {
+		assert.Nil(t, c.LoadEnv(), "")
+		assert.Equal(t, c.FoldCasePrefixes, []string{"/hosts", "/dns"}, "")
+	})
+}
+
+// Ensures that a the Fold Case Prefixes flag can be parsed.
+func TestConfigFoldCasePrefixesFlag(t *testing.T) {
+	c := NewConfig()
+	assert.Nil(t, c.LoadFlags([]string{"-fold-case-prefixes", "/hosts, /dns"}), "")
+	assert.Equal(t, c.FoldCasePrefixes, []string{"/hosts", "/dns"}, "")
+}
+
+// Ensures that the Encryption Key File can be parsed from the environment.
+func TestConfigEncryptionKeyFileEnv(t *testing.T) {
+	withEnv("ETCD_ENCRYPTION_KEY_FILE", "/tmp/etcd.key", func(c *Config) {
+		assert.Nil(t, c.LoadEnv(), "")
+		assert.Equal(t, c.EncryptionKeyFile, "/tmp/etcd.key", "")
+	})
+}
+
+// Ensures that a the Encryption Key File flag can be parsed.
+func TestConfigEncryptionKeyFileFlag(t *testing.T) {
+	c := NewConfig()
+	assert.Nil(t, c.LoadFlags([]string{"-encryption-key-file", "/tmp/etcd.key"}), "")
+	assert.Equal(t, c.EncryptionKeyFile, "/tmp/etcd.key", "")
+}
+
+// Ensures that the Hash Check Interval can be parsed from the environment.
+func TestConfigHashCheckIntervalEnv(t *testing.T) {
+	withEnv("ETCD_HASH_CHECK_INTERVAL", "60", func(c *Config) {
+		assert.Nil(t, c.LoadEnv(), "")
+		assert.Equal(t, c.HashCheckInterval, 60, "")
+	})
+}
+
+// Ensures that a the Hash Check Interval flag can be parsed.
+func TestConfigHashCheckIntervalFlag(t *testing.T) {
+	c := NewConfig()
+	assert.Nil(t, c.LoadFlags([]string{"-hash-check-interval", "60"}), "")
+	assert.Equal(t, c.HashCheckInterval, 60, "")
+}
+
+// Ensures that the Observer option can be parsed from the environment.
+func TestConfigObserverEnv(t *testing.T) {
+	withEnv("ETCD_OBSERVER", "true", func(c *Config) {
+		assert.Nil(t, c.LoadEnv(), "")
+		assert.Equal(t, c.Observer, true, "")
+	})
+}
+
+// Ensures that a the Observer flag can be parsed.
+func TestConfigObserverFlag(t *testing.T) {
+	c := NewConfig()
+	assert.Nil(t, c.LoadFlags([]string{"-observer"}), "")
+	assert.Equal(t, c.Observer, true, "")
+}
+
+// Ensures that the Reuse Port option can be parsed from the environment.
+func TestConfigReusePortEnv(t *testing.T) {
+	withEnv("ETCD_REUSE_PORT", "true", func(c *Config) {
+		assert.Nil(t, c.LoadEnv(), "")
+		assert.Equal(t, c.ReusePort, true, "")
+	})
+}
+
+// Ensures that a the Reuse Port flag can be parsed.
+func TestConfigReusePortFlag(t *testing.T) {
+	c := NewConfig()
+	assert.Nil(t, c.LoadFlags([]string{"-reuse-port"}), "")
+	assert.Equal(t, c.ReusePort, true, "")
+}
+
+// Ensures that the TCP Keepalive period can be parsed from the environment.
+func TestConfigTCPKeepAliveEnv(t *testing.T) {
+	withEnv("ETCD_TCP_KEEPALIVE", "30", func(c *Config) {
+		assert.Nil(t, c.LoadEnv(), "")
+		assert.Equal(t, c.TCPKeepAlive, 30, "")
+	})
+}
+
+// Ensures that a the TCP Keepalive flag can be parsed.
+func TestConfigTCPKeepAliveFlag(t *testing.T) {
+	c := NewConfig()
+	assert.Nil(t, c.LoadFlags([]string{"-tcp-keepalive", "30"}), "")
+	assert.Equal(t, c.SocketOptions().KeepAlive, 30*time.Second, "")
+}
+
+// Ensures that TCP_NODELAY is on by default and can be turned off from the environment.
+func TestConfigTCPNoDelayEnv(t *testing.T) {
+	assert.Equal(t, NewConfig().TCPNoDelay, true, "")
+	withEnv("ETCD_TCP_NODELAY", "false", func(c *Config) {
+		assert.Nil(t, c.LoadEnv(), "")
+		assert.Equal(t, c.TCPNoDelay, false, "")
+	})
+}
+
+// Ensures that a the TCP NoDelay flag can be parsed.
+func TestConfigTCPNoDelayFlag(t *testing.T) {
+	c := NewConfig()
+	assert.Nil(t, c.LoadFlags([]string{"-tcp-nodelay=false"}), "")
+	assert.Equal(t, c.SocketOptions().Delay, true, "")
+}
+
+// Ensures that the Tombstone Indexes can be parsed from the environment.
+func TestConfigTombstoneIndexesEnv(t *testing.T) {
+	withEnv("ETCD_TOMBSTONE_INDEXES", "1000", func(c *Config) {
+		assert.Nil(t, c.LoadEnv(), "")
+		assert.Equal(t, c.TombstoneIndexes, 1000, "")
+	})
+}
+
+// Ensures that a the Tombstone Indexes flag can be parsed.
+func TestConfigTombstoneIndexesFlag(t *testing.T) {
+	c := NewConfig()
+	assert.Nil(t, c.LoadFlags([]string{"-tombstone-indexes", "1000"}), "")
+	assert.Equal(t, c.TombstoneIndexes, 1000, "")
+}
+
+// Ensures that the Tombstone TTL can be parsed from the environment.
+func TestConfigTombstoneTTLEnv(t *testing.T) {
+	withEnv("ETCD_TOMBSTONE_TTL", "3600", func(c *Config) {
+		assert.Nil(t, c.LoadEnv(), "")
+		assert.Equal(t, c.TombstoneTTL, 3600, "")
+	})
+}
+
+// Ensures that a the Tombstone TTL flag can be parsed.
+func TestConfigTombstoneTTLFlag(t *testing.T) {
+	c := NewConfig()
+	assert.Nil(t, c.LoadFlags([]string{"-tombstone-ttl", "3600"}), "")
+	assert.Equal(t, c.TombstoneTTL, 3600, "")
+}
+
+// Ensures that the Access Log can be parsed from the environment.
+func TestConfigAccessLogEnv(t *testing.T) {
+	withEnv("ETCD_ACCESS_LOG", "true", func(c *Config) {
+		assert.Nil(t, c.LoadEnv(), "")
+		assert.Equal(t, c.AccessLog, true, "")
+	})
+}
+
+// Ensures that a the Access Log flag can be parsed.
+func TestConfigAccessLogFlag(t *testing.T) {
+	c := NewConfig()
+	assert.Nil(t, c.LoadFlags([]string{"-access-log"}), "")
+	assert.Equal(t, c.AccessLog, true, "")
+}
+
+// Ensures that the Log Slow Requests can be parsed from the environment.
+func TestConfigLogSlowRequestsEnv(t *testing.T) {
+	withEnv("ETCD_LOG_SLOW_REQUESTS", "250ms", func(c *Config) {
+		assert.Nil(t, c.LoadEnv(), "")
+		d, err := c.SlowRequestThreshold()
+		assert.Nil(t, err, "")
+		assert.Equal(t, d, 250*time.Millisecond, "")
+	})
+}
+
+// Ensures that a the Log Slow Requests flag can be parsed.
+func TestConfigLogSlowRequestsFlag(t *testing.T) {
+	c := NewConfig()
+	assert.Nil(t, c.LoadFlags([]string{"-log-slow-requests", "1s"}), "")
+	d, err := c.SlowRequestThreshold()
+	assert.Nil(t, err, "")
+	assert.Equal(t, d, time.Second, "")
+
+	c.LogSlowRequests = "fast"
+	_, err = c.SlowRequestThreshold()
+	assert.Error(t, err)
+}
+
+// Ensures that the Max Blocking Requests can be parsed from the environment.
+func TestConfigMaxBlockingRequestsEnv(t *testing.T) {
+	withEnv("ETCD_MAX_BLOCKING_REQUESTS", "1000", func(c *Config) {
+		assert.Nil(t, c.LoadEnv(), "")
+		assert.Equal(t, c.MaxBlocking, 1000, "")
+	})
+}
+
+// Ensures that a the Max Blocking Requests flag can be parsed.
+func TestConfigMaxBlockingRequestsFlag(t *testing.T) {
+	c := NewConfig()
+	assert.Nil(t, c.LoadFlags([]string{"-max-blocking-requests", "1000"}), "")
+	assert.Equal(t, c.MaxBlocking, 1000, "")
+}
+
+// Ensures that the Snapshot Bytes can be parsed from the environment.
+func TestConfigSnapshotBytesEnv(t *testing.T) {
+	withEnv("ETCD_SNAPSHOT_BYTES", "1048576", func(c *Config) {
+		assert.Nil(t, c.LoadEnv(), "")
+		assert.Equal(t, c.SnapshotBytes, 1048576, "")
+	})
+}
+
+// Ensures that a the Snapshot Bytes flag can be parsed.
+func TestConfigSnapshotBytesFlag(t *testing.T) {
+	c := NewConfig()
+	assert.Nil(t, c.LoadFlags([]string{"-snapshot-bytes", "1048576"}), "")
+	assert.Equal(t, c.SnapshotBytes, 1048576, "")
+}
+
+// Ensures that the Max Log Bytes can be parsed from the environment.
+func TestConfigMaxLogBytesEnv(t *testing.T) {
+	withEnv("ETCD_MAX_LOG_BYTES", "1048576", func(c *Config) {
+		assert.Nil(t, c.LoadEnv(), "")
+		assert.Equal(t, c.MaxLogBytes, 1048576, "")
+	})
+}
+
+// Ensures that a the Max Log Bytes flag can be parsed.
+func TestConfigMaxLogBytesFlag(t *testing.T) {
+	c := NewConfig()
+	assert.Nil(t, c.LoadFlags([]string{"-max-log-bytes", "1048576"}), "")
+	assert.Equal(t, c.MaxLogBytes, 1048576, "")
+}
+
 // Ensures that the Peers File can be parsed from the environment.
 func TestConfigPeersFileEnv(t *testing.T) {
 	withEnv("ETCD_PEERS_FILE", "/tmp/peers", func(c *Config) {
@@ -291,6 +848,21 @@ func TestConfigMaxClusterSizeFlag(t *testing.T) {
 	assert.Equal(t, c.MaxClusterSize, 5, "")
 }
 
+// Ensures that the Max Observers can be parsed from the environment.
+func TestConfigMaxObserversEnv(t *testing.T) {
+	withEnv("ETCD_MAX_OBSERVERS", "3", func(c *Config) {
+		assert.Nil(t, c.LoadEnv(), "")
+		assert.Equal(t, c.MaxObservers, 3, "")
+	})
+}
+
+// Ensures that the Max Observers flag can be parsed.
+func TestConfigMaxObserversFlag(t *testing.T) {
+	c := NewConfig()
+	assert.Nil(t, c.LoadFlags([]string{"-max-observers", "3"}), "")
+	assert.Equal(t, c.MaxObservers, 3, "")
+}
+
 // Ensures that the Max Result Buffer can be parsed from the environment.
 func TestConfigMaxResultBufferEnv(t *testing.T) {
 	withEnv("ETCD_MAX_RESULT_BUFFER", "512", func(c *Config) {
@@ -354,6 +926,66 @@ func TestConfigDataDirGuess(t *testing.T) {
 	assert.Equal(t, c.DataDir, name+".etcd", "")
 }
 
+// Ensures that the Election Window can be parsed from the environment.
+func TestConfigElectionWindowEnv(t *testing.T) {
+	withEnv("ETCD_PEER_ELECTION_WINDOW", "50", func(c *Config) {
+		assert.Nil(t, c.LoadEnv(), "")
+		assert.Equal(t, c.ElectionWindow, 50, "")
+	})
+}
+
+// Ensures that a the Election Window flag can be parsed.
+func TestConfigElectionWindowFlag(t *testing.T) {
+	c := NewConfig()
+	assert.Nil(t, c.LoadFlags([]string{"-peer-election-window", "50"}), "")
+	assert.Equal(t, c.ElectionWindow, 50, "")
+}
+
+// Ensures that the Max Clock Skew can be parsed from the environment.
+func TestConfigMaxClockSkewEnv(t *testing.T) {
+	withEnv("ETCD_MAX_CLOCK_SKEW", "250", func(c *Config) {
+		assert.Nil(t, c.LoadEnv(), "")
+		assert.Equal(t, c.MaxClockSkew, 250, "")
+	})
+}
+
+// Ensures that a the Max Clock Skew flag can be parsed.
+func TestConfigMaxClockSkewFlag(t *testing.T) {
+	c := NewConfig()
+	assert.Nil(t, c.LoadFlags([]string{"-max-clock-skew", "250"}), "")
+	assert.Equal(t, c.MaxClockSkew, 250, "")
+}
+
+// Ensures that the Slow Disk Threshold can be parsed from the environment.
+func TestConfigSlowDiskThresholdEnv(t *testing.T) {
+	withEnv("ETCD_SLOW_DISK_THRESHOLD", "100", func(c *Config) {
+		assert.Nil(t, c.LoadEnv(), "")
+		assert.Equal(t, c.SlowDiskThreshold, 100, "")
+	})
+}
+
+// Ensures that a the Slow Disk Threshold flag can be parsed.
+func TestConfigSlowDiskThresholdFlag(t *testing.T) {
+	c := NewConfig()
+	assert.Nil(t, c.LoadFlags([]string{"-slow-disk-threshold", "100"}), "")
+	assert.Equal(t, c.SlowDiskThreshold, 100, "")
+}
+
+// Ensures that Slow Disk Abdicate can be parsed from the environment.
+func TestConfigSlowDiskAbdicateEnv(t *testing.T) {
+	withEnv("ETCD_SLOW_DISK_ABDICATE", "true", func(c *Config) {
+		assert.Nil(t, c.LoadEnv(), "")
+		assert.True(t, c.SlowDiskAbdicate, "")
+	})
+}
+
+// Ensures that a the Slow Disk Abdicate flag can be parsed.
+func TestConfigSlowDiskAbdicateFlag(t *testing.T) {
+	c := NewConfig()
+	assert.Nil(t, c.LoadFlags([]string{"-slow-disk-abdicate"}), "")
+	assert.True(t, c.SlowDiskAbdicate, "")
+}
+
 // Ensures that Snapshot can be parsed from the environment.
 func TestConfigSnapshotEnv(t *testing.T) {
 	withEnv("ETCD_SNAPSHOT", "1", func(c *Config) {
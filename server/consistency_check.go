@@ -0,0 +1,144 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/coreos/etcd/log"
+	"github.com/coreos/raft"
+)
+
+// The number of times a member that has not applied a checkpoint yet is asked
+// for its hash before it is skipped.
+const hashCheckAttempts = 3
+
+// consistencyStats counts the applied-state comparisons run by the leader.
+type consistencyStats struct {
+	mutex sync.Mutex
+
+	Checks     uint64            `json:"checks"`
+	Mismatches uint64            `json:"mismatches"`
+	LastIndex  uint64            `json:"lastIndex"`
+	LastCheck  time.Time         `json:"lastCheck"`
+	Hashes     map[string]string `json:"hashes"`
+	Diverged   []string          `json:"diverged"`
+}
+
+// record stores the hashes of the members at a checkpoint and returns the
+// members whose hash differs from the leader's.
+func (cs *consistencyStats) record(leader *hashCheckpoint, hashes map[string]string) []string {
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+
+	var diverged []string
+	for name, hash := range hashes {
+		if hash != leader.Hash {
+			diverged = append(diverged, name)
+		}
+	}
+	sort.Strings(diverged)
+
+	cs.Checks++
+	if len(diverged) > 0 {
+		cs.Mismatches++
+	}
+	cs.LastIndex = leader.Index
+	cs.LastCheck = time.Now()
+	cs.Hashes = hashes
+	cs.Diverged = diverged
+	return diverged
+}
+
+// JSON returns the stats encoded as JSON.
+func (cs *consistencyStats) JSON() []byte {
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+
+	b, _ := json.Marshal(cs)
+	return b
+}
+
+// monitorConsistency periodically compares the applied state of every
+// member while this node is the leader.
+func (s *PeerServer) monitorConsistency() {
+	for {
+		time.Sleep(s.HashCheckInterval)
+
+		if s.raftServer.State() != raft.Leader {
+			continue
+		}
+		if err := s.checkConsistency(); err != nil {
+			log.Warnf("[consistency] check failed: %v", err)
+		}
+	}
+}
+
+// checkConsistency commits a hash checkpoint and compares the hash of each
+// reachable member at that index with the leader's.
+func (s *PeerServer) checkConsistency() error {
+	result, err := s.raftServer.Do(&HashCommand{})
+	if err != nil {
+		return err
+	}
+	var leader hashCheckpoint
+	if err := json.Unmarshal(result.([]byte), &leader); err != nil {
+		return err
+	}
+
+	hashes := map[string]string{s.name: leader.Hash}
+	for name := range s.raftServer.Peers() {
+		checkpoint, err := s.peerHash(name, leader.Index)
+		if err != nil {
+			log.Debugf("[consistency] no hash from %s: %v", name, err)
+			continue
+		}
+		hashes[name] = checkpoint.Hash
+	}
+
+	if diverged := s.consistencyStats.record(&leader, hashes); len(diverged) > 0 {
+		for _, name := range diverged {
+			log.Warnf("[consistency] hash mismatch: index=%d name=%s hash=%s leader=%s hash=%s", leader.Index, name, hashes[name], s.name, leader.Hash)
+		}
+	}
+	return nil
+}
+
+// peerHash fetches the hash of a member at a checkpoint, giving a member
+// that lags behind a few heartbeats to apply it.
+func (s *PeerServer) peerHash(name string, index uint64) (*hashCheckpoint, error) {
+	peerURL, ok := s.registry.PeerURL(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown peer %s", name)
+	}
+
+	t := s.raftServer.Transporter().(*transporter)
+	for i := 0; ; i++ {
+		resp, req, err := t.Get(fmt.Sprintf("%s/hash?index=%d", peerURL, index))
+		if err != nil {
+			return nil, err
+		}
+		t.CancelWhenTimeout(req)
+
+		if resp.StatusCode == http.StatusOK {
+			var checkpoint hashCheckpoint
+			err = json.NewDecoder(resp.Body).Decode(&checkpoint)
+			resp.Body.Close()
+			return &checkpoint, err
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusNotFound || i+1 >= hashCheckAttempts {
+			return nil, fmt.Errorf("no hash at index %d: %s", index, resp.Status)
+		}
+		time.Sleep(s.HeartbeatTimeout)
+	}
+}
+
+// Retrieves the results of the last applied-state comparison.
+func (s *PeerServer) ConsistencyStats() []byte {
+	return s.consistencyStats.JSON()
+}
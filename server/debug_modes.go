@@ -0,0 +1,161 @@
+package server
+
+import (
+	"net/http"
+	"net/http/pprof"
+	"sync"
+	"time"
+
+	"github.com/coreos/etcd/log"
+	"github.com/coreos/raft"
+	"github.com/gorilla/mux"
+)
+
+// How long debug modes switched on at runtime stay on by default.
+const defaultDebugTTL = 10 * time.Minute
+
+// DebugConfig holds the debug modes switched on at runtime through the
+// admin config endpoint. They are switched off again once they expire, so
+// that a forgotten debug session does not keep slowing a member down.
+type DebugConfig struct {
+	// Serves the profiles of net/http/pprof under /debug/pprof/ to admin
+	// clients.
+	Pprof bool `json:"pprof"`
+
+	// Logs like -vv, including the Raft debug messages.
+	Verbose bool `json:"verbose"`
+
+	// Logs every request served, like -access-log.
+	AccessLog bool `json:"accessLog"`
+
+	// When the modes are switched off again, and the seconds left until then.
+	// A TTL given in a change sets how long the modes stay on.
+	Expiration *time.Time `json:"expiration,omitempty"`
+	TTL        int64      `json:"ttl,omitempty"`
+}
+
+func (c DebugConfig) enabled() bool {
+	return c.Pprof || c.Verbose || c.AccessLog
+}
+
+type debugModes struct {
+	mutex sync.Mutex
+	DebugConfig
+
+	// The log levels to go back to when verbose logging is switched off.
+	verbose   bool
+	raftLevel int
+
+	// Changes count up the generation so that a timer started before the
+	// last change does nothing.
+	timer      *time.Timer
+	generation uint64
+}
+
+func (d *debugModes) get() DebugConfig {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	c := d.DebugConfig
+	if c.Expiration != nil {
+		c.TTL = int64(c.Expiration.Sub(time.Now())/time.Second) + 1
+	}
+	return c
+}
+
+// set switches the debug modes on or off. The modes that are on are
+// switched off after ttl.
+func (d *debugModes) set(c DebugConfig, ttl time.Duration) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.setLocked(c, ttl)
+}
+
+func (d *debugModes) setLocked(c DebugConfig, ttl time.Duration) {
+	if c.Verbose != d.Verbose {
+		if c.Verbose {
+			d.verbose, d.raftLevel = log.Verbose, raft.LogLevel()
+			log.Verbose = true
+			raft.SetLogLevel(raft.Debug)
+		} else {
+			log.Verbose = d.verbose
+			raft.SetLogLevel(d.raftLevel)
+		}
+	}
+	d.Pprof, d.Verbose, d.AccessLog = c.Pprof, c.Verbose, c.AccessLog
+
+	d.generation++
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+	d.Expiration = nil
+	if d.enabled() {
+		expiration := time.Now().Add(ttl)
+		d.Expiration = &expiration
+		generation := d.generation
+		d.timer = time.AfterFunc(ttl, func() { d.expire(generation) })
+	}
+}
+
+// expire switches every debug mode off, unless they were changed since the
+// timer was started.
+func (d *debugModes) expire(generation uint64) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.generation != generation {
+		return
+	}
+	log.Infof("[config] debug modes expired")
+	d.setLocked(DebugConfig{}, 0)
+}
+
+func (d *debugModes) pprof() bool {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.Pprof
+}
+
+func (d *debugModes) accessLog() bool {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.AccessLog
+}
+
+// DebugConfig returns the debug modes switched on at runtime.
+func (s *Server) DebugConfig() DebugConfig {
+	return s.debug.get()
+}
+
+// SetDebugConfig switches the debug modes on or off. Modes that are on are
+// switched off after the TTL of the config, or DebugTTL when it has none.
+func (s *Server) SetDebugConfig(c DebugConfig) {
+	ttl := time.Duration(c.TTL) * time.Second
+	if ttl <= 0 {
+		ttl = s.DebugTTL
+	}
+	if ttl <= 0 {
+		ttl = defaultDebugTTL
+	}
+	s.debug.set(c, ttl)
+}
+
+// Serves the profiles of net/http/pprof while profiling is switched on.
+func (s *Server) PprofHandler(w http.ResponseWriter, req *http.Request) error {
+	if !s.debug.pprof() {
+		http.Error(w, "Profiling is off", http.StatusNotFound)
+		return nil
+	}
+	switch mux.Vars(req)["profile"] {
+	case "cmdline":
+		pprof.Cmdline(w, req)
+	case "profile":
+		pprof.Profile(w, req)
+	case "symbol":
+		pprof.Symbol(w, req)
+	case "trace":
+		pprof.Trace(w, req)
+	default:
+		pprof.Index(w, req)
+	}
+	return nil
+}
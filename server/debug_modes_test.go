@@ -0,0 +1,40 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/coreos/etcd/log"
+	"github.com/stretchr/testify/assert"
+)
+
+// Ensures that debug modes are switched off once they expire and that the
+// log level is restored.
+func TestDebugModesExpire(t *testing.T) {
+	var d debugModes
+	d.set(DebugConfig{Pprof: true, Verbose: true}, 20*time.Millisecond)
+	c := d.get()
+	assert.True(t, c.Pprof && c.Verbose && !c.AccessLog, "")
+	assert.NotNil(t, c.Expiration, "")
+	assert.Equal(t, c.TTL, int64(1), "")
+	assert.True(t, log.Verbose, "")
+
+	time.Sleep(50 * time.Millisecond)
+	c = d.get()
+	assert.False(t, c.enabled(), "")
+	assert.Nil(t, c.Expiration, "")
+	assert.False(t, log.Verbose, "")
+}
+
+// Ensures that a change restarts the expiration of the debug modes.
+func TestDebugModesChange(t *testing.T) {
+	var d debugModes
+	d.set(DebugConfig{AccessLog: true}, 20*time.Millisecond)
+	d.set(DebugConfig{AccessLog: true}, time.Minute)
+	time.Sleep(50 * time.Millisecond)
+	assert.True(t, d.accessLog(), "")
+
+	d.set(DebugConfig{}, time.Minute)
+	assert.False(t, d.accessLog(), "")
+	assert.Nil(t, d.get().Expiration, "")
+}
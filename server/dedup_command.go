@@ -0,0 +1,122 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"path"
+	"regexp"
+	"time"
+
+	etcdErr "github.com/coreos/etcd/error"
+	"github.com/coreos/etcd/store"
+	"github.com/coreos/raft"
+	"github.com/gorilla/mux"
+)
+
+// How long the result of a request is kept for its retries.
+const requestIDTTL = 5 * time.Minute
+
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+func init() {
+	raft.RegisterCommand(&DedupCommand{})
+}
+
+// The DedupCommand applies a command at most once for a client-supplied
+// request ID on a key. Retries of the same request get the result of the
+// first one.
+type DedupCommand struct {
+	Key        string          `json:"key"`
+	RequestID  string          `json:"requestId"`
+	Name       string          `json:"name"`
+	Data       json.RawMessage `json:"data"`
+	ExpireTime time.Time       `json:"expireTime"`
+}
+
+// dedupResult is the outcome of a request as the store keeps it.
+type dedupResult struct {
+	Event     *store.Event   `json:"event,omitempty"`
+	PrevValue string         `json:"prevValue,omitempty"`
+	Error     *etcdErr.Error `json:"error,omitempty"`
+}
+
+// Wraps a command on a key so that it is applied once for the request ID.
+func NewDedupCommand(key string, requestID string, command raft.Command) (*DedupCommand, error) {
+	var b bytes.Buffer
+	if encoder, ok := command.(raft.CommandEncoder); ok {
+		if err := encoder.Encode(&b); err != nil {
+			return nil, err
+		}
+	} else if err := json.NewEncoder(&b).Encode(command); err != nil {
+		return nil, err
+	}
+	return &DedupCommand{
+		Key:        key,
+		RequestID:  requestID,
+		Name:       command.CommandName(),
+		Data:       b.Bytes(),
+		ExpireTime: time.Now().Add(requestIDTTL),
+	}, nil
+}
+
+// The name of the dedup command in the log
+func (c *DedupCommand) CommandName() string {
+	return "etcd:dedup"
+}
+
+// Apply the wrapped command unless a result is already recorded for the
+// request ID, in which case that result is returned instead.
+func (c *DedupCommand) Apply(server raft.Server) (interface{}, error) {
+	s, _ := server.StateMachine().(store.Store)
+	// Request IDs are scoped by key; they cannot hold a slash.
+	id := path.Join(c.Key, c.RequestID)
+
+	if b, ok := s.AppliedRequest(id); ok {
+		var r dedupResult
+		if err := json.Unmarshal(b, &r); err == nil {
+			if r.Error != nil {
+				return nil, r.Error
+			}
+			r.Event.Node.PrevValue = r.PrevValue
+			return r.Event, nil
+		}
+	}
+
+	command, err := raft.NewCommand(c.Name, c.Data)
+	if err != nil {
+		return nil, err
+	}
+	value, err := command.Apply(server)
+
+	var r dedupResult
+	if e, ok := value.(*store.Event); ok && e != nil {
+		r.Event, r.PrevValue = e, e.Node.PrevValue
+	}
+	if e, ok := err.(*etcdErr.Error); ok {
+		r.Error = e
+	}
+	if r.Event != nil || r.Error != nil {
+		b, merr := json.Marshal(r)
+		if merr != nil {
+			return nil, merr
+		}
+		s.RecordRequest(id, b, c.ExpireTime)
+	}
+
+	return value, err
+}
+
+// withRequestID wraps a write in a DedupCommand when the client supplied a
+// request ID with it.
+func (s *Server) withRequestID(c raft.Command, req *http.Request) (raft.Command, error) {
+	id := req.FormValue("requestId")
+	if id == "" {
+		return c, nil
+	}
+	if !validRequestID.MatchString(id) {
+		return nil, etcdErr.NewError(etcdErr.EcodeInvalidRequestID, "Set", s.Store().Index())
+	}
+	key := path.Clean("/" + mux.Vars(req)["key"])
+	return NewDedupCommand(key, id, c)
+}
@@ -0,0 +1,131 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/coreos/etcd/store"
+	"github.com/coreos/raft"
+)
+
+// How long a dev instance may take to elect itself and start serving.
+const devStartTimeout = 10 * time.Second
+
+// sanitizeDev turns the configuration into the one of a dev instance: a
+// single member named "dev" without peers, whose data lives in a fresh
+// temporary directory that is removed on exit.
+func (c *Config) sanitizeDev() error {
+	if c.Name == "" {
+		c.Name = "dev"
+	}
+	dir, err := ioutil.TempDir("", "etcd-dev-")
+	if err != nil {
+		return fmt.Errorf("Dev data dir: %v", err)
+	}
+	c.DataDir = dir
+	c.Peers = nil
+	c.PeersFile = ""
+	c.Snapshot = false
+	return nil
+}
+
+// A fixture is a key set when a dev instance starts.
+type fixture struct {
+	key   string
+	dir   bool
+	value string
+}
+
+// parseFixtures reads the keys of a fixtures document. Objects are
+// directories and every other value is the value of a key: strings are
+// used as they are and numbers, booleans and lists as their JSON text.
+func parseFixtures(b []byte) ([]fixture, error) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("Invalid fixtures: %v", err)
+	}
+	var fixtures []fixture
+	if err := collectFixtures("/", doc, &fixtures); err != nil {
+		return nil, err
+	}
+	return fixtures, nil
+}
+
+func collectFixtures(dir string, doc map[string]json.RawMessage, fixtures *[]fixture) error {
+	names := make([]string, 0, len(doc))
+	for name := range doc {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		key := path.Join(dir, name)
+		raw := doc[name]
+
+		var children map[string]json.RawMessage
+		var s string
+		if string(raw) == "null" {
+			return fmt.Errorf("Invalid fixtures: %s has no value", key)
+		} else if err := json.Unmarshal(raw, &children); err == nil {
+			if len(children) == 0 {
+				*fixtures = append(*fixtures, fixture{key: key, dir: true})
+			} else if err := collectFixtures(key, children, fixtures); err != nil {
+				return err
+			}
+		} else if err := json.Unmarshal(raw, &s); err == nil {
+			*fixtures = append(*fixtures, fixture{key: key, value: s})
+		} else {
+			*fixtures = append(*fixtures, fixture{key: key, value: string(raw)})
+		}
+	}
+	return nil
+}
+
+// StartDev waits for a dev instance to elect itself and serve clients, then
+// sets the keys of the fixtures file, if any.
+func (s *Server) StartDev(fixturesFile string) error {
+	var fixtures []fixture
+	if fixturesFile != "" {
+		b, err := ioutil.ReadFile(fixturesFile)
+		if err != nil {
+			return fmt.Errorf("Fixtures file error: %s", err)
+		}
+		if fixtures, err = parseFixtures(b); err != nil {
+			return err
+		}
+	}
+
+	deadline := time.Now().Add(devStartTimeout)
+	for !s.devReady() {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("Dev instance not ready after %v", devStartTimeout)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	for _, f := range fixtures {
+		c := s.Store().CommandFactory().CreateSetCommand(f.key, f.dir, f.value, store.Permanent)
+		if _, err := s.Do(c); err != nil {
+			return fmt.Errorf("Fixture %s: %v", f.key, err)
+		}
+	}
+	return nil
+}
+
+// devReady checks whether the member leads and answers on its client URL.
+func (s *Server) devReady() bool {
+	if s.peerServer.raftServer.State() != raft.Leader {
+		return false
+	}
+	resp, err := http.Get(s.URL() + "/version")
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return resp.StatusCode == http.StatusOK
+}
@@ -0,0 +1,32 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Ensures that fixtures are read into keys and directories in key order.
+func TestParseFixtures(t *testing.T) {
+	fixtures, err := parseFixtures([]byte(`{
+		"queue": {},
+		"config": {"workers": 4, "db": "postgres://localhost", "debug": false},
+		"hosts": ["a", "b"]
+	}`))
+	assert.Nil(t, err, "")
+	assert.Equal(t, fixtures, []fixture{
+		{key: "/config/db", value: "postgres://localhost"},
+		{key: "/config/debug", value: "false"},
+		{key: "/config/workers", value: "4"},
+		{key: "/hosts", value: `["a", "b"]`},
+		{key: "/queue", dir: true},
+	}, "")
+}
+
+// Ensures that invalid fixtures are refused.
+func TestParseFixturesInvalid(t *testing.T) {
+	_, err := parseFixtures([]byte(`["foo"]`))
+	assert.Error(t, err, "")
+	_, err = parseFixtures([]byte(`{"foo": null}`))
+	assert.Error(t, err, "")
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+const (
+	// The default latency above which a raft log sync is counted as slow.
+	defaultSlowDiskThreshold = 500 * time.Millisecond
+
+	// Snapshots write the whole store so they get a far higher threshold.
+	slowSnapshotThreshold = 10 * time.Second
+
+	// The number of consecutive slow (or fast) syncs before the disk is
+	// marked as degraded (or healthy again).
+	slowDiskSamples = 3
+)
+
+// diskStats tracks the write latency of the data directory.
+type diskStats struct {
+	mutex sync.Mutex
+
+	Threshold       float64 `json:"threshold"`
+	FsyncLatency    float64 `json:"fsyncLatency"`
+	MaxFsyncLatency float64 `json:"maxFsyncLatency"`
+	SlowFsyncs      uint64  `json:"slowFsyncs"`
+	SnapshotLatency float64 `json:"snapshotLatency"`
+	SlowSnapshots   uint64  `json:"slowSnapshots"`
+	Degraded        bool    `json:"degraded"`
+
+	slow int
+	fast int
+}
+
+func newDiskStats(threshold time.Duration) *diskStats {
+	return &diskStats{Threshold: milliseconds(threshold)}
+}
+
+// recordFsync adds a sync latency and returns true if the degraded flag changed.
+func (ds *diskStats) recordFsync(d time.Duration) bool {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	ds.FsyncLatency = milliseconds(d)
+	if ds.FsyncLatency > ds.MaxFsyncLatency {
+		ds.MaxFsyncLatency = ds.FsyncLatency
+	}
+
+	if ds.FsyncLatency > ds.Threshold {
+		ds.SlowFsyncs++
+		ds.slow, ds.fast = ds.slow+1, 0
+	} else {
+		ds.slow, ds.fast = 0, ds.fast+1
+	}
+
+	if !ds.Degraded && ds.slow >= slowDiskSamples {
+		ds.Degraded = true
+		return true
+	} else if ds.Degraded && ds.fast >= slowDiskSamples {
+		ds.Degraded = false
+		return true
+	}
+	return false
+}
+
+// recordSnapshot adds a snapshot latency and returns true if it was slow.
+func (ds *diskStats) recordSnapshot(d time.Duration) bool {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	ds.SnapshotLatency = milliseconds(d)
+	if d > slowSnapshotThreshold {
+		ds.SlowSnapshots++
+		return true
+	}
+	return false
+}
+
+// IsDegraded returns true if the disk has been slow for several syncs in a row.
+func (ds *diskStats) IsDegraded() bool {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+	return ds.Degraded
+}
+
+// JSON returns the stats encoded as JSON.
+func (ds *diskStats) JSON() []byte {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	b, _ := json.Marshal(ds)
+	return b
+}
+
+// milliseconds converts a duration to fractional milliseconds.
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
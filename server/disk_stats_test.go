@@ -0,0 +1,38 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Ensures that a disk is only degraded after several slow syncs in a row.
+func TestDiskStatsDegraded(t *testing.T) {
+	ds := newDiskStats(100 * time.Millisecond)
+	assert.False(t, ds.recordFsync(200*time.Millisecond), "")
+	assert.False(t, ds.recordFsync(200*time.Millisecond), "")
+	assert.False(t, ds.recordFsync(10*time.Millisecond), "")
+	assert.False(t, ds.IsDegraded(), "")
+
+	assert.False(t, ds.recordFsync(200*time.Millisecond), "")
+	assert.False(t, ds.recordFsync(200*time.Millisecond), "")
+	assert.True(t, ds.recordFsync(200*time.Millisecond), "")
+	assert.True(t, ds.IsDegraded(), "")
+	assert.Equal(t, ds.SlowFsyncs, uint64(5), "")
+	assert.Equal(t, ds.MaxFsyncLatency, float64(200), "")
+}
+
+// Ensures that a degraded disk recovers after several fast syncs in a row.
+func TestDiskStatsRecovered(t *testing.T) {
+	ds := newDiskStats(100 * time.Millisecond)
+	for i := 0; i < slowDiskSamples; i++ {
+		ds.recordFsync(200 * time.Millisecond)
+	}
+	assert.True(t, ds.IsDegraded(), "")
+
+	assert.False(t, ds.recordFsync(10*time.Millisecond), "")
+	assert.False(t, ds.recordFsync(10*time.Millisecond), "")
+	assert.True(t, ds.recordFsync(10*time.Millisecond), "")
+	assert.False(t, ds.IsDegraded(), "")
+}
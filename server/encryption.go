@@ -0,0 +1,228 @@
+package server
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/coreos/etcd/log"
+	"github.com/coreos/etcd/store"
+	"github.com/gorilla/mux"
+)
+
+// Marks the stored values that are encrypted, so that values written before
+// a prefix became sensitive are still served as they are.
+const encryptedValuePrefix = "etcd-aes-gcm:"
+
+// ValueCipher encrypts the values stored under the sensitive prefixes. A
+// cipher backed by a key management service can be used instead of a key
+// file by implementing it.
+type ValueCipher interface {
+	Encrypt(plaintext []byte) ([]byte, error)
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
+
+// aesCipher seals values with AES-GCM under a random nonce.
+type aesCipher struct {
+	aead cipher.AEAD
+}
+
+// NewKeyFileCipher creates an AES-GCM cipher from a key file holding a 16,
+// 24 or 32 byte key, either raw or hex encoded.
+func NewKeyFileCipher(path string) (ValueCipher, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key := b
+	if k, err := hex.DecodeString(strings.TrimSpace(string(b))); err == nil && validKeySize(len(k)) {
+		key = k
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid encryption key: %s", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &aesCipher{aead: aead}, nil
+}
+
+func validKeySize(n int) bool {
+	return n == 16 || n == 24 || n == 32
+}
+
+func (c *aesCipher) Encrypt(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return c.aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func (c *aesCipher) Decrypt(ciphertext []byte) ([]byte, error) {
+	n := c.aead.NonceSize()
+	if len(ciphertext) < n {
+		return nil, errors.New("ciphertext too short")
+	}
+	return c.aead.Open(nil, ciphertext[:n], ciphertext[n:], nil)
+}
+
+// EncryptValues makes the values written under the given prefixes encrypted
+// with the cipher in the log, the snapshots and the store. They are only
+// decrypted for the clients allowed to write them.
+func (s *Server) EncryptValues(prefixes []string, c ValueCipher) error {
+	for _, p := range prefixes {
+		if !strings.HasPrefix(p, "/") {
+			return fmt.Errorf("Invalid encrypted prefix: %s", p)
+		}
+	}
+	s.encryptPrefixes = prefixes
+	s.valueCipher = c
+	return nil
+}
+
+// sensitive determines whether the values of a key are encrypted.
+func (s *Server) sensitive(key string) bool {
+	for _, p := range s.encryptPrefixes {
+		if hasKeyPrefix(key, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) encryptValue(value string) (string, error) {
+	b, err := s.valueCipher.Encrypt([]byte(value))
+	if err != nil {
+		return "", err
+	}
+	return encryptedValuePrefix + base64.StdEncoding.EncodeToString(b), nil
+}
+
+func (s *Server) decryptValue(value string) (string, error) {
+	if !strings.HasPrefix(value, encryptedValuePrefix) {
+		return value, nil
+	}
+	b, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, encryptedValuePrefix))
+	if err != nil {
+		return "", err
+	}
+	if b, err = s.valueCipher.Decrypt(b); err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// Encrypts the values written under a sensitive prefix before they reach
+// the handler so that every write command carries the encrypted value.
+// A prevValue is matched against the decrypted value and replaced by the
+// stored one, which the compare-and-swap then checks atomically.
+func (s *Server) encryptValues(f func(http.ResponseWriter, *http.Request) error) func(http.ResponseWriter, *http.Request) error {
+	return func(w http.ResponseWriter, req *http.Request) error {
+		key := "/" + mux.Vars(req)["key"]
+		if req.Method == "GET" || len(s.encryptPrefixes) == 0 {
+			return f(w, req)
+		}
+		if err := s.recodeMove(req, key); err != nil {
+			return err
+		}
+		if !s.sensitive(key) {
+			return f(w, req)
+		}
+		req.ParseForm()
+
+		if value := req.Form.Get("value"); value != "" {
+			encrypted, err := s.encryptValue(value)
+			if err != nil {
+				return err
+			}
+			setFormValue(req, "value", encrypted)
+		}
+
+		if prevValue := req.Form.Get("prevValue"); prevValue != "" {
+			if e, err := s.store.Get(key, false, false); err == nil && !e.Node.Dir {
+				if v, err := s.decryptValue(e.Node.Value); err == nil && v == prevValue {
+					setFormValue(req, "prevValue", e.Node.Value)
+				}
+			}
+		}
+		return f(w, req)
+	}
+}
+
+// A value moved in or out of a sensitive prefix is stored as it would be
+// written to the key it is moved to. The move is made to carry the value,
+// decrypted here and encrypted again by encryptValues when needed, and to
+// only happen while the key it comes from is still at the index read.
+func (s *Server) recodeMove(req *http.Request, key string) error {
+	moveFrom := moveFromKey(req)
+	if moveFrom == "" || req.Form.Get("moveFromIndex") != "" {
+		return nil
+	}
+	moveFrom = path.Clean(path.Join("/", moveFrom))
+	if s.sensitive(moveFrom) == s.sensitive(key) {
+		return nil
+	}
+	e, err := s.store.Get(moveFrom, false, false)
+	if err != nil || e.Node.Dir {
+		// The move fails the same way.
+		return nil
+	}
+	value, err := s.decryptValue(e.Node.Value)
+	if err != nil {
+		return err
+	}
+	setFormValue(req, "value", value)
+	setFormValue(req, "moveFromIndex", strconv.FormatUint(e.Node.ModifiedIndex, 10))
+	return nil
+}
+
+func setFormValue(req *http.Request, name string, value string) {
+	req.Form.Set(name, value)
+	if _, ok := req.PostForm[name]; ok {
+		req.PostForm.Set(name, value)
+	}
+}
+
+// RevealEvent returns the event with the values under the sensitive prefixes
+// decrypted when the client may write them. The event itself is left as it
+// is since it can be shared with other watchers.
+func (s *Server) RevealEvent(req *http.Request, e *store.Event) *store.Event {
+	if e == nil || e.Node == nil || len(s.encryptPrefixes) == 0 {
+		return e
+	}
+	return &store.Event{Action: e.Action, Node: s.revealNode(req, e.Node)}
+}
+
+func (s *Server) revealNode(req *http.Request, n *store.NodeExtern) *store.NodeExtern {
+	c := *n
+	if s.sensitive(n.Key) && s.WriteAllowed(req, n.Key) {
+		var err error
+		if c.Value, err = s.decryptValue(n.Value); err != nil {
+			log.Warnf("[encryption] cannot decrypt %s: %v", n.Key, err)
+			c.Value = n.Value
+		}
+		if c.PrevValue, err = s.decryptValue(n.PrevValue); err != nil {
+			c.PrevValue = n.PrevValue
+		}
+	}
+	if n.Nodes != nil {
+		c.Nodes = make(store.NodeExterns, len(n.Nodes))
+		for i := range n.Nodes {
+			c.Nodes[i] = *s.revealNode(req, &n.Nodes[i])
+		}
+	}
+	return &c
+}
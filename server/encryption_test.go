@@ -0,0 +1,65 @@
+package server
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestCipher(t *testing.T, key string) ValueCipher {
+	f, _ := ioutil.TempFile("", "etcd-key")
+	defer os.Remove(f.Name())
+	f.WriteString(key)
+	f.Close()
+	c, err := NewKeyFileCipher(f.Name())
+	assert.Nil(t, err, "")
+	return c
+}
+
+// Ensures that values are encrypted under a random nonce and decrypted back.
+func TestEncryptValue(t *testing.T) {
+	s := &Server{}
+	s.EncryptValues([]string{"/secrets"}, newTestCipher(t, "0123456789abcdef"))
+
+	a, err := s.encryptValue("hunter2")
+	assert.Nil(t, err, "")
+	b, _ := s.encryptValue("hunter2")
+	assert.NotEqual(t, a, b, "")
+
+	v, err := s.decryptValue(a)
+	assert.Nil(t, err, "")
+	assert.Equal(t, v, "hunter2", "")
+
+	// Values written before the prefix was encrypted are left as they are.
+	v, err = s.decryptValue("plain")
+	assert.Nil(t, err, "")
+	assert.Equal(t, v, "plain", "")
+
+	// A value encrypted under another key is rejected.
+	other := &Server{}
+	other.EncryptValues([]string{"/secrets"}, newTestCipher(t, "fedcba9876543210fedcba9876543210"))
+	_, err = other.decryptValue(a)
+	assert.NotNil(t, err, "")
+}
+
+// Ensures that only the keys under the encrypted prefixes are sensitive.
+func TestEncryptSensitive(t *testing.T) {
+	s := &Server{}
+	assert.NotNil(t, s.EncryptValues([]string{"secrets"}, nil), "")
+	s.EncryptValues([]string{"/secrets"}, nil)
+	assert.True(t, s.sensitive("/secrets"), "")
+	assert.True(t, s.sensitive("/secrets/db"), "")
+	assert.False(t, s.sensitive("/secretsdb"), "")
+}
+
+// Ensures that a key file without a valid AES key is rejected.
+func TestNewKeyFileCipherInvalid(t *testing.T) {
+	f, _ := ioutil.TempFile("", "etcd-key")
+	defer os.Remove(f.Name())
+	f.WriteString("short")
+	f.Close()
+	_, err := NewKeyFileCipher(f.Name())
+	assert.NotNil(t, err, "")
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/coreos/etcd/store"
+	"github.com/gorilla/mux"
+)
+
+// FoldKeyCase makes the keys under the given prefixes case-insensitive.
+// They are stored in lower case and every key a client sends under them is
+// lowered before it is looked up, written or watched.
+func (s *Server) FoldKeyCase(prefixes []string) error {
+	folded := make([]string, 0, len(prefixes))
+	for _, p := range prefixes {
+		if !strings.HasPrefix(p, "/") {
+			return fmt.Errorf("Invalid fold case prefix: %s", p)
+		}
+		folded = append(folded, path.Clean(strings.ToLower(p)))
+	}
+	s.foldCasePrefixes = folded
+	return nil
+}
+
+// foldCase returns the canonical form of a key.
+func (s *Server) foldCase(key string) string {
+	lower := strings.ToLower(key)
+	for _, p := range s.foldCasePrefixes {
+		if hasKeyPrefix(lower, p) {
+			return lower
+		}
+	}
+	return key
+}
+
+// Replaces the key of a request, and the key a value is moved from, with
+// their canonical form.
+func (s *Server) foldKeys(f func(http.ResponseWriter, *http.Request) error) func(http.ResponseWriter, *http.Request) error {
+	return func(w http.ResponseWriter, req *http.Request) error {
+		if len(s.foldCasePrefixes) == 0 {
+			return f(w, req)
+		}
+		vars := mux.Vars(req)
+		if key, ok := vars["key"]; ok {
+			vars["key"] = strings.TrimPrefix(s.foldCase("/"+key), "/")
+		}
+		if req.Method == "POST" {
+			req.ParseForm()
+			if moveFrom := req.Form.Get("moveFrom"); moveFrom != "" {
+				setFormValue(req, "moveFrom", s.foldCase(moveFrom))
+			}
+		}
+		return f(w, req)
+	}
+}
+
+// CheckFoldedKeys makes sure that the keys under the case-insensitive
+// prefixes are already in lower case. Keys that are not could no longer be
+// reached, or would clash with another key once folded.
+func (s *Server) CheckFoldedKeys() error {
+	for _, p := range s.foldCasePrefixes {
+		// The prefix itself may have been written in another case.
+		e, err := s.store.Get(path.Dir(p), false, false)
+		if err != nil {
+			continue
+		}
+		for _, n := range e.Node.Nodes {
+			if !hasKeyPrefix(strings.ToLower(n.Key), p) {
+				continue
+			}
+			e, err := s.store.Get(n.Key, true, false)
+			if err != nil {
+				continue
+			}
+			if key := unfoldedKey(e.Node); key != "" {
+				return fmt.Errorf("Cannot match the keys under %s case-insensitively: %s is not in lower case", p, key)
+			}
+		}
+	}
+	return nil
+}
+
+// unfoldedKey returns the first key of a tree that is not in lower case.
+func unfoldedKey(n *store.NodeExtern) string {
+	if n.Key != strings.ToLower(n.Key) {
+		return n.Key
+	}
+	for i := range n.Nodes {
+		if key := unfoldedKey(&n.Nodes[i]); key != "" {
+			return key
+		}
+	}
+	return ""
+}
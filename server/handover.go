@@ -0,0 +1,234 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/coreos/etcd/log"
+)
+
+// A handover replaces a running etcd with a new binary without refusing
+// connections. The old process starts the new one with its listening
+// sockets as inherited descriptors, along with two pipes. The new process
+// writes to the first pipe once it has loaded its configuration and then
+// waits until the second one is closed, which the old process does after
+// giving up leadership and closing its log. Clients connecting in the
+// meantime queue on the shared sockets.
+const (
+	// The inherited listeners, as comma separated "addr=fd" pairs.
+	handoverListenersEnv = "ETCD_HANDOVER_LISTENERS"
+
+	// The descriptors of the ready and release pipes, as "ready,release".
+	handoverPipesEnv = "ETCD_HANDOVER_PIPES"
+)
+
+// How long the old process waits for the new one to be ready.
+const handoverReadyTimeout = 30 * time.Second
+
+// The raw listeners opened by this process, by the address they were
+// opened for.
+var handoverListeners = struct {
+	sync.Mutex
+	m map[string]*net.TCPListener
+}{m: make(map[string]*net.TCPListener)}
+
+// NotifyHandover relays the signal that asks for a handover to c.
+func NotifyHandover(c chan<- os.Signal) {
+	signal.Notify(c, syscall.SIGUSR2)
+}
+
+// registerListener remembers a listener so that it can be handed over.
+func registerListener(addr string, l net.Listener) {
+	if tl, ok := l.(*net.TCPListener); ok {
+		handoverListeners.Lock()
+		handoverListeners.m[addr] = tl
+		handoverListeners.Unlock()
+	}
+}
+
+// inheritedListener returns the listener for addr handed over by the
+// previous process, or nil if there is none. A listener is only returned
+// once.
+func inheritedListener(addr string) (net.Listener, error) {
+	s := os.Getenv(handoverListenersEnv)
+	if s == "" {
+		return nil, nil
+	}
+	fds, err := parseHandoverListeners(s)
+	if err != nil {
+		return nil, err
+	}
+	fd, ok := fds[addr]
+	if !ok {
+		return nil, nil
+	}
+
+	delete(fds, addr)
+	var rest []string
+	for a, fd := range fds {
+		rest = append(rest, fmt.Sprintf("%s=%d", a, fd))
+	}
+	if len(rest) > 0 {
+		os.Setenv(handoverListenersEnv, strings.Join(rest, ","))
+	} else {
+		os.Unsetenv(handoverListenersEnv)
+	}
+
+	f := os.NewFile(fd, "listener:"+addr)
+	defer f.Close()
+	l, err := net.FileListener(f)
+	if err != nil {
+		return nil, fmt.Errorf("inherited listener %s: %v", addr, err)
+	}
+	log.Infof("[handover] inherited listener: addr=%s", addr)
+	return l, nil
+}
+
+// parseHandoverListeners parses the "addr=fd" pairs of the inherited
+// listeners.
+func parseHandoverListeners(s string) (map[string]uintptr, error) {
+	fds := make(map[string]uintptr)
+	for _, pair := range strings.Split(s, ",") {
+		i := strings.LastIndex(pair, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid %s: %q", handoverListenersEnv, s)
+		}
+		fd, err := strconv.ParseUint(pair[i+1:], 10, 32)
+		if err != nil || fd < 3 {
+			return nil, fmt.Errorf("invalid %s: %q", handoverListenersEnv, s)
+		}
+		fds[pair[:i]] = uintptr(fd)
+	}
+	return fds, nil
+}
+
+// WaitForHandover blocks a process started by a handover until the previous
+// process has released the data directory. It returns right away for a
+// process that was started normally.
+func WaitForHandover() error {
+	s := os.Getenv(handoverPipesEnv)
+	if s == "" {
+		return nil
+	}
+	os.Unsetenv(handoverPipesEnv)
+
+	var readyFd, releaseFd uintptr
+	if _, err := fmt.Sscanf(s, "%d,%d", &readyFd, &releaseFd); err != nil {
+		return fmt.Errorf("invalid %s: %q", handoverPipesEnv, s)
+	}
+	ready := os.NewFile(readyFd, "handover-ready")
+	release := os.NewFile(releaseFd, "handover-release")
+	defer release.Close()
+
+	_, err := ready.Write([]byte("ready\n"))
+	ready.Close()
+	if err != nil {
+		return fmt.Errorf("handover: %v", err)
+	}
+
+	// The pipe is closed once the previous process is done, or has exited.
+	log.Infof("[handover] waiting for the previous process to release the data dir")
+	io.Copy(ioutil.Discard, release)
+	return nil
+}
+
+// Handover starts a new process from the current executable and arguments
+// and hands the listeners over to it. Once the new process is ready, release
+// is called to shut this one down short of exiting. The new process is
+// killed if it does not get ready, and this one keeps running.
+func Handover(release func()) error {
+	handoverListeners.Lock()
+	defer handoverListeners.Unlock()
+	if len(handoverListeners.m) == 0 {
+		return errors.New("no listeners to hand over")
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	readyR, readyW, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer readyR.Close()
+	releaseR, releaseW, err := os.Pipe()
+	if err != nil {
+		readyW.Close()
+		return err
+	}
+	defer releaseW.Close()
+
+	// The child's descriptors start at 3, in the order of ExtraFiles.
+	files := []*os.File{readyW, releaseR}
+	defer func() {
+		for _, f := range files {
+			f.Close()
+		}
+	}()
+	var pairs []string
+	for addr, l := range handoverListeners.m {
+		f, err := l.File()
+		if err != nil {
+			return fmt.Errorf("listener %s: %v", addr, err)
+		}
+		pairs = append(pairs, fmt.Sprintf("%s=%d", addr, 3+len(files)))
+		files = append(files, f)
+	}
+
+	cmd := exec.Command(exe, os.Args[1:]...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.ExtraFiles = files
+	cmd.Env = append(os.Environ(),
+		handoverListenersEnv+"="+strings.Join(pairs, ","),
+		handoverPipesEnv+"=3,4",
+	)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	log.Infof("[handover] started new process: pid=%d", cmd.Process.Pid)
+
+	// Only the child keeps its ends of the pipes, so that reading the ready
+	// pipe fails if it exits early.
+	readyW.Close()
+	releaseR.Close()
+
+	ready := make(chan error, 1)
+	go func() {
+		b := make([]byte, 1)
+		if _, err := readyR.Read(b); err != nil {
+			ready <- errors.New("new process exited before it was ready")
+			return
+		}
+		ready <- nil
+	}()
+	select {
+	case err = <-ready:
+	case <-time.After(handoverReadyTimeout):
+		err = fmt.Errorf("new process not ready after %v", handoverReadyTimeout)
+	}
+	if err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return err
+	}
+
+	log.Infof("[handover] new process ready, releasing")
+	release()
+	return nil
+}
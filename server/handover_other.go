@@ -0,0 +1,29 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package server
+
+import (
+	"errors"
+	"net"
+	"os"
+)
+
+// NotifyHandover does nothing where listeners cannot be handed over.
+func NotifyHandover(c chan<- os.Signal) {}
+
+func registerListener(addr string, l net.Listener) {}
+
+func inheritedListener(addr string) (net.Listener, error) {
+	return nil, nil
+}
+
+// WaitForHandover returns right away where listeners cannot be handed over.
+func WaitForHandover() error {
+	return nil
+}
+
+// Handover fails where listeners cannot be handed over.
+func Handover(release func()) error {
+	return errors.New("handing listeners over is not supported on this platform")
+}
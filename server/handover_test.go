@@ -0,0 +1,45 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package server
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Ensures that a listener handed over by a previous process is used instead
+// of binding the address again.
+func TestListenInherited(t *testing.T) {
+	l, err := SocketOptions{}.Listen("127.0.0.1:0")
+	assert.NoError(t, err)
+	defer l.Close()
+	f, err := l.(*net.TCPListener).File()
+	assert.NoError(t, err)
+	addr := l.Addr().String()
+
+	os.Setenv(handoverListenersEnv, fmt.Sprintf("%s=%d", addr, f.Fd()))
+	defer os.Unsetenv(handoverListenersEnv)
+	l2, err := SocketOptions{}.Listen(addr)
+	if assert.NoError(t, err) {
+		defer l2.Close()
+		assert.Equal(t, l2.Addr().String(), addr)
+	}
+	assert.Equal(t, os.Getenv(handoverListenersEnv), "", "inherited listeners are only used once")
+}
+
+// Ensures that malformed inherited listeners are rejected.
+func TestParseHandoverListeners(t *testing.T) {
+	fds, err := parseHandoverListeners("127.0.0.1:4001=3,[::1]:7001=4")
+	assert.NoError(t, err)
+	assert.Equal(t, fds, map[string]uintptr{"127.0.0.1:4001": 3, "[::1]:7001": 4})
+
+	for _, s := range []string{"127.0.0.1:4001", "=3", "127.0.0.1:4001=x", "127.0.0.1:4001=1"} {
+		_, err := parseHandoverListeners(s)
+		assert.Error(t, err, s)
+	}
+}
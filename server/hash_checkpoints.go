@@ -0,0 +1,100 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// The number of applied-state hashes kept by each member.
+const maxHashCheckpoints = 64
+
+// hashCheckpoint is the applied-state hash of a member at a log index.
+type hashCheckpoint struct {
+	Name  string `json:"name"`
+	Index uint64 `json:"index"`
+	Hash  string `json:"hash"`
+}
+
+func newHashCheckpoint(name string, index uint64, hash uint32) *hashCheckpoint {
+	return &hashCheckpoint{Name: name, Index: index, Hash: fmt.Sprintf("%08x", hash)}
+}
+
+// hashCheckpoints keeps the most recent applied-state hashes of this member
+// by the index of the hash command that produced them.
+type hashCheckpoints struct {
+	sync.Mutex
+	indexes []uint64
+	hashes  map[uint64]uint32
+}
+
+func newHashCheckpoints() *hashCheckpoints {
+	return &hashCheckpoints{hashes: make(map[uint64]uint32)}
+}
+
+// Records the hash at an index, dropping the oldest one when full.
+func (h *hashCheckpoints) record(index uint64, hash uint32) {
+	h.Lock()
+	defer h.Unlock()
+
+	if _, ok := h.hashes[index]; !ok {
+		h.indexes = append(h.indexes, index)
+	}
+	h.hashes[index] = hash
+
+	if len(h.indexes) > maxHashCheckpoints {
+		delete(h.hashes, h.indexes[0])
+		h.indexes = h.indexes[1:]
+	}
+}
+
+// Returns the hash recorded at an index, or the latest hash for index zero.
+func (h *hashCheckpoints) get(index uint64) (uint64, uint32, bool) {
+	h.Lock()
+	defer h.Unlock()
+
+	if index == 0 {
+		if len(h.indexes) == 0 {
+			return 0, 0, false
+		}
+		index = h.indexes[len(h.indexes)-1]
+	}
+	hash, ok := h.hashes[index]
+	return index, hash, ok
+}
+
+// Writes the hash recorded at the index given by the "index" parameter, or
+// the latest hash without one.
+func (h *hashCheckpoints) serveHTTP(w http.ResponseWriter, req *http.Request, name string) {
+	var index uint64
+	if v := req.FormValue("index"); v != "" {
+		var err error
+		if index, err = strconv.ParseUint(v, 10, 64); err != nil {
+			http.Error(w, "Invalid index", http.StatusBadRequest)
+			return
+		}
+	}
+
+	index, hash, ok := h.get(index)
+	if !ok {
+		http.Error(w, "No hash at this index", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(newHashCheckpoint(name, index, hash))
+}
+
+// Retrieves the applied-state hash of this member at a checkpoint.
+func (s *Server) GetHashHandler(w http.ResponseWriter, req *http.Request) error {
+	s.peerServer.hashes.serveHTTP(w, req, s.name)
+	return nil
+}
+
+// Commits a hash command that makes every member record its applied-state
+// hash at the same index.
+func (s *Server) PostHashHandler(w http.ResponseWriter, req *http.Request) error {
+	return s.Dispatch(&HashCommand{}, w, req)
+}
@@ -0,0 +1,34 @@
+package server
+
+import (
+	"encoding/json"
+
+	"github.com/coreos/raft"
+)
+
+func init() {
+	raft.RegisterCommand(&HashCommand{})
+}
+
+// The HashCommand records the applied-state hash of every member at the
+// index it is committed at, so members can be compared at a common index.
+type HashCommand struct {
+}
+
+// The name of the hash command in the log
+func (c *HashCommand) CommandName() string {
+	return "etcd:hash"
+}
+
+// Hash the store as of this command's index.
+func (c *HashCommand) Apply(server raft.Server) (interface{}, error) {
+	ps, _ := server.Context().(*PeerServer)
+
+	h, err := ps.store.Hash()
+	if err != nil {
+		return []byte{0}, err
+	}
+	ps.hashes.record(server.CommitIndex(), h)
+
+	return json.Marshal(newHashCheckpoint(ps.name, server.CommitIndex(), h))
+}
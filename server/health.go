@@ -0,0 +1,130 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	etcdErr "github.com/coreos/etcd/error"
+	"github.com/coreos/raft"
+)
+
+// How long a health check may take unless the request asks otherwise.
+const defaultHealthTimeout = time.Second
+
+// The body of a /health response.
+type health struct {
+	Health string   `json:"health"`
+	Name   string   `json:"name"`
+	State  string   `json:"state"`
+	Leader string   `json:"leader"`
+	Errors []string `json:"errors,omitempty"`
+
+	Recovery *recoveryProgress `json:"recovery,omitempty"`
+}
+
+// Reports whether the member can reach a quorum and serve a read. Load
+// balancers should only send traffic to members answering 200; failures are
+// answered with 503 and explained in the body.
+func (s *Server) GetHealthHandler(w http.ResponseWriter, req *http.Request) error {
+	timeout := defaultHealthTimeout
+	if v := req.FormValue("timeout"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("invalid timeout: %v", err)
+		}
+		timeout = d
+	}
+
+	h := &health{
+		Name:   s.name,
+		State:  s.peerServer.RaftServer().State(),
+		Leader: s.peerServer.RaftServer().Leader(),
+	}
+
+	// A member loading its data directory is neither up to date nor able to
+	// take part in the quorum yet.
+	if r := s.peerServer.recovery; !r.recovered() {
+		h.Health = "recovering"
+		h.Recovery = r.progress()
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Retry-After", fmt.Sprint(h.Recovery.retryAfter()))
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return json.NewEncoder(w).Encode(h)
+	}
+
+	if err := s.peerServer.checkQuorum(timeout); err != nil {
+		h.Errors = append(h.Errors, err.Error())
+	}
+	if err := s.checkRead(timeout); err != nil {
+		h.Errors = append(h.Errors, err.Error())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if len(h.Errors) > 0 {
+		h.Health = "unhealthy"
+		w.WriteHeader(http.StatusServiceUnavailable)
+	} else {
+		h.Health = "ok"
+		w.WriteHeader(http.StatusOK)
+	}
+	return json.NewEncoder(w).Encode(h)
+}
+
+// checkRead reads the root of the store within the timeout.
+func (s *Server) checkRead(timeout time.Duration) error {
+	c := make(chan error, 1)
+	go func() {
+		_, err := s.store.Get("/", false, false)
+		c <- err
+	}()
+
+	select {
+	case err := <-c:
+		if err != nil {
+			if e, ok := err.(*etcdErr.Error); ok {
+				return fmt.Errorf("read failed: %s", e.Message)
+			}
+			return fmt.Errorf("read failed: %v", err)
+		}
+		return nil
+	case <-time.After(timeout):
+		return fmt.Errorf("read timed out after %v", timeout)
+	}
+}
+
+// checkQuorum makes sure a majority of the cluster is reachable. The leader
+// commits a no-op entry; a follower must have heard from its leader lately.
+func (s *PeerServer) checkQuorum(timeout time.Duration) error {
+	switch state := s.raftServer.State(); state {
+	case raft.Leader:
+		c := make(chan error, 1)
+		go func() {
+			_, err := s.raftServer.Do(raft.NOPCommand{})
+			c <- err
+		}()
+
+		select {
+		case err := <-c:
+			if err != nil {
+				return fmt.Errorf("cannot commit: %v", err)
+			}
+			return nil
+		case <-time.After(timeout):
+			return fmt.Errorf("no quorum: cannot commit within %v", timeout)
+		}
+
+	case raft.Follower:
+		if s.raftServer.Leader() == "" {
+			return fmt.Errorf("no leader")
+		}
+		if d := time.Now().Sub(s.serverStats.lastRecvAppend); d > timeout+s.ElectionTimeout {
+			return fmt.Errorf("no contact with leader %s for %v", s.raftServer.Leader(), d)
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("no leader: member is %s", state)
+	}
+}
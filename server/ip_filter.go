@@ -0,0 +1,102 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/coreos/etcd/log"
+)
+
+// IPFilter decides which remote addresses may connect to a listener.
+// Connections are checked as they are accepted, before any request is read.
+type IPFilter struct {
+	allow    []*net.IPNet
+	deny     []*net.IPNet
+	rejected uint64
+}
+
+// NewIPFilter creates a filter from lists of CIDRs. Denied networks take
+// precedence. When allowed networks are given only they may connect. It
+// returns nil when both lists are empty.
+func NewIPFilter(allow, deny []string) (*IPFilter, error) {
+	if len(allow) == 0 && len(deny) == 0 {
+		return nil, nil
+	}
+	a, err := parseCIDRs(allow)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid allowed CIDR: %s", err)
+	}
+	d, err := parseCIDRs(deny)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid denied CIDR: %s", err)
+	}
+	return &IPFilter{allow: a, deny: d}, nil
+}
+
+// Allowed determines whether the given IP address may connect.
+func (f *IPFilter) Allowed(ip net.IP) bool {
+	if f == nil {
+		return true
+	}
+	for _, ipnet := range f.deny {
+		if ipnet.Contains(ip) {
+			return false
+		}
+	}
+	if len(f.allow) == 0 {
+		return true
+	}
+	for _, ipnet := range f.allow {
+		if ipnet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Rejected returns the number of connections turned away.
+func (f *IPFilter) Rejected() uint64 {
+	if f == nil {
+		return 0
+	}
+	return atomic.LoadUint64(&f.rejected)
+}
+
+// filterListener closes the connections its filter does not allow.
+type filterListener struct {
+	net.Listener
+	filter *IPFilter
+}
+
+func (l *filterListener) Accept() (net.Conn, error) {
+	for {
+		c, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+		host, _, err := net.SplitHostPort(c.RemoteAddr().String())
+		if err == nil && l.filter.Allowed(net.ParseIP(host)) {
+			return c, nil
+		}
+		atomic.AddUint64(&l.filter.rejected, 1)
+		log.Debugf("rejected connection from %s on %s", c.RemoteAddr(), l.Addr())
+		c.Close()
+	}
+}
+
+// Retrieves the number of connections rejected by the client and peer
+// listeners.
+func (s *Server) GetListenerStatsHandler(w http.ResponseWriter, req *http.Request) error {
+	type listenerStats struct {
+		Rejected uint64 `json:"rejected"`
+	}
+	stats := map[string]listenerStats{
+		"client": {s.SocketOptions.Filter.Rejected()},
+		"peer":   {s.peerServer.SocketOptions.Filter.Rejected()},
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(stats)
+}
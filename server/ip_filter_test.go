@@ -0,0 +1,50 @@
+package server
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Ensures that denied networks take precedence over allowed ones.
+func TestIPFilterAllowed(t *testing.T) {
+	f, err := NewIPFilter([]string{"10.0.0.0/8"}, []string{"10.0.0.1"})
+	assert.NoError(t, err)
+	assert.True(t, f.Allowed(net.ParseIP("10.1.2.3")))
+	assert.False(t, f.Allowed(net.ParseIP("10.0.0.1")))
+	assert.False(t, f.Allowed(net.ParseIP("192.168.1.1")))
+
+	f, err = NewIPFilter(nil, []string{"192.168.0.0/16"})
+	assert.NoError(t, err)
+	assert.True(t, f.Allowed(net.ParseIP("10.1.2.3")))
+	assert.False(t, f.Allowed(net.ParseIP("192.168.1.1")))
+
+	f, err = NewIPFilter(nil, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, f)
+	assert.True(t, f.Allowed(net.ParseIP("10.1.2.3")))
+
+	_, err = NewIPFilter([]string{"10.0.0.0/99"}, nil)
+	assert.Error(t, err)
+}
+
+// Ensures that a listener closes the connections its filter denies.
+func TestListenFilter(t *testing.T) {
+	f, _ := NewIPFilter(nil, []string{"127.0.0.1"})
+	l, err := SocketOptions{Filter: f}.Listen("127.0.0.1:0")
+	assert.NoError(t, err)
+	defer l.Close()
+
+	go l.Accept()
+
+	c, err := net.Dial("tcp", l.Addr().String())
+	assert.NoError(t, err)
+	defer c.Close()
+
+	c.SetReadDeadline(time.Now().Add(time.Second))
+	_, err = c.Read(make([]byte, 1))
+	assert.Error(t, err)
+	assert.Equal(t, f.Rejected(), uint64(1))
+}
@@ -19,15 +19,23 @@ type JoinCommand struct {
 	Name       string `json:"name"`
 	RaftURL    string `json:"raftURL"`
 	EtcdURL    string `json:"etcdURL"`
+
+	// Tags are free-form member metadata such as zone or rack.
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// Observers replicate the log but never vote or count toward the quorum.
+	Observer bool `json:"observer,omitempty"`
 }
 
-func NewJoinCommand(minVersion int, maxVersion int, name, raftUrl, etcdUrl string) *JoinCommand {
+func NewJoinCommand(minVersion int, maxVersion int, name, raftUrl, etcdUrl string, tags map[string]string, observer bool) *JoinCommand {
 	return &JoinCommand{
 		MinVersion: minVersion,
 		MaxVersion: maxVersion,
 		Name:       name,
 		RaftURL:    raftUrl,
 		EtcdURL:    etcdUrl,
+		Tags:       tags,
+		Observer:   observer,
 	}
 }
 
@@ -47,21 +55,33 @@ func (c *JoinCommand) Apply(server raft.Server) (interface{}, error) {
 	ps.registry.Invalidate(c.Name)
 
 	// Check if the join command is from a previous peer, who lost all its previous log.
+	// A rejoining peer may have been restarted with different tags.
 	if _, ok := ps.registry.ClientURL(c.Name); ok {
+		if tags, _ := ps.registry.Tags(c.Name); !equalTags(tags, c.Tags) {
+			ps.registry.SetTags(c.Name, c.Tags)
+		}
 		return b, nil
 	}
 
-	// Check peer number in the cluster
-	if ps.registry.Count() == ps.MaxClusterSize {
+	// Check peer number in the cluster. Observers are counted apart.
+	if c.Observer {
+		if ps.observerCount() >= ps.MaxObservers {
+			log.Debug("Reject observer join request from ", c.Name)
+			return []byte{0}, etcdErr.NewError(etcdErr.EcodeNoMorePeer, "", server.CommitIndex())
+		}
+	} else if ps.voterCount() >= ps.MaxClusterSize {
 		log.Debug("Reject join request from ", c.Name)
 		return []byte{0}, etcdErr.NewError(etcdErr.EcodeNoMorePeer, "", server.CommitIndex())
 	}
 
 	// Add to shared peer registry.
-	ps.registry.Register(c.Name, c.RaftURL, c.EtcdURL)
+	ps.registry.Register(c.Name, c.RaftURL, c.EtcdURL, c.Tags)
 
 	// Add peer in raft
 	err := server.AddPeer(c.Name, "")
+	if err == nil && c.Observer {
+		err = server.SetObserver(c.Name, true)
+	}
 
 	// Add peer stats
 	if c.Name != ps.RaftServer().Name() {
@@ -75,3 +95,32 @@ func (c *JoinCommand) Apply(server raft.Server) (interface{}, error) {
 func (c *JoinCommand) NodeName() string {
 	return c.Name
 }
+
+// voterCount returns the number of registered members that are not observers.
+func (s *PeerServer) voterCount() int {
+	count := 0
+	for _, name := range s.registry.Names() {
+		if !s.raftServer.IsObserver(name) {
+			count++
+		}
+	}
+	return count
+}
+
+// observerCount returns the number of registered members that are observers.
+func (s *PeerServer) observerCount() int {
+	return len(s.registry.Names()) - s.voterCount()
+}
+
+// equalTags checks whether two tag sets hold the same pairs.
+func equalTags(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if w, ok := b[k]; !ok || w != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,43 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// The default maximum number of path components in a key.
+	defaultMaxKeyDepth = 64
+
+	// The default maximum length, in bytes, of a single path component.
+	defaultMaxKeyNameLength = 255
+)
+
+// validateKey checks a key path against the depth and component length
+// limits and rejects control characters and relative components. A limit of
+// zero disables the corresponding check.
+func validateKey(key string, maxDepth int, maxNameLength int) error {
+	depth := 0
+	for _, name := range strings.Split(key, "/") {
+		if name == "" {
+			continue
+		}
+		if name == "." || name == ".." {
+			return fmt.Errorf("relative path component %q", name)
+		}
+		if maxNameLength > 0 && len(name) > maxNameLength {
+			return fmt.Errorf("path component of %d bytes exceeds the maximum of %d", len(name), maxNameLength)
+		}
+		for _, c := range name {
+			if c < 0x20 || c == 0x7f {
+				return fmt.Errorf("invalid character %q", c)
+			}
+		}
+		depth++
+	}
+
+	if maxDepth > 0 && depth > maxDepth {
+		return fmt.Errorf("key depth of %d exceeds the maximum of %d", depth, maxDepth)
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package server
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Ensures that well formed keys pass validation.
+func TestValidateKey(t *testing.T) {
+	assert.Nil(t, validateKey("", 2, 8), "")
+	assert.Nil(t, validateKey("foo/bar", 2, 8), "")
+	assert.Nil(t, validateKey("foo/bar/", 2, 8), "")
+	assert.Nil(t, validateKey("foo//bar", 2, 8), "")
+	assert.Nil(t, validateKey("föö", 2, 8), "")
+	assert.Nil(t, validateKey(strings.Repeat("a/", 100), 0, 0), "")
+}
+
+// Ensures that keys breaking the limits or holding invalid characters are rejected.
+func TestValidateKeyInvalid(t *testing.T) {
+	assert.NotNil(t, validateKey("a/b/c", 2, 8), "")
+	assert.NotNil(t, validateKey("foo/barbazbat", 2, 8), "")
+	assert.NotNil(t, validateKey("foo/../bar", 2, 8), "")
+	assert.NotNil(t, validateKey("foo/./bar", 0, 0), "")
+	assert.NotNil(t, validateKey("foo\nbar", 2, 8), "")
+	assert.NotNil(t, validateKey("foo\x00", 2, 8), "")
+}
@@ -0,0 +1,90 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/coreos/etcd/log"
+	"github.com/coreos/raft"
+)
+
+// The member tag holding the zone of a node.
+const zoneTag = "zone"
+
+// How often the leader checks whether it should hand leadership over to a
+// member of the preferred zone.
+const leaderZoneCheckInterval = 5 * time.Second
+
+// monitorLeaderZone moves leadership into the preferred zone whenever this
+// node leads from outside of it and a caught-up member of the zone exists.
+func (s *PeerServer) monitorLeaderZone() {
+	for {
+		time.Sleep(leaderZoneCheckInterval)
+
+		if s.raftServer.State() != raft.Leader || s.inLeaderZone(s.name) {
+			continue
+		}
+
+		name := s.preferredLeader()
+		if name == "" {
+			continue
+		}
+
+		log.Infof("[zone] transferring leadership: name=%s to=%s zone=%s", s.name, name, s.LeaderZone)
+		if err := s.transferLeadership(name); err != nil {
+			log.Warnf("[zone] transfer to %s failed: %v", name, err)
+		}
+	}
+}
+
+// inLeaderZone checks whether the named member is tagged with the preferred zone.
+func (s *PeerServer) inLeaderZone(name string) bool {
+	tags, _ := s.registry.Tags(name)
+	return tags[zoneTag] == s.LeaderZone
+}
+
+// preferredLeader returns a member of the preferred zone that has replicated
+// every committed entry, or an empty string if there is none.
+func (s *PeerServer) preferredLeader() string {
+	commitIndex := s.raftServer.CommitIndex()
+	for name, peer := range s.raftServer.Peers() {
+		if !peer.Observer && s.inLeaderZone(name) && peer.PrevLogIndex() >= commitIndex {
+			return name
+		}
+	}
+	return ""
+}
+
+// transferLeadership asks the named follower to start an election. This node
+// does not campaign meanwhile so that the follower wins.
+func (s *PeerServer) transferLeadership(name string) error {
+	peerURL, ok := s.registry.PeerURL(name)
+	if !ok {
+		return fmt.Errorf("unknown peer %s", name)
+	}
+
+	s.raftServer.SetPromotable(false)
+	defer func() {
+		time.Sleep(2 * s.ElectionTimeout)
+		s.raftServer.SetPromotable(!(s.SlowDiskAbdicate && s.diskStats.IsDegraded()))
+	}()
+
+	return s.requestCampaign(peerURL)
+}
+
+// requestCampaign asks the follower at peerURL to start an election now.
+func (s *PeerServer) requestCampaign(peerURL string) error {
+	t := s.raftServer.Transporter().(*transporter)
+	resp, req, err := t.Post(peerURL+"/campaign", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	t.CancelWhenTimeout(req)
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("campaign refused: %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+// SocketOptions tunes the sockets of a listener and the connections it
+// accepts.
+type SocketOptions struct {
+	// Sets SO_REUSEPORT so that a new process can bind the same address
+	// while the old one drains its connections.
+	ReusePort bool
+
+	// The TCP keepalive period of accepted connections. Zero keeps the
+	// default and a negative period disables keepalives.
+	KeepAlive time.Duration
+
+	// Enables Nagle's algorithm on accepted connections, which otherwise
+	// send small writes without delay.
+	Delay bool
+
+	// Closes accepted connections from addresses the filter does not allow.
+	Filter *IPFilter
+}
+
+// Listen opens a TCP listener on addr with the socket options applied. A
+// listener handed over by a previous process is used instead of binding the
+// address again.
+func (o SocketOptions) Listen(addr string) (net.Listener, error) {
+	l, err := inheritedListener(addr)
+	if err != nil {
+		return nil, err
+	}
+	if l == nil {
+		lc := net.ListenConfig{KeepAlive: o.KeepAlive}
+		if o.ReusePort {
+			lc.Control = reusePort
+		}
+		if l, err = lc.Listen(context.Background(), "tcp", addr); err != nil {
+			return nil, err
+		}
+		registerListener(addr, l)
+	} else {
+		registerListener(addr, l)
+		if o.KeepAlive != 0 {
+			l = &keepAliveListener{l, o.KeepAlive}
+		}
+	}
+	if o.Delay {
+		l = &delayListener{l}
+	}
+	if o.Filter != nil {
+		l = &filterListener{l, o.Filter}
+	}
+	return l, nil
+}
+
+// keepAliveListener sets the keepalive period of the connections accepted
+// by an inherited listener, which the listen config cannot do.
+type keepAliveListener struct {
+	net.Listener
+	period time.Duration
+}
+
+func (l *keepAliveListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	if tc, ok := c.(*net.TCPConn); ok {
+		if l.period < 0 {
+			tc.SetKeepAlive(false)
+		} else {
+			tc.SetKeepAlive(true)
+			tc.SetKeepAlivePeriod(l.period)
+		}
+	}
+	return c, nil
+}
+
+// delayListener turns TCP_NODELAY off on the connections it accepts.
+type delayListener struct {
+	net.Listener
+}
+
+func (l *delayListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	if tc, ok := c.(*net.TCPConn); ok {
+		tc.SetNoDelay(false)
+	}
+	return c, nil
+}
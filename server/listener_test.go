@@ -0,0 +1,25 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Ensures that two listeners can bind the same address with ReusePort.
+func TestListenReusePort(t *testing.T) {
+	o := SocketOptions{ReusePort: true}
+	l1, err := o.Listen("127.0.0.1:0")
+	assert.NoError(t, err)
+	defer l1.Close()
+
+	l2, err := o.Listen(l1.Addr().String())
+	assert.NoError(t, err)
+	if l2 != nil {
+		l2.Close()
+	}
+
+	// Without it the address is taken.
+	_, err = SocketOptions{}.Listen(l1.Addr().String())
+	assert.Error(t, err)
+}
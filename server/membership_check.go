@@ -0,0 +1,241 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// A membershipChange is a join or a removal checked by a dry run.
+type membershipChange struct {
+	Action     string `json:"action"`
+	Name       string `json:"name"`
+	RaftURL    string `json:"raftURL,omitempty"`
+	EtcdURL    string `json:"etcdURL,omitempty"`
+	Observer   bool   `json:"observer,omitempty"`
+	MinVersion int    `json:"minVersion,omitempty"`
+	MaxVersion int    `json:"maxVersion,omitempty"`
+}
+
+// A membershipCheck is one of the checks a dry run makes.
+type membershipCheck struct {
+	Name    string `json:"name"`
+	OK      bool   `json:"ok"`
+	Message string `json:"message,omitempty"`
+}
+
+// A membershipReport tells what a membership change would do to the quorum
+// and whether it passes every check, without applying it.
+type membershipReport struct {
+	Action         string             `json:"action"`
+	Name           string             `json:"name"`
+	OK             bool               `json:"ok"`
+	Voters         int                `json:"voters"`
+	VotersAfter    int                `json:"votersAfter"`
+	Quorum         int                `json:"quorum"`
+	QuorumAfter    int                `json:"quorumAfter"`
+	Reachable      int                `json:"reachable"`
+	ReachableAfter int                `json:"reachableAfter"`
+	Checks         []*membershipCheck `json:"checks"`
+}
+
+func (r *membershipReport) check(name string, ok bool, format string, args ...interface{}) {
+	r.Checks = append(r.Checks, &membershipCheck{Name: name, OK: ok, Message: fmt.Sprintf(format, args...)})
+	if !ok {
+		r.OK = false
+	}
+}
+
+// simulatedMember is a member as seen by a dry run.
+type simulatedMember struct {
+	voter     bool
+	reachable bool
+}
+
+// simulatedCluster is the membership a dry run applies its changes to.
+type simulatedCluster map[string]*simulatedMember
+
+func (c simulatedCluster) voters() (voters int, reachable int) {
+	for _, m := range c {
+		if m.voter {
+			voters++
+			if m.reachable {
+				reachable++
+			}
+		}
+	}
+	return voters, reachable
+}
+
+func quorum(voters int) int {
+	return voters/2 + 1
+}
+
+// CheckMembership checks a list of membership changes as if they were
+// applied one after the other, without applying any, and reports the effect
+// of each on the quorum.
+func (s *PeerServer) CheckMembership(changes []*membershipChange) []*membershipReport {
+	cluster := make(simulatedCluster)
+	for _, status := range s.memberStatuses() {
+		cluster[status.Name] = &simulatedMember{
+			voter:     !s.raftServer.IsObserver(status.Name),
+			reachable: status.Reachable,
+		}
+	}
+
+	reports := make([]*membershipReport, 0, len(changes))
+	for _, c := range changes {
+		reports = append(reports, s.checkChange(cluster, c))
+	}
+	return reports
+}
+
+// checkChange checks a single change and applies it to the simulated
+// cluster so that the next changes are checked against the result.
+func (s *PeerServer) checkChange(cluster simulatedCluster, c *membershipChange) *membershipReport {
+	r := &membershipReport{Action: c.Action, Name: c.Name, OK: true}
+	r.Voters, r.Reachable = cluster.voters()
+	r.Quorum = quorum(r.Voters)
+
+	if c.Name == "" {
+		r.check("name", false, "a name is required")
+		return r
+	}
+
+	switch c.Action {
+	case "join":
+		if _, ok := cluster[c.Name]; ok {
+			r.check("member", true, "%s is already a member, joining again keeps its place", c.Name)
+			break
+		}
+		r.check("member", true, "")
+
+		reachable := s.checkJoiningPeer(r, c)
+		if c.Observer {
+			observers, max := len(cluster)-r.Voters, s.MaxObservers
+			r.check("observers", observers < max, "%d of at most %d observers", observers, max)
+		} else {
+			max := s.MaxClusterSize
+			r.check("clusterSize", r.Voters < max, "%d of at most %d voters", r.Voters, max)
+		}
+		cluster[c.Name] = &simulatedMember{voter: !c.Observer, reachable: reachable}
+
+	case "remove":
+		m, ok := cluster[c.Name]
+		if !ok {
+			r.check("member", false, "%s is not a member", c.Name)
+			break
+		}
+		r.check("member", true, "")
+		if c.Name == s.raftServer.Leader() {
+			r.check("leader", true, "%s is the leader, removing it starts an election", c.Name)
+		}
+		delete(cluster, c.Name)
+		if m.voter && len(cluster) == 0 {
+			r.check("lastMember", false, "%s is the last member", c.Name)
+		}
+
+	default:
+		r.check("action", false, "unknown action %q, expected join or remove", c.Action)
+		return r
+	}
+
+	r.VotersAfter, r.ReachableAfter = cluster.voters()
+	r.QuorumAfter = quorum(r.VotersAfter)
+	r.check("quorum", r.ReachableAfter >= r.QuorumAfter, "%d of %d voters reachable for a quorum of %d",
+		r.ReachableAfter, r.VotersAfter, r.QuorumAfter)
+	return r
+}
+
+// checkJoiningPeer checks that a joining member answers on its peer URL
+// under its name and supports the store version of the cluster. It tells
+// whether the member could be reached.
+func (s *PeerServer) checkJoiningPeer(r *membershipReport, c *membershipChange) bool {
+	version := s.store.Version()
+	if c.MinVersion > 0 || c.MaxVersion > 0 {
+		ok := version >= c.MinVersion && (c.MaxVersion == 0 || version <= c.MaxVersion)
+		r.check("version", ok, "cluster version is %d; version compatibility is %d - %d", version, c.MinVersion, c.MaxVersion)
+	}
+
+	if !validMemberURL(c.RaftURL) {
+		r.check("peerURL", false, "invalid peer URL %q", c.RaftURL)
+		return false
+	}
+	if c.EtcdURL != "" && !validMemberURL(c.EtcdURL) {
+		r.check("clientURL", false, "invalid client URL %q", c.EtcdURL)
+	}
+
+	t := s.raftServer.Transporter().(*transporter)
+	peerURL := strings.TrimSuffix(c.RaftURL, "/")
+	resp, req, err := t.Get(peerURL + "/name")
+	if err != nil {
+		r.check("peerURL", false, "cannot reach %s: %v", peerURL, err)
+		return false
+	}
+	t.CancelWhenTimeout(req)
+	name, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(name) != c.Name {
+		r.check("peerURL", false, "%s answers as %q", peerURL, name)
+		return false
+	}
+	r.check("peerURL", true, "")
+
+	if c.MinVersion == 0 && c.MaxVersion == 0 {
+		resp, req, err := t.Get(fmt.Sprintf("%s/version/%d/check", peerURL, version))
+		if err != nil {
+			r.check("version", false, "cannot check version: %v", err)
+		} else {
+			t.CancelWhenTimeout(req)
+			resp.Body.Close()
+			r.check("version", resp.StatusCode == http.StatusOK, "cluster version is %d", version)
+		}
+	}
+	return true
+}
+
+// writeMembershipReports answers a dry run with its reports, as a list for a
+// batch of changes. It fails when any change does not pass its checks.
+func writeMembershipReports(w http.ResponseWriter, reports []*membershipReport, batch bool) {
+	status := http.StatusOK
+	for _, r := range reports {
+		if !r.OK {
+			status = http.StatusBadRequest
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if batch {
+		json.NewEncoder(w).Encode(reports)
+	} else {
+		json.NewEncoder(w).Encode(reports[0])
+	}
+}
+
+// Checks a batch of membership changes, given as a JSON list, without
+// applying them.
+func (s *Server) PostMembershipCheckHandler(w http.ResponseWriter, req *http.Request) error {
+	var changes []*membershipChange
+	if err := json.NewDecoder(req.Body).Decode(&changes); err != nil || len(changes) == 0 {
+		http.Error(w, "Invalid membership changes", http.StatusBadRequest)
+		return nil
+	}
+	writeMembershipReports(w, s.peerServer.CheckMembership(changes), true)
+	return nil
+}
+
+// joinChange returns the membership change of a join command.
+func joinChange(c *JoinCommand) *membershipChange {
+	return &membershipChange{
+		Action:     "join",
+		Name:       c.Name,
+		RaftURL:    c.RaftURL,
+		EtcdURL:    c.EtcdURL,
+		Observer:   c.Observer,
+		MinVersion: c.MinVersion,
+		MaxVersion: c.MaxVersion,
+	}
+}
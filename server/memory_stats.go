@@ -0,0 +1,63 @@
+package server
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/coreos/etcd/store"
+)
+
+// How often the memory held by the store is estimated.
+const memoryStatsInterval = 10 * time.Second
+
+// memoryStats keeps the last estimate of the memory held by the store.
+type memoryStats struct {
+	mutex sync.Mutex
+
+	*store.MemoryStats
+	Updated  time.Time `json:"updated"`
+	Duration float64   `json:"duration"`
+}
+
+// update estimates the memory held by the store again.
+func (ms *memoryStats) update(s store.Store) {
+	start := time.Now()
+	stats := s.MemoryStats()
+	d := time.Now().Sub(start)
+
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+	ms.MemoryStats = stats
+	ms.Updated = start
+	ms.Duration = milliseconds(d)
+}
+
+// JSON returns the last estimate, making one if there is none yet.
+func (ms *memoryStats) JSON(s store.Store) []byte {
+	ms.mutex.Lock()
+	empty := ms.MemoryStats == nil
+	ms.mutex.Unlock()
+	if empty {
+		ms.update(s)
+	}
+
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+	b, _ := json.Marshal(ms)
+	return b
+}
+
+// monitorMemory periodically estimates the memory held by the store. Walking
+// a large store takes a while so requests get the last estimate instead.
+func (s *PeerServer) monitorMemory() {
+	for {
+		s.memoryStats.update(s.store)
+		time.Sleep(memoryStatsInterval)
+	}
+}
+
+// MemoryStats retrieves the last estimate of the memory held by the store.
+func (s *PeerServer) MemoryStats() []byte {
+	return s.memoryStats.JSON(s.store)
+}
@@ -29,6 +29,7 @@ type PeerServer struct {
 	listener         net.Listener
 	joinIndex        uint64
 	name             string
+	path             string
 	url              string
 	bindAddr         string
 	tlsConf          *TLSConfig
@@ -38,35 +39,69 @@ type PeerServer struct {
 	registry         *Registry
 	store            store.Store
 	snapConf         *snapshotConf
+	batcher          *batcher
+	diskStats        *diskStats
+	memoryStats      memoryStats
+	hashes           *hashCheckpoints
+	consistencyStats *consistencyStats
+	recovery         *recoveryStatus
+	raftEvents       *raftEventStreams
 	MaxClusterSize   int
+	MaxObservers     int
 	RetryTimes       int
 	HeartbeatTimeout time.Duration
 	ElectionTimeout  time.Duration
-}
+	BatchWindow      time.Duration
+
+	// The width of the window election timeouts are randomized in. Zero
+	// uses the election timeout, so that timeouts vary between one and two
+	// election timeouts.
+	ElectionWindow time.Duration
+
+	// Raft log syncs slower than this are counted as slow. A node whose
+	// syncs are slow several times in a row is marked as degraded.
+	SlowDiskThreshold time.Duration
+
+	// Stop a degraded node from campaigning and step down if it is the leader.
+	SlowDiskAbdicate bool
+
+	// Followers whose clock differs from the leader's by more than this are
+	// reported as skewed.
+	MaxClockSkew time.Duration
 
-// TODO: find a good policy to do snapshot
-type snapshotConf struct {
-	// Etcd will check if snapshot is need every checkingInterval
-	checkingInterval time.Duration
+	// Member metadata published in the registry when joining.
+	Tags map[string]string
 
-	// The number of writes when the last snapshot happened
-	lastWrites uint64
+	// Hand leadership over to members whose zone tag matches.
+	LeaderZone string
 
-	// If the incremental number of writes since the last snapshot
-	// exceeds the write Threshold, etcd will do a snapshot
-	writesThr uint64
+	// Join the cluster as an observer that never votes.
+	Observer bool
+
+	// The options of the peer listener.
+	SocketOptions SocketOptions
+
+	// Never sync to disk, for data that is thrown away on exit. The disk
+	// is then not monitored.
+	NoFsync bool
+
+	// How often the leader compares the applied state of the members.
+	// Zero disables the check.
+	HashCheckInterval time.Duration
 }
 
 func NewPeerServer(name string, path string, url string, bindAddr string, tlsConf *TLSConfig, tlsInfo *TLSInfo, registry *Registry, store store.Store, snapshotCount int) *PeerServer {
 	s := &PeerServer{
 		name:     name,
+		path:     path,
 		url:      url,
 		bindAddr: bindAddr,
 		tlsConf:  tlsConf,
 		tlsInfo:  tlsInfo,
 		registry: registry,
 		store:    store,
-		snapConf: &snapshotConf{time.Second * 3, 0, uint64(snapshotCount)},
+		snapConf: newSnapshotConf(time.Second*3, uint64(snapshotCount)),
+		hashes:   newHashCheckpoints(),
 		followersStats: &raftFollowersStats{
 			Leader:    name,
 			Followers: make(map[string]*raftFollowerStats),
@@ -80,28 +115,39 @@ func NewPeerServer(name string, path string, url string, bindAddr string, tlsCon
 				back: -1,
 			},
 		},
-		HeartbeatTimeout: defaultHeartbeatTimeout,
-		ElectionTimeout:  defaultElectionTimeout,
+		HeartbeatTimeout:  defaultHeartbeatTimeout,
+		ElectionTimeout:   defaultElectionTimeout,
+		SlowDiskThreshold: defaultSlowDiskThreshold,
+		MaxClockSkew:      defaultMaxClockSkew,
 	}
 
+	s.consistencyStats = &consistencyStats{}
+	s.raftEvents = newRaftEventStreams()
+
 	// Create transporter for raft
 	raftTransporter := newTransporter(tlsConf.Scheme, tlsConf.Client, s)
 
 	// Create raft server
-	raftServer, err := raft.NewServer(name, path, raftTransporter, s.store, s, "")
+	s.recovery = newRecoveryStatus(func() uint64 { return s.raftServer.CommitIndex() })
+	raftServer, err := raft.NewServer(name, path, raftTransporter, &recoveryStore{s.store, s.recovery}, s, "")
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	s.raftServer = raftServer
+	s.watchRaftEvents()
+	s.watchCorruption()
 
 	return s
 }
 
 // Start the raft server
 func (s *PeerServer) ListenAndServe(snapshot bool, cluster []string) error {
+	go s.recovery.monitor()
+
 	// LoadSnapshot
 	if snapshot {
+		s.recovery.begin(recoverySnapshot)
 		err := s.raftServer.LoadSnapshot()
 
 		if err == nil {
@@ -112,15 +158,45 @@ func (s *PeerServer) ListenAndServe(snapshot bool, cluster []string) error {
 	}
 
 	s.raftServer.SetElectionTimeout(s.ElectionTimeout)
+	s.raftServer.SetElectionWindow(s.ElectionWindow)
 	s.raftServer.SetHeartbeatTimeout(s.HeartbeatTimeout)
 
+	if s.BatchWindow > 0 {
+		s.batcher = newBatcher(s.raftServer, s.BatchWindow)
+	}
+	s.diskStats = newDiskStats(s.SlowDiskThreshold)
+	s.raftServer.SetLogSync(!s.NoFsync)
+	if !s.NoFsync {
+		s.watchLogSyncs()
+	}
+
+	// Starting raft replays the committed entries of the log.
+	s.recovery.begin(recoveryReplay)
 	s.raftServer.Start()
+	if s.server != nil {
+		// Keys in another case written before would be unreachable.
+		if err := s.server.CheckFoldedKeys(); err != nil {
+			log.Fatal(err)
+		}
+	}
+	s.recovery.finish()
+
+	if replay := s.raftServer.ReplayStats(); replay.Entries > 0 {
+		s.serverStats.Replay = &replay
+		log.Infof("[recovery] replayed %d entries (%d bytes) in %v with %d workers: %.0f entries/s, %.0f bytes/s",
+			replay.Entries, replay.Bytes, replay.Duration, replay.Workers, replay.EntriesPerSecond, replay.BytesPerSecond)
+	}
 
 	if s.raftServer.IsLogEmpty() {
 		// start as a leader in a new cluster
 		if len(cluster) == 0 {
+			if s.Observer {
+				log.Fatal("An observer cannot start a new cluster")
+			}
 			s.startAsLeader()
 		} else {
+			// Do not campaign before our own join entry arrives.
+			s.raftServer.SetObserver(s.name, s.Observer)
 			s.startAsFollower(cluster)
 		}
 
@@ -143,6 +219,13 @@ func (s *PeerServer) ListenAndServe(snapshot bool, cluster []string) error {
 	}
 
 	go s.monitorSync()
+	go s.monitorMemory()
+	if s.LeaderZone != "" {
+		go s.monitorLeaderZone()
+	}
+	if s.HashCheckInterval > 0 {
+		go s.monitorConsistency()
+	}
 
 	// open the snapshot
 	if snapshot {
@@ -159,7 +242,7 @@ func (s *PeerServer) listenAndServe() error {
 	if addr == "" {
 		addr = ":http"
 	}
-	l, e := net.Listen("tcp", addr)
+	l, e := s.SocketOptions.Listen(addr)
 	if e != nil {
 		return e
 	}
@@ -188,7 +271,7 @@ func (s *PeerServer) listenAndServeTLS(certFile, keyFile string) error {
 		return err
 	}
 
-	conn, err := net.Listen("tcp", addr)
+	conn, err := s.SocketOptions.Listen(addr)
 	if err != nil {
 		return err
 	}
@@ -219,7 +302,7 @@ func (s *PeerServer) SetServer(server *Server) {
 func (s *PeerServer) startAsLeader() {
 	// leader need to join self as a peer
 	for {
-		_, err := s.raftServer.Do(NewJoinCommand(store.MinVersion(), store.MaxVersion(), s.raftServer.Name(), s.url, s.server.URL()))
+		_, err := s.raftServer.Do(NewJoinCommand(store.MinVersion(), store.MaxVersion(), s.raftServer.Name(), s.url, s.server.URL(), s.Tags, s.Observer))
 		if err == nil {
 			break
 		}
@@ -261,6 +344,9 @@ func (s *PeerServer) startTransport(scheme string, tlsConf tls.Config) error {
 	router.HandleFunc("/join", s.JoinHttpHandler)
 	router.HandleFunc("/remove/{name:.+}", s.RemoveHttpHandler)
 	router.HandleFunc("/vote", s.VoteHttpHandler)
+	router.HandleFunc("/campaign", s.CampaignHttpHandler)
+	router.HandleFunc("/hash", s.HashHttpHandler)
+	router.HandleFunc("/status", s.StatusHttpHandler)
 	router.HandleFunc("/log", s.GetLogHttpHandler)
 	router.HandleFunc("/log/append", s.AppendEntriesHttpHandler)
 	router.HandleFunc("/snapshot", s.SnapshotHttpHandler)
@@ -356,7 +442,7 @@ func (s *PeerServer) joinByPeer(server raft.Server, peer string, scheme string)
 		return fmt.Errorf("Unable to join: cluster version is %d; version compatibility is %d - %d", version, store.MinVersion(), store.MaxVersion())
 	}
 
-	json.NewEncoder(&b).Encode(NewJoinCommand(store.MinVersion(), store.MaxVersion(), server.Name(), s.url, s.server.URL()))
+	json.NewEncoder(&b).Encode(NewJoinCommand(store.MinVersion(), store.MaxVersion(), server.Name(), s.url, s.server.URL(), s.Tags, s.Observer))
 
 	joinURL := url.URL{Host: peer, Scheme: scheme, Path: "/join"}
 
@@ -381,7 +467,7 @@ func (s *PeerServer) joinByPeer(server raft.Server, peer string, scheme string)
 			if resp.StatusCode == http.StatusTemporaryRedirect {
 				address := resp.Header.Get("Location")
 				log.Debugf("Send Join Request to %s", address)
-				json.NewEncoder(&b).Encode(NewJoinCommand(store.MinVersion(), store.MaxVersion(), server.Name(), s.url, s.server.URL()))
+				json.NewEncoder(&b).Encode(NewJoinCommand(store.MinVersion(), store.MaxVersion(), server.Name(), s.url, s.server.URL(), s.Tags, s.Observer))
 				resp, req, err = t.Post(address, &b)
 
 			} else if resp.StatusCode == http.StatusBadRequest {
@@ -409,11 +495,31 @@ func (s *PeerServer) Stats() []byte {
 
 	s.serverStats.RecvingPkgRate, s.serverStats.RecvingBandwidthRate = queue.Rate()
 
+	s.serverStats.setElectionTimeout(s.raftServer.ElectionTimeoutStats())
+
 	b, _ := json.Marshal(s.serverStats)
 
 	return b
 }
 
+// Retrieves stats on the batching of client writes.
+func (s *PeerServer) BatchStats() []byte {
+	if s.batcher == nil {
+		return (&batchStats{}).JSON()
+	}
+	return s.batcher.stats.JSON()
+}
+
+// propose sends a client command to raft, grouping it with other commands
+// when a batch window is set.
+func (s *PeerServer) propose(c raft.Command) (interface{}, error) {
+	defer s.checkLogSize()
+	if s.batcher == nil {
+		return s.raftServer.Do(c)
+	}
+	return s.batcher.Do(c)
+}
+
 func (s *PeerServer) PeerStats() []byte {
 	if s.raftServer.State() == raft.Leader {
 		b, _ := json.Marshal(s.followersStats)
@@ -422,15 +528,48 @@ func (s *PeerServer) PeerStats() []byte {
 	return nil
 }
 
-func (s *PeerServer) monitorSnapshot() {
-	for {
-		time.Sleep(s.snapConf.checkingInterval)
-		currentWrites := s.store.TotalTransactions() - s.snapConf.lastWrites
-		if uint64(currentWrites) > s.snapConf.writesThr {
-			s.raftServer.TakeSnapshot()
-			s.snapConf.lastWrites = s.store.TotalTransactions()
+// watchLogSyncs records how long the raft log takes to write and sync
+// appended entries, and marks the disk as degraded or healthy again.
+func (s *PeerServer) watchLogSyncs() {
+	s.raftServer.AddEventListener(func(e raft.Event) {
+		if e.Type != raft.LogSyncEventType {
+			return
+		}
+		d := e.Value.(time.Duration)
+		if d > s.SlowDiskThreshold {
+			log.Warnf("[disk] slow fsync: name=%s latency=%v threshold=%v", s.name, d, s.SlowDiskThreshold)
 		}
+		if s.diskStats.recordFsync(d) {
+			// Listeners must not call back into the raft server.
+			go s.diskHealthChanged()
+		}
+	})
+}
+
+// diskHealthChanged lets a degraded node stop leading and campaigning when
+// SlowDiskAbdicate is set, and lets it campaign again once it recovers.
+func (s *PeerServer) diskHealthChanged() {
+	if s.diskStats.IsDegraded() {
+		log.Warnf("[disk] degraded: name=%s samples=%d threshold=%v abdicate=%v", s.name, slowDiskSamples, s.SlowDiskThreshold, s.SlowDiskAbdicate)
+		if s.SlowDiskAbdicate {
+			s.raftServer.SetPromotable(false)
+			if s.raftServer.State() == raft.Leader {
+				log.Warnf("[disk] stepping down as leader: name=%s", s.name)
+				s.raftServer.StepDown()
+			}
+		}
+	} else {
+		log.Infof("[disk] recovered: name=%s samples=%d threshold=%v", s.name, slowDiskSamples, s.SlowDiskThreshold)
+		s.raftServer.SetPromotable(true)
+	}
+}
+
+// Retrieves stats on the write latency of the data directory.
+func (s *PeerServer) DiskStats() []byte {
+	if s.diskStats == nil {
+		return newDiskStats(s.SlowDiskThreshold).JSON()
 	}
+	return s.diskStats.JSON()
 }
 
 func (s *PeerServer) monitorSync() {
@@ -47,6 +47,30 @@ func (ps *PeerServer) VoteHttpHandler(w http.ResponseWriter, req *http.Request)
 	}
 }
 
+// Starts an election on behalf of a leader handing over leadership
+func (ps *PeerServer) CampaignHttpHandler(w http.ResponseWriter, req *http.Request) {
+	log.Debugf("[recv] POST %s/campaign", ps.url)
+
+	if err := ps.raftServer.Campaign(); err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// Response to a request for the applied-state hash at a checkpoint
+func (ps *PeerServer) HashHttpHandler(w http.ResponseWriter, req *http.Request) {
+	log.Debugf("[recv] GET %s/hash", ps.url)
+	ps.hashes.serveHTTP(w, req, ps.name)
+}
+
+// Response to a request for the status of this member
+func (ps *PeerServer) StatusHttpHandler(w http.ResponseWriter, req *http.Request) {
+	log.Debugf("[recv] GET %s/status", ps.url)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ps.Status())
+}
+
 // Response to append entries request
 func (ps *PeerServer) AppendEntriesHttpHandler(w http.ResponseWriter, req *http.Request) {
 	aereq := &raft.AppendEntriesRequest{}
@@ -62,6 +86,9 @@ func (ps *PeerServer) AppendEntriesHttpHandler(w http.ResponseWriter, req *http.
 	ps.serverStats.RecvAppendReq(aereq.LeaderName, int(req.ContentLength))
 
 	resp := ps.raftServer.AppendEntries(aereq)
+	if len(aereq.Entries) > 0 {
+		ps.checkLogSize()
+	}
 
 	if resp == nil {
 		log.Warn("[ae] Error: nil response")
@@ -73,6 +100,7 @@ func (ps *PeerServer) AppendEntriesHttpHandler(w http.ResponseWriter, req *http.
 		log.Debugf("[Append Entry] Step back")
 	}
 
+	setPeerTime(w)
 	if _, err := resp.Encode(w); err != nil {
 		log.Warn("[ae] Error: %v", err)
 		http.Error(w, "", http.StatusInternalServerError)
@@ -158,6 +186,11 @@ func (ps *PeerServer) JoinHttpHandler(w http.ResponseWriter, req *http.Request)
 		return
 	}
 
+	if req.FormValue("dryRun") == "true" {
+		writeMembershipReports(w, ps.CheckMembership([]*membershipChange{joinChange(command)}), false)
+		return
+	}
+
 	log.Debugf("Receive Join Request from %s", command.Name)
 	err = ps.server.Dispatch(command, w, req)
 
@@ -184,6 +217,12 @@ func (ps *PeerServer) RemoveHttpHandler(w http.ResponseWriter, req *http.Request
 		Name: vars["name"],
 	}
 
+	if req.FormValue("dryRun") == "true" {
+		change := &membershipChange{Action: "remove", Name: command.Name}
+		writeMembershipReports(w, ps.CheckMembership([]*membershipChange{change}), false)
+		return
+	}
+
 	log.Debugf("[recv] Remove Request [%s]", command.Name)
 
 	ps.server.Dispatch(command, w, req)
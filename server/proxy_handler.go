@@ -0,0 +1,106 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// proxyHandler rewrites the remote address of requests that arrive through a
+// trusted proxy so that everything downstream sees the real client address.
+type proxyHandler struct {
+	handler http.Handler
+	trusted []*net.IPNet
+}
+
+// TrustProxies sets the list of CIDRs whose X-Forwarded-For and X-Real-IP
+// headers are honored.
+func (h *proxyHandler) TrustProxies(cidrs []string) error {
+	trusted, err := parseCIDRs(cidrs)
+	if err != nil {
+		return fmt.Errorf("Invalid trusted proxy: %s", err)
+	}
+	h.trusted = trusted
+
+	return nil
+}
+
+// parseCIDRs parses a list of CIDRs. A bare IP address is a single host network.
+func parseCIDRs(cidrs []string) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, v := range cidrs {
+		if !strings.Contains(v, "/") {
+			if ip := net.ParseIP(v); ip != nil && ip.To4() != nil {
+				v += "/32"
+			} else {
+				v += "/128"
+			}
+		}
+
+		_, ipnet, err := net.ParseCIDR(v)
+		if err != nil {
+			return nil, err
+		}
+		nets = append(nets, ipnet)
+	}
+	return nets, nil
+}
+
+// ProxyTrusted determines whether the given IP address is a trusted proxy.
+func (h *proxyHandler) ProxyTrusted(ip net.IP) bool {
+	for _, ipnet := range h.trusted {
+		if ipnet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP returns the address of the client that originated the request.
+// Forwarding headers are only used when the request came from a trusted proxy
+// and X-Forwarded-For is walked from the right, stopping at the first hop that
+// is not a trusted proxy.
+func (h *proxyHandler) ClientIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+	if ip := net.ParseIP(host); ip == nil || !h.ProxyTrusted(ip) {
+		return host
+	}
+
+	if xff := req.Header.Get("X-Forwarded-For"); xff != "" {
+		hops := strings.Split(xff, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			ip := net.ParseIP(strings.TrimSpace(hops[i]))
+			if ip == nil {
+				break
+			}
+			host = ip.String()
+			if !h.ProxyTrusted(ip) {
+				break
+			}
+		}
+		return host
+	}
+
+	if ip := net.ParseIP(strings.TrimSpace(req.Header.Get("X-Real-IP"))); ip != nil {
+		return ip.String()
+	}
+	return host
+}
+
+// ServeHTTP replaces the host of the request's remote address with the client
+// IP and passes the request on.
+func (h *proxyHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if len(h.trusted) > 0 {
+		_, port, err := net.SplitHostPort(req.RemoteAddr)
+		if err != nil {
+			port = "0"
+		}
+		req.RemoteAddr = net.JoinHostPort(h.ClientIP(req), port)
+	}
+
+	h.handler.ServeHTTP(w, req)
+}
@@ -0,0 +1,52 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newProxyRequest(remoteAddr string, xff string, xrealip string) *http.Request {
+	req, _ := http.NewRequest("GET", "/v2/keys/foo", nil)
+	req.RemoteAddr = remoteAddr
+	if xff != "" {
+		req.Header.Set("X-Forwarded-For", xff)
+	}
+	if xrealip != "" {
+		req.Header.Set("X-Real-IP", xrealip)
+	}
+	return req
+}
+
+// Ensures that forwarding headers are ignored from untrusted addresses.
+func TestProxyHandlerUntrusted(t *testing.T) {
+	h := &proxyHandler{}
+	assert.Nil(t, h.TrustProxies([]string{"10.0.0.0/8"}), "")
+	assert.Equal(t, h.ClientIP(newProxyRequest("192.168.1.5:1234", "1.2.3.4", "")), "192.168.1.5", "")
+	assert.Equal(t, h.ClientIP(newProxyRequest("192.168.1.5:1234", "", "1.2.3.4")), "192.168.1.5", "")
+}
+
+// Ensures that X-Forwarded-For is walked back to the first untrusted hop.
+func TestProxyHandlerForwardedFor(t *testing.T) {
+	h := &proxyHandler{}
+	assert.Nil(t, h.TrustProxies([]string{"10.0.0.0/8", "172.16.0.1"}), "")
+	assert.Equal(t, h.ClientIP(newProxyRequest("10.0.0.1:1234", "1.2.3.4", "")), "1.2.3.4", "")
+	assert.Equal(t, h.ClientIP(newProxyRequest("10.0.0.1:1234", "6.6.6.6, 1.2.3.4, 172.16.0.1", "")), "1.2.3.4", "")
+	assert.Equal(t, h.ClientIP(newProxyRequest("10.0.0.1:1234", "10.1.1.1", "")), "10.1.1.1", "")
+}
+
+// Ensures that X-Real-IP is used when there is no X-Forwarded-For header.
+func TestProxyHandlerRealIP(t *testing.T) {
+	h := &proxyHandler{}
+	assert.Nil(t, h.TrustProxies([]string{"10.0.0.0/8"}), "")
+	assert.Equal(t, h.ClientIP(newProxyRequest("10.0.0.1:1234", "", "1.2.3.4")), "1.2.3.4", "")
+	assert.Equal(t, h.ClientIP(newProxyRequest("10.0.0.1:1234", "", "")), "10.0.0.1", "")
+}
+
+// Ensures that invalid CIDRs are rejected.
+func TestProxyHandlerInvalidCIDR(t *testing.T) {
+	h := &proxyHandler{}
+	assert.Error(t, h.TrustProxies([]string{"10.0.0.0/99"}))
+	assert.Error(t, h.TrustProxies([]string{"not-an-ip"}))
+}
@@ -0,0 +1,278 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	etcdErr "github.com/coreos/etcd/error"
+	"github.com/coreos/etcd/store"
+	"github.com/gorilla/mux"
+)
+
+// userQuota holds the limits of a client named by its certificate and what
+// it currently uses of them. Zero means no limit.
+type userQuota struct {
+	sync.Mutex
+	maxWatches           int
+	maxRequestsPerSecond int
+	maxBytes             int64
+
+	watches  int
+	requests int
+	bytes    int64
+	rejected uint64
+
+	// The second the requests are counted in.
+	window time.Time
+}
+
+// quotaUsage is the quota of a client along with its current usage.
+type quotaUsage struct {
+	Name                 string `json:"name"`
+	MaxWatches           int    `json:"maxWatches,omitempty"`
+	MaxRequestsPerSecond int    `json:"maxRequestsPerSecond,omitempty"`
+	MaxBytes             int64  `json:"maxBytes,omitempty"`
+	Watches              int    `json:"watches"`
+	Requests             int    `json:"requests"`
+	Bytes                int64  `json:"bytes"`
+	Rejected             uint64 `json:"rejected"`
+}
+
+// allowRequest counts a request in the current second unless the rate is
+// already reached.
+func (q *userQuota) allowRequest(now time.Time) bool {
+	q.Lock()
+	defer q.Unlock()
+	if now.Sub(q.window) >= time.Second {
+		q.window = now
+		q.requests = 0
+	}
+	if q.maxRequestsPerSecond > 0 && q.requests >= q.maxRequestsPerSecond {
+		q.rejected++
+		return false
+	}
+	q.requests++
+	return true
+}
+
+// enterWatch admits a watch unless maxWatches are already open. The returned
+// function must be called once the watch is done.
+func (q *userQuota) enterWatch() (func(), bool) {
+	q.Lock()
+	defer q.Unlock()
+	if q.maxWatches > 0 && q.watches >= q.maxWatches {
+		q.rejected++
+		return nil, false
+	}
+	q.watches++
+	return func() {
+		q.Lock()
+		q.watches--
+		q.Unlock()
+	}, true
+}
+
+// SetUserQuotas sets the quotas of clients named by their certificate, each
+// given as a list of name=limit pairs: the number of watches open at once,
+// the number of requests per second and the number of bytes stored under
+// the prefixes the write rules give the client.
+func (s *Server) SetUserQuotas(watches, rates, bytes []string) error {
+	quotas := make(map[string]*userQuota)
+	quota := func(name string) *userQuota {
+		if quotas[name] == nil {
+			quotas[name] = &userQuota{}
+		}
+		return quotas[name]
+	}
+
+	for _, l := range []struct {
+		rules []string
+		set   func(q *userQuota, n int64)
+	}{
+		{watches, func(q *userQuota, n int64) { q.maxWatches = int(n) }},
+		{rates, func(q *userQuota, n int64) { q.maxRequestsPerSecond = int(n) }},
+		{bytes, func(q *userQuota, n int64) { q.maxBytes = n }},
+	} {
+		for _, r := range l.rules {
+			kv := strings.SplitN(r, "=", 2)
+			if len(kv) != 2 || kv[0] == "" {
+				return fmt.Errorf("Invalid quota: %s", r)
+			}
+			n, err := strconv.ParseInt(kv[1], 10, 64)
+			if err != nil || n <= 0 {
+				return fmt.Errorf("Invalid quota: %s", r)
+			}
+			l.set(quota(kv[0]), n)
+		}
+	}
+	s.quotas = quotas
+	return nil
+}
+
+// ownedPrefixes returns the prefixes the write rules let the named client
+// write.
+func (s *Server) ownedPrefixes(name string) []string {
+	var prefixes []string
+	for _, r := range s.writeRules {
+		if r.name == name {
+			prefixes = append(prefixes, r.prefix)
+		}
+	}
+	return prefixes
+}
+
+// storedBytes adds up the size of the keys and values under the prefixes the
+// named client owns.
+func (s *Server) storedBytes(name string) int64 {
+	var n int64
+	for _, prefix := range s.ownedPrefixes(name) {
+		if e, err := s.store.Get(prefix, true, false); err == nil {
+			n += nodeBytes(e.Node)
+		}
+	}
+	return n
+}
+
+func nodeBytes(n *store.NodeExtern) int64 {
+	size := int64(len(n.Key) + len(n.Value))
+	for i := range n.Nodes {
+		size += nodeBytes(&n.Nodes[i])
+	}
+	return size
+}
+
+// checkBytes rejects a write that would take the named client past its
+// stored bytes quota. The value the write replaces is not counted.
+func (s *Server) checkBytes(q *userQuota, name string, key string, value string) bool {
+	if q.maxBytes <= 0 || len(s.ownedPrefixes(name)) == 0 {
+		return true
+	}
+	used := s.storedBytes(name)
+	if e, err := s.store.Get(key, false, false); err == nil && !e.Node.Dir {
+		used -= nodeBytes(e.Node)
+	}
+
+	q.Lock()
+	defer q.Unlock()
+	q.bytes = used
+	if used+int64(len(key)+len(value)) > q.maxBytes {
+		q.rejected++
+		return false
+	}
+	return true
+}
+
+// admitUser checks a request against the quota of the client that sent it
+// and returns the function that releases it. Clients without a quota are
+// always admitted.
+func (s *Server) admitUser(req *http.Request) (func(), error) {
+	name := clientName(req)
+	q := s.quotas[name]
+	if q == nil {
+		return func() {}, nil
+	}
+	if !q.allowRequest(time.Now()) {
+		return nil, etcdErr.NewError(etcdErr.EcodeQuotaExceeded, "requests per second", s.store.Index())
+	}
+
+	if isWatchRequest(req) {
+		done, ok := q.enterWatch()
+		if !ok {
+			return nil, etcdErr.NewError(etcdErr.EcodeQuotaExceeded, "watches", s.store.Index())
+		}
+		return done, nil
+	}
+
+	key, ok := mux.Vars(req)["key"]
+	if !ok {
+		return func() {}, nil
+	}
+	key = "/" + key
+
+	if (req.Method == "PUT" || req.Method == "POST") && !s.checkBytes(q, name, key, req.FormValue("value")) {
+		return nil, etcdErr.NewError(etcdErr.EcodeQuotaExceeded, "bytes", s.store.Index())
+	}
+	return func() {}, nil
+}
+
+// Rejects requests past the quota of the client that sent them.
+func (s *Server) checkQuota(f func(http.ResponseWriter, *http.Request) error) func(http.ResponseWriter, *http.Request) error {
+	return func(w http.ResponseWriter, req *http.Request) error {
+		done, err := s.admitUser(req)
+		if err != nil {
+			return err
+		}
+		defer done()
+		return f(w, req)
+	}
+}
+
+// Rejects module requests past the requests per second quota of the client
+// that sent them.
+func (s *Server) checkModQuota(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if q := s.quotas[clientName(req)]; q != nil && !q.allowRequest(time.Now()) {
+			w.Header().Set("Content-Type", "application/json")
+			etcdErr.NewError(etcdErr.EcodeQuotaExceeded, "requests per second", s.store.Index()).Write(w)
+			return
+		}
+		h.ServeHTTP(w, req)
+	})
+}
+
+// usage returns the quota of the named client along with its current usage,
+// refreshing the stored bytes.
+func (s *Server) usage(name string) *quotaUsage {
+	q := s.quotas[name]
+	bytes := s.storedBytes(name)
+
+	q.Lock()
+	defer q.Unlock()
+	q.bytes = bytes
+	if time.Since(q.window) >= time.Second {
+		q.requests = 0
+	}
+	return &quotaUsage{
+		Name:                 name,
+		MaxWatches:           q.maxWatches,
+		MaxRequestsPerSecond: q.maxRequestsPerSecond,
+		MaxBytes:             q.maxBytes,
+		Watches:              q.watches,
+		Requests:             q.requests,
+		Bytes:                q.bytes,
+		Rejected:             q.rejected,
+	}
+}
+
+// Retrieves the quota and usage of every client with a quota.
+func (s *Server) GetQuotasHandler(w http.ResponseWriter, req *http.Request) error {
+	names := make([]string, 0, len(s.quotas))
+	for name := range s.quotas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	usage := make([]*quotaUsage, 0, len(names))
+	for _, name := range names {
+		usage = append(usage, s.usage(name))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(usage)
+}
+
+// Retrieves the quota and usage of the client sending the request.
+func (s *Server) GetQuotaHandler(w http.ResponseWriter, req *http.Request) error {
+	name := clientName(req)
+	if s.quotas[name] == nil {
+		http.Error(w, "No quota", http.StatusNotFound)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(s.usage(name))
+}
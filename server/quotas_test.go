@@ -0,0 +1,59 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/coreos/etcd/store"
+	"github.com/stretchr/testify/assert"
+)
+
+// Ensures that quotas are parsed by client name and invalid ones refused.
+func TestSetUserQuotas(t *testing.T) {
+	s := &Server{}
+	assert.Nil(t, s.SetUserQuotas([]string{"web=2"}, []string{"web=100", "cron=10"}, []string{"cron=1024"}), "")
+	assert.Equal(t, s.quotas["web"].maxWatches, 2, "")
+	assert.Equal(t, s.quotas["web"].maxRequestsPerSecond, 100, "")
+	assert.Equal(t, s.quotas["cron"].maxRequestsPerSecond, 10, "")
+	assert.Equal(t, s.quotas["cron"].maxBytes, int64(1024), "")
+
+	assert.Error(t, s.SetUserQuotas([]string{"web"}, nil, nil))
+	assert.Error(t, s.SetUserQuotas(nil, []string{"web=0"}, nil))
+	assert.Error(t, s.SetUserQuotas(nil, nil, []string{"=10"}))
+}
+
+// Ensures that requests are limited per second and watches while they are open.
+func TestUserQuotaLimits(t *testing.T) {
+	q := &userQuota{maxWatches: 1, maxRequestsPerSecond: 2}
+	now := time.Now()
+	assert.True(t, q.allowRequest(now), "")
+	assert.True(t, q.allowRequest(now), "")
+	assert.False(t, q.allowRequest(now.Add(500*time.Millisecond)), "")
+	assert.True(t, q.allowRequest(now.Add(time.Second)), "")
+
+	done, ok := q.enterWatch()
+	assert.True(t, ok, "")
+	_, ok = q.enterWatch()
+	assert.False(t, ok, "")
+	done()
+	_, ok = q.enterWatch()
+	assert.True(t, ok, "")
+	assert.Equal(t, q.rejected, uint64(2), "")
+}
+
+// Ensures that the stored bytes quota counts the prefixes a client owns and
+// not the value a write replaces.
+func TestUserQuotaBytes(t *testing.T) {
+	s := &Server{store: store.New()}
+	assert.Nil(t, s.AllowWriters([]string{"/web=web"}), "")
+	assert.Nil(t, s.SetUserQuotas(nil, nil, []string{"web=30"}), "")
+	q := s.quotas["web"]
+
+	s.store.Set("/web/a", false, "1234", store.Permanent)
+	s.store.Set("/other", false, "12345678901234567890", store.Permanent)
+	assert.Equal(t, s.storedBytes("web"), int64(len("/web")+len("/web/a")+4), "")
+
+	assert.True(t, s.checkBytes(q, "web", "/web/b", "123456789"), "")
+	assert.False(t, s.checkBytes(q, "web", "/web/b", "12345678901"), "")
+	assert.True(t, s.checkBytes(q, "web", "/web/a", "12345678901234567890"), "")
+}
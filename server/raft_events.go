@@ -0,0 +1,147 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	etcdErr "github.com/coreos/etcd/error"
+	"github.com/coreos/etcd/lifetime"
+	"github.com/coreos/raft"
+)
+
+// The number of events buffered for a stream before it is dropped.
+const raftEventQueueSize = 64
+
+// raftEvent is a raft event as it is sent to the streams.
+type raftEvent struct {
+	Type      string      `json:"type"`
+	Value     interface{} `json:"value,omitempty"`
+	PrevValue interface{} `json:"prevValue,omitempty"`
+	Term      uint64      `json:"term"`
+	Time      time.Time   `json:"time"`
+}
+
+// raftEventStreams fans the events of the raft server out to the clients
+// streaming them.
+type raftEventStreams struct {
+	sync.Mutex
+	streams map[chan *raftEvent]bool
+}
+
+func newRaftEventStreams() *raftEventStreams {
+	return &raftEventStreams{streams: make(map[chan *raftEvent]bool)}
+}
+
+// publish sends an event to every stream. It is called by the raft server
+// and must not block, so a stream that is not keeping up is closed.
+func (rs *raftEventStreams) publish(e *raftEvent) {
+	rs.Lock()
+	defer rs.Unlock()
+	for c := range rs.streams {
+		select {
+		case c <- e:
+		default:
+			delete(rs.streams, c)
+			close(c)
+		}
+	}
+}
+
+// subscribe returns a new stream and a function that stops it.
+func (rs *raftEventStreams) subscribe() (<-chan *raftEvent, func()) {
+	rs.Lock()
+	defer rs.Unlock()
+	c := make(chan *raftEvent, raftEventQueueSize)
+	rs.streams[c] = true
+	return c, func() {
+		rs.Lock()
+		defer rs.Unlock()
+		if rs.streams[c] {
+			delete(rs.streams, c)
+			close(c)
+		}
+	}
+}
+
+// watchRaftEvents publishes the events of the raft server.
+func (s *PeerServer) watchRaftEvents() {
+	s.raftServer.AddEventListener(func(e raft.Event) {
+		// Every write syncs the log; those are in /v2/stats/disk instead.
+		if e.Type == raft.LogSyncEventType {
+			return
+		}
+		s.raftEvents.publish(&raftEvent{
+			Type:      e.Type,
+			Value:     e.Value,
+			PrevValue: e.PrevValue,
+			Term:      s.raftServer.Term(),
+			Time:      time.Now(),
+		})
+	})
+}
+
+// Streams the raft events of this member as they happen, one JSON object per
+// line: state, leader and term changes, peers joining and leaving, snapshots
+// and corrupted log entries. The types parameter is a comma-separated list
+// of the event types to send; every type is sent without it. A client that
+// does not keep up is disconnected.
+func (s *Server) GetRaftEventsHandler(w http.ResponseWriter, req *http.Request) error {
+	var types map[string]bool
+	if v := req.FormValue("types"); v != "" {
+		types = make(map[string]bool)
+		for _, t := range strings.Split(v, ",") {
+			if !validRaftEventType(t) {
+				return etcdErr.NewError(etcdErr.EcodeInvalidField, "Types", s.Store().Index())
+			}
+			types[t] = true
+		}
+	}
+
+	events, stop := s.peerServer.raftEvents.subscribe()
+	defer stop()
+
+	l := lifetime.Start(w, -1)
+	defer l.Stop()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	f, _ := w.(http.Flusher)
+	if f != nil {
+		f.Flush()
+	}
+
+	enc := json.NewEncoder(w)
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if types != nil && !types[e.Type] {
+				continue
+			}
+			if err := enc.Encode(e); err != nil {
+				return nil
+			}
+			if f != nil {
+				f.Flush()
+			}
+		case <-l.Done():
+			return nil
+		}
+	}
+}
+
+// validRaftEventType checks whether t is an event type the raft server sends.
+func validRaftEventType(t string) bool {
+	switch t {
+	case raft.StateChangeEventType, raft.LeaderChangeEventType, raft.TermChangeEventType,
+		raft.AddPeerEventType, raft.RemovePeerEventType,
+		raft.SnapshotEventType, raft.SnapshotRecoveryEventType, raft.CorruptionEventType:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,29 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Ensures that events reach every stream and that a stream which falls behind is closed.
+func TestRaftEventStreams(t *testing.T) {
+	rs := newRaftEventStreams()
+	fast, stopFast := rs.subscribe()
+	slow, stopSlow := rs.subscribe()
+	defer stopFast()
+	defer stopSlow()
+
+	for i := 0; i <= raftEventQueueSize; i++ {
+		rs.publish(&raftEvent{Type: "termChange", Value: uint64(i)})
+		e := <-fast
+		assert.Equal(t, e.Value, uint64(i))
+	}
+
+	for i := 0; i < raftEventQueueSize; i++ {
+		_, ok := <-slow
+		assert.True(t, ok)
+	}
+	_, ok := <-slow
+	assert.False(t, ok, "the slow stream should be closed")
+}
@@ -24,6 +24,13 @@ type raftFollowerStats struct {
 		Fail    uint64 `json:"fail"`
 		Success uint64 `json:"success"`
 	} `json:"counts"`
+
+	// How far ahead of the leader's clock the follower's clock is, in
+	// milliseconds.
+	ClockSkew struct {
+		Current float64 `json:"current"`
+		Skewed  bool    `json:"skewed"`
+	} `json:"clockSkew"`
 }
 
 // Succ function update the raftFollowerStats with a successful send
@@ -25,8 +25,23 @@ type raftServerStats struct {
 	SendingPkgRate       float64 `json:"sendPkgRate,omitempty"`
 	SendingBandwidthRate float64 `json:"sendBandwidthRate,omitempty"`
 
+	// How long replaying the log took when the member started.
+	Replay *raft.ReplayStats `json:"replay,omitempty"`
+
+	// The randomized election timeout chosen last and the window it was
+	// chosen from, in milliseconds.
+	ElectionTimeout struct {
+		Term    uint64  `json:"term"`
+		Min     float64 `json:"min"`
+		Max     float64 `json:"max"`
+		Current float64 `json:"current"`
+	} `json:"electionTimeout"`
+
 	sendRateQueue *statsQueue
 	recvRateQueue *statsQueue
+
+	// When the last append entries request from a leader arrived.
+	lastRecvAppend time.Time
 }
 
 func (ss *raftServerStats) RecvAppendReq(leaderName string, pkgSize int) {
@@ -36,8 +51,10 @@ func (ss *raftServerStats) RecvAppendReq(leaderName string, pkgSize int) {
 		ss.LeaderInfo.startTime = time.Now()
 	}
 
-	ss.recvRateQueue.Insert(NewPackageStats(time.Now(), pkgSize))
+	now := time.Now()
+	ss.recvRateQueue.Insert(NewPackageStats(now, pkgSize))
 	ss.RecvAppendRequestCnt++
+	ss.lastRecvAppend = now
 }
 
 func (ss *raftServerStats) SendAppendReq(pkgSize int) {
@@ -53,3 +70,10 @@ func (ss *raftServerStats) SendAppendReq(pkgSize int) {
 
 	ss.SendAppendRequestCnt++
 }
+
+func (ss *raftServerStats) setElectionTimeout(e raft.ElectionTimeoutStats) {
+	ss.ElectionTimeout.Term = e.Term
+	ss.ElectionTimeout.Min = float64(e.Min) / float64(time.Millisecond)
+	ss.ElectionTimeout.Max = float64(e.Max) / float64(time.Millisecond)
+	ss.ElectionTimeout.Current = float64(e.Current) / float64(time.Millisecond)
+}
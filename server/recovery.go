@@ -0,0 +1,196 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/coreos/etcd/log"
+	"github.com/coreos/etcd/store"
+	"github.com/coreos/raft"
+)
+
+// How often the recovery progress is logged while a member starts.
+const recoveryLogInterval = 5 * time.Second
+
+// The recovery phases a member goes through before serving clients.
+const (
+	recoveryStarting = "starting"
+	recoverySnapshot = "snapshot"
+	recoveryReplay   = "replay"
+)
+
+// recoveryProgress describes how far a member is in loading its data
+// directory.
+type recoveryProgress struct {
+	Phase    string  `json:"phase"`
+	Replayed uint64  `json:"replayed"`
+	Total    uint64  `json:"total"`
+	Percent  float64 `json:"percent"`
+	ETA      string  `json:"eta,omitempty"`
+	Elapsed  string  `json:"elapsed"`
+}
+
+// recoveryStatus tracks the snapshot load and the log replay at startup.
+type recoveryStatus struct {
+	mutex sync.Mutex
+	phase string
+	done  bool
+	start time.Time
+
+	// The replay start and the first and last index applied during it.
+	replayStart time.Time
+	first       uint64
+	applied     uint64
+
+	// The commit index the replay goes up to.
+	target func() uint64
+}
+
+func newRecoveryStatus(target func() uint64) *recoveryStatus {
+	return &recoveryStatus{phase: recoveryStarting, start: time.Now(), target: target}
+}
+
+func (r *recoveryStatus) begin(phase string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.phase = phase
+	if phase == recoveryReplay {
+		r.replayStart = time.Now()
+	}
+}
+
+// apply records that the entry at the given index was applied.
+func (r *recoveryStatus) apply(index uint64) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.done || r.phase != recoveryReplay {
+		return
+	}
+	if r.first == 0 {
+		r.first = index
+	}
+	r.applied = index
+}
+
+func (r *recoveryStatus) finish() {
+	r.mutex.Lock()
+	r.done = true
+	r.mutex.Unlock()
+	log.Infof("[recovery] ready after %v", time.Now().Sub(r.start))
+}
+
+// recovered reports whether the member is done loading its data directory.
+func (r *recoveryStatus) recovered() bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.done
+}
+
+// progress returns how far the recovery is. The ETA is extrapolated from
+// the replay rate so far.
+func (r *recoveryStatus) progress() *recoveryProgress {
+	r.mutex.Lock()
+	phase, start, replayStart, first, applied := r.phase, r.start, r.replayStart, r.first, r.applied
+	r.mutex.Unlock()
+
+	p := &recoveryProgress{
+		Phase:   phase,
+		Elapsed: time.Now().Sub(start).String(),
+	}
+	if phase != recoveryReplay {
+		return p
+	}
+
+	// Read outside of the lock: raft holds its log lock while applying.
+	target := r.target()
+	if first == 0 || target < first {
+		return p
+	}
+	p.Total = target - first + 1
+	if applied >= first {
+		p.Replayed = applied - first + 1
+	}
+	p.Percent = math.Floor(float64(p.Replayed)*1000/float64(p.Total)) / 10
+
+	if p.Replayed > 0 && p.Replayed < p.Total {
+		elapsed := time.Now().Sub(replayStart)
+		eta := time.Duration(float64(elapsed) * float64(p.Total-p.Replayed) / float64(p.Replayed))
+		p.ETA = (eta / time.Second * time.Second).String()
+	}
+	return p
+}
+
+// retryAfter returns the number of seconds clients should wait before
+// trying again.
+func (p *recoveryProgress) retryAfter() int {
+	eta, err := time.ParseDuration(p.ETA)
+	if err != nil || eta < time.Second {
+		return 1
+	}
+	return int(eta / time.Second)
+}
+
+func (p *recoveryProgress) String() string {
+	if p.Phase != recoveryReplay || p.Total == 0 {
+		return fmt.Sprintf("%s, elapsed %s", p.Phase, p.Elapsed)
+	}
+	s := fmt.Sprintf("replayed %d/%d entries (%.1f%%), elapsed %s", p.Replayed, p.Total, p.Percent, p.Elapsed)
+	if p.ETA != "" {
+		s += ", eta " + p.ETA
+	}
+	return s
+}
+
+// monitor logs the progress periodically until the recovery is done.
+func (r *recoveryStatus) monitor() {
+	for {
+		time.Sleep(recoveryLogInterval)
+		if r.recovered() {
+			return
+		}
+		log.Infof("[recovery] %v", r.progress())
+	}
+}
+
+// recoveryStore hands the store to raft and follows the replay through the
+// position of each entry applied.
+type recoveryStore struct {
+	store.Store
+	recovery *recoveryStatus
+}
+
+func (s *recoveryStore) SetPosition(term uint64, index uint64) {
+	if p, ok := s.Store.(raft.PositionedStateMachine); ok {
+		p.SetPosition(term, index)
+	}
+	s.recovery.apply(index)
+}
+
+// Refuses client requests with a 503 until the member is done loading its
+// data directory, since its store is still behind.
+func (s *Server) checkRecovered(f func(http.ResponseWriter, *http.Request) error) func(http.ResponseWriter, *http.Request) error {
+	return func(w http.ResponseWriter, req *http.Request) error {
+		r := s.peerServer.recovery
+		if !r.recovered() {
+			p := r.progress()
+			w.Header().Set("Retry-After", fmt.Sprint(p.retryAfter()))
+			http.Error(w, "Recovering: "+p.String(), http.StatusServiceUnavailable)
+			return nil
+		}
+		return f(w, req)
+	}
+}
+
+// Refuses the requests to a handler until the member is recovered.
+func (s *Server) serveRecovered(h http.Handler) http.HandlerFunc {
+	f := s.checkRecovered(func(w http.ResponseWriter, req *http.Request) error {
+		h.ServeHTTP(w, req)
+		return nil
+	})
+	return func(w http.ResponseWriter, req *http.Request) {
+		f(w, req)
+	}
+}
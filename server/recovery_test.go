@@ -0,0 +1,51 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Ensures that the replay progress is computed from the entries applied.
+func TestRecoveryProgress(t *testing.T) {
+	r := newRecoveryStatus(func() uint64 { return 200 })
+	assert.Equal(t, r.progress().Phase, recoveryStarting, "")
+
+	// Entries applied before the replay are not counted.
+	r.apply(5)
+	r.begin(recoveryReplay)
+	assert.Equal(t, r.progress().Total, uint64(0), "")
+
+	r.apply(101)
+	r.apply(150)
+	p := r.progress()
+	assert.Equal(t, p.Replayed, uint64(50), "")
+	assert.Equal(t, p.Total, uint64(100), "")
+	assert.Equal(t, p.Percent, 50.0, "")
+	assert.False(t, r.recovered(), "")
+
+	r.finish()
+	assert.True(t, r.recovered(), "")
+}
+
+// Ensures that client requests are refused until the member is recovered.
+func TestCheckRecovered(t *testing.T) {
+	s := &Server{peerServer: &PeerServer{recovery: newRecoveryStatus(func() uint64 { return 0 })}}
+	f := s.checkRecovered(func(w http.ResponseWriter, req *http.Request) error {
+		w.WriteHeader(http.StatusOK)
+		return nil
+	})
+
+	req, _ := http.NewRequest("GET", "/v2/keys/foo", nil)
+	w := httptest.NewRecorder()
+	f(w, req)
+	assert.Equal(t, w.Code, http.StatusServiceUnavailable, "")
+	assert.Equal(t, w.Header().Get("Retry-After"), "1", "")
+
+	s.peerServer.recovery.finish()
+	w = httptest.NewRecorder()
+	f(w, req)
+	assert.Equal(t, w.Code, http.StatusOK, "")
+}
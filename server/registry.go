@@ -27,8 +27,21 @@ type node struct {
 	peerVersion string
 	peerURL     string
 	url         string
+	tags        map[string]string
 }
 
+// Member describes a node in the registry as exposed by the members API.
+type Member struct {
+	Name      string            `json:"name"`
+	ClientURL string            `json:"clientURL"`
+	PeerURL   string            `json:"peerURL"`
+	Tags      map[string]string `json:"tags,omitempty"`
+	Observer  bool              `json:"observer,omitempty"`
+}
+
+// Tag keys are stored next to the URLs in the registry entry with this prefix.
+const tagPrefix = "tag."
+
 // Creates a new Registry.
 func NewRegistry(s store.Store) *Registry {
 	return &Registry{
@@ -38,18 +51,63 @@ func NewRegistry(s store.Store) *Registry {
 }
 
 // Adds a node to the registry.
-func (r *Registry) Register(name string, peerURL string, url string) error {
+func (r *Registry) Register(name string, peerURL string, url string, tags map[string]string) error {
 	r.Lock()
 	defer r.Unlock()
 
 	// Write data to store.
 	key := path.Join(RegistryKey, name)
-	value := fmt.Sprintf("raft=%s&etcd=%s", peerURL, url)
+	value := fmt.Sprintf("raft=%s&etcd=%s", peerURL, url) + encodeTags(tags)
 	_, err := r.store.Create(key, false, value, false, store.Permanent)
 	log.Debugf("Register: %s", name)
 	return err
 }
 
+// Replaces the tags of a registered node.
+func (r *Registry) SetTags(name string, tags map[string]string) error {
+	r.Lock()
+	defer r.Unlock()
+
+	peerURL, ok := r.peerURL(name)
+	if !ok {
+		return fmt.Errorf("Unknown peer: %s", name)
+	}
+	etcdURL, _ := r.clientURL(name)
+
+	key := path.Join(RegistryKey, name)
+	value := fmt.Sprintf("raft=%s&etcd=%s", peerURL, etcdURL) + encodeTags(tags)
+	_, err := r.store.Update(key, value, store.Permanent)
+	delete(r.nodes, name)
+	log.Debugf("SetTags: %s", name)
+	return err
+}
+
+// Replaces the peer and client URLs of a registered node. Empty URLs are
+// left unchanged.
+func (r *Registry) SetURLs(name string, peerURL string, url string) error {
+	r.Lock()
+	defer r.Unlock()
+
+	oldPeerURL, ok := r.peerURL(name)
+	if !ok {
+		return fmt.Errorf("Unknown peer: %s", name)
+	}
+	if peerURL == "" {
+		peerURL = oldPeerURL
+	}
+	if url == "" {
+		url, _ = r.clientURL(name)
+	}
+	tags, _ := r.tags(name)
+
+	key := path.Join(RegistryKey, name)
+	value := fmt.Sprintf("raft=%s&etcd=%s", peerURL, url) + encodeTags(tags)
+	_, err := r.store.Update(key, value, store.Permanent)
+	delete(r.nodes, name)
+	log.Debugf("SetURLs: %s", name)
+	return err
+}
+
 // Removes a node from the registry.
 func (r *Registry) Unregister(name string) error {
 	r.Lock()
@@ -111,6 +169,64 @@ func (r *Registry) peerURL(name string) (string, bool) {
 	return "", false
 }
 
+// Retrieves the tags for a given node by name.
+func (r *Registry) Tags(name string) (map[string]string, bool) {
+	r.Lock()
+	defer r.Unlock()
+	return r.tags(name)
+}
+
+func (r *Registry) tags(name string) (map[string]string, bool) {
+	if r.nodes[name] == nil {
+		r.load(name)
+	}
+
+	if node := r.nodes[name]; node != nil {
+		return node.tags, true
+	}
+
+	return nil, false
+}
+
+// Retrieves the names of all nodes.
+func (r *Registry) Names() []string {
+	e, err := r.store.Get(RegistryKey, false, true)
+	if err != nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(e.Node.Nodes))
+	for _, pair := range e.Node.Nodes {
+		_, name := filepath.Split(pair.Key)
+		names = append(names, name)
+	}
+	return names
+}
+
+// Retrieves the URLs and tags of all nodes, sorted by name.
+func (r *Registry) Members() []*Member {
+	names := r.Names()
+
+	r.Lock()
+	defer r.Unlock()
+
+	members := make([]*Member, 0, len(names))
+	for _, name := range names {
+		if r.nodes[name] == nil {
+			r.load(name)
+		}
+		if node := r.nodes[name]; node != nil {
+			members = append(members, &Member{
+				Name:      name,
+				ClientURL: node.url,
+				PeerURL:   node.peerURL,
+				Tags:      node.tags,
+			})
+		}
+	}
+	return members
+}
+
 // Retrieves the Client URLs for all nodes.
 func (r *Registry) ClientURLs(leaderName, selfName string) []string {
 	return r.urls(leaderName, selfName, r.clientURL)
@@ -171,9 +287,33 @@ func (r *Registry) load(name string) {
 		panic(fmt.Sprintf("Failed to parse peers entry: %s", name))
 	}
 
+	// Collect the tags.
+	var tags map[string]string
+	for k, v := range m {
+		if strings.HasPrefix(k, tagPrefix) {
+			if tags == nil {
+				tags = make(map[string]string)
+			}
+			tags[strings.TrimPrefix(k, tagPrefix)] = v[0]
+		}
+	}
+
 	// Create node.
 	r.nodes[name] = &node{
 		url:     m["etcd"][0],
 		peerURL: m["raft"][0],
+		tags:    tags,
+	}
+}
+
+// Encodes tags as extra query string parameters for a registry entry.
+func encodeTags(tags map[string]string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	v := url.Values{}
+	for k, t := range tags {
+		v.Set(tagPrefix+k, t)
 	}
+	return "&" + v.Encode()
 }
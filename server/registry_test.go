@@ -0,0 +1,46 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/coreos/etcd/store"
+	"github.com/stretchr/testify/assert"
+)
+
+// Ensures that member tags are stored with the registry entry and read back.
+func TestRegistryTags(t *testing.T) {
+	r := NewRegistry(store.New())
+	assert.Nil(t, r.Register("node1", "http://127.0.0.1:7001", "http://127.0.0.1:4001", map[string]string{"zone": "us-east-1a", "rack": "r 12"}), "")
+	assert.Nil(t, r.Register("node2", "http://127.0.0.1:7002", "http://127.0.0.1:4002", nil), "")
+
+	tags, ok := r.Tags("node1")
+	assert.True(t, ok, "")
+	assert.Equal(t, tags, map[string]string{"zone": "us-east-1a", "rack": "r 12"}, "")
+	url, _ := r.ClientURL("node1")
+	assert.Equal(t, url, "http://127.0.0.1:4001", "")
+
+	tags, ok = r.Tags("node2")
+	assert.True(t, ok, "")
+	assert.Nil(t, tags, "")
+
+	members := r.Members()
+	assert.Equal(t, len(members), 2, "")
+	assert.Equal(t, members[0].Name, "node1", "")
+	assert.Equal(t, members[0].PeerURL, "http://127.0.0.1:7001", "")
+	assert.Equal(t, members[0].Tags["zone"], "us-east-1a", "")
+	assert.Equal(t, members[1].Name, "node2", "")
+}
+
+// Ensures that the tags of a registered member can be replaced.
+func TestRegistrySetTags(t *testing.T) {
+	r := NewRegistry(store.New())
+	r.Register("node1", "http://127.0.0.1:7001", "http://127.0.0.1:4001", map[string]string{"zone": "a"})
+	assert.Nil(t, r.SetTags("node1", map[string]string{"zone": "b"}), "")
+
+	tags, _ := r.Tags("node1")
+	assert.Equal(t, tags, map[string]string{"zone": "b"}, "")
+	url, _ := r.PeerURL("node1")
+	assert.Equal(t, url, "http://127.0.0.1:7001", "")
+
+	assert.NotNil(t, r.SetTags("node2", nil), "")
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/coreos/etcd/lifetime"
+	"github.com/coreos/etcd/log"
+)
+
+// requestRecorder keeps the status and the etcd index of a response for the
+// request log.
+type requestRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *requestRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *requestRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// CloseNotify lets watchers notice clients going away through the recorder.
+func (r *requestRecorder) CloseNotify() <-chan bool {
+	return lifetime.CloseNotify(r.ResponseWriter)
+}
+
+func (r *requestRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack lets websocket watches take over the connection. They are logged
+// as switching protocols once they end.
+func (r *requestRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("connection cannot be hijacked")
+	}
+	r.status = http.StatusSwitchingProtocols
+	return h.Hijack()
+}
+
+// Logs every request when the access log is on, and requests slower than
+// SlowRequestThreshold otherwise. Watches and ephemeral writes hold the
+// connection by design and are only written to the access log.
+func (s *Server) logRequest(f func(http.ResponseWriter, *http.Request)) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, req *http.Request) {
+		accessLog := s.AccessLog || s.debug.accessLog()
+		if !accessLog && s.SlowRequestThreshold <= 0 {
+			f(w, req)
+			return
+		}
+
+		start := time.Now()
+		rec := &requestRecorder{ResponseWriter: w}
+		f(rec, req)
+		d := time.Now().Sub(start)
+
+		index := w.Header().Get("X-Etcd-Index")
+		if accessLog {
+			log.Infof("[access] %s %s status=%d latency=%v index=%s remote=%s", req.Method, req.URL.Path, rec.status, d, index, req.RemoteAddr)
+		} else if d > s.SlowRequestThreshold && !isWatchRequest(req) && req.FormValue("ephemeral") != "true" {
+			log.Warnf("[slow] %s %s status=%d latency=%v index=%s remote=%s", req.Method, req.URL.Path, rec.status, d, index, req.RemoteAddr)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Ensures that the request recorder keeps the response status.
+func TestRequestRecorder(t *testing.T) {
+	w := httptest.NewRecorder()
+	rec := &requestRecorder{ResponseWriter: w}
+	rec.Write([]byte("ok"))
+	assert.Equal(t, rec.status, http.StatusOK, "")
+
+	rec = &requestRecorder{ResponseWriter: w}
+	rec.WriteHeader(http.StatusCreated)
+	rec.Write([]byte("ok"))
+	assert.Equal(t, rec.status, http.StatusCreated, "")
+}
+
+// Ensures that logged handlers still see the original response.
+func TestLogRequest(t *testing.T) {
+	s := &Server{SlowRequestThreshold: time.Millisecond}
+	req, _ := http.NewRequest("PUT", "/v2/keys/foo", nil)
+	w := httptest.NewRecorder()
+	s.logRequest(func(w http.ResponseWriter, req *http.Request) {
+		_, ok := w.(http.CloseNotifier)
+		assert.True(t, ok, "")
+		w.Header().Set("X-Etcd-Index", "3")
+		w.WriteHeader(http.StatusCreated)
+		time.Sleep(2 * time.Millisecond)
+	})(w, req)
+	assert.Equal(t, w.Code, http.StatusCreated, "")
+}
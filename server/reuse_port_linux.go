@@ -0,0 +1,4 @@
+package server
+
+// SO_REUSEPORT is missing from the syscall package on Linux.
+const soReusePort = 0xf
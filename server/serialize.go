@@ -0,0 +1,113 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"path"
+	"sync"
+
+	etcdErr "github.com/coreos/etcd/error"
+	"github.com/gorilla/mux"
+)
+
+// serialQueues lines up the writes that ask to be serialized on a prefix, so
+// that the leader applies them one at a time in the order they arrived.
+// Contended compare-and-swaps then see the result of the write before them
+// instead of racing it through raft.
+type serialQueues struct {
+	sync.Mutex
+	queues map[string]*serialQueue
+
+	Queued   uint64 `json:"queued"`
+	MaxDepth int    `json:"maxDepth"`
+}
+
+// serialQueue is the line of writes for one prefix.
+type serialQueue struct {
+	depth int
+	// Closed once the last write in line is done.
+	tail chan bool
+}
+
+// The response of the serialize stats endpoint.
+type serialStatsResponse struct {
+	Queued   uint64         `json:"queued"`
+	MaxDepth int            `json:"maxDepth"`
+	Prefixes map[string]int `json:"prefixes"`
+}
+
+func newSerialQueues() *serialQueues {
+	return &serialQueues{queues: make(map[string]*serialQueue)}
+}
+
+// enter waits until every earlier write on the prefix is done. The returned
+// function must be called once the write is done.
+func (sq *serialQueues) enter(prefix string) func() {
+	sq.Lock()
+	q, ok := sq.queues[prefix]
+	if !ok {
+		q = &serialQueue{}
+		sq.queues[prefix] = q
+	}
+	prev := q.tail
+	done := make(chan bool)
+	q.tail = done
+	q.depth++
+	sq.Queued++
+	if q.depth > sq.MaxDepth {
+		sq.MaxDepth = q.depth
+	}
+	sq.Unlock()
+
+	if prev != nil {
+		<-prev
+	}
+
+	return func() {
+		sq.Lock()
+		q.depth--
+		if q.depth == 0 {
+			delete(sq.queues, prefix)
+		}
+		sq.Unlock()
+		close(done)
+	}
+}
+
+// JSON returns the counts and the depth of every busy prefix.
+func (sq *serialQueues) JSON() []byte {
+	sq.Lock()
+	defer sq.Unlock()
+	r := &serialStatsResponse{
+		Queued:   sq.Queued,
+		MaxDepth: sq.MaxDepth,
+		Prefixes: make(map[string]int),
+	}
+	for prefix, q := range sq.queues {
+		r.Prefixes[prefix] = q.depth
+	}
+	j, _ := json.Marshal(r)
+	return j
+}
+
+// serializePrefix returns the prefix a write asks to be serialized on, or an
+// empty string if it does not. The prefix must contain the key written, or
+// writes could be lined up behind unrelated ones.
+func (s *Server) serializePrefix(req *http.Request) (string, error) {
+	p := req.FormValue("serialize")
+	if p == "" {
+		return "", nil
+	}
+	prefix := s.foldCase(path.Clean("/" + p))
+	if key := path.Clean("/" + mux.Vars(req)["key"]); !hasKeyPrefix(key, prefix) {
+		return "", etcdErr.NewError(etcdErr.EcodeInvalidSerialize, p, s.Store().Index())
+	}
+	return prefix, nil
+}
+
+// Retrieves the depth of the write queues of serialized prefixes.
+func (s *Server) GetSerializeStatsHandler(w http.ResponseWriter, req *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(s.serial.JSON())
+	return nil
+}
@@ -0,0 +1,49 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Ensures that writes serialized on a prefix run one at a time in order.
+func TestSerialQueuesOrder(t *testing.T) {
+	sq := newSerialQueues()
+	first := sq.enter("/counters")
+
+	order := make(chan int, 3)
+	for i := 1; i <= 2; i++ {
+		go func(i int) {
+			done := sq.enter("/counters")
+			order <- i
+			done()
+		}(i)
+		// Let the write get in line before the next one.
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Another prefix does not wait.
+	sq.enter("/other")()
+
+	var r serialStatsResponse
+	assert.Nil(t, json.Unmarshal(sq.JSON(), &r), "")
+	assert.Equal(t, r.Prefixes["/counters"], 3, "")
+	assert.Equal(t, r.MaxDepth, 3, "")
+	assert.Equal(t, r.Queued, uint64(4), "")
+
+	select {
+	case <-order:
+		t.Fatal("A queued write ran before the one ahead of it was done")
+	default:
+	}
+	first()
+	assert.Equal(t, <-order, 1, "")
+	assert.Equal(t, <-order, 2, "")
+
+	time.Sleep(10 * time.Millisecond)
+	var idle serialStatsResponse
+	assert.Nil(t, json.Unmarshal(sq.JSON(), &idle), "")
+	assert.Equal(t, len(idle.Prefixes), 0, "")
+}
@@ -17,48 +17,116 @@ import (
 	"github.com/coreos/etcd/store"
 	_ "github.com/coreos/etcd/store/v2"
 	"github.com/coreos/raft"
+	"github.com/gorilla/context"
 	"github.com/gorilla/mux"
 )
 
 // This is the default implementation of the Server interface.
 type Server struct {
 	http.Server
-	peerServer  *PeerServer
-	registry    *Registry
-	listener    net.Listener
-	store       store.Store
-	name        string
-	url         string
-	tlsConf     *TLSConfig
-	tlsInfo     *TLSInfo
-	router      *mux.Router
-	corsHandler *corsHandler
+	peerServer   *PeerServer
+	registry     *Registry
+	listener     net.Listener
+	store        store.Store
+	name         string
+	url          string
+	tlsConf      *TLSConfig
+	tlsInfo      *TLSInfo
+	router       *mux.Router
+	corsHandler  *corsHandler
+	proxyHandler *proxyHandler
+	adminNets    []*net.IPNet
+	adminNames   map[string]bool
+	writeRules   []writeRule
+	quotas       map[string]*userQuota
+	watchers     *watcherStats
+	requests     *activeRequests
+	blocking     *blockingStats
+	serial       *serialQueues
+	debug        debugModes
+
+	// The values under these prefixes are encrypted with valueCipher.
+	encryptPrefixes []string
+	valueCipher     ValueCipher
+
+	// The keys under these prefixes are stored and matched in lower case.
+	foldCasePrefixes []string
+
+	// Keys deeper than this many components are rejected.
+	MaxKeyDepth int
+
+	// Keys with a component longer than this many bytes are rejected.
+	MaxKeyNameLength int
+
+	// The options of the client listener.
+	SocketOptions SocketOptions
+
+	// The TTL, in seconds, given to writes without one. Zero disables it.
+	DefaultTTL int
+
+	// Writes with a longer TTL, in seconds, are rejected. Zero disables it.
+	MaxTTL int
+
+	// The key prefixes covered by DefaultTTL and MaxTTL. When empty they
+	// cover every key outside of /_etcd.
+	TTLPrefixes []string
+
+	// Log every request served.
+	AccessLog bool
+
+	// How long debug modes switched on at runtime stay on when the change
+	// does not say. Zero means ten minutes.
+	DebugTTL time.Duration
+
+	// Requests slower than this are logged even without the access log.
+	// Zero disables it.
+	SlowRequestThreshold time.Duration
+
+	// The number of watches and other waiting requests served at once.
+	// Zero disables the limit.
+	MaxBlockingRequests int
+
+	// Long-poll watches are ended after this long so that clients watch
+	// again. Zero lets them wait forever.
+	MaxWatchDuration time.Duration
 }
 
 // Creates a new Server.
 func New(name string, urlStr string, bindAddr string, tlsConf *TLSConfig, tlsInfo *TLSInfo, peerServer *PeerServer, registry *Registry, store store.Store) *Server {
 	r := mux.NewRouter()
 	cors := &corsHandler{router: r}
+	proxy := &proxyHandler{handler: cors}
 
 	s := &Server{
 		Server: http.Server{
-			Handler:   cors,
+			Handler:   proxy,
 			TLSConfig: &tlsConf.Server,
 			Addr:      bindAddr,
 		},
-		name:        name,
-		store:       store,
-		registry:    registry,
-		url:         urlStr,
-		tlsConf:     tlsConf,
-		tlsInfo:     tlsInfo,
-		peerServer:  peerServer,
-		router:      r,
-		corsHandler: cors,
+		name:         name,
+		store:        store,
+		registry:     registry,
+		url:          urlStr,
+		tlsConf:      tlsConf,
+		tlsInfo:      tlsInfo,
+		peerServer:   peerServer,
+		router:       r,
+		corsHandler:  cors,
+		proxyHandler: proxy,
+		watchers:     newWatcherStats(),
+		requests:     newActiveRequests(),
+		blocking:     &blockingStats{},
+		serial:       newSerialQueues(),
+
+		MaxKeyDepth:      defaultMaxKeyDepth,
+		MaxKeyNameLength: defaultMaxKeyNameLength,
 	}
 
+	s.AllowAdmin(nil)
+
 	// Install the routes.
 	s.handleFunc("/version", s.GetVersionHandler).Methods("GET")
+	s.handleFunc("/health", s.GetHealthHandler).Methods("GET")
 	s.installV1()
 	s.installV2()
 	s.installMod()
@@ -106,6 +174,13 @@ func (s *Server) Store() store.Store {
 	return s.store
 }
 
+// Serves a client API request of a module in-process, through the same
+// checks as one received over the network, except for the write rules.
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	context.Set(req, internalRequestKey{}, true)
+	s.router.ServeHTTP(w, req)
+}
+
 func (s *Server) installV1() {
 	s.handleFuncV1("/v1/keys/{key:.*}", v1.GetKeyHandler).Methods("GET")
 	s.handleFuncV1("/v1/keys/{key:.*}", v1.SetKeyHandler).Methods("POST", "PUT")
@@ -124,32 +199,130 @@ func (s *Server) installV2() {
 	s.handleFuncV2("/v2/keys/{key:.*}", v2.PostHandler).Methods("POST")
 	s.handleFuncV2("/v2/keys/{key:.*}", v2.PutHandler).Methods("PUT")
 	s.handleFuncV2("/v2/keys/{key:.*}", v2.DeleteHandler).Methods("DELETE")
+	s.handleFuncV2("/v2/watch/{key:.*}", v2.WatchHandler).Methods("GET")
+	s.handleFuncV2("/v2/stream", v2.StreamHandler).Methods("GET")
 	s.handleFunc("/v2/leader", s.GetLeaderHandler).Methods("GET")
 	s.handleFunc("/v2/machines", s.GetPeersHandler).Methods("GET")
 	s.handleFunc("/v2/peers", s.GetPeersHandler).Methods("GET")
+	s.handleFunc("/v2/members", s.requireAdminRole(s.GetMembersHandler)).Methods("GET")
 	s.handleFunc("/v2/stats/self", s.GetStatsHandler).Methods("GET")
 	s.handleFunc("/v2/stats/leader", s.GetLeaderStatsHandler).Methods("GET")
 	s.handleFunc("/v2/stats/store", s.GetStoreStatsHandler).Methods("GET")
-	s.handleFunc("/v2/speedTest", s.SpeedTestHandler).Methods("GET")
+	s.handleFunc("/v2/stats/batch", s.GetBatchStatsHandler).Methods("GET")
+	s.handleFunc("/v2/stats/disk", s.GetDiskStatsHandler).Methods("GET")
+	s.handleFunc("/v2/stats/memory", s.GetMemoryStatsHandler).Methods("GET")
+	s.handleFunc("/v2/stats/consistency", s.GetConsistencyStatsHandler).Methods("GET")
+	s.handleFunc("/v2/stats/cluster", s.GetClusterStatsHandler).Methods("GET")
+	s.handleFunc("/v2/stats/blocking", s.GetBlockingStatsHandler).Methods("GET")
+	s.handleFunc("/v2/stats/listeners", s.GetListenerStatsHandler).Methods("GET")
+	s.handleFunc("/v2/stats/serialize", s.GetSerializeStatsHandler).Methods("GET")
+	s.handleAdminFunc("/v2/stats/watchers", s.GetWatcherStatsHandler).Methods("GET")
+	s.handleAdminFunc("/v2/admin/hash", s.GetHashHandler).Methods("GET")
+	s.handleAdminFunc("/v2/admin/hash", s.PostHashHandler).Methods("POST")
+	s.handleAdminFunc("/v2/admin/config", s.GetConfigHandler).Methods("GET")
+	s.handleAdminFunc("/v2/admin/config", s.PutConfigHandler).Methods("PUT")
+	s.handleAdminFunc("/v2/admin/logs", s.GetLogsHandler).Methods("GET")
+	s.handleAdminFunc("/v2/admin/raft/events", s.GetRaftEventsHandler).Methods("GET")
+	s.handleAdminFunc("/v2/admin/quotas", s.GetQuotasHandler).Methods("GET")
+	s.handleAdminFunc("/v2/admin/requests", s.GetActiveRequestsHandler).Methods("GET")
+	s.handleAdminFunc("/v2/admin/requests/{id}", s.DeleteActiveRequestHandler).Methods("DELETE")
+	s.handleFunc("/v2/quota", s.GetQuotaHandler).Methods("GET")
+	s.handleAdminFunc("/debug/pprof/{profile:.*}", s.PprofHandler)
+	s.handleAdminFunc("/v2/admin/members/{name}", s.PutMemberHandler).Methods("PUT")
+	s.handleAdminFunc("/v2/admin/members/check", s.PostMembershipCheckHandler).Methods("POST")
+	s.handleFunc("/v2/version", s.GetVersionInfoHandler).Methods("GET")
+	s.handleFunc("/v2/speedTest", s.requireAdminRole(s.SpeedTestHandler)).Methods("GET")
 }
 
 func (s *Server) installMod() {
 	r := s.router
-	r.PathPrefix("/mod").Handler(http.StripPrefix("/mod", mod.HttpHandler(s.url)))
+	h := s.limitModBlocking(s.cancelableModRequest(http.StripPrefix("/mod", s.checkModWrite(s.checkModQuota(mod.HttpHandler(s.url, s, newModTransport(s)))))))
+	r.PathPrefix("/mod").HandlerFunc(s.serveRecovered(h))
+}
+
+// A middleware wraps a handler with one step of request processing.
+type middleware func(func(http.ResponseWriter, *http.Request) error) func(http.ResponseWriter, *http.Request) error
+
+// chain wraps f in the middlewares, the first one running first.
+func chain(f func(http.ResponseWriter, *http.Request) error, middlewares []middleware) func(http.ResponseWriter, *http.Request) error {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		f = middlewares[i](f)
+	}
+	return f
+}
+
+// The steps every v1 client API request goes through, in order.
+func (s *Server) v1Middlewares() []middleware {
+	return []middleware{
+		s.checkRecovered,
+		s.checkKey,
+		s.foldKeys,
+		s.checkWrite,
+		s.checkQuota,
+		s.checkTTL,
+		s.encryptValues,
+		s.limitBlocking,
+		s.trackWatcher,
+	}
+}
+
+// The steps every v2 client API request goes through, in order.
+func (s *Server) v2Middlewares() []middleware {
+	return []middleware{
+		s.checkRecovered,
+		s.checkKey,
+		s.foldKeys,
+		s.checkWrite,
+		s.checkQuota,
+		s.checkTTL,
+		s.encryptValues,
+		s.limitBlocking,
+		s.cancelableRequest,
+		s.trackWatcher,
+		s.limitWatch,
+	}
 }
 
 // Adds a v1 server handler to the router.
 func (s *Server) handleFuncV1(path string, f func(http.ResponseWriter, *http.Request, v1.Server) error) *mux.Route {
-	return s.handleFunc(path, func(w http.ResponseWriter, req *http.Request) error {
+	return s.handleFunc(path, chain(func(w http.ResponseWriter, req *http.Request) error {
 		return f(w, req, s)
-	})
+	}, s.v1Middlewares()))
 }
 
 // Adds a v2 server handler to the router.
 func (s *Server) handleFuncV2(path string, f func(http.ResponseWriter, *http.Request, v2.Server) error) *mux.Route {
-	return s.handleFunc(path, func(w http.ResponseWriter, req *http.Request) error {
+	return s.handleFunc(path, chain(func(w http.ResponseWriter, req *http.Request) error {
 		return f(w, req, s)
-	})
+	}, s.v2Middlewares()))
+}
+
+// Adds a key validation step in front of a handler serving a {key} route so
+// every API rejects malformed keys the same way. The key a value is moved
+// from is validated as well.
+func (s *Server) checkKey(f func(http.ResponseWriter, *http.Request) error) func(http.ResponseWriter, *http.Request) error {
+	return func(w http.ResponseWriter, req *http.Request) error {
+		if key, ok := mux.Vars(req)["key"]; ok {
+			if err := validateKey(key, s.MaxKeyDepth, s.MaxKeyNameLength); err != nil {
+				return etcdErr.NewError(etcdErr.EcodeInvalidKey, err.Error(), s.store.Index())
+			}
+		}
+		if moveFrom := moveFromKey(req); moveFrom != "" {
+			if err := validateKey(strings.TrimPrefix(moveFrom, "/"), s.MaxKeyDepth, s.MaxKeyNameLength); err != nil {
+				return etcdErr.NewError(etcdErr.EcodeInvalidKey, err.Error(), s.store.Index())
+			}
+		}
+		return f(w, req)
+	}
+}
+
+// moveFromKey returns the key a POST moves its value from, or an empty
+// string when it does not move one.
+func moveFromKey(req *http.Request) string {
+	if req.Method != "POST" {
+		return ""
+	}
+	return req.FormValue("moveFrom")
 }
 
 // Adds a server handler to the router.
@@ -157,7 +330,7 @@ func (s *Server) handleFunc(path string, f func(http.ResponseWriter, *http.Reque
 	r := s.router
 
 	// Wrap the standard HandleFunc interface to pass in the server reference.
-	return r.HandleFunc(path, func(w http.ResponseWriter, req *http.Request) {
+	return r.HandleFunc(path, s.logRequest(func(w http.ResponseWriter, req *http.Request) {
 		// Log request.
 		log.Debugf("[recv] %s %s %s [%s]", req.Method, s.url, req.URL.Path, req.RemoteAddr)
 
@@ -171,7 +344,7 @@ func (s *Server) handleFunc(path string, f func(http.ResponseWriter, *http.Reque
 				http.Error(w, err.Error(), http.StatusInternalServerError)
 			}
 		}
-	})
+	}))
 }
 
 // Start to listen and response etcd client command
@@ -191,7 +364,7 @@ func (s *Server) listenAndServe() error {
 	if addr == "" {
 		addr = ":http"
 	}
-	l, e := net.Listen("tcp", addr)
+	l, e := s.SocketOptions.Listen(addr)
 	if e != nil {
 		return e
 	}
@@ -220,7 +393,7 @@ func (s *Server) listenAndServeTLS(certFile, keyFile string) error {
 		return err
 	}
 
-	conn, err := net.Listen("tcp", addr)
+	conn, err := s.SocketOptions.Listen(addr)
 	if err != nil {
 		return err
 	}
@@ -238,11 +411,44 @@ func (s *Server) Close() {
 	}
 }
 
+// Do proposes a command on the leader without writing a response.
+func (s *Server) Do(c raft.Command) (interface{}, error) {
+	if s.peerServer.raftServer.State() != raft.Leader {
+		return nil, raft.NotLeaderError
+	}
+	return s.peerServer.propose(c)
+}
+
+// propose sends a client write to raft on the leader, waiting first for the
+// writes ahead of it on the prefix it is serialized on.
+func (s *Server) propose(c raft.Command, req *http.Request) (interface{}, error) {
+	c, err := s.withRequestID(c, req)
+	if err != nil {
+		return nil, err
+	}
+	prefix, err := s.serializePrefix(req)
+	if err != nil {
+		return nil, err
+	}
+	if prefix != "" {
+		done := s.serial.enter(prefix)
+		defer done()
+	}
+	return s.peerServer.propose(c)
+}
+
 // Dispatch command to the current leader
 func (s *Server) Dispatch(c raft.Command, w http.ResponseWriter, req *http.Request) error {
 	ps := s.peerServer
 	if ps.raftServer.State() == raft.Leader {
-		result, err := ps.raftServer.Do(c)
+		var result interface{}
+		var err error
+		switch c.(type) {
+		case *JoinCommand, *RemoveCommand, *HashCommand, *UpdateMemberCommand:
+			result, err = ps.raftServer.Do(c)
+		default:
+			result, err = s.propose(c, req)
+		}
 		if err != nil {
 			return err
 		}
@@ -260,11 +466,11 @@ func (s *Server) Dispatch(c raft.Command, w http.ResponseWriter, req *http.Reque
 
 		var b []byte
 		if strings.HasPrefix(req.URL.Path, "/v1") {
-			b, _ = json.Marshal(result.(*store.Event).Response(0))
+			b, _ = json.Marshal(s.RevealEvent(req, result.(*store.Event)).Response(0))
 			w.WriteHeader(http.StatusOK)
 		} else {
 			e, _ := result.(*store.Event)
-			b, _ = json.Marshal(e)
+			b, _ = json.Marshal(s.RevealEvent(req, e))
 
 			w.Header().Set("Content-Type", "application/json")
 			// etcd index should be the same as the event index
@@ -315,6 +521,17 @@ func (s *Server) AllowOrigins(origins []string) error {
 	return s.corsHandler.AllowOrigins(origins)
 }
 
+// TrustProxies sets the list of proxy CIDRs whose forwarding headers are
+// used to find the real client address.
+func (s *Server) TrustProxies(cidrs []string) error {
+	return s.proxyHandler.TrustProxies(cidrs)
+}
+
+// ClientIP returns the IP address of the client that made the request.
+func (s *Server) ClientIP(req *http.Request) string {
+	return s.proxyHandler.ClientIP(req)
+}
+
 // Handler to return the current version of etcd.
 func (s *Server) GetVersionHandler(w http.ResponseWriter, req *http.Request) error {
 	w.WriteHeader(http.StatusOK)
@@ -342,6 +559,45 @@ func (s *Server) GetPeersHandler(w http.ResponseWriter, req *http.Request) error
 	return nil
 }
 
+// Retrieves the name, URLs and tags of every member of the cluster.
+func (s *Server) GetMembersHandler(w http.ResponseWriter, req *http.Request) error {
+	members := s.registry.Members()
+	for _, m := range members {
+		m.Observer = s.peerServer.RaftServer().IsObserver(m.Name)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(w).Encode(members)
+}
+
+// Changes the URLs of a member. Fields missing from the JSON body keep their
+// current value.
+func (s *Server) PutMemberHandler(w http.ResponseWriter, req *http.Request) error {
+	var m Member
+	if err := json.NewDecoder(req.Body).Decode(&m); err != nil {
+		http.Error(w, "Invalid member", http.StatusBadRequest)
+		return nil
+	}
+	name := mux.Vars(req)["name"]
+	if m.Name != "" && m.Name != name {
+		http.Error(w, "Members cannot be renamed", http.StatusBadRequest)
+		return nil
+	}
+	if m.PeerURL == "" && m.ClientURL == "" {
+		http.Error(w, "A peerURL or clientURL is required", http.StatusBadRequest)
+		return nil
+	}
+	for _, u := range []string{m.PeerURL, m.ClientURL} {
+		if u != "" && !validMemberURL(u) {
+			http.Error(w, "Invalid URL: "+u, http.StatusBadRequest)
+			return nil
+		}
+	}
+
+	c := &UpdateMemberCommand{Name: name, RaftURL: m.PeerURL, EtcdURL: m.ClientURL}
+	return s.Dispatch(c, w, req)
+}
+
 // Retrieves stats on the Raft server.
 func (s *Server) GetStatsHandler(w http.ResponseWriter, req *http.Request) error {
 	w.Write(s.peerServer.Stats())
@@ -370,6 +626,37 @@ func (s *Server) GetStoreStatsHandler(w http.ResponseWriter, req *http.Request)
 	return nil
 }
 
+// Retrieves stats on the batching of writes on this node.
+func (s *Server) GetBatchStatsHandler(w http.ResponseWriter, req *http.Request) error {
+	w.Write(s.peerServer.BatchStats())
+	return nil
+}
+
+// Retrieves stats on the disk latency of this node.
+func (s *Server) GetDiskStatsHandler(w http.ResponseWriter, req *http.Request) error {
+	w.Write(s.peerServer.DiskStats())
+	return nil
+}
+
+// Retrieves the last estimate of the memory held by the store.
+func (s *Server) GetMemoryStatsHandler(w http.ResponseWriter, req *http.Request) error {
+	w.Write(s.peerServer.MemoryStats())
+	return nil
+}
+
+// Retrieves the results of the leader's last applied-state comparison.
+func (s *Server) GetConsistencyStatsHandler(w http.ResponseWriter, req *http.Request) error {
+	w.Write(s.peerServer.ConsistencyStats())
+	return nil
+}
+
+// Retrieves the status of every member of the cluster.
+func (s *Server) GetClusterStatsHandler(w http.ResponseWriter, req *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(s.peerServer.ClusterStats())
+	return nil
+}
+
 // Executes a speed test to evaluate the performance of update replication.
 func (s *Server) SpeedTestHandler(w http.ResponseWriter, req *http.Request) error {
 	count := 1000
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"time"
+
+	"github.com/coreos/etcd/log"
+	"github.com/coreos/raft"
+)
+
+// How often Resign checks whether leadership has moved.
+const resignPollInterval = 10 * time.Millisecond
+
+// Resign hands leadership over before this node shuts down so the cluster
+// does not wait out an election timeout. The most caught-up follower is
+// asked to campaign right away, preferring members of the leader zone; if
+// there is none this node simply steps down. It returns once another node
+// leads or the timeout passes, and this node never campaigns again.
+func (s *PeerServer) Resign(timeout time.Duration) {
+	s.raftServer.SetPromotable(false)
+	if s.raftServer.State() != raft.Leader {
+		return
+	}
+
+	name := s.successor()
+	if name == "" {
+		log.Infof("[shutdown] stepping down: name=%s", s.name)
+		s.raftServer.StepDown()
+	} else {
+		log.Infof("[shutdown] transferring leadership: name=%s to=%s", s.name, name)
+		peerURL, _ := s.registry.PeerURL(name)
+		if err := s.requestCampaign(peerURL); err != nil {
+			log.Warnf("[shutdown] transfer to %s failed: %v", name, err)
+			s.raftServer.StepDown()
+		}
+	}
+
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if leader := s.raftServer.Leader(); leader != "" && leader != s.name {
+			log.Infof("[shutdown] new leader: %s", leader)
+			return
+		}
+		time.Sleep(resignPollInterval)
+	}
+	log.Warnf("[shutdown] no new leader after %v", timeout)
+}
+
+// successor returns the follower that has replicated the most of the log.
+// Caught-up members of the leader zone come first. Returns an empty string
+// if there are no followers.
+func (s *PeerServer) successor() string {
+	commitIndex := s.raftServer.CommitIndex()
+	preferred := func(name string, index uint64) bool {
+		return s.LeaderZone != "" && index >= commitIndex && s.inLeaderZone(name)
+	}
+
+	var best string
+	var bestIndex uint64
+	for name, peer := range s.raftServer.Peers() {
+		index := peer.PrevLogIndex()
+		if best == "" {
+			best, bestIndex = name, index
+			continue
+		}
+		p, bp := preferred(name, index), preferred(best, bestIndex)
+		if (p && !bp) || (p == bp && index > bestIndex) {
+			best, bestIndex = name, index
+		}
+	}
+	return best
+}
+
+// Stop closes the peer listener and stops the Raft server, which closes the
+// log. It is meant for a node that resigned and is about to exit.
+func (s *PeerServer) Stop() {
+	s.Close()
+	s.raftServer.Stop()
+}
+
+// watchCorruption stops this node once its log turns out to hold an entry
+// that cannot be applied. The process exits when the peer listener closes.
+func (s *PeerServer) watchCorruption() {
+	s.raftServer.AddEventListener(func(e raft.Event) {
+		if e.Type != raft.CorruptionEventType {
+			return
+		}
+		log.Warnf("[raft] %v: stopping, the log of %s is corrupted", e.Value, s.name)
+
+		// Listeners must not call back into the raft server.
+		go func() {
+			s.Resign(2 * s.ElectionTimeout)
+			s.Stop()
+		}()
+	})
+}
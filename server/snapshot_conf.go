@@ -0,0 +1,194 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/coreos/etcd/log"
+)
+
+// SnapshotConfig holds the thresholds that trigger a snapshot of a member.
+// A zero byte threshold is disabled.
+type SnapshotConfig struct {
+	// Snapshot once more than this many writes were made since the last one.
+	Count uint64 `json:"snapshotCount"`
+
+	// Snapshot once the raft log is larger than this many bytes.
+	Bytes uint64 `json:"snapshotBytes"`
+
+	// Snapshot as soon as the raft log reaches this many bytes instead of
+	// waiting for the next check.
+	MaxLogBytes uint64 `json:"maxLogBytes"`
+}
+
+// TODO: find a good policy to do snapshot
+type snapshotConf struct {
+	mutex sync.Mutex
+
+	// Etcd will check if snapshot is need every checkingInterval
+	checkingInterval time.Duration
+
+	// The number of writes when the last snapshot happened
+	lastWrites uint64
+
+	// The thresholds checked against the writes since the last snapshot
+	// and the size of the log.
+	SnapshotConfig
+
+	// Wakes the snapshot monitor up before the next check.
+	wake chan bool
+}
+
+func newSnapshotConf(checkingInterval time.Duration, count uint64) *snapshotConf {
+	return &snapshotConf{
+		checkingInterval: checkingInterval,
+		SnapshotConfig:   SnapshotConfig{Count: count},
+		wake:             make(chan bool, 1),
+	}
+}
+
+func (c *snapshotConf) get() SnapshotConfig {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.SnapshotConfig
+}
+
+func (c *snapshotConf) set(conf SnapshotConfig) {
+	c.mutex.Lock()
+	c.SnapshotConfig = conf
+	c.mutex.Unlock()
+
+	// The new thresholds may already be crossed.
+	c.notify()
+}
+
+// due reports whether a snapshot should be taken given the total number of
+// writes and the size of the log.
+func (c *snapshotConf) due(writes uint64, logSize uint64) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if writes-c.lastWrites > c.Count {
+		return true
+	}
+	if c.Bytes > 0 && logSize > c.Bytes {
+		return true
+	}
+	return c.MaxLogBytes > 0 && logSize >= c.MaxLogBytes
+}
+
+// taken records the total number of writes at the last snapshot.
+func (c *snapshotConf) taken(writes uint64) {
+	c.mutex.Lock()
+	c.lastWrites = writes
+	c.mutex.Unlock()
+}
+
+// notify wakes the snapshot monitor up without waiting for it.
+func (c *snapshotConf) notify() {
+	select {
+	case c.wake <- true:
+	default:
+	}
+}
+
+// SnapshotConfig returns the current snapshot thresholds.
+func (s *PeerServer) SnapshotConfig() SnapshotConfig {
+	return s.snapConf.get()
+}
+
+// SetSnapshotConfig changes the snapshot thresholds. It is safe to call
+// while the server is running.
+func (s *PeerServer) SetSnapshotConfig(conf SnapshotConfig) {
+	s.snapConf.set(conf)
+}
+
+// logSize returns the size in bytes of the raft log on disk.
+func (s *PeerServer) logSize() uint64 {
+	fi, err := os.Stat(s.raftServer.LogPath())
+	if err != nil {
+		return 0
+	}
+	return uint64(fi.Size())
+}
+
+// checkLogSize wakes the snapshot monitor up once the log reaches the cap.
+// It is called whenever entries are appended to the log.
+func (s *PeerServer) checkLogSize() {
+	if max := s.snapConf.get().MaxLogBytes; max > 0 && s.logSize() >= max {
+		s.snapConf.notify()
+	}
+}
+
+func (s *PeerServer) monitorSnapshot() {
+	for {
+		select {
+		case <-time.After(s.snapConf.checkingInterval):
+		case <-s.snapConf.wake:
+		}
+		if s.snapConf.due(s.store.TotalTransactions(), s.logSize()) {
+			start := time.Now()
+			s.raftServer.TakeSnapshot()
+			if d := time.Now().Sub(start); s.diskStats.recordSnapshot(d) {
+				log.Warnf("[disk] slow snapshot: name=%s latency=%v threshold=%v", s.name, d, slowSnapshotThreshold)
+			}
+			s.snapConf.taken(s.store.TotalTransactions())
+		}
+	}
+}
+
+// The runtime configuration of a member, as served by the admin config
+// endpoint.
+type runtimeConfig struct {
+	SnapshotConfig
+	Debug *DebugConfig `json:"debug"`
+}
+
+// Retrieves the runtime configuration of this member.
+func (s *Server) GetConfigHandler(w http.ResponseWriter, req *http.Request) error {
+	debug := s.DebugConfig()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(runtimeConfig{s.peerServer.SnapshotConfig(), &debug})
+	return nil
+}
+
+// Changes the runtime configuration of this member. Fields missing from the
+// JSON body keep their current value.
+func (s *Server) PutConfigHandler(w http.ResponseWriter, req *http.Request) error {
+	var conf struct {
+		SnapshotConfig
+		Debug json.RawMessage `json:"debug"`
+	}
+	conf.SnapshotConfig = s.peerServer.SnapshotConfig()
+	if err := json.NewDecoder(req.Body).Decode(&conf); err != nil {
+		http.Error(w, "Invalid config", http.StatusBadRequest)
+		return nil
+	}
+
+	// The debug modes are only changed, and their TTL restarted, when the
+	// body has them.
+	var debug *DebugConfig
+	if conf.Debug != nil {
+		current := s.DebugConfig()
+		current.Expiration, current.TTL = nil, 0
+		if err := json.Unmarshal(conf.Debug, &current); err != nil {
+			http.Error(w, "Invalid config", http.StatusBadRequest)
+			return nil
+		}
+		debug = &current
+	}
+
+	if conf.SnapshotConfig != s.peerServer.SnapshotConfig() {
+		s.peerServer.SetSnapshotConfig(conf.SnapshotConfig)
+		log.Infof("[config] snapshot thresholds set: count=%d bytes=%d maxLogBytes=%d", conf.Count, conf.Bytes, conf.MaxLogBytes)
+	}
+	if debug != nil {
+		s.SetDebugConfig(*debug)
+		debug := s.DebugConfig()
+		log.Infof("[config] debug modes set: pprof=%v verbose=%v accessLog=%v ttl=%d", debug.Pprof, debug.Verbose, debug.AccessLog, debug.TTL)
+	}
+
+	return s.GetConfigHandler(w, req)
+}
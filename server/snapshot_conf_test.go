@@ -0,0 +1,36 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Ensures that a snapshot is due after enough writes or once the log is large.
+func TestSnapshotConfDue(t *testing.T) {
+	c := newSnapshotConf(time.Second, 100)
+	assert.False(t, c.due(100, 1<<30), "")
+	assert.True(t, c.due(101, 0), "")
+	c.taken(101)
+	assert.False(t, c.due(150, 0), "")
+
+	c.set(SnapshotConfig{Count: 100, Bytes: 1000})
+	assert.False(t, c.due(150, 1000), "")
+	assert.True(t, c.due(150, 1001), "")
+
+	c.set(SnapshotConfig{Count: 100, MaxLogBytes: 500})
+	assert.True(t, c.due(150, 500), "")
+}
+
+// Ensures that changing the thresholds wakes the snapshot monitor up.
+func TestSnapshotConfNotify(t *testing.T) {
+	c := newSnapshotConf(time.Second, 100)
+	c.set(SnapshotConfig{Count: 10})
+	c.notify()
+	select {
+	case <-c.wake:
+	default:
+		t.Fatal("monitor not woken up")
+	}
+}
@@ -99,6 +99,17 @@ func (t *transporter) SendAppendEntriesRequest(server raft.Server, peer *raft.Pe
 
 		t.CancelWhenTimeout(httpRequest)
 
+		if skew, ok := clockSkew(resp, start, end); ok {
+			threshold := t.peerServer.MaxClockSkew
+			if thisFollowerStats.UpdateClockSkew(skew, threshold) {
+				if thisFollowerStats.ClockSkew.Skewed {
+					log.Warnf("[clock] skewed: name=%s skew=%v threshold=%v", peer.Name, skew, threshold)
+				} else {
+					log.Infof("[clock] recovered: name=%s skew=%v threshold=%v", peer.Name, skew, threshold)
+				}
+			}
+		}
+
 		aeresp := &raft.AppendEntriesResponse{}
 		if _, err = aeresp.Decode(resp.Body); err != nil && err != io.EOF {
 			log.Warn("transporter.ae.decoding.error:", err)
@@ -0,0 +1,56 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	etcdErr "github.com/coreos/etcd/error"
+	"github.com/gorilla/mux"
+)
+
+// ttlPolicyApplies checks whether the TTL caps cover a key. Without any
+// prefixes they cover every key outside of the internal /_etcd directory.
+func ttlPolicyApplies(key string, prefixes []string) bool {
+	key = "/" + strings.TrimPrefix(key, "/")
+	if len(prefixes) == 0 {
+		return !hasKeyPrefix(key, "/_etcd")
+	}
+	for _, prefix := range prefixes {
+		if hasKeyPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Applies the default TTL to writes that do not set one and rejects TTLs
+// above the maximum, before the handler reads the "ttl" form value.
+func (s *Server) checkTTL(f func(http.ResponseWriter, *http.Request) error) func(http.ResponseWriter, *http.Request) error {
+	return func(w http.ResponseWriter, req *http.Request) error {
+		if s.DefaultTTL <= 0 && s.MaxTTL <= 0 {
+			return f(w, req)
+		}
+		if (req.Method != "PUT" && req.Method != "POST") || strings.HasPrefix(req.URL.Path, "/v1/watch/") {
+			return f(w, req)
+		}
+		key, ok := mux.Vars(req)["key"]
+		if !ok || !ttlPolicyApplies(key, s.TTLPrefixes) {
+			return f(w, req)
+		}
+
+		req.ParseForm()
+		ttl := req.Form.Get("ttl")
+		if ttl == "" {
+			if s.DefaultTTL > 0 {
+				req.Form.Set("ttl", strconv.Itoa(s.DefaultTTL))
+			} else {
+				return etcdErr.NewError(etcdErr.EcodeTTLTooLarge, fmt.Sprintf("no TTL, maximum is %d", s.MaxTTL), s.store.Index())
+			}
+		} else if n, err := strconv.Atoi(ttl); err == nil && s.MaxTTL > 0 && n > s.MaxTTL {
+			return etcdErr.NewError(etcdErr.EcodeTTLTooLarge, fmt.Sprintf("%d > %d", n, s.MaxTTL), s.store.Index())
+		}
+		return f(w, req)
+	}
+}
@@ -0,0 +1,60 @@
+package server
+
+import (
+	"encoding/json"
+	"net/url"
+
+	etcdErr "github.com/coreos/etcd/error"
+	"github.com/coreos/etcd/log"
+	"github.com/coreos/raft"
+)
+
+func init() {
+	raft.RegisterCommand(&UpdateMemberCommand{})
+}
+
+// The UpdateMemberCommand changes the URLs a member is reached at, so that
+// it can move to another address without leaving the cluster.
+type UpdateMemberCommand struct {
+	Name    string `json:"name"`
+	RaftURL string `json:"raftURL,omitempty"`
+	EtcdURL string `json:"etcdURL,omitempty"`
+}
+
+// The name of the update member command in the log
+func (c *UpdateMemberCommand) CommandName() string {
+	return "etcd:updateMember"
+}
+
+// Update the URLs of the member in the shared registry
+func (c *UpdateMemberCommand) Apply(server raft.Server) (interface{}, error) {
+	ps, _ := server.Context().(*PeerServer)
+
+	// Make sure we're not getting a cached value from the registry.
+	ps.registry.Invalidate(c.Name)
+
+	if _, ok := ps.registry.PeerURL(c.Name); !ok {
+		return []byte{0}, etcdErr.NewError(etcdErr.EcodeKeyNotFound, c.Name, server.CommitIndex())
+	}
+	if err := ps.registry.SetURLs(c.Name, c.RaftURL, c.EtcdURL); err != nil {
+		log.Debugf("Error while updating member: %s (%v)", c.Name, err)
+		return []byte{0}, err
+	}
+
+	for _, m := range ps.registry.Members() {
+		if m.Name == c.Name {
+			log.Infof("[members] %s is now at raft=%s etcd=%s", m.Name, m.PeerURL, m.ClientURL)
+			return json.Marshal(m)
+		}
+	}
+	return []byte{0}, nil
+}
+
+// validMemberURL checks that a member URL can be dialed.
+func validMemberURL(s string) bool {
+	u, err := url.Parse(s)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
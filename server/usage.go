@@ -11,6 +11,7 @@ etcd
 Usage:
   etcd -name <name>
   etcd -name <name> [-data-dir=<path>]
+  etcd -dev [-dev-fixtures=<path>]
   etcd -h | -help
   etcd -version
 
@@ -22,6 +23,10 @@ Options:
   -name=<name>      Name of this node in the etcd cluster.
   -data-dir=<path>  Path to the data directory.
   -cors=<origins>   Comma-separated list of CORS origins.
+  -dev              Run a throwaway single node for development and print
+                    its client URL on stdout.
+  -dev-fixtures=<path>
+                    Path to a JSON file of keys set by -dev.
   -v                Enabled verbose logging.
   -vv               Enabled very verbose logging.
 
@@ -29,6 +34,9 @@ Cluster Configuration Options:
   -peers-file=<path>              Path to a file containing the peer list.
   -peers=<host:port>,<host:port>  Comma-separated list of peers. The members
                                   should match the peer's '-peer-addr' flag.
+  -tags=<key=value>,<key=value>   Comma-separated list of tags (zone, rack...)
+                                  published with this member.
+  -leader-zone=<zone>             Prefer leaders whose 'zone' tag matches.
 
 Client Communication Options:
   -addr=<host:port>         The public host:port used for client communication.
@@ -36,6 +44,33 @@ Client Communication Options:
   -ca-file=<path>           Path to the client CA file.
   -cert-file=<path>         Path to the client cert file.
   -key-file=<path>          Path to the client key file.
+  -trusted-proxies=<cidrs>  Comma-separated list of proxy CIDRs whose
+                            X-Forwarded-For and X-Real-IP headers are trusted.
+  -admin-cidrs=<cidrs>      Comma-separated list of client CIDRs allowed to use
+                            the admin endpoints. Defaults to loopback only.
+  -admin-names=<names>      Comma-separated list of client certificate common
+                            names holding the admin role.
+  -allow-cidrs=<cidrs>      Comma-separated list of CIDRs allowed to connect to
+                            the client port. Defaults to any address.
+  -deny-cidrs=<cidrs>       Comma-separated list of CIDRs refused on the client
+                            port, even when allowed.
+  -write-rules=<rules>      Comma-separated list of prefix=name rules letting the
+                            client certificate named name write keys under prefix.
+  -user-max-watches=<quotas>
+                            Comma-separated list of name=watches quotas on the
+                            watches a client certificate keeps open at once.
+  -user-max-rate=<quotas>   Comma-separated list of name=requests quotas on the
+                            requests per second of a client certificate.
+  -user-max-bytes=<quotas>  Comma-separated list of name=bytes quotas on the bytes
+                            stored under the prefixes a client may write.
+  -encrypt-prefixes=<prefixes>
+                            Comma-separated list of key prefixes whose values
+                            are encrypted at rest.
+  -encryption-key-file=<path>
+                            Path to the AES key encrypting those values.
+  -fold-case-prefixes=<prefixes>
+                            Comma-separated list of key prefixes whose keys
+                            are matched case-insensitively.
 
 Peer Communication Options:
   -peer-addr=<host:port>  The public host:port used for peer communication.
@@ -43,17 +78,86 @@ Peer Communication Options:
   -peer-ca-file=<path>    Path to the peer CA file.
   -peer-cert-file=<path>  Path to the peer cert file.
   -peer-key-file=<path>   Path to the peer key file.
+  -peer-allow-cidrs=<cidrs>
+                          Comma-separated list of CIDRs allowed to connect to
+                          the peer port. Defaults to any address.
+  -peer-deny-cidrs=<cidrs>
+                          Comma-separated list of CIDRs refused on the peer
+                          port, even when allowed.
   -peer-heartbeat-timeout=<time>
                           Time (in milliseconds) for a heartbeat to timeout.
   -peer-election-timeout=<time>
                           Time (in milliseconds) for an election to timeout.
+  -peer-election-window=<time>
+                          Width (in milliseconds) of the window election
+                          timeouts are randomized in. Defaults to the
+                          election timeout.
+
+DNS Options:
+  -dns-addr=<host:port>   Answer DNS queries over UDP and TCP on this address.
+                          Defaults to none (disabled).
+  -dns-domain=<domain>    The domain the names are served under.
+                          Defaults to "etcd.".
+  -dns-prefix=<prefix>    The key prefix the services are read from.
+                          Defaults to "/services".
 
 Other Options:
   -max-result-buffer   Max size of the result buffer.
   -max-retry-attempts  Number of times a node will try to join a cluster.
   -max-cluster-size    Maximum number of nodes in the cluster.
+  -max-observers       Maximum number of observers in the cluster.
+                       Defaults to 9.
+  -max-clock-skew      Time (in milliseconds) the clock of a follower may
+                       differ from the leader's before a warning is logged.
+                       Defaults to 1000.
+  -max-blocking-requests
+                       Maximum number of watches and other waiting requests
+                       served at once. Defaults to 0 (no limit).
+  -max-key-depth       Maximum number of components in a key path.
+                       Defaults to 64, 0 disables the limit.
+  -max-key-name-length Maximum length (in bytes) of a key path component.
+                       Defaults to 255, 0 disables the limit.
+  -default-ttl         TTL (in seconds) given to writes without one.
+                       Defaults to 0 (no TTL).
+  -max-ttl             Maximum TTL (in seconds) a write may set.
+                       Defaults to 0 (no limit).
+  -max-watch-duration  Time (in seconds) after which a v2 long-poll watch is
+                       ended with a 408. Defaults to 0 (no limit).
+  -ttl-prefixes        Comma-separated list of key prefixes covered by
+                       -default-ttl and -max-ttl. Defaults to all keys.
   -snapshot            Open or close the snapshot.
   -snapshot-count      Number of transactions before issuing a snapshot.
+  -snapshot-bytes      Size (in bytes) of the log before issuing a snapshot.
+                       Defaults to 0 (disabled).
+  -max-log-bytes       Size (in bytes) of the log at which a snapshot is
+                       issued right away. Defaults to 0 (disabled).
+  -batch-window        Time (in milliseconds) the leader waits to group
+                       client writes into a single log entry.
+  -slow-disk-threshold Time (in milliseconds) above which a raft log sync is
+                       considered slow. Defaults to 500.
+  -access-log          Log every client request.
+  -log-slow-requests   Log client requests slower than this duration
+                       (i.e 250ms) even without -access-log.
+  -debug-ttl           Time (in seconds) debug modes switched on through
+                       /v2/admin/config stay on. Defaults to 600.
+  -slow-disk-abdicate  Refuse to campaign and step down as leader while
+                       the disk is degraded.
+  -hash-check-interval Time (in seconds) between comparisons of the applied
+                       state of every member by the leader. Defaults to 0
+                       (disabled).
+  -observer            Join the cluster as an observer that replicates the
+                       log and serves reads and watches but never votes.
+  -reuse-port          Set SO_REUSEPORT on both listeners so that a new
+                       process can bind them while the old one drains.
+  -tcp-keepalive       TCP keepalive period (in seconds) of accepted
+                       connections. Defaults to 0 (system default), -1
+                       disables keepalives.
+  -tcp-nodelay         Send small writes without delay. Defaults to true.
+  -tombstone-indexes   Number of indexes the tombstones of deleted keys are
+                       kept for. Defaults to 0 (no limit).
+  -tombstone-ttl       Time (in seconds) the tombstones of deleted keys are
+                       kept for. Defaults to 0 (no limit). Tombstones are
+                       only kept when one of the two is set.
 `
 
 // Usage returns the usage message for etcd.
@@ -12,4 +12,5 @@ type Server interface {
 	Term() uint64
 	Store() store.Store
 	Dispatch(raft.Command, http.ResponseWriter, *http.Request) error
+	RevealEvent(*http.Request, *store.Event) *store.Event
 }
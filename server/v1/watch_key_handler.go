@@ -32,7 +32,7 @@ func WatchKeyHandler(w http.ResponseWriter, req *http.Request, s Server) error {
 	event := <-c
 
 	// Convert event to a response and write to client.
-	b, _ := json.Marshal(event.Response(s.Store().Index()))
+	b, _ := json.Marshal(s.RevealEvent(req, event).Response(s.Store().Index()))
 	w.WriteHeader(http.StatusOK)
 	w.Write(b)
 
@@ -0,0 +1,97 @@
+package v2
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	etcdErr "github.com/coreos/etcd/error"
+	"github.com/coreos/etcd/lifetime"
+	"github.com/coreos/etcd/store"
+)
+
+// How long the changes to a key are coalesced when the watch does not say.
+const defaultCoalesceInterval = time.Second
+
+// coalesceInterval returns the interval over which a watch coalesces the
+// changes to a key, or zero when it gets every change.
+func coalesceInterval(req *http.Request, s Server) (time.Duration, error) {
+	if req.FormValue("coalesce") != "true" {
+		return 0, nil
+	}
+	v := req.FormValue("coalesceInterval")
+	if v == "" {
+		return defaultCoalesceInterval, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		return 0, etcdErr.NewError(etcdErr.EcodeInvalidCoalesce, v, s.Store().Index())
+	}
+	return d, nil
+}
+
+// waitForCoalescedEvent waits for the first change to a key since the given
+// index, then returns the latest change to the same key made within the
+// interval. A change to another key ends the wait early; it is left for the
+// next watch so no change is skipped.
+func waitForCoalescedEvent(w http.ResponseWriter, s Server, key string, recursive bool, sinceIndex uint64, interval time.Duration) (*store.Event, error) {
+	event, err := waitForEvent(w, s, key, recursive, sinceIndex)
+	if err != nil || event == nil {
+		return event, err
+	}
+
+	l := lifetime.Start(w, -1)
+	defer l.Stop()
+	deadline := time.After(interval)
+
+	for {
+		eventChan, err := s.Store().Watch(key, recursive, event.Index()+1)
+		if err != nil {
+			return event, nil
+		}
+
+		select {
+		case <-l.Done():
+			return nil, nil
+		case <-deadline:
+			return event, nil
+		case e := <-eventChan:
+			if e.Node.Key != event.Node.Key {
+				return event, nil
+			}
+			event = e
+		}
+	}
+}
+
+// eventsByIndex sorts events in the order they happened.
+type eventsByIndex []*store.Event
+
+func (e eventsByIndex) Len() int           { return len(e) }
+func (e eventsByIndex) Less(i, j int) bool { return e[i].Index() < e[j].Index() }
+func (e eventsByIndex) Swap(i, j int)      { e[i], e[j] = e[j], e[i] }
+
+// coalescer keeps the latest change to each key until it is flushed.
+type coalescer struct {
+	pending map[string]*store.Event
+}
+
+func newCoalescer() *coalescer {
+	return &coalescer{pending: make(map[string]*store.Event)}
+}
+
+// add records a change, replacing the pending one for the same key.
+func (c *coalescer) add(e *store.Event) {
+	c.pending[e.Node.Key] = e
+}
+
+// flush returns the pending changes in the order of their last update.
+func (c *coalescer) flush() []*store.Event {
+	events := make(eventsByIndex, 0, len(c.pending))
+	for _, e := range c.pending {
+		events = append(events, e)
+	}
+	sort.Sort(events)
+	c.pending = make(map[string]*store.Event)
+	return events
+}
@@ -0,0 +1,107 @@
+package v2
+
+import (
+	"net/http"
+	"path"
+	"strconv"
+	"time"
+
+	etcdErr "github.com/coreos/etcd/error"
+	"github.com/coreos/etcd/lifetime"
+	"github.com/coreos/etcd/log"
+)
+
+// The TTL of an ephemeral key that sets none. An ephemeral key only outlives
+// its connection by up to this much when the leader holding it fails.
+const defaultEphemeralTTL = 10 * time.Second
+
+// ephemeralTTL parses the "ttl" value of an ephemeral write.
+func ephemeralTTL(req *http.Request, s Server) (time.Duration, error) {
+	ttl := req.FormValue("ttl")
+	if ttl == "" {
+		return defaultEphemeralTTL, nil
+	}
+	n, err := strconv.Atoi(ttl)
+	if err != nil || n <= 0 {
+		return 0, etcdErr.NewError(etcdErr.EcodeTTLNaN, "Ephemeral", s.Store().Index())
+	}
+	return time.Duration(n) * time.Second, nil
+}
+
+// EphemeralHandler writes a key that lives as long as the client keeps the
+// connection open. The response is sent right away; the leader then keeps
+// the key's TTL alive and deletes the key when the client disconnects,
+// unless someone else changed it meanwhile. Followers redirect the client
+// to the leader like any other write.
+func EphemeralHandler(w http.ResponseWriter, req *http.Request, s Server, key string, unique bool, value string, ttl time.Duration, create bool) error {
+	f := s.Store().CommandFactory()
+	c := f.CreateSetCommand(key, false, value, time.Now().Add(ttl))
+	if unique || create {
+		c = f.CreateCreateCommand(key, false, value, time.Now().Add(ttl), unique)
+	}
+
+	if err := s.Dispatch(c, w, req); err != nil {
+		return err
+	}
+
+	// Not written by this node.
+	index, err := strconv.ParseUint(w.Header().Get("X-Etcd-Index"), 10, 64)
+	if err != nil {
+		return nil
+	}
+	if unique {
+		key = path.Join(key, strconv.FormatUint(index, 10))
+	}
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+
+	l := lifetime.Start(w, -1)
+	defer l.Stop()
+
+	ticker := time.NewTicker(ttl / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.Done():
+			deleteEphemeral(s, key, index)
+			return nil
+
+		case <-ticker.C:
+			// A refresh leaves the key and its index alone, so watchers
+			// are not woken up for it.
+			if err := refreshEphemeral(s, key, index, ttl); err != nil {
+				// The key changed hands or this node lost the leadership: let it expire.
+				log.Debugf("[ephemeral] stop refreshing %s: %v", key, err)
+				<-l.Done()
+				return nil
+			}
+		}
+	}
+}
+
+// refreshEphemeral extends the TTL of an ephemeral key if it is still at the
+// index it was written at.
+func refreshEphemeral(s Server, key string, index uint64, ttl time.Duration) error {
+	e, err := s.Store().Get(key, false, false)
+	if err != nil {
+		return err
+	}
+	if e.Node.ModifiedIndex != index {
+		return etcdErr.NewError(etcdErr.EcodeTestFailed, key, s.Store().Index())
+	}
+	_, err = s.Do(s.Store().CommandFactory().CreateRefreshCommand(key, time.Now().Add(ttl)))
+	return err
+}
+
+// deleteEphemeral deletes an ephemeral key if it is still at the index it
+// was written at.
+func deleteEphemeral(s Server, key string, index uint64) {
+	e, err := s.Store().Get(key, false, false)
+	if err != nil || e.Node.ModifiedIndex != index {
+		return
+	}
+	if _, err := s.Do(s.Store().CommandFactory().CreateDeleteCommand(key, false, false)); err != nil {
+		log.Debugf("[ephemeral] cannot delete %s: %v", key, err)
+	}
+}
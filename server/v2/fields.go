@@ -0,0 +1,139 @@
+package v2
+
+import (
+	"net/http"
+	"strings"
+
+	etcdErr "github.com/coreos/etcd/error"
+	"github.com/coreos/etcd/store"
+)
+
+// The node fields that can be selected with "fields".
+var nodeFields = map[string]bool{
+	"key":           true,
+	"value":         true,
+	"dir":           true,
+	"expiration":    true,
+	"ttl":           true,
+	"nodes":         true,
+	"childCount":    true,
+	"deleted":       true,
+	"deletedIndex":  true,
+	"modifiedIndex": true,
+	"createdIndex":  true,
+	"raftTerm":      true,
+	"raftIndex":     true,
+}
+
+// fieldSet is the part of an event a client asked for with "fields", like
+// "action,node.key,node.modifiedIndex". Selecting "node.nodes" includes the
+// children of a directory with the same fields as the node.
+type fieldSet struct {
+	action bool
+	node   bool
+	fields map[string]bool
+}
+
+// parseFields reads the fields selected by the request, if any.
+func parseFields(req *http.Request, s Server) (*fieldSet, error) {
+	value := req.FormValue("fields")
+	if value == "" {
+		return nil, nil
+	}
+
+	f := &fieldSet{fields: make(map[string]bool)}
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		switch {
+		case name == "action":
+			f.action = true
+		case name == "node":
+			f.node = true
+		case strings.HasPrefix(name, "node.") && nodeFields[name[len("node."):]]:
+			f.fields[name[len("node."):]] = true
+		default:
+			return nil, etcdErr.NewError(etcdErr.EcodeInvalidField, name, s.Store().Index())
+		}
+	}
+	return f, nil
+}
+
+// project returns what is written to the client for an event. It is the
+// event itself unless fields were selected.
+func (f *fieldSet) project(e *store.Event) interface{} {
+	if f == nil {
+		return e
+	}
+
+	m := make(map[string]interface{})
+	if f.action {
+		m["action"] = e.Action
+	}
+	if e.Node != nil {
+		if f.node {
+			m["node"] = e.Node
+		} else if len(f.fields) > 0 {
+			m["node"] = f.projectNode(e.Node)
+		}
+	}
+	return m
+}
+
+// projectNode copies the selected fields of a node. Fields a node does not
+// have, like the value of a directory, are left out as in a full response.
+func (f *fieldSet) projectNode(n *store.NodeExtern) map[string]interface{} {
+	m := make(map[string]interface{}, len(f.fields))
+	for name := range f.fields {
+		switch name {
+		case "key":
+			m[name] = n.Key
+		case "value":
+			if !n.Dir {
+				m[name] = n.Value
+			}
+		case "dir":
+			if n.Dir {
+				m[name] = true
+			}
+		case "expiration":
+			if n.Expiration != nil {
+				m[name] = n.Expiration
+			}
+		case "ttl":
+			if n.TTL != 0 {
+				m[name] = n.TTL
+			}
+		case "nodes":
+			if len(n.Nodes) > 0 {
+				nodes := make([]map[string]interface{}, len(n.Nodes))
+				for i := range n.Nodes {
+					nodes[i] = f.projectNode(&n.Nodes[i])
+				}
+				m[name] = nodes
+			}
+		case "childCount":
+			if n.ChildCount != 0 {
+				m[name] = n.ChildCount
+			}
+		case "deleted":
+			if n.Deleted {
+				m[name] = true
+			}
+		case "deletedIndex":
+			if n.DeletedIndex != 0 {
+				m[name] = n.DeletedIndex
+			}
+		case "modifiedIndex":
+			m[name] = n.ModifiedIndex
+		case "createdIndex":
+			m[name] = n.CreatedIndex
+		case "raftTerm":
+			m[name] = n.RaftTerm
+		case "raftIndex":
+			if n.RaftIndex != 0 {
+				m[name] = n.RaftIndex
+			}
+		}
+	}
+	return m
+}
@@ -5,15 +5,27 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"regexp"
 	"strconv"
+	"time"
 
 	etcdErr "github.com/coreos/etcd/error"
+	"github.com/coreos/etcd/lifetime"
 	"github.com/coreos/etcd/log"
 	"github.com/coreos/etcd/store"
 	"github.com/coreos/raft"
 	"github.com/gorilla/mux"
 )
 
+// How long a read waits for the member to reach the minIndex it asked for.
+const minIndexTimeout = time.Second
+
+// How often the store index is checked while waiting for minIndex.
+const minIndexPoll = 10 * time.Millisecond
+
+// validCallback matches the JSONP callback names that are safe to echo back.
+var validCallback = regexp.MustCompile(`^[a-zA-Z_$][a-zA-Z0-9_$.]*$`)
+
 func GetHandler(w http.ResponseWriter, req *http.Request, s Server) error {
 	var err error
 	var event *store.Event
@@ -23,26 +35,65 @@ func GetHandler(w http.ResponseWriter, req *http.Request, s Server) error {
 
 	// Help client to redirect the request to the current leader
 	if req.FormValue("consistent") == "true" && s.State() != raft.Leader {
-		leader := s.Leader()
-		hostname, _ := s.ClientURL(leader)
+		return redirectToLeader(w, req, s)
+	}
 
-		url, err := url.Parse(hostname)
+	// A client that wrote at some index can read its own write from any
+	// member by passing that index. Members that are behind wait for it
+	// to be applied, then hand the client over to the leader.
+	if minIndex := req.FormValue("minIndex"); minIndex != "" {
+		index, err := strconv.ParseUint(minIndex, 10, 64)
 		if err != nil {
-			log.Warn("Redirect cannot parse hostName ", hostname)
-			return err
+			return etcdErr.NewError(etcdErr.EcodeIndexNaN, "Minimum Index", s.Store().Index())
+		}
+		if !waitForIndex(w, s, index) {
+			if s.State() == raft.Leader || s.Leader() == "" {
+				return etcdErr.NewError(etcdErr.EcodeIndexNotReached, minIndex, s.Store().Index())
+			}
+			return redirectToLeader(w, req, s)
 		}
-		url.RawQuery = req.URL.RawQuery
-		url.Path = req.URL.Path
+	}
+
+	// JSONP sidesteps the browser's origin checks so it is only honored
+	// when the server already allows every CORS origin.
+	callback := req.FormValue("callback")
+	if callback != "" {
+		if !s.OriginAllowed("*") {
+			callback = ""
+		} else if !validCallback.MatchString(callback) {
+			return etcdErr.NewError(etcdErr.EcodeInvalidCallback, callback, s.Store().Index())
+		}
+	}
 
-		log.Debugf("Redirect consistent get to %s", url.String())
-		http.Redirect(w, req, url.String(), http.StatusTemporaryRedirect)
-		return nil
+	fields, err := parseFields(req, s)
+	if err != nil {
+		return err
 	}
 
 	recursive := (req.FormValue("recursive") == "true")
 	sorted := (req.FormValue("sorted") == "true")
 
-	if req.FormValue("wait") == "true" { // watch
+	// The index reported to the client when it differs from the current one.
+	var index uint64
+
+	// Listings for key completion only need the keys, and clients sizing
+	// a directory only the number of children.
+	var keys, count bool
+
+	if req.FormValue("wait") == "true" && req.FormValue("withCurrent") == "true" {
+		// Return the current node with the index it was read at, so the client
+		// can watch from the next one without missing a change. If the key does
+		// not exist yet then wait for it from that index.
+		event, index, err = s.Store().GetWithIndex(key, recursive, sorted)
+		if e, ok := err.(*etcdErr.Error); ok && e.ErrorCode == etcdErr.EcodeKeyNotFound {
+			event, err = waitForEvent(w, s, key, recursive, index+1)
+			index = 0
+		}
+		if err != nil || event == nil {
+			return err
+		}
+
+	} else if req.FormValue("wait") == "true" { // watch
 		// Create a command to watch from a given index (default 0).
 		var sinceIndex uint64 = 0
 
@@ -54,37 +105,142 @@ func GetHandler(w http.ResponseWriter, req *http.Request, s Server) error {
 			}
 		}
 
-		// Start the watcher on the store.
-		eventChan, err := s.Store().Watch(key, recursive, sinceIndex)
+		interval, err := coalesceInterval(req, s)
 		if err != nil {
-			return etcdErr.NewError(500, key, s.Store().Index())
+			return err
 		}
-
-		cn, _ := w.(http.CloseNotifier)
-		closeChan := cn.CloseNotify()
-
-		select {
-		case <-closeChan:
-			return nil
-		case event = <-eventChan:
+		if interval > 0 {
+			event, err = waitForCoalescedEvent(w, s, key, recursive, sinceIndex, interval)
+		} else {
+			event, err = waitForEvent(w, s, key, recursive, sinceIndex)
+		}
+		if err != nil || event == nil {
+			return err
 		}
 
 	} else { //get
-		// Retrieve the key from the store.
-		event, err = s.Store().Get(key, recursive, sorted)
+		count = req.FormValue("countOnly") == "true"
+		if count {
+			recursive, sorted = false, false
+		}
+
+		// Retrieve the key from the store, as it was at a past index, or
+		// with the tombstones of deleted keys for clients catching up on
+		// deletions.
+		if atIndex := req.FormValue("atIndex"); atIndex != "" {
+			i, e := strconv.ParseUint(atIndex, 10, 64)
+			if e != nil {
+				return etcdErr.NewError(etcdErr.EcodeIndexNaN, "At Index", s.Store().Index())
+			}
+			event, err = s.Store().GetAtIndex(key, i)
+		} else if req.FormValue("includeTombstones") == "true" {
+			event, err = s.Store().GetWithTombstones(key, recursive, sorted)
+		} else {
+			event, err = s.Store().Get(key, recursive, sorted)
+		}
 		if err != nil {
 			return err
 		}
+		keys = req.FormValue("keysOnly") == "true"
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Add("X-Etcd-Index", fmt.Sprint(s.Store().Index()))
+	if callback != "" {
+		w.Header().Set("Content-Type", "application/javascript")
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	if index == 0 {
+		index = s.Store().Index()
+	}
+	w.Header().Add("X-Etcd-Index", fmt.Sprint(index))
 	w.Header().Add("X-Raft-Index", fmt.Sprint(s.CommitIndex()))
 	w.Header().Add("X-Raft-Term", fmt.Sprint(s.Term()))
+	if s.Store().Restoring() {
+		// The member is loading a snapshot and may be behind the cluster.
+		w.Header().Set("X-Etcd-Stale", "true")
+	}
 	w.WriteHeader(http.StatusOK)
-	b, _ := json.Marshal(event)
+	event = s.RevealEvent(req, event)
+	if keys {
+		keysOnly(event.Node)
+	}
+	if count {
+		event.Node.Nodes = nil
+	}
+	b, _ := json.Marshal(fields.project(event))
 
-	w.Write(b)
+	if callback != "" {
+		fmt.Fprintf(w, "%s(%s);", callback, b)
+	} else {
+		w.Write(b)
+	}
 
 	return nil
 }
+
+// waitForEvent waits for the first change to a key since the given index.
+// It returns no event if the client disconnects first.
+func waitForEvent(w http.ResponseWriter, s Server, key string, recursive bool, sinceIndex uint64) (*store.Event, error) {
+	// Start the watcher on the store.
+	eventChan, err := s.Store().Watch(key, recursive, sinceIndex)
+	if err != nil {
+		return nil, etcdErr.NewError(500, key, s.Store().Index())
+	}
+
+	l := lifetime.Start(w, -1)
+	defer l.Stop()
+
+	select {
+	case <-l.Done():
+		return nil, nil
+	case event := <-eventChan:
+		return event, nil
+	}
+}
+
+// keysOnly drops the values of a node and of its children.
+func keysOnly(n *store.NodeExtern) {
+	n.Value = ""
+	for i := range n.Nodes {
+		keysOnly(&n.Nodes[i])
+	}
+}
+
+// redirectToLeader sends the client the same request on the current leader.
+func redirectToLeader(w http.ResponseWriter, req *http.Request, s Server) error {
+	leader := s.Leader()
+	hostname, _ := s.ClientURL(leader)
+
+	url, err := url.Parse(hostname)
+	if err != nil {
+		log.Warn("Redirect cannot parse hostName ", hostname)
+		return err
+	}
+	url.RawQuery = req.URL.RawQuery
+	url.Path = req.URL.Path
+
+	log.Debugf("Redirect get to %s", url.String())
+	http.Redirect(w, req, url.String(), http.StatusTemporaryRedirect)
+	return nil
+}
+
+// waitForIndex waits for the store to reach the given index. It returns
+// false if the index was not reached within minIndexTimeout or the client
+// disconnects first.
+func waitForIndex(w http.ResponseWriter, s Server, index uint64) bool {
+	if s.Store().Index() >= index {
+		return true
+	}
+
+	l := lifetime.Start(w, minIndexTimeout)
+	defer l.Stop()
+
+	for s.Store().Index() < index {
+		select {
+		case <-l.Done():
+			return false
+		case <-time.After(minIndexPoll):
+		}
+	}
+	return true
+}
@@ -2,6 +2,7 @@ package v2
 
 import (
 	"net/http"
+	"strconv"
 
 	etcdErr "github.com/coreos/etcd/error"
 	"github.com/coreos/etcd/store"
@@ -19,6 +20,29 @@ func PostHandler(w http.ResponseWriter, req *http.Request, s Server) error {
 		return etcdErr.NewError(etcdErr.EcodeTTLNaN, "Create", s.Store().Index())
 	}
 
+	// Move the value of another key into the queue instead of using "value".
+	// With moveFromIndex the key must still be at that index, and "value"
+	// is stored in place of the moved one.
+	if moveFrom := req.FormValue("moveFrom"); moveFrom != "" {
+		var srcIndex uint64
+		if i := req.FormValue("moveFromIndex"); i != "" {
+			if srcIndex, err = strconv.ParseUint(i, 10, 64); err != nil {
+				return etcdErr.NewError(etcdErr.EcodeIndexNaN, "Create", s.Store().Index())
+			}
+		}
+		c := s.Store().CommandFactory().CreateMoveToQueueCommand(key, moveFrom, srcIndex, value, expireTime)
+		return s.Dispatch(c, w, req)
+	}
+
+	// An ephemeral queue entry lives as long as the connection.
+	if req.FormValue("ephemeral") == "true" && !dir {
+		ttl, err := ephemeralTTL(req, s)
+		if err != nil {
+			return err
+		}
+		return EphemeralHandler(w, req, s, key, true, value, ttl, true)
+	}
+
 	c := s.Store().CommandFactory().CreateCreateCommand(key, dir, value, expireTime, true)
 	return s.Dispatch(c, w, req)
 }
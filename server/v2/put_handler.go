@@ -36,6 +36,28 @@ func PutHandler(w http.ResponseWriter, req *http.Request, s Server) error {
 	_, existOk := req.Form["prevExist"]
 	prevExist := req.FormValue("prevExist")
 
+	// Ephemeral keys are set, or created with prevExist=false, and live as
+	// long as the connection.
+	if req.FormValue("ephemeral") == "true" {
+		if dir || valueOk || indexOk || (existOk && prevExist != "false") {
+			return etcdErr.NewError(etcdErr.EcodeNotFile, "Ephemeral", s.Store().Index())
+		}
+		ttl, err := ephemeralTTL(req, s)
+		if err != nil {
+			return err
+		}
+		return EphemeralHandler(w, req, s, key, false, value, ttl, existOk)
+	}
+
+	// Refresh handler: extend the TTL of an existing node without changing
+	// it or waking its watchers up.
+	if req.FormValue("refresh") == "true" {
+		if _, ok := req.Form["value"]; ok || valueOk || indexOk {
+			return etcdErr.NewError(etcdErr.EcodeRefreshValue, "Refresh", s.Store().Index())
+		}
+		return RefreshHandler(w, req, s, key, expireTime)
+	}
+
 	// Set handler: create a new node or replace the old one.
 	if !valueOk && !indexOk && !existOk {
 		return SetHandler(w, req, s, key, dir, value, expireTime)
@@ -96,3 +118,12 @@ func UpdateHandler(w http.ResponseWriter, req *http.Request, s Server, key, valu
 	c := s.Store().CommandFactory().CreateUpdateCommand(key, value, expireTime)
 	return s.Dispatch(c, w, req)
 }
+
+func RefreshHandler(w http.ResponseWriter, req *http.Request, s Server, key string, expireTime time.Time) error {
+	if req.Form.Get("ttl") == "" {
+		return etcdErr.NewError(etcdErr.EcodeRefreshTTLRequired, "Refresh", s.Store().Index())
+	}
+
+	c := s.Store().CommandFactory().CreateRefreshCommand(key, expireTime)
+	return s.Dispatch(c, w, req)
+}
@@ -0,0 +1,83 @@
+package v2
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	etcdErr "github.com/coreos/etcd/error"
+	"github.com/coreos/etcd/lifetime"
+	"github.com/coreos/etcd/log"
+	"github.com/coreos/etcd/store"
+)
+
+// streamEvent is a change as it is sent on a stream. The sequence number is
+// the index the change was committed at, so it only increases, across all
+// of the prefixes of the stream and from one stream to the next.
+type streamEvent struct {
+	Sequence uint64 `json:"sequence"`
+	*store.Event
+}
+
+// StreamHandler streams every change under any of the "prefix" parameters
+// in the order the changes were committed, one JSON object per line, until
+// the client disconnects. Streaming again with a "waitIndex" of the last
+// sequence number received plus one picks up where the stream stopped.
+// A client that falls more than the queue behind gets an error and is
+// disconnected.
+func StreamHandler(w http.ResponseWriter, req *http.Request, s Server) error {
+	var err error
+	req.ParseForm()
+
+	prefixes := req.Form["prefix"]
+	if len(prefixes) == 0 {
+		return etcdErr.NewError(etcdErr.EcodeInvalidField, "Prefix", s.Store().Index())
+	}
+
+	// Stream from a given index (default 0).
+	var sinceIndex uint64 = 0
+	if waitIndex := req.FormValue("waitIndex"); waitIndex != "" {
+		sinceIndex, err = strconv.ParseUint(waitIndex, 10, 64)
+		if err != nil {
+			return etcdErr.NewError(etcdErr.EcodeIndexNaN, "Watch From Index", s.Store().Index())
+		}
+	}
+
+	watcher, err := s.Store().WatchStreams(prefixes, true, sinceIndex, watchQueueSize)
+	if err != nil {
+		return err
+	}
+	defer watcher.Remove()
+
+	l := lifetime.Start(w, -1)
+	defer l.Stop()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	f, _ := w.(http.Flusher)
+	if f != nil {
+		f.Flush()
+	}
+
+	enc := json.NewEncoder(w)
+	for {
+		select {
+		case event, ok := <-watcher.EventChan():
+			if !ok {
+				log.Infof("stream %s: evicted slow watcher at index %d remote=%s", strings.Join(prefixes, ","), sinceIndex, req.RemoteAddr)
+				enc.Encode(etcdErr.NewError(etcdErr.EcodeWatcherTooSlow, strings.Join(prefixes, ","), s.Store().Index()))
+				return nil
+			}
+			sinceIndex = event.Index() + 1
+			if err := enc.Encode(&streamEvent{event.Index(), s.RevealEvent(req, event)}); err != nil {
+				return nil
+			}
+			if f != nil {
+				f.Flush()
+			}
+		case <-l.Done():
+			return nil
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package v2
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/coreos/etcd/server"
+	"github.com/coreos/etcd/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+// Ensures that blocking requests are listed and can be cancelled.
+//
+//   $ curl localhost:4001/v2/keys/foo/bar?wait=true
+//   $ curl localhost:4001/v2/admin/requests
+//   $ curl -X DELETE localhost:4001/v2/admin/requests/1
+//
+func TestV2ActiveRequests(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		c := make(chan map[string]interface{})
+		go func() {
+			resp, _ := tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo/bar?wait=true"))
+			c <- tests.ReadBodyJSON(resp)
+		}()
+		time.Sleep(50 * time.Millisecond)
+
+		resp, _ := tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/admin/requests"))
+		body := tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["total"], 1, "")
+		r := body["requests"].([]interface{})[0].(map[string]interface{})
+		assert.Equal(t, r["kind"], "watch", "")
+		assert.Equal(t, r["key"], "/foo/bar", "")
+		assert.NotEqual(t, r["remoteAddr"], "", "")
+		assert.NotEqual(t, r["age"], "", "")
+
+		resp, _ = tests.Delete(fmt.Sprintf("%s/v2/admin/requests/%v", s.URL(), r["id"]), "", nil)
+		assert.Equal(t, resp.StatusCode, 200, "")
+		tests.ReadBody(resp)
+
+		select {
+		case body := <-c:
+			assert.Equal(t, body["errorCode"], 407, "")
+		case <-time.After(time.Second):
+			t.Fatal("cancelled watch did not return")
+		}
+
+		resp, _ = tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/admin/requests"))
+		assert.Equal(t, tests.ReadBodyJSON(resp)["total"], 0, "")
+
+		resp, _ = tests.Delete(fmt.Sprintf("%s/v2/admin/requests/%v", s.URL(), r["id"]), "", nil)
+		assert.Equal(t, resp.StatusCode, 404, "")
+		tests.ReadBody(resp)
+	})
+}
@@ -0,0 +1,75 @@
+package v2
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/coreos/etcd/server"
+	"github.com/coreos/etcd/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+// Ensures that watches beyond the limit are rejected while other requests
+// are still served.
+//
+//   $ curl localhost:4001/v2/keys/foo?wait=true
+//   $ curl localhost:4001/v2/keys/bar?wait=true
+//   $ curl localhost:4001/v2/stats/blocking
+//
+func TestV2MaxBlockingRequests(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		s.MaxBlockingRequests = 1
+
+		c := make(chan bool)
+		go func() {
+			resp, _ := tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo?wait=true"))
+			tests.ReadBody(resp)
+			c <- true
+		}()
+		time.Sleep(50 * time.Millisecond)
+
+		resp, _ := tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/bar?wait=true"))
+		body := tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["errorCode"], 402, "")
+
+		resp, _ = tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/stats/blocking"))
+		body = tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["active"], 1, "")
+		assert.Equal(t, body["max"], 1, "")
+		assert.Equal(t, body["rejected"], 1, "")
+
+		// Writes are not limited and release the watch.
+		v := url.Values{}
+		v.Set("value", "XXX")
+		resp, _ = tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo"), v)
+		assert.Equal(t, resp.StatusCode, 201, "")
+		tests.ReadBody(resp)
+		<-c
+
+		resp, _ = tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/stats/blocking"))
+		body = tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["active"], 0, "")
+	})
+}
+
+// Ensures that lock acquisitions count against the limit.
+//
+//   $ curl -X POST localhost:4001/mod/v2/lock/foo?ttl=10
+//
+func TestV2MaxBlockingRequestsMod(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		s.MaxBlockingRequests = 1
+
+		go func() {
+			resp, _ := tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo?wait=true"))
+			tests.ReadBody(resp)
+		}()
+		time.Sleep(50 * time.Millisecond)
+
+		resp, _ := tests.PostForm(fmt.Sprintf("%s%s", s.URL(), "/mod/v2/lock/foo?ttl=10"), nil)
+		body := tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["errorCode"], 402, "")
+	})
+}
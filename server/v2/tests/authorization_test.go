@@ -0,0 +1,85 @@
+package v2
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/coreos/etcd/server"
+	"github.com/coreos/etcd/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+// Ensures that writes without a matching write rule are rejected while
+// reads are still served.
+//
+//   $ curl -X PUT localhost:4001/v2/keys/services/web -d value=XXX
+//
+func TestV2WriteRules(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		v := url.Values{}
+		v.Set("value", "XXX")
+		resp, _ := tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/services/web"), v)
+		assert.Equal(t, resp.StatusCode, 201, "")
+		tests.ReadBody(resp)
+
+		assert.Nil(t, s.AllowWriters([]string{"/services=web"}), "")
+		resp, _ = tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/services/web"), v)
+		assert.Equal(t, resp.StatusCode, 400, "")
+		body := tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["errorCode"], 110, "")
+		assert.Equal(t, body["cause"], "/services/web", "")
+
+		resp, _ = tests.DeleteForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/services/web"), nil)
+		assert.Equal(t, resp.StatusCode, 400, "")
+		tests.ReadBody(resp)
+
+		resp, _ = tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/services/web"))
+		assert.Equal(t, resp.StatusCode, 200, "")
+		tests.ReadBody(resp)
+
+		// Module writes need access to the keys of the module.
+		resp, _ = tests.PostForm(fmt.Sprintf("%s%s", s.URL(), "/mod/v2/lock/web?ttl=10"), nil)
+		assert.Equal(t, resp.StatusCode, 403, "")
+		tests.ReadBody(resp)
+	})
+}
+
+// Ensures that a client allowed to write the keys of a module can use it,
+// although the module writes them in-process, without the client certificate.
+func TestV2ModWriteRules(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		assert.Nil(t, s.AllowWriters([]string{"/_etcd/mod=web"}), "")
+
+		w := serveAs(s, "web", "POST", "/mod/v2/lock/web?ttl=10", nil)
+		assert.Equal(t, w.Code, 200, w.Body.String())
+		assert.Equal(t, w.Body.String(), "2", "")
+
+		w = serveAs(s, "web", "POST", "/mod/v2/lease?ttl=10", nil)
+		assert.Equal(t, w.Code, 200, w.Body.String())
+
+		// The rules still apply to the clients themselves.
+		resp, _ := tests.PostForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/_etcd/mod/lock/web"), url.Values{"value": {"XXX"}})
+		assert.Equal(t, resp.StatusCode, 400, "")
+		tests.ReadBody(resp)
+	})
+}
+
+// serveAs serves a request the way one sent over TLS with a verified client
+// certificate for name is.
+func serveAs(s *server.Server, name string, method string, path string, v url.Values) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, strings.NewReader(v.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.RemoteAddr = "127.0.0.1:0"
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: name}}
+	req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+	w := httptest.NewRecorder()
+	s.Handler.ServeHTTP(w, req)
+	return w
+}
@@ -0,0 +1,83 @@
+package v2
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/coreos/etcd/server"
+	"github.com/coreos/etcd/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+// Ensures that the snapshot thresholds can be changed at runtime.
+//
+//   $ curl localhost:4001/v2/admin/config
+//   $ curl -X PUT localhost:4001/v2/admin/config -d '{"snapshotBytes":1048576}'
+//
+func TestV2AdminConfig(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		resp, _ := tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/admin/config"))
+		assert.Equal(t, resp.StatusCode, 200, "")
+		body := tests.ReadBodyJSON(resp)
+		count := body["snapshotCount"]
+		assert.Equal(t, body["snapshotBytes"], 0, "")
+
+		resp, _ = tests.Put(fmt.Sprintf("%s%s", s.URL(), "/v2/admin/config"), "application/json", strings.NewReader(`{"snapshotBytes":1048576,"maxLogBytes":4194304}`))
+		assert.Equal(t, resp.StatusCode, 200, "")
+		body = tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["snapshotCount"], count, "")
+		assert.Equal(t, body["snapshotBytes"], float64(1048576), "")
+		assert.Equal(t, body["maxLogBytes"], float64(4194304), "")
+
+		resp, _ = tests.Put(fmt.Sprintf("%s%s", s.URL(), "/v2/admin/config"), "application/json", strings.NewReader(`{"snapshotBytes":-1}`))
+		assert.Equal(t, resp.StatusCode, 400, "")
+		tests.ReadBody(resp)
+
+		resp, _ = tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/admin/config"))
+		body = tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["snapshotBytes"], float64(1048576), "")
+	})
+}
+
+// Ensures that debug modes can be switched on at runtime and that profiling
+// is only served while it is on.
+//
+//   $ curl -X PUT localhost:4001/v2/admin/config -d '{"debug":{"pprof":true,"ttl":60}}'
+//   $ curl localhost:4001/debug/pprof/
+//
+func TestV2AdminConfigDebug(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		resp, _ := tests.Get(fmt.Sprintf("%s%s", s.URL(), "/debug/pprof/"))
+		assert.Equal(t, resp.StatusCode, 404, "")
+		tests.ReadBody(resp)
+
+		resp, _ = tests.Put(fmt.Sprintf("%s%s", s.URL(), "/v2/admin/config"), "application/json", strings.NewReader(`{"debug":{"pprof":true,"ttl":60}}`))
+		assert.Equal(t, resp.StatusCode, 200, "")
+		body := tests.ReadBodyJSON(resp)
+		debug := body["debug"].(map[string]interface{})
+		assert.Equal(t, debug["pprof"], true, "")
+		assert.Equal(t, debug["verbose"], false, "")
+		assert.Equal(t, debug["ttl"], float64(60), "")
+		assert.NotNil(t, debug["expiration"], "")
+
+		resp, _ = tests.Get(fmt.Sprintf("%s%s", s.URL(), "/debug/pprof/goroutine?debug=1"))
+		assert.Equal(t, resp.StatusCode, 200, "")
+		tests.ReadBody(resp)
+
+		// Leaving debug out keeps the modes as they are.
+		resp, _ = tests.Put(fmt.Sprintf("%s%s", s.URL(), "/v2/admin/config"), "application/json", strings.NewReader(`{"snapshotBytes":1048576}`))
+		body = tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["debug"].(map[string]interface{})["pprof"], true, "")
+
+		resp, _ = tests.Put(fmt.Sprintf("%s%s", s.URL(), "/v2/admin/config"), "application/json", strings.NewReader(`{"debug":{"pprof":false}}`))
+		body = tests.ReadBodyJSON(resp)
+		debug = body["debug"].(map[string]interface{})
+		assert.Equal(t, debug["pprof"], false, "")
+		assert.Nil(t, debug["expiration"], "")
+
+		resp, _ = tests.Get(fmt.Sprintf("%s%s", s.URL(), "/debug/pprof/"))
+		assert.Equal(t, resp.StatusCode, 404, "")
+		tests.ReadBody(resp)
+	})
+}
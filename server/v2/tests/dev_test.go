@@ -0,0 +1,36 @@
+package v2
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/coreos/etcd/server"
+	"github.com/coreos/etcd/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+// Ensures that a dev instance sets the keys of its fixtures once it serves.
+//
+//   $ etcd -dev -dev-fixtures fixtures.json
+//   $ curl localhost:4001/v2/keys/config/workers
+//
+func TestV2DevFixtures(t *testing.T) {
+	f, _ := ioutil.TempFile("", "fixtures")
+	f.WriteString(`{"config": {"db": "postgres://localhost", "workers": 4}, "queue": {}}`)
+	f.Close()
+	defer os.Remove(f.Name())
+
+	tests.RunServer(func(s *server.Server) {
+		assert.Nil(t, s.StartDev(f.Name()), "")
+
+		resp, _ := tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/config/workers"))
+		body := tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["node"].(map[string]interface{})["value"], "4", "")
+
+		resp, _ = tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/queue"))
+		body = tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["node"].(map[string]interface{})["dir"], true, "")
+	})
+}
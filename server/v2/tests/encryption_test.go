@@ -0,0 +1,105 @@
+package v2
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/coreos/etcd/server"
+	"github.com/coreos/etcd/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+// Ensures that the values under an encrypted prefix are stored encrypted and
+// only decrypted for the clients allowed to write them.
+//
+//   $ curl -X PUT localhost:4001/v2/keys/secrets/db -d value=hunter2
+//   $ curl -X PUT localhost:4001/v2/keys/secrets/db -d value=hunter3 -d prevValue=hunter2
+//
+func TestV2EncryptedPrefix(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		f, _ := ioutil.TempFile("", "etcd-key")
+		defer os.Remove(f.Name())
+		f.WriteString("000102030405060708090a0b0c0d0e0f")
+		f.Close()
+		c, err := server.NewKeyFileCipher(f.Name())
+		assert.Nil(t, err, "")
+		assert.Nil(t, s.EncryptValues([]string{"/secrets"}, c), "")
+
+		v := url.Values{}
+		v.Set("value", "hunter2")
+		resp, _ := tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/secrets/db"), v)
+		body := tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["node"].(map[string]interface{})["value"], "hunter2", "")
+
+		e, _ := s.Store().Get("/secrets/db", false, false)
+		assert.True(t, strings.HasPrefix(e.Node.Value, "etcd-aes-gcm:"), "")
+		assert.False(t, strings.Contains(e.Node.Value, "hunter2"), "")
+
+		v.Set("value", "hunter3")
+		v.Set("prevValue", "wrong")
+		resp, _ = tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/secrets/db"), v)
+		body = tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["errorCode"], 101, "")
+
+		v.Set("prevValue", "hunter2")
+		resp, _ = tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/secrets/db"), v)
+		assert.Equal(t, resp.StatusCode, 200, "")
+		tests.ReadBody(resp)
+
+		resp, _ = tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/secrets?recursive=true"))
+		body = tests.ReadBodyJSON(resp)
+		node := body["node"].(map[string]interface{})["nodes"].([]interface{})[0].(map[string]interface{})
+		assert.Equal(t, node["value"], "hunter3", "")
+
+		// Clients that may not write the key only see the encrypted value.
+		assert.Nil(t, s.AllowWriters([]string{"/secrets=ops"}), "")
+		resp, _ = tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/secrets/db"))
+		body = tests.ReadBodyJSON(resp)
+		assert.True(t, strings.HasPrefix(body["node"].(map[string]interface{})["value"].(string), "etcd-aes-gcm:"), "")
+	})
+}
+
+// Ensures that a value moved into an encrypted prefix is stored encrypted,
+// and one moved out of it is stored decrypted.
+//
+//   $ curl -X POST localhost:4001/v2/keys/secrets/queue -d moveFrom=/jobs/db
+//
+func TestV2EncryptedPrefixMoveFrom(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		f, _ := ioutil.TempFile("", "etcd-key")
+		defer os.Remove(f.Name())
+		f.WriteString("000102030405060708090a0b0c0d0e0f")
+		f.Close()
+		c, err := server.NewKeyFileCipher(f.Name())
+		assert.Nil(t, err, "")
+		assert.Nil(t, s.EncryptValues([]string{"/secrets"}, c), "")
+
+		v := url.Values{}
+		v.Set("value", "hunter2")
+		resp, _ := tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/jobs/db"), v)
+		tests.ReadBody(resp)
+
+		v = url.Values{}
+		v.Set("moveFrom", "/jobs/db")
+		resp, _ = tests.PostForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/secrets/queue"), v)
+		assert.Equal(t, resp.StatusCode, 201, "")
+		body := tests.ReadBodyJSON(resp)
+		node := body["node"].(map[string]interface{})
+		assert.Equal(t, node["value"], "hunter2", "")
+
+		key := node["key"].(string)
+		e, _ := s.Store().Get(key, false, false)
+		assert.True(t, strings.HasPrefix(e.Node.Value, "etcd-aes-gcm:"), "")
+
+		v.Set("moveFrom", key)
+		resp, _ = tests.PostForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/jobs"), v)
+		assert.Equal(t, resp.StatusCode, 201, "")
+		body = tests.ReadBodyJSON(resp)
+		e, _ = s.Store().Get(body["node"].(map[string]interface{})["key"].(string), false, false)
+		assert.Equal(t, e.Node.Value, "hunter2", "")
+	})
+}
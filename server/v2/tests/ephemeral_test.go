@@ -0,0 +1,90 @@
+package v2
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/coreos/etcd/server"
+	"github.com/coreos/etcd/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+// Ensures that an ephemeral key outlives its TTL while the connection is
+// open and is deleted once the client disconnects.
+//
+//   $ curl -X PUT localhost:4001/v2/keys/foo -d value=XXX -d ephemeral=true -d ttl=1
+//
+func TestV2EphemeralKey(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		v := url.Values{}
+		v.Set("value", "XXX")
+		v.Set("ephemeral", "true")
+		v.Set("ttl", "1")
+		ephemeral, err := tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo"), v)
+		assert.NoError(t, err)
+
+		var body map[string]interface{}
+		assert.NoError(t, json.NewDecoder(ephemeral.Body).Decode(&body))
+		assert.Equal(t, body["action"], "set", "")
+
+		time.Sleep(1500 * time.Millisecond)
+		resp, _ := tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo"))
+		get := tests.ReadBodyJSON(resp)
+		node := get["node"].(map[string]interface{})
+		assert.Equal(t, node["value"], "XXX", "")
+
+		// Keeping the key alive does not change it.
+		assert.Equal(t, node["modifiedIndex"], body["node"].(map[string]interface{})["modifiedIndex"], "")
+
+		ephemeral.Body.Close()
+		time.Sleep(100 * time.Millisecond)
+		resp, _ = tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo"))
+		get = tests.ReadBodyJSON(resp)
+		assert.Equal(t, get["errorCode"], 100, "")
+	})
+}
+
+// Ensures that an ephemeral in-order key is named after its index and is
+// deleted once the client disconnects.
+//
+//   $ curl -X POST localhost:4001/v2/keys/queue -d value=XXX -d ephemeral=true
+//
+func TestV2EphemeralUniqueKey(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		v := url.Values{}
+		v.Set("value", "XXX")
+		v.Set("ephemeral", "true")
+		resp, err := tests.PostForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/queue"), v)
+		assert.NoError(t, err)
+
+		var body map[string]interface{}
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		assert.Equal(t, body["action"], "create", "")
+		key := body["node"].(map[string]interface{})["key"].(string)
+
+		resp.Body.Close()
+		time.Sleep(100 * time.Millisecond)
+		resp, _ = tests.Get(fmt.Sprintf("%s/v2/keys%s", s.URL(), key))
+		get := tests.ReadBodyJSON(resp)
+		assert.Equal(t, get["errorCode"], 100, "")
+	})
+}
+
+// Ensures that an ephemeral key cannot be written conditionally.
+//
+//   $ curl -X PUT localhost:4001/v2/keys/foo -d value=XXX -d ephemeral=true -d prevValue=YYY
+//
+func TestV2EphemeralKeyConditional(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		v := url.Values{}
+		v.Set("value", "XXX")
+		v.Set("ephemeral", "true")
+		v.Set("prevValue", "YYY")
+		resp, _ := tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo"), v)
+		body := tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["errorCode"], 102, "")
+	})
+}
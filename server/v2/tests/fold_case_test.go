@@ -0,0 +1,50 @@
+package v2
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/coreos/etcd/server"
+	"github.com/coreos/etcd/store"
+	"github.com/coreos/etcd/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+// Ensures that the keys under a case-insensitive prefix are stored in lower
+// case and found whatever the case a client uses.
+//
+//   $ curl -X PUT localhost:4001/v2/keys/hosts/Web1.Example.com -d value=10.0.0.1
+//   $ curl localhost:4001/v2/keys/HOSTS/web1.example.COM
+//
+func TestV2FoldCasePrefix(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		assert.Nil(t, s.FoldKeyCase([]string{"/hosts"}), "")
+
+		v := url.Values{}
+		v.Set("value", "10.0.0.1")
+		resp, _ := tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/hosts/Web1.Example.com"), v)
+		body := tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["node"].(map[string]interface{})["key"], "/hosts/web1.example.com", "")
+
+		resp, _ = tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/HOSTS/web1.example.COM"))
+		body = tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["node"].(map[string]interface{})["value"], "10.0.0.1", "")
+
+		// Keys outside of the prefix keep their case.
+		resp, _ = tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/Other"), v)
+		body = tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["node"].(map[string]interface{})["key"], "/Other", "")
+
+		assert.Nil(t, s.CheckFoldedKeys(), "")
+	})
+}
+
+// Ensures that a prefix holding keys in another case is rejected.
+func TestV2FoldCasePrefixConflict(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		s.Store().Set("/Hosts/web1", false, "10.0.0.1", store.Permanent)
+		assert.Nil(t, s.FoldKeyCase([]string{"/hosts"}), "")
+		assert.NotNil(t, s.CheckFoldedKeys(), "")
+	})
+}
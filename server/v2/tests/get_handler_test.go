@@ -2,7 +2,9 @@ package v2
 
 import (
 	"fmt"
+	"net/http"
 	"net/url"
+	"strings"
 	"testing"
 	"time"
 
@@ -32,6 +34,61 @@ func TestV2GetKey(t *testing.T) {
 	})
 }
 
+// Ensures that a node reports the raft term and index of the write that last modified it.
+//
+//   $ curl -X PUT localhost:4001/v2/keys/foo -d value=XXX
+//   $ curl -X PUT localhost:4001/v2/keys/bar -d value=YYY
+//   $ curl localhost:4001/v2/keys/foo
+//
+func TestV2GetKeyRaftPosition(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		v := url.Values{}
+		v.Set("value", "XXX")
+		resp, _ := tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo"), v)
+		foo := tests.ReadBodyJSON(resp)["node"].(map[string]interface{})
+		v.Set("value", "YYY")
+		resp, _ = tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/bar"), v)
+		bar := tests.ReadBodyJSON(resp)["node"].(map[string]interface{})
+		assert.NotNil(t, foo["raftTerm"], "")
+		assert.NotNil(t, foo["raftIndex"], "")
+		assert.True(t, bar["raftIndex"].(float64) > foo["raftIndex"].(float64), "")
+
+		resp, _ = tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo"))
+		node := tests.ReadBodyJSON(resp)["node"].(map[string]interface{})
+		assert.Equal(t, node["raftTerm"], foo["raftTerm"], "")
+		assert.Equal(t, node["raftIndex"], foo["raftIndex"], "")
+	})
+}
+
+// Ensures that a read passing the index of a write sees that write, and that
+// a read asking for an index the member has not reached fails.
+//
+//   $ curl -X PUT localhost:4001/v2/keys/foo -d value=XXX
+//   $ curl localhost:4001/v2/keys/foo?minIndex=2
+//
+func TestV2GetKeyMinIndex(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		v := url.Values{}
+		v.Set("value", "XXX")
+		resp, _ := tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo"), v)
+		index := resp.Header.Get("X-Etcd-Index")
+		tests.ReadBody(resp)
+
+		resp, _ = tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo?minIndex="+index))
+		assert.Equal(t, resp.StatusCode, http.StatusOK)
+		body := tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["node"].(map[string]interface{})["value"], "XXX", "")
+
+		resp, _ = tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo?minIndex=1000"))
+		body = tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["errorCode"], 403, "")
+
+		resp, _ = tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo?minIndex=bad"))
+		body = tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["errorCode"], 203, "")
+	})
+}
+
 // Ensures that a directory of values can be recursively retrieved for a given key.
 //
 //   $ curl -X PUT localhost:4001/v2/keys/foo/x -d value=XXX
@@ -74,6 +131,192 @@ func TestV2GetKeyRecursively(t *testing.T) {
 	})
 }
 
+// Ensures that a listing can leave out the values of the nodes.
+//
+//   $ curl -X PUT localhost:4001/v2/keys/foo/x -d value=XXX
+//   $ curl -X PUT localhost:4001/v2/keys/foo/y/z -d value=YYY
+//   $ curl localhost:4001/v2/keys/foo?recursive=true&keysOnly=true
+//
+func TestV2GetKeysOnly(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		v := url.Values{}
+		v.Set("value", "XXX")
+		resp, _ := tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo/x"), v)
+		tests.ReadBody(resp)
+		v.Set("value", "YYY")
+		resp, _ = tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo/y/z"), v)
+		tests.ReadBody(resp)
+
+		resp, _ = tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo?recursive=true&sorted=true&keysOnly=true"))
+		node := tests.ReadBodyJSON(resp)["node"].(map[string]interface{})
+		nodes := node["nodes"].([]interface{})
+		assert.Equal(t, len(nodes), 2, "")
+
+		node0 := nodes[0].(map[string]interface{})
+		assert.Equal(t, node0["key"], "/foo/x", "")
+		assert.Nil(t, node0["value"], "")
+
+		node1 := nodes[1].(map[string]interface{})
+		assert.Equal(t, node1["dir"], true, "")
+		node2 := node1["nodes"].([]interface{})[0].(map[string]interface{})
+		assert.Equal(t, node2["key"], "/foo/y/z", "")
+		assert.Nil(t, node2["value"], "")
+
+		resp, _ = tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo/x?keysOnly=true"))
+		node = tests.ReadBodyJSON(resp)["node"].(map[string]interface{})
+		assert.Equal(t, node["key"], "/foo/x", "")
+		assert.Nil(t, node["value"], "")
+	})
+}
+
+// Ensures that deleted keys are listed as tombstones with includeTombstones.
+//
+//   $ curl -X PUT localhost:4001/v2/keys/foo/x -d value=XXX
+//   $ curl -X PUT localhost:4001/v2/keys/foo/y -d value=YYY
+//   $ curl -X DELETE localhost:4001/v2/keys/foo/x
+//   $ curl localhost:4001/v2/keys/foo?sorted=true&includeTombstones=true
+//
+func TestV2GetIncludeTombstones(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		s.Store().SetTombstoneRetention(time.Minute, 0)
+		v := url.Values{}
+		v.Set("value", "XXX")
+		resp, _ := tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo/x"), v)
+		tests.ReadBody(resp)
+		v.Set("value", "YYY")
+		resp, _ = tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo/y"), v)
+		tests.ReadBody(resp)
+		resp, _ = tests.DeleteForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo/x"), url.Values{})
+		tests.ReadBody(resp)
+
+		resp, _ = tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo?sorted=true"))
+		node := tests.ReadBodyJSON(resp)["node"].(map[string]interface{})
+		assert.Equal(t, len(node["nodes"].([]interface{})), 1, "")
+
+		resp, _ = tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo?sorted=true&includeTombstones=true"))
+		node = tests.ReadBodyJSON(resp)["node"].(map[string]interface{})
+		nodes := node["nodes"].([]interface{})
+		assert.Equal(t, len(nodes), 2, "")
+		node0 := nodes[0].(map[string]interface{})
+		assert.Equal(t, node0["key"], "/foo/x", "")
+		assert.Equal(t, node0["deleted"], true, "")
+		assert.Equal(t, node0["deletedIndex"], 4, "")
+		assert.Nil(t, node0["value"], "")
+		node1 := nodes[1].(map[string]interface{})
+		assert.Nil(t, node1["deleted"], "")
+
+		resp, _ = tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo/x?includeTombstones=true"))
+		assert.Equal(t, resp.StatusCode, http.StatusOK)
+		node = tests.ReadBodyJSON(resp)["node"].(map[string]interface{})
+		assert.Equal(t, node["deleted"], true, "")
+	})
+}
+
+// Ensures that a key can be read as it was at a past index.
+//
+//   $ curl -X PUT localhost:4001/v2/keys/foo -d value=XXX
+//   $ curl -X PUT localhost:4001/v2/keys/foo -d value=YYY
+//   $ curl localhost:4001/v2/keys/foo?atIndex=2
+//
+func TestV2GetAtIndex(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		v := url.Values{}
+		v.Set("value", "XXX")
+		resp, _ := tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo"), v)
+		body := tests.ReadBodyJSON(resp)
+		index := int(body["node"].(map[string]interface{})["modifiedIndex"].(float64))
+		v.Set("value", "YYY")
+		resp, _ = tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo"), v)
+		tests.ReadBody(resp)
+
+		resp, _ = tests.Get(fmt.Sprintf("%s/v2/keys/foo?atIndex=%d", s.URL(), index))
+		assert.Equal(t, resp.StatusCode, http.StatusOK)
+		node := tests.ReadBodyJSON(resp)["node"].(map[string]interface{})
+		assert.Equal(t, node["value"], "XXX", "")
+		assert.Equal(t, node["modifiedIndex"], index, "")
+
+		resp, _ = tests.Get(fmt.Sprintf("%s/v2/keys/foo?atIndex=%d", s.URL(), index-1))
+		assert.Equal(t, tests.ReadBodyJSON(resp)["errorCode"], 100, "")
+
+		resp, _ = tests.Get(fmt.Sprintf("%s/v2/keys/foo?atIndex=%d", s.URL(), index+100))
+		assert.Equal(t, tests.ReadBodyJSON(resp)["errorCode"], 403, "")
+
+		resp, _ = tests.Get(fmt.Sprintf("%s/v2/keys/foo?atIndex=bar", s.URL()))
+		assert.Equal(t, tests.ReadBodyJSON(resp)["errorCode"], 203, "")
+	})
+}
+
+// Ensures that a response can be trimmed to the fields selected with
+// "fields", for a read and for a watch.
+//
+//   $ curl -X PUT localhost:4001/v2/keys/foo/x -d value=XXX
+//   $ curl localhost:4001/v2/keys/foo?recursive=true&fields=node.key,node.nodes
+//   $ curl localhost:4001/v2/keys/foo/x?wait=true&waitIndex=2&fields=action,node.modifiedIndex
+//
+func TestV2GetFields(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		v := url.Values{}
+		v.Set("value", "XXX")
+		resp, _ := tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo/x"), v)
+		tests.ReadBody(resp)
+
+		resp, _ = tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo?recursive=true&fields=node.key,node.nodes"))
+		assert.Equal(t, resp.StatusCode, http.StatusOK)
+		body := tests.ReadBodyJSON(resp)
+		assert.Nil(t, body["action"], "")
+		node := body["node"].(map[string]interface{})
+		assert.Equal(t, node["key"], "/foo", "")
+		assert.Nil(t, node["dir"], "")
+		assert.Nil(t, node["modifiedIndex"], "")
+		node0 := node["nodes"].([]interface{})[0].(map[string]interface{})
+		assert.Equal(t, len(node0), 1, "")
+		assert.Equal(t, node0["key"], "/foo/x", "")
+
+		resp, _ = tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo/x?wait=true&waitIndex=2&fields=action,node.modifiedIndex"))
+		body = tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["action"], "set", "")
+		node = body["node"].(map[string]interface{})
+		assert.Equal(t, len(node), 1, "")
+		assert.Equal(t, node["modifiedIndex"], 2, "")
+
+		resp, _ = tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo/x?fields=node.bogus"))
+		assert.Equal(t, resp.StatusCode, http.StatusBadRequest)
+		body = tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["errorCode"], 211, "")
+	})
+}
+
+// Ensures that a directory reports its number of children, and that a get
+// can ask for the count alone.
+//
+//   $ curl -X PUT localhost:4001/v2/keys/foo/x -d value=XXX
+//   $ curl -X PUT localhost:4001/v2/keys/foo/y/z -d value=YYY
+//   $ curl localhost:4001/v2/keys/foo?countOnly=true
+//
+func TestV2GetChildCount(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		v := url.Values{}
+		v.Set("value", "XXX")
+		resp, _ := tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo/x"), v)
+		tests.ReadBody(resp)
+		v.Set("value", "YYY")
+		resp, _ = tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo/y/z"), v)
+		tests.ReadBody(resp)
+
+		resp, _ = tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/?sorted=true"))
+		node := tests.ReadBodyJSON(resp)["node"].(map[string]interface{})
+		assert.Equal(t, node["childCount"], 1, "")
+		foo := node["nodes"].([]interface{})[0].(map[string]interface{})
+		assert.Equal(t, foo["key"], "/foo", "")
+		assert.Equal(t, foo["childCount"], 2, "")
+
+		resp, _ = tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo?countOnly=true&recursive=true"))
+		node = tests.ReadBodyJSON(resp)["node"].(map[string]interface{})
+		assert.Equal(t, node["childCount"], 2, "")
+		assert.Nil(t, node["nodes"], "")
+	})
+}
+
 // Ensures that a watcher can wait for a value to be set and return it to the client.
 //
 //   $ curl localhost:4001/v2/keys/foo/bar?wait=true
@@ -119,6 +362,40 @@ func TestV2WatchKey(t *testing.T) {
 	})
 }
 
+// Ensures that a watch still waiting after the maximum watch duration is
+// ended with a 408 and a Retry-After header, and that an answered one is not.
+//
+//   $ curl localhost:4001/v2/keys/foo/bar?wait=true
+//
+func TestV2WatchKeyMaxDuration(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		s.MaxWatchDuration = 100 * time.Millisecond
+
+		start := time.Now()
+		resp, _ := tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo/bar?wait=true"))
+		assert.True(t, time.Since(start) >= s.MaxWatchDuration, "")
+		assert.Equal(t, resp.StatusCode, 408, "")
+		assert.Equal(t, resp.Header.Get("Retry-After"), "0", "")
+		body := tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["errorCode"], 405, "")
+
+		c := make(chan map[string]interface{})
+		go func() {
+			resp, _ := tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo/bar?wait=true"))
+			c <- tests.ReadBodyJSON(resp)
+		}()
+		time.Sleep(10 * time.Millisecond)
+
+		v := url.Values{}
+		v.Set("value", "XXX")
+		resp, _ = tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo/bar"), v)
+		tests.ReadBody(resp)
+
+		body = <-c
+		assert.Equal(t, body["action"], "set", "")
+	})
+}
+
 // Ensures that a watcher can wait for a value to be set after a given index.
 //
 //   $ curl localhost:4001/v2/keys/foo/bar?wait=true&waitIndex=4
@@ -221,3 +498,144 @@ func TestV2WatchKeyInDir(t *testing.T) {
 		assert.Equal(t, node["key"], "/keyindir", "")
 	})
 }
+
+// Ensures that a coalescing watcher gets the latest of several quick changes
+// to a key and stops at a change to another key.
+//
+//   $ curl localhost:4001/v2/keys/foo?wait=true&recursive=true&coalesce=true&coalesceInterval=500ms
+//   $ curl -X PUT localhost:4001/v2/keys/foo/bar -d value=XXX
+//   $ curl -X PUT localhost:4001/v2/keys/foo/bar -d value=YYY
+//   $ curl -X PUT localhost:4001/v2/keys/foo/baz -d value=ZZZ
+//
+func TestV2WatchKeyCoalesce(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		c := make(chan map[string]interface{})
+		go func() {
+			resp, _ := tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo?wait=true&recursive=true&coalesce=true&coalesceInterval=500ms"))
+			c <- tests.ReadBodyJSON(resp)
+		}()
+		time.Sleep(10 * time.Millisecond)
+
+		for _, kv := range [][2]string{{"bar", "XXX"}, {"bar", "YYY"}, {"baz", "ZZZ"}} {
+			resp, _ := tests.PutForm(fmt.Sprintf("%s/v2/keys/foo/%s", s.URL(), kv[0]), url.Values{"value": {kv[1]}})
+			tests.ReadBody(resp)
+		}
+
+		select {
+		case body := <-c:
+			node := body["node"].(map[string]interface{})
+			assert.Equal(t, node["key"], "/foo/bar", "")
+			assert.Equal(t, node["value"], "YYY", "")
+			assert.Equal(t, node["modifiedIndex"], 3, "")
+		case <-time.After(time.Second):
+			t.Fatal("cannot get watch result")
+		}
+
+		// The change to the other key is not lost.
+		resp, _ := tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo?wait=true&recursive=true&waitIndex=4"))
+		body := tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["node"].(map[string]interface{})["value"], "ZZZ", "")
+
+		resp, _ = tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo?wait=true&coalesce=true&coalesceInterval=soon"))
+		assert.Equal(t, resp.StatusCode, http.StatusBadRequest, "")
+		body = tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["errorCode"], 210, "")
+	})
+}
+
+// Ensures that a watcher with the current value gets it right away along
+// with the index to continue watching from.
+//
+//   $ curl -X PUT localhost:4001/v2/keys/foo/bar -d value=XXX
+//   $ curl localhost:4001/v2/keys/foo/bar?wait=true&withCurrent=true
+//   $ curl -X PUT localhost:4001/v2/keys/foo/bar -d value=YYY
+//   $ curl localhost:4001/v2/keys/foo/bar?wait=true&waitIndex=3
+//
+func TestV2WatchKeyWithCurrent(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		v := url.Values{}
+		v.Set("value", "XXX")
+		resp, _ := tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo/bar"), v)
+		tests.ReadBody(resp)
+
+		resp, _ = tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo/bar?wait=true&withCurrent=true"))
+		assert.Equal(t, resp.Header.Get("X-Etcd-Index"), "2", "")
+		body := tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["action"], "get", "")
+		node := body["node"].(map[string]interface{})
+		assert.Equal(t, node["value"], "XXX", "")
+
+		v.Set("value", "YYY")
+		resp, _ = tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo/bar"), v)
+		tests.ReadBody(resp)
+
+		// Continuing from the next index sees the change.
+		resp, _ = tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo/bar?wait=true&waitIndex=3"))
+		body = tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["action"], "set", "")
+		node = body["node"].(map[string]interface{})
+		assert.Equal(t, node["value"], "YYY", "")
+	})
+}
+
+// Ensures that a watcher with the current value of a missing key waits for it.
+//
+//   $ curl localhost:4001/v2/keys/foo/bar?wait=true&withCurrent=true
+//   $ curl -X PUT localhost:4001/v2/keys/foo/bar -d value=XXX
+//
+func TestV2WatchMissingKeyWithCurrent(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		c := make(chan map[string]interface{})
+		go func() {
+			resp, _ := tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo/bar?wait=true&withCurrent=true"))
+			c <- tests.ReadBodyJSON(resp)
+		}()
+		time.Sleep(50 * time.Millisecond)
+
+		v := url.Values{}
+		v.Set("value", "XXX")
+		resp, _ := tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo/bar"), v)
+		tests.ReadBody(resp)
+
+		select {
+		case body := <-c:
+			assert.Equal(t, body["action"], "set", "")
+			node := body["node"].(map[string]interface{})
+			assert.Equal(t, node["value"], "XXX", "")
+		case <-time.After(time.Second):
+			t.Fatal("cannot get watch result")
+		}
+	})
+}
+
+// Ensures that a GET can be wrapped in a JSONP callback when all origins are allowed.
+//
+//   $ curl -X PUT localhost:4001/v2/keys/foo/bar -d value=XXX
+//   $ curl localhost:4001/v2/keys/foo/bar?callback=update
+//
+func TestV2GetKeyJSONP(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		v := url.Values{}
+		v.Set("value", "XXX")
+		resp, _ := tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo/bar"), v)
+		tests.ReadBody(resp)
+
+		// Without a wildcard CORS origin the callback is ignored.
+		resp, _ = tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo/bar?callback=update"))
+		assert.Equal(t, resp.Header.Get("Content-Type"), "application/json", "")
+		body := tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["action"], "get", "")
+
+		s.AllowOrigins([]string{"*"})
+		resp, _ = tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo/bar?callback=update"))
+		assert.Equal(t, resp.Header.Get("Content-Type"), "application/javascript", "")
+		b := string(tests.ReadBody(resp))
+		assert.True(t, strings.HasPrefix(b, `update({"action":"get"`), b)
+		assert.True(t, strings.HasSuffix(b, ");"), b)
+
+		resp, _ = tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo/bar?callback=alert(1)//"))
+		assert.Equal(t, resp.StatusCode, 400, "")
+		body = tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["errorCode"], 205, "")
+	})
+}
@@ -0,0 +1,57 @@
+package v2
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/coreos/etcd/server"
+	"github.com/coreos/etcd/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+// Ensures that applied-state hashes are recorded at committed checkpoints.
+//
+//   $ curl -X POST localhost:4001/v2/admin/hash
+//   $ curl localhost:4001/v2/admin/hash?index=4
+//
+func TestV2AdminHash(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		resp, _ := tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/admin/hash"))
+		assert.Equal(t, resp.StatusCode, 404, "")
+		tests.ReadBody(resp)
+
+		v := url.Values{}
+		v.Set("value", "XXX")
+		resp, _ = tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo"), v)
+		tests.ReadBody(resp)
+
+		resp, _ = tests.PostForm(fmt.Sprintf("%s%s", s.URL(), "/v2/admin/hash"), nil)
+		assert.Equal(t, resp.StatusCode, 200, "")
+		checkpoint := tests.ReadBodyJSON(resp)
+		index := int(checkpoint["index"].(float64))
+		assert.Equal(t, len(checkpoint["hash"].(string)), 8, "")
+
+		resp, _ = tests.Get(fmt.Sprintf("%s/v2/admin/hash?index=%d", s.URL(), index))
+		body := tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["hash"], checkpoint["hash"], "")
+		assert.Equal(t, body["name"], checkpoint["name"], "")
+
+		// A write changes the hash at the next checkpoint.
+		v.Set("value", "YYY")
+		resp, _ = tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo"), v)
+		tests.ReadBody(resp)
+		resp, _ = tests.PostForm(fmt.Sprintf("%s%s", s.URL(), "/v2/admin/hash"), nil)
+		tests.ReadBody(resp)
+
+		resp, _ = tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/admin/hash"))
+		body = tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["index"], index+2, "")
+		assert.NotEqual(t, body["hash"], checkpoint["hash"], "")
+
+		// The write in between has no checkpoint.
+		resp, _ = tests.Get(fmt.Sprintf("%s/v2/admin/hash?index=%d", s.URL(), index+1))
+		assert.Equal(t, resp.StatusCode, 404, "")
+		tests.ReadBody(resp)
+	})
+}
@@ -0,0 +1,37 @@
+package v2
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/coreos/etcd/server"
+	"github.com/coreos/etcd/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+// Ensures that a leader able to commit reports itself healthy.
+//
+//   $ curl localhost:4001/health
+//
+func TestHealth(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		resp, _ := tests.Get(fmt.Sprintf("%s%s", s.URL(), "/health"))
+		assert.Equal(t, resp.StatusCode, 200, "")
+		body := tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["health"], "ok", "")
+		assert.Equal(t, body["state"], "leader", "")
+		assert.Nil(t, body["errors"], "")
+	})
+}
+
+// Ensures that an invalid health check timeout is rejected.
+//
+//   $ curl localhost:4001/health?timeout=soon
+//
+func TestHealthInvalidTimeout(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		resp, _ := tests.Get(fmt.Sprintf("%s%s", s.URL(), "/health?timeout=soon"))
+		assert.Equal(t, resp.StatusCode, 500, "")
+		tests.ReadBody(resp)
+	})
+}
@@ -0,0 +1,39 @@
+package v2
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/coreos/etcd/log"
+	"github.com/coreos/etcd/server"
+	"github.com/coreos/etcd/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+// Ensures that the recent log entries can be read and filtered by level.
+//
+//   $ curl localhost:4001/v2/admin/logs?level=warning
+//
+func TestV2AdminLogs(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		log.Warnf("logs test %d", 1)
+
+		resp, _ := tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/admin/logs?level=warning"))
+		assert.Equal(t, resp.StatusCode, 200, "")
+		var entries []log.Entry
+		assert.Nil(t, json.Unmarshal(tests.ReadBody(resp), &entries), "")
+		if assert.NotEmpty(t, entries, "") {
+			last := entries[len(entries)-1]
+			assert.Equal(t, last.Level, "warning", "")
+			assert.Equal(t, last.Message, "logs test 1", "")
+
+			resp, _ = tests.Get(fmt.Sprintf("%s/v2/admin/logs?since=%d", s.URL(), last.Index))
+			assert.Equal(t, string(tests.ReadBody(resp)), "[]\n", "")
+		}
+
+		resp, _ = tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/admin/logs?level=loud"))
+		assert.Equal(t, resp.StatusCode, 400, "")
+		tests.ReadBody(resp)
+	})
+}
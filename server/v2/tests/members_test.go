@@ -0,0 +1,75 @@
+package v2
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/coreos/etcd/server"
+	"github.com/coreos/etcd/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+// Ensures that the client URL of a member can be changed at runtime.
+//
+//   $ curl -X PUT localhost:4001/v2/admin/members/ETCDTEST -d '{"clientURL":"http://10.0.0.2:4001"}'
+//
+func TestV2AdminUpdateMember(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		resp, _ := tests.Put(fmt.Sprintf("%s%s", s.URL(), "/v2/admin/members/ETCDTEST"), "application/json", strings.NewReader(`{"clientURL":"http://10.0.0.2:4001"}`))
+		assert.Equal(t, resp.StatusCode, 200, "")
+		body := tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["name"], "ETCDTEST", "")
+		assert.Equal(t, body["clientURL"], "http://10.0.0.2:4001", "")
+		assert.Equal(t, body["peerURL"], "http://localhost:7701", "")
+
+		resp, _ = tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/machines"))
+		assert.Equal(t, string(tests.ReadBody(resp)), "http://10.0.0.2:4001", "")
+
+		resp, _ = tests.Put(fmt.Sprintf("%s%s", s.URL(), "/v2/admin/members/ETCDTEST"), "application/json", strings.NewReader(`{"name":"other","clientURL":"http://10.0.0.3:4001"}`))
+		assert.Equal(t, resp.StatusCode, 400, "")
+		tests.ReadBody(resp)
+
+		resp, _ = tests.Put(fmt.Sprintf("%s%s", s.URL(), "/v2/admin/members/ETCDTEST"), "application/json", strings.NewReader(`{"peerURL":"10.0.0.3:7001"}`))
+		assert.Equal(t, resp.StatusCode, 400, "")
+		tests.ReadBody(resp)
+
+		resp, _ = tests.Put(fmt.Sprintf("%s%s", s.URL(), "/v2/admin/members/missing"), "application/json", strings.NewReader(`{"clientURL":"http://10.0.0.3:4001"}`))
+		body = tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["errorCode"], 100, "")
+	})
+}
+
+// Ensures that a dry run reports a membership change without applying it.
+//
+//   $ curl -X POST 'localhost:7001/join?dryRun=true' -d '{"name":"other","raftURL":"http://localhost:7001"}'
+//   $ curl -X DELETE 'localhost:7001/remove/ETCDTEST?dryRun=true'
+//   $ curl -X POST localhost:4001/v2/admin/members/check -d '[{"action":"join","name":"ETCDTEST","raftURL":"http://localhost:7001"}]'
+//
+func TestV2MembershipDryRun(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		resp, _ := tests.Post("http://localhost:7701/join?dryRun=true", "application/json", strings.NewReader(`{"name":"other","raftURL":"http://localhost:7701"}`))
+		assert.Equal(t, resp.StatusCode, 400, "")
+		body := tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["action"], "join", "")
+		assert.Equal(t, body["ok"], false, "")
+		assert.Equal(t, body["voters"], 1, "")
+		assert.Equal(t, body["votersAfter"], 2, "")
+		assert.Equal(t, body["quorumAfter"], 2, "")
+		assert.Equal(t, body["reachableAfter"], 1, "")
+
+		resp, _ = tests.Delete("http://localhost:7701/remove/ETCDTEST?dryRun=true", "", nil)
+		assert.Equal(t, resp.StatusCode, 400, "")
+		body = tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["votersAfter"], 0, "")
+
+		resp, _ = tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/machines"))
+		assert.Equal(t, string(tests.ReadBody(resp)), "http://localhost:4401", "")
+
+		resp, _ = tests.Post(fmt.Sprintf("%s%s", s.URL(), "/v2/admin/members/check"), "application/json", strings.NewReader(`[{"action":"join","name":"ETCDTEST","raftURL":"http://localhost:7701"},{"action":"remove","name":"missing"}]`))
+		assert.Equal(t, resp.StatusCode, 400, "")
+		reports := tests.ReadBody(resp)
+		assert.Contains(t, string(reports), `"ok":true`, "")
+		assert.Contains(t, string(reports), `missing is not a member`, "")
+	})
+}
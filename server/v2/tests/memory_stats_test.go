@@ -0,0 +1,27 @@
+package v2
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/coreos/etcd/server"
+	"github.com/coreos/etcd/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+// Ensures that the memory held by the store is reported by prefix.
+//
+//   $ curl localhost:4001/v2/stats/memory
+//
+func TestV2MemoryStats(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		resp, _ := tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/stats/memory"))
+		assert.Equal(t, resp.StatusCode, 200, "")
+		body := tests.ReadBodyJSON(resp)
+		assert.True(t, body["nodes"].(float64) > 1, "")
+		assert.True(t, body["totalBytes"].(float64) > 0, "")
+		assert.NotNil(t, body["updated"], "")
+		prefixes := body["prefixes"].(map[string]interface{})
+		assert.NotNil(t, prefixes["/_etcd"], "")
+	})
+}
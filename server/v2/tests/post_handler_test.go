@@ -2,6 +2,7 @@ package v2
 
 import (
 	"fmt"
+	"net/url"
 	"testing"
 
 	"github.com/coreos/etcd/server"
@@ -42,3 +43,113 @@ func TestV2CreateUnique(t *testing.T) {
 		assert.Equal(t, node["key"], "/foo/baz/4", "")
 	})
 }
+
+// Ensures the value of a key can be moved into the key's children.
+//
+//   $ curl -X PUT localhost:4001/v2/keys/stage1/job -d value=XXX
+//   $ curl -X POST localhost:4001/v2/keys/stage2 -d moveFrom=/stage1/job
+//
+func TestV2CreateUniqueMoveFrom(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		v := url.Values{}
+		v.Set("value", "XXX")
+		resp, _ := tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/stage1/job"), v)
+		tests.ReadBody(resp)
+
+		v = url.Values{}
+		v.Set("moveFrom", "/stage1/job")
+		resp, _ = tests.PostForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/stage2"), v)
+		assert.Equal(t, resp.StatusCode, 201, "")
+		body := tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["action"], "create", "")
+		node := body["node"].(map[string]interface{})
+		assert.Equal(t, node["key"], "/stage2/3", "")
+		assert.Equal(t, node["value"], "XXX", "")
+
+		resp, _ = tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/stage1/job"))
+		assert.Equal(t, resp.StatusCode, 400, "")
+		tests.ReadBody(resp)
+
+		// Moving it again fails since it was already handed off.
+		resp, _ = tests.PostForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/stage2"), v)
+		assert.Equal(t, resp.StatusCode, 400, "")
+		body = tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["errorCode"], 100, "")
+	})
+}
+
+// Ensures the key a value is moved from is validated and checked against
+// the write rules, since the move deletes it.
+//
+//   $ curl -X POST localhost:4001/v2/keys/queue -d moveFrom=/secrets/db
+//
+func TestV2CreateUniqueMoveFromDenied(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		v := url.Values{}
+		v.Set("value", "XXX")
+		resp, _ := tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/secrets/db"), v)
+		tests.ReadBody(resp)
+
+		v = url.Values{}
+		v.Set("moveFrom", "/queue/../secrets/db")
+		resp, _ = tests.PostForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/queue"), v)
+		assert.Equal(t, resp.StatusCode, 400, "")
+		body := tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["errorCode"], 109, "")
+
+		assert.Nil(t, s.AllowWriters([]string{"/queue=web"}), "")
+		v.Set("moveFrom", "/secrets/db")
+		w := serveAs(s, "web", "POST", "/v2/keys/queue", v)
+		assert.Equal(t, w.Code, 400, "")
+		assert.Contains(t, w.Body.String(), `"errorCode":110`, "")
+		assert.Contains(t, w.Body.String(), `"cause":"/secrets/db"`, "")
+
+		e, err := s.Store().Get("/secrets/db", false, false)
+		assert.Nil(t, err, "")
+		assert.Equal(t, e.Node.Value, "XXX", "")
+	})
+}
+
+// Ensures a retried POST with the same request ID is only applied once.
+//
+//   $ curl -X POST localhost:4001/v2/keys/queue -d value=XXX -d requestId=job-1
+//   $ curl -X POST localhost:4001/v2/keys/queue -d value=XXX -d requestId=job-1
+//
+func TestV2CreateUniqueRequestID(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		v := url.Values{}
+		v.Set("value", "XXX")
+		v.Set("requestId", "job-1")
+		resp, _ := tests.PostForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/queue"), v)
+		assert.Equal(t, resp.StatusCode, 201, "")
+		body := tests.ReadBodyJSON(resp)
+		node := body["node"].(map[string]interface{})
+		assert.Equal(t, node["key"], "/queue/2", "")
+
+		resp, _ = tests.PostForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/queue"), v)
+		assert.Equal(t, resp.StatusCode, 201, "")
+		body = tests.ReadBodyJSON(resp)
+		node = body["node"].(map[string]interface{})
+		assert.Equal(t, node["key"], "/queue/2", "")
+		assert.Equal(t, node["modifiedIndex"], 2, "")
+
+		resp, _ = tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/queue"))
+		assert.Equal(t, resp.Header.Get("X-Etcd-Index"), "2", "")
+		body = tests.ReadBodyJSON(resp)
+		node = body["node"].(map[string]interface{})
+		assert.Equal(t, len(node["nodes"].([]interface{})), 1, "")
+
+		// The same ID on another key is another request.
+		resp, _ = tests.PostForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/jobs"), v)
+		assert.Equal(t, resp.StatusCode, 201, "")
+		body = tests.ReadBodyJSON(resp)
+		node = body["node"].(map[string]interface{})
+		assert.Equal(t, node["key"], "/jobs/3", "")
+
+		v.Set("requestId", "job/1")
+		resp, _ = tests.PostForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/queue"), v)
+		assert.Equal(t, resp.StatusCode, 400, "")
+		body = tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["errorCode"], 207, "")
+	})
+}
@@ -22,7 +22,7 @@ func TestV2SetKey(t *testing.T) {
 		resp, err := tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo/bar"), v)
 		body := tests.ReadBody(resp)
 		assert.Nil(t, err, "")
-		assert.Equal(t, string(body), `{"action":"set","node":{"key":"/foo/bar","value":"XXX","modifiedIndex":2,"createdIndex":2}}`, "")
+		assert.Equal(t, string(body), `{"action":"set","node":{"key":"/foo/bar","value":"XXX","modifiedIndex":2,"createdIndex":2,"raftTerm":0,"raftIndex":3}}`, "")
 	})
 }
 
@@ -35,7 +35,7 @@ func TestV2SetDirectory(t *testing.T) {
 		resp, err := tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo?dir=true"), url.Values{})
 		body := tests.ReadBody(resp)
 		assert.Nil(t, err, "")
-		assert.Equal(t, string(body), `{"action":"set","node":{"key":"/foo","dir":true,"modifiedIndex":2,"createdIndex":2}}`, "")
+		assert.Equal(t, string(body), `{"action":"set","node":{"key":"/foo","dir":true,"modifiedIndex":2,"createdIndex":2,"raftTerm":0,"raftIndex":3}}`, "")
 	})
 }
 
@@ -77,6 +77,81 @@ func TestV2SetKeyWithBadTTL(t *testing.T) {
 	})
 }
 
+// Ensures that the TTL of a key can be refreshed without changing its value.
+//
+//   $ curl -X PUT localhost:4001/v2/keys/foo/bar -d value=XXX -d ttl=20
+//   $ curl -X PUT localhost:4001/v2/keys/foo/bar -d ttl=100 -d refresh=true
+//
+func TestV2RefreshKeyTTL(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		v := url.Values{}
+		v.Set("value", "XXX")
+		v.Set("ttl", "20")
+		resp, _ := tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo/bar"), v)
+		tests.ReadBody(resp)
+
+		v = url.Values{}
+		v.Set("ttl", "100")
+		v.Set("refresh", "true")
+		resp, _ = tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo/bar"), v)
+		assert.Equal(t, resp.StatusCode, 200, "")
+		body := tests.ReadBodyJSON(resp)
+		node := body["node"].(map[string]interface{})
+		assert.Equal(t, node["value"], "XXX", "")
+		assert.Equal(t, node["ttl"], 100, "")
+		assert.Equal(t, node["modifiedIndex"], 2, "")
+
+		v.Set("value", "YYY")
+		resp, _ = tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo/bar"), v)
+		body = tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["errorCode"], 208, "")
+
+		v = url.Values{}
+		v.Set("refresh", "true")
+		resp, _ = tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo/bar"), v)
+		body = tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["errorCode"], 209, "")
+	})
+}
+
+// Ensures that a refresh does not wake the watchers of the key up.
+//
+//   $ curl localhost:4001/v2/keys/foo/bar?wait=true
+//   $ curl -X PUT localhost:4001/v2/keys/foo/bar -d ttl=100 -d refresh=true
+//   $ curl -X PUT localhost:4001/v2/keys/foo/bar -d value=YYY
+//
+func TestV2RefreshKeyTTLDoesNotNotifyWatchers(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		v := url.Values{}
+		v.Set("value", "XXX")
+		v.Set("ttl", "20")
+		resp, _ := tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo/bar"), v)
+		tests.ReadBody(resp)
+
+		c := make(chan map[string]interface{})
+		go func() {
+			resp, _ := tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo/bar?wait=true"))
+			c <- tests.ReadBodyJSON(resp)
+		}()
+		time.Sleep(50 * time.Millisecond)
+
+		v = url.Values{}
+		v.Set("ttl", "100")
+		v.Set("refresh", "true")
+		resp, _ = tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo/bar"), v)
+		tests.ReadBody(resp)
+
+		v = url.Values{}
+		v.Set("value", "YYY")
+		resp, _ = tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo/bar"), v)
+		tests.ReadBody(resp)
+
+		body := <-c
+		node := body["node"].(map[string]interface{})
+		assert.Equal(t, node["value"], "YYY", "")
+	})
+}
+
 // Ensures that a key is conditionally set only if it previously did not exist.
 //
 //   $ curl -X PUT localhost:4001/v2/keys/foo/bar -d value=XXX -d prevExist=false
@@ -293,3 +368,61 @@ func TestV2SetKeyCASWithMissingValueFails(t *testing.T) {
 		assert.Equal(t, body["cause"], "CompareAndSwap", "")
 	})
 }
+
+// Ensures that keys deeper than the configured limit are rejected.
+//
+//   $ curl -X PUT localhost:4001/v2/keys/a/b/c -d value=XXX
+//
+func TestV2SetKeyTooDeep(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		s.MaxKeyDepth = 2
+		v := url.Values{}
+		v.Set("value", "XXX")
+		resp, _ := tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/a/b/c"), v)
+		assert.Equal(t, resp.StatusCode, 400, "")
+		body := tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["errorCode"], 109, "")
+		assert.Equal(t, body["cause"], "key depth of 3 exceeds the maximum of 2", "")
+
+		resp, _ = tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/a/b"), v)
+		assert.Equal(t, resp.StatusCode, 201, "")
+		tests.ReadBody(resp)
+	})
+}
+
+// Ensures that keys holding control characters are rejected.
+//
+//   $ curl -X PUT localhost:4001/v2/keys/foo%01bar -d value=XXX
+//
+func TestV2SetKeyInvalidCharacter(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		v := url.Values{}
+		v.Set("value", "XXX")
+		resp, _ := tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo%01bar"), v)
+		assert.Equal(t, resp.StatusCode, 400, "")
+		body := tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["errorCode"], 109, "")
+	})
+}
+
+// Ensures that a write cannot be serialized on a prefix that does not
+// contain its key.
+//
+//   $ curl -X PUT localhost:4001/v2/keys/counters/hits -d value=1 -d serialize=/other
+//
+func TestV2SetKeySerializeOutsidePrefix(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		v := url.Values{}
+		v.Set("value", "1")
+		v.Set("serialize", "/other")
+		resp, _ := tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/counters/hits"), v)
+		assert.Equal(t, resp.StatusCode, 400, "")
+		body := tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["errorCode"], 212, "")
+
+		v.Set("serialize", "/counters")
+		resp, _ = tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/counters/hits"), v)
+		assert.Equal(t, resp.StatusCode, 201, "")
+		tests.ReadBody(resp)
+	})
+}
@@ -0,0 +1,58 @@
+package v2
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/coreos/etcd/server"
+	"github.com/coreos/etcd/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+// Ensures that a stream sends the changes under all of its prefixes, and
+// only those, in the order they were committed.
+func TestV2StreamPrefixes(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		var indexes []float64
+		for _, key := range []string{"foo/a", "baz/a", "bar/a"} {
+			resp, _ := tests.PutForm(fmt.Sprintf("%s/v2/keys/%s", s.URL(), key), url.Values{"value": {"XXX"}})
+			body := tests.ReadBodyJSON(resp)
+			indexes = append(indexes, body["node"].(map[string]interface{})["modifiedIndex"].(float64))
+		}
+
+		resp, err := tests.Get(fmt.Sprintf("%s/v2/stream?prefix=/foo&prefix=/bar&waitIndex=%d", s.URL(), uint64(indexes[0])))
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, resp.StatusCode, 200, "")
+		dec := json.NewDecoder(resp.Body)
+
+		var body map[string]interface{}
+		assert.NoError(t, dec.Decode(&body))
+		assert.Equal(t, body["sequence"], indexes[0], "")
+		assert.Equal(t, body["node"].(map[string]interface{})["key"], "/foo/a", "")
+
+		body = nil
+		assert.NoError(t, dec.Decode(&body))
+		assert.Equal(t, body["sequence"], indexes[2], "")
+		assert.Equal(t, body["node"].(map[string]interface{})["key"], "/bar/a", "")
+
+		r, _ := tests.Delete(fmt.Sprintf("%s/v2/keys/foo/a", s.URL()), "", nil)
+		tests.ReadBody(r)
+
+		body = nil
+		assert.NoError(t, dec.Decode(&body))
+		assert.Equal(t, body["action"], "delete", "")
+		assert.True(t, body["sequence"].(float64) > indexes[2], "")
+	})
+}
+
+// Ensures that a stream without a prefix is refused.
+func TestV2StreamWithoutPrefix(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		resp, _ := tests.Get(fmt.Sprintf("%s/v2/stream", s.URL()))
+		body := tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["errorCode"], 211, "")
+	})
+}
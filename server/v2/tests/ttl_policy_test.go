@@ -0,0 +1,76 @@
+package v2
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/coreos/etcd/server"
+	"github.com/coreos/etcd/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+// Ensures that the default TTL is given to writes that omit one.
+//
+//   $ curl -X PUT localhost:4001/v2/keys/ephemeral/foo -d value=XXX
+//
+func TestV2DefaultTTL(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		s.DefaultTTL = 60
+		s.TTLPrefixes = []string{"/ephemeral"}
+
+		v := url.Values{}
+		v.Set("value", "XXX")
+		resp, _ := tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/ephemeral/foo"), v)
+		body := tests.ReadBodyJSON(resp)
+		node := body["node"].(map[string]interface{})
+		assert.Equal(t, node["ttl"], 60, "")
+
+		// An explicit TTL is kept.
+		v.Set("ttl", "20")
+		resp, _ = tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/ephemeral/bar"), v)
+		body = tests.ReadBodyJSON(resp)
+		node = body["node"].(map[string]interface{})
+		assert.Equal(t, node["ttl"], 20, "")
+
+		// Keys outside of the prefixes are untouched.
+		v.Del("ttl")
+		resp, _ = tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/ephemeralfoo"), v)
+		body = tests.ReadBodyJSON(resp)
+		node = body["node"].(map[string]interface{})
+		assert.Nil(t, node["ttl"], "")
+	})
+}
+
+// Ensures that writes above the maximum TTL are rejected.
+//
+//   $ curl -X POST localhost:4001/v2/keys/ephemeral -d value=XXX -d ttl=7200
+//
+func TestV2MaxTTL(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		s.MaxTTL = 3600
+		s.TTLPrefixes = []string{"/ephemeral"}
+
+		v := url.Values{}
+		v.Set("value", "XXX")
+		v.Set("ttl", "7200")
+		resp, _ := tests.PostForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/ephemeral"), v)
+		assert.Equal(t, resp.StatusCode, 400, "")
+		body := tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["errorCode"], 206, "")
+
+		// Without a default TTL a permanent write would outlive the maximum.
+		v.Del("ttl")
+		resp, _ = tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/ephemeral/foo"), v)
+		assert.Equal(t, resp.StatusCode, 400, "")
+		body = tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["errorCode"], 206, "")
+
+		v.Set("ttl", "3600")
+		resp, _ = tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/ephemeral/foo"), v)
+		assert.Equal(t, resp.StatusCode, 201, "")
+		body = tests.ReadBodyJSON(resp)
+		node := body["node"].(map[string]interface{})
+		assert.Equal(t, node["ttl"], 3600, "")
+	})
+}
@@ -0,0 +1,31 @@
+package v2
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/coreos/etcd/server"
+	"github.com/coreos/etcd/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+// Ensures that the version endpoint reports the build and the process.
+//
+//   $ curl localhost:4001/v2/version
+//
+func TestVersionInfo(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		resp, _ := tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/version"))
+		assert.Equal(t, resp.StatusCode, 200, "")
+		body := tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["name"], "ETCDTEST", "")
+		assert.Equal(t, body["releaseVersion"], server.ReleaseVersion, "")
+		assert.Equal(t, body["goVersion"], runtime.Version(), "")
+		assert.Equal(t, body["apiVersions"], []interface{}{"v1", "v2"}, "")
+		assert.Equal(t, body["modules"].([]interface{})[0], "dashboard", "")
+		assert.Equal(t, body["storeVersion"], 2, "")
+		assert.NotEmpty(t, body["startTime"], "")
+		assert.NotEmpty(t, body["uptime"], "")
+	})
+}
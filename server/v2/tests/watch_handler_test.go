@@ -0,0 +1,139 @@
+package v2
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+
+	"code.google.com/p/go.net/websocket"
+	"github.com/coreos/etcd/server"
+	"github.com/coreos/etcd/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+// Ensures that a websocket watcher receives every change under a key.
+func TestV2WatchWebsocket(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		wsURL := "ws" + strings.TrimPrefix(s.URL(), "http") + "/v2/watch/foo?recursive=true"
+		conn, err := websocket.Dial(wsURL, "", s.URL())
+		assert.NoError(t, err)
+		defer conn.Close()
+
+		v := url.Values{}
+		v.Set("value", "XXX")
+		resp, _ := tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo/bar"), v)
+		tests.ReadBody(resp)
+		v.Set("value", "YYY")
+		resp, _ = tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo/baz"), v)
+		tests.ReadBody(resp)
+
+		var body map[string]interface{}
+		assert.NoError(t, websocket.JSON.Receive(conn, &body))
+		node := body["node"].(map[string]interface{})
+		assert.Equal(t, body["action"], "set", "")
+		assert.Equal(t, node["key"], "/foo/bar", "")
+		assert.Equal(t, node["value"], "XXX", "")
+
+		body = nil
+		assert.NoError(t, websocket.JSON.Receive(conn, &body))
+		node = body["node"].(map[string]interface{})
+		assert.Equal(t, node["key"], "/foo/baz", "")
+		assert.Equal(t, node["value"], "YYY", "")
+	})
+}
+
+// Ensures that a websocket watcher works with the access log on, which
+// wraps the response writer.
+func TestV2WatchWebsocketAccessLog(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		s.AccessLog = true
+		defer func() { s.AccessLog = false }()
+
+		wsURL := "ws" + strings.TrimPrefix(s.URL(), "http") + "/v2/watch/foo"
+		conn, err := websocket.Dial(wsURL, "", s.URL())
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer conn.Close()
+
+		v := url.Values{}
+		v.Set("value", "XXX")
+		resp, _ := tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo"), v)
+		tests.ReadBody(resp)
+
+		var body map[string]interface{}
+		assert.NoError(t, websocket.JSON.Receive(conn, &body))
+		assert.Equal(t, body["node"].(map[string]interface{})["value"], "XXX", "")
+	})
+}
+
+// Ensures that a websocket watcher can get the current node before the changes.
+func TestV2WatchWebsocketWithCurrent(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		v := url.Values{}
+		v.Set("value", "XXX")
+		resp, _ := tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo"), v)
+		tests.ReadBody(resp)
+
+		wsURL := "ws" + strings.TrimPrefix(s.URL(), "http") + "/v2/watch/foo?withCurrent=true"
+		conn, err := websocket.Dial(wsURL, "", s.URL())
+		assert.NoError(t, err)
+		defer conn.Close()
+
+		var body map[string]interface{}
+		assert.NoError(t, websocket.JSON.Receive(conn, &body))
+		assert.Equal(t, body["action"], "get", "")
+		assert.Equal(t, body["node"].(map[string]interface{})["value"], "XXX", "")
+
+		v.Set("value", "YYY")
+		resp, _ = tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo"), v)
+		tests.ReadBody(resp)
+
+		body = nil
+		assert.NoError(t, websocket.JSON.Receive(conn, &body))
+		assert.Equal(t, body["action"], "set", "")
+		assert.Equal(t, body["node"].(map[string]interface{})["value"], "YYY", "")
+	})
+}
+
+// Ensures that a coalescing websocket watcher gets the latest change to each key.
+func TestV2WatchWebsocketCoalesce(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		wsURL := "ws" + strings.TrimPrefix(s.URL(), "http") + "/v2/watch/foo?recursive=true&coalesce=true&coalesceInterval=300ms"
+		conn, err := websocket.Dial(wsURL, "", s.URL())
+		assert.NoError(t, err)
+		defer conn.Close()
+
+		for _, kv := range [][2]string{{"bar", "XXX"}, {"baz", "YYY"}, {"bar", "ZZZ"}} {
+			resp, _ := tests.PutForm(fmt.Sprintf("%s/v2/keys/foo/%s", s.URL(), kv[0]), url.Values{"value": {kv[1]}})
+			tests.ReadBody(resp)
+		}
+
+		var body map[string]interface{}
+		assert.NoError(t, websocket.JSON.Receive(conn, &body))
+		node := body["node"].(map[string]interface{})
+		assert.Equal(t, node["key"], "/foo/baz", "")
+		assert.Equal(t, node["value"], "YYY", "")
+
+		body = nil
+		assert.NoError(t, websocket.JSON.Receive(conn, &body))
+		node = body["node"].(map[string]interface{})
+		assert.Equal(t, node["key"], "/foo/bar", "")
+		assert.Equal(t, node["value"], "ZZZ", "")
+	})
+}
+
+// Ensures that websocket connections from disallowed origins are rejected.
+func TestV2WatchWebsocketOrigin(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		wsURL := "ws" + strings.TrimPrefix(s.URL(), "http") + "/v2/watch/foo"
+		_, err := websocket.Dial(wsURL, "", "http://evil.example.com")
+		assert.Error(t, err)
+
+		s.AllowOrigins([]string{"http://evil.example.com"})
+		conn, err := websocket.Dial(wsURL, "", "http://evil.example.com")
+		assert.NoError(t, err)
+		conn.Close()
+	})
+}
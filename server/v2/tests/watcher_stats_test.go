@@ -0,0 +1,69 @@
+package v2
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/coreos/etcd/server"
+	"github.com/coreos/etcd/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+// Ensures that the watchers waiting on a node are listed until they fire.
+//
+//   $ curl localhost:4001/v2/keys/foo/bar?wait=true&waitIndex=2
+//   $ curl localhost:4001/v2/stats/watchers
+//
+func TestV2WatcherStats(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		c := make(chan bool)
+		go func() {
+			resp, _ := tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo/bar?wait=true&waitIndex=2"))
+			tests.ReadBody(resp)
+			c <- true
+		}()
+		go func() {
+			resp, _ := tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/services?wait=true&recursive=true"))
+			tests.ReadBody(resp)
+		}()
+		time.Sleep(50 * time.Millisecond)
+
+		resp, _ := tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/stats/watchers"))
+		body := tests.ReadBodyJSON(resp)
+		keys := body["keys"].(map[string]interface{})
+		assert.Equal(t, keys["/foo/bar"], 1, "")
+		assert.Equal(t, keys["/services"], 1, "")
+
+		resp, _ = tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/stats/watchers?prefix=/foo"))
+		body = tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["total"], 1, "")
+		w := body["watchers"].([]interface{})[0].(map[string]interface{})
+		assert.Equal(t, w["key"], "/foo/bar", "")
+		assert.Equal(t, w["waitIndex"], 2, "")
+		assert.Equal(t, w["recursive"], false, "")
+		assert.NotEqual(t, w["remoteAddr"], "", "")
+		assert.NotEqual(t, w["age"], "", "")
+
+		// A fired watcher is no longer listed.
+		v := url.Values{}
+		v.Set("value", "XXX")
+		resp, _ = tests.PutForm(fmt.Sprintf("%s%s", s.URL(), "/v2/keys/foo/bar"), v)
+		tests.ReadBody(resp)
+		<-c
+		resp, _ = tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/stats/watchers?prefix=/foo"))
+		body = tests.ReadBodyJSON(resp)
+		assert.Equal(t, body["total"], 0, "")
+	})
+}
+
+// Ensures that the watcher stats are only served to admin clients.
+func TestV2WatcherStatsAdminOnly(t *testing.T) {
+	tests.RunServer(func(s *server.Server) {
+		s.AllowAdmin([]string{"10.0.0.0/8"})
+		resp, _ := tests.Get(fmt.Sprintf("%s%s", s.URL(), "/v2/stats/watchers"))
+		assert.Equal(t, resp.StatusCode, 403, "")
+		tests.ReadBody(resp)
+	})
+}
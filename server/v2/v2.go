@@ -15,5 +15,8 @@ type Server interface {
 	PeerURL(string) (string, bool)
 	ClientURL(string) (string, bool)
 	Store() store.Store
+	OriginAllowed(string) bool
 	Dispatch(raft.Command, http.ResponseWriter, *http.Request) error
+	Do(raft.Command) (interface{}, error)
+	RevealEvent(*http.Request, *store.Event) *store.Event
 }
@@ -0,0 +1,167 @@
+package v2
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"code.google.com/p/go.net/websocket"
+	etcdErr "github.com/coreos/etcd/error"
+	"github.com/coreos/etcd/lifetime"
+	"github.com/coreos/etcd/log"
+	"github.com/coreos/etcd/store"
+	"github.com/gorilla/mux"
+)
+
+// WatchHandler upgrades the request to a websocket and streams every change
+// under the key to the client until it disconnects. It is meant for browser
+// tools sitting behind proxies that buffer long-polling responses.
+// With "withCurrent" the current node is sent first and the changes follow
+// from the index it was read at.
+func WatchHandler(w http.ResponseWriter, req *http.Request, s Server) error {
+	var err error
+	vars := mux.Vars(req)
+	key := "/" + vars["key"]
+
+	recursive := (req.FormValue("recursive") == "true")
+	withCurrent := (req.FormValue("withCurrent") == "true")
+
+	interval, err := coalesceInterval(req, s)
+	if err != nil {
+		return err
+	}
+	fields, err := parseFields(req, s)
+	if err != nil {
+		return err
+	}
+
+	// Watch from a given index (default 0).
+	var sinceIndex uint64 = 0
+	if waitIndex := req.FormValue("waitIndex"); waitIndex != "" {
+		sinceIndex, err = strconv.ParseUint(waitIndex, 10, 64)
+		if err != nil {
+			return etcdErr.NewError(etcdErr.EcodeIndexNaN, "Watch From Index", s.Store().Index())
+		}
+	}
+
+	// The connection is hijacked, so the client going away is noticed by
+	// reading from it; this only fires when the watch is cancelled.
+	cancelChan := lifetime.CloseNotify(w)
+
+	ws := websocket.Server{
+		Handshake: websocketHandshake(s),
+		Handler: func(conn *websocket.Conn) {
+			defer conn.Close()
+			if withCurrent {
+				event, index, err := s.Store().GetWithIndex(key, recursive, false)
+				if e, ok := err.(*etcdErr.Error); ok && e.ErrorCode != etcdErr.EcodeKeyNotFound {
+					websocket.JSON.Send(conn, err)
+					return
+				} else if event != nil {
+					if err := websocket.JSON.Send(conn, fields.project(s.RevealEvent(req, event))); err != nil {
+						return
+					}
+				}
+				sinceIndex = index + 1
+			}
+			watch(conn, req, s, key, recursive, sinceIndex, interval, fields, cancelChan)
+		},
+	}
+	ws.ServeHTTP(w, req)
+	return nil
+}
+
+// How many events are queued for a websocket watch that is not keeping up
+// before it is evicted.
+const watchQueueSize = 256
+
+// watch sends events to the websocket from a stream watcher, so no change
+// between two events is missed. A client that falls more than the queue
+// behind gets an error and is disconnected, and has to watch again from the
+// last index it received. With an interval the changes are held back and
+// only the latest change to each key within the interval is sent.
+func watch(conn *websocket.Conn, req *http.Request, s Server, key string, recursive bool, sinceIndex uint64, interval time.Duration, fields *fieldSet, cancelChan <-chan bool) {
+	// The client never sends anything; a read returning means it went away.
+	closeChan := make(chan bool)
+	go func() {
+		io.Copy(ioutil.Discard, conn)
+		close(closeChan)
+	}()
+
+	watcher, err := s.Store().WatchStream(key, recursive, sinceIndex, watchQueueSize)
+	if err != nil {
+		websocket.JSON.Send(conn, err)
+		return
+	}
+	defer watcher.Remove()
+
+	pending := newCoalescer()
+	var flushChan <-chan time.Time
+
+	for {
+		select {
+		case <-closeChan:
+			return
+		case <-cancelChan:
+			return
+		case <-flushChan:
+			flushChan = nil
+			for _, event := range pending.flush() {
+				if !send(conn, req, s, key, event, fields) {
+					return
+				}
+			}
+		case event, ok := <-watcher.EventChan():
+			if !ok {
+				log.Infof("[ws] watch %s: evicted slow watcher at index %d remote=%s", key, sinceIndex, req.RemoteAddr)
+				websocket.JSON.Send(conn, etcdErr.NewError(etcdErr.EcodeWatcherTooSlow, key, s.Store().Index()))
+				return
+			}
+			sinceIndex = event.Index() + 1
+			if interval == 0 {
+				if !send(conn, req, s, key, event, fields) {
+					return
+				}
+			} else {
+				pending.add(event)
+				if flushChan == nil {
+					flushChan = time.After(interval)
+				}
+			}
+		}
+	}
+}
+
+// send writes an event to the websocket. It returns false once the client
+// cannot be written to.
+func send(conn *websocket.Conn, req *http.Request, s Server, key string, event *store.Event, fields *fieldSet) bool {
+	if err := websocket.JSON.Send(conn, fields.project(s.RevealEvent(req, event))); err != nil {
+		log.Debugf("[ws] watch %s: %v", key, err)
+		return false
+	}
+	return true
+}
+
+// websocketHandshake rejects browser connections from origins that the CORS
+// configuration would not allow. Clients that send no Origin are accepted.
+func websocketHandshake(s Server) func(*websocket.Config, *http.Request) error {
+	return func(config *websocket.Config, req *http.Request) error {
+		origin := req.Header.Get("Origin")
+		if origin == "" {
+			return nil
+		}
+		u, err := url.Parse(origin)
+		if err != nil {
+			return err
+		}
+		if u.Host != req.Host && !s.OriginAllowed(origin) {
+			return fmt.Errorf("websocket origin not allowed: %s", origin)
+		}
+		config.Origin = u
+		return nil
+	}
+}
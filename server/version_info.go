@@ -0,0 +1,61 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"runtime"
+	"time"
+
+	"github.com/coreos/etcd/mod"
+	"github.com/coreos/etcd/store"
+)
+
+// The API versions served to clients.
+var apiVersions = []string{"v1", "v2"}
+
+// VersionInfo describes the build and the process of a member.
+type VersionInfo struct {
+	Name        string    `json:"name"`
+	Release     string    `json:"releaseVersion"`
+	Commit      string    `json:"commit,omitempty"`
+	GoVersion   string    `json:"goVersion"`
+	GoOS        string    `json:"goOS"`
+	GoArch      string    `json:"goArch"`
+	APIVersions []string  `json:"apiVersions"`
+	Modules     []string  `json:"modules"`
+	StartTime   time.Time `json:"startTime"`
+	Uptime      string    `json:"uptime"`
+
+	// The version of the store format in the data directory, and the
+	// versions this build can read.
+	StoreVersion    int `json:"storeVersion"`
+	MinStoreVersion int `json:"minStoreVersion"`
+	MaxStoreVersion int `json:"maxStoreVersion"`
+}
+
+// VersionInfo returns the build and process information of the server.
+func (s *Server) VersionInfo() *VersionInfo {
+	start := s.peerServer.serverStats.StartTime
+	return &VersionInfo{
+		Name:            s.name,
+		Release:         ReleaseVersion,
+		Commit:          ReleaseCommit,
+		GoVersion:       runtime.Version(),
+		GoOS:            runtime.GOOS,
+		GoArch:          runtime.GOARCH,
+		APIVersions:     apiVersions,
+		Modules:         mod.Modules,
+		StartTime:       start,
+		Uptime:          time.Now().Sub(start).String(),
+		StoreVersion:    s.store.Version(),
+		MinStoreVersion: store.MinVersion(),
+		MaxStoreVersion: store.MaxVersion(),
+	}
+}
+
+// Handler to return the version, build and uptime of the server as JSON.
+func (s *Server) GetVersionInfoHandler(w http.ResponseWriter, req *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(w).Encode(s.VersionInfo())
+}
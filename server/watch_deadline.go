@@ -0,0 +1,78 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	etcdErr "github.com/coreos/etcd/error"
+	"github.com/coreos/etcd/lifetime"
+)
+
+// deadlineWriter ends a watch once its deadline passes by reporting the
+// client as gone to the handler waiting on it.
+type deadlineWriter struct {
+	http.ResponseWriter
+	lifetime *lifetime.Lifetime
+
+	mutex   sync.Mutex
+	written bool
+}
+
+func newDeadlineWriter(w http.ResponseWriter, d time.Duration) *deadlineWriter {
+	return &deadlineWriter{ResponseWriter: w, lifetime: lifetime.Start(w, d)}
+}
+
+// CloseNotify fires when the client goes away or the deadline passes.
+func (dw *deadlineWriter) CloseNotify() <-chan bool {
+	return dw.lifetime.Done()
+}
+
+func (dw *deadlineWriter) WriteHeader(code int) {
+	dw.wrote()
+	dw.ResponseWriter.WriteHeader(code)
+}
+
+func (dw *deadlineWriter) Write(b []byte) (int, error) {
+	dw.wrote()
+	return dw.ResponseWriter.Write(b)
+}
+
+func (dw *deadlineWriter) wrote() {
+	dw.mutex.Lock()
+	dw.written = true
+	dw.mutex.Unlock()
+}
+
+// expired tells whether the deadline passed before the handler answered.
+func (dw *deadlineWriter) expired() bool {
+	dw.mutex.Lock()
+	defer dw.mutex.Unlock()
+	return dw.lifetime.Expired() && !dw.written
+}
+
+func (dw *deadlineWriter) stop() {
+	dw.lifetime.Stop()
+}
+
+// Ends long-poll watches that wait longer than MaxWatchDuration with a 408
+// and a Retry-After header, so clients watch again from the same index
+// instead of having a proxy cut the connection in a way that looks like an
+// answer. Websocket watches and streams send events as they happen and are
+// not limited.
+func (s *Server) limitWatch(f func(http.ResponseWriter, *http.Request) error) func(http.ResponseWriter, *http.Request) error {
+	return func(w http.ResponseWriter, req *http.Request) error {
+		if s.MaxWatchDuration <= 0 || !isWatchRequest(req) || strings.HasPrefix(req.URL.Path, "/v2/watch/") || req.URL.Path == "/v2/stream" {
+			return f(w, req)
+		}
+
+		dw := newDeadlineWriter(w, s.MaxWatchDuration)
+		defer dw.stop()
+		if err := f(dw, req); err != nil || !dw.expired() {
+			return err
+		}
+		w.Header().Set("Retry-After", "0")
+		return etcdErr.NewError(etcdErr.EcodeWatchExpired, req.URL.Path, s.store.Index())
+	}
+}
@@ -0,0 +1,126 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// watcherStats keeps track of the watch requests currently being served.
+type watcherStats struct {
+	sync.Mutex
+	nextID uint64
+	active map[uint64]*activeWatcher
+}
+
+// activeWatcher describes a client waiting on a key.
+type activeWatcher struct {
+	Key        string `json:"key"`
+	Recursive  bool   `json:"recursive"`
+	WaitIndex  uint64 `json:"waitIndex"`
+	RemoteAddr string `json:"remoteAddr"`
+	Age        string `json:"age"`
+
+	startTime time.Time
+}
+
+// The response of the watcher stats endpoint.
+type watcherStatsResponse struct {
+	Total    int              `json:"total"`
+	Keys     map[string]int   `json:"keys"`
+	Watchers []*activeWatcher `json:"watchers"`
+}
+
+func newWatcherStats() *watcherStats {
+	return &watcherStats{active: make(map[uint64]*activeWatcher)}
+}
+
+// add records a watcher and returns a function that removes it.
+func (ws *watcherStats) add(w *activeWatcher) func() {
+	ws.Lock()
+	defer ws.Unlock()
+	ws.nextID++
+	id := ws.nextID
+	ws.active[id] = w
+	return func() {
+		ws.Lock()
+		delete(ws.active, id)
+		ws.Unlock()
+	}
+}
+
+// snapshot returns the watchers under a key prefix, oldest first.
+func (ws *watcherStats) snapshot(prefix string, now time.Time) *watcherStatsResponse {
+	ws.Lock()
+	defer ws.Unlock()
+
+	r := &watcherStatsResponse{Keys: make(map[string]int), Watchers: make([]*activeWatcher, 0)}
+	for _, w := range ws.active {
+		if !hasKeyPrefix(w.Key, prefix) {
+			continue
+		}
+		c := *w
+		c.Age = now.Sub(w.startTime).String()
+		r.Watchers = append(r.Watchers, &c)
+		r.Keys[w.Key]++
+	}
+	sort.Sort(watchersByAge(r.Watchers))
+	r.Total = len(r.Watchers)
+	return r
+}
+
+// hasKeyPrefix checks whether key is prefix or lies beneath it.
+func hasKeyPrefix(key string, prefix string) bool {
+	prefix = strings.TrimSuffix(prefix, "/")
+	return prefix == "" || key == prefix || strings.HasPrefix(key, prefix+"/")
+}
+
+type watchersByAge []*activeWatcher
+
+func (w watchersByAge) Len() int           { return len(w) }
+func (w watchersByAge) Less(i, j int) bool { return w[i].startTime.Before(w[j].startTime) }
+func (w watchersByAge) Swap(i, j int)      { w[i], w[j] = w[j], w[i] }
+
+// isWatchRequest checks whether a request waits for a change to a key.
+func isWatchRequest(req *http.Request) bool {
+	p := req.URL.Path
+	return strings.HasPrefix(p, "/v1/watch/") || strings.HasPrefix(p, "/v2/watch/") || p == "/v2/stream" ||
+		(req.Method == "GET" && req.FormValue("wait") == "true")
+}
+
+// Records watch requests for the lifetime of the handler so they show up
+// in the watcher stats. Other requests pass straight through.
+func (s *Server) trackWatcher(f func(http.ResponseWriter, *http.Request) error) func(http.ResponseWriter, *http.Request) error {
+	return func(w http.ResponseWriter, req *http.Request) error {
+		if !isWatchRequest(req) {
+			return f(w, req)
+		}
+
+		index := req.FormValue("waitIndex")
+		if strings.HasPrefix(req.URL.Path, "/v1/") {
+			index = req.FormValue("index")
+		}
+		waitIndex, _ := strconv.ParseUint(index, 10, 64)
+
+		done := s.watchers.add(&activeWatcher{
+			Key:        requestKey(req),
+			Recursive:  req.FormValue("recursive") == "true",
+			WaitIndex:  waitIndex,
+			RemoteAddr: req.RemoteAddr,
+			startTime:  time.Now(),
+		})
+		defer done()
+		return f(w, req)
+	}
+}
+
+// Retrieves the watchers currently waiting on this node.
+// The "prefix" parameter limits the list to the watchers under a key.
+func (s *Server) GetWatcherStatsHandler(w http.ResponseWriter, req *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(s.watchers.snapshot(req.FormValue("prefix"), time.Now()))
+}
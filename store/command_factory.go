@@ -19,9 +19,11 @@ type CommandFactory interface {
 	CreateSetCommand(key string, dir bool, value string, expireTime time.Time) raft.Command
 	CreateCreateCommand(key string, dir bool, value string, expireTime time.Time, unique bool) raft.Command
 	CreateUpdateCommand(key string, value string, expireTime time.Time) raft.Command
+	CreateRefreshCommand(key string, expireTime time.Time) raft.Command
 	CreateDeleteCommand(key string, dir, recursive bool) raft.Command
 	CreateCompareAndSwapCommand(key string, value string, prevValue string,
 		prevIndex uint64, expireTime time.Time) raft.Command
+	CreateMoveToQueueCommand(dirPath string, srcPath string, srcIndex uint64, value string, expireTime time.Time) raft.Command
 	CreateSyncCommand(now time.Time) raft.Command
 }
 
@@ -87,6 +87,41 @@ func (eh *EventHistory) scan(key string, recursive bool, index uint64) (*Event,
 	}
 }
 
+// around returns the last event on or before the given index that changed
+// a key and the first one after it, either of which may be missing. Removing
+// a parent directory changes the key too. It fails once the events since the
+// index are no longer all in the history.
+func (eh *EventHistory) around(key string, index uint64, currentIndex uint64) (*Event, *Event, *etcdErr.Error) {
+	eh.rwl.RLock()
+	defer eh.rwl.RUnlock()
+
+	if index < currentIndex && (eh.Queue.Size == 0 || eh.StartIndex > index+1) {
+		return nil, nil,
+			etcdErr.NewError(etcdErr.EcodeEventIndexCleared,
+				fmt.Sprintf("the requested history has been cleared [%v/%v]",
+					eh.StartIndex, index), 0)
+	}
+
+	var before *Event
+	for i := 0; i < eh.Queue.Size; i++ {
+		e := eh.Queue.Events[(eh.Queue.Front+i)%eh.Queue.Capacity]
+
+		ok := e.Node.Key == key
+		if e.Action == Delete || e.Action == Expire {
+			ok = ok || strings.HasPrefix(key, path.Clean(e.Node.Key)+"/")
+		}
+		if !ok {
+			continue
+		}
+
+		if e.Index() > index {
+			return before, e, nil
+		}
+		before = e
+	}
+	return before, nil, nil
+}
+
 // clone will be protected by a stop-world lock
 // do not need to obtain internal lock
 func (eh *EventHistory) clone() *EventHistory {
@@ -0,0 +1,128 @@
+package store
+
+import (
+	"strings"
+	"unsafe"
+)
+
+// The estimated cost of an entry in a map of children or watchers, and of
+// an element of a watcher list.
+const (
+	mapEntryBytes  = 48
+	listEntryBytes = 48
+)
+
+// The estimated cost of a watcher: the watcher, its channel and its buffer.
+var watcherBytes = uint64(unsafe.Sizeof(watcher{})) + 96 + 8
+
+// MemoryStats estimates the memory held by the store. The sizes count the
+// structures and the strings they hold, not the allocator overhead, so they
+// are a lower bound to compare prefixes and follow the growth of the store.
+type MemoryStats struct {
+	Nodes        uint64 `json:"nodes"`
+	NodeBytes    uint64 `json:"nodeBytes"`
+	Events       uint64 `json:"events"`
+	EventBytes   uint64 `json:"eventBytes"`
+	Watchers     uint64 `json:"watchers"`
+	WatcherBytes uint64 `json:"watcherBytes"`
+	TotalBytes   uint64 `json:"totalBytes"`
+
+	// The same figures for the keys under each top-level directory.
+	Prefixes map[string]*PrefixMemoryStats `json:"prefixes"`
+}
+
+// PrefixMemoryStats estimates the memory held for the keys under a prefix.
+type PrefixMemoryStats struct {
+	Nodes        uint64 `json:"nodes"`
+	NodeBytes    uint64 `json:"nodeBytes"`
+	Events       uint64 `json:"events"`
+	EventBytes   uint64 `json:"eventBytes"`
+	Watchers     uint64 `json:"watchers"`
+	WatcherBytes uint64 `json:"watcherBytes"`
+	TotalBytes   uint64 `json:"totalBytes"`
+}
+
+// prefix returns the stats of the top-level directory holding a key. The
+// root itself is counted under "/".
+func (ms *MemoryStats) prefix(key string) *PrefixMemoryStats {
+	p := "/"
+	if parts := strings.SplitN(strings.TrimPrefix(key, "/"), "/", 2); parts[0] != "" {
+		p += parts[0]
+	}
+	ps, ok := ms.Prefixes[p]
+	if !ok {
+		ps = &PrefixMemoryStats{}
+		ms.Prefixes[p] = ps
+	}
+	return ps
+}
+
+// MemoryStats walks the node tree, the event history and the watchers and
+// estimates the memory they hold.
+func (s *store) MemoryStats() *MemoryStats {
+	ms := &MemoryStats{Prefixes: make(map[string]*PrefixMemoryStats)}
+
+	s.worldLock.RLock()
+	s.Root.memoryStats(ms)
+	s.worldLock.RUnlock()
+
+	s.WatcherHub.EventHistory.memoryStats(ms)
+
+	// Watches are registered under the read lock, so the watchers can only be
+	// walked while holding the write lock.
+	s.worldLock.Lock()
+	for key, l := range s.WatcherHub.watchers {
+		n := uint64(l.Len())
+		b := n*(watcherBytes+listEntryBytes) + mapEntryBytes + uint64(len(key))
+		ps := ms.prefix(key)
+		ps.Watchers += n
+		ps.WatcherBytes += b
+		ms.Watchers += n
+		ms.WatcherBytes += b
+	}
+	s.worldLock.Unlock()
+
+	for _, ps := range ms.Prefixes {
+		ps.TotalBytes = ps.NodeBytes + ps.EventBytes + ps.WatcherBytes
+	}
+	ms.TotalBytes = ms.NodeBytes + ms.EventBytes + ms.WatcherBytes
+	return ms
+}
+
+// memoryStats adds the estimated size of the node and its children.
+func (n *node) memoryStats(ms *MemoryStats) {
+	b := uint64(unsafe.Sizeof(*n)) + uint64(len(n.Path)+len(n.Value)+len(n.ACL))
+	if n.Parent != nil {
+		b += mapEntryBytes
+	}
+	ps := ms.prefix(n.Path)
+	ps.Nodes++
+	ps.NodeBytes += b
+	ms.Nodes++
+	ms.NodeBytes += b
+
+	for _, child := range n.Children {
+		child.memoryStats(ms)
+	}
+}
+
+// memoryStats adds the estimated size of the events kept in the history.
+func (eh *EventHistory) memoryStats(ms *MemoryStats) {
+	eh.rwl.RLock()
+	defer eh.rwl.RUnlock()
+
+	for i := 0; i < eh.Queue.Size; i++ {
+		e := eh.Queue.Events[(eh.Queue.Front+i)%eh.Queue.Capacity]
+		b := uint64(unsafe.Sizeof(*e)) + uint64(len(e.Action))
+		key := "/"
+		if e.Node != nil {
+			b += uint64(unsafe.Sizeof(*e.Node)) + uint64(len(e.Node.Key)+len(e.Node.Value)+len(e.Node.PrevValue))
+			key = e.Node.Key
+		}
+		ps := ms.prefix(key)
+		ps.Events++
+		ps.EventBytes += b
+		ms.Events++
+		ms.EventBytes += b
+	}
+}
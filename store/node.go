@@ -19,6 +19,10 @@ type node struct {
 	CreatedIndex  uint64
 	ModifiedIndex uint64
 
+	// The raft term and log index of the entry that last modified the node.
+	RaftTerm  uint64
+	RaftIndex uint64
+
 	Parent *node `json:"-"` // should not encode this field! avoid circular dependency.
 
 	ExpireTime time.Time
@@ -107,10 +111,17 @@ func (n *node) Write(value string, index uint64) *etcdErr.Error {
 
 	n.Value = value
 	n.ModifiedIndex = index
+	n.touch()
 
 	return nil
 }
 
+// touch records the raft position of the command being applied as the
+// position at which the node was last modified.
+func (n *node) touch() {
+	n.RaftTerm, n.RaftIndex = n.store.raftTerm, n.store.raftIndex
+}
+
 func (n *node) ExpirationAndTTL() (*time.Time, int64) {
 	if !n.IsPermanent() {
 		return &n.ExpireTime, int64(n.ExpireTime.Sub(time.Now())/time.Second) + 1
@@ -136,6 +147,18 @@ func (n *node) List() ([]*node, *etcdErr.Error) {
 	return nodes, nil
 }
 
+// ChildCount function returns the number of children of a directory node
+// that a get lists, which leaves out the hidden ones.
+func (n *node) ChildCount() int {
+	count := 0
+	for _, child := range n.Children {
+		if !child.IsHidden() {
+			count++
+		}
+	}
+	return count
+}
+
 // GetChild function returns the child node under the directory node.
 // On success, it returns the file node
 func (n *node) GetChild(name string) (*node, *etcdErr.Error) {
@@ -238,6 +261,9 @@ func (n *node) Repr(recurisive, sorted bool) NodeExtern {
 			Dir:           true,
 			ModifiedIndex: n.ModifiedIndex,
 			CreatedIndex:  n.CreatedIndex,
+			RaftTerm:      n.RaftTerm,
+			RaftIndex:     n.RaftIndex,
+			ChildCount:    n.ChildCount(),
 		}
 		node.Expiration, node.TTL = n.ExpirationAndTTL()
 
@@ -277,6 +303,8 @@ func (n *node) Repr(recurisive, sorted bool) NodeExtern {
 		Value:         n.Value,
 		ModifiedIndex: n.ModifiedIndex,
 		CreatedIndex:  n.CreatedIndex,
+		RaftTerm:      n.RaftTerm,
+		RaftIndex:     n.RaftIndex,
 	}
 	node.Expiration, node.TTL = n.ExpirationAndTTL()
 	return node
@@ -330,17 +358,19 @@ func (n *node) Clone() *node {
 // call this function on its children.
 // We check the expire last since we need to recover the whole structure first and add all the
 // notifications into the event history.
-func (n *node) recoverAndclean() {
+// Expiring nodes are pushed onto the given heap, which the store adopts once
+// the whole tree is recovered.
+func (n *node) recoverAndclean(ttlKeyHeap *ttlKeyHeap) {
 	if n.IsDir() {
 		for _, child := range n.Children {
 			child.Parent = n
 			child.store = n.store
-			child.recoverAndclean()
+			child.recoverAndclean(ttlKeyHeap)
 		}
 	}
 
 	if !n.ExpireTime.IsZero() {
-		n.store.ttlKeyHeap.push(n)
+		ttlKeyHeap.push(n)
 	}
 
 }
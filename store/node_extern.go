@@ -16,8 +16,13 @@ type NodeExtern struct {
 	Expiration    *time.Time  `json:"expiration,omitempty"`
 	TTL           int64       `json:"ttl,omitempty"`
 	Nodes         NodeExterns `json:"nodes,omitempty"`
+	ChildCount    int         `json:"childCount,omitempty"`
+	Deleted       bool        `json:"deleted,omitempty"`
+	DeletedIndex  uint64      `json:"deletedIndex,omitempty"`
 	ModifiedIndex uint64      `json:"modifiedIndex,omitempty"`
 	CreatedIndex  uint64      `json:"createdIndex,omitempty"`
+	RaftTerm      uint64      `json:"raftTerm"`
+	RaftIndex     uint64      `json:"raftIndex,omitempty"`
 }
 
 type NodeExterns []NodeExtern
@@ -0,0 +1,61 @@
+package store
+
+import (
+	"time"
+)
+
+// An appliedRequest remembers the result of a write applied for a client
+// supplied request ID, so that a retry of the request gets the same result.
+type appliedRequest struct {
+	Result     []byte    `json:"result"`
+	ExpireTime time.Time `json:"expireTime"`
+}
+
+// appliedRequests holds the results of recent requests by ID. They are kept
+// beside the key space, so recording one neither creates a key nor moves
+// the index, and they are saved in snapshots.
+type appliedRequests struct {
+	Entries map[string]*appliedRequest `json:"entries"`
+}
+
+func newAppliedRequests() *appliedRequests {
+	return &appliedRequests{Entries: make(map[string]*appliedRequest)}
+}
+
+// prune drops the requests that expired before the cutoff.
+func (r *appliedRequests) prune(cutoff time.Time) {
+	for id, a := range r.Entries {
+		if !a.ExpireTime.After(cutoff) {
+			delete(r.Entries, id)
+		}
+	}
+}
+
+// clone copies the requests for a snapshot.
+func (r *appliedRequests) clone() *appliedRequests {
+	c := newAppliedRequests()
+	for id, a := range r.Entries {
+		c.Entries[id] = a
+	}
+	return c
+}
+
+// AppliedRequest returns the result recorded for a request ID.
+func (s *store) AppliedRequest(id string) ([]byte, bool) {
+	s.worldLock.RLock()
+	defer s.worldLock.RUnlock()
+
+	a, ok := s.Requests.Entries[id]
+	if !ok {
+		return nil, false
+	}
+	return a.Result, true
+}
+
+// RecordRequest keeps the result of a request until expireTime.
+func (s *store) RecordRequest(id string, result []byte, expireTime time.Time) {
+	s.worldLock.Lock()
+	defer s.worldLock.Unlock()
+
+	s.Requests.Entries[id] = &appliedRequest{Result: result, ExpireTime: expireTime}
+}
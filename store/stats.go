@@ -35,6 +35,8 @@ const (
 	GetSuccess
 	GetFail
 	ExpireCount
+	CompareFail
+	NotFound
 )
 
 type Stats struct {
@@ -63,9 +65,22 @@ type Stats struct {
 	CompareAndSwapSuccess uint64 `json:"compareAndSwapSuccess"`
 	CompareAndSwapFail    uint64 `json:"compareAndSwapFail"`
 
+	// Number of testAndSet requests that failed because the comparison
+	// did not match. This is a subset of compareAndSwapFail.
+	CompareFail uint64 `json:"compareFail"`
+
+	// Number of requests that failed because the key did not exist
+	NotFound uint64 `json:"notFound"`
+
 	ExpireCount uint64 `json:"expireCount"`
 
 	Watchers uint64 `json:"watchers"`
+
+	// Number of events delivered to watchers
+	WatchFires uint64 `json:"watchFires"`
+
+	// Number of stream watchers evicted for falling behind
+	WatchEvictions uint64 `json:"watchEvictions"`
 }
 
 func newStats() *Stats {
@@ -74,9 +89,8 @@ func newStats() *Stats {
 }
 
 func (s *Stats) clone() *Stats {
-	return &Stats{s.GetSuccess, s.GetFail, s.SetSuccess, s.SetFail,
-		s.DeleteSuccess, s.DeleteFail, s.UpdateSuccess, s.UpdateFail, s.CreateSuccess,
-		s.CreateFail, s.CompareAndSwapSuccess, s.CompareAndSwapFail, s.Watchers, s.ExpireCount}
+	c := *s
+	return &c
 }
 
 // Status() return the statistics info of etcd storage its recent start
@@ -124,5 +138,9 @@ func (s *Stats) Inc(field int) {
 		atomic.AddUint64(&s.CompareAndSwapFail, 1)
 	case ExpireCount:
 		atomic.AddUint64(&s.ExpireCount, 1)
+	case CompareFail:
+		atomic.AddUint64(&s.CompareFail, 1)
+	case NotFound:
+		atomic.AddUint64(&s.NotFound, 1)
 	}
 }
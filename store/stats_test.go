@@ -1,6 +1,7 @@
 package store
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
@@ -69,6 +70,25 @@ func TestStoreStatsCompareAndSwapFail(t *testing.T) {
 	assert.Equal(t, uint64(1), s.Stats.CompareAndSwapFail, "")
 }
 
+// Ensure that a CAS comparison failure is recorded separately in the stats.
+func TestStoreStatsCompareFail(t *testing.T) {
+	s := newStore()
+	s.Create("/foo", false, "bar", false, Permanent)
+	s.CompareAndSwap("/foo", "wrong_value", 0, "baz", Permanent)
+	s.CompareAndSwap("/no_such_key", "bar", 0, "baz", Permanent)
+	assert.Equal(t, uint64(2), s.Stats.CompareAndSwapFail, "")
+	assert.Equal(t, uint64(1), s.Stats.CompareFail, "")
+}
+
+// Ensure that not found errors are recorded in the stats.
+func TestStoreStatsNotFound(t *testing.T) {
+	s := newStore()
+	s.Get("/no_such_key", false, false)
+	s.Delete("/no_such_key", false, false)
+	s.Update("/no_such_key", "bar", Permanent)
+	assert.Equal(t, uint64(3), s.Stats.NotFound, "")
+}
+
 // Ensure that a successful Delete is recorded in the stats.
 func TestStoreStatsDeleteSuccess(t *testing.T) {
 	s := newStore()
@@ -99,3 +119,41 @@ func TestStoreStatsExpireCount(t *testing.T) {
 	time.Sleep(600 * time.Millisecond)
 	assert.Equal(t, uint64(1), s.Stats.ExpireCount, "")
 }
+
+// Ensure that the number of events delivered to watchers is recorded in the stats.
+func TestStoreStatsWatchFires(t *testing.T) {
+	s := newStore()
+	s.Watch("/foo", false, 0)
+	s.Watch("/foo", false, 0)
+	s.Create("/foo", false, "bar", false, Permanent)
+
+	// Watch from history.
+	s.Watch("/foo", false, 1)
+
+	stats := make(map[string]interface{})
+	assert.Nil(t, json.Unmarshal(s.JsonStats(), &stats), "")
+	assert.Equal(t, float64(3), stats["watchFires"], "")
+}
+
+// Ensure that the memory held by the nodes, events and watchers is broken
+// down by top-level directory.
+func TestStoreMemoryStats(t *testing.T) {
+	s := newStore()
+	s.Create("/foo/a", false, "bar", false, Permanent)
+	s.Create("/foo/b", false, "bar", false, Permanent)
+	s.Create("/big", false, string(make([]byte, 4096)), false, Permanent)
+	s.Watch("/foo", true, 0)
+
+	ms := s.MemoryStats()
+	assert.Equal(t, ms.Nodes, uint64(5), "")
+	assert.Equal(t, ms.Events, uint64(3), "")
+	assert.Equal(t, ms.Watchers, uint64(1), "")
+	assert.Equal(t, ms.TotalBytes, ms.NodeBytes+ms.EventBytes+ms.WatcherBytes, "")
+
+	assert.Equal(t, ms.Prefixes["/"].Nodes, uint64(1), "")
+	assert.Equal(t, ms.Prefixes["/foo"].Nodes, uint64(3), "")
+	assert.Equal(t, ms.Prefixes["/foo"].Events, uint64(2), "")
+	assert.Equal(t, ms.Prefixes["/foo"].Watchers, uint64(1), "")
+	assert.True(t, ms.Prefixes["/big"].NodeBytes > 4096, "")
+	assert.True(t, ms.Prefixes["/big"].EventBytes > 4096, "")
+}
@@ -17,13 +17,16 @@ limitations under the License.
 package store
 
 import (
+	"encoding/binary"
 	"encoding/json"
 	"fmt"
+	"hash/crc32"
 	"path"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	etcdErr "github.com/coreos/etcd/error"
@@ -44,21 +47,35 @@ type Store interface {
 	Index() uint64
 
 	Get(nodePath string, recursive, sorted bool) (*Event, error)
+	GetWithIndex(nodePath string, recursive, sorted bool) (*Event, uint64, error)
+	GetWithTombstones(nodePath string, recursive, sorted bool) (*Event, error)
+	GetAtIndex(nodePath string, index uint64) (*Event, error)
 	Set(nodePath string, dir bool, value string, expireTime time.Time) (*Event, error)
 	Update(nodePath string, newValue string, expireTime time.Time) (*Event, error)
+	Refresh(nodePath string, expireTime time.Time) (*Event, error)
 	Create(nodePath string, dir bool, value string, unique bool,
 		expireTime time.Time) (*Event, error)
 	CompareAndSwap(nodePath string, prevValue string, prevIndex uint64,
 		value string, expireTime time.Time) (*Event, error)
+	MoveToQueue(dirPath string, srcPath string, srcIndex uint64, value string,
+		expireTime time.Time) (*Event, error)
 	Delete(nodePath string, recursive, dir bool) (*Event, error)
 	Watch(prefix string, recursive bool, sinceIndex uint64) (<-chan *Event, error)
+	WatchStream(prefix string, recursive bool, sinceIndex uint64, queueSize int) (Watcher, error)
+	WatchStreams(prefixes []string, recursive bool, sinceIndex uint64, queueSize int) (Watcher, error)
 
 	Save() ([]byte, error)
 	Recovery(state []byte) error
+	Restoring() bool
+	Hash() (uint32, error)
 
 	TotalTransactions() uint64
 	JsonStats() []byte
+	MemoryStats() *MemoryStats
 	DeleteExpiredKeys(cutoff time.Time)
+	AppliedRequest(id string) ([]byte, bool)
+	RecordRequest(id string, result []byte, expireTime time.Time)
+	SetTombstoneRetention(retention time.Duration, indexes uint64)
 }
 
 type store struct {
@@ -67,7 +84,12 @@ type store struct {
 	CurrentIndex   uint64
 	Stats          *Stats
 	CurrentVersion int
-	ttlKeyHeap     *ttlKeyHeap  // need to recovery manually
+	Tombstones     *tombstones
+	Requests       *appliedRequests
+	ttlKeyHeap     *ttlKeyHeap // need to recovery manually
+	restoring      int32       // set while a snapshot is being recovered
+	raftTerm       uint64      // raft position of the command being applied
+	raftIndex      uint64
 	worldLock      sync.RWMutex // stop the world lock
 }
 
@@ -82,6 +104,8 @@ func newStore() *store {
 	s.Stats = newStats()
 	s.WatcherHub = newWatchHub(1000)
 	s.ttlKeyHeap = newTtlKeyHeap()
+	s.Tombstones = newTombstones()
+	s.Requests = newAppliedRequests()
 	return s
 }
 
@@ -95,6 +119,16 @@ func (s *store) Index() uint64 {
 	return s.CurrentIndex
 }
 
+// SetPosition records the raft term and log index of the entry whose
+// command is about to be applied. Nodes and events written by that command
+// carry the position so that clients can order changes across keys.
+func (s *store) SetPosition(term uint64, index uint64) {
+	s.worldLock.Lock()
+	defer s.worldLock.Unlock()
+
+	s.raftTerm, s.raftIndex = term, index
+}
+
 // CommandFactory retrieves the command factory for the current version of the store.
 func (s *store) CommandFactory() CommandFactory {
 	return GetCommandFactory(s.Version())
@@ -107,6 +141,22 @@ func (s *store) Get(nodePath string, recursive, sorted bool) (*Event, error) {
 	s.worldLock.RLock()
 	defer s.worldLock.RUnlock()
 
+	return s.get(nodePath, recursive, sorted)
+}
+
+// GetWithIndex returns a get event along with the index of the store at the
+// time of the read. Watching from the next index sees every later change.
+// The index is returned even if the read fails.
+func (s *store) GetWithIndex(nodePath string, recursive, sorted bool) (*Event, uint64, error) {
+	s.worldLock.RLock()
+	defer s.worldLock.RUnlock()
+
+	e, err := s.get(nodePath, recursive, sorted)
+	return e, s.CurrentIndex, err
+}
+
+// get is Get without the world lock.
+func (s *store) get(nodePath string, recursive, sorted bool) (*Event, error) {
 	nodePath = path.Clean(path.Join("/", nodePath))
 
 	n, err := s.internalGet(nodePath)
@@ -118,6 +168,7 @@ func (s *store) Get(nodePath string, recursive, sorted bool) (*Event, error) {
 
 	e := newEvent(Get, nodePath, n.ModifiedIndex, n.CreatedIndex)
 	eNode := e.Node
+	eNode.RaftTerm, eNode.RaftIndex = n.RaftTerm, n.RaftIndex
 
 	if n.IsDir() { // node is a directory
 		eNode.Dir = true
@@ -140,6 +191,7 @@ func (s *store) Get(nodePath string, recursive, sorted bool) (*Event, error) {
 
 		// eliminate hidden nodes
 		eNode.Nodes = eNode.Nodes[:i]
+		eNode.ChildCount = i
 
 		if sorted {
 			sort.Sort(eNode.Nodes)
@@ -156,6 +208,120 @@ func (s *store) Get(nodePath string, recursive, sorted bool) (*Event, error) {
 	return e, nil
 }
 
+// GetWithTombstones is Get that also returns the tombstones of deleted keys.
+// A deleted key returns its tombstone and a directory lists the tombstones
+// of the children that do not exist anymore next to the live ones.
+func (s *store) GetWithTombstones(nodePath string, recursive, sorted bool) (*Event, error) {
+	s.worldLock.RLock()
+	defer s.worldLock.RUnlock()
+
+	nodePath = path.Clean(path.Join("/", nodePath))
+	now := time.Now()
+
+	e, err := s.get(nodePath, recursive, sorted)
+	if err != nil {
+		if ts := s.Tombstones.find(nodePath, s.CurrentIndex, now); ts != nil {
+			if e, ok := err.(*etcdErr.Error); ok && e.ErrorCode == etcdErr.EcodeKeyNotFound {
+				n := ts.repr()
+				return &Event{Action: Get, Node: &n}, nil
+			}
+		}
+		return nil, err
+	}
+
+	if e.Node.Dir {
+		s.addTombstones(e.Node, recursive, sorted, now)
+	}
+	return e, nil
+}
+
+// GetAtIndex returns a key as it was at the given index. The changes made to
+// the key since then must still be in the event history; directories are not
+// supported. When the history only tells what the value was, the node comes
+// without its indexes.
+func (s *store) GetAtIndex(nodePath string, index uint64) (*Event, error) {
+	s.worldLock.RLock()
+	defer s.worldLock.RUnlock()
+
+	nodePath = path.Clean(path.Join("/", nodePath))
+	if index > s.CurrentIndex {
+		return nil, etcdErr.NewError(etcdErr.EcodeIndexNotReached, fmt.Sprint(index), s.CurrentIndex)
+	}
+
+	before, after, err := s.WatcherHub.EventHistory.around(nodePath, index, s.CurrentIndex)
+	if err != nil {
+		err.Index = s.CurrentIndex
+		return nil, err
+	}
+
+	var n NodeExtern
+	switch {
+	case after == nil:
+		// Nothing changed since the index: the key is what it is now.
+		e, err := s.get(nodePath, false, false)
+		if err != nil {
+			return nil, err
+		}
+		n = *e.Node
+
+	case before != nil:
+		if before.Action == Delete || before.Action == Expire {
+			return nil, etcdErr.NewError(etcdErr.EcodeKeyNotFound, nodePath, s.CurrentIndex)
+		}
+		n = *before.Node
+		n.PrevValue, n.TTL = "", 0
+
+	case after.IsCreated():
+		return nil, etcdErr.NewError(etcdErr.EcodeKeyNotFound, nodePath, s.CurrentIndex)
+
+	case after.Node.Key == nodePath && after.Action != Expire:
+		// The first change since the index replaced the value the key had.
+		n = NodeExtern{Key: nodePath, Value: after.Node.PrevValue, Dir: after.Node.Dir}
+
+	default:
+		return nil, etcdErr.NewError(etcdErr.EcodeEventIndexCleared,
+			fmt.Sprintf("the value at index %v has been cleared", index), s.CurrentIndex)
+	}
+
+	if n.Dir {
+		return nil, etcdErr.NewError(etcdErr.EcodeNotFile, nodePath, s.CurrentIndex)
+	}
+	return &Event{Action: Get, Node: &n}, nil
+}
+
+// addTombstones lists the tombstones of the deleted children of a directory
+// next to the live ones, and in the directories under it when recursive.
+func (s *store) addTombstones(n *NodeExtern, recursive, sorted bool, now time.Time) {
+	live := make(map[string]bool, len(n.Nodes))
+	for i := range n.Nodes {
+		live[n.Nodes[i].Key] = true
+		if recursive && n.Nodes[i].Dir {
+			s.addTombstones(&n.Nodes[i], recursive, sorted, now)
+		}
+	}
+
+	for _, ts := range s.Tombstones.children(n.Key, s.CurrentIndex, now) {
+		if !live[ts.Key] {
+			n.Nodes = append(n.Nodes, ts.repr())
+		}
+	}
+
+	if sorted {
+		sort.Sort(n.Nodes)
+	}
+}
+
+// SetTombstoneRetention sets how long the tombstones of deleted keys are
+// kept, as a duration and as a number of indexes after the deletion. Either
+// can be zero to not limit by it; with both zero no tombstones are kept.
+func (s *store) SetTombstoneRetention(retention time.Duration, indexes uint64) {
+	s.worldLock.Lock()
+	defer s.worldLock.Unlock()
+
+	s.Tombstones.retention, s.Tombstones.indexes = retention, indexes
+	s.Tombstones.prune(s.CurrentIndex, time.Now())
+}
+
 // Create function creates the node at nodePath. Create will help to create intermediate directories with no ttl.
 // If the node has already existed, create will fail.
 // If any node on the path is a file, create will fail.
@@ -218,7 +384,7 @@ func (s *store) CompareAndSwap(nodePath string, prevValue string, prevIndex uint
 		// update etcd index
 		s.CurrentIndex++
 
-		e := newEvent(CompareAndSwap, nodePath, s.CurrentIndex, n.CreatedIndex)
+		e := s.newWriteEvent(CompareAndSwap, nodePath, s.CurrentIndex, n.CreatedIndex)
 		eNode := e.Node
 
 		eNode.PrevValue = n.Value
@@ -237,9 +403,64 @@ func (s *store) CompareAndSwap(nodePath string, prevValue string, prevIndex uint
 
 	cause := fmt.Sprintf("[%v != %v] [%v != %v]", prevValue, n.Value, prevIndex, n.ModifiedIndex)
 	s.Stats.Inc(CompareAndSwapFail)
+	s.Stats.Inc(CompareFail)
 	return nil, etcdErr.NewError(etcdErr.EcodeTestFailed, cause, s.CurrentIndex)
 }
 
+// MoveToQueue moves the value of the file at srcPath into a new in-order
+// child of the directory at dirPath and deletes srcPath, all under the same
+// lock so that no other command can observe or claim the value in between.
+// The create event is returned; watchers see it followed by the delete.
+// A srcIndex other than zero only lets the move happen while srcPath is
+// still at that index, and then value is stored instead of the moved one.
+func (s *store) MoveToQueue(dirPath string, srcPath string, srcIndex uint64, value string, expireTime time.Time) (*Event, error) {
+	srcPath = path.Clean(path.Join("/", srcPath))
+	if srcPath == "/" {
+		return nil, etcdErr.NewError(etcdErr.EcodeRootROnly, "/", s.CurrentIndex)
+	}
+
+	s.worldLock.Lock()
+	defer s.worldLock.Unlock()
+
+	src, err := s.internalGet(srcPath)
+	if err != nil {
+		s.Stats.Inc(CreateFail)
+		return nil, err
+	}
+	if src.IsDir() {
+		s.Stats.Inc(CreateFail)
+		return nil, etcdErr.NewError(etcdErr.EcodeNotFile, srcPath, s.CurrentIndex)
+	}
+	if srcIndex == 0 {
+		value = src.Value
+	} else if src.ModifiedIndex != srcIndex {
+		s.Stats.Inc(CreateFail)
+		cause := fmt.Sprintf("[%v != %v]", srcIndex, src.ModifiedIndex)
+		return nil, etcdErr.NewError(etcdErr.EcodeTestFailed, cause, s.CurrentIndex)
+	}
+
+	// Create first; it validates the destination before anything changes.
+	e, cerr := s.internalCreate(dirPath, false, value, true, false, expireTime, Create)
+	if cerr != nil {
+		s.Stats.Inc(CreateFail)
+		return nil, cerr
+	}
+
+	s.CurrentIndex++
+	de := s.newWriteEvent(Delete, srcPath, s.CurrentIndex, src.CreatedIndex)
+	de.Node.PrevValue = src.Value
+	now := time.Now()
+	s.Tombstones.add(s.Tombstones.collect(src, s.CurrentIndex, now), s.CurrentIndex, now)
+	src.Remove(false, false, func(path string) {
+		s.WatcherHub.notifyWatchers(de, path, true)
+	})
+	s.WatcherHub.notify(de)
+
+	s.Stats.Inc(CreateSuccess)
+	s.Stats.Inc(DeleteSuccess)
+	return e, nil
+}
+
 // Delete function deletes the node at the given path.
 // If the node is a directory, recursive must be true to delete it.
 func (s *store) Delete(nodePath string, dir, recursive bool) (*Event, error) {
@@ -266,7 +487,7 @@ func (s *store) Delete(nodePath string, dir, recursive bool) (*Event, error) {
 		return nil, err
 	}
 
-	e := newEvent(Delete, nodePath, nextIndex, n.CreatedIndex)
+	e := s.newWriteEvent(Delete, nodePath, nextIndex, n.CreatedIndex)
 	eNode := e.Node
 
 	if n.IsDir() {
@@ -280,6 +501,9 @@ func (s *store) Delete(nodePath string, dir, recursive bool) (*Event, error) {
 		s.WatcherHub.notifyWatchers(e, path, true)
 	}
 
+	now := time.Now()
+	tombstones := s.Tombstones.collect(n, nextIndex, now)
+
 	err = n.Remove(dir, recursive, callback)
 
 	if err != nil {
@@ -289,6 +513,7 @@ func (s *store) Delete(nodePath string, dir, recursive bool) (*Event, error) {
 
 	// update etcd index
 	s.CurrentIndex++
+	s.Tombstones.add(tombstones, s.CurrentIndex, now)
 
 	s.WatcherHub.notify(e)
 	s.Stats.Inc(DeleteSuccess)
@@ -324,6 +549,47 @@ func (s *store) Watch(key string, recursive bool, sinceIndex uint64) (<-chan *Ev
 	return c, nil
 }
 
+// WatchStream returns a watcher that receives every change after sinceIndex
+// under key, or after the current index when it is zero, until it is
+// removed. Up to queueSize events are queued for the watcher; it is evicted
+// when it falls further behind.
+func (s *store) WatchStream(key string, recursive bool, sinceIndex uint64, queueSize int) (Watcher, error) {
+	return s.WatchStreams([]string{key}, recursive, sinceIndex, queueSize)
+}
+
+// WatchStreams is WatchStream for the changes under any of a set of
+// prefixes. Each change is received once, in the order of the index it was
+// made at, so changes under different prefixes keep their relative order.
+func (s *store) WatchStreams(prefixes []string, recursive bool, sinceIndex uint64, queueSize int) (Watcher, error) {
+	keys := make([]string, 0, len(prefixes))
+	seen := make(map[string]bool)
+	for _, prefix := range prefixes {
+		key := path.Clean(path.Join("/", prefix))
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+
+	s.worldLock.Lock()
+	defer s.worldLock.Unlock()
+
+	if sinceIndex == 0 {
+		sinceIndex = s.CurrentIndex + 1
+	}
+	w, err := s.WatcherHub.watchStreams(keys, recursive, sinceIndex, queueSize)
+	if err != nil {
+		err.Index = s.CurrentIndex
+		return nil, err
+	}
+	w.remove = func() {
+		s.worldLock.Lock()
+		s.WatcherHub.remove(w)
+		s.worldLock.Unlock()
+	}
+	return w, nil
+}
+
 // walk function walks all the nodePath and apply the walkFunc on each directory
 func (s *store) walk(nodePath string, walkFunc func(prev *node, component string) (*node, *etcdErr.Error)) (*node, *etcdErr.Error) {
 	components := strings.Split(nodePath, "/")
@@ -368,7 +634,7 @@ func (s *store) Update(nodePath string, newValue string, expireTime time.Time) (
 		return nil, err
 	}
 
-	e := newEvent(Update, nodePath, nextIndex, n.CreatedIndex)
+	e := s.newWriteEvent(Update, nodePath, nextIndex, n.CreatedIndex)
 	eNode := e.Node
 
 	if n.IsDir() && len(newValue) != 0 {
@@ -395,6 +661,43 @@ func (s *store) Update(nodePath string, newValue string, expireTime time.Time) (
 	return e, nil
 }
 
+// Refresh function extends the TTL of the node at nodePath.
+// The value and the modified index of the node do not change and no event
+// is sent to the watchers, so heartbeats do not wake them up.
+func (s *store) Refresh(nodePath string, expireTime time.Time) (*Event, error) {
+	nodePath = path.Clean(path.Join("/", nodePath))
+	// we do not allow the user to change "/"
+	if nodePath == "/" {
+		return nil, etcdErr.NewError(etcdErr.EcodeRootROnly, "/", s.CurrentIndex)
+	}
+
+	s.worldLock.Lock()
+	defer s.worldLock.Unlock()
+
+	n, err := s.internalGet(nodePath)
+
+	if err != nil { // if the node does not exist, return error
+		s.Stats.Inc(UpdateFail)
+		return nil, err
+	}
+
+	n.UpdateTTL(expireTime)
+
+	e := s.newWriteEvent(Update, nodePath, n.ModifiedIndex, n.CreatedIndex)
+	eNode := e.Node
+	if n.IsDir() {
+		eNode.Dir = true
+	} else {
+		eNode.PrevValue = n.Value
+		eNode.Value = n.Value
+	}
+	eNode.Expiration, eNode.TTL = n.ExpirationAndTTL()
+
+	s.Stats.Inc(UpdateSuccess)
+
+	return e, nil
+}
+
 func (s *store) internalCreate(nodePath string, dir bool, value string, unique, replace bool,
 	expireTime time.Time, action string) (*Event, error) {
 
@@ -428,7 +731,7 @@ func (s *store) internalCreate(nodePath string, dir bool, value string, unique,
 		return nil, err
 	}
 
-	e := newEvent(action, nodePath, nextIndex, nextIndex)
+	e := s.newWriteEvent(action, nodePath, nextIndex, nextIndex)
 	eNode := e.Node
 
 	n, _ := d.GetChild(nodeName)
@@ -451,11 +754,13 @@ func (s *store) internalCreate(nodePath string, dir bool, value string, unique,
 		eNode.Value = value
 
 		n = newKV(s, nodePath, value, nextIndex, d, "", expireTime)
+		n.touch()
 
 	} else { // create directory
 		eNode.Dir = true
 
 		n = newDir(s, nodePath, nextIndex, d, "", expireTime)
+		n.touch()
 	}
 
 	// we are sure d is a directory and does not have the children with name n.Name
@@ -474,6 +779,14 @@ func (s *store) internalCreate(nodePath string, dir bool, value string, unique,
 	return e, nil
 }
 
+// newWriteEvent creates an event stamped with the raft position of the
+// command being applied.
+func (s *store) newWriteEvent(action string, key string, modifiedIndex, createdIndex uint64) *Event {
+	e := newEvent(action, key, modifiedIndex, createdIndex)
+	e.Node.RaftTerm, e.Node.RaftIndex = s.raftTerm, s.raftIndex
+	return e
+}
+
 // InternalGet function get the node of the given nodePath.
 func (s *store) internalGet(nodePath string) (*node, *etcdErr.Error) {
 	nodePath = path.Clean(path.Join("/", nodePath))
@@ -490,6 +803,7 @@ func (s *store) internalGet(nodePath string) (*node, *etcdErr.Error) {
 			return child, nil
 		}
 
+		s.Stats.Inc(NotFound)
 		return nil, etcdErr.NewError(etcdErr.EcodeKeyNotFound, path.Join(parent.Path, name), s.CurrentIndex)
 	}
 
@@ -513,7 +827,7 @@ func (s *store) DeleteExpiredKeys(cutoff time.Time) {
 		}
 
 		s.CurrentIndex++
-		e := newEvent(Expire, node.Path, s.CurrentIndex, node.CreatedIndex)
+		e := s.newWriteEvent(Expire, node.Path, s.CurrentIndex, node.CreatedIndex)
 
 		callback := func(path string) { // notify function
 			// notify the watchers with deleted set true
@@ -521,12 +835,15 @@ func (s *store) DeleteExpiredKeys(cutoff time.Time) {
 		}
 
 		s.ttlKeyHeap.pop()
+		tombstones := s.Tombstones.collect(node, s.CurrentIndex, cutoff)
 		node.Remove(true, true, callback)
+		s.Tombstones.add(tombstones, s.CurrentIndex, cutoff)
 
 		s.Stats.Inc(ExpireCount)
 		s.WatcherHub.notify(e)
 	}
 
+	s.Requests.prune(cutoff)
 }
 
 // checkDir function will check whether the component is a directory under parent node.
@@ -545,6 +862,7 @@ func (s *store) checkDir(parent *node, dirName string) (*node, *etcdErr.Error) {
 	}
 
 	n := newDir(s, path.Join(parent.Path, dirName), s.CurrentIndex+1, parent, parent.ACL, Permanent)
+	n.touch()
 
 	parent.Children[dirName] = n
 
@@ -564,6 +882,8 @@ func (s *store) Save() ([]byte, error) {
 	clonedStore.WatcherHub = s.WatcherHub.clone()
 	clonedStore.Stats = s.Stats.clone()
 	clonedStore.CurrentVersion = s.CurrentVersion
+	clonedStore.Tombstones = s.Tombstones.clone()
+	clonedStore.Requests = s.Requests.clone()
 
 	s.worldLock.Unlock()
 
@@ -576,27 +896,73 @@ func (s *store) Save() ([]byte, error) {
 	return b, nil
 }
 
+// Hash returns a checksum of the keys, values and index of the store.
+// Watchers and stats differ between members and are not included.
+func (s *store) Hash() (uint32, error) {
+	s.worldLock.RLock()
+	defer s.worldLock.RUnlock()
+
+	b, err := json.Marshal(s.Root)
+	if err != nil {
+		return 0, err
+	}
+
+	var index [8]byte
+	binary.BigEndian.PutUint64(index[:], s.CurrentIndex)
+
+	h := crc32.NewIEEE()
+	h.Write(index[:])
+	h.Write(b)
+	return h.Sum32(), nil
+}
+
 // recovery function recovery the store system from a static state.
 // It needs to recovery the parent field of the nodes.
 // It needs to delete the expired nodes since the saved time and also
 // need to create monitor go routines.
+// The state is decoded into a shadow store first and swapped in at the end,
+// so reads are served from the previous state while a large snapshot loads.
 func (s *store) Recovery(state []byte) error {
-	s.worldLock.Lock()
-	defer s.worldLock.Unlock()
-	err := json.Unmarshal(state, s)
+	atomic.StoreInt32(&s.restoring, 1)
+	defer atomic.StoreInt32(&s.restoring, 0)
+
+	shadow := newStore()
+	err := json.Unmarshal(state, shadow)
 
 	if err != nil {
 		return err
 	}
 
-	s.ttlKeyHeap = newTtlKeyHeap()
+	ttlKeyHeap := newTtlKeyHeap()
+
+	shadow.Root.store = s
+	shadow.Root.recoverAndclean(ttlKeyHeap)
 
-	s.Root.recoverAndclean()
+	s.worldLock.Lock()
+	defer s.worldLock.Unlock()
+
+	// Watchers stay registered: only the event history comes from the snapshot.
+	s.Root = shadow.Root
+	s.CurrentIndex = shadow.CurrentIndex
+	s.CurrentVersion = shadow.CurrentVersion
+	s.WatcherHub.EventHistory = shadow.WatcherHub.EventHistory
+	s.Tombstones.Entries = shadow.Tombstones.Entries
+	s.Requests = shadow.Requests
+	*s.Stats = *shadow.Stats
+	s.ttlKeyHeap = ttlKeyHeap
 	return nil
 }
 
+// Restoring checks whether a snapshot is being recovered, in which case
+// reads return the state from before the snapshot.
+func (s *store) Restoring() bool {
+	return atomic.LoadInt32(&s.restoring) == 1
+}
+
 func (s *store) JsonStats() []byte {
 	s.Stats.Watchers = uint64(s.WatcherHub.count)
+	s.Stats.WatchFires = atomic.LoadUint64(&s.WatcherHub.fired)
+	s.Stats.WatchEvictions = atomic.LoadUint64(&s.WatcherHub.evicted)
 	return s.Stats.toJson()
 }
 
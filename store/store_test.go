@@ -84,6 +84,190 @@ func TestStoreGetSorted(t *testing.T) {
 	assert.Equal(t, e.Node.Nodes[2].Key, "/foo/z", "")
 }
 
+// Ensure that directories report how many children a get lists.
+func TestStoreGetChildCount(t *testing.T) {
+	s := newStore()
+	s.Create("/foo", true, "", false, Permanent)
+	s.Create("/foo/x", false, "0", false, Permanent)
+	s.Create("/foo/_hidden", false, "*", false, Permanent)
+	s.Create("/foo/y", true, "", false, Permanent)
+	s.Create("/foo/y/a", false, "0", false, Permanent)
+	s.Create("/foo/y/b", false, "0", false, Permanent)
+	s.Create("/foo/y/c", false, "0", false, Permanent)
+	e, err := s.Get("/foo", false, true)
+	assert.Nil(t, err, "")
+	assert.Equal(t, e.Node.ChildCount, 2, "")
+	assert.Equal(t, e.Node.Nodes[0].ChildCount, 0, "")
+	assert.Equal(t, e.Node.Nodes[1].ChildCount, 3, "")
+	assert.Nil(t, e.Node.Nodes[1].Nodes, "")
+}
+
+// Ensure that deleted keys leave tombstones that are listed with the live
+// keys of their directory.
+func TestStoreGetWithTombstones(t *testing.T) {
+	s := newStore()
+	s.SetTombstoneRetention(0, 100)
+	s.Create("/foo/x", false, "0", false, Permanent)
+	s.Create("/foo/y/a", false, "0", false, Permanent)
+	s.Create("/foo/z", false, "0", false, Permanent)
+	s.Create("/foo/_hidden", false, "*", false, Permanent)
+	s.Delete("/foo/x", false, false)
+	s.Delete("/foo/y", true, true)
+	s.Delete("/foo/_hidden", false, false)
+
+	// Plain gets do not see tombstones.
+	e, _ := s.Get("/foo", true, true)
+	assert.Equal(t, len(e.Node.Nodes), 1, "")
+	_, err := s.Get("/foo/x", false, false)
+	assert.NotNil(t, err, "")
+
+	e, err = s.GetWithTombstones("/foo", true, true)
+	assert.Nil(t, err, "")
+	assert.Equal(t, len(e.Node.Nodes), 3, "")
+	assert.Equal(t, e.Node.Nodes[0].Key, "/foo/x", "")
+	assert.True(t, e.Node.Nodes[0].Deleted, "")
+	assert.Equal(t, e.Node.Nodes[0].DeletedIndex, uint64(5), "")
+	assert.Equal(t, e.Node.Nodes[0].CreatedIndex, uint64(1), "")
+	assert.Equal(t, e.Node.Nodes[1].Key, "/foo/y", "")
+	assert.True(t, e.Node.Nodes[1].Dir, "")
+	assert.True(t, e.Node.Nodes[1].Deleted, "")
+	assert.Equal(t, e.Node.Nodes[2].Key, "/foo/z", "")
+	assert.False(t, e.Node.Nodes[2].Deleted, "")
+	assert.Equal(t, e.Node.ChildCount, 1, "")
+
+	e, err = s.GetWithTombstones("/foo/y/a", false, false)
+	assert.Nil(t, err, "")
+	assert.True(t, e.Node.Deleted, "")
+	assert.Equal(t, e.Node.DeletedIndex, uint64(6), "")
+
+	// A key that is created again hides its tombstone.
+	s.Create("/foo/x", false, "1", false, Permanent)
+	e, _ = s.GetWithTombstones("/foo", false, true)
+	assert.Equal(t, len(e.Node.Nodes), 3, "")
+	assert.False(t, e.Node.Nodes[0].Deleted, "")
+	assert.Equal(t, e.Node.Nodes[0].Value, "1", "")
+}
+
+// Ensure that a key can be read as it was at a past index.
+func TestStoreGetAtIndex(t *testing.T) {
+	s := newStore()
+	s.Create("/foo", false, "bar", false, Permanent)     // 1
+	s.Update("/foo", "baz", Permanent)                   // 2
+	s.Create("/dir/a", false, "x", false, Permanent)     // 3
+	s.CompareAndSwap("/foo", "baz", 0, "qux", Permanent) // 4
+	s.Delete("/dir", true, true)                         // 5
+	s.Set("/foo", false, "quux", Permanent)              // 6
+
+	for index, value := range map[uint64]string{1: "bar", 2: "baz", 3: "baz", 4: "qux", 5: "qux", 6: "quux"} {
+		e, err := s.GetAtIndex("/foo", index)
+		assert.Nil(t, err, "")
+		assert.Equal(t, e.Node.Value, value, "")
+	}
+	e, _ := s.GetAtIndex("/foo", 2)
+	assert.Equal(t, e.Node.ModifiedIndex, uint64(2), "")
+	assert.Equal(t, e.Node.CreatedIndex, uint64(1), "")
+
+	e, err := s.GetAtIndex("/dir/a", 4)
+	assert.Nil(t, err, "")
+	assert.Equal(t, e.Node.Value, "x", "")
+	_, err = s.GetAtIndex("/dir/a", 2)
+	assert.Equal(t, err.(*etcdErr.Error).ErrorCode, etcdErr.EcodeKeyNotFound, "")
+	_, err = s.GetAtIndex("/dir/a", 5)
+	assert.Equal(t, err.(*etcdErr.Error).ErrorCode, etcdErr.EcodeKeyNotFound, "")
+	_, err = s.GetAtIndex("/dir", 4)
+	assert.Equal(t, err.(*etcdErr.Error).ErrorCode, etcdErr.EcodeNotFile, "")
+	_, err = s.GetAtIndex("/foo", 7)
+	assert.Equal(t, err.(*etcdErr.Error).ErrorCode, etcdErr.EcodeIndexNotReached, "")
+}
+
+// Ensure that reading at an index fails once the history since then is gone,
+// unless the history still tells what the value was.
+func TestStoreGetAtIndexCleared(t *testing.T) {
+	s := newStore()
+	s.WatcherHub = newWatchHub(2)
+	s.Create("/foo", false, "bar", false, Permanent) // 1
+	s.Create("/other", false, "x", false, Permanent) // 2
+	s.Update("/foo", "baz", Permanent)               // 3
+	s.Update("/other", "y", Permanent)               // 4
+
+	_, err := s.GetAtIndex("/foo", 1)
+	assert.Equal(t, err.(*etcdErr.Error).ErrorCode, etcdErr.EcodeEventIndexCleared, "")
+
+	// Only the update at 3 is left, which replaced the value of index 2.
+	e, err := s.GetAtIndex("/foo", 2)
+	assert.Nil(t, err, "")
+	assert.Equal(t, e.Node.Value, "bar", "")
+	assert.Equal(t, e.Node.ModifiedIndex, uint64(0), "")
+}
+
+// Ensure that tombstones are dropped once they are past the retention.
+func TestStoreTombstoneRetention(t *testing.T) {
+	s := newStore()
+	s.Create("/foo", false, "0", false, Permanent)
+	s.Delete("/foo", false, false)
+	_, err := s.GetWithTombstones("/foo", false, false)
+	assert.NotNil(t, err, "no tombstones are kept by default")
+
+	s.SetTombstoneRetention(0, 2)
+	s.Create("/foo", false, "0", false, Permanent)
+	s.Delete("/foo", false, false)
+	_, err = s.GetWithTombstones("/foo", false, false)
+	assert.Nil(t, err, "")
+
+	s.Create("/bar", false, "0", false, Permanent)
+	s.Create("/baz", false, "0", false, Permanent)
+	s.Create("/qux", false, "0", false, Permanent)
+	_, err = s.GetWithTombstones("/foo", false, false)
+	assert.NotNil(t, err, "")
+
+	s.SetTombstoneRetention(time.Second, 0)
+	s.Create("/foo", false, "0", false, Permanent)
+	s.Delete("/foo", false, false)
+	for _, ts := range s.Tombstones.Entries {
+		ts.DeleteTime = time.Now().Add(-2 * time.Second)
+	}
+	_, err = s.GetWithTombstones("/foo", false, false)
+	assert.NotNil(t, err, "")
+}
+
+// Ensure that tombstones are kept in snapshots.
+func TestStoreTombstoneRecovery(t *testing.T) {
+	s := newStore()
+	s.SetTombstoneRetention(0, 100)
+	s.Create("/foo", false, "0", false, Permanent)
+	s.Delete("/foo", false, false)
+	b, err := s.Save()
+	assert.Nil(t, err, "")
+
+	s2 := newStore()
+	s2.SetTombstoneRetention(0, 100)
+	s2.Recovery(b)
+	e, err := s2.GetWithTombstones("/foo", false, false)
+	assert.Nil(t, err, "")
+	assert.True(t, e.Node.Deleted, "")
+}
+
+// Ensure that applied requests are kept in snapshots, do not move the index
+// and are dropped once expired.
+func TestStoreAppliedRequests(t *testing.T) {
+	s := newStore()
+	expire := time.Now().Add(time.Minute)
+	s.RecordRequest("/queue/job-1", []byte("{}"), expire)
+	assert.Equal(t, s.CurrentIndex, uint64(0), "")
+	b, err := s.Save()
+	assert.Nil(t, err, "")
+
+	s2 := newStore()
+	s2.Recovery(b)
+	r, ok := s2.AppliedRequest("/queue/job-1")
+	assert.True(t, ok, "")
+	assert.Equal(t, string(r), "{}", "")
+
+	s2.DeleteExpiredKeys(expire)
+	_, ok = s2.AppliedRequest("/queue/job-1")
+	assert.False(t, ok, "")
+}
+
 func TestSet(t *testing.T) {
 	s := newStore()
 
@@ -249,6 +433,25 @@ func TestStoreUpdateValueTTL(t *testing.T) {
 	assert.Equal(t, err.(*etcdErr.Error).ErrorCode, etcdErr.EcodeKeyNotFound, "")
 }
 
+// Ensure that the store can refresh the TTL of a key without changing it.
+func TestStoreRefreshTTL(t *testing.T) {
+	s := newStore()
+	s.Create("/foo", false, "bar", false, time.Now().Add(200*time.Millisecond))
+	c, _ := s.Watch("/foo", false, 0)
+	e, err := s.Refresh("/foo", time.Now().Add(time.Hour))
+	assert.Nil(t, err, "")
+	assert.Equal(t, e.Node.Value, "bar", "")
+	assert.Equal(t, e.Node.ModifiedIndex, uint64(1), "")
+	assert.Equal(t, s.Index(), uint64(1), "")
+	assert.Nil(t, nbselect(c), "")
+
+	time.Sleep(300 * time.Millisecond)
+	s.DeleteExpiredKeys(time.Now())
+	e, _ = s.Get("/foo", false, false)
+	assert.Equal(t, e.Node.Value, "bar", "")
+	assert.True(t, e.Node.TTL > 3500, "")
+}
+
 // Ensure that the store can update the TTL on a directory.
 func TestStoreUpdateDirTTL(t *testing.T) {
 	s := newStore()
@@ -392,6 +595,68 @@ func TestStoreCompareAndSwapPrevIndexFailsIfNotMatch(t *testing.T) {
 	assert.Equal(t, e.Node.Value, "bar", "")
 }
 
+// Ensure that the store can move a key into an in-order child of a directory.
+func TestStoreMoveToQueue(t *testing.T) {
+	s := newStore()
+	s.Create("/stage1/job", false, "work", false, Permanent)
+	cq, _ := s.Watch("/stage2", true, 0)
+	cj, _ := s.Watch("/stage1/job", false, 0)
+	e, err := s.MoveToQueue("/stage2", "/stage1/job", 0, "", Permanent)
+	assert.Nil(t, err, "")
+	assert.Equal(t, e.Action, "create", "")
+	assert.Equal(t, e.Node.Key, "/stage2/2", "")
+	assert.Equal(t, e.Node.Value, "work", "")
+
+	// Watchers see the create followed by the delete.
+	e = nbselect(cq)
+	assert.Equal(t, e.Action, "create", "")
+	assert.Equal(t, e.Node.Key, "/stage2/2", "")
+	e = nbselect(cj)
+	assert.Equal(t, e.Action, "delete", "")
+	assert.Equal(t, e.Node.ModifiedIndex, uint64(3), "")
+	assert.Equal(t, e.Node.PrevValue, "work", "")
+
+	_, err = s.Get("/stage1/job", false, false)
+	assert.Equal(t, err.(*etcdErr.Error).ErrorCode, etcdErr.EcodeKeyNotFound, "")
+}
+
+// Ensure that a failed move into a queue leaves the source key in place.
+func TestStoreMoveToQueueFailsWithoutChanges(t *testing.T) {
+	s := newStore()
+	s.Create("/job", false, "work", false, Permanent)
+	s.Create("/file", false, "x", false, Permanent)
+	e, _err := s.MoveToQueue("/file", "/job", 0, "", Permanent)
+	err := _err.(*etcdErr.Error)
+	assert.Equal(t, err.ErrorCode, etcdErr.EcodeNotDir, "")
+	assert.Nil(t, e, "")
+	e, _ = s.Get("/job", false, false)
+	assert.Equal(t, e.Node.Value, "work", "")
+	assert.Equal(t, s.CurrentIndex, uint64(2), "")
+
+	// The source must be a file.
+	s.Create("/dir", true, "", false, Permanent)
+	_, _err = s.MoveToQueue("/queue", "/dir", 0, "", Permanent)
+	assert.Equal(t, _err.(*etcdErr.Error).ErrorCode, etcdErr.EcodeNotFile, "")
+
+	_, _err = s.MoveToQueue("/queue", "/missing", 0, "", Permanent)
+	assert.Equal(t, _err.(*etcdErr.Error).ErrorCode, etcdErr.EcodeKeyNotFound, "")
+}
+
+// Ensure that a move at a source index stores the value given, and only
+// while the source is still at that index.
+func TestStoreMoveToQueueAtIndex(t *testing.T) {
+	s := newStore()
+	s.Create("/job", false, "work", false, Permanent)
+	_, _err := s.MoveToQueue("/queue", "/job", 2, "other", Permanent)
+	assert.Equal(t, _err.(*etcdErr.Error).ErrorCode, etcdErr.EcodeTestFailed, "")
+
+	e, err := s.MoveToQueue("/queue", "/job", 1, "other", Permanent)
+	assert.Nil(t, err, "")
+	assert.Equal(t, e.Node.Value, "other", "")
+	_, err = s.Get("/job", false, false)
+	assert.Equal(t, err.(*etcdErr.Error).ErrorCode, etcdErr.EcodeKeyNotFound, "")
+}
+
 // Ensure that the store can watch for key creation.
 func TestStoreWatchCreate(t *testing.T) {
 	s := newStore()
@@ -526,6 +791,93 @@ func TestStoreRecover(t *testing.T) {
 	assert.Equal(t, e.Node.Value, "baz", "")
 }
 
+// Ensure that the store returns its index along with a read.
+func TestStoreGetWithIndex(t *testing.T) {
+	s := newStore()
+	s.Create("/foo", false, "bar", false, Permanent)
+	s.Create("/baz", false, "bat", false, Permanent)
+	e, index, err := s.GetWithIndex("/foo", false, false)
+	assert.Nil(t, err, "")
+	assert.Equal(t, e.Node.Value, "bar", "")
+	assert.Equal(t, e.Node.ModifiedIndex, uint64(1), "")
+	assert.Equal(t, index, uint64(2), "")
+
+	// A missing key still reports the index.
+	_, index, err = s.GetWithIndex("/missing", false, false)
+	assert.Equal(t, err.(*etcdErr.Error).ErrorCode, etcdErr.EcodeKeyNotFound, "")
+	assert.Equal(t, index, uint64(2), "")
+}
+
+// Ensure that nodes and events carry the raft position of the write that last modified them.
+func TestStoreRaftPosition(t *testing.T) {
+	s := newStore()
+	s.SetPosition(2, 10)
+	e, _ := s.Create("/foo", false, "bar", false, Permanent)
+	assert.Equal(t, e.Node.RaftTerm, uint64(2), "")
+	assert.Equal(t, e.Node.RaftIndex, uint64(10), "")
+	s.SetPosition(3, 11)
+	s.Create("/baz", false, "bat", false, Permanent)
+
+	// A read reports the position of the write, not the current one.
+	e, _ = s.Get("/foo", false, false)
+	assert.Equal(t, e.Node.RaftTerm, uint64(2), "")
+	assert.Equal(t, e.Node.RaftIndex, uint64(10), "")
+
+	c, _ := s.Watch("/foo", false, 0)
+	s.SetPosition(3, 12)
+	s.Update("/foo", "baz", Permanent)
+	e = nbselect(c)
+	assert.Equal(t, e.Node.RaftTerm, uint64(3), "")
+	assert.Equal(t, e.Node.RaftIndex, uint64(12), "")
+
+	e, _ = s.Get("/", true, true)
+	assert.Equal(t, e.Node.Nodes[0].RaftIndex, uint64(11), "")
+	assert.Equal(t, e.Node.Nodes[1].RaftIndex, uint64(12), "")
+}
+
+// Ensure that watchers registered before a recovery are notified of changes after it.
+func TestStoreRecoveryKeepsWatchers(t *testing.T) {
+	s := newStore()
+	s.Create("/foo", false, "bar", false, Permanent)
+	b, _ := s.Save()
+
+	s2 := newStore()
+	c, _ := s2.Watch("/foo", false, 0)
+	assert.Nil(t, s2.Recovery(b), "")
+	assert.False(t, s2.Restoring(), "")
+
+	e, err := s2.Get("/foo", false, false)
+	assert.Nil(t, err, "")
+	assert.Equal(t, e.Node.Value, "bar", "")
+
+	s2.Update("/foo", "baz", Permanent)
+	e = nbselect(c)
+	assert.Equal(t, e.Action, "update", "")
+	assert.Equal(t, e.Node.Value, "baz", "")
+}
+
+// Ensure that stores with the same keys and index have the same hash.
+func TestStoreHash(t *testing.T) {
+	s := newStore()
+	s.Create("/foo", true, "", false, Permanent)
+	s.Create("/foo/x", false, "bar", false, Permanent)
+	s.Watch("/foo", true, 0)
+	b, _ := s.Save()
+
+	s2 := newStore()
+	s2.Recovery(b)
+
+	h, err := s.Hash()
+	assert.Nil(t, err, "")
+	h2, err := s2.Hash()
+	assert.Nil(t, err, "")
+	assert.Equal(t, h, h2, "")
+
+	s2.Update("/foo/x", "baz", Permanent)
+	h2, _ = s2.Hash()
+	assert.NotEqual(t, h, h2, "")
+}
+
 // Ensure that the store can recover from a previously saved state that includes an expiring key.
 func TestStoreRecoverWithExpiration(t *testing.T) {
 	s := newStore()
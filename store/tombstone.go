@@ -0,0 +1,138 @@
+package store
+
+import (
+	"path"
+	"time"
+)
+
+// A tombstone remembers a deleted key so that a client syncing a copy of the
+// store can learn about the deletion after the fact.
+type tombstone struct {
+	Key          string    `json:"key"`
+	Dir          bool      `json:"dir,omitempty"`
+	CreatedIndex uint64    `json:"createdIndex"`
+	DeletedIndex uint64    `json:"deletedIndex"`
+	DeleteTime   time.Time `json:"deleteTime"`
+}
+
+// tombstones holds the tombstones of recently deleted keys, oldest first.
+// Tombstones are kept for a duration, for a number of indexes, or both;
+// nothing is kept when neither is set.
+type tombstones struct {
+	Entries []*tombstone `json:"entries"`
+
+	retention time.Duration
+	indexes   uint64
+}
+
+func newTombstones() *tombstones {
+	return &tombstones{}
+}
+
+func (t *tombstones) enabled() bool {
+	return t.retention > 0 || t.indexes > 0
+}
+
+// collect returns the tombstones for a node about to be removed and for the
+// nodes under it.
+func (t *tombstones) collect(n *node, index uint64, now time.Time) []*tombstone {
+	if !t.enabled() {
+		return nil
+	}
+	ts := []*tombstone{{
+		Key:          n.Path,
+		Dir:          n.IsDir(),
+		CreatedIndex: n.CreatedIndex,
+		DeletedIndex: index,
+		DeleteTime:   now,
+	}}
+	for _, child := range n.Children {
+		ts = append(ts, t.collect(child, index, now)...)
+	}
+	return ts
+}
+
+// add keeps the tombstones of removed nodes and drops the ones that are past
+// the retention.
+func (t *tombstones) add(ts []*tombstone, currentIndex uint64, now time.Time) {
+	t.Entries = append(t.Entries, ts...)
+	t.prune(currentIndex, now)
+}
+
+// prune drops the tombstones that are past the retention. Tombstones are
+// kept in deletion order so only the oldest ones need to be looked at.
+func (t *tombstones) prune(currentIndex uint64, now time.Time) {
+	i := 0
+	for ; i < len(t.Entries) && t.expired(t.Entries[i], currentIndex, now); i++ {
+	}
+	if i > 0 {
+		t.Entries = append(t.Entries[:0], t.Entries[i:]...)
+	}
+}
+
+func (t *tombstones) expired(ts *tombstone, currentIndex uint64, now time.Time) bool {
+	if !t.enabled() {
+		return true
+	}
+	if t.retention > 0 && now.Sub(ts.DeleteTime) > t.retention {
+		return true
+	}
+	return t.indexes > 0 && currentIndex-ts.DeletedIndex > t.indexes
+}
+
+// find returns the latest tombstone of a key, if it was kept.
+func (t *tombstones) find(key string, currentIndex uint64, now time.Time) *tombstone {
+	for i := len(t.Entries) - 1; i >= 0; i-- {
+		ts := t.Entries[i]
+		if ts.Key == key && !t.expired(ts, currentIndex, now) {
+			return ts
+		}
+	}
+	return nil
+}
+
+// children returns the latest tombstone of each deleted child of the
+// directory, oldest first, leaving out hidden keys.
+func (t *tombstones) children(dir string, currentIndex uint64, now time.Time) []*tombstone {
+	dir = path.Clean(dir)
+	seen := make(map[string]bool)
+
+	var ts []*tombstone
+	for i := len(t.Entries) - 1; i >= 0; i-- {
+		e := t.Entries[i]
+		parent, name := path.Split(e.Key)
+		if path.Clean(parent) != dir || e.Key == dir || seen[e.Key] || t.expired(e, currentIndex, now) {
+			continue
+		}
+		if name[0] == '_' { // hidden
+			continue
+		}
+		seen[e.Key] = true
+		ts = append(ts, e)
+	}
+
+	for i, j := 0, len(ts)-1; i < j; i, j = i+1, j-1 {
+		ts[i], ts[j] = ts[j], ts[i]
+	}
+	return ts
+}
+
+// clone copies the tombstones for a snapshot.
+func (t *tombstones) clone() *tombstones {
+	c := &tombstones{retention: t.retention, indexes: t.indexes}
+	c.Entries = make([]*tombstone, len(t.Entries))
+	copy(c.Entries, t.Entries)
+	return c
+}
+
+// repr returns the node a client sees for a tombstone.
+func (ts *tombstone) repr() NodeExtern {
+	return NodeExtern{
+		Key:           ts.Key,
+		Dir:           ts.Dir,
+		Deleted:       true,
+		CreatedIndex:  ts.CreatedIndex,
+		ModifiedIndex: ts.DeletedIndex,
+		DeletedIndex:  ts.DeletedIndex,
+	}
+}
@@ -55,6 +55,14 @@ func (f *CommandFactory) CreateUpdateCommand(key string, value string, expireTim
 	}
 }
 
+// CreateRefreshCommand creates a version 2 command to extend the TTL of a key without changing it.
+func (f *CommandFactory) CreateRefreshCommand(key string, expireTime time.Time) raft.Command {
+	return &RefreshCommand{
+		Key:        key,
+		ExpireTime: expireTime,
+	}
+}
+
 // CreateDeleteCommand creates a version 2 command to delete a key from the store.
 func (f *CommandFactory) CreateDeleteCommand(key string, dir, recursive bool) raft.Command {
 	return &DeleteCommand{
@@ -75,6 +83,17 @@ func (f *CommandFactory) CreateCompareAndSwapCommand(key string, value string, p
 	}
 }
 
+// CreateMoveToQueueCommand creates a version 2 command to move a key into an in-order child of a directory.
+func (f *CommandFactory) CreateMoveToQueueCommand(dirPath string, srcPath string, srcIndex uint64, value string, expireTime time.Time) raft.Command {
+	return &MoveToQueueCommand{
+		Key:        dirPath,
+		SrcKey:     srcPath,
+		SrcIndex:   srcIndex,
+		Value:      value,
+		ExpireTime: expireTime,
+	}
+}
+
 func (f *CommandFactory) CreateSyncCommand(now time.Time) raft.Command {
 	return &SyncCommand{
 		Time: time.Now(),
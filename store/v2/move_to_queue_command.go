@@ -0,0 +1,41 @@
+package v2
+
+import (
+	"time"
+
+	"github.com/coreos/etcd/log"
+	"github.com/coreos/etcd/store"
+	"github.com/coreos/raft"
+)
+
+func init() {
+	raft.RegisterCommand(&MoveToQueueCommand{})
+}
+
+// MoveToQueue command
+type MoveToQueueCommand struct {
+	Key        string    `json:"key"`
+	SrcKey     string    `json:"srcKey"`
+	SrcIndex   uint64    `json:"srcIndex,omitempty"`
+	Value      string    `json:"value,omitempty"`
+	ExpireTime time.Time `json:"expireTime"`
+}
+
+// The name of the moveToQueue command in the log
+func (c *MoveToQueueCommand) CommandName() string {
+	return "etcd:moveToQueue"
+}
+
+// Move the source key into an in-order child of the directory
+func (c *MoveToQueueCommand) Apply(server raft.Server) (interface{}, error) {
+	s, _ := server.StateMachine().(store.Store)
+
+	e, err := s.MoveToQueue(c.Key, c.SrcKey, c.SrcIndex, c.Value, c.ExpireTime)
+
+	if err != nil {
+		log.Debug(err)
+		return nil, err
+	}
+
+	return e, nil
+}
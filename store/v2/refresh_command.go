@@ -0,0 +1,38 @@
+package v2
+
+import (
+	"time"
+
+	"github.com/coreos/etcd/log"
+	"github.com/coreos/etcd/store"
+	"github.com/coreos/raft"
+)
+
+func init() {
+	raft.RegisterCommand(&RefreshCommand{})
+}
+
+// Refresh command
+type RefreshCommand struct {
+	Key        string    `json:"key"`
+	ExpireTime time.Time `json:"expireTime"`
+}
+
+// The name of the refresh command in the log
+func (c *RefreshCommand) CommandName() string {
+	return "etcd:refresh"
+}
+
+// Extend the TTL of the node
+func (c *RefreshCommand) Apply(server raft.Server) (interface{}, error) {
+	s, _ := server.StateMachine().(store.Store)
+
+	e, err := s.Refresh(c.Key, c.ExpireTime)
+
+	if err != nil {
+		log.Debug(err)
+		return nil, err
+	}
+
+	return e, nil
+}
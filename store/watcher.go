@@ -16,14 +16,43 @@ limitations under the License.
 
 package store
 
+// Watcher keeps receiving the changes under a key until it is removed.
+// Each watcher has its own bounded queue so that a slow consumer never holds
+// up the delivery of events to the other watchers: a watcher that lets its
+// queue fill up is evicted and its channel closed, after which the changes
+// have to be read again from the index of the last event received.
+type Watcher interface {
+	EventChan() <-chan *Event
+	Remove()
+}
+
 type watcher struct {
 	eventChan  chan *Event
 	recursive  bool
+	stream     bool
 	sinceIndex uint64
+
+	// The keys the watcher is registered at.
+	keys []string
+
+	// Set once the watcher is out of the hub, and how to take it out.
+	removed bool
+	remove  func()
+}
+
+func (w *watcher) EventChan() <-chan *Event {
+	return w.eventChan
+}
+
+// Remove stops the watcher. It is safe to call on an evicted watcher.
+func (w *watcher) Remove() {
+	if w.remove != nil {
+		w.remove()
+	}
 }
 
 // notify function notifies the watcher. If the watcher interests in the given path,
-// the function will return true.
+// the function will return true once the watcher is done and can be removed.
 func (w *watcher) notify(e *Event, originalPath bool, deleted bool) bool {
 	// watcher is interested the path in three cases and under one condition
 	// the condition is that the event happens after the watcher's sinceIndex
@@ -41,8 +70,21 @@ func (w *watcher) notify(e *Event, originalPath bool, deleted bool) bool {
 	// For example a watcher is watching at "/foo/bar". And we deletes "/foo". The watcher
 	// should get notified even if "/foo" is not the path it is watching.
 	if (w.recursive || originalPath || deleted) && e.Index() >= w.sinceIndex {
-		w.eventChan <- e
-		return true
+		if !w.stream {
+			// The queue of a single event watcher always has room.
+			w.eventChan <- e
+			return true
+		}
+
+		select {
+		case w.eventChan <- e:
+			w.sinceIndex = e.Index() + 1
+			return false
+		default:
+			// Too slow: evict rather than wait for the consumer.
+			close(w.eventChan)
+			return true
+		}
 	}
 	return false
 }
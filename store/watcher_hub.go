@@ -17,7 +17,9 @@ import (
 // of the second command.
 type watcherHub struct {
 	watchers     map[string]*list.List
-	count        int64 // current number of watchers.
+	count        int64  // current number of watchers.
+	fired        uint64 // total number of events sent to watchers.
+	evicted      uint64 // total number of slow watchers evicted.
 	EventHistory *EventHistory
 }
 
@@ -47,6 +49,7 @@ func (wh *watcherHub) watch(key string, recursive bool, index uint64) (<-chan *E
 
 	if event != nil {
 		eventChan <- event
+		atomic.AddUint64(&wh.fired, 1)
 
 		return eventChan, nil
 	}
@@ -55,22 +58,112 @@ func (wh *watcherHub) watch(key string, recursive bool, index uint64) (<-chan *E
 		eventChan:  eventChan,
 		recursive:  recursive,
 		sinceIndex: index,
+		keys:       []string{key},
 	}
+	wh.add(w)
 
-	l, ok := wh.watchers[key]
+	return eventChan, nil
+}
 
-	if ok { // add the new watcher to the back of the list
-		l.PushBack(w)
+// watchStream returns a watcher that receives every change after index under
+// key, starting with the ones still in the event history, and queues up to
+// size events. A history longer than the queue evicts the watcher right away.
+func (wh *watcherHub) watchStream(key string, recursive bool, index uint64, size int) (*watcher, *etcdErr.Error) {
+	return wh.watchStreams([]string{key}, recursive, index, size)
+}
 
-	} else { // create a new list and add the new watcher
-		l := list.New()
-		l.PushBack(w)
-		wh.watchers[key] = l
+// watchStreams is watchStream for the changes under any of a set of keys.
+// They are received once each and in the order they were made, whatever
+// key they are under.
+func (wh *watcherHub) watchStreams(keys []string, recursive bool, index uint64, size int) (*watcher, *etcdErr.Error) {
+	if size < 1 {
+		size = 1
+	}
+	w := &watcher{
+		eventChan:  make(chan *Event, size),
+		recursive:  recursive,
+		stream:     true,
+		sinceIndex: index,
+		keys:       keys,
 	}
 
+	for {
+		// Replay the earliest event under any of the keys first.
+		var event *Event
+		originalPath := false
+		for _, key := range keys {
+			e, err := wh.EventHistory.scan(key, recursive, w.sinceIndex)
+			if err != nil {
+				return nil, err
+			}
+			if e != nil && (event == nil || e.Index() < event.Index()) {
+				event = e
+			}
+		}
+		if event == nil {
+			break
+		}
+		for _, key := range keys {
+			originalPath = originalPath || event.Node.Key == key
+		}
+		if w.notify(event, originalPath, false) {
+			w.removed = true
+			atomic.AddUint64(&wh.evicted, 1)
+			return w, nil
+		}
+		atomic.AddUint64(&wh.fired, 1)
+	}
+
+	wh.add(w)
+	return w, nil
+}
+
+// add registers a watcher at each of its keys.
+func (wh *watcherHub) add(w *watcher) {
+	for _, key := range w.keys {
+		l, ok := wh.watchers[key]
+		if !ok {
+			l = list.New()
+			wh.watchers[key] = l
+		}
+		l.PushBack(w)
+	}
 	atomic.AddInt64(&wh.count, 1)
+}
 
-	return eventChan, nil
+// remove unregisters a watcher from the keys it is still registered at.
+// It scans the list at every key, so it is only used to cancel a watcher.
+func (wh *watcherHub) remove(w *watcher) {
+	if w.removed {
+		return
+	}
+	wh.unregister(w, "")
+}
+
+// unregister takes a watcher out of the lists at its keys but skip, whose
+// list the caller has already taken it out of.
+func (wh *watcherHub) unregister(w *watcher, skip string) {
+	w.removed = true
+
+	for _, key := range w.keys {
+		if key == skip {
+			continue
+		}
+		l, ok := wh.watchers[key]
+		if !ok {
+			continue
+		}
+		for e := l.Front(); e != nil; e = e.Next() {
+			if e.Value == w {
+				l.Remove(e)
+				break
+			}
+		}
+		if l.Len() == 0 {
+			delete(wh.watchers, key)
+		}
+	}
+	atomic.AddInt64(&wh.count, -1)
 }
 
 // notify function accepts an event and notify to the watchers.
@@ -110,15 +203,24 @@ func (wh *watcherHub) notifyWatchers(e *Event, path string, deleted bool) {
 			next := curr.Next() // save reference to the next one in the list
 
 			w, _ := curr.Value.(*watcher)
+			sinceIndex := w.sinceIndex
 
 			if w.notify(e, e.Node.Key == path, deleted) {
 
-				// if we successfully notify a watcher
-				// we need to remove the watcher from the list
+				// if we successfully notify a watcher, or evicted a
+				// slow one, we need to remove the watcher from the lists
 				// and decrease the counter
 				l.Remove(curr)
-				atomic.AddInt64(&wh.count, -1)
+				wh.unregister(w, path)
+				if w.stream {
+					atomic.AddUint64(&wh.evicted, 1)
+				} else {
+					atomic.AddUint64(&wh.fired, 1)
+				}
 
+			} else if w.sinceIndex != sinceIndex {
+				// a stream watcher queued the event
+				atomic.AddUint64(&wh.fired, 1)
 			}
 
 			curr = next // update current to the next
@@ -89,3 +89,101 @@ func TestWatcher(t *testing.T) {
 	}
 
 }
+
+// Ensure that a stream watcher receives every change, starting with the ones
+// still in the history, until it is removed.
+func TestWatchStream(t *testing.T) {
+	s := newStore()
+	s.Create("/foo/bar", false, "1", false, Permanent)
+
+	w, err := s.WatchStream("/foo", true, 1, 10)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	s.Set("/foo/bar", false, "2", Permanent)
+	s.Set("/foo/baz", false, "3", Permanent)
+
+	for i := uint64(1); i <= 3; i++ {
+		e := <-w.EventChan()
+		if e.Index() != i {
+			t.Fatalf("event %d has index %d", i, e.Index())
+		}
+	}
+
+	w.Remove()
+	if s.WatcherHub.count != 0 {
+		t.Fatalf("%d watchers left after removal", s.WatcherHub.count)
+	}
+	s.Set("/foo/bar", false, "4", Permanent)
+	select {
+	case e := <-w.EventChan():
+		t.Fatal("received an event after removal ", e)
+	default:
+	}
+}
+
+// Ensure that a watcher on several prefixes receives their changes once
+// each and in the order they were made, including the ones in the history.
+func TestWatchStreams(t *testing.T) {
+	s := newStore()
+	s.Create("/foo/a", false, "1", false, Permanent)
+	s.Create("/bar/a", false, "2", false, Permanent)
+	s.Create("/baz/a", false, "3", false, Permanent)
+
+	w, err := s.WatchStreams([]string{"/foo", "/bar", "/foo/a", "/bar"}, true, 1, 10)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	s.Set("/bar/b", false, "4", Permanent)
+	s.Set("/foo/a", false, "5", Permanent)
+	s.Delete("/foo", true, true)
+
+	for _, i := range []uint64{1, 2, 4, 5, 6} {
+		e := <-w.EventChan()
+		if e.Index() != i {
+			t.Fatalf("expected index %d, got %d", i, e.Index())
+		}
+	}
+	select {
+	case e := <-w.EventChan():
+		t.Fatal("received an unexpected event ", e)
+	default:
+	}
+
+	if s.WatcherHub.count != 1 {
+		t.Fatalf("%d watchers for one watch", s.WatcherHub.count)
+	}
+	w.Remove()
+	if s.WatcherHub.count != 0 || len(s.WatcherHub.watchers) != 0 {
+		t.Fatalf("%d watchers left after removal", s.WatcherHub.count)
+	}
+}
+
+// Ensure that a stream watcher that lets its queue fill up is evicted
+// without holding up the other watchers.
+func TestWatchStreamEvictSlow(t *testing.T) {
+	s := newStore()
+	slow, _ := s.WatchStream("/foo", true, 0, 2)
+	fast, _ := s.WatchStream("/foo", true, 0, 2)
+
+	for i := 0; i < 5; i++ {
+		s.Set("/foo/bar", false, "v", Permanent)
+		<-fast.EventChan()
+	}
+
+	n := 0
+	for _ = range slow.EventChan() {
+		n++
+	}
+	if n != 2 {
+		t.Fatalf("slow watcher received %d events before eviction", n)
+	}
+	if s.WatcherHub.count != 1 || s.WatcherHub.evicted != 1 {
+		t.Fatalf("count=%d evicted=%d", s.WatcherHub.count, s.WatcherHub.evicted)
+	}
+	slow.Remove()
+	fast.Remove()
+	if s.WatcherHub.count != 0 {
+		t.Fatalf("%d watchers left after removal", s.WatcherHub.count)
+	}
+}
@@ -2,7 +2,7 @@
 set -e
 
 if [ -z "$PKG" ]; then
-    PKG="./store ./server ./server/v2/tests ./mod/lock/v2/tests"
+    PKG="./store ./server ./server/v2/tests ./mod/lock/v2/tests ./mod/leader/v2/tests ./mod/lease/v2/tests ./mod/scheduler/v2/tests ./tools/etcd-dump"
 fi
 
 # Get GOPATH, etc from build
@@ -0,0 +1,72 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/coreos/go-etcd/etcd"
+)
+
+// Create a single node with a batch window and check that concurrent writes
+// are grouped into fewer log entries.
+func TestBatchWindow(t *testing.T) {
+	procAttr := new(os.ProcAttr)
+	procAttr.Files = []*os.File{nil, os.Stdout, os.Stderr}
+	args := []string{"etcd", "-name=node1", "-f", "-data-dir=/tmp/node1", "-batch-window=5"}
+
+	process, err := os.StartProcess(EtcdBinPath, args, procAttr)
+	if err != nil {
+		t.Fatal("start process failed:" + err.Error())
+		return
+	}
+	defer process.Kill()
+
+	time.Sleep(time.Second)
+
+	c := etcd.NewClient(nil)
+	c.SyncCluster()
+
+	// Issue concurrent writes.
+	count := 100
+	errs := make(chan error, count)
+	for i := 0; i < count; i++ {
+		go func(i int) {
+			key := fmt.Sprintf("foo_%d", i)
+			result, err := c.Set(key, "bar", 0)
+			if err == nil && (result.Node.Key != "/"+key || result.Node.Value != "bar") {
+				err = fmt.Errorf("Set failed with %s %s", result.Node.Key, result.Node.Value)
+			}
+			errs <- err
+		}(i)
+	}
+	for i := 0; i < count; i++ {
+		if err := <-errs; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	resp, err := http.Get("http://127.0.0.1:4001/v2/stats/batch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	var stats struct {
+		Window   int    `json:"window"`
+		Batches  uint64 `json:"batches"`
+		Commands uint64 `json:"commands"`
+		MaxSize  int    `json:"maxSize"`
+	}
+	if err := json.Unmarshal(b, &stats); err != nil {
+		t.Fatal(err)
+	}
+	if stats.Window != 5 || stats.Commands < uint64(count) || stats.Batches >= stats.Commands || stats.MaxSize < 2 {
+		t.Fatalf("unexpected batch stats: %s", string(b))
+	}
+}
@@ -0,0 +1,52 @@
+package test
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+// Create a three nodes cluster, kill one node and check that the cluster
+// stats of another one list every member, with the dead one unreachable.
+func TestClusterStats(t *testing.T) {
+	procAttr := new(os.ProcAttr)
+	procAttr.Files = []*os.File{nil, os.Stdout, os.Stderr}
+
+	clusterSize := 3
+	_, etcds, err := CreateCluster(clusterSize, procAttr, false)
+	if err != nil {
+		t.Fatal("cannot create cluster")
+	}
+	defer DestroyCluster(etcds[:2])
+
+	time.Sleep(time.Second)
+	etcds[2].Kill()
+	etcds[2].Release()
+	time.Sleep(time.Second)
+
+	var stats struct {
+		Leader  string `json:"leader"`
+		Members []struct {
+			Name        string `json:"name"`
+			Reachable   bool   `json:"reachable"`
+			Error       string `json:"error"`
+			Leader      string `json:"leader"`
+			CommitIndex uint64 `json:"commitIndex"`
+			Version     string `json:"version"`
+		} `json:"members"`
+	}
+	if err := getJSON("http://127.0.0.1:4002/v2/stats/cluster", &stats); err != nil {
+		t.Fatal(err)
+	}
+	if stats.Leader != "node1" || len(stats.Members) != clusterSize {
+		t.Fatalf("unexpected cluster stats: %+v", stats)
+	}
+	for i, m := range stats.Members[:2] {
+		if !m.Reachable || m.Leader != "node1" || m.CommitIndex == 0 || m.Version == "" {
+			t.Fatalf("unexpected stats of member %d: %+v", i, m)
+		}
+	}
+	if m := stats.Members[2]; m.Name != "node3" || m.Reachable || m.Error == "" {
+		t.Fatalf("dead member reported as reachable: %+v", m)
+	}
+}
@@ -0,0 +1,92 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Create a three nodes cluster with the consistency check enabled and check
+// that the leader finds every member at the same applied state.
+func TestConsistencyCheck(t *testing.T) {
+	procAttr := new(os.ProcAttr)
+	procAttr.Files = []*os.File{nil, os.Stdout, os.Stderr}
+
+	etcds := make([]*os.Process, 3)
+	for i := range etcds {
+		strI := strconv.Itoa(i + 1)
+		args := []string{"etcd", "-f", "-name=node" + strI, "-addr=127.0.0.1:400" + strI, "-peer-addr=127.0.0.1:700" + strI, "-data-dir=/tmp/node" + strI, "-hash-check-interval=1"}
+		if i > 0 {
+			args = append(args, "-peers=127.0.0.1:7001")
+		}
+
+		var err error
+		etcds[i], err = os.StartProcess(EtcdBinPath, args, procAttr)
+		if err != nil {
+			t.Fatal("start process failed:" + err.Error())
+		}
+		defer etcds[i].Kill()
+
+		if i == 0 {
+			time.Sleep(time.Second * 2)
+		}
+	}
+
+	stop := make(chan bool)
+	go Set(stop)
+	time.Sleep(3 * time.Second)
+	stop <- true
+	<-stop
+
+	leader, err := getLeader("http://127.0.0.1:4001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, _ := strconv.Atoi(strings.Split(leader, ":")[2])
+
+	var stats struct {
+		Checks     uint64            `json:"checks"`
+		Mismatches uint64            `json:"mismatches"`
+		LastIndex  uint64            `json:"lastIndex"`
+		Hashes     map[string]string `json:"hashes"`
+	}
+	if err := getJSON(fmt.Sprintf("http://127.0.0.1:%d/v2/stats/consistency", port-3000), &stats); err != nil {
+		t.Fatal(err)
+	}
+	if stats.Checks == 0 {
+		t.Fatal("no consistency check ran")
+	}
+	if stats.Mismatches != 0 || len(stats.Hashes) != 3 {
+		t.Fatalf("unexpected consistency stats: %+v", stats)
+	}
+
+	// Every member reports the same hash at the last checkpoint.
+	for i := 1; i <= 3; i++ {
+		var checkpoint struct {
+			Hash string `json:"hash"`
+		}
+		if err := getJSON(fmt.Sprintf("http://127.0.0.1:400%d/v2/admin/hash?index=%d", i, stats.LastIndex), &checkpoint); err != nil {
+			t.Fatal(err)
+		}
+		if checkpoint.Hash != stats.Hashes["node1"] {
+			t.Fatalf("node%d hash %s != %s", i, checkpoint.Hash, stats.Hashes["node1"])
+		}
+	}
+}
+
+func getJSON(url string, v interface{}) error {
+	resp, err := http.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
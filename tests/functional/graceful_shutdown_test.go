@@ -0,0 +1,62 @@
+package test
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// Create a three nodes cluster, stop the leader with SIGTERM and check that
+// it exits cleanly after another node has already taken over.
+func TestGracefulShutdown(t *testing.T) {
+	procAttr := new(os.ProcAttr)
+	procAttr.Files = []*os.File{nil, os.Stdout, os.Stderr}
+
+	clusterSize := 3
+	num := -1
+	_, etcds, err := CreateCluster(clusterSize, procAttr, false)
+	if err != nil {
+		t.Fatal("cannot create cluster")
+	}
+	defer func() {
+		for i, etcd := range etcds {
+			if i != num {
+				etcd.Kill()
+				etcd.Release()
+			}
+		}
+	}()
+
+	time.Sleep(time.Second)
+	leader, err := getLeader("http://127.0.0.1:4001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, _ := strconv.Atoi(strings.Split(leader, ":")[2])
+	num = port - 7001
+	follower := fmt.Sprintf("http://127.0.0.1:%d", 4001+(num+1)%clusterSize)
+
+	etcds[num].Signal(syscall.SIGTERM)
+	state, err := etcds[num].Wait()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !state.Success() {
+		t.Fatalf("leader exited with %v", state)
+	}
+	etcds[num].Release()
+
+	// The new leader is known well within an election timeout.
+	for i := 0; i < 10; i++ {
+		newLeader, err := getLeader(follower)
+		if err == nil && newLeader != leader {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("no new leader after shutdown, leader is %s", leader)
+}
@@ -0,0 +1,56 @@
+package test
+
+import (
+	"os"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// Create a three nodes cluster whose first node sits outside of the
+// preferred zone and check that it hands leadership over to the zone.
+func TestLeaderZone(t *testing.T) {
+	procAttr := new(os.ProcAttr)
+	procAttr.Files = []*os.File{nil, os.Stdout, os.Stderr}
+
+	etcds := make([]*os.Process, 3)
+	for i := range etcds {
+		strI := strconv.Itoa(i + 1)
+		args := []string{"etcd", "-f", "-name=node" + strI, "-addr=127.0.0.1:400" + strI, "-peer-addr=127.0.0.1:700" + strI, "-data-dir=/tmp/node" + strI, "-leader-zone=b"}
+		if i == 0 {
+			args = append(args, "-tags=zone=a")
+		} else {
+			args = append(args, "-tags=zone=b", "-peers=127.0.0.1:7001")
+		}
+
+		var err error
+		etcds[i], err = os.StartProcess(EtcdBinPath, args, procAttr)
+		if err != nil {
+			t.Fatal("start process failed:" + err.Error())
+		}
+		defer etcds[i].Kill()
+
+		if i == 0 {
+			time.Sleep(time.Second * 2)
+		}
+	}
+
+	time.Sleep(time.Second)
+	leader, err := getLeader("http://127.0.0.1:4001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if leader != "http://127.0.0.1:7001" {
+		t.Fatalf("expected node1 to lead first, got %s", leader)
+	}
+
+	// Leave enough time for the periodic check to run.
+	for i := 0; i < 20; i++ {
+		time.Sleep(time.Second)
+		leader, err = getLeader("http://127.0.0.1:4001")
+		if err == nil && leader != "http://127.0.0.1:7001" {
+			return
+		}
+	}
+	t.Fatalf("leadership did not move into the preferred zone, leader is %s", leader)
+}
@@ -28,8 +28,11 @@ func RunServer(f func(*server.Server)) {
 
 	ps := server.NewPeerServer(testName, path, "http://"+testRaftURL, testRaftURL, &server.TLSConfig{Scheme: "http"}, &server.TLSInfo{}, registry, store, testSnapshotCount)
 	ps.MaxClusterSize = 9
+	ps.MaxObservers = 9
 	ps.ElectionTimeout = testElectionTimeout
 	ps.HeartbeatTimeout = testHeartbeatTimeout
+	// The data is thrown away, so do not wait on the disk for it.
+	ps.NoFsync = true
 	s := server.New(testName, "http://"+testClientURL, testClientURL, &server.TLSConfig{Scheme: "http"}, &server.TLSInfo{}, ps, registry, store)
 	ps.SetServer(s)
 
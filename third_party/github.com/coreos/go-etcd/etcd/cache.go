@@ -0,0 +1,256 @@
+package etcd
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrCacheStale is returned by a cache that has not heard from the cluster
+// for longer than its staleness bound.
+var ErrCacheStale = errors.New("Cache is stale")
+
+// The error code of a watch whose index was cleared from the event history.
+const errorCodeEventIndexCleared = 401
+
+// How long a failed watch waits before trying again.
+const cacheRetryInterval = time.Second
+
+// Cache is an in-memory copy of the keys under a prefix. It is loaded once
+// and then kept up to date by watching the prefix. When the watch falls so
+// far behind that the changes it missed are no longer in the event history,
+// the prefix is loaded again.
+type Cache struct {
+	client   *Client
+	prefix   string
+	maxStale time.Duration
+
+	mu     sync.RWMutex
+	nodes  map[string]*Node
+	index  uint64
+	synced time.Time
+	stop   chan bool
+	done   chan bool
+}
+
+// NewCache creates a cache of the keys under prefix. Get fails with
+// ErrCacheStale once the cache has not heard from the cluster for maxStale,
+// for example because the watch keeps failing. The cache is empty until it
+// is started.
+func (c *Client) NewCache(prefix string, maxStale time.Duration) *Cache {
+	return &Cache{
+		client:   c,
+		prefix:   path.Join("/", prefix),
+		maxStale: maxStale,
+		nodes:    make(map[string]*Node),
+	}
+}
+
+// Start loads the prefix and keeps watching it in the background until the
+// cache is stopped.
+func (c *Cache) Start() error {
+	if c.maxStale <= 0 {
+		return errors.New("Cache staleness bound must be greater than zero")
+	}
+	if err := c.load(); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.stop = make(chan bool)
+	c.done = make(chan bool)
+	c.mu.Unlock()
+	go c.watch(c.stop, c.done)
+	return nil
+}
+
+// Stop stops watching the prefix. The cache keeps what it holds but turns
+// stale after maxStale.
+func (c *Cache) Stop() {
+	c.mu.Lock()
+	stop, done := c.stop, c.done
+	c.stop = nil
+	c.mu.Unlock()
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+}
+
+// Get returns a copy of the node of a key under the prefix. A key that is
+// not cached returns an EtcdError with the key not found code, like the
+// server would.
+func (c *Cache) Get(key string) (*Node, error) {
+	key = path.Join("/", key)
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if time.Since(c.synced) > c.maxStale {
+		return nil, ErrCacheStale
+	}
+	n, ok := c.nodes[key]
+	if !ok {
+		return nil, EtcdError{ErrorCode: 100, Message: "Key not found", Cause: key}
+	}
+	node := *n
+	return &node, nil
+}
+
+// Index returns the etcd index the cache is current at.
+func (c *Cache) Index() uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.index
+}
+
+// load replaces the content of the cache with the current keys under the
+// prefix, and the index they were read at.
+func (c *Cache) load() error {
+	raw, err := c.client.RawGet(c.prefix, false, true)
+	if err != nil {
+		return err
+	}
+	index, _ := strconv.ParseUint(raw.Header.Get("X-Etcd-Index"), 10, 64)
+
+	nodes := make(map[string]*Node)
+	resp, err := raw.toResponse()
+	if e, ok := err.(EtcdError); ok && e.ErrorCode == 100 {
+		// The prefix does not exist yet.
+	} else if err != nil {
+		return err
+	} else if resp.Node != nil {
+		for i := range resp.Node.Nodes {
+			flatten(&resp.Node.Nodes[i], nodes)
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.nodes = nodes
+	c.index = index
+	c.synced = time.Now()
+	return nil
+}
+
+// flatten adds a node and the nodes under it to the map, by key.
+func flatten(n *Node, nodes map[string]*Node) {
+	node := *n
+	node.Nodes = nil
+	nodes[n.Key] = &node
+	for i := range n.Nodes {
+		flatten(&n.Nodes[i], nodes)
+	}
+}
+
+// watch applies the changes to the prefix until stop is closed. A watch
+// still waiting after half the staleness bound is restarted: it shows that
+// nothing changed while the cluster held it.
+func (c *Cache) watch(stop chan bool, done chan bool) {
+	defer close(done)
+
+	for {
+		cancel, finished := make(chan bool), make(chan bool)
+		go func() {
+			select {
+			case <-stop:
+			case <-finished:
+			case <-time.After(c.maxStale / 2):
+			}
+			close(cancel)
+		}()
+		resp, err := c.watchOnce(c.Index()+1, cancel)
+		close(finished)
+
+		select {
+		case <-stop:
+			return
+		default:
+		}
+
+		switch e := err.(type) {
+		case nil:
+			c.apply(resp)
+		case EtcdError:
+			if e.ErrorCode == errorCodeEventIndexCleared {
+				// Changes were missed; start over from the current keys.
+				logger.Debugf("cache %s: watch index cleared, reloading", c.prefix)
+				err = c.load()
+			}
+		default:
+			if err == errStopped {
+				c.mu.Lock()
+				c.synced = time.Now()
+				c.mu.Unlock()
+				err = nil
+			}
+		}
+
+		if err != nil {
+			logger.Debugf("cache %s: %v", c.prefix, err)
+			select {
+			case <-stop:
+				return
+			case <-time.After(cacheRetryInterval):
+			}
+		}
+	}
+}
+
+// watchOnce waits for the first change under the prefix since the given
+// index. Unlike Watch, closing stop cancels the request on the server.
+func (c *Cache) watchOnce(index uint64, stop chan bool) (*Response, error) {
+	v := url.Values{"wait": {"true"}, "recursive": {"true"}, "waitIndex": {strconv.FormatUint(index, 10)}}
+	random := c.client.config.Consistency == WEAK_CONSISTENCY
+	req, _ := http.NewRequest("GET", c.client.getHttpPath(random, path.Join("keys", c.prefix))+"?"+v.Encode(), nil)
+
+	resp, err := c.client.doCancelable(req, stop)
+	if err != nil {
+		return nil, err
+	}
+	b, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusRequestTimeout {
+		// The server ended the watch after its maximum watch duration, which
+		// shows that nothing changed while it held it.
+		return nil, errStopped
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusBadRequest {
+		return nil, fmt.Errorf("Unexpected status %d watching %s", resp.StatusCode, c.prefix)
+	}
+
+	raw := &RawResponse{StatusCode: resp.StatusCode, Body: b, Header: resp.Header}
+	return raw.toResponse()
+}
+
+// apply updates the cache with a change to a key under the prefix.
+func (c *Cache) apply(resp *Response) {
+	if resp.Node == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch resp.Action {
+	case "delete", "expire":
+		delete(c.nodes, resp.Node.Key)
+		for key := range c.nodes {
+			if strings.HasPrefix(key, resp.Node.Key+"/") {
+				delete(c.nodes, key)
+			}
+		}
+	default:
+		flatten(resp.Node, c.nodes)
+	}
+	c.index = resp.Node.ModifiedIndex
+	c.synced = time.Now()
+}
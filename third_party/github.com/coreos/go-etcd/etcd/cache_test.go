@@ -0,0 +1,55 @@
+package etcd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	c := NewClient(nil)
+	defer func() {
+		c.Delete("fooCache", true)
+	}()
+
+	c.Set("fooCache/a", "1", 0)
+	c.Set("fooCache/dir/b", "2", 0)
+
+	cache := c.NewCache("fooCache", 2*time.Second)
+	if err := cache.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer cache.Stop()
+
+	n, err := cache.Get("fooCache/dir/b")
+	if err != nil || n.Value != "2" {
+		t.Fatalf("Get of a loaded key returned %v, %v", n, err)
+	}
+
+	// Changes reach the cache through the watch.
+	c.Set("fooCache/a", "3", 0)
+	c.Delete("fooCache/dir", true)
+	time.Sleep(100 * time.Millisecond)
+
+	n, err = cache.Get("fooCache/a")
+	if err != nil || n.Value != "3" {
+		t.Fatalf("Get of an updated key returned %v, %v", n, err)
+	}
+	if _, err := cache.Get("fooCache/dir/b"); err == nil {
+		t.Fatal("Get of a deleted key should fail")
+	} else if e, ok := err.(EtcdError); !ok || e.ErrorCode != 100 {
+		t.Fatalf("Get of a deleted key returned %v", err)
+	}
+
+	// A quiet prefix stays fresh while it is watched.
+	time.Sleep(3 * time.Second)
+	if _, err := cache.Get("fooCache/a"); err != nil {
+		t.Fatalf("Get of a quiet prefix returned %v", err)
+	}
+
+	// A stopped cache turns stale.
+	cache.Stop()
+	time.Sleep(3 * time.Second)
+	if _, err := cache.Get("fooCache/a"); err != ErrCacheStale {
+		t.Fatalf("Get of a stopped cache returned %v", err)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"os"
 	"path"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -52,6 +53,16 @@ type Client struct {
 	httpClient  *http.Client
 	persistence io.Writer
 	cURLch      chan string
+
+	// Guards the cluster, which AutoSync updates in the background.
+	clusterMutex sync.RWMutex
+
+	// Closed to stop AutoSync.
+	syncMutex sync.Mutex
+	stopSync  chan bool
+
+	// Per-endpoint stats and blacklisting, see health.go.
+	health endpointHealth
 }
 
 // NewClient create a basic client that is configured to be used
@@ -180,6 +191,7 @@ func (c *Client) SetConsistency(consistency string) error {
 // MarshalJSON implements the Marshaller interface
 // as defined by the standard JSON package.
 func (c *Client) MarshalJSON() ([]byte, error) {
+	c.clusterMutex.RLock()
 	b, err := json.Marshal(struct {
 		Config  Config  `json:"config"`
 		Cluster Cluster `json:"cluster"`
@@ -187,6 +199,7 @@ func (c *Client) MarshalJSON() ([]byte, error) {
 		Config:  c.config,
 		Cluster: c.cluster,
 	})
+	c.clusterMutex.RUnlock()
 
 	if err != nil {
 		return nil, err
@@ -252,6 +265,12 @@ func (c *Client) SetCertAndKey(cert string, key string) error {
 	return errors.New("Require both cert and key path")
 }
 
+// SetTransport sets the transport requests are sent through. A nil
+// transport uses http.DefaultTransport.
+func (c *Client) SetTransport(tr http.RoundTripper) {
+	c.httpClient = &http.Client{Transport: tr}
+}
+
 func (c *Client) SetScheme(scheme int) error {
 	if scheme == HTTP {
 		c.config.Scheme = "http"
@@ -273,12 +292,14 @@ func (c *Client) SetCluster(machines []string) bool {
 }
 
 func (c *Client) GetCluster() []string {
+	c.clusterMutex.RLock()
+	defer c.clusterMutex.RUnlock()
 	return c.cluster.Machines
 }
 
 // SyncCluster updates config using the internal machine list.
 func (c *Client) SyncCluster() bool {
-	success := c.internalSyncCluster(c.cluster.Machines)
+	success := c.internalSyncCluster(c.GetCluster())
 	return success
 }
 
@@ -299,6 +320,7 @@ func (c *Client) internalSyncCluster(machines []string) bool {
 			}
 
 			// update Machines List
+			c.clusterMutex.Lock()
 			c.cluster.Machines = strings.Split(string(b), ", ")
 
 			// update leader
@@ -307,6 +329,7 @@ func (c *Client) internalSyncCluster(machines []string) bool {
 			c.cluster.Leader = c.cluster.Machines[0]
 
 			logger.Debug("sync.machines ", c.cluster.Machines)
+			c.clusterMutex.Unlock()
 			c.saveConfig()
 			return true
 		}
@@ -339,13 +362,18 @@ func (c *Client) updateLeader(u *url.URL) {
 		leader = u.Scheme + "://" + u.Host
 	}
 
+	c.clusterMutex.Lock()
 	logger.Debugf("update.leader[%s,%s]", c.cluster.Leader, leader)
 	c.cluster.Leader = leader
+	c.clusterMutex.Unlock()
 	c.saveConfig()
 }
 
 // switchLeader switch the current leader to machines[num]
 func (c *Client) switchLeader(num int) {
+	c.clusterMutex.Lock()
+	defer c.clusterMutex.Unlock()
+	num %= len(c.cluster.Machines)
 	logger.Debugf("switch.leader[from %v to %v]",
 		c.cluster.Leader, c.cluster.Machines[num])
 
@@ -0,0 +1,112 @@
+package etcd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// DecodeError is a key whose value is not valid JSON for the type it was
+// decoded into.
+type DecodeError struct {
+	Key           string
+	ModifiedIndex uint64
+	Err           error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("Cannot decode %s at index %d: %v", e.Key, e.ModifiedIndex, e.Err)
+}
+
+// TypedResponse is a watch response along with the value of its node
+// decoded into the type the watch was given. Value is a pointer to a new
+// value of that type, or nil for deletes, expirations and directories.
+type TypedResponse struct {
+	*Response
+	Value interface{}
+}
+
+// GetJSON gets the given key and decodes its value, which must be JSON,
+// into v. A value that does not decode into v returns a *DecodeError along
+// with the response.
+func (c *Client) GetJSON(key string, v interface{}) (*Response, error) {
+	resp, err := c.Get(key, false, false)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Node.Dir {
+		return resp, &DecodeError{Key: resp.Node.Key, ModifiedIndex: resp.Node.ModifiedIndex,
+			Err: errors.New("Is a directory")}
+	}
+	return resp, decodeNode(resp.Node, v)
+}
+
+// WatchJSON watches like Watch with a receiver channel, and decodes the
+// value of every changed node into a new value of the type of proto, which
+// is a value or a pointer to a value of that type, such as Job{} or &Job{}.
+//
+// Events whose value does not decode are not sent to the receiver; a
+// *DecodeError is sent to errs instead, if errs is not nil, and the watch
+// goes on. WatchJSON returns when the watch itself fails or is stopped.
+func (c *Client) WatchJSON(prefix string, waitIndex uint64, recursive bool, proto interface{},
+	receiver chan *TypedResponse, errs chan error, stop chan bool) error {
+	typ := reflect.TypeOf(proto)
+	if typ == nil {
+		return errors.New("Watch value type required")
+	}
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	for {
+		raw, err := c.watchOnce(prefix, waitIndex, recursive, stop)
+		if err != nil {
+			return err
+		}
+		resp, err := raw.toResponse()
+		if err != nil {
+			return err
+		}
+		waitIndex = resp.Node.ModifiedIndex + 1
+
+		typed := &TypedResponse{Response: resp}
+		if hasValue(resp) {
+			v := reflect.New(typ).Interface()
+			if err := decodeNode(resp.Node, v); err != nil {
+				if errs != nil {
+					select {
+					case errs <- err:
+					case <-stop:
+						return ErrWatchStoppedByUser
+					}
+				}
+				continue
+			}
+			typed.Value = v
+		}
+
+		select {
+		case receiver <- typed:
+		case <-stop:
+			return ErrWatchStoppedByUser
+		}
+	}
+}
+
+// hasValue tells whether the node of a watch response holds a value to
+// decode.
+func hasValue(resp *Response) bool {
+	switch resp.Action {
+	case "delete", "expire":
+		return false
+	}
+	return !resp.Node.Dir
+}
+
+func decodeNode(n *Node, v interface{}) error {
+	if err := json.Unmarshal([]byte(n.Value), v); err != nil {
+		return &DecodeError{Key: n.Key, ModifiedIndex: n.ModifiedIndex, Err: err}
+	}
+	return nil
+}
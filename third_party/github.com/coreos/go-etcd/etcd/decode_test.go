@@ -0,0 +1,65 @@
+package etcd
+
+import (
+	"testing"
+)
+
+type decodeJob struct {
+	Image string `json:"image"`
+}
+
+func TestGetJSON(t *testing.T) {
+	c := NewClient(nil)
+	defer func() {
+		c.Delete("fooJSON", true)
+	}()
+
+	c.Set("fooJSON/a", `{"image": "busybox"}`, 0)
+	c.Set("fooJSON/b", `not json`, 0)
+
+	var job decodeJob
+	if _, err := c.GetJSON("fooJSON/a", &job); err != nil || job.Image != "busybox" {
+		t.Fatalf("GetJSON of a JSON value returned %v, %v", job, err)
+	}
+	if _, err := c.GetJSON("fooJSON/b", &job); err == nil {
+		t.Fatal("GetJSON of an invalid value should fail")
+	} else if _, ok := err.(*DecodeError); !ok {
+		t.Fatalf("GetJSON of an invalid value returned %v", err)
+	}
+}
+
+func TestWatchJSON(t *testing.T) {
+	c := NewClient(nil)
+	defer func() {
+		c.Delete("watchJSON", true)
+	}()
+
+	resp, err := c.Set("watchJSON/a", `{"image": "busybox"}`, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Set("watchJSON/a", `not json`, 0)
+	c.Set("watchJSON/a", `{"image": "nginx"}`, 0)
+	c.Delete("watchJSON/a", false)
+
+	receiver := make(chan *TypedResponse, 10)
+	errs := make(chan error, 10)
+	stop := make(chan bool)
+	go c.WatchJSON("watchJSON", resp.Node.ModifiedIndex, true, &decodeJob{}, receiver, errs, stop)
+	defer close(stop)
+
+	for _, image := range []string{"busybox", "nginx"} {
+		r := <-receiver
+		if job, ok := r.Value.(*decodeJob); !ok || job.Image != image {
+			t.Fatalf("Watch returned %#v, expected image %s", r.Value, image)
+		}
+	}
+	if r := <-receiver; r.Action != "delete" || r.Value != nil {
+		t.Fatalf("Watch of a delete returned %#v", r)
+	}
+	if err := <-errs; err == nil {
+		t.Fatal("Watch of an invalid value should report an error")
+	} else if _, ok := err.(*DecodeError); !ok {
+		t.Fatalf("Watch of an invalid value reported %v", err)
+	}
+}
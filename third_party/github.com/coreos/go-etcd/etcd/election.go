@@ -0,0 +1,129 @@
+package etcd
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"sync"
+)
+
+// Election is a leader election built on the etcd leader module.
+// Once elected the leadership is kept alive in the background until the
+// candidate resigns or the leadership is lost.
+type Election struct {
+	client *Client
+	key    string
+
+	mu   sync.Mutex
+	name string
+	ttl  uint64
+	stop chan bool
+	lost chan bool
+}
+
+// NewElection creates an election on the given key. No campaign is started.
+func (c *Client) NewElection(key string) *Election {
+	return &Election{client: c, key: key}
+}
+
+// Campaign waits until name is elected leader. The ttl is how long the
+// leadership survives if this client goes away. Closing stop abandons the
+// campaign.
+//
+// The returned channel is closed once name is no longer the leader, either
+// because it resigned or because the leadership could not be kept alive.
+func (e *Election) Campaign(name string, ttl uint64, stop chan bool) (<-chan bool, error) {
+	if name == "" {
+		return nil, errors.New("Candidate name required")
+	}
+	if ttl == 0 {
+		return nil, errors.New("Leader TTL must be greater than zero")
+	}
+
+	e.mu.Lock()
+	if e.name != "" {
+		e.mu.Unlock()
+		return nil, errors.New("Already leader")
+	}
+	e.mu.Unlock()
+
+	params := url.Values{"name": {name}, "ttl": {strconv.FormatUint(ttl, 10)}}
+	if _, err := e.client.sendModRequest("PUT", "leader/"+e.key, params, stop); err != nil {
+		if err == errStopped {
+			// The candidate may still be queued; withdraw it.
+			e.client.sendModRequest("DELETE", "leader/"+e.key, url.Values{"name": {name}}, nil)
+		}
+		return nil, err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.name = name
+	e.ttl = ttl
+	e.stop = make(chan bool)
+	e.lost = make(chan bool)
+	go keepAlive(ttl, e.renew, e.stop, e.lost, e.clear)
+	return e.lost, nil
+}
+
+// Leader returns the name of the current leader.
+func (e *Election) Leader() (string, error) {
+	return e.client.sendModRequest("GET", "leader/"+e.key, nil, nil)
+}
+
+// Proclaim changes the name the leader is known by, for example to
+// advertise a new endpoint, without giving up the leadership. The leadership
+// keeps its lock index, so observers do not see it as a new leader.
+func (e *Election) Proclaim(name string) error {
+	if name == "" {
+		return errors.New("Candidate name required")
+	}
+
+	// Hold the lock so that renewals do not use the old name while the
+	// proclamation is on its way.
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.name == "" {
+		return ErrNotHeld
+	}
+
+	params := url.Values{"name": {e.name}, "value": {name}}
+	if _, err := e.client.sendModRequest("PUT", "leader/"+e.key+"/proclaim", params, nil); err != nil {
+		return err
+	}
+	e.name = name
+	return nil
+}
+
+// Resign gives up the leadership and stops keeping it alive.
+func (e *Election) Resign() error {
+	e.mu.Lock()
+	name, stop := e.name, e.stop
+	e.mu.Unlock()
+	if name == "" {
+		return ErrNotHeld
+	}
+
+	close(stop)
+	<-e.lost
+	_, err := e.client.sendModRequest("DELETE", "leader/"+e.key, url.Values{"name": {name}}, nil)
+	return err
+}
+
+// renew resets the TTL of the leadership. It renews the underlying lock
+// directly since renewing through the leader module would queue the
+// candidate again if the leadership had already expired.
+func (e *Election) renew() error {
+	e.mu.Lock()
+	params := url.Values{"value": {e.name}, "ttl": {strconv.FormatUint(e.ttl, 10)}}
+	e.mu.Unlock()
+	_, err := e.client.sendModRequest("PUT", "lock/"+e.key, params, nil)
+	return err
+}
+
+// clear marks the leadership as lost.
+func (e *Election) clear() {
+	e.mu.Lock()
+	e.name = ""
+	e.mu.Unlock()
+}
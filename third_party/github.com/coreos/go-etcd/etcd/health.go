@@ -0,0 +1,214 @@
+package etcd
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// How long a failing endpoint is skipped unless SetBlacklistDuration is called.
+const defaultBlacklistDuration = 5 * time.Second
+
+// EndpointStats describes what the client has seen of one endpoint through
+// requests and health probes.
+type EndpointStats struct {
+	Endpoint  string
+	Successes uint64
+	Failures  uint64
+	// Latency is a moving average of the successful round trips.
+	Latency time.Duration
+	// Leader is true if the last probe found the endpoint leading the cluster.
+	Leader bool
+	// BlacklistedUntil is zero unless the endpoint is being skipped.
+	BlacklistedUntil time.Time
+}
+
+// endpointHealth holds the stats of every endpoint the client has used.
+type endpointHealth struct {
+	sync.Mutex
+	blacklistFor time.Duration
+	endpoints    map[string]*EndpointStats
+	stopProbe    chan bool
+}
+
+// stats returns the entry of an endpoint, creating it on first use.
+// The caller holds the lock.
+func (h *endpointHealth) stats(endpoint string) *EndpointStats {
+	if h.endpoints == nil {
+		h.endpoints = make(map[string]*EndpointStats)
+	}
+	s, ok := h.endpoints[endpoint]
+	if !ok {
+		s = &EndpointStats{Endpoint: endpoint}
+		h.endpoints[endpoint] = s
+	}
+	return s
+}
+
+// success records a successful round trip and lifts any blacklisting.
+// A zero latency is not counted in the average; long polls use it.
+func (h *endpointHealth) success(endpoint string, latency time.Duration) {
+	h.Lock()
+	defer h.Unlock()
+	s := h.stats(endpoint)
+	s.Successes++
+	s.BlacklistedUntil = time.Time{}
+	if latency <= 0 {
+		return
+	}
+	if s.Latency == 0 {
+		s.Latency = latency
+	} else {
+		s.Latency = (4*s.Latency + latency) / 5
+	}
+}
+
+// failure records a failed round trip and blacklists the endpoint.
+func (h *endpointHealth) failure(endpoint string) {
+	h.Lock()
+	defer h.Unlock()
+	s := h.stats(endpoint)
+	s.Failures++
+	s.Leader = false
+	d := h.blacklistFor
+	if d == 0 {
+		d = defaultBlacklistDuration
+	}
+	s.BlacklistedUntil = time.Now().Add(d)
+	logger.Debugf("blacklist.endpoint[%s until %v]", endpoint, s.BlacklistedUntil)
+}
+
+// blacklisted reports whether an endpoint is currently being skipped.
+func (h *endpointHealth) blacklisted(endpoint string) bool {
+	h.Lock()
+	defer h.Unlock()
+	s, ok := h.endpoints[endpoint]
+	return ok && time.Now().Before(s.BlacklistedUntil)
+}
+
+// available returns the machines that are not blacklisted, keeping their
+// order. If every machine is blacklisted all of them are returned, so that
+// a cluster coming back is noticed without waiting for the blacklist to end.
+func (h *endpointHealth) available(machines []string) []string {
+	var ok []string
+	for _, m := range machines {
+		if !h.blacklisted(m) {
+			ok = append(ok, m)
+		}
+	}
+	if len(ok) == 0 {
+		return machines
+	}
+	return ok
+}
+
+// SetBlacklistDuration sets how long an endpoint that failed a request or
+// a health probe is skipped.
+func (c *Client) SetBlacklistDuration(d time.Duration) {
+	c.health.Lock()
+	c.health.blacklistFor = d
+	c.health.Unlock()
+}
+
+// EndpointStats returns the stats of every endpoint the client has used,
+// sorted by endpoint.
+func (c *Client) EndpointStats() []EndpointStats {
+	c.health.Lock()
+	defer c.health.Unlock()
+	stats := make([]EndpointStats, 0, len(c.health.endpoints))
+	for _, s := range c.health.endpoints {
+		stats = append(stats, *s)
+	}
+	sort.Sort(byEndpoint(stats))
+	return stats
+}
+
+type byEndpoint []EndpointStats
+
+func (s byEndpoint) Len() int           { return len(s) }
+func (s byEndpoint) Less(i, j int) bool { return s[i].Endpoint < s[j].Endpoint }
+func (s byEndpoint) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// StartProbing checks the /health endpoint of every machine each interval
+// until StopProbing is called. Failing machines are blacklisted, and the
+// machine reporting itself as leader becomes the one writes are sent to.
+func (c *Client) StartProbing(interval time.Duration) {
+	c.StopProbing()
+
+	stop := make(chan bool)
+	c.health.Lock()
+	c.health.stopProbe = stop
+	c.health.Unlock()
+
+	go func() {
+		for {
+			c.probe()
+			select {
+			case <-stop:
+				return
+			case <-time.After(interval):
+			}
+		}
+	}()
+}
+
+// StopProbing stops checking the health of the machines.
+func (c *Client) StopProbing() {
+	c.health.Lock()
+	defer c.health.Unlock()
+	if c.health.stopProbe != nil {
+		close(c.health.stopProbe)
+		c.health.stopProbe = nil
+	}
+}
+
+// probe checks the health of every machine at once.
+func (c *Client) probe() {
+	machines := c.GetCluster()
+	states := make([]string, len(machines))
+	var wg sync.WaitGroup
+	for i, m := range machines {
+		wg.Add(1)
+		go func(i int, m string) {
+			defer wg.Done()
+			start := time.Now()
+			states[i] = c.memberState(m)
+			if states[i] == "" {
+				c.health.failure(m)
+			} else {
+				c.health.success(m, time.Since(start))
+			}
+		}(i, m)
+	}
+	wg.Wait()
+
+	c.health.Lock()
+	for i, m := range machines {
+		if states[i] != "" {
+			c.health.stats(m).Leader = states[i] == "leader"
+		}
+	}
+	c.health.Unlock()
+
+	for i, m := range machines {
+		if states[i] == "leader" {
+			c.clusterMutex.Lock()
+			changed := c.cluster.Leader != m
+			if changed {
+				logger.Debugf("update.leader[%s,%s]", c.cluster.Leader, m)
+				c.cluster.Leader = m
+			}
+			c.clusterMutex.Unlock()
+			if changed {
+				c.saveConfig()
+			}
+			return
+		}
+	}
+}
+
+// machineOf returns the machine a request is sent to.
+func machineOf(req *http.Request) string {
+	return req.URL.Scheme + "://" + req.URL.Host
+}
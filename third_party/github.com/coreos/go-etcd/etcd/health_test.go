@@ -0,0 +1,53 @@
+package etcd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBlacklist(t *testing.T) {
+	// A dead machine first in the list costs only the first request.
+	c := NewClient([]string{"http://127.0.0.1:4999", "http://127.0.0.1:4001"})
+	c.SetConsistency(WEAK_CONSISTENCY)
+	defer c.Delete("fooBlacklist", true)
+
+	if _, err := c.Set("fooBlacklist", "bar", 0); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		start := time.Now()
+		if _, err := c.Get("fooBlacklist", false, false); err != nil {
+			t.Fatal(err)
+		}
+		if d := time.Since(start); d > 100*time.Millisecond {
+			t.Fatalf("Get took %v with the dead machine blacklisted", d)
+		}
+	}
+
+	stats := c.EndpointStats()
+	if len(stats) != 2 {
+		t.Fatalf("EndpointStats returned %v", stats)
+	}
+	if dead := stats[1]; dead.Endpoint != "http://127.0.0.1:4999" || dead.Failures == 0 || dead.BlacklistedUntil.IsZero() {
+		t.Fatalf("Dead machine stats are %+v", dead)
+	}
+	if live := stats[0]; live.Successes < 6 || live.Latency == 0 || !live.BlacklistedUntil.IsZero() {
+		t.Fatalf("Live machine stats are %+v", live)
+	}
+}
+
+func TestProbing(t *testing.T) {
+	c := NewClient([]string{"http://127.0.0.1:4999", "http://127.0.0.1:4001"})
+	c.StartProbing(100 * time.Millisecond)
+	defer c.StopProbing()
+	time.Sleep(2 * time.Second)
+
+	stats := c.EndpointStats()
+	if len(stats) != 2 || !stats[0].Leader || stats[1].BlacklistedUntil.IsZero() {
+		t.Fatalf("EndpointStats returned %+v", stats)
+	}
+	// Writes now go to the leader.
+	if l := c.cluster.Leader; l != "http://127.0.0.1:4001" {
+		t.Fatalf("Probing did not find the leader, got %s", l)
+	}
+}
@@ -0,0 +1,136 @@
+package etcd
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrNotHeld is returned when releasing a lock or resigning an election
+// that is not currently held.
+var ErrNotHeld = errors.New("Not held")
+
+// Lock is a distributed lock built on the etcd lock module.
+// Once acquired the lock is kept alive in the background until it is
+// released or lost.
+type Lock struct {
+	client *Client
+	key    string
+
+	mu    sync.Mutex
+	index string
+	ttl   uint64
+	stop  chan bool
+	lost  chan bool
+}
+
+// NewLock creates a lock on the given key. The lock is not acquired.
+func (c *Client) NewLock(key string) *Lock {
+	return &Lock{client: c, key: key}
+}
+
+// Acquire waits until the lock is held. The value identifies the holder
+// and ttl is how long the lock survives if this client goes away.
+// Closing stop abandons the wait.
+//
+// The returned channel is closed once the lock is no longer held, either
+// because it was released or because it could not be kept alive.
+func (l *Lock) Acquire(value string, ttl uint64, stop chan bool) (<-chan bool, error) {
+	if ttl == 0 {
+		return nil, errors.New("Lock TTL must be greater than zero")
+	}
+
+	l.mu.Lock()
+	if l.index != "" {
+		l.mu.Unlock()
+		return nil, errors.New("Lock already held")
+	}
+	l.mu.Unlock()
+
+	params := url.Values{"ttl": {strconv.FormatUint(ttl, 10)}}
+	if value != "" {
+		params.Set("value", value)
+	}
+	index, err := l.client.sendModRequest("POST", "lock/"+l.key, params, stop)
+	if err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.index = index
+	l.ttl = ttl
+	l.stop = make(chan bool)
+	l.lost = make(chan bool)
+	go keepAlive(ttl, l.renew, l.stop, l.lost, l.clear)
+	return l.lost, nil
+}
+
+// Index returns the index of the held lock, or an empty string if it is not held.
+func (l *Lock) Index() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.index
+}
+
+// Release releases the lock and stops keeping it alive.
+func (l *Lock) Release() error {
+	l.mu.Lock()
+	index, stop := l.index, l.stop
+	l.mu.Unlock()
+	if index == "" {
+		return ErrNotHeld
+	}
+
+	close(stop)
+	<-l.lost
+	_, err := l.client.sendModRequest("DELETE", "lock/"+l.key, url.Values{"index": {index}}, nil)
+	return err
+}
+
+// renew resets the TTL of the held lock.
+func (l *Lock) renew() error {
+	l.mu.Lock()
+	params := url.Values{"index": {l.index}, "ttl": {strconv.FormatUint(l.ttl, 10)}}
+	l.mu.Unlock()
+	_, err := l.client.sendModRequest("PUT", "lock/"+l.key, params, nil)
+	return err
+}
+
+// clear marks the lock as no longer held.
+func (l *Lock) clear() {
+	l.mu.Lock()
+	l.index = ""
+	l.mu.Unlock()
+}
+
+// keepAlive calls renew three times per TTL until stop is closed or the
+// hold is lost. A rejected renewal loses it immediately; network errors are
+// retried until a full TTL has passed without a successful renewal.
+// The lost channel is closed after clear is called.
+func keepAlive(ttl uint64, renew func() error, stop chan bool, lost chan bool, clear func()) {
+	defer close(lost)
+	defer clear()
+
+	interval := time.Duration(ttl) * time.Second / 3
+	deadline := time.Now().Add(time.Duration(ttl) * time.Second)
+	for {
+		select {
+		case <-stop:
+			return
+		case <-time.After(interval):
+		}
+
+		err := renew()
+		if err == nil {
+			deadline = time.Now().Add(time.Duration(ttl) * time.Second)
+			continue
+		}
+		logger.Debug("keep alive failed: ", err)
+		if _, ok := err.(*ModError); ok || time.Now().After(deadline) {
+			return
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package etcd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLock(t *testing.T) {
+	c := NewClient(nil)
+	l := c.NewLock("fooLock")
+
+	lost, err := l.Acquire("holder1", 2, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Index() == "" {
+		t.Fatal("Acquire did not record the lock index")
+	}
+
+	// A second holder waits until the first one releases the lock.
+	acquired := make(chan bool)
+	l2 := c.NewLock("fooLock")
+	go func() {
+		if _, err := l2.Acquire("holder2", 2, nil); err != nil {
+			t.Error(err)
+		}
+		close(acquired)
+	}()
+
+	// The keep alive holds the lock past its TTL.
+	select {
+	case <-acquired:
+		t.Fatal("Lock acquired twice")
+	case <-lost:
+		t.Fatal("Lock lost while held")
+	case <-time.After(3 * time.Second):
+	}
+
+	if err := l.Release(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-lost:
+	default:
+		t.Fatal("Lost channel not closed after release")
+	}
+	if err := l.Release(); err != ErrNotHeld {
+		t.Fatalf("Release of an unheld lock should return ErrNotHeld, not %v", err)
+	}
+
+	<-acquired
+	l2.Release()
+}
+
+func TestLockStop(t *testing.T) {
+	c := NewClient(nil)
+	l := c.NewLock("fooLockStop")
+	if _, err := l.Acquire("holder1", 5, nil); err != nil {
+		t.Fatal(err)
+	}
+	defer l.Release()
+
+	stop := make(chan bool)
+	go func() {
+		time.Sleep(500 * time.Millisecond)
+		close(stop)
+	}()
+	if _, err := c.NewLock("fooLockStop").Acquire("holder2", 5, stop); err == nil {
+		t.Fatal("Acquire should fail when stopped")
+	}
+}
+
+func TestElection(t *testing.T) {
+	c := NewClient(nil)
+	e := c.NewElection("fooElection")
+
+	lost, err := e.Campaign("node1", 2, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	elected := make(chan bool)
+	e2 := c.NewElection("fooElection")
+	go func() {
+		if _, err := e2.Campaign("node2", 2, nil); err != nil {
+			t.Error(err)
+		}
+		close(elected)
+	}()
+
+	select {
+	case <-elected:
+		t.Fatal("Two leaders elected")
+	case <-lost:
+		t.Fatal("Leadership lost while held")
+	case <-time.After(3 * time.Second):
+	}
+
+	leader, err := e.Leader()
+	if err != nil || leader != "node1" {
+		t.Fatalf("Leader should be node1, not %q (%v)", leader, err)
+	}
+
+	if err := e.Proclaim("node1b"); err != nil {
+		t.Fatal(err)
+	}
+	if leader, _ := e.Leader(); leader != "node1b" {
+		t.Fatalf("Leader should be node1b, not %q", leader)
+	}
+
+	if err := e.Resign(); err != nil {
+		t.Fatal(err)
+	}
+	<-elected
+	if leader, _ := e.Leader(); leader != "node2" {
+		t.Fatalf("Leader should be node2, not %q", leader)
+	}
+	e2.Resign()
+}
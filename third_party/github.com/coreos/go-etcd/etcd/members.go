@@ -0,0 +1,177 @@
+package etcd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+// How long a member may take to answer a health check during a sync.
+const healthTimeout = time.Second
+
+// Member is a member of the cluster as listed by the members API.
+type Member struct {
+	Name      string            `json:"name"`
+	ClientURL string            `json:"clientURL"`
+	PeerURL   string            `json:"peerURL"`
+	Tags      map[string]string `json:"tags,omitempty"`
+}
+
+// Members retrieves the name, URLs and tags of every member of the cluster.
+// The members API only answers clients holding the admin role.
+func (c *Client) Members() ([]Member, error) {
+	for _, machine := range c.GetCluster() {
+		resp, err := c.httpClient.Get(c.createHttpPath(machine, version+"/members"))
+		if err != nil {
+			// try another machine in the cluster
+			continue
+		}
+		b, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			continue
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("members: %s", http.StatusText(resp.StatusCode))
+		}
+
+		var members []Member
+		if err := json.Unmarshal(b, &members); err != nil {
+			return nil, err
+		}
+		return members, nil
+	}
+	return nil, errors.New("members: cannot reach any machine")
+}
+
+// AutoSync refreshes the machine list from the members API every interval
+// until StopAutoSync is called. Healthy members are tried first, starting
+// with the leader. Clients without the admin role fall back to SyncCluster.
+//
+// The returned channel receives the client URLs of the members each time
+// they change. Only the latest list is kept if it is not read in time.
+// The channel is closed by StopAutoSync.
+func (c *Client) AutoSync(interval time.Duration) <-chan []string {
+	c.StopAutoSync()
+
+	changes := make(chan []string, 1)
+	stop := make(chan bool)
+	c.syncMutex.Lock()
+	c.stopSync = stop
+	c.syncMutex.Unlock()
+
+	go func() {
+		last := sortedCopy(c.GetCluster())
+		for {
+			select {
+			case <-stop:
+				close(changes)
+				return
+			case <-time.After(interval):
+			}
+
+			if !c.syncMembers() {
+				continue
+			}
+			if machines := sortedCopy(c.GetCluster()); !reflect.DeepEqual(machines, last) {
+				last = machines
+				logger.Debug("sync.members.changed ", machines)
+				select {
+				case <-changes:
+				default:
+				}
+				changes <- machines
+			}
+		}
+	}()
+	return changes
+}
+
+// StopAutoSync stops refreshing the machine list.
+func (c *Client) StopAutoSync() {
+	c.syncMutex.Lock()
+	defer c.syncMutex.Unlock()
+	if c.stopSync != nil {
+		close(c.stopSync)
+		c.stopSync = nil
+	}
+}
+
+// syncMembers updates the machine list from the members API, ordering the
+// healthy members first. It returns false if the list could not be read.
+func (c *Client) syncMembers() bool {
+	members, err := c.Members()
+	if err != nil {
+		logger.Debug("sync.members ", err)
+		return c.SyncCluster()
+	}
+	if len(members) == 0 {
+		return false
+	}
+
+	// Check the health of every member at once.
+	states := make([]string, len(members))
+	var wg sync.WaitGroup
+	for i, m := range members {
+		wg.Add(1)
+		go func(i int, m Member) {
+			defer wg.Done()
+			states[i] = c.memberState(m.ClientURL)
+		}(i, m)
+	}
+	wg.Wait()
+
+	var leader, healthy, unhealthy []string
+	for i, m := range members {
+		switch states[i] {
+		case "leader":
+			leader = append(leader, m.ClientURL)
+		case "":
+			unhealthy = append(unhealthy, m.ClientURL)
+		default:
+			healthy = append(healthy, m.ClientURL)
+		}
+	}
+	machines := append(append(leader, healthy...), unhealthy...)
+
+	c.clusterMutex.Lock()
+	c.cluster.Machines = machines
+	c.cluster.Leader = machines[0]
+	c.clusterMutex.Unlock()
+	logger.Debug("sync.members ", machines)
+	c.saveConfig()
+	return true
+}
+
+// memberState returns the raft state of a healthy member, or an empty
+// string if the member is unhealthy or cannot be reached.
+func (c *Client) memberState(machine string) string {
+	resp, err := c.httpClient.Get(c.createHttpPath(machine, "health") + "?timeout=" + healthTimeout.String())
+	if err != nil {
+		return ""
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return ""
+	}
+
+	var h struct {
+		State string `json:"state"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&h); err != nil || h.State == "" {
+		return ""
+	}
+	return h.State
+}
+
+func sortedCopy(s []string) []string {
+	c := append([]string(nil), s...)
+	sort.Strings(c)
+	return c
+}
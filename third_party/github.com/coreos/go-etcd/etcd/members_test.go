@@ -0,0 +1,38 @@
+package etcd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMembers(t *testing.T) {
+	c := NewClient(nil)
+	members, err := c.Members()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(members) == 0 || members[0].ClientURL == "" {
+		t.Fatalf("Members returned %v", members)
+	}
+}
+
+func TestAutoSync(t *testing.T) {
+	// Start with a stale list holding an unreachable machine.
+	c := NewClient([]string{"http://127.0.0.1:4999", "http://127.0.0.1:4001"})
+	changes := c.AutoSync(100 * time.Millisecond)
+	defer c.StopAutoSync()
+
+	select {
+	case machines := <-changes:
+		for _, m := range machines {
+			if m == "http://127.0.0.1:4999" {
+				t.Fatalf("AutoSync kept a stale machine: %v", machines)
+			}
+		}
+		if c.GetCluster()[0] != "http://127.0.0.1:4001" {
+			t.Fatalf("AutoSync did not put the leader first: %v", c.GetCluster())
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("No membership change")
+	}
+}
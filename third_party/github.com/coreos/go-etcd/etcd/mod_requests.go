@@ -0,0 +1,94 @@
+package etcd
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// sendModRequest sends a request to an etcd module and returns the response body.
+// Every machine serves the modules so, unlike key requests, the leader is
+// not followed; the next machine is only tried on a network error.
+// Closing stop cancels a request that is still waiting.
+func (c *Client) sendModRequest(method string, relativePath string,
+	values url.Values, stop chan bool) (string, error) {
+
+	c.clusterMutex.RLock()
+	machines := append([]string{c.cluster.Leader}, c.cluster.Machines...)
+	c.clusterMutex.RUnlock()
+	for _, machine := range c.health.available(machines) {
+		httpPath := machine + "/mod/" + version + "/" + relativePath
+		if len(values) > 0 {
+			httpPath += "?" + values.Encode()
+		}
+		logger.Debug("send.mod.request.to ", httpPath, " | method ", method)
+
+		req, _ := http.NewRequest(method, httpPath, nil)
+		resp, err := c.doCancelable(req, stop)
+		if err == errStopped {
+			return "", err
+		} else if err != nil {
+			c.health.failure(machine)
+			continue
+		}
+
+		b, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			continue
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			return "", &ModError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(b))}
+		}
+		return string(b), nil
+	}
+
+	return "", fmt.Errorf("Cannot reach servers for %s", relativePath)
+}
+
+// errStopped is returned when a module request is cancelled by its stop channel.
+var errStopped = errors.New("Request stopped")
+
+// doCancelable sends a request and cancels it if stop is closed first.
+func (c *Client) doCancelable(req *http.Request, stop chan bool) (*http.Response, error) {
+	if stop == nil {
+		return c.httpClient.Do(req)
+	}
+
+	type result struct {
+		resp *http.Response
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		resp, err := c.httpClient.Do(req)
+		done <- result{resp, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.resp, r.err
+	case <-stop:
+		if tr, ok := c.httpClient.Transport.(*http.Transport); ok {
+			tr.CancelRequest(req)
+		}
+		if r := <-done; r.err == nil {
+			r.resp.Body.Close()
+		}
+		return nil, errStopped
+	}
+}
+
+// ModError is returned when an etcd module rejects a request.
+type ModError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *ModError) Error() string {
+	return fmt.Sprintf("%d: %s", e.StatusCode, e.Message)
+}
@@ -110,7 +110,8 @@ func (c *Client) sendRequest(method string, relativePath string,
 	for {
 		trial++
 		logger.Debug("begin trail ", trial)
-		if trial > 2*len(c.cluster.Machines) {
+		machines := len(c.GetCluster())
+		if trial > 2*machines {
 			return nil, fmt.Errorf("Cannot reach servers after %v time", trial)
 		}
 
@@ -145,12 +146,23 @@ func (c *Client) sendRequest(method string, relativePath string,
 		}
 
 		// network error, change a machine!
+		start := time.Now()
 		if resp, err = c.httpClient.Do(req); err != nil {
-			c.switchLeader(trial % len(c.cluster.Machines))
+			c.health.failure(machineOf(req))
+			c.switchLeader(trial % machines)
 			time.Sleep(time.Millisecond * 200)
 			continue
 		}
 
+		if resp.StatusCode == http.StatusInternalServerError {
+			c.health.failure(machineOf(req))
+		} else if req.URL.Query().Get("wait") == "true" {
+			// The latency of a long poll says nothing about the machine.
+			c.health.success(machineOf(req), 0)
+		} else {
+			c.health.success(machineOf(req), time.Since(start))
+		}
+
 		if resp != nil {
 			logger.Debug("recv.response.from ", httpPath)
 
@@ -184,6 +196,8 @@ func (c *Client) sendRequest(method string, relativePath string,
 // If status code is OK, read the http body and return it as byte array
 // If status code is TemporaryRedirect, update leader.
 // If status code is InternalServerError, sleep for 200ms.
+// If status code is RequestTimeout, a watch reached the maximum duration
+// of the server and the caller watches again.
 func (c *Client) handleResp(resp *http.Response) (bool, []byte) {
 	defer resp.Body.Close()
 
@@ -205,7 +219,8 @@ func (c *Client) handleResp(resp *http.Response) (bool, []byte) {
 
 	} else if code == http.StatusOK ||
 		code == http.StatusCreated ||
-		code == http.StatusBadRequest {
+		code == http.StatusBadRequest ||
+		code == http.StatusRequestTimeout {
 		b, err := ioutil.ReadAll(resp.Body)
 
 		if err != nil {
@@ -220,11 +235,19 @@ func (c *Client) handleResp(resp *http.Response) (bool, []byte) {
 }
 
 func (c *Client) getHttpPath(random bool, s ...string) string {
+	c.clusterMutex.RLock()
+	defer c.clusterMutex.RUnlock()
+
 	var machine string
 	if random {
-		machine = c.cluster.Machines[rand.Intn(len(c.cluster.Machines))]
+		machines := c.health.available(c.cluster.Machines)
+		machine = machines[rand.Intn(len(machines))]
 	} else {
 		machine = c.cluster.Leader
+		if c.health.blacklisted(machine) {
+			// Skip a failing leader until a redirect or a probe names a new one.
+			machine = c.health.available(c.cluster.Machines)[0]
+		}
 	}
 
 	fullPath := machine + "/" + version
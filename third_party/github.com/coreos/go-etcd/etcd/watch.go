@@ -2,6 +2,7 @@ package etcd
 
 import (
 	"errors"
+	"net/http"
 )
 
 // Errors introduced by the Watch command.
@@ -100,14 +101,28 @@ func (c *Client) watchOnce(key string, waitIndex uint64, recursive bool, stop ch
 			options["recursive"] = true
 		}
 
-		resp, err := c.get(key, options)
-
-		if err != nil {
-			errChan <- err
+		for {
+			resp, err := c.get(key, options)
+
+			if err != nil {
+				errChan <- err
+				return
+			}
+
+			// The server ended the watch after its maximum watch
+			// duration; nothing changed, so watch again.
+			if resp.StatusCode == http.StatusRequestTimeout {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				continue
+			}
+
+			respChan <- resp
 			return
 		}
-
-		respChan <- resp
 	}()
 
 	select {
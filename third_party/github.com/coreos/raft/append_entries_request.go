@@ -41,6 +41,7 @@ func (req *AppendEntriesRequest) Encode(w io.Writer) (int, error) {
 			Term:        proto.Uint64(entry.Term),
 			CommandName: proto.String(entry.CommandName),
 			Command:     entry.Command,
+			Checksum:    proto.Uint32(entry.Checksum),
 		}
 	}
 
@@ -91,6 +92,7 @@ func (req *AppendEntriesRequest) Decode(r io.Reader) (int, error) {
 			Term:        entry.GetTerm(),
 			CommandName: entry.GetCommandName(),
 			Command:     entry.Command,
+			Checksum:    entry.GetChecksum(),
 		}
 	}
 
@@ -47,6 +47,12 @@ type CommandEncoder interface {
 // Instantiation
 //--------------------------------------
 
+// Creates a new instance of a registered command by name and decodes the
+// command data into it.
+func NewCommand(name string, data []byte) (Command, error) {
+	return newCommand(name, data)
+}
+
 // Creates a new instance of a command by name.
 func newCommand(name string, data []byte) (Command, error) {
 	// Find the registered command.
@@ -0,0 +1,84 @@
+package raft
+
+//------------------------------------------------------------------------------
+//
+// Constants
+//
+//------------------------------------------------------------------------------
+
+const (
+	StateChangeEventType      = "stateChange"
+	LeaderChangeEventType     = "leaderChange"
+	TermChangeEventType       = "termChange"
+	AddPeerEventType          = "addPeer"
+	RemovePeerEventType       = "removePeer"
+	SnapshotEventType         = "snapshot"
+	SnapshotRecoveryEventType = "snapshotRecovery"
+	LogSyncEventType          = "logSync"
+	CorruptionEventType       = "corruption"
+)
+
+//------------------------------------------------------------------------------
+//
+// Typedefs
+//
+//------------------------------------------------------------------------------
+
+// An Event is a change to the state of a server. Value and PrevValue hold
+// the new and old state, leader or term, and the last index of the new and
+// previous snapshot for snapshot events. Peer events only set Value to the
+// name of the peer, log sync events to the time.Duration it took to write
+// and sync appended entries, and corruption events to the *ChecksumError
+// of the entry that cannot be applied.
+type Event struct {
+	Type      string
+	Value     interface{}
+	PrevValue interface{}
+}
+
+// An EventListener is called with every event of a server. It runs on the
+// goroutine that made the change, sometimes the event loop, so it must not
+// block or call back into the server.
+type EventListener func(e Event)
+
+//------------------------------------------------------------------------------
+//
+// Methods
+//
+//------------------------------------------------------------------------------
+
+// Registers a function to call on every event of the server.
+func (s *server) AddEventListener(l EventListener) {
+	s.listenersMutex.Lock()
+	defer s.listenersMutex.Unlock()
+	s.listeners = append(s.listeners, l)
+}
+
+// Calls the event listeners with an event.
+func (s *server) dispatch(typ string, value interface{}, prevValue interface{}) {
+	s.listenersMutex.RLock()
+	listeners := s.listeners
+	s.listenersMutex.RUnlock()
+
+	e := Event{Type: typ, Value: value, PrevValue: prevValue}
+	for _, l := range listeners {
+		l(e)
+	}
+}
+
+// Dispatches the changes of state, leader and term since they were saved.
+func (s *server) dispatchChanges(prevState string, prevLeader string, prevTerm uint64) {
+	s.mutex.RLock()
+	state, leader, term := s.state, s.leader, s.currentTerm
+	s.mutex.RUnlock()
+
+	if term != prevTerm {
+		s.dispatch(TermChangeEventType, term, prevTerm)
+	}
+	if state != prevState {
+		s.dispatch(StateChangeEventType, state, prevState)
+	}
+	if leader != prevLeader {
+		s.dispatch(LeaderChangeEventType, leader, prevLeader)
+	}
+}
@@ -0,0 +1,91 @@
+package raft
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Ensure that changes of term, state, leader and peers are dispatched to listeners.
+func TestServerEvents(t *testing.T) {
+	s := newTestServer("1", &testTransporter{})
+	var events []Event
+	s.AddEventListener(func(e Event) { events = append(events, e) })
+
+	s.(*server).setState(Follower)
+	s.(*server).setCurrentTerm(2, "2", true)
+	s.(*server).setCurrentTerm(2, "2", true)
+	s.AddPeer("3", "")
+	s.AddPeer("3", "")
+	s.RemovePeer("3")
+
+	expected := []Event{
+		{StateChangeEventType, Follower, Stopped},
+		{TermChangeEventType, uint64(2), uint64(0)},
+		{LeaderChangeEventType, "2", ""},
+		{AddPeerEventType, "3", nil},
+		{RemovePeerEventType, "3", nil},
+	}
+	if !reflect.DeepEqual(events, expected) {
+		t.Fatalf("Unexpected events: %v", events)
+	}
+}
+
+// Ensure that appending entries dispatches how long they took to sync, and
+// that heartbeats without entries do not.
+func TestServerLogSyncEvents(t *testing.T) {
+	s := newTestServer("1", &testTransporter{})
+	syncs := make(chan Event, 10)
+	s.AddEventListener(func(e Event) {
+		if e.Type == LogSyncEventType {
+			syncs <- e
+		}
+	})
+
+	s.SetHeartbeatTimeout(time.Second * 10)
+	s.Start()
+	defer s.Stop()
+
+	e, _ := newLogEntry(nil, 1, 1, &testCommand1{Val: "foo", I: 10})
+	s.AppendEntries(newAppendEntriesRequest(1, 0, 0, 0, "ldr", []*LogEntry{e}))
+	s.AppendEntries(newAppendEntriesRequest(1, 1, 1, 1, "ldr", nil))
+
+	if len(syncs) != 1 {
+		t.Fatalf("Unexpected number of sync events: %v", len(syncs))
+	}
+	if _, ok := (<-syncs).Value.(time.Duration); !ok {
+		t.Fatal("Sync event without a duration")
+	}
+}
+
+// Ensure that an entry failing its checksum is reported once, instead of
+// being applied.
+func TestServerCorruptionEvent(t *testing.T) {
+	s := newTestServer("1", &testTransporter{})
+	corruptions := make(chan Event, 10)
+	s.AddEventListener(func(e Event) {
+		if e.Type == CorruptionEventType {
+			corruptions <- e
+		}
+	})
+
+	s.SetHeartbeatTimeout(time.Second * 10)
+	s.Start()
+	defer s.Stop()
+
+	e, _ := newLogEntry(nil, 1, 1, &testCommand1{Val: "foo", I: 10})
+	s.AppendEntries(newAppendEntriesRequest(1, 0, 0, 0, "ldr", []*LogEntry{e}))
+	s.(*server).log.entries[0].Command = []byte(`{"val":"bar","i":10}`)
+	s.AppendEntries(newAppendEntriesRequest(1, 1, 1, 1, "ldr", nil))
+	s.AppendEntries(newAppendEntriesRequest(1, 1, 1, 1, "ldr", nil))
+
+	if len(corruptions) != 1 {
+		t.Fatalf("Unexpected number of corruption events: %v", len(corruptions))
+	}
+	if err, ok := (<-corruptions).Value.(*ChecksumError); !ok || err.Index != 1 {
+		t.Fatalf("Unexpected corruption event value: %v", err)
+	}
+	if s.CommitIndex() != 0 {
+		t.Fatalf("Corrupted entry was committed: %v", s.CommitIndex())
+	}
+}
@@ -19,7 +19,7 @@ import (
 
 // A log is a collection of log entries that are persisted to durable storage.
 type Log struct {
-	ApplyFunc   func(Command) (interface{}, error)
+	ApplyFunc   func(*LogEntry, Command) (interface{}, error)
 	file        *os.File
 	path        string
 	entries     []*LogEntry
@@ -30,6 +30,7 @@ type Log struct {
 	startTerm   uint64
 	pBuffer     *proto.Buffer
 	pLogEntry   *protobuf.ProtoLogEntry
+	replayStats ReplayStats
 }
 
 // The results of the applying a log entry.
@@ -70,6 +71,13 @@ func (l *Log) CommitIndex() uint64 {
 	return l.commitIndex
 }
 
+// The statistics of the replay of the log when it was opened.
+func (l *Log) ReplayStats() ReplayStats {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	return l.replayStats
+}
+
 // The current index in the log.
 func (l *Log) currentIndex() uint64 {
 	l.mutex.RLock()
@@ -142,8 +150,6 @@ func (l *Log) currentTerm() uint64 {
 // continue to append entries to the end of the log.
 func (l *Log) open(path string) error {
 	// Read all the entries from the log if one exists.
-	var readBytes int64
-
 	var err error
 	debugln("log.open.open ", path)
 	// open log file
@@ -164,36 +170,8 @@ func (l *Log) open(path string) error {
 	debugln("log.open.exist ", path)
 
 	// Read the file and decode entries.
-	for {
-		// Instantiate log entry and decode into it.
-		entry, _ := newLogEntry(l, 0, 0, nil)
-		entry.Position, _ = l.file.Seek(0, os.SEEK_CUR)
-
-		n, err := entry.decode(l.file)
-		if err != nil {
-			if err == io.EOF {
-				debugln("open.log.append: finish ")
-			} else {
-				if err = os.Truncate(path, readBytes); err != nil {
-					return fmt.Errorf("raft.Log: Unable to recover: %v", err)
-				}
-			}
-			break
-		}
-		if entry.Index > l.startIndex {
-			// Append entry.
-			l.entries = append(l.entries, entry)
-			if entry.Index <= l.commitIndex {
-				command, err := newCommand(entry.CommandName, entry.Command)
-				if err != nil {
-					continue
-				}
-				l.ApplyFunc(command)
-			}
-			debugln("open.log.append log index ", entry.Index)
-		}
-
-		readBytes += int64(n)
+	if err := l.replay(); err != nil {
+		return err
 	}
 	l.results = make([]*logResult, len(l.entries))
 
@@ -396,6 +374,12 @@ func (l *Log) setCommitIndex(index uint64) error {
 		entryIndex := i - 1 - l.startIndex
 		entry := l.entries[entryIndex]
 
+		// Refuse to apply an entry that was corrupted after it was appended.
+		if err := entry.verify(); err != nil {
+			warnln(err)
+			return err
+		}
+
 		// Update commit index.
 		l.commitIndex = entry.Index
 
@@ -406,7 +390,7 @@ func (l *Log) setCommitIndex(index uint64) error {
 		}
 
 		// Apply the changes to the state machine and store the error code.
-		returnValue, err := l.ApplyFunc(command)
+		returnValue, err := l.ApplyFunc(entry, command)
 		debugln("setCommitIndex.set.result index: ", entryIndex)
 		l.results[entryIndex] = &logResult{returnValue: returnValue, err: err}
 	}
@@ -501,6 +485,14 @@ func (l *Log) appendEntries(entries []*LogEntry) error {
 	return nil
 }
 
+// Flushes the appended entries to stable storage.
+func (l *Log) sync() error {
+	if l.file == nil {
+		return errors.New("raft.Log: Log is not open")
+	}
+	return l.file.Sync()
+}
+
 // Writes a single log entry to the end of the log. This function does not
 // obtain a lock and should only be used internally. Use AppendEntries() and
 // AppendEntry() to use it externally.
@@ -3,9 +3,11 @@ package raft
 import (
 	"bytes"
 	"code.google.com/p/goprotobuf/proto"
+	"encoding/binary"
 	"encoding/json"
 	"fmt"
 	"github.com/coreos/raft/protobuf"
+	"hash/crc32"
 	"io"
 )
 
@@ -16,7 +18,8 @@ type LogEntry struct {
 	Term        uint64
 	CommandName string
 	Command     []byte
-	Position    int64 // position in the log file
+	Checksum    uint32 // zero for entries written without a checksum
+	Position    int64  // position in the log file
 	commit      chan bool
 }
 
@@ -43,10 +46,49 @@ func newLogEntry(log *Log, index uint64, term uint64, command Command) (*LogEntr
 		Command:     buf.Bytes(),
 		commit:      make(chan bool, 5),
 	}
+	e.Checksum = e.checksum()
 
 	return e, nil
 }
 
+// Computes the checksum of the entry's index, term and command.
+func (e *LogEntry) checksum() uint32 {
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[0:8], e.Index)
+	binary.BigEndian.PutUint64(b[8:16], e.Term)
+
+	h := crc32.NewIEEE()
+	h.Write(b[:])
+	h.Write([]byte(e.CommandName))
+	h.Write(e.Command)
+	return h.Sum32()
+}
+
+// ChecksumError is returned for an entry whose content no longer matches
+// the checksum computed when it was appended.
+type ChecksumError struct {
+	Index    uint64
+	Term     uint64
+	Sum      uint32
+	Expected uint32
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("raft.Log: Checksum mismatch for entry %v in term %v (%08x != %08x)", e.Index, e.Term, e.Sum, e.Expected)
+}
+
+// Verifies the entry against the checksum computed when it was appended.
+// Entries from logs and peers without checksums are not checked.
+func (e *LogEntry) verify() error {
+	if e.Checksum == 0 {
+		return nil
+	}
+	if sum := e.checksum(); sum != e.Checksum {
+		return &ChecksumError{Index: e.Index, Term: e.Term, Sum: sum, Expected: e.Checksum}
+	}
+	return nil
+}
+
 // Encodes the log entry to a buffer. Returns the number of bytes
 // written and any error that may have occurred.
 func (e *LogEntry) encode(w io.Writer) (int, error) {
@@ -55,6 +97,7 @@ func (e *LogEntry) encode(w io.Writer) (int, error) {
 	e.log.pLogEntry.Term = proto.Uint64(e.Term)
 	e.log.pLogEntry.CommandName = proto.String(e.CommandName)
 	e.log.pLogEntry.Command = e.Command
+	e.log.pLogEntry.Checksum = proto.Uint32(e.Checksum)
 
 	err := e.log.pBuffer.Marshal(e.log.pLogEntry)
 	if err != nil {
@@ -85,15 +128,26 @@ func (e *LogEntry) decode(r io.Reader) (int, error) {
 		return -1, err
 	}
 
-	pb := &protobuf.ProtoLogEntry{}
-	if err = proto.Unmarshal(data, pb); err != nil {
+	if err = e.unmarshal(data); err != nil {
 		return -1, err
 	}
 
+	return length, nil
+}
+
+// Decodes the log entry from the protobuf encoded data of a single entry and
+// verifies its checksum.
+func (e *LogEntry) unmarshal(data []byte) error {
+	pb := &protobuf.ProtoLogEntry{}
+	if err := proto.Unmarshal(data, pb); err != nil {
+		return err
+	}
+
 	e.Term = pb.GetTerm()
 	e.Index = pb.GetIndex()
 	e.CommandName = pb.GetCommandName()
 	e.Command = pb.Command
+	e.Checksum = pb.GetChecksum()
 
-	return length, nil
+	return e.verify()
 }
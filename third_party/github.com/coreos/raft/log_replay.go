@@ -0,0 +1,185 @@
+package raft
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"time"
+)
+
+// The number of goroutines decoding and verifying log entries while the log
+// is replayed. The entries are still applied one at a time, in order.
+var ReplayWorkers = runtime.NumCPU()
+
+// The number of entries read ahead of the one being applied.
+const replayQueueSize = 1024
+
+// The length header written in front of every encoded entry.
+const entryHeaderSize = 9
+
+// Statistics of the replay of the log when it was opened.
+type ReplayStats struct {
+	Entries          uint64        `json:"entries"`
+	Applied          uint64        `json:"applied"`
+	Bytes            int64         `json:"bytes"`
+	Workers          int           `json:"workers"`
+	Duration         time.Duration `json:"duration"`
+	EntriesPerSecond float64       `json:"entriesPerSecond"`
+	BytesPerSecond   float64       `json:"bytesPerSecond"`
+}
+
+// An entry read from the log file, handed to the decoding workers.
+type replayItem struct {
+	entry   *LogEntry
+	data    []byte
+	size    int64
+	command Command
+	err     error
+	torn    bool
+	done    chan bool
+}
+
+// Decodes the entry and, when it is going to be applied, its command.
+func (l *Log) decodeReplayItem(item *replayItem) {
+	defer close(item.done)
+	if item.err = item.entry.unmarshal(item.data); item.err != nil {
+		return
+	}
+	item.data = nil
+
+	e := item.entry
+	if e.Index > l.startIndex && e.Index <= l.commitIndex {
+		item.command, item.err = newCommand(e.CommandName, e.Command)
+		if item.err != nil {
+			// Commands that cannot be decoded are kept but not applied.
+			item.command, item.err = nil, nil
+		}
+	}
+}
+
+// Reads the entries of the log file in order. Reading stops at the end of
+// the file or at the first entry that cannot be read, which is sent with
+// its error.
+func (l *Log) readReplayItems(r io.Reader, jobs chan<- *replayItem, items chan<- *replayItem, stop <-chan bool) {
+	defer close(jobs)
+	defer close(items)
+
+	br := bufio.NewReader(r)
+	var position int64
+	for {
+		entry, _ := newLogEntry(l, 0, 0, nil)
+		entry.Position = position
+		item := &replayItem{entry: entry, done: make(chan bool)}
+
+		var length int
+		header := make([]byte, entryHeaderSize)
+		if _, item.err = io.ReadFull(br, header); item.err == nil {
+			if _, err := fmt.Sscanf(string(header), "%8x\n", &length); err != nil {
+				item.err = err
+			} else {
+				item.data = make([]byte, length)
+				_, item.err = io.ReadFull(br, item.data)
+			}
+		}
+		if item.err == io.ErrUnexpectedEOF {
+			// The last entry was only partly written.
+			item.torn = true
+		}
+
+		if item.err != nil {
+			close(item.done)
+		} else {
+			item.size = int64(entryHeaderSize + length)
+		}
+		select {
+		case items <- item:
+		case <-stop:
+			return
+		}
+		if item.err != nil {
+			return
+		}
+
+		position += item.size
+		jobs <- item
+	}
+}
+
+// Reads the entries of the log file and applies the committed ones. The
+// entries are decoded and verified by several workers while the entries
+// before them are being applied. An entry cut short at the end of the file,
+// left by a write that never finished, is truncated. Any other entry that
+// cannot be read, including one failing its checksum, stops the replay and
+// leaves the file untouched: it may be followed by committed entries.
+func (l *Log) replay() error {
+	start := time.Now()
+
+	workers := ReplayWorkers
+	if workers < 1 {
+		workers = 1
+	}
+
+	jobs := make(chan *replayItem, replayQueueSize)
+	items := make(chan *replayItem, replayQueueSize)
+	stop := make(chan bool)
+	defer close(stop)
+
+	go l.readReplayItems(l.file, jobs, items, stop)
+	for i := 0; i < workers; i++ {
+		go func() {
+			for item := range jobs {
+				l.decodeReplayItem(item)
+			}
+		}()
+	}
+
+	stats := ReplayStats{Workers: workers}
+	var end int64
+	for item := range items {
+		<-item.done
+		if item.err == io.EOF {
+			debugln("open.log.append: finish ")
+			break
+		} else if item.torn {
+			warnln("raft.Log: Truncating incomplete entry at ", item.entry.Position)
+			if err := l.file.Truncate(item.entry.Position); err != nil {
+				return fmt.Errorf("raft.Log: Unable to recover: %v", err)
+			}
+			break
+		} else if item.err != nil {
+			return fmt.Errorf("raft.Log: Unable to read entry at %v: %v", item.entry.Position, item.err)
+		}
+
+		entry := item.entry
+		if entry.Index > l.startIndex {
+			// Append entry.
+			l.entries = append(l.entries, entry)
+			if item.command != nil {
+				l.ApplyFunc(entry, item.command)
+				stats.Applied++
+			}
+			debugln("open.log.append log index ", entry.Index)
+		}
+
+		stats.Entries++
+		end += item.size
+	}
+
+	// The reader reads ahead, so move back to the end of the last entry
+	// where the next one is appended.
+	if _, err := l.file.Seek(end, os.SEEK_SET); err != nil {
+		return fmt.Errorf("raft.Log: Unable to recover: %v", err)
+	}
+
+	stats.Bytes = end
+	stats.Duration = time.Now().Sub(start)
+	if seconds := stats.Duration.Seconds(); seconds > 0 {
+		stats.EntriesPerSecond = float64(stats.Entries) / seconds
+		stats.BytesPerSecond = float64(stats.Bytes) / seconds
+	}
+	l.replayStats = stats
+
+	return nil
+}
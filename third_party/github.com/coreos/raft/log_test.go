@@ -21,7 +21,7 @@ import (
 func TestLogNewLog(t *testing.T) {
 	path := getLogPath()
 	log := newLog()
-	log.ApplyFunc = func(c Command) (interface{}, error) {
+	log.ApplyFunc = func(e *LogEntry, c Command) (interface{}, error) {
 		return nil, nil
 	}
 	if err := log.open(path); err != nil {
@@ -60,6 +60,67 @@ func TestLogNewLog(t *testing.T) {
 	}
 }
 
+// Ensure that a corrupted entry is not applied.
+func TestLogChecksumMismatch(t *testing.T) {
+	path := getLogPath()
+	log := newLog()
+	applied := 0
+	log.ApplyFunc = func(e *LogEntry, c Command) (interface{}, error) {
+		applied++
+		return nil, nil
+	}
+	if err := log.open(path); err != nil {
+		t.Fatalf("Unable to open log: %v", err)
+	}
+	defer log.close()
+	defer os.Remove(path)
+
+	e, _ := newLogEntry(log, 1, 1, &testCommand1{Val: "foo", I: 20})
+	if err := log.appendEntry(e); err != nil {
+		t.Fatalf("Unable to append: %v", err)
+	}
+	e, _ = newLogEntry(log, 2, 1, &testCommand1{Val: "bar", I: 0})
+	if err := log.appendEntry(e); err != nil {
+		t.Fatalf("Unable to append: %v", err)
+	}
+	e.Command = []byte(`{"val":"baz","i":0}`)
+
+	if err := log.setCommitIndex(2); err == nil {
+		t.Fatal("Expected a checksum mismatch")
+	}
+	if index, _ := log.commitInfo(); index != 1 || applied != 1 {
+		t.Fatalf("Invalid commit info [IDX=%v, APPLIED=%v]", index, applied)
+	}
+}
+
+// Ensure that a log with a corrupted entry is not opened and not truncated.
+func TestLogOpenChecksumMismatch(t *testing.T) {
+	tmpLog := newLog()
+	e0, _ := newLogEntry(tmpLog, 1, 1, &testCommand1{Val: "foo", I: 20})
+	e1, _ := newLogEntry(tmpLog, 2, 1, &testCommand2{X: 100})
+	f, _ := ioutil.TempFile("", "raft-log-")
+	defer os.Remove(f.Name())
+
+	e0.Command = []byte(`{"val":"baz","i":20}`)
+	e0.encode(f)
+	e1.encode(f)
+	fi, _ := f.Stat()
+	f.Close()
+
+	log := newLog()
+	log.ApplyFunc = func(e *LogEntry, c Command) (interface{}, error) {
+		return nil, nil
+	}
+	err := log.open(f.Name())
+	if err == nil {
+		log.close()
+		t.Fatal("Expected a checksum mismatch")
+	}
+	if after, _ := os.Stat(f.Name()); after.Size() != fi.Size() {
+		t.Fatalf("Log truncated from %d to %d bytes", fi.Size(), after.Size())
+	}
+}
+
 // Ensure that we can decode and encode to an existing log.
 func TestLogExistingLog(t *testing.T) {
 	tmpLog := newLog()
@@ -125,7 +186,7 @@ func TestLogRecovery(t *testing.T) {
 	f.Close()
 
 	log := newLog()
-	log.ApplyFunc = func(c Command) (interface{}, error) {
+	log.ApplyFunc = func(e *LogEntry, c Command) (interface{}, error) {
 		return nil, nil
 	}
 	if err := log.open(f.Name()); err != nil {
@@ -154,6 +215,55 @@ func TestLogRecovery(t *testing.T) {
 	}
 }
 
+// Ensure that the committed entries are applied in order while several
+// workers decode them.
+func TestLogReplayInOrder(t *testing.T) {
+	tmpLog := newLog()
+	f, _ := ioutil.TempFile("", "raft-log-")
+	for i := 1; i <= 500; i++ {
+		e, _ := newLogEntry(tmpLog, uint64(i), 1, &testCommand2{X: i})
+		e.encode(f)
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+
+	workers := ReplayWorkers
+	ReplayWorkers = 4
+	defer func() { ReplayWorkers = workers }()
+
+	log := newLog()
+	log.commitIndex = 400
+	var applied []int
+	log.ApplyFunc = func(e *LogEntry, c Command) (interface{}, error) {
+		applied = append(applied, c.(*testCommand2).X)
+		return nil, nil
+	}
+	if err := log.open(f.Name()); err != nil {
+		t.Fatalf("Unable to open log: %v", err)
+	}
+	defer log.close()
+
+	if len(log.entries) != 500 {
+		t.Fatalf("Expected 500 entries, got %d", len(log.entries))
+	}
+	if len(applied) != 400 {
+		t.Fatalf("Expected 400 applied entries, got %d", len(applied))
+	}
+	for i, x := range applied {
+		if x != i+1 {
+			t.Fatalf("Entry %d applied out of order: %d", i+1, x)
+		}
+	}
+
+	stats := log.ReplayStats()
+	if stats.Entries != 500 || stats.Applied != 400 || stats.Workers != 4 {
+		t.Fatalf("Unexpected replay stats: %+v", stats)
+	}
+	if fi, _ := os.Stat(f.Name()); stats.Bytes != fi.Size() {
+		t.Fatalf("Expected %d bytes replayed, got %d", fi.Size(), stats.Bytes)
+	}
+}
+
 //--------------------------------------
 // Append
 //--------------------------------------
@@ -16,6 +16,7 @@ type Peer struct {
 	server           *server
 	Name             string `json:"name"`
 	ConnectionString string `json:"connectionString"`
+	Observer         bool   `json:"observer,omitempty"`
 	prevLogIndex     uint64
 	mutex            sync.RWMutex
 	stopChan         chan bool
@@ -60,6 +61,12 @@ func (p *Peer) getPrevLogIndex() uint64 {
 	return p.prevLogIndex
 }
 
+// Retrieves the index of the last log entry known to be replicated on the
+// peer. It is only maintained while the local server is the leader.
+func (p *Peer) PrevLogIndex() uint64 {
+	return p.getPrevLogIndex()
+}
+
 // Sets the previous log index.
 func (p *Peer) setPrevLogIndex(value uint64) {
 	p.mutex.Lock()
@@ -112,6 +119,7 @@ func (p *Peer) clone() *Peer {
 	return &Peer{
 		Name:             p.Name,
 		ConnectionString: p.ConnectionString,
+		Observer:         p.Observer,
 		prevLogIndex:     p.prevLogIndex,
 	}
 }
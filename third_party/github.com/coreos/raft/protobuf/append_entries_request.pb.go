@@ -74,6 +74,7 @@ type ProtoAppendEntriesRequest_ProtoLogEntry struct {
 	Term             *uint64 `protobuf:"varint,2,req" json:"Term,omitempty"`
 	CommandName      *string `protobuf:"bytes,3,req" json:"CommandName,omitempty"`
 	Command          []byte  `protobuf:"bytes,4,opt" json:"Command,omitempty"`
+	Checksum         *uint32 `protobuf:"varint,5,opt" json:"Checksum,omitempty"`
 	XXX_unrecognized []byte  `json:"-"`
 }
 
@@ -111,5 +112,12 @@ func (m *ProtoAppendEntriesRequest_ProtoLogEntry) GetCommand() []byte {
 	return nil
 }
 
+func (m *ProtoAppendEntriesRequest_ProtoLogEntry) GetChecksum() uint32 {
+	if m != nil && m.Checksum != nil {
+		return *m.Checksum
+	}
+	return 0
+}
+
 func init() {
 }
@@ -18,6 +18,7 @@ type ProtoLogEntry struct {
 	Term             *uint64 `protobuf:"varint,2,req" json:"Term,omitempty"`
 	CommandName      *string `protobuf:"bytes,3,req" json:"CommandName,omitempty"`
 	Command          []byte  `protobuf:"bytes,4,opt" json:"Command,omitempty"`
+	Checksum         *uint32 `protobuf:"varint,5,opt" json:"Checksum,omitempty"`
 	XXX_unrecognized []byte  `json:"-"`
 }
 
@@ -53,5 +54,12 @@ func (m *ProtoLogEntry) GetCommand() []byte {
 	return nil
 }
 
+func (m *ProtoLogEntry) GetChecksum() uint32 {
+	if m != nil && m.Checksum != nil {
+		return *m.Checksum
+	}
+	return 0
+}
+
 func init() {
 }
@@ -39,6 +39,10 @@ const (
 
 var stopValue interface{}
 
+var stepDownValue interface{}
+
+var campaignValue interface{}
+
 //------------------------------------------------------------------------------
 //
 // Errors
@@ -48,6 +52,7 @@ var stopValue interface{}
 var NotLeaderError = errors.New("raft.Server: Not current leader")
 var DuplicatePeerError = errors.New("raft.Server: Duplicate peer")
 var CommandTimeoutError = errors.New("raft: Command timeout")
+var NotPromotableError = errors.New("raft.Server: Not promotable")
 
 //------------------------------------------------------------------------------
 //
@@ -74,9 +79,13 @@ type Server interface {
 	IsLogEmpty() bool
 	LogEntries() []*LogEntry
 	LastCommandName() string
+	ReplayStats() ReplayStats
 	GetState() string
 	ElectionTimeout() time.Duration
 	SetElectionTimeout(duration time.Duration)
+	ElectionWindow() time.Duration
+	SetElectionWindow(duration time.Duration)
+	ElectionTimeoutStats() ElectionTimeoutStats
 	HeartbeatTimeout() time.Duration
 	SetHeartbeatTimeout(duration time.Duration)
 	Transporter() Transporter
@@ -92,8 +101,15 @@ type Server interface {
 	Stop()
 	Running() bool
 	Do(command Command) (interface{}, error)
+	StepDown() error
+	Campaign() error
+	SetPromotable(promotable bool)
+	SetLogSync(sync bool)
+	SetObserver(name string, observer bool) error
+	IsObserver(name string) bool
 	TakeSnapshot() error
 	LoadSnapshot() error
+	AddEventListener(l EventListener)
 }
 
 type server struct {
@@ -113,6 +129,8 @@ type server struct {
 
 	c                chan *event
 	electionTimeout  time.Duration
+	electionWindow   time.Duration
+	electionStats    ElectionTimeoutStats
 	heartbeatTimeout time.Duration
 
 	currentSnapshot         *Snapshot
@@ -121,6 +139,21 @@ type server struct {
 	maxLogEntriesPerRequest uint64
 
 	connectionString string
+
+	// Set to false to stop the server from becoming a candidate.
+	promotionAllowed bool
+
+	// Set when the server replicates the log without voting.
+	observer bool
+
+	// Set to false to leave appended entries to the page cache.
+	logSync bool
+
+	// Set once a corrupted entry has been reported.
+	corrupted bool
+
+	listeners      []EventListener
+	listenersMutex sync.RWMutex
 }
 
 // An event to be processed by the server's event loop.
@@ -159,10 +192,15 @@ func NewServer(name string, path string, transporter Transporter, stateMachine S
 		heartbeatTimeout:        DefaultHeartbeatTimeout,
 		maxLogEntriesPerRequest: MaxLogEntriesPerRequest,
 		connectionString:        connectionString,
+		promotionAllowed:        true,
+		logSync:                 true,
 	}
 
 	// Setup apply function.
-	s.log.ApplyFunc = func(c Command) (interface{}, error) {
+	s.log.ApplyFunc = func(e *LogEntry, c Command) (interface{}, error) {
+		if p, ok := s.stateMachine.(PositionedStateMachine); ok {
+			p.SetPosition(e.Term, e.Index)
+		}
 		result, err := c.Apply(s)
 		return result, err
 	}
@@ -245,11 +283,14 @@ func (s *server) State() string {
 // Sets the state of the server.
 func (s *server) setState(state string) {
 	s.mutex.Lock()
-	defer s.mutex.Unlock()
+	prevState, prevLeader, prevTerm := s.state, s.leader, s.currentTerm
 	s.state = state
 	if state == Leader {
 		s.leader = s.Name()
 	}
+	s.mutex.Unlock()
+
+	s.dispatchChanges(prevState, prevLeader, prevTerm)
 }
 
 // Retrieves the current term of the server.
@@ -282,6 +323,11 @@ func (s *server) LastCommandName() string {
 	return s.log.lastCommandName()
 }
 
+// The statistics of the replay of the log when the server was started.
+func (s *server) ReplayStats() ReplayStats {
+	return s.log.ReplayStats()
+}
+
 // Get the state of the server for debugging
 func (s *server) GetState() string {
 	s.mutex.RLock()
@@ -291,7 +337,42 @@ func (s *server) GetState() string {
 
 // Check if the server is promotable
 func (s *server) promotable() bool {
-	return s.log.currentIndex() > 0
+	s.mutex.RLock()
+	allowed := s.promotionAllowed && !s.observer
+	s.mutex.RUnlock()
+	return allowed && s.log.currentIndex() > 0
+}
+
+// Allows or prevents the server from promoting itself to a candidate when
+// the election timeout elapses. The server can still vote and follow.
+func (s *server) SetPromotable(promotable bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.promotionAllowed = promotable
+}
+
+// Sets whether appended entries are synced to disk before they are
+// acknowledged.
+func (s *server) SetLogSync(sync bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.logSync = sync
+}
+
+// Syncs the entries appended since start, unless syncing is off, and
+// reports how long they took to reach the disk.
+func (s *server) syncLog(start time.Time) error {
+	s.mutex.RLock()
+	sync := s.logSync
+	s.mutex.RUnlock()
+
+	if sync {
+		if err := s.log.sync(); err != nil {
+			return err
+		}
+	}
+	s.dispatch(LogSyncEventType, time.Now().Sub(start), nil)
+	return nil
 }
 
 //--------------------------------------
@@ -305,9 +386,62 @@ func (s *server) MemberCount() int {
 	return len(s.peers) + 1
 }
 
-// Retrieves the number of servers required to make a quorum.
+// Retrieves the number of servers required to make a quorum. Observers
+// are not counted.
 func (s *server) QuorumSize() int {
-	return (s.MemberCount() / 2) + 1
+	return (s.voterCount() / 2) + 1
+}
+
+// Retrieves the number of member servers that vote.
+func (s *server) voterCount() int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	count := 0
+	if !s.observer {
+		count++
+	}
+	for _, peer := range s.peers {
+		if !peer.Observer {
+			count++
+		}
+	}
+	return count
+}
+
+// Marks a member as an observer, which replicates the log but never votes
+// or counts toward the quorum.
+func (s *server) SetObserver(name string, observer bool) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if name == s.name {
+		s.observer = observer
+		return nil
+	}
+	peer := s.peers[name]
+	if peer == nil {
+		return fmt.Errorf("raft: Peer not found: %s", name)
+	}
+	peer.mutex.Lock()
+	peer.Observer = observer
+	peer.mutex.Unlock()
+	s.writeConf()
+	return nil
+}
+
+// Checks whether a member is an observer.
+func (s *server) IsObserver(name string) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if name == s.name {
+		return s.observer
+	}
+	if peer := s.peers[name]; peer != nil {
+		return peer.Observer
+	}
+	return false
 }
 
 //--------------------------------------
@@ -328,6 +462,54 @@ func (s *server) SetElectionTimeout(duration time.Duration) {
 	s.electionTimeout = duration
 }
 
+// Retrieves the width of the window the election timeout is randomized in.
+// Timeouts are chosen between the election timeout and the election timeout
+// plus the window, which defaults to the election timeout itself.
+func (s *server) ElectionWindow() time.Duration {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.electionWindowLocked()
+}
+
+// Sets the width of the election timeout window. A window that is not
+// positive resets it to the election timeout.
+func (s *server) SetElectionWindow(duration time.Duration) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.electionWindow = duration
+}
+
+func (s *server) electionWindowLocked() time.Duration {
+	if s.electionWindow <= 0 {
+		return s.electionTimeout
+	}
+	return s.electionWindow
+}
+
+// Retrieves the randomized election timeout chosen last.
+func (s *server) ElectionTimeoutStats() ElectionTimeoutStats {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.electionStats
+}
+
+// Starts the election timer with a random timeout from the window and records
+// it. The timeout is logged the first time one is chosen in a term.
+func (s *server) electionTimer() <-chan time.Time {
+	s.mutex.Lock()
+	min := s.electionTimeout
+	max := min + s.electionWindowLocked()
+	d := randomBetween(min, max)
+	first := s.electionStats.Term != s.currentTerm || s.electionStats.Current == 0
+	s.electionStats = ElectionTimeoutStats{Term: s.currentTerm, Min: min, Max: max, Current: d}
+	s.mutex.Unlock()
+
+	if first {
+		s.debugln("server.election.timeout: ", d, " term:", s.currentTerm, " window:", min, "-", max)
+	}
+	return time.After(d)
+}
+
 //--------------------------------------
 // Heartbeat timeout
 //--------------------------------------
@@ -418,6 +600,21 @@ func (s *server) Start() error {
 	return nil
 }
 
+// Makes the server step down to a follower if it is the leader. One of the
+// other servers becomes the leader once its election timeout elapses.
+func (s *server) StepDown() error {
+	_, err := s.send(&stepDownValue)
+	return err
+}
+
+// Makes a follower start an election right away instead of waiting for its
+// election timeout. Calling it on a caught-up follower hands leadership over
+// to it since the current leader steps down on seeing the higher term.
+func (s *server) Campaign() error {
+	_, err := s.send(&campaignValue)
+	return err
+}
+
 // Shuts down the server.
 func (s *server) Stop() {
 	s.send(&stopValue)
@@ -441,7 +638,7 @@ func (s *server) Running() bool {
 // current term is found.
 func (s *server) setCurrentTerm(term uint64, leaderName string, append bool) {
 	s.mutex.Lock()
-	defer s.mutex.Unlock()
+	prevState, prevLeader, prevTerm := s.state, s.leader, s.currentTerm
 
 	// update the term and clear vote for
 	if term > s.currentTerm {
@@ -449,16 +646,15 @@ func (s *server) setCurrentTerm(term uint64, leaderName string, append bool) {
 		s.currentTerm = term
 		s.leader = leaderName
 		s.votedFor = ""
-		return
-	}
-
-	// discover new leader when candidate
-	// save leader name when follower
-	if term == s.currentTerm && s.state != Leader && append {
+	} else if term == s.currentTerm && s.state != Leader && append {
+		// discover new leader when candidate
+		// save leader name when follower
 		s.state = Follower
 		s.leader = leaderName
 	}
+	s.mutex.Unlock()
 
+	s.dispatchChanges(prevState, prevLeader, prevTerm)
 }
 
 //--------------------------------------
@@ -526,7 +722,7 @@ func (s *server) sendAsync(value interface{}) *event {
 func (s *server) followerLoop() {
 
 	s.setState(Follower)
-	timeoutChan := afterBetween(s.ElectionTimeout(), s.ElectionTimeout()*2)
+	timeoutChan := s.electionTimer()
 
 	for {
 		var err error
@@ -535,6 +731,12 @@ func (s *server) followerLoop() {
 		case e := <-s.c:
 			if e.target == &stopValue {
 				s.setState(Stopped)
+			} else if e.target == &campaignValue {
+				if s.promotable() {
+					s.setState(Candidate)
+				} else {
+					err = NotPromotableError
+				}
 			} else {
 				switch req := e.target.(type) {
 				case JoinCommand:
@@ -575,7 +777,7 @@ func (s *server) followerLoop() {
 		//   1.Receiving valid AppendEntries RPC, or
 		//   2.Granting vote to candidate
 		if update {
-			timeoutChan = afterBetween(s.ElectionTimeout(), s.ElectionTimeout()*2)
+			timeoutChan = s.electionTimer()
 		}
 
 		// Exit loop on state change.
@@ -588,16 +790,23 @@ func (s *server) followerLoop() {
 // The event loop that is run when the server is in a Candidate state.
 func (s *server) candidateLoop() {
 	lastLogIndex, lastLogTerm := s.log.lastInfo()
+	prevLeader := s.leader
 	s.leader = ""
 
 	for {
 		// Increment current term, vote for self.
+		prevTerm := s.currentTerm
 		s.currentTerm++
 		s.votedFor = s.name
+		s.dispatchChanges(Candidate, prevLeader, prevTerm)
+		prevLeader = ""
 
 		// Send RequestVote RPCs to all other servers.
 		respChan := make(chan *RequestVoteResponse, len(s.peers))
 		for _, peer := range s.peers {
+			if peer.Observer {
+				continue
+			}
 			go peer.sendVoteRequest(newRequestVoteRequest(s.currentTerm, s.name, lastLogIndex, lastLogTerm), respChan)
 		}
 
@@ -607,7 +816,7 @@ func (s *server) candidateLoop() {
 		//   * Election timeout elapses without election resolution: increment term, start new election
 		//   * Discover higher term: step down (§5.1)
 		votesGranted := 1
-		timeoutChan := afterBetween(s.ElectionTimeout(), s.ElectionTimeout()*2)
+		timeoutChan := s.electionTimer()
 		timeout := false
 
 		for {
@@ -690,6 +899,8 @@ func (s *server) leaderLoop() {
 		case e := <-s.c:
 			if e.target == &stopValue {
 				s.setState(Stopped)
+			} else if e.target == &stepDownValue {
+				s.setState(Follower)
 			} else {
 				switch req := e.target.(type) {
 				case Command:
@@ -777,11 +988,17 @@ func (s *server) processCommand(command Command, e *event) {
 		return
 	}
 
+	start := time.Now()
 	if err := s.log.appendEntry(entry); err != nil {
 		s.debugln("server.command.log.error:", err)
 		e.c <- err
 		return
 	}
+	if err := s.syncLog(start); err != nil {
+		s.debugln("server.command.log.sync.error:", err)
+		e.c <- err
+		return
+	}
 
 	// Issue a callback for the entry once it's committed.
 	go func() {
@@ -844,13 +1061,21 @@ func (s *server) processAppendEntriesRequest(req *AppendEntriesRequest) (*Append
 	}
 
 	// Append entries to the log.
+	start := time.Now()
 	if err := s.log.appendEntries(req.Entries); err != nil {
 		s.debugln("server.ae.append.error: ", err)
 		return newAppendEntriesResponse(s.currentTerm, false, s.log.currentIndex(), s.log.CommitIndex()), true
 	}
+	if len(req.Entries) > 0 {
+		if err := s.syncLog(start); err != nil {
+			s.debugln("server.ae.sync.error: ", err)
+			return newAppendEntriesResponse(s.currentTerm, false, s.log.currentIndex(), s.log.CommitIndex()), true
+		}
+	}
 
 	// Commit up to the commit index.
 	if err := s.log.setCommitIndex(req.CommitIndex); err != nil {
+		s.checkCorruption(err)
 		s.debugln("server.ae.commit.error: ", err)
 		return newAppendEntriesResponse(s.currentTerm, false, s.log.currentIndex(), s.log.CommitIndex()), true
 	}
@@ -883,15 +1108,24 @@ func (s *server) processAppendEntriesResponse(resp *AppendEntriesResponse) {
 	}
 
 	// Increment the commit count to make sure we have a quorum before committing.
-	if len(s.syncedPeer) < s.QuorumSize() {
+	synced := 0
+	for name := range s.syncedPeer {
+		if peer := s.peers[name]; peer == nil || !peer.Observer {
+			synced++
+		}
+	}
+	if synced < s.QuorumSize() {
 		return
 	}
 
-	// Determine the committed index that a majority has.
+	// Determine the committed index that a majority has. Observers do not
+	// count toward the majority.
 	var indices []uint64
 	indices = append(indices, s.log.currentIndex())
 	for _, peer := range s.peers {
-		indices = append(indices, peer.getPrevLogIndex())
+		if !peer.Observer {
+			indices = append(indices, peer.getPrevLogIndex())
+		}
 	}
 	sort.Sort(sort.Reverse(uint64Slice(indices)))
 
@@ -900,7 +1134,10 @@ func (s *server) processAppendEntriesResponse(resp *AppendEntriesResponse) {
 	committedIndex := s.log.commitIndex
 
 	if commitIndex > committedIndex {
-		s.log.setCommitIndex(commitIndex)
+		if err := s.log.setCommitIndex(commitIndex); err != nil {
+			s.checkCorruption(err)
+			s.debugln("server.commit.error: ", err)
+		}
 		s.debugln("commit index ", commitIndex)
 		for i := committedIndex; i < commitIndex; i++ {
 			if entry := s.log.getEntry(i + 1); entry != nil {
@@ -920,6 +1157,25 @@ func (s *server) processAppendEntriesResponse(resp *AppendEntriesResponse) {
 	}
 }
 
+// Reports an entry that fails its checksum to the listeners, once. The
+// entry can never be applied, so the commit index stays behind it forever
+// and the member has to be stopped rather than keep answering as if it were
+// healthy.
+func (s *server) checkCorruption(err error) {
+	e, ok := err.(*ChecksumError)
+	if !ok {
+		return
+	}
+	s.mutex.Lock()
+	reported := s.corrupted
+	s.corrupted = true
+	s.mutex.Unlock()
+
+	if !reported {
+		s.dispatch(CorruptionEventType, e, nil)
+	}
+}
+
 //--------------------------------------
 // Request Vote
 //--------------------------------------
@@ -944,6 +1200,12 @@ func (s *server) processRequestVoteRequest(req *RequestVoteRequest) (*RequestVot
 
 	s.setCurrentTerm(req.Term, "", false)
 
+	// Observers never vote.
+	if s.observer {
+		s.debugln("server.rv.error: observer")
+		return newRequestVoteResponse(s.currentTerm, false), false
+	}
+
 	// If we've already voted for a different candidate then don't vote for this candidate.
 	if s.votedFor != "" && s.votedFor != req.CandidateName {
 		s.debugln("server.rv.error: duplicate vote: ", req.CandidateName,
@@ -994,6 +1256,9 @@ func (s *server) AddPeer(name string, connectiongString string) error {
 	// Write the configuration to file.
 	s.writeConf()
 
+	if s.name != name {
+		s.dispatch(AddPeerEventType, name, nil)
+	}
 	return nil
 }
 
@@ -1020,6 +1285,9 @@ func (s *server) RemovePeer(name string) error {
 	// Write the configuration to file.
 	s.writeConf()
 
+	if name != s.Name() {
+		s.dispatch(RemovePeerEventType, name, nil)
+	}
 	return nil
 }
 
@@ -1067,11 +1335,14 @@ func (s *server) TakeSnapshot() error {
 	peers[i] = &Peer{
 		Name:             s.Name(),
 		ConnectionString: s.connectionString,
+		Observer:         s.observer,
 	}
 
 	s.currentSnapshot = &Snapshot{lastIndex, lastTerm, peers, state, path}
 
+	prevIndex := s.lastSnapshotIndex()
 	s.saveSnapshot()
+	s.dispatch(SnapshotEventType, lastIndex, prevIndex)
 
 	// We keep some log entries after the snapshot
 	// We do not want to send the whole snapshot
@@ -1109,6 +1380,14 @@ func (s *server) saveSnapshot() error {
 	return nil
 }
 
+// Retrieves the last index of the last snapshot, or zero if there is none.
+func (s *server) lastSnapshotIndex() uint64 {
+	if s.lastSnapshot == nil {
+		return 0
+	}
+	return s.lastSnapshot.LastIndex
+}
+
 // Retrieves the log path for the server.
 func (s *server) SnapshotPath(lastIndex uint64, lastTerm uint64) string {
 	return path.Join(s.path, "snapshot", fmt.Sprintf("%v_%v.ss", lastTerm, lastIndex))
@@ -1154,10 +1433,15 @@ func (s *server) processSnapshotRecoveryRequest(req *SnapshotRecoveryRequest) *S
 	// recovery the cluster configuration
 	for _, peer := range req.Peers {
 		s.AddPeer(peer.Name, peer.ConnectionString)
+		s.SetObserver(peer.Name, peer.Observer)
 	}
 
 	//update term and index
+	prevTerm := s.currentTerm
 	s.currentTerm = req.LastTerm
+	if s.currentTerm != prevTerm {
+		s.dispatch(TermChangeEventType, s.currentTerm, prevTerm)
+	}
 
 	s.log.updateCommitIndex(req.LastIndex)
 
@@ -1165,7 +1449,9 @@ func (s *server) processSnapshotRecoveryRequest(req *SnapshotRecoveryRequest) *S
 
 	s.currentSnapshot = &Snapshot{req.LastIndex, req.LastTerm, req.Peers, req.State, snapshotPath}
 
+	prevIndex := s.lastSnapshotIndex()
 	s.saveSnapshot()
+	s.dispatch(SnapshotRecoveryEventType, req.LastIndex, prevIndex)
 
 	// clear the previous log entries
 	s.log.compact(req.LastIndex, req.LastTerm)
@@ -1247,6 +1533,7 @@ func (s *server) LoadSnapshot() error {
 
 	for _, peer := range s.lastSnapshot.Peers {
 		s.AddPeer(peer.Name, peer.ConnectionString)
+		s.SetObserver(peer.Name, peer.Observer)
 	}
 
 	s.log.startTerm = s.lastSnapshot.LastTerm
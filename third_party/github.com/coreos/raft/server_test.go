@@ -183,6 +183,54 @@ func TestServerPromote(t *testing.T) {
 	}
 }
 
+// Ensure that an observer denies vote requests and does not promote itself.
+func TestServerObserverDoesNotVote(t *testing.T) {
+	e0, _ := newLogEntry(newLog(), 1, 1, &testCommand1{Val: "foo", I: 20})
+	s := newTestServerWithLog("1", &testTransporter{}, []*LogEntry{e0})
+	s.SetObserver(s.Name(), true)
+
+	s.Start()
+	defer s.Stop()
+
+	resp := s.RequestVote(newRequestVoteRequest(2, "foo", 1, 1))
+	if resp.Term != 2 || resp.VoteGranted {
+		t.Fatalf("Invalid request vote response: %v/%v", resp.Term, resp.VoteGranted)
+	}
+
+	time.Sleep(2 * testElectionTimeout)
+	if s.State() != Follower {
+		t.Fatalf("Observer promoted itself: %v", s.State())
+	}
+}
+
+// Ensure that observers do not count toward the quorum.
+func TestServerObserverQuorumSize(t *testing.T) {
+	s := newTestServer("1", &testTransporter{})
+	s.AddPeer("2", "")
+	s.AddPeer("3", "")
+	if s.QuorumSize() != 2 {
+		t.Fatalf("Invalid quorum size: %v", s.QuorumSize())
+	}
+
+	s.AddPeer("4", "")
+	s.AddPeer("5", "")
+	s.SetObserver("4", true)
+	s.SetObserver("5", true)
+	if s.QuorumSize() != 2 || s.MemberCount() != 5 {
+		t.Fatalf("Invalid quorum size: %v/%v", s.QuorumSize(), s.MemberCount())
+	}
+	if !s.IsObserver("4") || s.IsObserver("2") {
+		t.Fatalf("Invalid observer flags")
+	}
+	if !s.Peers()["5"].Observer {
+		t.Fatalf("Observer flag not copied")
+	}
+
+	if err := s.SetObserver("6", true); err == nil {
+		t.Fatalf("Expected error for unknown peer")
+	}
+}
+
 //--------------------------------------
 // Append Entries
 //--------------------------------------
@@ -617,3 +665,28 @@ func TestServerMultiNode(t *testing.T) {
 	}
 
 }
+
+// Ensure that election timeouts are chosen within the configured window and
+// recorded for the current term.
+func TestServerElectionWindow(t *testing.T) {
+	s := newTestServer("1", &testTransporter{}).(*server)
+	s.SetElectionTimeout(100 * time.Millisecond)
+	if s.ElectionWindow() != 100*time.Millisecond {
+		t.Fatalf("Invalid default window: %v", s.ElectionWindow())
+	}
+
+	s.SetElectionWindow(20 * time.Millisecond)
+	for i := 0; i < 10; i++ {
+		s.electionTimer()
+		stats := s.ElectionTimeoutStats()
+		if stats.Min != 100*time.Millisecond || stats.Max != 120*time.Millisecond {
+			t.Fatalf("Invalid window: %v-%v", stats.Min, stats.Max)
+		}
+		if stats.Current < stats.Min || stats.Current >= stats.Max {
+			t.Fatalf("Timeout outside of window: %v", stats.Current)
+		}
+		if stats.Term != s.Term() {
+			t.Fatalf("Invalid term: %v", stats.Term)
+		}
+	}
+}
@@ -12,3 +12,10 @@ type StateMachine interface {
 	Save() ([]byte, error)
 	Recovery([]byte) error
 }
+
+// PositionedStateMachine is implemented by state machines that want to know
+// the term and index of each log entry right before its command is applied.
+type PositionedStateMachine interface {
+	StateMachine
+	SetPosition(term uint64, index uint64)
+}
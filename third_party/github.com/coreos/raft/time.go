@@ -5,13 +5,21 @@ import (
 	"time"
 )
 
-// Waits for a random time between two durations and sends the current time on
-// the returned channel.
-func afterBetween(min time.Duration, max time.Duration) <-chan time.Time {
+// Returns a random duration between two durations.
+func randomBetween(min time.Duration, max time.Duration) time.Duration {
 	rand := rand.New(rand.NewSource(time.Now().UnixNano()))
 	d, delta := min, (max - min)
 	if delta > 0 {
 		d += time.Duration(rand.Int63n(int64(delta)))
 	}
-	return time.After(d)
+	return d
+}
+
+// The randomized election timeout a server chose last, in the window it was
+// chosen from.
+type ElectionTimeoutStats struct {
+	Term    uint64        `json:"term"`
+	Min     time.Duration `json:"min"`
+	Max     time.Duration `json:"max"`
+	Current time.Duration `json:"current"`
 }
@@ -0,0 +1,395 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"code.google.com/p/goprotobuf/proto"
+	"github.com/coreos/etcd/server"
+	"github.com/coreos/etcd/store"
+	"github.com/coreos/raft"
+	"github.com/coreos/raft/protobuf"
+)
+
+// A dump is the state rebuilt from a data directory.
+type dump struct {
+	snapshotPath string
+	snapshot     *raft.Snapshot
+	entries      []*raft.LogEntry
+	store        store.Store
+
+	// The commit index raft last saved. Entries after it were possibly
+	// committed later on, so they are replayed as well.
+	commitIndex uint64
+
+	// Changes applied from the log, in order.
+	changes []*change
+
+	// Entries whose command could not be replayed by the tool.
+	skipped map[string]int
+
+	// The hash of the store after each replayed entry, when asked for.
+	hashing bool
+	hashes  []*entryHash
+
+	// Everything found wrong while verifying.
+	problems []string
+}
+
+// A change is a store event produced by a log entry.
+type change struct {
+	index uint64
+	term  uint64
+	event *store.Event
+	err   error
+}
+
+// An entryHash is the state of the store right after an entry was replayed.
+type entryHash struct {
+	index   uint64
+	term    uint64
+	command string
+	hash    uint32
+}
+
+// load verifies and replays the snapshot and committed log entries of a
+// data directory. With hashing, the store is hashed after every entry.
+func load(dir string, hashing bool) (*dump, error) {
+	d := &dump{store: store.New(), skipped: make(map[string]int), hashing: hashing}
+
+	if err := d.loadConf(dir); err != nil {
+		return nil, err
+	}
+	if err := d.loadSnapshot(dir); err != nil {
+		return nil, err
+	}
+	if err := d.loadLog(dir); err != nil {
+		return nil, err
+	}
+	d.replay()
+	return d, nil
+}
+
+// loadConf reads the commit index saved by raft.
+func (d *dump) loadConf(dir string) error {
+	b, err := ioutil.ReadFile(filepath.Join(dir, "conf"))
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	conf := &raft.Config{}
+	if err := json.Unmarshal(b, conf); err != nil {
+		return fmt.Errorf("conf: %v", err)
+	}
+	d.commitIndex = conf.CommitIndex
+	return nil
+}
+
+// loadSnapshot verifies the checksum of the latest snapshot and recovers
+// the store from it.
+func (d *dump) loadSnapshot(dir string) error {
+	names, err := filepath.Glob(filepath.Join(dir, "snapshot", "*.ss"))
+	if err != nil || len(names) == 0 {
+		return nil
+	}
+	// Pick the latest snapshot the same way raft does.
+	sort.Strings(names)
+	d.snapshotPath = names[len(names)-1]
+
+	f, err := os.Open(d.snapshotPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var checksum uint32
+	if _, err := fmt.Fscanf(f, "%08x\n", &checksum); err != nil {
+		return fmt.Errorf("snapshot %s: bad header: %v", d.snapshotPath, err)
+	}
+	b, err := ioutil.ReadAll(f)
+	if err != nil {
+		return err
+	}
+	if sum := crc32.ChecksumIEEE(b); sum != checksum {
+		d.problems = append(d.problems, fmt.Sprintf("snapshot %s: checksum mismatch (%08x != %08x)", d.snapshotPath, sum, checksum))
+	}
+
+	d.snapshot = &raft.Snapshot{}
+	if err := json.Unmarshal(b, d.snapshot); err != nil {
+		return fmt.Errorf("snapshot %s: %v", d.snapshotPath, err)
+	}
+	if err := d.store.Recovery(d.snapshot.State); err != nil {
+		return fmt.Errorf("snapshot %s: %v", d.snapshotPath, err)
+	}
+	return nil
+}
+
+// loadLog decodes every entry of the log file and checks that indexes follow
+// each other and terms never go back.
+func (d *dump) loadLog(dir string) error {
+	f, err := os.Open(filepath.Join(dir, "log"))
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var offset int64
+	var prev *raft.LogEntry
+	for {
+		e, n, err := decodeEntry(f)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			d.problems = append(d.problems, fmt.Sprintf("log: corrupt entry at offset %d: %v", offset, err))
+			break
+		}
+		e.Position = offset
+		offset += int64(n)
+
+		if prev != nil {
+			if e.Index != prev.Index+1 {
+				d.problems = append(d.problems, fmt.Sprintf("log: index %d follows %d", e.Index, prev.Index))
+			}
+			if e.Term < prev.Term {
+				d.problems = append(d.problems, fmt.Sprintf("log: term goes back from %d to %d at index %d", prev.Term, e.Term, e.Index))
+			}
+		}
+		d.entries = append(d.entries, e)
+		prev = e
+	}
+	return nil
+}
+
+// decodeEntry reads an entry in the raft log format: its length in hex on
+// a line followed by the protobuf encoded entry.
+func decodeEntry(r io.Reader) (*raft.LogEntry, int, error) {
+	var length int
+	n, err := fmt.Fscanf(r, "%8x\n", &length)
+	if err != nil {
+		if n == 0 && (err == io.EOF || err == io.ErrUnexpectedEOF) {
+			return nil, 0, io.EOF
+		}
+		return nil, 0, err
+	}
+
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, 0, err
+	}
+
+	pb := &protobuf.ProtoLogEntry{}
+	if err := proto.Unmarshal(data, pb); err != nil {
+		return nil, 0, err
+	}
+
+	e := &raft.LogEntry{
+		Index:       pb.GetIndex(),
+		Term:        pb.GetTerm(),
+		CommandName: pb.GetCommandName(),
+		Command:     pb.Command,
+	}
+	return e, 9 + length, nil
+}
+
+// replay applies the entries following the snapshot to the store.
+func (d *dump) replay() {
+	var startIndex uint64
+	if d.snapshot != nil {
+		startIndex = d.snapshot.LastIndex
+	}
+
+	for _, e := range d.entries {
+		if e.Index <= startIndex {
+			continue
+		}
+
+		command, err := raft.NewCommand(e.CommandName, e.Command)
+		if err != nil {
+			d.problems = append(d.problems, fmt.Sprintf("log: index %d: %v", e.Index, err))
+			continue
+		}
+
+		if batch, ok := command.(*server.BatchCommand); ok {
+			for _, bc := range batch.Commands {
+				c, err := raft.NewCommand(bc.Name, bc.Data)
+				if err != nil {
+					d.problems = append(d.problems, fmt.Sprintf("log: index %d: %v", e.Index, err))
+					continue
+				}
+				d.apply(e, c)
+			}
+		} else {
+			d.apply(e, command)
+		}
+		if d.hashing {
+			d.hash(e)
+		}
+	}
+}
+
+// hash records the hash of the store after an entry. Replaying the same
+// entries on top of the same snapshot always gives the same hashes, since
+// entries carry the time and index they apply at.
+func (d *dump) hash(e *raft.LogEntry) {
+	h, err := d.store.Hash()
+	if err != nil {
+		d.problems = append(d.problems, fmt.Sprintf("log: index %d: hash: %v", e.Index, err))
+		return
+	}
+	d.hashes = append(d.hashes, &entryHash{index: e.Index, term: e.Term, command: e.CommandName, hash: h})
+}
+
+// apply runs a single command against the store. Commands that need a
+// running peer server, such as joins, are counted as skipped.
+func (d *dump) apply(e *raft.LogEntry, command raft.Command) {
+	defer func() {
+		if recover() != nil {
+			d.skipped[command.CommandName()]++
+		}
+	}()
+
+	value, err := command.Apply(&replayServer{store: d.store, index: e.Index, term: e.Term})
+	if event, ok := value.(*store.Event); ok || err != nil {
+		d.changes = append(d.changes, &change{index: e.Index, term: e.Term, event: event, err: err})
+	}
+}
+
+// printSummary describes what was read from the data directory.
+func (d *dump) printSummary(w io.Writer) {
+	if d.snapshot != nil {
+		fmt.Fprintf(w, "snapshot: %s index=%d term=%d peers=%d\n", d.snapshotPath, d.snapshot.LastIndex, d.snapshot.LastTerm, len(d.snapshot.Peers))
+	} else {
+		fmt.Fprintln(w, "snapshot: none")
+	}
+	if len(d.entries) > 0 {
+		last := d.entries[len(d.entries)-1].Index
+		fmt.Fprintf(w, "log: %d entries index=%d-%d commit=%d\n", len(d.entries), d.entries[0].Index, last, d.commitIndex)
+		if last > d.commitIndex {
+			fmt.Fprintf(w, "log: entries after index %d may not have been committed\n", d.commitIndex)
+		}
+	} else {
+		fmt.Fprintln(w, "log: empty")
+	}
+	for name, n := range d.skipped {
+		fmt.Fprintf(w, "skipped: %d %s entries\n", n, name)
+	}
+}
+
+// printKeys prints every key under the prefix with its value.
+func (d *dump) printKeys(w io.Writer, prefix string) error {
+	e, err := d.store.Get(prefix, true, true)
+	if err != nil {
+		return err
+	}
+	printNode(w, e.Node)
+	return nil
+}
+
+func printNode(w io.Writer, n *store.NodeExtern) {
+	if n.Dir {
+		for i := range n.Nodes {
+			printNode(w, &n.Nodes[i])
+		}
+		if len(n.Nodes) == 0 && n.Key != "/" {
+			fmt.Fprintf(w, "%s/\n", n.Key)
+		}
+		return
+	}
+	fmt.Fprintf(w, "%s=%s\n", n.Key, n.Value)
+}
+
+// printHistory prints every change replayed from the log that touched the
+// key or one of its children. Changes older than the snapshot are lost.
+func (d *dump) printHistory(w io.Writer, key string) {
+	for _, c := range d.changes {
+		if c.err != nil || c.event == nil || c.event.Node == nil {
+			continue
+		}
+		k := c.event.Node.Key
+		if k != key && !strings.HasPrefix(k, key+"/") {
+			continue
+		}
+		fmt.Fprintf(w, "%d\t%d\t%s\t%s", c.index, c.term, c.event.Action, k)
+		if c.event.Node.Value != "" {
+			fmt.Fprintf(w, "=%s", c.event.Node.Value)
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// printHashes prints the hash of the store after every replayed entry.
+func (d *dump) printHashes(w io.Writer) {
+	for _, h := range d.hashes {
+		fmt.Fprintf(w, "%d\t%d\t%s\t%08x\n", h.index, h.term, h.command, h.hash)
+	}
+}
+
+// printDiff compares the hashes of two dumps at the indexes both replayed
+// and prints the first entry after which the stores differ, with its
+// command on both sides. It returns false if there is one.
+func (d *dump) printDiff(w io.Writer, other *dump) bool {
+	theirs := make(map[uint64]*entryHash, len(other.hashes))
+	for _, h := range other.hashes {
+		theirs[h.index] = h
+	}
+
+	compared := 0
+	for _, h := range d.hashes {
+		o, ok := theirs[h.index]
+		if !ok {
+			continue
+		}
+		compared++
+		if h.hash == o.hash {
+			continue
+		}
+		fmt.Fprintf(w, "first difference after index %d\n", h.index)
+		fmt.Fprintf(w, "  this:  term=%d %s hash=%08x %s\n", h.term, h.command, h.hash, d.command(h.index))
+		fmt.Fprintf(w, "  other: term=%d %s hash=%08x %s\n", o.term, o.command, o.hash, other.command(o.index))
+		return false
+	}
+	fmt.Fprintf(w, "no difference in %d common entries\n", compared)
+	return true
+}
+
+// command returns the command of the entry at index as it is in the log.
+func (d *dump) command(index uint64) string {
+	for _, e := range d.entries {
+		if e.Index == index {
+			return string(e.Command)
+		}
+	}
+	return ""
+}
+
+// A replayServer hands the store to commands being replayed. Any other
+// method of raft.Server panics since there is no running server.
+type replayServer struct {
+	raft.Server
+	store store.Store
+	index uint64
+	term  uint64
+}
+
+func (s *replayServer) StateMachine() raft.StateMachine {
+	return s.store
+}
+
+func (s *replayServer) CommitIndex() uint64 {
+	return s.index
+}
+
+func (s *replayServer) Term() uint64 {
+	return s.term
+}
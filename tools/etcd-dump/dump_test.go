@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"code.google.com/p/goprotobuf/proto"
+	"github.com/coreos/etcd/store"
+	"github.com/coreos/raft"
+	"github.com/coreos/raft/protobuf"
+	"github.com/stretchr/testify/assert"
+)
+
+// Ensures that the log is replayed on top of the snapshot.
+func TestDumpReplay(t *testing.T) {
+	dir := testDataDir(t)
+	defer os.RemoveAll(dir)
+
+	d, err := load(dir, false)
+	assert.NoError(t, err)
+	assert.Equal(t, len(d.problems), 0)
+	assert.Equal(t, len(d.entries), 3)
+
+	var b bytes.Buffer
+	assert.NoError(t, d.printKeys(&b, "/"))
+	assert.Equal(t, b.String(), "/foo/bar=YYY\n/foo/baz=ZZZ\n")
+
+	b.Reset()
+	d.printHistory(&b, "/foo/bar")
+	assert.Equal(t, b.String(), "2\t1\tset\t/foo/bar=YYY\n")
+}
+
+// Ensures that a corrupt snapshot and log are reported.
+func TestDumpVerify(t *testing.T) {
+	dir := testDataDir(t)
+	defer os.RemoveAll(dir)
+
+	// Alter the snapshot and truncate the log mid-entry.
+	path := filepath.Join(dir, "snapshot", "1_1.ss")
+	b, _ := ioutil.ReadFile(path)
+	ioutil.WriteFile(path, bytes.Replace(b, []byte(`"lastTerm":1`), []byte(`"lastTerm":2`), 1), 0600)
+
+	path = filepath.Join(dir, "log")
+	b, _ = ioutil.ReadFile(path)
+	ioutil.WriteFile(path, b[:len(b)-3], 0600)
+
+	d, err := load(dir, false)
+	assert.NoError(t, err)
+	assert.Equal(t, len(d.problems), 2)
+	assert.Equal(t, len(d.entries), 2)
+}
+
+// Ensures that replays hash the same and that the first entry two data
+// directories disagree on is found.
+func TestDumpDiff(t *testing.T) {
+	dir := testDataDir(t)
+	defer os.RemoveAll(dir)
+	other := testDataDir(t)
+	defer os.RemoveAll(other)
+
+	d, err := load(dir, true)
+	assert.NoError(t, err)
+	assert.Equal(t, len(d.hashes), 2)
+	o, err := load(other, true)
+	assert.NoError(t, err)
+	assert.Equal(t, d.hashes, o.hashes)
+
+	var b bytes.Buffer
+	assert.True(t, d.printDiff(&b, o))
+	assert.Equal(t, b.String(), "no difference in 2 common entries\n")
+
+	var log bytes.Buffer
+	testWriteEntry(&log, 1, `{"key":"/foo/bar","value":"XXX"}`)
+	testWriteEntry(&log, 2, `{"key":"/foo/bar","value":"YYY"}`)
+	testWriteEntry(&log, 3, `{"key":"/foo/baz","value":"QQQ"}`)
+	ioutil.WriteFile(filepath.Join(other, "log"), log.Bytes(), 0600)
+
+	o, err = load(other, true)
+	assert.NoError(t, err)
+	b.Reset()
+	assert.False(t, d.printDiff(&b, o))
+	assert.Contains(t, b.String(), "first difference after index 3\n")
+	assert.Contains(t, b.String(), `{"key":"/foo/baz","value":"QQQ"}`)
+}
+
+// testDataDir writes a snapshot holding /foo/bar=XXX followed by a log that
+// updates it and adds /foo/baz.
+func testDataDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "etcd-dump")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := store.New()
+	s.Set("/foo/bar", false, "XXX", store.Permanent)
+	state, _ := s.Save()
+	ss, _ := json.Marshal(&raft.Snapshot{LastIndex: 1, LastTerm: 1, State: state})
+	os.MkdirAll(filepath.Join(dir, "snapshot"), 0700)
+	ioutil.WriteFile(filepath.Join(dir, "snapshot", "1_1.ss"), []byte(fmt.Sprintf("%08x\n%s", crc32.ChecksumIEEE(ss), ss)), 0600)
+
+	var log bytes.Buffer
+	testWriteEntry(&log, 1, `{"key":"/foo/bar","value":"XXX"}`)
+	testWriteEntry(&log, 2, `{"key":"/foo/bar","value":"YYY"}`)
+	testWriteEntry(&log, 3, `{"key":"/foo/baz","value":"ZZZ"}`)
+	ioutil.WriteFile(filepath.Join(dir, "log"), log.Bytes(), 0600)
+
+	return dir
+}
+
+func testWriteEntry(w *bytes.Buffer, index uint64, command string) {
+	b, _ := proto.Marshal(&protobuf.ProtoLogEntry{
+		Index:       proto.Uint64(index),
+		Term:        proto.Uint64(1),
+		CommandName: proto.String("etcd:set"),
+		Command:     []byte(command),
+	})
+	fmt.Fprintf(w, "%8x\n", len(b))
+	w.Write(b)
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2013 CoreOS Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// etcd-dump reads the snapshot and log of an etcd data directory, verifies
+// them and prints the resulting keyspace or the history of a single key.
+//
+//	etcd-dump -data-dir=/var/lib/etcd
+//	etcd-dump -data-dir=/var/lib/etcd -prefix=/services
+//	etcd-dump -data-dir=/var/lib/etcd -history=/services/web
+//	etcd-dump -data-dir=/var/lib/etcd -verify
+//	etcd-dump -data-dir=/var/lib/etcd -hashes
+//	etcd-dump -data-dir=/var/lib/etcd -diff=/backup/machine2
+//
+// -hashes prints the hash of the store after every entry of the log, and
+// -diff replays a second data directory and prints the first entry after
+// which the two stores differ, to find where two members diverged.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+func main() {
+	var dataDir, prefix, history, diff string
+	var verify, hashes bool
+
+	f := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	f.StringVar(&dataDir, "data-dir", ".", "Path to the etcd data directory.")
+	f.StringVar(&prefix, "prefix", "/", "Only print keys under this prefix.")
+	f.StringVar(&history, "history", "", "Print every change of this key instead of the keyspace.")
+	f.BoolVar(&verify, "verify", false, "Only verify the snapshot and log.")
+	f.BoolVar(&hashes, "hashes", false, "Print the hash of the store after every log entry instead of the keyspace.")
+	f.StringVar(&diff, "diff", "", "Compare the store after every log entry with the one of this other data directory.")
+	f.Parse(os.Args[1:])
+
+	d, err := load(dataDir, hashes || diff != "")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	d.printSummary(os.Stderr)
+	if len(d.problems) > 0 {
+		for _, p := range d.problems {
+			fmt.Fprintln(os.Stderr, "error:", p)
+		}
+		defer os.Exit(1)
+	}
+	if verify {
+		return
+	}
+
+	if diff != "" {
+		other, err := load(diff, true)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if !d.printDiff(os.Stdout, other) {
+			defer os.Exit(1)
+		}
+		return
+	}
+	if hashes {
+		d.printHashes(os.Stdout)
+		return
+	}
+	if history != "" {
+		d.printHistory(os.Stdout, "/"+strings.TrimPrefix(history, "/"))
+		return
+	}
+	if err := d.printKeys(os.Stdout, prefix); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}